@@ -0,0 +1,575 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"path"
+	"sort"
+
+	"github.com/mitchellh/hashstructure"
+	"github.com/pkg/errors"
+	log "github.com/unchartedsoftware/plog"
+
+	"github.com/uncharted-distil/distil-compute/model"
+	"github.com/uncharted-distil/distil-compute/primitive/compute"
+	"github.com/uncharted-distil/distil/api/env"
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/util"
+)
+
+const (
+	defaultFolds       = 5
+	foldsFolderName    = "folds"
+	minRowsPerFoldTest = 1
+)
+
+// foldedSplitter is a splitter that can partition the data into multiple
+// train/test folds for cross validation. The holdout split of a folded
+// splitter is its final fold.
+type foldedSplitter interface {
+	datasetSplitter
+	folds(data [][]string, rng *rand.Rand) ([]*datasetFold, error)
+	foldCount() int
+}
+
+type datasetFold struct {
+	train [][]string
+	test  [][]string
+}
+
+// DatasetFold holds the schema files of the train and test partitions of a fold.
+type DatasetFold struct {
+	TrainSchemaFile string
+	TestSchemaFile  string
+}
+
+type foldedManifestSplitter struct {
+	*manifestSplitter
+}
+
+type kFoldSplitter struct {
+	numFolds int
+}
+
+type stratifiedKFoldSplitter struct {
+	numFolds  int
+	targetCol int
+}
+
+type groupKFoldSplitter struct {
+	numFolds    int
+	groupingCol int
+}
+
+type rollingOriginSplitter struct {
+	numFolds      int
+	timeseriesCol int
+}
+
+func hashFoldedSplitter(splitter foldedSplitter, col int, schemaFile string, params []interface{}) (uint64, error) {
+	// generate the hash from the params
+	hashStruct := struct {
+		Schema   string
+		Type     string
+		NumFolds int
+		Col      int
+		Params   []interface{}
+	}{
+		Schema:   schemaFile,
+		Type:     splitter.splitType(),
+		NumFolds: splitter.foldCount(),
+		Col:      col,
+		Params:   params,
+	}
+	hash, err := hashstructure.Hash(hashStruct, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to generate persisted data hash")
+	}
+	return hash, nil
+}
+
+// holdoutFold uses the last fold as the train/test split of the search.
+func holdoutFold(splitter foldedSplitter, data [][]string, rng *rand.Rand) ([][]string, [][]string, error) {
+	folds, err := splitter.folds(data, rng)
+	if err != nil {
+		return nil, nil, err
+	}
+	holdout := folds[len(folds)-1]
+
+	return holdout.train, holdout.test, nil
+}
+
+// foldsFromAssignments builds the folds given the fold each row is tested in.
+func foldsFromAssignments(header []string, rows [][]string, assignments []int, numFolds int) []*datasetFold {
+	folds := make([]*datasetFold, numFolds)
+	for i := range folds {
+		folds[i] = &datasetFold{
+			train: [][]string{header},
+			test:  [][]string{header},
+		}
+	}
+
+	for i, row := range rows {
+		for f, fold := range folds {
+			if assignments[i] == f {
+				fold.test = append(fold.test, row)
+			} else {
+				fold.train = append(fold.train, row)
+			}
+		}
+	}
+
+	return folds
+}
+
+func validateFoldCount(numFolds int, rows int) error {
+	if numFolds < 2 {
+		return errors.Errorf("cross validation requires at least 2 folds but %d were requested", numFolds)
+	}
+	if rows < numFolds*minRowsPerFoldTest {
+		return errors.Errorf("unable to split %d rows into %d folds", rows, numFolds)
+	}
+	return nil
+}
+
+// collectFoldIndices lists the d3m index of the test rows of every fold.
+func collectFoldIndices(splitter foldedSplitter, data [][]string, seed int64) ([][]string, error) {
+	d3mIndexCol := getD3MIndexColumn(data[0])
+	if d3mIndexCol < 0 {
+		log.Warnf("no '%s' field found in split data so the split manifest will not list folds", model.D3MIndexFieldName)
+		return nil, nil
+	}
+
+	folds, err := splitter.folds(data, newSeededRand(seed))
+	if err != nil {
+		return nil, err
+	}
+	foldIndices := make([][]string, len(folds))
+	for f, fold := range folds {
+		foldIndices[f] = collectIndices(fold.test[1:], d3mIndexCol)
+	}
+
+	return foldIndices, nil
+}
+
+func (m *foldedManifestSplitter) foldCount() int {
+	return len(m.manifest.FoldTest)
+}
+
+func (m *foldedManifestSplitter) folds(data [][]string, rng *rand.Rand) ([]*datasetFold, error) {
+	log.Infof("performing %d fold split based on the manifest of request '%s'", m.foldCount(), m.manifest.RequestID)
+	header := data[0]
+	rows := data[1:]
+	d3mIndexCol := getD3MIndexColumn(header)
+	if d3mIndexCol < 0 {
+		return nil, errors.Errorf("unable to split using a manifest since the data has no '%s' field", model.D3MIndexFieldName)
+	}
+
+	foldIndices := map[string]int{}
+	for f, indices := range m.manifest.FoldTest {
+		for _, index := range indices {
+			foldIndices[index] = f
+		}
+	}
+
+	// every row needs to be in a fold for the folds to be identical
+	assignments := make([]int, len(rows))
+	for i, row := range rows {
+		f, ok := foldIndices[row[d3mIndexCol]]
+		if !ok {
+			return nil, errors.Errorf("row '%s' is not part of the folds of request '%s'", row[d3mIndexCol], m.manifest.RequestID)
+		}
+		assignments[i] = f
+	}
+
+	return foldsFromAssignments(header, rows, assignments, m.foldCount()), nil
+}
+
+func (k *kFoldSplitter) hash(schemaFile string, params ...interface{}) (uint64, error) {
+	return hashFoldedSplitter(k, -1, schemaFile, params)
+}
+
+func (k *kFoldSplitter) splitType() string {
+	return api.SplitTypeKFold
+}
+
+func (k *kFoldSplitter) ratio() float64 {
+	return 1.0 - 1.0/float64(k.numFolds)
+}
+
+func (k *kFoldSplitter) foldCount() int {
+	return k.numFolds
+}
+
+func (k *kFoldSplitter) split(data [][]string, rng *rand.Rand) ([][]string, [][]string, error) {
+	return holdoutFold(k, data, rng)
+}
+
+func (k *kFoldSplitter) folds(data [][]string, rng *rand.Rand) ([]*datasetFold, error) {
+	log.Infof("performing %d fold split", k.numFolds)
+	header := data[0]
+	rows := data[1:]
+	err := validateFoldCount(k.numFolds, len(rows))
+	if err != nil {
+		return nil, err
+	}
+
+	// shuffle the row order and deal the rows out to the folds
+	order := rng.Perm(len(rows))
+	assignments := make([]int, len(rows))
+	for i, rowIndex := range order {
+		assignments[rowIndex] = i % k.numFolds
+	}
+
+	return foldsFromAssignments(header, rows, assignments, k.numFolds), nil
+}
+
+func (s *stratifiedKFoldSplitter) hash(schemaFile string, params ...interface{}) (uint64, error) {
+	return hashFoldedSplitter(s, s.targetCol, schemaFile, params)
+}
+
+func (s *stratifiedKFoldSplitter) splitType() string {
+	return api.SplitTypeStratifiedKFold
+}
+
+func (s *stratifiedKFoldSplitter) ratio() float64 {
+	return 1.0 - 1.0/float64(s.numFolds)
+}
+
+func (s *stratifiedKFoldSplitter) foldCount() int {
+	return s.numFolds
+}
+
+func (s *stratifiedKFoldSplitter) split(data [][]string, rng *rand.Rand) ([][]string, [][]string, error) {
+	return holdoutFold(s, data, rng)
+}
+
+func (s *stratifiedKFoldSplitter) folds(data [][]string, rng *rand.Rand) ([]*datasetFold, error) {
+	log.Infof("performing %d fold split based on stratified sampling", s.numFolds)
+	header := data[0]
+	rows := data[1:]
+	err := validateFoldCount(s.numFolds, len(rows))
+	if err != nil {
+		return nil, err
+	}
+
+	// collect the rows of each category
+	categoryRows := map[string][]int{}
+	for i, row := range rows {
+		categoryRows[row[s.targetCol]] = append(categoryRows[row[s.targetCol]], i)
+	}
+	categories := make([]string, 0, len(categoryRows))
+	for category := range categoryRows {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	// shuffle each category and deal its rows out to the folds, continuing
+	// where the previous category left off to keep the fold sizes balanced
+	assignments := make([]int, len(rows))
+	next := 0
+	for _, category := range categories {
+		indices := categoryRows[category]
+		rng.Shuffle(len(indices), func(i, j int) { indices[i], indices[j] = indices[j], indices[i] })
+		for _, rowIndex := range indices {
+			assignments[rowIndex] = next % s.numFolds
+			next++
+		}
+	}
+
+	return foldsFromAssignments(header, rows, assignments, s.numFolds), nil
+}
+
+func (g *groupKFoldSplitter) hash(schemaFile string, params ...interface{}) (uint64, error) {
+	return hashFoldedSplitter(g, g.groupingCol, schemaFile, params)
+}
+
+func (g *groupKFoldSplitter) splitType() string {
+	return api.SplitTypeGroupKFold
+}
+
+func (g *groupKFoldSplitter) ratio() float64 {
+	return 1.0 - 1.0/float64(g.numFolds)
+}
+
+func (g *groupKFoldSplitter) foldCount() int {
+	return g.numFolds
+}
+
+func (g *groupKFoldSplitter) split(data [][]string, rng *rand.Rand) ([][]string, [][]string, error) {
+	return holdoutFold(g, data, rng)
+}
+
+func (g *groupKFoldSplitter) folds(data [][]string, rng *rand.Rand) ([]*datasetFold, error) {
+	log.Infof("performing %d fold split keeping groups together", g.numFolds)
+	header := data[0]
+	rows := data[1:]
+
+	// collect the rows of each group
+	groupRows := map[string][]int{}
+	for i, row := range rows {
+		groupRows[row[g.groupingCol]] = append(groupRows[row[g.groupingCol]], i)
+	}
+	err := validateFoldCount(g.numFolds, len(groupRows))
+	if err != nil {
+		return nil, err
+	}
+
+	// shuffle the groups, then order them from largest to smallest so that
+	// each group can be placed in the fold with the fewest rows
+	groups := make([]string, 0, len(groupRows))
+	for group := range groupRows {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	rng.Shuffle(len(groups), func(i, j int) { groups[i], groups[j] = groups[j], groups[i] })
+	sort.SliceStable(groups, func(i, j int) bool {
+		return len(groupRows[groups[i]]) > len(groupRows[groups[j]])
+	})
+
+	assignments := make([]int, len(rows))
+	foldSizes := make([]int, g.numFolds)
+	for _, group := range groups {
+		smallest := 0
+		for f, size := range foldSizes {
+			if size < foldSizes[smallest] {
+				smallest = f
+			}
+		}
+		for _, rowIndex := range groupRows[group] {
+			assignments[rowIndex] = smallest
+		}
+		foldSizes[smallest] += len(groupRows[group])
+	}
+
+	return foldsFromAssignments(header, rows, assignments, g.numFolds), nil
+}
+
+func (r *rollingOriginSplitter) hash(schemaFile string, params ...interface{}) (uint64, error) {
+	return hashFoldedSplitter(r, r.timeseriesCol, schemaFile, params)
+}
+
+func (r *rollingOriginSplitter) splitType() string {
+	return api.SplitTypeRollingOrigin
+}
+
+func (r *rollingOriginSplitter) ratio() float64 {
+	return float64(r.numFolds) / float64(r.numFolds+1)
+}
+
+func (r *rollingOriginSplitter) foldCount() int {
+	return r.numFolds
+}
+
+func (r *rollingOriginSplitter) split(data [][]string, rng *rand.Rand) ([][]string, [][]string, error) {
+	return holdoutFold(r, data, rng)
+}
+
+// folds divides the time ordered data into one more block than there are
+// folds. Fold i trains on all the blocks up to and including block i, and
+// tests on block i+1, so the training window expands over time.
+func (r *rollingOriginSplitter) folds(data [][]string, rng *rand.Rand) ([]*datasetFold, error) {
+	log.Infof("performing %d fold rolling origin split", r.numFolds)
+	header := data[0]
+
+	// collect the distinct timestamps so that rows sharing a timestamp end up in the same block
+	timestampRows := map[float64][][]string{}
+	for _, row := range data[1:] {
+		t, err := parseTimeColValue(row[r.timeseriesCol])
+		if err != nil {
+			return nil, err
+		}
+		timestampRows[t] = append(timestampRows[t], row)
+	}
+	timestamps := make([]float64, 0, len(timestampRows))
+	for t := range timestampRows {
+		timestamps = append(timestamps, t)
+	}
+	sort.Float64s(timestamps)
+
+	err := validateFoldCount(r.numFolds, len(timestamps)-1)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := r.numFolds + 1
+	blockSize := int(math.Ceil(float64(len(timestamps)) / float64(blocks)))
+	blockEnd := func(block int) int {
+		return min(len(timestamps), (block+1)*blockSize)
+	}
+
+	folds := make([]*datasetFold, r.numFolds)
+	for f := range folds {
+		fold := &datasetFold{
+			train: [][]string{header},
+			test:  [][]string{header},
+		}
+		for _, t := range timestamps[:blockEnd(f)] {
+			fold.train = append(fold.train, timestampRows[t]...)
+		}
+		for _, t := range timestamps[blockEnd(f):blockEnd(f+1)] {
+			fold.test = append(fold.test, timestampRows[t]...)
+		}
+		if len(fold.test) == 1 {
+			return nil, errors.Errorf("not enough distinct timestamps to create %d rolling origin folds", r.numFolds)
+		}
+		folds[f] = fold
+	}
+
+	return folds, nil
+}
+
+func createCrossValidationSplitter(method string, taskType []string, numFolds int, targetFieldIndex int, groupingFieldIndex int) (foldedSplitter, error) {
+	if numFolds <= 0 {
+		numFolds = defaultFolds
+	}
+
+	switch method {
+	case api.SplitTypeKFold:
+		return &kFoldSplitter{
+			numFolds: numFolds,
+		}, nil
+	case api.SplitTypeStratifiedKFold:
+		return &stratifiedKFoldSplitter{
+			numFolds:  numFolds,
+			targetCol: targetFieldIndex,
+		}, nil
+	case api.SplitTypeGroupKFold:
+		if groupingFieldIndex < 0 {
+			return nil, errors.Errorf("%s cross validation requires a grouping field", method)
+		}
+		return &groupKFoldSplitter{
+			numFolds:    numFolds,
+			groupingCol: groupingFieldIndex,
+		}, nil
+	case api.SplitTypeRollingOrigin:
+		// only forecasting tasks resolve the grouping field to the timestamp
+		if !isForecastingTask(taskType) {
+			return nil, errors.Errorf("%s cross validation is only supported for forecasting tasks", method)
+		}
+		if groupingFieldIndex < 0 {
+			return nil, errors.Errorf("%s cross validation requires a timestamp field", method)
+		}
+		return &rollingOriginSplitter{
+			numFolds:      numFolds,
+			timeseriesCol: groupingFieldIndex,
+		}, nil
+	}
+
+	return nil, errors.Errorf("unsupported cross validation method '%s'", method)
+}
+
+func isForecastingTask(taskType []string) bool {
+	for _, task := range taskType {
+		if task == compute.ForecastingTask {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitDatasetFolds splits a dataset into the train and test partitions of
+// each cross validation fold.
+func SplitDatasetFolds(schemaFile string, splitter foldedSplitter) ([]*DatasetFold, error) {
+	// load the metadata to get the data resource
+	meta, err := loadMetadataForSplit(schemaFile)
+	if err != nil {
+		return nil, err
+	}
+
+	splitDatasetName, err := generateSplitDatasetName(meta.ID, schemaFile, splitter)
+	if err != nil {
+		return nil, err
+	}
+	foldsFolder := path.Join(env.GetTmpPath(), splitDatasetName, foldsFolderName)
+
+	// check if already split
+	datasetFolds := make([]*DatasetFold, splitter.foldCount())
+	split := true
+	for f := range datasetFolds {
+		foldFolder := path.Join(foldsFolder, fmt.Sprintf("%d", f))
+		datasetFolds[f] = &DatasetFold{
+			TrainSchemaFile: path.Join(foldFolder, trainFilenamePrefix, compute.D3MDataSchema),
+			TestSchemaFile:  path.Join(foldFolder, testFilenamePrefix, compute.D3MDataSchema),
+		}
+		split = split && util.FileExists(datasetFolds[f].TrainSchemaFile) && util.FileExists(datasetFolds[f].TestSchemaFile)
+	}
+	if split {
+		log.Infof("dataset '%s' already split into %d folds", meta.ID, len(datasetFolds))
+		return datasetFolds, nil
+	}
+
+	err = deleteIfExists(foldsFolder)
+	if err != nil {
+		return nil, err
+	}
+
+	// load data to split
+	data, err := loadData(path.Dir(schemaFile), meta)
+	if err != nil {
+		return nil, err
+	}
+
+	// seed the same way as the holdout split so the final fold matches it
+	hash, err := splitter.hash(schemaFile)
+	if err != nil {
+		return nil, err
+	}
+	folds, err := splitter.folds(data, newSeededRand(int64(hash)))
+	if err != nil {
+		return nil, err
+	}
+
+	// output the train and test data of every fold
+	resolveSplitResourcePaths(schemaFile, meta)
+	for f, fold := range folds {
+		err = writeSplitPartition(meta, path.Dir(datasetFolds[f].TrainSchemaFile), fold.train)
+		if err != nil {
+			return nil, err
+		}
+		err = writeSplitPartition(meta, path.Dir(datasetFolds[f].TestSchemaFile), fold.test)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return datasetFolds, nil
+}
+
+// aggregateFoldScores computes the mean and population standard deviation of
+// the scores across folds.
+func aggregateFoldScores(scores []float64) (float64, float64) {
+	if len(scores) == 0 {
+		return 0, 0
+	}
+
+	sum := 0.0
+	for _, score := range scores {
+		sum += score
+	}
+	mean := sum / float64(len(scores))
+
+	variance := 0.0
+	for _, score := range scores {
+		variance += (score - mean) * (score - mean)
+	}
+	variance = variance / float64(len(scores))
+
+	return mean, math.Sqrt(variance)
+}
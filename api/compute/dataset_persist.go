@@ -23,7 +23,6 @@ import (
 	"path"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/araddon/dateparse"
 	"github.com/mitchellh/hashstructure"
@@ -42,6 +41,8 @@ import (
 const (
 	trainFilenamePrefix = "train"
 	testFilenamePrefix  = "test"
+
+	splitManifestFilename = "split-manifest.json"
 )
 
 // FilteredDataProvider defines a function that will fetch data from a back end source given
@@ -84,6 +85,12 @@ func parseTimeColValue(timeColValue string) (float64, error) {
 	return f, nil
 }
 
+// newSeededRand creates a random source for shuffling. Splits use a seed
+// derived from the split parameters so that they can be reproduced.
+func newSeededRand(seed int64) *rand.Rand {
+	return rand.New(rand.NewSource(seed))
+}
+
 type shuffleTracker struct {
 	output [][]string
 	count  int
@@ -94,16 +101,13 @@ func (s *shuffleTracker) lessThanMax() bool {
 	return s.count < s.max
 }
 
-func shuffleAndWrite(rowData [][]string, groupCol int, maxTrainingCount int,
+func shuffleAndWrite(rng *rand.Rand, rowData [][]string, groupCol int, maxTrainingCount int,
 	maxTestCount int, adjustCount bool, outputTrain [][]string, outputTest [][]string,
 	trainTestSplit float64) ([][]string, [][]string) {
 	if maxTrainingCount <= 0 {
 		maxTrainingCount = math.MaxInt64
 	}
 
-	// shuffle array
-	rand.Seed(time.Now().UnixNano())
-
 	// Figure out the number of train and test rows to use capping on the limit supplied by the caller.
 	numTrain := maxTrainingCount
 	numTest := maxTestCount
@@ -126,7 +130,7 @@ func shuffleAndWrite(rowData [][]string, groupCol int, maxTrainingCount int,
 
 	if groupCol < 0 {
 		// Shuffle the list of unique group keys to randomize their order.
-		rand.Shuffle(len(rowData), func(i, j int) { rowData[i], rowData[j] = rowData[j], rowData[i] })
+		rng.Shuffle(len(rowData), func(i, j int) { rowData[i], rowData[j] = rowData[j], rowData[i] })
 
 		// write out training data until we we reach the max training count, then write out the
 		// test data
@@ -158,7 +162,7 @@ func shuffleAndWrite(rowData [][]string, groupCol int, maxTrainingCount int,
 		}
 
 		// Shuffle the list of unique group keys to randomize their order.
-		rand.Shuffle(len(groupKeys), func(i, j int) { groupKeys[i], groupKeys[j] = groupKeys[j], groupKeys[i] })
+		rng.Shuffle(len(groupKeys), func(i, j int) { groupKeys[i], groupKeys[j] = groupKeys[j], groupKeys[i] })
 
 		// Iterate over the randomized list of group keys, looking up the associated rows for each.  Write out
 		// the train rows, then the test rows.
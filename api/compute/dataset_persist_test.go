@@ -40,7 +40,7 @@ func TestPersistOriginalDataUnstratified(t *testing.T) {
 	trainPath := fmt.Sprintf("test/tmp_data/%s/train/datasetDoc.json", splitDatasetName)
 	testPath := fmt.Sprintf("test/tmp_data/%s/test/datasetDoc.json", splitDatasetName)
 
-	trainFolder, testFolder, err := SplitDataset(path.Join(params.SourceDataFolder, params.SchemaFile), splitter)
+	trainFolder, testFolder, _, err := SplitDataset(path.Join(params.SourceDataFolder, params.SchemaFile), splitter)
 	assert.NoError(t, err)
 	assert.Equal(t, trainPath, trainFolder)
 	assert.Equal(t, testPath, testFolder)
@@ -67,7 +67,7 @@ func TestPersistOriginalDataStratified(t *testing.T) {
 	trainPath := fmt.Sprintf("test/tmp_data/%s/train/datasetDoc.json", splitDatasetName)
 	testPath := fmt.Sprintf("test/tmp_data/%s/test/datasetDoc.json", splitDatasetName)
 
-	trainFolder, testFolder, err := SplitDataset(path.Join(params.SourceDataFolder, params.SchemaFile), splitter)
+	trainFolder, testFolder, _, err := SplitDataset(path.Join(params.SourceDataFolder, params.SchemaFile), splitter)
 	assert.NoError(t, err)
 	assert.Equal(t, trainPath, trainFolder)
 	assert.Equal(t, testPath, testFolder)
@@ -101,7 +101,7 @@ func TestLimitChange(t *testing.T) {
 	splitDatasetName0, err := generateSplitDatasetName("test_data", path.Join("./test/test_dataset", "datasetDoc.json"), splitter)
 	assert.NoError(t, err)
 
-	_, _, err = SplitDataset(path.Join(params.SourceDataFolder, params.SchemaFile), splitter)
+	_, _, _, err = SplitDataset(path.Join(params.SourceDataFolder, params.SchemaFile), splitter)
 	assert.NoError(t, err)
 	trainPath := fmt.Sprintf("test/tmp_data/%s/train/datasetDoc.json", splitDatasetName0)
 	assert.FileExists(t, trainPath)
@@ -114,7 +114,7 @@ func TestLimitChange(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotEqual(t, splitDatasetName0, splitDatasetName1)
 
-	_, _, err = SplitDataset(path.Join(params.SourceDataFolder, params.SchemaFile), splitter)
+	_, _, _, err = SplitDataset(path.Join(params.SourceDataFolder, params.SchemaFile), splitter)
 	assert.NoError(t, err)
 	trainPath = fmt.Sprintf("test/tmp_data/%s/train/datasetDoc.json", splitDatasetName1)
 	assert.FileExists(t, trainPath)
@@ -79,9 +79,10 @@ func (s *SolutionRequest) createPreFeaturizedPipeline(learningDataset string,
 		}
 	}
 
-	// image feature variables are m system data role and should be included in our selected set
+	// image feature variables are in the featurized role and should be included in our selected set
+	// (other system data such as the image cloud cover is not a feature)
 	for _, featurizedVariable := range featurizedVariables {
-		if featurizedVariable.HasRole(model.VarDistilRoleSystemData) {
+		if featurizedVariable.HasRole(model.VarDistilRoleFeaturized) {
 			selectedVariables = append(selectedVariables, featurizedVariable.Key)
 		}
 	}
@@ -78,7 +78,7 @@ func filterData(client *compute.Client, ds *api.Dataset, filterParams *api.Filte
 		allowableTypes = append(allowableTypes, compute.ParquetURIValueType)
 		allowableTypes = append(allowableTypes, compute.CSVURIValueType)
 	}
-	filteredData, err := SubmitPipeline(client, []string{outputFolder}, nil, nil, pipeline, allowableTypes, true)
+	filteredData, err := SubmitPipeline(client, []string{outputFolder}, nil, nil, pipeline, allowableTypes, true, QueuePriorityDefault, ds.ID, "filter")
 	if err != nil {
 		return "", nil, err
 	}
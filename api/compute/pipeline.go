@@ -22,7 +22,6 @@ import (
 	"io/ioutil"
 	"os"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/mitchellh/hashstructure"
@@ -32,7 +31,6 @@ import (
 	"github.com/uncharted-distil/distil-compute/pipeline"
 	"github.com/uncharted-distil/distil-compute/primitive/compute"
 	"github.com/uncharted-distil/distil-compute/primitive/compute/description"
-	"github.com/uncharted-distil/distil/api/env"
 	"github.com/uncharted-distil/distil/api/util"
 	log "github.com/unchartedsoftware/plog"
 )
@@ -106,91 +104,6 @@ func (t *pipelineQueueTask) hashEquivalent() (string, error) {
 	return hashedPipelineKey, nil
 }
 
-// QueueItem is the wrapper for the data to process and the response channel.
-type QueueItem struct {
-	key    string
-	output []chan *QueueResponse
-	data   interface{}
-}
-
-// QueueResponse represents the result from processing a queue item.
-type QueueResponse struct {
-	Output interface{}
-	Error  error
-}
-
-// Queue uses a buffered channel to queue tasks and provides the result via channels.
-type Queue struct {
-	mu            sync.RWMutex
-	tasks         chan *QueueItem
-	alreadyQueued map[string]*QueueItem
-	inProgress    *QueueItem
-}
-
-// Enqueue adds one entry to the queue, providing the response channel as result.
-// If the key is already in the queue, then the data is not added a second time.
-// Rather, a new output channel is added
-func (q *Queue) Enqueue(key string, data interface{}) chan *QueueResponse {
-	log.Infof("enqueuing data in the queue")
-	output := make(chan *QueueResponse, 1)
-
-	// use key to check if it is already in the queue
-	q.mu.Lock()
-	queuedItem := q.alreadyQueued[key]
-	if queuedItem != nil {
-		log.Infof("'%s' already in queue so adding one more channel to output", key)
-		queuedItem.output = append(queuedItem.output, output)
-		queuedItem.data = data
-		q.mu.Unlock()
-
-		return output
-	}
-	if q.inProgress != nil && q.inProgress.key == key {
-		log.Infof("'%s' already in progress so adding one more channel to output", key)
-		q.inProgress.output = append(q.inProgress.output, output)
-		q.inProgress.data = data
-		q.mu.Unlock()
-
-		return output
-	}
-
-	log.Infof("'%s' not in queue so creating new item", key)
-	item := &QueueItem{
-		key:    key,
-		data:   data,
-		output: []chan *QueueResponse{output},
-	}
-	q.alreadyQueued[key] = item
-	q.mu.Unlock()
-
-	q.tasks <- item
-
-	return output
-}
-
-// Dequeue removes one item from the queue.
-func (q *Queue) Dequeue() (*QueueItem, bool) {
-	log.Infof("dequeuing data from the queue")
-	item, ok := <-q.tasks
-	if !ok {
-		return item, ok
-	}
-
-	q.mu.Lock()
-	q.alreadyQueued[item.key] = nil
-	q.inProgress = item
-	q.mu.Unlock()
-
-	return item, true
-}
-
-// Done flags a task queue as being completed, which removes it from the in progress slot.
-func (q *Queue) Done() {
-	q.mu.Lock()
-	q.inProgress = nil
-	q.mu.Unlock()
-}
-
 // InitializeCache sets up an empty cache or if a source file provided, reads
 // the cache from the source file.
 func InitializeCache(sourceFile string, readEnabled bool) error {
@@ -222,19 +135,11 @@ func InitializeCache(sourceFile string, readEnabled bool) error {
 	return nil
 }
 
-// InitializeQueue creates the pipeline queue and runs go routine to process pipeline requests
-func InitializeQueue(config *env.Config) {
-	queue = &Queue{
-		tasks:         make(chan *QueueItem, config.PipelineQueueSize),
-		alreadyQueued: make(map[string]*QueueItem),
-	}
-
-	go runPipelineQueue(queue)
-}
-
 // SubmitPipeline executes pipelines using the client and returns the result URI.
+// The pipeline is queued using the priority class and is listed in the queue
+// status under the requester, the dataset being worked on, and the operation.
 func SubmitPipeline(client *compute.Client, datasets []string, datasetsProduce []string, searchRequest *pipeline.SearchSolutionsRequest,
-	fullySpecifiedStep *description.FullySpecifiedPipeline, allowedValueTypes []string, shouldCache bool) (string, error) {
+	fullySpecifiedStep *description.FullySpecifiedPipeline, allowedValueTypes []string, shouldCache bool, priority QueuePriority, requester string, operation string) (string, error) {
 
 	request := compute.NewExecPipelineRequest(datasets, datasetsProduce, fullySpecifiedStep.Pipeline)
 
@@ -271,8 +176,7 @@ func SubmitPipeline(client *compute.Client, datasets []string, datasetsProduce [
 		return "", err
 	}
 
-	resultChan := queue.Enqueue(hashedPipelineEquivKey, queueTask)
-	defer queue.Done()
+	resultChan := queue.Enqueue(hashedPipelineEquivKey, queueTask, priority, requester, operation)
 
 	result := <-resultChan
 	if result.Error != nil {
@@ -289,67 +193,61 @@ func SubmitPipeline(client *compute.Client, datasets []string, datasetsProduce [
 	return datasetURI, nil
 }
 
-func runPipelineQueue(queue *Queue) {
+func runPipelineQueue(queue *Queue, worker int) {
 	for {
 		queueTask, ok := queue.Dequeue()
 		if !ok {
 			break
 		}
-		log.Infof("processing data pulled from the queue (key '%s')", queueTask.key)
+		log.Infof("worker %d processing data pulled from the queue (key '%s')", worker, queueTask.key)
 
-		pipelineTask, ok := queueTask.data.(*pipelineQueueTask)
-		if !ok {
-			queueTask.returnResult(&QueueResponse{
-				Error: errors.Errorf("data pulled from queue is not a pipeline"),
-			})
-			continue
-		}
+		queue.complete(queueTask, processPipelineQueueTask(queueTask))
+	}
 
-		err := pipelineTask.request.Dispatch(pipelineTask.client, pipelineTask.searchRequest, pipelineTask.allowedValueTypes)
-		if err != nil {
-			queueTask.returnResult(&QueueResponse{
-				Error: errors.Wrap(err, "unable to dispatch pipeline"),
-			})
-			continue
-		}
+	log.Infof("ending queue processing for worker %d", worker)
+}
 
-		// listen for completion
-		var errPipeline error
-		var datasetURI string
-		err = pipelineTask.request.Listen(func(status compute.ExecPipelineStatus) {
-			// check for error
-			if status.Error != nil {
-				errPipeline = status.Error
-			}
+func processPipelineQueueTask(queueTask *QueueItem) *QueueResponse {
+	pipelineTask, ok := queueTask.data.(*pipelineQueueTask)
+	if !ok {
+		return &QueueResponse{
+			Error: errors.Errorf("data pulled from queue is not a pipeline"),
+		}
+	}
 
-			if status.Progress == compute.RequestCompletedStatus {
-				datasetURI = status.ResultURI
-			}
-		})
-		if err != nil {
-			queueTask.returnResult(&QueueResponse{
-				Error: errors.Wrap(err, "unable to listen to pipeline"),
-			})
-			continue
+	err := pipelineTask.request.Dispatch(pipelineTask.client, pipelineTask.searchRequest, pipelineTask.allowedValueTypes)
+	if err != nil {
+		return &QueueResponse{
+			Error: errors.Wrap(err, "unable to dispatch pipeline"),
 		}
+	}
 
-		if errPipeline != nil {
-			queueTask.returnResult(&QueueResponse{
-				Error: errors.Wrap(errPipeline, "error executing pipeline"),
-			})
-			continue
+	// listen for completion
+	var errPipeline error
+	var datasetURI string
+	err = pipelineTask.request.Listen(func(status compute.ExecPipelineStatus) {
+		// check for error
+		if status.Error != nil {
+			errPipeline = status.Error
 		}
 
-		datasetURI = strings.Replace(datasetURI, "file://", "", -1)
+		if status.Progress == compute.RequestCompletedStatus {
+			datasetURI = status.ResultURI
+		}
+	})
+	if err != nil {
+		return &QueueResponse{
+			Error: errors.Wrap(err, "unable to listen to pipeline"),
+		}
+	}
 
-		queueTask.returnResult(&QueueResponse{Output: datasetURI})
+	if errPipeline != nil {
+		return &QueueResponse{
+			Error: errors.Wrap(errPipeline, "error executing pipeline"),
+		}
 	}
 
-	log.Infof("ending queue processing")
-}
+	datasetURI = strings.Replace(datasetURI, "file://", "", -1)
 
-func (qi *QueueItem) returnResult(response *QueueResponse) {
-	for _, oc := range qi.output {
-		oc <- response
-	}
+	return &QueueResponse{Output: datasetURI}
 }
@@ -0,0 +1,368 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package compute
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/unchartedsoftware/plog"
+
+	"github.com/uncharted-distil/distil/api/env"
+)
+
+// QueuePriority is the priority class of a queued task. Lower values are
+// processed first.
+type QueuePriority int
+
+const (
+	// QueuePriorityInteractive is used for tasks a user is actively waiting on.
+	QueuePriorityInteractive QueuePriority = iota
+	// QueuePriorityDefault is used for tasks with no specific priority.
+	QueuePriorityDefault
+	// QueuePriorityBatch is used for long running tasks such as featurization.
+	QueuePriorityBatch
+
+	// defaultQueueAgingPeriod is how long a task waits before moving up a
+	// priority class.
+	defaultQueueAgingPeriod = 5 * time.Minute
+
+	// QueueStateQueued flags a task waiting for a worker.
+	QueueStateQueued = "queued"
+	// QueueStateInProgress flags a task being processed by a worker.
+	QueueStateInProgress = "in-progress"
+)
+
+var (
+	queuePriorityNames = map[QueuePriority]string{
+		QueuePriorityInteractive: "interactive",
+		QueuePriorityDefault:     "default",
+		QueuePriorityBatch:       "batch",
+	}
+)
+
+// String returns the name of the priority class.
+func (p QueuePriority) String() string {
+	return queuePriorityNames[p]
+}
+
+// QueueItem is the wrapper for the data to process and the response channel.
+type QueueItem struct {
+	key         string
+	output      []chan *QueueResponse
+	data        interface{}
+	priority    QueuePriority
+	requesters  []string
+	operations  []string
+	queuedTime  time.Time
+	startedTime time.Time
+}
+
+// QueueResponse represents the result from processing a queue item.
+type QueueResponse struct {
+	Output interface{}
+	Error  error
+}
+
+// QueueTaskStatus describes a queued or in progress task.
+type QueueTaskStatus struct {
+	Key         string     `json:"key"`
+	State       string     `json:"state"`
+	Priority    string     `json:"priority"`
+	Requesters  []string   `json:"requesters"`
+	Operations  []string   `json:"operations"`
+	Listeners   int        `json:"listeners"`
+	QueuedTime  time.Time  `json:"queuedTime"`
+	StartedTime *time.Time `json:"startedTime,omitempty"`
+	Age         float64    `json:"age"`
+}
+
+// QueueStatus lists the tasks of the queue.
+type QueueStatus struct {
+	Workers    int                `json:"workers"`
+	Capacity   int                `json:"capacity"`
+	InProgress []*QueueTaskStatus `json:"inProgress"`
+	Queued     []*QueueTaskStatus `json:"queued"`
+}
+
+// Queue holds tasks by priority class and hands them out to a pool of
+// workers, providing the result via channels. Tasks with the same key are
+// only processed once. Within a priority class, the requester served least
+// recently goes first so that a single requester cannot hold up the others,
+// and tasks move up a class every aging period so that batch work is not
+// starved by a steady flow of interactive requests.
+type Queue struct {
+	mu            sync.Mutex
+	available     *sync.Cond
+	pending       []*QueueItem
+	alreadyQueued map[string]*QueueItem
+	inProgress    map[string]*QueueItem
+	lastServed    map[string]int
+	served        int
+	agingPeriod   time.Duration
+	capacity      int
+	workers       int
+	closed        bool
+}
+
+// NewQueue creates a queue holding at most capacity waiting tasks.
+func NewQueue(capacity int, workers int) *Queue {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+
+	q := &Queue{
+		alreadyQueued: make(map[string]*QueueItem),
+		inProgress:    make(map[string]*QueueItem),
+		lastServed:    make(map[string]int),
+		agingPeriod:   defaultQueueAgingPeriod,
+		capacity:      capacity,
+		workers:       workers,
+	}
+	q.available = sync.NewCond(&q.mu)
+
+	return q
+}
+
+// Enqueue adds one entry to the queue, providing the response channel as result.
+// The requester is who the task is run for, which is the dataset being worked on,
+// and the operation describes the task. If the key is already queued or in
+// progress, then the data is not added a second time. Rather, a new output
+// channel is added. A queued entry is moved up if the new request has a higher
+// priority. Enqueue blocks while the queue is at capacity.
+func (q *Queue) Enqueue(key string, data interface{}, priority QueuePriority, requester string, operation string) chan *QueueResponse {
+	log.Infof("enqueuing data in the queue")
+	output := make(chan *QueueResponse, 1)
+	if priority < QueuePriorityInteractive || priority > QueuePriorityBatch {
+		priority = QueuePriorityDefault
+	}
+
+	// use key to check if it is already in the queue
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for {
+		queuedItem := q.alreadyQueued[key]
+		if queuedItem != nil {
+			log.Infof("'%s' already in queue so adding one more channel to output", key)
+			queuedItem.output = append(queuedItem.output, output)
+			queuedItem.requesters = append(queuedItem.requesters, requester)
+			queuedItem.operations = append(queuedItem.operations, operation)
+			queuedItem.data = data
+			if priority < queuedItem.priority {
+				queuedItem.priority = priority
+			}
+
+			return output
+		}
+		inProgressItem := q.inProgress[key]
+		if inProgressItem != nil {
+			log.Infof("'%s' already in progress so adding one more channel to output", key)
+			inProgressItem.output = append(inProgressItem.output, output)
+			inProgressItem.requesters = append(inProgressItem.requesters, requester)
+			inProgressItem.operations = append(inProgressItem.operations, operation)
+			inProgressItem.data = data
+
+			return output
+		}
+
+		if len(q.pending) < q.capacity {
+			break
+		}
+		q.available.Wait()
+	}
+
+	log.Infof("'%s' not in queue so creating new item with %s priority", key, priority)
+	item := &QueueItem{
+		key:        key,
+		data:       data,
+		output:     []chan *QueueResponse{output},
+		priority:   priority,
+		requesters: []string{requester},
+		operations: []string{operation},
+		queuedTime: time.Now(),
+	}
+	q.alreadyQueued[key] = item
+	q.pending = append(q.pending, item)
+	q.available.Broadcast()
+
+	return output
+}
+
+// Dequeue removes the next item to process from the queue, waiting until one
+// is available.
+func (q *Queue) Dequeue() (*QueueItem, bool) {
+	log.Infof("dequeuing data from the queue")
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.pending) == 0 {
+		if q.closed {
+			return nil, false
+		}
+		q.available.Wait()
+	}
+
+	item := q.processingOrder(time.Now())[0]
+	q.removePending(item)
+	q.served++
+	for _, requester := range item.requesters {
+		q.lastServed[requester] = q.served
+	}
+
+	delete(q.alreadyQueued, item.key)
+	item.startedTime = time.Now()
+	q.inProgress[item.key] = item
+	q.available.Broadcast()
+
+	return item, true
+}
+
+// Close stops the workers once the queued tasks have been processed.
+func (q *Queue) Close() {
+	q.mu.Lock()
+	q.closed = true
+	q.available.Broadcast()
+	q.mu.Unlock()
+}
+
+// Status lists the tasks in progress and the queued tasks in the order they
+// will be processed.
+func (q *Queue) Status() *QueueStatus {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	status := &QueueStatus{
+		Workers:    q.workers,
+		Capacity:   q.capacity,
+		InProgress: []*QueueTaskStatus{},
+		Queued:     []*QueueTaskStatus{},
+	}
+	for _, item := range q.inProgress {
+		status.InProgress = append(status.InProgress, item.status(QueueStateInProgress, now))
+	}
+	sort.Slice(status.InProgress, func(i, j int) bool {
+		return status.InProgress[i].QueuedTime.Before(status.InProgress[j].QueuedTime)
+	})
+	for _, item := range q.processingOrder(now) {
+		status.Queued = append(status.Queued, item.status(QueueStateQueued, now))
+	}
+
+	return status
+}
+
+// complete sends the response to every listener of the item and removes it
+// from the in progress tasks. This is done under lock so that a request for
+// the same key either receives this response or queues a new item.
+func (q *Queue) complete(item *QueueItem, response *QueueResponse) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.inProgress, item.key)
+	for _, oc := range item.output {
+		oc <- response
+	}
+}
+
+// processingOrder sorts the pending items by their aged priority class, then by
+// how recently their requesters were served and finally by age.
+func (q *Queue) processingOrder(now time.Time) []*QueueItem {
+	items := append([]*QueueItem{}, q.pending...)
+	sort.SliceStable(items, func(i, j int) bool {
+		pi := items[i].agedPriority(now, q.agingPeriod)
+		pj := items[j].agedPriority(now, q.agingPeriod)
+		if pi != pj {
+			return pi < pj
+		}
+		si := q.requestersServed(items[i])
+		sj := q.requestersServed(items[j])
+		if si != sj {
+			return si < sj
+		}
+		return items[i].queuedTime.Before(items[j].queuedTime)
+	})
+
+	return items
+}
+
+// requestersServed returns when the least recently served requester of the
+// item was last served.
+func (q *Queue) requestersServed(item *QueueItem) int {
+	served := q.served
+	for _, requester := range item.requesters {
+		if q.lastServed[requester] < served {
+			served = q.lastServed[requester]
+		}
+	}
+	return served
+}
+
+func (q *Queue) removePending(item *QueueItem) {
+	for i, pendingItem := range q.pending {
+		if pendingItem == item {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// agedPriority moves the priority class of the item up one class for every
+// aging period it has been waiting.
+func (qi *QueueItem) agedPriority(now time.Time, agingPeriod time.Duration) QueuePriority {
+	priority := qi.priority - QueuePriority(now.Sub(qi.queuedTime)/agingPeriod)
+	if priority < QueuePriorityInteractive {
+		priority = QueuePriorityInteractive
+	}
+	return priority
+}
+
+func (qi *QueueItem) status(state string, now time.Time) *QueueTaskStatus {
+	status := &QueueTaskStatus{
+		Key:        qi.key,
+		State:      state,
+		Priority:   qi.priority.String(),
+		Requesters: append([]string{}, qi.requesters...),
+		Operations: append([]string{}, qi.operations...),
+		Listeners:  len(qi.output),
+		QueuedTime: qi.queuedTime,
+		Age:        now.Sub(qi.queuedTime).Seconds(),
+	}
+	if !qi.startedTime.IsZero() {
+		startedTime := qi.startedTime
+		status.StartedTime = &startedTime
+	}
+
+	return status
+}
+
+// InitializeQueue creates the pipeline queue and runs the pool of workers
+// processing pipeline requests.
+func InitializeQueue(config *env.Config) {
+	queue = NewQueue(config.PipelineQueueSize, config.PipelineQueueWorkers)
+
+	for i := 0; i < queue.workers; i++ {
+		go runPipelineQueue(queue, i)
+	}
+}
+
+// GetQueueStatus returns the status of the pipeline queue.
+func GetQueueStatus() *QueueStatus {
+	return queue.Status()
+}
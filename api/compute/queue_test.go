@@ -0,0 +1,105 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package compute
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueuePriority(t *testing.T) {
+	q := NewQueue(10, 1)
+
+	batch := q.Enqueue("featurize", 1, QueuePriorityBatch, "dataset-a", "featurize")
+	q.Enqueue("summarize", 2, QueuePriorityBatch, "dataset-a", "summarize")
+	interactive := q.Enqueue("clustering", 3, QueuePriorityInteractive, "dataset-a", "clustering")
+
+	// equivalent requests are merged, with the most urgent priority kept
+	duplicate := q.Enqueue("summarize", 4, QueuePriorityInteractive, "dataset-b", "outlier-detection")
+
+	status := q.Status()
+	assert.Equal(t, 3, len(status.Queued))
+	assert.Equal(t, "summarize", status.Queued[0].Key)
+	assert.Equal(t, []string{"dataset-a", "dataset-b"}, status.Queued[0].Requesters)
+	assert.Equal(t, []string{"summarize", "outlier-detection"}, status.Queued[0].Operations)
+	assert.Equal(t, "clustering", status.Queued[1].Key)
+	assert.Equal(t, "featurize", status.Queued[2].Key)
+
+	item, ok := q.Dequeue()
+	assert.True(t, ok)
+	assert.Equal(t, "summarize", item.key)
+	assert.Equal(t, 4, item.data)
+
+	// requests for an in progress key share its result
+	shared := q.Enqueue("summarize", 5, QueuePriorityDefault, "dataset-a", "summarize")
+	status = q.Status()
+	assert.Equal(t, 1, len(status.InProgress))
+	assert.Equal(t, 3, status.InProgress[0].Listeners)
+	assert.Equal(t, 2, len(status.Queued))
+
+	q.complete(item, &QueueResponse{Output: "summarized"})
+	assert.Equal(t, "summarized", (<-duplicate).Output)
+	assert.Equal(t, "summarized", (<-shared).Output)
+
+	item, _ = q.Dequeue()
+	assert.Equal(t, "clustering", item.key)
+	q.complete(item, &QueueResponse{Output: "done"})
+	assert.Equal(t, "done", (<-interactive).Output)
+
+	item, _ = q.Dequeue()
+	assert.Equal(t, "featurize", item.key)
+	q.complete(item, &QueueResponse{Output: "featurized"})
+	assert.Equal(t, "featurized", (<-batch).Output)
+
+	q.Close()
+	_, ok = q.Dequeue()
+	assert.False(t, ok)
+}
+
+func TestQueueFairness(t *testing.T) {
+	q := NewQueue(10, 1)
+
+	// the requester served least recently goes first within a priority class
+	q.Enqueue("cluster-a-1", 1, QueuePriorityInteractive, "dataset-a", "clustering")
+	q.Enqueue("cluster-a-2", 2, QueuePriorityInteractive, "dataset-a", "clustering")
+	q.Enqueue("cluster-b-1", 3, QueuePriorityInteractive, "dataset-b", "clustering")
+
+	keys := []string{}
+	for i := 0; i < 3; i++ {
+		item, _ := q.Dequeue()
+		keys = append(keys, item.key)
+		q.complete(item, &QueueResponse{})
+	}
+	assert.Equal(t, []string{"cluster-a-1", "cluster-b-1", "cluster-a-2"}, keys)
+}
+
+func TestQueueAging(t *testing.T) {
+	q := NewQueue(10, 1)
+	q.agingPeriod = time.Minute
+
+	q.Enqueue("featurize", 1, QueuePriorityBatch, "dataset-a", "featurize")
+	q.Enqueue("clustering", 2, QueuePriorityInteractive, "dataset-b", "clustering")
+	assert.Equal(t, "clustering", q.Status().Queued[0].Key)
+
+	// batch work waiting long enough reaches the interactive class
+	q.pending[1].queuedTime = time.Now().Add(-time.Second)
+	q.pending[0].queuedTime = time.Now().Add(-2 * time.Minute)
+	assert.Equal(t, QueuePriorityInteractive, q.pending[0].agedPriority(time.Now(), q.agingPeriod))
+	item, _ := q.Dequeue()
+	assert.Equal(t, "featurize", item.key)
+}
@@ -43,6 +43,7 @@ type pipelineSearchContext struct {
 	sourceDatasetURI  string
 	trainDatasetURI   string
 	testDatasetURI    string
+	foldURIs          []string
 	produceDatasetURI string
 	variables         []*model.Variable
 	targetCol         int
@@ -212,31 +213,16 @@ func (s *SolutionRequest) dispatchSolutionSearchPipeline(statusChan chan Solutio
 
 	s.persistSolutionStatus(statusChan, solutionStorage, searchContext.searchID, searchSolutionID, compute.SolutionScoringStatus)
 
-	// score solution
-	solutionScoreResponses, err := client.GenerateSolutionScores(cancelContext, searchSolutionID, searchContext.testDatasetURI, s.Metrics, s.PosLabel)
+	// score solution, either on the holdout split or on each cross validation fold
+	if len(searchContext.foldURIs) > 0 {
+		err = s.persistCrossValidationScores(cancelContext, client, solutionStorage, searchSolutionID, searchContext.foldURIs)
+	} else {
+		err = s.persistHoldoutScores(cancelContext, client, solutionStorage, searchSolutionID, searchContext.testDatasetURI)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	// persist the scores
-	for _, response := range solutionScoreResponses {
-		// only persist scores from COMPLETED responses
-		if response.Progress.State == pipeline.ProgressState_COMPLETED {
-			for _, score := range response.Scores {
-				metric := ""
-				if score.GetMetric() == nil {
-					metric = compute.ConvertMetricsFromTA3ToTA2(s.Metrics, s.PosLabel)[0].GetMetric()
-				} else {
-					metric = score.Metric.Metric
-				}
-				err := solutionStorage.PersistSolutionScore(searchSolutionID, metric, score.Value.GetRaw().GetDouble())
-				if err != nil {
-					return nil, err
-				}
-			}
-		}
-	}
-
 	// persist solution running status
 	s.persistSolutionStatus(statusChan, solutionStorage, searchContext.searchID, searchSolutionID, compute.SolutionProducingStatus)
 
@@ -307,3 +293,86 @@ func (s *SolutionRequest) dispatchSolutionSearchPipeline(statusChan chan Solutio
 		fittedSolutionID: fittedSolutionID,
 	}, nil
 }
+
+type metricScore struct {
+	metric string
+	score  float64
+}
+
+func (s *SolutionRequest) generateScores(ctx context.Context, client *compute.Client, searchSolutionID string, datasetURI string) ([]*metricScore, error) {
+	solutionScoreResponses, err := client.GenerateSolutionScores(ctx, searchSolutionID, datasetURI, s.Metrics, s.PosLabel)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := []*metricScore{}
+	for _, response := range solutionScoreResponses {
+		// only use scores from COMPLETED responses
+		if response.Progress.State == pipeline.ProgressState_COMPLETED {
+			for _, score := range response.Scores {
+				metric := ""
+				if score.GetMetric() == nil {
+					metric = compute.ConvertMetricsFromTA3ToTA2(s.Metrics, s.PosLabel)[0].GetMetric()
+				} else {
+					metric = score.Metric.Metric
+				}
+				scores = append(scores, &metricScore{
+					metric: metric,
+					score:  score.Value.GetRaw().GetDouble(),
+				})
+			}
+		}
+	}
+
+	return scores, nil
+}
+
+func (s *SolutionRequest) persistHoldoutScores(ctx context.Context, client *compute.Client, solutionStorage api.SolutionStorage,
+	searchSolutionID string, testDatasetURI string) error {
+	scores, err := s.generateScores(ctx, client, searchSolutionID, testDatasetURI)
+	if err != nil {
+		return err
+	}
+
+	// persist the scores
+	for _, score := range scores {
+		err := solutionStorage.PersistSolutionScore(searchSolutionID, score.metric, score.score)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *SolutionRequest) persistCrossValidationScores(ctx context.Context, client *compute.Client, solutionStorage api.SolutionStorage,
+	searchSolutionID string, foldURIs []string) error {
+	// score every fold through the same scoring request as the holdout split,
+	// keeping the metrics in the order they were first reported
+	metrics := []string{}
+	foldScores := map[string][]float64{}
+	for i, foldURI := range foldURIs {
+		log.Infof("scoring solution '%s' on fold %d of %d", searchSolutionID, i+1, len(foldURIs))
+		scores, err := s.generateScores(ctx, client, searchSolutionID, foldURI)
+		if err != nil {
+			return err
+		}
+		for _, score := range scores {
+			if foldScores[score.metric] == nil {
+				metrics = append(metrics, score.metric)
+			}
+			foldScores[score.metric] = append(foldScores[score.metric], score.score)
+		}
+	}
+
+	// persist the mean and standard deviation across folds
+	for _, metric := range metrics {
+		mean, stdDev := aggregateFoldScores(foldScores[metric])
+		err := solutionStorage.PersistSolutionCrossValidationScore(searchSolutionID, metric, mean, stdDev, len(foldScores[metric]))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -70,6 +70,7 @@ type PredictionResult struct {
 
 // SolutionRequest represents a solution search request.
 type SolutionRequest struct {
+	RequestID            string
 	Dataset              string
 	DatasetMetadata      *api.Dataset
 	TargetFeature        *model.Variable
@@ -84,6 +85,9 @@ type SolutionRequest struct {
 	Filters              *api.FilterParams
 	DatasetAugmentations []*model.DatasetOrigin
 	TrainTestSplit       float64
+	SplitRequestID       string
+	CrossValidation      string
+	Folds                int
 	CancelFuncs          map[string]context.CancelFunc
 	PosLabel             string
 	mu                   *sync.Mutex
@@ -134,6 +138,12 @@ func NewSolutionRequest(variables []*model.Variable, data []byte) (*SolutionRequ
 	req.Metrics, _ = json.StringArray(j, "metrics")
 	req.TrainTestSplit = json.FloatDefault(j, 0.9, "trainTestSplit")
 	req.TimestampSplitValue = json.FloatDefault(j, 0.0, "timestampSplitValue")
+	req.SplitRequestID = json.StringDefault(j, "", "splitRequestId")
+	req.CrossValidation = json.StringDefault(j, "", "crossValidation")
+	req.Folds = json.IntDefault(j, defaultFolds, "folds")
+	if req.SplitRequestID != "" && req.CrossValidation != "" {
+		return nil, fmt.Errorf("`splitRequestId` and `crossValidation` cannot both be set in a solution request")
+	}
 	posLabel, ok := json.String(j, "positiveLabel")
 	if ok {
 		req.PosLabel = posLabel
@@ -753,13 +763,48 @@ func (s *SolutionRequest) PersistAndDispatch(client *compute.Client, solutionSto
 
 	// when dealing with categorical data we want to stratify
 	stratify := model.IsCategorical(s.TargetFeature.Type)
-	// create the splitter to use for the train / test split
-	splitter := createSplitter(s.Task, targetVariable.Index, groupingVariableIndex, stratify, s.Quality, s.TrainTestSplit, s.TimestampSplitValue)
-	datasetPathTrain, datasetPathTest, err := SplitDataset(path.Join(filteredDatasetPath, compute.D3MDataSchema), splitter)
+	// create the splitter to use for the train / test split, reusing the exact
+	// split of a previous request if one was specified
+	var splitter datasetSplitter
+	if s.SplitRequestID != "" {
+		sourceManifest, err := solutionStorage.FetchRequestSplitManifest(s.SplitRequestID)
+		if err != nil {
+			return err
+		}
+		if sourceManifest.Dataset != dataset.ID {
+			return errors.Errorf("split of request '%s' is of dataset '%s' and cannot be used for dataset '%s'",
+				s.SplitRequestID, sourceManifest.Dataset, dataset.ID)
+		}
+		splitter = newManifestSplitter(sourceManifest)
+	} else if s.CrossValidation != "" {
+		splitter, err = createCrossValidationSplitter(s.CrossValidation, s.Task, s.Folds, targetVariable.Index, groupingVariableIndex)
+		if err != nil {
+			return err
+		}
+	} else {
+		splitter = createSplitter(s.Task, targetVariable.Index, groupingVariableIndex, stratify, s.Quality, s.TrainTestSplit, s.TimestampSplitValue)
+	}
+	datasetPathTrain, datasetPathTest, splitManifest, err := SplitDataset(path.Join(filteredDatasetPath, compute.D3MDataSchema), splitter)
 	if err != nil {
 		return err
 	}
 
+	// cross validation scores the solutions on the test partition of every fold
+	foldURIs := []string{}
+	if fs, ok := splitter.(foldedSplitter); ok {
+		datasetFolds, err := SplitDatasetFolds(path.Join(filteredDatasetPath, compute.D3MDataSchema), fs)
+		if err != nil {
+			return err
+		}
+		for _, fold := range datasetFolds {
+			foldPathTest, err := filepath.Abs(fold.TestSchemaFile)
+			if err != nil {
+				return err
+			}
+			foldURIs = append(foldURIs, fmt.Sprintf("file://%s", foldPathTest))
+		}
+	}
+
 	// make sure the path is absolute and contains the URI prefix
 	datasetPathTrain, err = filepath.Abs(datasetPathTrain)
 	if err != nil {
@@ -804,6 +849,8 @@ func (s *SolutionRequest) PersistAndDispatch(client *compute.Client, solutionSto
 		return err
 	}
 
+	s.RequestID = requestID
+
 	// persist the request
 	err = s.persistRequestStatus(s.requestChannel, solutionStorage, requestID, dataset.ID, compute.RequestPendingStatus)
 	if err != nil {
@@ -841,6 +888,14 @@ func (s *SolutionRequest) PersistAndDispatch(client *compute.Client, solutionSto
 		return err
 	}
 
+	// store the split manifest so the search can be rerun against the same split
+	splitManifest.RequestID = requestID
+	splitManifest.Dataset = dataset.ID
+	err = solutionStorage.PersistRequestSplitManifest(requestID, splitManifest)
+	if err != nil {
+		return err
+	}
+
 	// dispatch search request
 	searchContext := pipelineSearchContext{
 		searchID:          requestID,
@@ -849,6 +904,7 @@ func (s *SolutionRequest) PersistAndDispatch(client *compute.Client, solutionSto
 		sourceDatasetURI:  datasetInputDir,
 		trainDatasetURI:   datasetPathTrain,
 		testDatasetURI:    datasetPathTest,
+		foldURIs:          foldURIs,
 		produceDatasetURI: datasetPathTest,
 		variables:         dataVariables,
 		targetCol:         s.TargetFeature.Index,
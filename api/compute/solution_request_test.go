@@ -0,0 +1,35 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package compute
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uncharted-distil/distil-compute/model"
+)
+
+func TestNewSolutionRequestSplit(t *testing.T) {
+	variables := []*model.Variable{{Key: "species", Type: model.CategoricalType}}
+
+	request, err := NewSolutionRequest(variables, []byte(`{"dataset": "flowers", "target": "species", "splitRequestId": "request-0"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "request-0", request.SplitRequestID)
+
+	// a previous split cannot be reused as cross validation folds
+	_, err = NewSolutionRequest(variables, []byte(`{"dataset": "flowers", "target": "species", "splitRequestId": "request-0", "crossValidation": "kfold"}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,291 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package compute
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/primitive/compute"
+	log "github.com/unchartedsoftware/plog"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+const (
+	// subscriberBufferSize is the number of status updates a subscriber can fall
+	// behind by before it is dropped.
+	subscriberBufferSize = 256
+)
+
+var (
+	// shared map of running searches - status updates are handled under separate
+	// go routines so it needs to be locked
+	searches = struct {
+		sync.RWMutex
+		m map[string]*SolutionSearch
+	}{
+		m: map[string]*SolutionSearch{},
+	}
+)
+
+// SolutionSearch is a solution request running as a server side job.  Its status
+// updates are persisted as they arrive, which allows clients to come and go while
+// the search runs without missing any of them.
+type SolutionSearch struct {
+	Request         *SolutionRequest
+	solutionStorage api.SolutionStorage
+	mu              *sync.Mutex
+	subscribers     map[int]*solutionSubscriber
+	nextListenerID  int
+	persistedCount  int
+	done            chan struct{}
+}
+
+// solutionSubscriber forwards status updates to a listener from its own go routine
+// so that a slow listener never holds up the search or the other listeners.
+type solutionSubscriber struct {
+	updates  chan SolutionStatus
+	stop     chan struct{}
+	stopOnce sync.Once
+	drained  chan struct{}
+	dropped  bool
+}
+
+// StartSolutionSearch persists and dispatches the solution request, and handles its
+// status updates in the background until the search completes.
+func StartSolutionSearch(request *SolutionRequest, client *compute.Client, solutionStorage api.SolutionStorage,
+	metaStorage api.MetadataStorage, dataStorage api.DataStorage) (*SolutionSearch, error) {
+	err := request.PersistAndDispatch(client, solutionStorage, metaStorage, dataStorage)
+	if err != nil {
+		return nil, err
+	}
+
+	search := &SolutionSearch{
+		Request:         request,
+		solutionStorage: solutionStorage,
+		mu:              &sync.Mutex{},
+		subscribers:     map[int]*solutionSubscriber{},
+		done:            make(chan struct{}),
+	}
+	searches.Lock()
+	searches.m[request.RequestID] = search
+	searches.Unlock()
+
+	go func() {
+		// downstream errors come through as status updates, this only fails when
+		// the request could not be started
+		err := request.Listen(search.handleStatus)
+		if err != nil {
+			log.Errorf("solution search %s failed: %+v", request.RequestID, err)
+		}
+	}()
+
+	return search, nil
+}
+
+// GetSolutionSearch returns the running search for a request ID, or nil if the
+// search is not running.
+func GetSolutionSearch(requestID string) *SolutionSearch {
+	searches.RLock()
+	defer searches.RUnlock()
+	return searches.m[requestID]
+}
+
+// SubscribeSolutionSearch replays the persisted status updates of a search to the
+// listener and then forwards live updates until the search completes.  The returned
+// channel is closed once the search is complete and every update has reached the
+// listener, and the returned function stops any further updates from reaching the
+// listener.  A listener that falls too far behind is dropped, in which case it
+// misses the remaining updates but the channel is still closed on completion.
+func SubscribeSolutionSearch(solutionStorage api.SolutionStorage, requestID string,
+	listener SolutionStatusListener) (<-chan struct{}, func(), error) {
+	search := GetSolutionSearch(requestID)
+	if search != nil {
+		return search.subscribe(listener)
+	}
+
+	// the search is no longer running so all that is left is the replay
+	events, err := solutionStorage.FetchSolutionStatusEvents(requestID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(events) == 0 {
+		return nil, nil, errors.Errorf("no status found for solution request '%s'", requestID)
+	}
+	for _, event := range events {
+		listener(newSolutionStatus(event))
+	}
+
+	done := make(chan struct{})
+	close(done)
+	return done, func() {}, nil
+}
+
+// Done returns a channel that is closed when the search is complete.
+func (s *SolutionSearch) Done() <-chan struct{} {
+	return s.done
+}
+
+// Cancel stops any pending work of the search.
+func (s *SolutionSearch) Cancel() {
+	s.Request.Cancel()
+}
+
+func (s *SolutionSearch) subscribe(listener SolutionStatusListener) (<-chan struct{}, func(), error) {
+	// only the updates already forwarded are replayed, the others reach the
+	// subscriber live, so that no update is missed or sent twice
+	s.mu.Lock()
+	events, err := s.solutionStorage.FetchSolutionStatusEvents(s.Request.RequestID)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, nil, err
+	}
+	if len(events) > s.persistedCount {
+		events = events[:s.persistedCount]
+	}
+
+	sub := &solutionSubscriber{
+		updates: make(chan SolutionStatus, subscriberBufferSize),
+		stop:    make(chan struct{}),
+		drained: make(chan struct{}),
+	}
+	id := s.nextListenerID
+	s.nextListenerID++
+	select {
+	case <-s.done:
+		// the search completed while the events were fetched
+		close(sub.updates)
+	default:
+		s.subscribers[id] = sub
+	}
+	s.mu.Unlock()
+
+	go sub.run(listener, events, s.done)
+
+	return sub.drained, func() {
+		s.mu.Lock()
+		if s.subscribers[id] == sub {
+			delete(s.subscribers, id)
+			close(sub.updates)
+		}
+		s.mu.Unlock()
+
+		// wait for any listener call in progress to return
+		sub.stopOnce.Do(func() { close(sub.stop) })
+		<-sub.drained
+	}, nil
+}
+
+func (s *SolutionSearch) handleStatus(status SolutionStatus) {
+	event := &api.SolutionStatusEvent{
+		RequestID:   status.RequestID,
+		SolutionID:  status.SolutionID,
+		ResultID:    status.ResultID,
+		Progress:    status.Progress,
+		CreatedTime: status.Timestamp,
+	}
+	if status.Error != nil {
+		event.Error = status.Error.Error()
+	}
+	err := s.solutionStorage.PersistSolutionStatusEvent(event)
+	if err != nil {
+		log.Warnf("unable to persist status of solution request %s: %+v", status.RequestID, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err == nil {
+		s.persistedCount++
+	}
+	for id, sub := range s.subscribers {
+		select {
+		case sub.updates <- status:
+		default:
+			log.Warnf("dropping slow subscriber of solution request %s", status.RequestID)
+			sub.dropped = true
+			delete(s.subscribers, id)
+			close(sub.updates)
+		}
+	}
+
+	// the search is complete once the request completed normally or errored - note that
+	// normally can include a cancellation, as some pipelines may have completed successfully
+	if status.SolutionID == "" && (status.Progress == compute.RequestCompletedStatus || status.Progress == compute.RequestErroredStatus) {
+		searches.Lock()
+		delete(searches.m, s.Request.RequestID)
+		searches.Unlock()
+
+		for _, sub := range s.subscribers {
+			close(sub.updates)
+		}
+		s.subscribers = map[int]*solutionSubscriber{}
+		select {
+		case <-s.done:
+		default:
+			close(s.done)
+		}
+	}
+}
+
+// run replays the events to the listener and then forwards the updates until they
+// are closed or the subscriber is stopped.
+func (sub *solutionSubscriber) run(listener SolutionStatusListener, events []*api.SolutionStatusEvent, searchDone <-chan struct{}) {
+	defer close(sub.drained)
+
+	for _, event := range events {
+		select {
+		case <-sub.stop:
+			return
+		default:
+			listener(newSolutionStatus(event))
+		}
+	}
+
+	for {
+		select {
+		case <-sub.stop:
+			return
+		case status, ok := <-sub.updates:
+			if !ok {
+				// a dropped subscriber still only completes with the search
+				if sub.dropped {
+					select {
+					case <-searchDone:
+					case <-sub.stop:
+					}
+				}
+				return
+			}
+			listener(status)
+		}
+	}
+}
+
+func newSolutionStatus(event *api.SolutionStatusEvent) SolutionStatus {
+	status := SolutionStatus{
+		RequestID:  event.RequestID,
+		SolutionID: event.SolutionID,
+		ResultID:   event.ResultID,
+		Progress:   event.Progress,
+		Timestamp:  event.CreatedTime,
+	}
+	if event.Error != "" {
+		status.Error = errors.New(event.Error)
+	}
+	return status
+}
@@ -0,0 +1,89 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package compute
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uncharted-distil/distil-compute/primitive/compute"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+type testStatusStorage struct {
+	api.SolutionStorage
+	mu     sync.Mutex
+	events []*api.SolutionStatusEvent
+}
+
+func (s *testStatusStorage) PersistSolutionStatusEvent(event *api.SolutionStatusEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *testStatusStorage) FetchSolutionStatusEvents(requestID string) ([]*api.SolutionStatusEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*api.SolutionStatusEvent{}, s.events...), nil
+}
+
+func TestSolutionSearchSubscribers(t *testing.T) {
+	search := &SolutionSearch{
+		Request:         &SolutionRequest{RequestID: "request-0"},
+		solutionStorage: &testStatusStorage{},
+		mu:              &sync.Mutex{},
+		subscribers:     map[int]*solutionSubscriber{},
+		done:            make(chan struct{}),
+	}
+	search.handleStatus(SolutionStatus{RequestID: "request-0", Progress: "status-0"})
+
+	// a subscriber gets the replay followed by the live updates, in order
+	received := make(chan string)
+	done, unsubscribe, err := search.subscribe(func(status SolutionStatus) {
+		received <- status.Progress
+	})
+	assert.NoError(t, err)
+	defer unsubscribe()
+	assert.Equal(t, "status-0", <-received)
+
+	// a subscriber that stops reading is dropped without holding up the search
+	release := make(chan struct{})
+	slowDone, slowUnsubscribe, err := search.subscribe(func(status SolutionStatus) {
+		<-release
+	})
+	assert.NoError(t, err)
+
+	for i := 1; i <= subscriberBufferSize+1; i++ {
+		progress := fmt.Sprintf("status-%d", i)
+		search.handleStatus(SolutionStatus{RequestID: "request-0", SolutionID: "solution-0", Progress: progress})
+		assert.Equal(t, progress, <-received)
+	}
+	assert.Len(t, search.subscribers, 1)
+	search.handleStatus(SolutionStatus{RequestID: "request-0", Progress: compute.RequestCompletedStatus})
+	assert.Equal(t, compute.RequestCompletedStatus, <-received)
+
+	<-done
+	assert.Empty(t, search.subscribers)
+
+	close(release)
+	slowUnsubscribe()
+	<-slowDone
+}
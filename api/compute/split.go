@@ -17,9 +17,11 @@ package compute
 
 import (
 	"math"
+	"math/rand"
 	"os"
 	"path"
 	"sort"
+	"time"
 
 	"github.com/mitchellh/hashstructure"
 	"github.com/pkg/errors"
@@ -29,13 +31,16 @@ import (
 	"github.com/uncharted-distil/distil-compute/model"
 	"github.com/uncharted-distil/distil-compute/primitive/compute"
 	"github.com/uncharted-distil/distil/api/env"
+	api "github.com/uncharted-distil/distil/api/model"
 	"github.com/uncharted-distil/distil/api/serialization"
 	"github.com/uncharted-distil/distil/api/util"
 )
 
 type datasetSplitter interface {
-	split(data [][]string) ([][]string, [][]string, error)
+	split(data [][]string, rng *rand.Rand) ([][]string, [][]string, error)
 	hash(schemaFile string, params ...interface{}) (uint64, error)
+	splitType() string
+	ratio() float64
 }
 
 type datasetSampler interface {
@@ -67,6 +72,10 @@ type semiSupervisedSplitter struct {
 	splitter *stratifiedSplitter
 }
 
+type manifestSplitter struct {
+	manifest *api.SplitManifest
+}
+
 func (t *timeseriesSplitter) hash(schemaFile string, params ...interface{}) (uint64, error) {
 	// generate the hash from the params
 	hashStruct := struct {
@@ -120,7 +129,15 @@ func (t *timeseriesSplitter) getSplitTime(data [][]string) (float64, error) {
 	return timestampSplit.SplitValue, nil
 }
 
-func (t *timeseriesSplitter) split(data [][]string) ([][]string, [][]string, error) {
+func (t *timeseriesSplitter) splitType() string {
+	return api.SplitTypeTimeseries
+}
+
+func (t *timeseriesSplitter) ratio() float64 {
+	return t.trainTestSplit
+}
+
+func (t *timeseriesSplitter) split(data [][]string, rng *rand.Rand) ([][]string, [][]string, error) {
 	// training data
 	outputTrain := [][]string{}
 
@@ -179,7 +196,15 @@ func (b *basicSplitter) hash(schemaFile string, params ...interface{}) (uint64,
 	return hash, nil
 }
 
-func (b *basicSplitter) split(data [][]string) ([][]string, [][]string, error) {
+func (b *basicSplitter) splitType() string {
+	return api.SplitTypeBasic
+}
+
+func (b *basicSplitter) ratio() float64 {
+	return b.trainTestSplit
+}
+
+func (b *basicSplitter) split(data [][]string, rng *rand.Rand) ([][]string, [][]string, error) {
 	// create the output
 	outputTrain := [][]string{}
 	outputTest := [][]string{}
@@ -192,14 +217,14 @@ func (b *basicSplitter) split(data [][]string) ([][]string, [][]string, error) {
 	numTestRows := b.rowLimits.testRows(numDatasetRows)
 
 	// randomly select from dataset based on row limits
-	outputTrain, outputTest = shuffleAndWrite(inputData, b.groupingCol, numTrainingRows, numTestRows, true, outputTrain, outputTest, b.trainTestSplit)
+	outputTrain, outputTest = shuffleAndWrite(rng, inputData, b.groupingCol, numTrainingRows, numTestRows, true, outputTrain, outputTest, b.trainTestSplit)
 
 	return outputTrain, outputTest, nil
 }
 
 func (b *basicSplitter) sample(data [][]string, maxRows int) [][]string {
 	output := [][]string{}
-	output, _ = shuffleAndWrite(data[1:], -1, maxRows, 0, false, output, nil, float64(1))
+	output, _ = shuffleAndWrite(newSeededRand(time.Now().UnixNano()), data[1:], -1, maxRows, 0, false, output, nil, float64(1))
 
 	return output
 }
@@ -241,11 +266,12 @@ func (s *stratifiedSplitter) sample(data [][]string, maxRows int) [][]string {
 	categoryRowData := s.splitCategories(s.targetCol, data[1:])
 
 	// second pass - randomly sample each category to generate train/test split
+	rng := newSeededRand(time.Now().UnixNano())
 	totalRows := len(data) - 1
 	for _, catData := range categoryRowData {
 		// split max rows by category
 		maxRowsCat := int(math.Max(1, float64(len(catData))/float64(totalRows)*float64(maxRows)))
-		output, _ = shuffleAndWrite(catData, -1, maxRowsCat, 0, false, output, nil, 1.0)
+		output, _ = shuffleAndWrite(rng, catData, -1, maxRowsCat, 0, false, output, nil, 1.0)
 	}
 
 	return output
@@ -264,7 +290,27 @@ func (s *stratifiedSplitter) splitCategories(colIndex int, data [][]string) map[
 	return categoryRowData
 }
 
-func (s *stratifiedSplitter) split(data [][]string) ([][]string, [][]string, error) {
+// sortedCategories returns the category keys in a stable order so that a seeded
+// shuffle always produces the same split.
+func sortedCategories(categoryRowData map[string][][]string) []string {
+	categories := make([]string, 0, len(categoryRowData))
+	for category := range categoryRowData {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	return categories
+}
+
+func (s *stratifiedSplitter) splitType() string {
+	return api.SplitTypeStratified
+}
+
+func (s *stratifiedSplitter) ratio() float64 {
+	return s.trainTestSplit
+}
+
+func (s *stratifiedSplitter) split(data [][]string, rng *rand.Rand) ([][]string, [][]string, error) {
 	log.Infof("performing split based on stratified sampling")
 	// create the output
 	outputTrain := [][]string{}
@@ -285,10 +331,11 @@ func (s *stratifiedSplitter) split(data [][]string) ([][]string, [][]string, err
 
 	// second pass - randomly sample each category to generate train/test split
 	log.Infof("shuffling and splitting based on %d categories", len(categoryRowData))
-	for _, data := range categoryRowData {
+	for _, category := range sortedCategories(categoryRowData) {
+		data := categoryRowData[category]
 		maxCategoryTrainingRows := int(math.Max(1, float64(len(data))/float64(len(inputData))*float64(numTrainingRows)))
 		maxCategoryTestRows := int(math.Max(1, float64(len(data))/float64(len(inputData))*float64(numTestRows)))
-		outputTrain, outputTest = shuffleAndWrite(data, s.groupingCol, maxCategoryTrainingRows, maxCategoryTestRows, true, outputTrain, outputTest, s.trainTestSplit)
+		outputTrain, outputTest = shuffleAndWrite(rng, data, s.groupingCol, maxCategoryTrainingRows, maxCategoryTestRows, true, outputTrain, outputTest, s.trainTestSplit)
 	}
 
 	return outputTrain, outputTest, nil
@@ -330,7 +377,15 @@ func (s *semiSupervisedSplitter) splitCategories(colIndex int, data [][]string)
 	return s.splitter.splitCategories(colIndex, data)
 }
 
-func (s *semiSupervisedSplitter) split(data [][]string) ([][]string, [][]string, error) {
+func (s *semiSupervisedSplitter) splitType() string {
+	return api.SplitTypeSemiSupervised
+}
+
+func (s *semiSupervisedSplitter) ratio() float64 {
+	return s.splitter.trainTestSplit
+}
+
+func (s *semiSupervisedSplitter) split(data [][]string, rng *rand.Rand) ([][]string, [][]string, error) {
 	log.Infof("performing split for semi supervised based on stratified sampling")
 	// create the output
 	outputTrain := [][]string{}
@@ -351,7 +406,8 @@ func (s *semiSupervisedSplitter) split(data [][]string) ([][]string, [][]string,
 
 	// second pass - randomly sample each category to generate train/test split
 	log.Infof("shuffling and splitting based on %d categories", len(categoryRowData))
-	for target, data := range categoryRowData {
+	for _, target := range sortedCategories(categoryRowData) {
+		data := categoryRowData[target]
 		maxCategoryTrainingRows := int(math.Max(1, float64(len(data))/float64(len(inputData))*float64(numTrainingRows)))
 		maxCategoryTestRows := int(math.Max(1, float64(len(data))/float64(len(inputData))*float64(numTestRows)))
 
@@ -360,97 +416,250 @@ func (s *semiSupervisedSplitter) split(data [][]string) ([][]string, [][]string,
 			maxCategoryTestRows = 0
 			maxCategoryTrainingRows = len(data)
 		}
-		outputTrain, outputTest = shuffleAndWrite(data, s.splitter.groupingCol, maxCategoryTrainingRows, maxCategoryTestRows, true, outputTrain, outputTest, s.splitter.trainTestSplit)
+		outputTrain, outputTest = shuffleAndWrite(rng, data, s.splitter.groupingCol, maxCategoryTrainingRows, maxCategoryTestRows, true, outputTrain, outputTest, s.splitter.trainTestSplit)
+	}
+
+	return outputTrain, outputTest, nil
+}
+
+func (m *manifestSplitter) hash(schemaFile string, params ...interface{}) (uint64, error) {
+	// generate the hash from the params
+	hashStruct := struct {
+		Schema          string
+		Manifest        bool
+		SourceRequestID string
+		Seed            int64
+		Params          []interface{}
+	}{
+		Schema:          schemaFile,
+		Manifest:        true,
+		SourceRequestID: m.manifest.RequestID,
+		Seed:            m.manifest.Seed,
+		Params:          params,
+	}
+	hash, err := hashstructure.Hash(hashStruct, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to generate persisted data hash")
+	}
+	return hash, nil
+}
+
+func (m *manifestSplitter) splitType() string {
+	return m.manifest.Splitter
+}
+
+func (m *manifestSplitter) ratio() float64 {
+	return m.manifest.TrainTestSplit
+}
+
+func (m *manifestSplitter) split(data [][]string, rng *rand.Rand) ([][]string, [][]string, error) {
+	log.Infof("performing split based on the manifest of request '%s'", m.manifest.RequestID)
+	// create the output
+	outputTrain := [][]string{}
+	outputTest := [][]string{}
+
+	d3mIndexCol := getD3MIndexColumn(data[0])
+	if d3mIndexCol < 0 {
+		return nil, nil, errors.Errorf("unable to split using a manifest since the data has no '%s' field", model.D3MIndexFieldName)
+	}
+
+	// handle the header
+	inputData, outputTrain, outputTest := splitTrainTestHeader(data, outputTrain, outputTest, true)
+
+	trainIndices := toIndexSet(m.manifest.Train)
+	testIndices := toIndexSet(m.manifest.Test)
+
+	// every row needs to be in the manifest for the split to be identical
+	for _, row := range inputData {
+		d3mIndex := row[d3mIndexCol]
+		if trainIndices[d3mIndex] {
+			outputTrain = append(outputTrain, row)
+		} else if testIndices[d3mIndex] {
+			outputTest = append(outputTest, row)
+		} else {
+			return nil, nil, errors.Errorf("row '%s' is not part of the split manifest of request '%s'", d3mIndex, m.manifest.RequestID)
+		}
 	}
 
 	return outputTrain, outputTest, nil
 }
 
+func toIndexSet(indices []string) map[string]bool {
+	set := make(map[string]bool, len(indices))
+	for _, index := range indices {
+		set[index] = true
+	}
+	return set
+}
+
+func getD3MIndexColumn(header []string) int {
+	for i, field := range header {
+		if field == model.D3MIndexFieldName {
+			return i
+		}
+	}
+	return -1
+}
+
 // SplitDataset splits a dataset into train and test, using an approach to splitting
-// suitable to the task performed.
-func SplitDataset(schemaFile string, splitter datasetSplitter) (string, string, error) {
+// suitable to the task performed. The split is seeded from the splitter parameters
+// and the resulting partitioning is returned as a manifest.
+func SplitDataset(schemaFile string, splitter datasetSplitter) (string, string, *api.SplitManifest, error) {
 	// load the metadata to get the data resource
 	meta, err := loadMetadataForSplit(schemaFile)
 	if err != nil {
-		return "", "", err
+		return "", "", nil, err
 	}
 
 	// check if already split
 	splitDatasetName, err := generateSplitDatasetName(meta.ID, schemaFile, splitter)
 	if err != nil {
-		return "", "", err
+		return "", "", nil, err
 	}
 	trainFolder := path.Join(env.GetTmpPath(), splitDatasetName, trainFilenamePrefix)
 	testFolder := path.Join(env.GetTmpPath(), splitDatasetName, testFilenamePrefix)
 	trainSchemaFile := path.Join(trainFolder, compute.D3MDataSchema)
 	testSchemaFile := path.Join(testFolder, compute.D3MDataSchema)
+	manifestFile := path.Join(env.GetTmpPath(), splitDatasetName, splitManifestFilename)
 
-	if alreadySplit(meta.ID, trainSchemaFile, testSchemaFile) {
-		return trainSchemaFile, testSchemaFile, nil
+	if alreadySplit(meta.ID, trainSchemaFile, testSchemaFile) && util.FileExists(manifestFile) {
+		manifest, err := api.LoadSplitManifest(manifestFile)
+		if err != nil {
+			return "", "", nil, err
+		}
+		return trainSchemaFile, testSchemaFile, manifest, nil
 	}
 
 	// delete existing folders
 	err = deleteIfExists(trainFolder)
 	if err != nil {
-		return "", "", nil
+		return "", "", nil, err
 	}
 	err = deleteIfExists(testFolder)
 	if err != nil {
-		return "", "", nil
+		return "", "", nil, err
 	}
 
 	// load data to split
 	data, err := loadData(path.Dir(schemaFile), meta)
 	if err != nil {
-		return "", "", err
+		return "", "", nil, err
+	}
+
+	// split the data, seeding the shuffle from the split parameters so the
+	// same inputs always produce the same split
+	hash, err := splitter.hash(schemaFile)
+	if err != nil {
+		return "", "", nil, err
+	}
+	seed := int64(hash)
+	trainData, testData, err := splitter.split(data, newSeededRand(seed))
+	if err != nil {
+		return "", "", nil, err
 	}
 
-	// split the data
-	trainData, testData, err := splitter.split(data)
+	// output the train and test data
+	resolveSplitResourcePaths(schemaFile, meta)
+	err = writeSplitPartition(meta, trainFolder, trainData)
 	if err != nil {
-		return "", "", err
+		return "", "", nil, err
+	}
+	err = writeSplitPartition(meta, testFolder, testData)
+	if err != nil {
+		return "", "", nil, err
 	}
 
-	// determine output location
-	outputFilename := path.Base(meta.GetMainDataResource().ResPath)
-	trainOutput := path.Join(trainFolder, compute.D3MDataFolder, outputFilename)
-	testOutput := path.Join(testFolder, compute.D3MDataFolder, outputFilename)
+	// record which rows ended up in each partition
+	manifest := buildSplitManifest(meta.ID, splitter, seed, trainData, testData)
+	if fs, ok := splitter.(foldedSplitter); ok {
+		// the folds are seeded the same way when they get written out
+		manifest.FoldTest, err = collectFoldIndices(fs, data, seed)
+		if err != nil {
+			return "", "", nil, err
+		}
+	}
+	err = api.WriteSplitManifest(manifestFile, manifest)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	return trainSchemaFile, testSchemaFile, manifest, nil
+}
 
-	// update the referenced data resource paths
+// resolveSplitResourcePaths updates the paths of the data resources other than
+// the main data resource to be resolved from the source schema file so that
+// they remain valid from the split output folders.
+func resolveSplitResourcePaths(schemaFile string, meta *model.Metadata) {
 	mainDR := meta.GetMainDataResource()
 	for _, dr := range meta.DataResources {
 		if dr != mainDR {
 			dr.ResPath = model.GetResourcePath(schemaFile, dr)
 		}
 	}
+}
 
-	// output the train and test data
+// writeSplitPartition writes one partition of a split dataset to the output folder.
+func writeSplitPartition(meta *model.Metadata, outputFolder string, data [][]string) error {
+	mainDR := meta.GetMainDataResource()
 	outputStore := serialization.GetStorage(mainDR.ResPath)
-	mainDR.ResPath = trainOutput
-	outputTrain := &serialization.RawDataset{
+	mainDR.ResPath = path.Join(outputFolder, compute.D3MDataFolder, path.Base(mainDR.ResPath))
+	output := &serialization.RawDataset{
 		ID:       meta.ID,
 		Name:     meta.Name,
 		Metadata: meta,
-		Data:     trainData,
+		Data:     data,
 	}
-	err = outputStore.WriteDataset(trainFolder, outputTrain)
-	if err != nil {
-		return "", "", err
+
+	return outputStore.WriteDataset(outputFolder, output)
+}
+
+func buildSplitManifest(dataset string, splitter datasetSplitter, seed int64, trainData [][]string, testData [][]string) *api.SplitManifest {
+	manifest := &api.SplitManifest{
+		Dataset:        dataset,
+		Splitter:       splitter.splitType(),
+		Seed:           seed,
+		TrainTestSplit: splitter.ratio(),
+		Train:          []string{},
+		Test:           []string{},
+		CreatedTime:    time.Now(),
 	}
 
-	mainDR.ResPath = testOutput
-	outputTest := &serialization.RawDataset{
-		ID:       meta.ID,
-		Name:     meta.Name,
-		Metadata: meta,
-		Data:     testData,
+	// a manifest split keeps the seed of the manifest it was built from
+	ms, ok := splitter.(*manifestSplitter)
+	if fms, folded := splitter.(*foldedManifestSplitter); folded {
+		ms, ok = fms.manifestSplitter, true
 	}
-	err = outputStore.WriteDataset(testFolder, outputTest)
-	if err != nil {
-		return "", "", err
+	if ok {
+		manifest.SourceRequestID = ms.manifest.RequestID
+		manifest.Seed = ms.manifest.Seed
+		manifest.Folds = ms.manifest.Folds
+	}
+	if fs, ok := splitter.(foldedSplitter); ok {
+		manifest.Folds = fs.foldCount()
+	}
+
+	d3mIndexCol := getD3MIndexColumn(trainData[0])
+	if d3mIndexCol < 0 {
+		log.Warnf("no '%s' field found in split data so the split manifest will not list rows", model.D3MIndexFieldName)
+		return manifest
 	}
+	manifest.Train = collectIndices(trainData[1:], d3mIndexCol)
+	manifest.Test = collectIndices(testData[1:], d3mIndexCol)
 
-	return trainSchemaFile, testSchemaFile, nil
+	return manifest
+}
+
+func collectIndices(data [][]string, d3mIndexCol int) []string {
+	indices := []string{}
+	seen := map[string]bool{}
+	for _, row := range data {
+		d3mIndex := row[d3mIndexCol]
+		if !seen[d3mIndex] {
+			indices = append(indices, d3mIndex)
+			seen[d3mIndex] = true
+		}
+	}
+	return indices
 }
 
 func loadData(sourceFolder string, meta *model.Metadata) ([][]string, error) {
@@ -524,6 +733,21 @@ func createSplitter(taskType []string, targetFieldIndex int, groupingFieldIndex
 	}
 }
 
+func newManifestSplitter(manifest *api.SplitManifest) datasetSplitter {
+	splitter := &manifestSplitter{
+		manifest: manifest,
+	}
+
+	// replay the cross validation folds if the manifest recorded them
+	if len(manifest.FoldTest) > 0 {
+		return &foldedManifestSplitter{
+			manifestSplitter: splitter,
+		}
+	}
+
+	return splitter
+}
+
 func createSampler(stratify bool, targetCol int, groupingCol int) datasetSampler {
 	// if grouped, stratified splitter works but on the group rather than the label
 	if groupingCol >= 0 {
@@ -17,10 +17,15 @@ package compute
 
 import (
 	"fmt"
+	"os"
 	"path"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/uncharted-distil/distil-compute/primitive/compute"
+	"github.com/uncharted-distil/distil/api/env"
+	api "github.com/uncharted-distil/distil/api/model"
 	"github.com/uncharted-distil/distil/api/util"
 )
 
@@ -60,7 +65,7 @@ func TestSplitTimestampValue(t *testing.T) {
 	trainPath := fmt.Sprintf("test/tmp_data/%s/train/datasetDoc.json", splitDatasetName)
 	testPath := fmt.Sprintf("test/tmp_data/%s/test/datasetDoc.json", splitDatasetName)
 
-	trainFolder, testFolder, err := SplitDataset(path.Join(params.SourceDataFolder, params.SchemaFile), splitter)
+	trainFolder, testFolder, _, err := SplitDataset(path.Join(params.SourceDataFolder, params.SchemaFile), splitter)
 	assert.NoError(t, err)
 	assert.Equal(t, trainPath, trainFolder)
 	assert.Equal(t, testPath, testFolder)
@@ -85,6 +90,159 @@ func TestSplitTimestampValue(t *testing.T) {
 	assert.Equal(t, 1, categoricalValues["d"])
 }
 
+func TestSplitManifest(t *testing.T) {
+	assert.NoError(t, removeTestFiles())
+	params := createTestParams(true, ModelQualityHigh, "test_data")
+	splitter := createTestSplitter(true, ModelQualityHigh)
+	initializeTestConfig(t)
+	schemaFile := path.Join(params.SourceDataFolder, params.SchemaFile)
+
+	_, testFolder, manifest, err := SplitDataset(schemaFile, splitter)
+	assert.NoError(t, err)
+	assert.Equal(t, api.SplitTypeStratified, manifest.Splitter)
+	assert.Equal(t, 0.9, manifest.TrainTestSplit)
+	assert.Equal(t, 28, len(manifest.Train))
+	assert.Equal(t, 5, len(manifest.Test))
+
+	// splitting again from scratch produces the same partitions
+	removeSplitTestFiles(t)
+	_, _, resplit, err := SplitDataset(schemaFile, splitter)
+	assert.NoError(t, err)
+	assert.Equal(t, manifest.Seed, resplit.Seed)
+	assert.Equal(t, manifest.Train, resplit.Train)
+	assert.Equal(t, manifest.Test, resplit.Test)
+
+	// a manifest splitter reproduces the recorded split
+	manifest.RequestID = "request-0"
+	_, replayTestFolder, replayed, err := SplitDataset(schemaFile, newManifestSplitter(manifest))
+	assert.NoError(t, err)
+	assert.NotEqual(t, testFolder, replayTestFolder)
+	assert.Equal(t, "request-0", replayed.SourceRequestID)
+	assert.Equal(t, manifest.Seed, replayed.Seed)
+	assert.ElementsMatch(t, manifest.Train, replayed.Train)
+	assert.ElementsMatch(t, manifest.Test, replayed.Test)
+
+	// rows missing from the manifest cannot be assigned to a partition
+	removeSplitTestFiles(t)
+	manifest.Train = manifest.Train[1:]
+	_, _, _, err = SplitDataset(schemaFile, newManifestSplitter(manifest))
+	assert.Error(t, err)
+	removeSplitTestFiles(t)
+}
+
+// removeSplitTestFiles removes the test files along with the cached splits of
+// the test dataset so that the next split is computed from scratch.
+func removeSplitTestFiles(t *testing.T) {
+	assert.NoError(t, removeTestFiles())
+	splits, err := filepath.Glob(path.Join(env.GetTmpPath(), "test_data-*"))
+	assert.NoError(t, err)
+	for _, split := range splits {
+		assert.NoError(t, os.RemoveAll(split))
+	}
+}
+
+func TestSplitFolds(t *testing.T) {
+	data, err := util.ReadCSVFile("./test/test_dataset/tables/learningData.csv", false)
+	assert.NoError(t, err)
+
+	splitters := []foldedSplitter{
+		&kFoldSplitter{numFolds: 5},
+		&stratifiedKFoldSplitter{numFolds: 5, targetCol: 2},
+		&groupKFoldSplitter{numFolds: 3, groupingCol: 2},
+	}
+	for _, splitter := range splitters {
+		folds, err := splitter.folds(data, newSeededRand(0))
+		assert.NoError(t, err)
+		assert.Equal(t, splitter.foldCount(), len(folds))
+
+		// every row is tested in exactly one fold
+		tested := map[string]int{}
+		for _, fold := range folds {
+			assert.Equal(t, len(data)+1, len(fold.train)+len(fold.test))
+			for _, row := range fold.test[1:] {
+				tested[row[0]]++
+			}
+		}
+		assert.Equal(t, len(data)-1, len(tested))
+		for _, count := range tested {
+			assert.Equal(t, 1, count)
+		}
+	}
+
+	// groups are never split across train and test
+	folds, err := (&groupKFoldSplitter{numFolds: 3, groupingCol: 2}).folds(data, newSeededRand(0))
+	assert.NoError(t, err)
+	for _, fold := range folds {
+		testGroups := map[string]bool{}
+		for _, row := range fold.test[1:] {
+			testGroups[row[2]] = true
+		}
+		for _, row := range fold.train[1:] {
+			assert.False(t, testGroups[row[2]])
+		}
+	}
+
+	// rolling origin folds always test on data after the training window
+	folds, err = (&rollingOriginSplitter{numFolds: 3, timeseriesCol: 0}).folds(data, newSeededRand(0))
+	assert.NoError(t, err)
+	for i, fold := range folds {
+		assert.Equal(t, fold.test[1][0], fmt.Sprintf("%d", len(fold.train)-1))
+		if i > 0 {
+			assert.True(t, len(fold.train) > len(folds[i-1].train))
+		}
+	}
+
+	mean, stdDev := aggregateFoldScores([]float64{0.5, 0.7, 0.9})
+	assert.InDelta(t, 0.7, mean, 0.0001)
+	assert.InDelta(t, 0.1633, stdDev, 0.0001)
+}
+
+func TestCreateCrossValidationSplitter(t *testing.T) {
+	_, err := createCrossValidationSplitter(api.SplitTypeRollingOrigin, []string{compute.ClassificationTask}, 3, 1, 2)
+	assert.Error(t, err)
+
+	splitter, err := createCrossValidationSplitter(api.SplitTypeRollingOrigin, []string{compute.ForecastingTask}, 3, 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, splitter.(*rollingOriginSplitter).timeseriesCol)
+}
+
+func TestSplitManifestFolds(t *testing.T) {
+	removeSplitTestFiles(t)
+	params := createTestParams(true, ModelQualityHigh, "test_data")
+	initializeTestConfig(t)
+	schemaFile := path.Join(params.SourceDataFolder, params.SchemaFile)
+
+	// the manifest records the test rows of every fold, the last being the holdout
+	_, _, manifest, err := SplitDataset(schemaFile, &kFoldSplitter{numFolds: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, manifest.Folds)
+	assert.Len(t, manifest.FoldTest, 3)
+	assert.ElementsMatch(t, manifest.Test, manifest.FoldTest[2])
+	assert.Equal(t, len(manifest.Train)+len(manifest.Test), len(manifest.FoldTest[0])+len(manifest.FoldTest[1])+len(manifest.FoldTest[2]))
+
+	// a manifest with folds replays them
+	manifest.RequestID = "request-0"
+	splitter, ok := newManifestSplitter(manifest).(foldedSplitter)
+	assert.True(t, ok)
+	_, _, replayed, err := SplitDataset(schemaFile, splitter)
+	assert.NoError(t, err)
+	assert.Equal(t, "request-0", replayed.SourceRequestID)
+	assert.Equal(t, 3, replayed.Folds)
+	for f := range manifest.FoldTest {
+		assert.ElementsMatch(t, manifest.FoldTest[f], replayed.FoldTest[f])
+	}
+
+	// rows missing from the folds cannot be assigned to a fold
+	meta, err := loadMetadataForSplit(schemaFile)
+	assert.NoError(t, err)
+	data, err := loadData(path.Dir(schemaFile), meta)
+	assert.NoError(t, err)
+	manifest.FoldTest[0] = manifest.FoldTest[0][1:]
+	_, err = splitter.folds(data, newSeededRand(0))
+	assert.Error(t, err)
+	removeSplitTestFiles(t)
+}
+
 func createTestTimestampSplitter(timestampValue float64) datasetSplitter {
 	return &timeseriesSplitter{
 		timeseriesCol:       1,
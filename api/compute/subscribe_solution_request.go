@@ -0,0 +1,36 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package compute
+
+import (
+	"encoding/json"
+)
+
+// SubscribeSolutionSearchRequest represents a request to receive the status updates of
+// a previously created solution search.
+type SubscribeSolutionSearchRequest struct {
+	RequestID string `json:"requestId"`
+}
+
+// NewSubscribeSolutionSearchRequest instantiates a new SubscribeSolutionSearchRequest.
+func NewSubscribeSolutionSearchRequest(data []byte) (*SubscribeSolutionSearchRequest, error) {
+	req := &SubscribeSolutionSearchRequest{}
+	err := json.Unmarshal(data, &req)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
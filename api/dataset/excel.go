@@ -0,0 +1,86 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package dataset
+
+import (
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/pkg/errors"
+	log "github.com/unchartedsoftware/plog"
+
+	"github.com/uncharted-distil/distil-compute/model"
+	"github.com/uncharted-distil/distil/api/env"
+	"github.com/uncharted-distil/distil/api/serialization"
+)
+
+// Excel represents a dataset stored in a single sheet of an excel workbook.
+type Excel struct {
+	Dataset     string `json:"dataset"`
+	RawFilePath string `json:"rawFilePath"`
+	Sheet       string `json:"sheet"`
+}
+
+// NewExcelDataset creates a new excel dataset from a workbook on disk. If no
+// sheet is specified, the first sheet of the workbook is used.
+func NewExcelDataset(dataset string, rawFilePath string, sheet string) (*Excel, error) {
+	return &Excel{
+		Dataset:     dataset,
+		RawFilePath: rawFilePath,
+		Sheet:       sheet,
+	}, nil
+}
+
+// CreateDataset reads the selected sheet and structures it into a valid D3M dataset.
+func (e *Excel) CreateDataset(rootDataPath string, datasetName string, config *env.Config) (*serialization.RawDataset, error) {
+	if datasetName == "" {
+		datasetName = e.Dataset
+	}
+
+	workbook, err := excelize.OpenFile(e.RawFilePath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to open excel workbook")
+	}
+
+	sheet := e.Sheet
+	if sheet == "" {
+		sheets := workbook.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, errors.Errorf("excel workbook has no sheets")
+		}
+		sheet = sheets[0]
+	}
+	log.Infof("reading sheet '%s' from excel workbook '%s'", sheet, e.RawFilePath)
+
+	rows, err := workbook.GetRows(sheet)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read sheet '%s'", sheet)
+	}
+
+	table, err := newTableFromRows(datasetName, rows, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return table.CreateDataset(rootDataPath, datasetName, config)
+}
+
+// GetDefinitiveTypes returns an empty list as definitive types.
+func (e *Excel) GetDefinitiveTypes() []*model.Variable {
+	return []*model.Variable{}
+}
+
+// CleanupTempFiles does nothing since this creates no temp files.
+func (e *Excel) CleanupTempFiles() {
+}
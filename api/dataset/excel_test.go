@@ -0,0 +1,57 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package dataset
+
+import (
+	"path"
+	"testing"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExcelCreateDataset(t *testing.T) {
+	workbook := excelize.NewFile()
+	assert.NoError(t, workbook.SetSheetRow("Sheet1", "A1", &[]interface{}{"d3mIndex", "name"}))
+	assert.NoError(t, workbook.SetSheetRow("Sheet1", "A2", &[]interface{}{0, "alpha"}))
+	workbook.NewSheet("second")
+	assert.NoError(t, workbook.SetSheetRow("second", "A1", &[]interface{}{"value", "count"}))
+	assert.NoError(t, workbook.SetSheetRow("second", "A2", &[]interface{}{"beta", 3}))
+	assert.NoError(t, workbook.SetSheetRow("second", "A3", &[]interface{}{"gamma"}))
+	rawFilePath := path.Join(t.TempDir(), "workbook.xlsx")
+	assert.NoError(t, workbook.SaveAs(rawFilePath))
+
+	// the first sheet is used by default
+	ds, err := NewExcelDataset("workbook", rawFilePath, "")
+	assert.NoError(t, err)
+	raw, err := ds.CreateDataset(t.TempDir(), "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "workbook", raw.Name)
+	assert.Equal(t, [][]string{{"d3mIndex", "name"}, {"0", "alpha"}}, raw.Data)
+	assert.Len(t, raw.Metadata.DataResources[0].Variables, 1)
+
+	// short rows are padded to the header length
+	ds, err = NewExcelDataset("workbook", rawFilePath, "second")
+	assert.NoError(t, err)
+	raw, err = ds.CreateDataset(t.TempDir(), "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"value", "count"}, {"beta", "3"}, {"gamma", ""}}, raw.Data)
+
+	ds, err = NewExcelDataset("workbook", rawFilePath, "missing")
+	assert.NoError(t, err)
+	_, err = ds.CreateDataset(t.TempDir(), "", nil)
+	assert.Error(t, err)
+}
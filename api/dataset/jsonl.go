@@ -0,0 +1,160 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package dataset
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/uncharted-distil/distil-compute/model"
+	"github.com/uncharted-distil/distil/api/env"
+	"github.com/uncharted-distil/distil/api/serialization"
+)
+
+const (
+	jsonLinesKeySeparator = "_"
+	jsonLinesMaxLineSize  = 64 * 1024 * 1024
+)
+
+// JSONLines represents a dataset stored as one json object per line. Nested
+// objects are flattened into columns by joining the keys.
+type JSONLines struct {
+	Dataset     string `json:"dataset"`
+	RawFilePath string `json:"rawFilePath"`
+}
+
+// NewJSONLinesDataset creates a new json lines dataset from a file on disk.
+func NewJSONLinesDataset(dataset string, rawFilePath string) (*JSONLines, error) {
+	return &JSONLines{
+		Dataset:     dataset,
+		RawFilePath: rawFilePath,
+	}, nil
+}
+
+// CreateDataset flattens the json objects and structures them into a valid D3M dataset.
+func (j *JSONLines) CreateDataset(rootDataPath string, datasetName string, config *env.Config) (*serialization.RawDataset, error) {
+	if datasetName == "" {
+		datasetName = j.Dataset
+	}
+
+	file, err := os.Open(j.RawFilePath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to open json lines file")
+	}
+	defer file.Close()
+
+	// flatten every record, tracking the columns in the order they are first seen
+	header := []string{}
+	headerIndices := map[string]int{}
+	records := []map[string]string{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), jsonLinesMaxLineSize)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.UseNumber()
+		var raw map[string]interface{}
+		err = decoder.Decode(&raw)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse json object on line %d", lineNumber)
+		}
+
+		record := map[string]string{}
+		flattenJSONObject("", raw, record)
+		keys := make([]string, 0, len(record))
+		for k := range record {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if _, ok := headerIndices[k]; !ok {
+				headerIndices[k] = len(header)
+				header = append(header, k)
+			}
+		}
+		records = append(records, record)
+	}
+	err = scanner.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read json lines file")
+	}
+
+	rows := make([][]string, len(records)+1)
+	rows[0] = header
+	for i, record := range records {
+		row := make([]string, len(header))
+		for k, v := range record {
+			row[headerIndices[k]] = v
+		}
+		rows[i+1] = row
+	}
+
+	table, err := newTableFromRows(datasetName, rows, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return table.CreateDataset(rootDataPath, datasetName, config)
+}
+
+// GetDefinitiveTypes returns an empty list as definitive types.
+func (j *JSONLines) GetDefinitiveTypes() []*model.Variable {
+	return []*model.Variable{}
+}
+
+// CleanupTempFiles does nothing since this creates no temp files.
+func (j *JSONLines) CleanupTempFiles() {
+}
+
+func flattenJSONObject(prefix string, obj map[string]interface{}, output map[string]string) {
+	for k, v := range obj {
+		key := k
+		if prefix != "" {
+			key = prefix + jsonLinesKeySeparator + k
+		}
+
+		switch typed := v.(type) {
+		case map[string]interface{}:
+			flattenJSONObject(key, typed, output)
+		case []interface{}:
+			// arrays are kept as a single json encoded value
+			encoded, err := json.Marshal(typed)
+			if err != nil {
+				output[key] = fmt.Sprintf("%v", typed)
+			} else {
+				output[key] = string(encoded)
+			}
+		case nil:
+			output[key] = ""
+		case string:
+			output[key] = typed
+		default:
+			output[key] = fmt.Sprintf("%v", typed)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package dataset
+
+import (
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONLinesCreateDataset(t *testing.T) {
+	rawFilePath := path.Join(t.TempDir(), "records.jsonl")
+	data := `{"name": "alpha", "location": {"lat": 1.5, "lon": 2}, "tags": ["a", "b"]}
+
+{"name": "beta", "count": 12345678901, "location": {"lat": null}}
+`
+	assert.NoError(t, ioutil.WriteFile(rawFilePath, []byte(data), 0644))
+
+	ds, err := NewJSONLinesDataset("records", rawFilePath)
+	assert.NoError(t, err)
+	raw, err := ds.CreateDataset(t.TempDir(), "", nil)
+	assert.NoError(t, err)
+
+	// nested objects are flattened, arrays are kept encoded and columns are
+	// ordered by first appearance
+	assert.Equal(t, [][]string{
+		{"location_lat", "location_lon", "name", "tags", "count"},
+		{"1.5", "2", "alpha", `["a","b"]`, ""},
+		{"", "", "beta", "", "12345678901"},
+	}, raw.Data)
+
+	assert.NoError(t, ioutil.WriteFile(rawFilePath, []byte("{\"name\": \"alpha\"}\nnot json\n"), 0644))
+	_, err = ds.CreateDataset(t.TempDir(), "", nil)
+	assert.Error(t, err)
+}
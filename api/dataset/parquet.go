@@ -0,0 +1,64 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package dataset
+
+import (
+	"github.com/uncharted-distil/distil-compute/model"
+	"github.com/uncharted-distil/distil/api/env"
+	"github.com/uncharted-distil/distil/api/serialization"
+)
+
+// Parquet represents a raw dataset stored in a single parquet file.
+type Parquet struct {
+	Dataset     string `json:"dataset"`
+	RawFilePath string `json:"rawFilePath"`
+}
+
+// NewParquetDataset creates a new parquet dataset from a file on disk.
+func NewParquetDataset(dataset string, rawFilePath string) (*Parquet, error) {
+	return &Parquet{
+		Dataset:     dataset,
+		RawFilePath: rawFilePath,
+	}, nil
+}
+
+// CreateDataset reads the parquet data and structures it into a valid D3M dataset.
+func (p *Parquet) CreateDataset(rootDataPath string, datasetName string, config *env.Config) (*serialization.RawDataset, error) {
+	if datasetName == "" {
+		datasetName = p.Dataset
+	}
+
+	rows, err := serialization.GetParquetStorage().ReadData(p.RawFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	table, err := newTableFromRows(datasetName, rows, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return table.CreateDataset(rootDataPath, datasetName, config)
+}
+
+// GetDefinitiveTypes returns an empty list as definitive types.
+func (p *Parquet) GetDefinitiveTypes() []*model.Variable {
+	return []*model.Variable{}
+}
+
+// CleanupTempFiles does nothing since this creates no temp files.
+func (p *Parquet) CleanupTempFiles() {
+}
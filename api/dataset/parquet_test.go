@@ -0,0 +1,39 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package dataset
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uncharted-distil/distil/api/serialization"
+)
+
+func TestParquetCreateDataset(t *testing.T) {
+	rawFilePath := path.Join(t.TempDir(), "data.parquet")
+	data := [][]string{{"d3mIndex", "name"}, {"0", "alpha"}, {"1", "beta"}}
+	assert.NoError(t, serialization.GetParquetStorage().WriteData(rawFilePath, data))
+
+	ds, err := NewParquetDataset("data", rawFilePath)
+	assert.NoError(t, err)
+	raw, err := ds.CreateDataset(t.TempDir(), "renamed", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "renamed", raw.Name)
+	assert.Equal(t, data, raw.Data)
+	assert.Len(t, raw.Metadata.DataResources[0].Variables, 1)
+}
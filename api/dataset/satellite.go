@@ -20,6 +20,7 @@ import (
 	"io/ioutil"
 	"path"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/araddon/dateparse"
@@ -37,6 +38,9 @@ import (
 
 const (
 	errorLogLimit = 50
+
+	cloudFractionField  = "cloud_fraction"
+	nodataFractionField = "nodata_fraction"
 )
 
 var (
@@ -48,14 +52,7 @@ var (
 		"tiff": {"tif", "tiff"},
 	}
 
-	bandRegex      = regexp.MustCompile(`_B[0-9][0-9a-zA-Z][.]`)
 	timestampRegex = regexp.MustCompile(`\d{8}T\d{4,6}`)
-
-	// eurosat drops cloud layer, has the 8A layer and offsets everything else.
-	eurosatBandMapping = map[int]string{
-		10: "",
-		13: "8A",
-	}
 )
 
 // RemoteSensingDatasetProperties lists the data properties of a remote sensing dataset.
@@ -70,6 +67,12 @@ type Satellite struct {
 	ImageType         string `json:"imageType"`
 	RawFilePath       string `json:"rawFilePath"`
 	ExtractedFilePath string `json:"extractedFilePath"`
+	Sensor            string `json:"sensor"`
+	// MaxCloudFraction and MaxNodataFraction drop images obscured by more than the
+	// specified fraction of cloud or nodata during ingest.
+	MaxCloudFraction  float64 `json:"maxCloudFraction"`
+	MaxNodataFraction float64 `json:"maxNodataFraction"`
+	sensorProfile     *imagery.SensorProfile
 	definitiveTypes   []*model.Variable
 }
 
@@ -118,8 +121,14 @@ func (b *BoundingBox) pointToString(point *Point, separator string) string {
 	return separator
 }
 
-// NewSatelliteDataset creates a new satelitte dataset from geotiff files
-func NewSatelliteDataset(dataset string, imageType string, rawFilePath string) (*Satellite, error) {
+// NewSatelliteDataset creates a new satelitte dataset from geotiff files captured by the
+// specified sensor.
+func NewSatelliteDataset(dataset string, imageType string, sensor string, rawFilePath string) (*Satellite, error) {
+	profile, err := imagery.GetSensorProfile(sensor)
+	if err != nil {
+		return nil, err
+	}
+
 	expandedInfo, err := ExpandZipDataset(rawFilePath, dataset)
 	if err != nil {
 		return nil, err
@@ -130,16 +139,29 @@ func NewSatelliteDataset(dataset string, imageType string, rawFilePath string) (
 		ImageType:         imageType,
 		RawFilePath:       expandedInfo.RawFilePath,
 		ExtractedFilePath: expandedInfo.ExtractedFilePath,
+		Sensor:            profile.ID,
+		MaxCloudFraction:  1,
+		MaxNodataFraction: 1,
+		sensorProfile:     profile,
 	}, nil
 }
 
 // NewSatelliteDatasetFromExpanded creates a new satelitte dataset from geotiff files where the archive has already been expanded.
-func NewSatelliteDatasetFromExpanded(dataset string, imageType string, rawFilePath string, extractedFilePath string) (*Satellite, error) {
+func NewSatelliteDatasetFromExpanded(dataset string, imageType string, sensor string, rawFilePath string, extractedFilePath string) (*Satellite, error) {
+	profile, err := imagery.GetSensorProfile(sensor)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Satellite{
 		Dataset:           dataset,
 		ImageType:         imageType,
 		RawFilePath:       rawFilePath,
 		ExtractedFilePath: extractedFilePath,
+		Sensor:            profile.ID,
+		MaxCloudFraction:  1,
+		MaxNodataFraction: 1,
+		sensorProfile:     profile,
 	}, nil
 }
 
@@ -158,7 +180,8 @@ func (s *Satellite) CreateDataset(rootDataPath string, datasetName string, confi
 	}
 	props := s.readProperties(imageFolders)
 	labelHeader := "label"
-	expectedHeaders := []string{model.D3MIndexFieldName, "image_file", "group_id", "band", "timestamp", "coordinates", "geo_coordinates"}
+	expectedHeaders := []string{model.D3MIndexFieldName, "image_file", "group_id", "band", "timestamp", "coordinates", "geo_coordinates",
+		cloudFractionField, nodataFractionField}
 	if props.MultiClass {
 		expectedHeaders = append(expectedHeaders, labelHeader)
 	}
@@ -168,9 +191,12 @@ func (s *Satellite) CreateDataset(rootDataPath string, datasetName string, confi
 	csvData = append(csvData, headerNames)
 	mediaFolder := util.GetUniqueFolder(path.Join(outputDatasetPath, "media"))
 
-	// need to keep track of d3m Index values since they are shared for a whole group
-	d3mIDs := make(map[string]int)
-	d3mIDRunning := 1
+	// the coverage of a group can only be determined once all its bands are known so the rows
+	// are collected by group and written out once the coverage has been computed
+	groupIDs := []string{}
+	groupRows := map[string][][]string{}
+	groupBandFiles := map[string]map[string]string{}
+	qualityFiles := map[string]string{}
 
 	// the folder name represents the label to apply for all containing images
 	errorCount := 0
@@ -191,6 +217,12 @@ func (s *Satellite) CreateDataset(rootDataPath string, datasetName string, confi
 			imageFilename := imageFile.Name()
 			imageFilenameFull := path.Join(imageFolder, imageFilename)
 
+			// quality bands are only used to determine the coverage and are not ingested
+			if s.sensorProfile.IsQualityBand(imageFilename) {
+				qualityFiles[extractGroupID(imageFilename, s.sensorProfile, props)] = imageFilenameFull
+				continue
+			}
+
 			ok := verifySatelliteImage(imageFilenameFull, s.ImageType)
 			if !ok {
 				logWarning(errorCount, "'%s' is not a valid or supported satellite image", imageFilenameFull)
@@ -198,7 +230,7 @@ func (s *Satellite) CreateDataset(rootDataPath string, datasetName string, confi
 				continue
 			}
 
-			filesToProcess, err := copyAndSplitMultiBandImage(imageFilenameFull, s.ImageType, mediaFolder)
+			filesToProcess, err := copyAndSplitMultiBandImage(imageFilenameFull, s.ImageType, s.sensorProfile, mediaFolder)
 			if err != nil {
 				errorLogCount++
 				if errorLogCount < 5 {
@@ -215,7 +247,7 @@ func (s *Satellite) CreateDataset(rootDataPath string, datasetName string, confi
 					continue
 				}
 
-				band, err := extractBand(targetImageFilename)
+				band, err := extractBand(targetImageFilename, s.sensorProfile)
 				if err != nil {
 					logWarning(errorCount, "unable to extract band from '%s': %v", targetImageFilename, err)
 					errorCount++
@@ -229,21 +261,51 @@ func (s *Satellite) CreateDataset(rootDataPath string, datasetName string, confi
 					timestampType = model.StringType
 				}
 
-				groupID := extractGroupID(targetImageFilename, props)
-
-				d3mID := d3mIDs[groupID]
-				if d3mID == 0 {
-					d3mID = d3mIDRunning
-					d3mIDRunning = d3mIDRunning + 1
-					d3mIDs[groupID] = d3mID
+				groupID := extractGroupID(targetImageFilename, s.sensorProfile, props)
+				if groupRows[groupID] == nil {
+					groupIDs = append(groupIDs, groupID)
+					groupBandFiles[groupID] = map[string]string{}
 				}
-				// remove values that are not needed based on the headerNames (expects values, expectedHeaders and headerNames to be IN ORDER)
-				csvLine := removeMissingValues(indicesToKeep, []string{fmt.Sprintf("%d", d3mID), path.Base(targetImageFilename), groupID, band, timestamp, coordinates.String(), coordinates.ToGeometryString(), label})
+				groupBandFiles[groupID][fmt.Sprintf("b%s", band)] = targetImageFilename
 
-				csvData = append(csvData, csvLine)
+				// d3m index and coverage are filled in once the group is complete
+				groupRows[groupID] = append(groupRows[groupID], []string{"", path.Base(targetImageFilename), groupID, band, timestamp,
+					coordinates.String(), coordinates.ToGeometryString(), "", "", label})
 			}
 		}
 	}
+
+	// compute the coverage of every group, dropping those that are too obscured
+	log.Infof("computing cloud and nodata coverage of %d images", len(groupIDs))
+	d3mID := 1
+	droppedCount := 0
+	for _, groupID := range groupIDs {
+		coverage, err := imagery.ComputeCoverage(s.sensorProfile, groupBandFiles[groupID], qualityFiles[groupID])
+		if err != nil {
+			logWarning(errorCount, "unable to compute coverage of '%s': %v", groupID, err)
+			errorCount++
+			coverage = &imagery.Coverage{}
+		}
+		if coverage.CloudFraction > s.MaxCloudFraction || coverage.NodataFraction > s.MaxNodataFraction {
+			droppedCount++
+			for _, row := range groupRows[groupID] {
+				util.Delete(path.Join(mediaFolder, row[1]))
+			}
+			continue
+		}
+
+		for _, row := range groupRows[groupID] {
+			row[0] = fmt.Sprintf("%d", d3mID)
+			row[7] = strconv.FormatFloat(coverage.CloudFraction, 'f', 4, 64)
+			row[8] = strconv.FormatFloat(coverage.NodataFraction, 'f', 4, 64)
+			// remove values that are not needed based on the headerNames (expects values, expectedHeaders and headerNames to be IN ORDER)
+			csvData = append(csvData, removeMissingValues(indicesToKeep, row))
+		}
+		d3mID++
+	}
+	if droppedCount > 0 {
+		log.Infof("dropped %d images exceeding the cloud (%v) or nodata (%v) threshold", droppedCount, s.MaxCloudFraction, s.MaxNodataFraction)
+	}
 	log.Infof("parsed all input data creating %d rows of data and %d errors", len(csvData)-1, errorCount)
 
 	// create the dataset schema doc
@@ -288,6 +350,16 @@ func (s *Satellite) CreateDataset(rootDataPath string, datasetName string, confi
 			model.GeoBoundsType, "postgis structure for the bounding box coordinates of the tile", []string{},
 			[]string{model.VarDistilRoleData}, nil, dr.Variables, false))
 	varCounter++
+	dr.Variables = append(dr.Variables,
+		model.NewVariable(varCounter, cloudFractionField, "cloud fraction", cloudFractionField, cloudFractionField, model.RealType,
+			model.RealType, "Fraction of the image data obscured by cloud", []string{"attribute"},
+			[]string{model.VarDistilRoleSystemData}, nil, dr.Variables, false))
+	varCounter++
+	dr.Variables = append(dr.Variables,
+		model.NewVariable(varCounter, nodataFractionField, "nodata fraction", nodataFractionField, nodataFractionField, model.RealType,
+			model.RealType, "Fraction of the image holding no data", []string{"attribute"},
+			[]string{model.VarDistilRoleSystemData}, nil, dr.Variables, false))
+	varCounter++
 	if len(expectedHeaders) == len(headerNames) {
 		dr.Variables = append(dr.Variables,
 			model.NewVariable(varCounter, "label", "label", "label", "label", model.CategoricalType,
@@ -389,14 +461,13 @@ func verifySatelliteImage(filename string, defaultType string) bool {
 	return satTypeMap[typ] != ""
 }
 
-func extractBand(filename string) (string, error) {
-	bandRaw := bandRegex.Find([]byte(filename))
-	if len(bandRaw) > 0 {
-		band := string(bandRaw)
-		return strings.ToLower(band[2 : len(band)-1]), nil
+func extractBand(filename string, sensor *imagery.SensorProfile) (string, error) {
+	band, ok := sensor.ExtractBand(path.Base(filename))
+	if !ok {
+		return "", errors.Errorf("unable to extract %s band from filename", sensor.ID)
 	}
 
-	return "", errors.New("unable to extract band from filename")
+	return band, nil
 }
 
 func extractTimestamp(filename string) (string, error) {
@@ -413,9 +484,12 @@ func extractTimestamp(filename string) (string, error) {
 	return parsed.Format("2006-01-02 03:04:05"), nil
 }
 
-func extractGroupID(filename string, props *RemoteSensingDatasetProperties) string {
-	bandRaw := bandRegex.Find([]byte(filename))
+func extractGroupID(filename string, sensor *imagery.SensorProfile, props *RemoteSensingDatasetProperties) string {
 	adjustedFilename := path.Base(filename)
+	bandRaw := sensor.BandPattern.Find([]byte(adjustedFilename))
+	if len(bandRaw) == 0 && sensor.QualityBand != nil {
+		bandRaw = sensor.QualityBand.Pattern.Find([]byte(adjustedFilename))
+	}
 	if len(bandRaw) > 0 {
 		adjustedFilename = strings.Replace(adjustedFilename, string(bandRaw), ".", 1)
 	}
@@ -500,7 +574,7 @@ func logWarning(currentCount int, warning string, params ...interface{}) {
 	}
 }
 
-func copyAndSplitMultiBandImage(imageFilename string, imageType string, outputFolder string) ([]string, error) {
+func copyAndSplitMultiBandImage(imageFilename string, imageType string, sensor *imagery.SensorProfile, outputFolder string) ([]string, error) {
 	files := make([]string, 0)
 
 	// open file
@@ -529,7 +603,7 @@ func copyAndSplitMultiBandImage(imageFilename string, imageType string, outputFo
 		files = append(files, targetImageFilename)
 	} else {
 		// multiband so need to split it into separate files
-		files, err = imagery.SplitMultiBandImage(dataset, outputFolder, eurosatBandMapping)
+		files, err = imagery.SplitMultiBandImage(dataset, outputFolder, sensor.MultiBandMapping)
 		if err != nil {
 			return nil, err
 		}
@@ -539,8 +613,9 @@ func copyAndSplitMultiBandImage(imageFilename string, imageType string, outputFo
 }
 
 // CreateSatelliteGrouping dumps the satellite grouping structure into a map.
-// It assumes that the dataset has the same structure as during upload.
-func CreateSatelliteGrouping() map[string]interface{} {
+// It assumes that the dataset has the same structure as during upload.  The
+// sensor is carried along so that it can be recorded when the grouping is set.
+func CreateSatelliteGrouping(sensor string) map[string]interface{} {
 	// assume dataset structure matches what would be created during ingest
 	grouping := map[string]interface{}{}
 	grouping["bandCol"] = "band"
@@ -548,6 +623,7 @@ func CreateSatelliteGrouping() map[string]interface{} {
 	grouping["imageCol"] = "image_file"
 	grouping["type"] = model.MultiBandImageType
 	grouping["hidden"] = []string{"image_file", "band", "group_id"}
+	grouping["sensor"] = sensor
 
 	return grouping
 }
@@ -46,16 +46,35 @@ func NewTableDataset(dataset string, rawData []byte, flagD3MIndex bool) (*Table,
 		return nil, errors.Wrapf(err, "unable to read csv data")
 	}
 
+	return newTableFromRows(dataset, csvData, flagD3MIndex)
+}
+
+// newTableFromRows creates a table dataset from rows of string values, with
+// the first row being the header. Short rows are padded to the header length.
+func newTableFromRows(dataset string, rows [][]string, flagD3MIndex bool) (*Table, error) {
+	if len(rows) == 0 {
+		return nil, errors.Errorf("no header found in tabular data")
+	}
+
 	// remove invisible characters from the header
-	for i, c := range csvData[0] {
-		csvData[0][i] = strings.TrimFunc(c, func(r rune) bool {
+	for i, c := range rows[0] {
+		rows[0][i] = strings.TrimFunc(c, func(r rune) bool {
 			return !unicode.IsGraphic(r)
 		})
 	}
 
+	headerLength := len(rows[0])
+	for i, row := range rows[1:] {
+		if len(row) < headerLength {
+			padded := make([]string, headerLength)
+			copy(padded, row)
+			rows[i+1] = padded
+		}
+	}
+
 	return &Table{
 		Dataset:   dataset,
-		CSVData:   csvData,
+		CSVData:   rows,
 		flagIndex: flagD3MIndex,
 	}, nil
 }
@@ -30,7 +30,17 @@ import (
 )
 
 var (
-	simpleFileTypes = map[string]string{"txt": "txt"}
+	simpleFileTypes = map[string]string{
+		"txt":     "txt",
+		"geojson": "geojson",
+		"gpkg":    "gpkg",
+		// a shapefile is made up of several files that all identify it
+		"shp": "shp",
+		"shx": "shp",
+		"dbf": "shp",
+		"prj": "shp",
+		"cpg": "shp",
+	}
 )
 
 // ExpandedDatasetPaths stores paths info about the input dataset archive
@@ -0,0 +1,272 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package dataset
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	"github.com/uncharted-distil/distil-compute/primitive/compute"
+	"github.com/uncharted-distil/gdal"
+	log "github.com/unchartedsoftware/plog"
+
+	"github.com/uncharted-distil/distil/api/env"
+	"github.com/uncharted-distil/distil/api/serialization"
+	"github.com/uncharted-distil/distil/api/util"
+)
+
+const (
+	vectorCoordinatesField = "coordinates"
+	vectorGeometryField    = "__geo_coordinates"
+	vectorPropertyPrefix   = "property_"
+)
+
+var (
+	// vectorExtensions lists the extensions of the files gdal can read vector data from.
+	vectorExtensions = map[string]bool{
+		".geojson": true,
+		".shp":     true,
+		".gpkg":    true,
+	}
+)
+
+// Vector captures the data in a vector (GeoJSON, Shapefile or GeoPackage) dataset.
+// Every feature of a layer becomes a row, with its properties as variables and the
+// bounding box of its geometry stored in the same coordinates field used for remote
+// sensing datasets.  The PostGIS geometry field is created from the coordinates by
+// the geobounds grouping once the dataset is ingested.
+type Vector struct {
+	Dataset         string `json:"dataset"`
+	RawFilePath     string `json:"rawFilePath"`
+	Layer           string `json:"layer"`
+	definitiveTypes []*model.Variable
+}
+
+// NewVectorDataset creates a new vector dataset from the file or folder found at the
+// supplied path.  The named layer is read, defaulting to the first one if unspecified.
+func NewVectorDataset(dataset string, rawFilePath string, layer string) (*Vector, error) {
+	if util.IsDirectory(rawFilePath) {
+		vectorFile, err := findVectorFile(rawFilePath)
+		if err != nil {
+			return nil, err
+		}
+		rawFilePath = vectorFile
+	}
+
+	return &Vector{
+		Dataset:     dataset,
+		RawFilePath: rawFilePath,
+		Layer:       layer,
+	}, nil
+}
+
+// CreateDataset reads the features of the vector layer and structures them into a valid D3M dataset.
+func (v *Vector) CreateDataset(rootDataPath string, datasetName string, config *env.Config) (*serialization.RawDataset, error) {
+	if datasetName == "" {
+		datasetName = v.Dataset
+	}
+	dataFilePath := path.Join(rootDataPath, compute.D3MDataFolder, compute.D3MLearningData)
+
+	source := gdal.OpenDataSource(v.RawFilePath, 0)
+	defer source.Destroy()
+	layer, err := v.getLayer(source)
+	if err != nil {
+		return nil, err
+	}
+
+	// all geometries are stored as lon / lat, which is assumed when the layer has
+	// no spatial reference (ie a shapefile without a .prj file)
+	target := gdal.CreateSpatialReference("")
+	err = target.FromEPSG(4326)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create target spatial reference from EPSG code")
+	}
+	defer target.Destroy()
+	transform := layer.SpatialReference() != gdal.SpatialReference{}
+	if !transform {
+		log.Warnf("layer of '%s' has no spatial reference so coordinates are assumed to be EPSG:4326", v.RawFilePath)
+	}
+
+	// create the dataset schema doc with a variable per feature property
+	datasetID := model.NormalizeDatasetID(datasetName)
+	meta := model.NewMetadata(datasetName, datasetName, "", datasetID)
+	dr := model.NewDataResource(compute.DefaultResourceID, model.ResTypeTable, map[string][]string{compute.D3MResourceFormat: {"csv"}})
+	dr.ResPath = dataFilePath
+	dr.Variables = append(dr.Variables,
+		model.NewVariable(0, model.D3MIndexFieldName, model.D3MIndexFieldName, model.D3MIndexFieldName,
+			model.D3MIndexFieldName, model.IntegerType, model.IntegerType, "D3M index",
+			[]string{model.RoleMultiIndex}, []string{model.VarDistilRoleIndex}, nil, dr.Variables, false))
+
+	definition := layer.Definition()
+	fieldCount := definition.FieldCount()
+	for i := 0; i < fieldCount; i++ {
+		field := definition.FieldDefinition(i)
+		name := field.Name()
+		if name == model.D3MIndexFieldName || name == vectorCoordinatesField || name == vectorGeometryField {
+			name = vectorPropertyPrefix + name
+		}
+		typ := mapVectorFieldType(field.Type())
+		dr.Variables = append(dr.Variables,
+			model.NewVariable(len(dr.Variables), name, name, name, name, typ, typ, "Feature property", []string{"attribute"},
+				[]string{model.VarDistilRoleData}, nil, dr.Variables, false))
+	}
+	dr.Variables = append(dr.Variables,
+		model.NewVariable(len(dr.Variables), vectorCoordinatesField, vectorCoordinatesField, vectorCoordinatesField, vectorCoordinatesField,
+			model.RealVectorType, model.RealVectorType, "Coordinates of the feature defined by a bounding box", []string{"attribute"},
+			[]string{model.VarDistilRoleData}, nil, dr.Variables, false))
+
+	csvData := [][]string{}
+	header := make([]string, len(dr.Variables))
+	for i, variable := range dr.Variables {
+		header[i] = variable.HeaderName
+	}
+	csvData = append(csvData, header)
+
+	errorCount := 0
+	layer.ResetReading()
+	for feature := layer.NextFeature(); feature != nil; feature = layer.NextFeature() {
+		row, err := v.parseFeature(feature, fieldCount, target, transform)
+		feature.Destroy()
+		if err != nil {
+			logWarning(errorCount, "unable to parse feature: %v", err)
+			errorCount++
+			continue
+		}
+		row[0] = fmt.Sprintf("%d", len(csvData)-1)
+		csvData = append(csvData, row)
+	}
+	log.Infof("parsed all features creating %d rows of data and %d errors", len(csvData)-1, errorCount)
+	if len(csvData) == 1 && errorCount > 0 {
+		return nil, errors.Errorf("unable to parse any of the %d features of '%s'", errorCount, v.RawFilePath)
+	}
+
+	meta.DataResources = []*model.DataResource{dr}
+	v.definitiveTypes = dr.Variables
+
+	return &serialization.RawDataset{
+		ID:              datasetID,
+		Name:            datasetName,
+		Data:            csvData,
+		Metadata:        meta,
+		DefinitiveTypes: true,
+	}, nil
+}
+
+func (v *Vector) getLayer(source gdal.DataSource) (gdal.Layer, error) {
+	layerCount := source.LayerCount()
+	if layerCount == 0 {
+		return gdal.Layer{}, errors.Errorf("no vector layers found in '%s'", v.RawFilePath)
+	}
+	if v.Layer == "" {
+		return source.LayerByIndex(0), nil
+	}
+
+	for i := 0; i < layerCount; i++ {
+		layer := source.LayerByIndex(i)
+		if layer.Name() == v.Layer {
+			return layer, nil
+		}
+	}
+
+	return gdal.Layer{}, errors.Errorf("layer '%s' not found in '%s'", v.Layer, v.RawFilePath)
+}
+
+func (v *Vector) parseFeature(feature *gdal.Feature, fieldCount int, target gdal.SpatialReference, transform bool) ([]string, error) {
+	geometry := feature.Geometry()
+	if geometry.IsEmpty() {
+		return nil, errors.New("feature has no geometry")
+	}
+	if transform {
+		err := geometry.TransformTo(target)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to transform geometry")
+		}
+	}
+
+	// the first value is the d3m index, filled in by the caller
+	row := make([]string, fieldCount+2)
+	for i := 0; i < fieldCount; i++ {
+		if feature.IsFieldSet(i) {
+			row[i+1] = feature.FieldAsString(i)
+		}
+	}
+
+	envelope := geometry.Envelope()
+	bounds := &BoundingBox{
+		LowerLeft:  &Point{X: envelope.MinX(), Y: envelope.MinY()},
+		UpperLeft:  &Point{X: envelope.MinX(), Y: envelope.MaxY()},
+		UpperRight: &Point{X: envelope.MaxX(), Y: envelope.MaxY()},
+		LowerRight: &Point{X: envelope.MaxX(), Y: envelope.MinY()},
+	}
+	row[fieldCount+1] = bounds.String()
+
+	return row, nil
+}
+
+// GetDefinitiveTypes returns the types mapped from the vector field definitions.
+func (v *Vector) GetDefinitiveTypes() []*model.Variable {
+	return v.definitiveTypes
+}
+
+// CleanupTempFiles does nothing since this creates no temp files.
+func (v *Vector) CleanupTempFiles() {
+}
+
+// IsVectorFile returns true if the file is a vector format that can be imported.
+func IsVectorFile(filename string) bool {
+	return vectorExtensions[strings.ToLower(path.Ext(filename))]
+}
+
+func findVectorFile(folder string) (string, error) {
+	dirQueue := []string{folder}
+	for len(dirQueue) > 0 {
+		currentDir := dirQueue[0]
+		dirQueue = dirQueue[1:]
+		files, err := ioutil.ReadDir(currentDir)
+		if err != nil {
+			return "", errors.Wrap(err, "unable to read vector dataset folder")
+		}
+		for _, f := range files {
+			filename := path.Join(currentDir, f.Name())
+			if f.IsDir() {
+				dirQueue = append(dirQueue, filename)
+			} else if IsVectorFile(filename) {
+				return filename, nil
+			}
+		}
+	}
+
+	return "", errors.Errorf("no vector file found in '%s'", folder)
+}
+
+func mapVectorFieldType(fieldType gdal.FieldType) string {
+	switch fieldType {
+	case gdal.FT_Integer, gdal.FT_Integer64:
+		return model.IntegerType
+	case gdal.FT_Real:
+		return model.RealType
+	case gdal.FT_Date, gdal.FT_DateTime:
+		return model.DateTimeType
+	case gdal.FT_String:
+		return model.CategoricalType
+	default:
+		return model.StringType
+	}
+}
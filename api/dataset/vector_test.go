@@ -0,0 +1,58 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package dataset
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uncharted-distil/distil-compute/model"
+	"github.com/uncharted-distil/gdal"
+)
+
+func TestFindVectorFile(t *testing.T) {
+	folder := t.TempDir()
+	nested := path.Join(folder, "regions", "shapes")
+	assert.NoError(t, os.MkdirAll(nested, 0755))
+
+	// sidecar files are not vector files and nested folders are searched
+	assert.NoError(t, ioutil.WriteFile(path.Join(folder, "readme.txt"), []byte("regions"), 0644))
+	assert.NoError(t, ioutil.WriteFile(path.Join(nested, "regions.dbf"), []byte{}, 0644))
+	assert.NoError(t, ioutil.WriteFile(path.Join(nested, "regions.SHP"), []byte{}, 0644))
+
+	filename, err := findVectorFile(folder)
+	assert.NoError(t, err)
+	assert.Equal(t, path.Join(nested, "regions.SHP"), filename)
+
+	_, err = findVectorFile(t.TempDir())
+	assert.Error(t, err)
+
+	_, err = findVectorFile(path.Join(folder, "missing"))
+	assert.Error(t, err)
+}
+
+func TestMapVectorFieldType(t *testing.T) {
+	assert.Equal(t, model.IntegerType, mapVectorFieldType(gdal.FT_Integer))
+	assert.Equal(t, model.IntegerType, mapVectorFieldType(gdal.FT_Integer64))
+	assert.Equal(t, model.RealType, mapVectorFieldType(gdal.FT_Real))
+	assert.Equal(t, model.DateTimeType, mapVectorFieldType(gdal.FT_Date))
+	assert.Equal(t, model.DateTimeType, mapVectorFieldType(gdal.FT_DateTime))
+	assert.Equal(t, model.CategoricalType, mapVectorFieldType(gdal.FT_String))
+	assert.Equal(t, model.StringType, mapVectorFieldType(gdal.FT_Binary))
+}
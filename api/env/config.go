@@ -21,6 +21,19 @@ import (
 	"github.com/caarlos0/env"
 )
 
+const (
+	// MetadataStorageElastic stores the dataset metadata and exported models in elasticsearch.
+	MetadataStorageElastic = "elastic"
+	// MetadataStoragePostgres stores the dataset metadata and exported models in postgres.
+	MetadataStoragePostgres = "postgres"
+	// MetadataStorageSQLite stores the dataset metadata and exported models in the embedded sqlite database.
+	MetadataStorageSQLite = "sqlite"
+	// DataStoragePostgres stores the dataset data and solution results in postgres.
+	DataStoragePostgres = "postgres"
+	// DataStorageSQLite stores the dataset data and solution results in the embedded sqlite database.
+	DataStorageSQLite = "sqlite"
+)
+
 var (
 	cfg  *Config
 	once sync.Once
@@ -31,6 +44,7 @@ type Config struct {
 	AppPort                      string  `env:"PORT" envDefault:"8080"`
 	AugmentedSubFolder           string  `env:"AUGMENTED_SUBFOLDER" envDefault:"augmented"`
 	BatchSubFolder               string  `env:"BATCH_SUBFOLDER" envDefault:"batch"`
+	CheckpointSubFolder          string  `env:"CHECKPOINT_SUBFOLDER" envDefault:"checkpoints"`
 	ClassificationOutputPath     string  `env:"CLASSIFICATION_OUTPUT_PATH" envDefault:"classification.json"`
 	ClassificationEnabled        bool    `env:"CLASSIFICATION_ENABLED" envDefault:"true"`
 	ClusteringKMeans             bool    `env:"CLUSTERING_KMEANS" envDefault:"true"`
@@ -38,16 +52,19 @@ type Config struct {
 	ClusteringEnabled            bool    `env:"CLUSTERING_ENABLED" envDefault:"true"` // This disables select view clustering see routes/clustering.go
 	D3MInputDir                  string  `env:"D3MINPUTDIR" envDefault:"datasets"`
 	D3MOutputDir                 string  `env:"D3MOUTPUTDIR" envDefault:"outputs"`
+	DataQualityOutputPath        string  `env:"DATA_QUALITY_OUTPUT_PATH" envDefault:"data-quality.json"`
 	DatamartURIISI               string  `env:"DATAMART_ISI_URL" envDefault:"https://dsbox02.isi.edu:9000"`
 	DatamartURINYU               string  `env:"DATAMART_NYU_URL" envDefault:"https://auctus.vida-nyu.org"`
 	DatamartISIEnabled           bool    `env:"DATAMART_ISI_ENABLED" envDefault:"false"`
 	DatamartNYUEnabled           bool    `env:"DATAMART_NYU_ENABLED" envDefault:"false"`
 	DatamartImportFolder         string  `env:"DATAMART_IMPORT_FOLDER" envDefault:"datamart"`
 	DatasetBatchSize             int     `env:"DATASET_BATCH_SIZE" envDefault:"10000"`
+	DataStorage                  string  `env:"DATA_STORAGE" envDefault:"postgres"`
 	DeleteBufferTime             int     `env:"DELETE_BUFFER_TIME" envDefault:"600"`
 	ElasticEndpoint              string  `env:"ES_ENDPOINT" envDefault:"http://localhost:9200"`
 	ESDatasetsIndex              string  `env:"ES_DATASETS_INDEX" envDefault:"datasets"`
-	ESModelsIndex                string  `env:"ES_DATASETS_INDEX" envDefault:"models"`
+	ESLineageIndex               string  `env:"ES_LINEAGE_INDEX" envDefault:"lineage"`
+	ESModelsIndex                string  `env:"ES_MODELS_INDEX" envDefault:"models"`
 	FastDataPercentage           float64 `env:"FAST_DATA_PERCENTAGE" envDefault:"0.2"`
 	FeaturizationEnabled         bool    `env:"FEATURIZATION_ENABLED" envDefault:"false"`
 	GeocodingEnabled             bool    `env:"GEOCODING_ENABLED" envDefault:"false"`
@@ -61,6 +78,7 @@ type Config struct {
 	LogUserAction                bool    `env:"LOG_USER_ACTION" envDefault:"true"`
 	MaxTrainingRows              int     `env:"MAX_TRAINING_ROWS" envDefault:"100000"`
 	MaxTestRows                  int     `env:"MAX_TEST_ROWS" envDefault:"100000"`
+	MetadataStorage              string  `env:"METADATA_STORAGE" envDefault:"elastic"`
 	MinTrainingRows              int     `env:"MIN_TRAINING_ROWS" envDefault:"100"`
 	MinTestRows                  int     `env:"MIN_TEST_ROWS" envDefault:"100"`
 	ModelType                    int     `env:"MODEL_TYPE" envDefault:"1"` // 0 is NOISE_CANCEL x2, 1 is GAN x4
@@ -70,6 +88,7 @@ type Config struct {
 	PipelineCacheEnabled         bool    `env:"PIPELINE_CACHE_ENABLED" envDefault:"true"`
 	PipelineCacheFilename        string  `env:"PIPELINE_CACHE_FILENAME" envDefault:"cache.bin"`
 	PipelineQueueSize            int     `env:"PIPELINE_QUEUE_SIZE" envDefault:"10"`
+	PipelineQueueWorkers         int     `env:"PIPELINE_QUEUE_WORKERS" envDefault:"2"`
 	PoolFeatures                 bool    `env:"POOL_FEATURES" envDefault:"true"`
 	PostgresBatchSize            int     `env:"PG_BATCH_SIZE" envDefault:"1000"`
 	PostgresDatabase             string  `env:"PG_DATABASE" envDefault:"distil"`
@@ -85,6 +104,7 @@ type Config struct {
 	RemoteSensingNumJobs         int     `env:"REMOTE_SENSING_NUM_JOBS" envDefault:"-1"` // -1 sets num jobs = num cpus
 	ResourceSubFolder            string  `env:"RESOURCE_SUBFOLDER" envDefault:"resources"`
 	ShouldScaleImages            bool    `env:"SHOULD_SCALE_IMAGES" envDefault:"false"` // enables and disables image scaling
+	SQLitePath                   string  `env:"SQLITE_PATH" envDefault:"distil.db"`
 	SkipPreprocessing            bool    `env:"SKIP_PREPROCESSING" envDefault:"false"`
 	SolutionComputeEndpoint      string  `env:"SOLUTION_COMPUTE_ENDPOINT" envDefault:"localhost:50051"`
 	SolutionComputePullTimeout   int     `env:"SOLUTION_COMPUTE_PULL_TIMEOUT" envDefault:"60"`
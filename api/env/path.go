@@ -33,11 +33,12 @@ var (
 	contribPath = ""
 	outputPath  = ""
 
-	seedSubPath   = ""
-	augmentedPath = ""
-	batchPath     = ""
-	publicPath    = ""
-	resourcePath  = ""
+	seedSubPath    = ""
+	augmentedPath  = ""
+	batchPath      = ""
+	checkpointPath = ""
+	publicPath     = ""
+	resourcePath   = ""
 
 	initialized = false
 	isTask      = false
@@ -63,6 +64,7 @@ func Initialize(config *Config) error {
 	contribPath = config.DatamartImportFolder
 	augmentedPath = path.Join(config.D3MOutputDir, config.AugmentedSubFolder)
 	batchPath = path.Join(config.D3MOutputDir, config.BatchSubFolder)
+	checkpointPath = path.Join(config.D3MOutputDir, config.CheckpointSubFolder)
 	publicPath = path.Join(config.D3MOutputDir, config.PublicSubFolder)
 	resourcePath = path.Join(config.D3MOutputDir, config.ResourceSubFolder)
 
@@ -72,6 +74,7 @@ func Initialize(config *Config) error {
 	log.Infof("using '%s' as contrib path", contribPath)
 	log.Infof("using '%s' as augmented path", augmentedPath)
 	log.Infof("using '%s' as batch path", batchPath)
+	log.Infof("using '%s' as checkpoint path", checkpointPath)
 	log.Infof("using '%s' as public path", publicPath)
 	log.Infof("using '%s' as resource path", resourcePath)
 
@@ -152,6 +155,11 @@ func GetBatchPath() string {
 	return batchPath
 }
 
+// GetCheckpointPath returns the ingest checkpoint path as initialized.
+func GetCheckpointPath() string {
+	return checkpointPath
+}
+
 // GetPublicPath returns the public path as initialized.
 func GetPublicPath() string {
 	return publicPath
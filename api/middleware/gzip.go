@@ -40,6 +40,10 @@ func isImage(r *http.Request) bool {
 	return strings.Contains(r.Header.Get("Accept"), "image")
 }
 
+func isEventStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
 func isWebsocketUpgrade(r *http.Request) bool {
 	return r.Header.Get("Upgrade") == "websocket"
 }
@@ -47,7 +51,7 @@ func isWebsocketUpgrade(r *http.Request) bool {
 // Gzip represents a middleware handler to support gzip compression.
 func Gzip(h http.Handler) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
-		if !isGzipSupported(r) || isWebsocketUpgrade(r) || isImage(r) {
+		if !isGzipSupported(r) || isWebsocketUpgrade(r) || isImage(r) || isEventStream(r) {
 			// do not use gzip
 			h.ServeHTTP(w, r)
 			return
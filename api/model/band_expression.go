@@ -0,0 +1,40 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package model
+
+// BandExpression is a user defined band math expression registered against a
+// multiband image dataset.  It is rendered like any other band combination,
+// with the expression result over [Min, Max] mapped onto the named color ramp.
+type BandExpression struct {
+	ID          string  `json:"id"`
+	DisplayName string  `json:"displayName"`
+	Expression  string  `json:"expression"`
+	Ramp        string  `json:"ramp"`
+	Min         float64 `json:"min"`
+	Max         float64 `json:"max"`
+}
+
+// GetBandExpression returns the band expression registered with the supplied ID, or
+// nil if there is none.
+func (d *Dataset) GetBandExpression(id string) *BandExpression {
+	for _, e := range d.BandExpressions {
+		if e.ID == id {
+			return e
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package model
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/uncharted-distil/distil/api/env"
+	"github.com/uncharted-distil/distil/api/util"
+)
+
+// DataQualityReport captures the quality of the data of a dataset as observed
+// during ingest.
+type DataQualityReport struct {
+	Dataset            string                   `json:"dataset"`
+	RowCount           int                      `json:"rowCount"`
+	DuplicateIndexRows int                      `json:"duplicateIndexRows"`
+	Variables          []*VariableQualityReport `json:"variables"`
+	Created            time.Time                `json:"created"`
+}
+
+// VariableQualityReport captures the data quality of a single variable. The
+// validity of the current and suggested types is verified by the data storage,
+// and out of range values are only counted for coordinates.
+type VariableQualityReport struct {
+	Key               string             `json:"key"`
+	Type              string             `json:"type"`
+	MissingCount      int                `json:"missingCount"`
+	MissingRate       float64            `json:"missingRate"`
+	DistinctCount     int                `json:"distinctCount"`
+	DistinctCapped    bool               `json:"distinctCapped"`
+	Constant          bool               `json:"constant"`
+	HighCardinality   bool               `json:"highCardinality"`
+	OutOfRangeCount   int                `json:"outOfRangeCount"`
+	TypeValid         map[string]bool    `json:"typeValid"`
+	TypeProbabilities map[string]float64 `json:"typeProbabilities"`
+}
+
+// GetDataQualityReportPath returns the location of the data quality report
+// of a dataset, stored next to the dataset metadata.
+func GetDataQualityReportPath(ds *Dataset, reportRelativePath string) string {
+	return path.Join(env.ResolvePath(ds.Source, ds.Folder), reportRelativePath)
+}
+
+// LoadDataQualityReport reads a data quality report from disk.
+func LoadDataQualityReport(reportPath string) (*DataQualityReport, error) {
+	data, err := ioutil.ReadFile(reportPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read data quality report")
+	}
+
+	report := &DataQualityReport{}
+	err = json.Unmarshal(data, report)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse data quality report")
+	}
+
+	return report, nil
+}
+
+// WriteDataQualityReport writes a data quality report to disk.
+func WriteDataQualityReport(reportPath string, report *DataQualityReport) error {
+	data, err := json.MarshalIndent(report, "", "    ")
+	if err != nil {
+		return errors.Wrapf(err, "unable to serialize data quality report")
+	}
+
+	err = util.WriteFileWithDirs(reportPath, data, os.ModePerm)
+	if err != nil {
+		return errors.Wrapf(err, "unable to write data quality report")
+	}
+
+	return nil
+}
@@ -62,6 +62,8 @@ type Dataset struct {
 	Immutable       bool                   `json:"immutable"`
 	ParentDataset   string                 `json:"parentDataset"`
 	Deleted         bool                   `json:"deleted"`
+	Sensor          string                 `json:"sensor"`
+	BandExpressions []*BandExpression      `json:"bandExpressions"`
 }
 
 // JoinSuggestion specifies potential joins between datasets.
@@ -40,6 +40,12 @@ const (
 	maxReportedErrors = 50
 )
 
+const (
+	// MissingFilter selects rows where the value of a variable is missing,
+	// regardless of the underlying variable type.
+	MissingFilter = "missing"
+)
+
 var (
 	// FilterModeInverse provides a quick way to inverse filter modes
 	FilterModeInverse = map[string]string{model.ExcludeFilter: model.IncludeFilter, model.IncludeFilter: model.ExcludeFilter}
@@ -57,13 +63,25 @@ func DataModeFromString(s string) (DataMode, error) {
 	}
 }
 
+// NewMissingFilter instantiates a filter that matches rows with a missing value
+// for the supplied variable.
+func NewMissingFilter(key string, mode string) *model.Filter {
+	return &model.Filter{
+		Key:  key,
+		Type: MissingFilter,
+		Mode: mode,
+	}
+}
+
 // FilterParams defines the set of filters to use. Note that this is to be used
 // by the server only, and not the client. Filters are gathered by mode (include/exclude),
-// with each mode being a list of features that are used as filters.
+// with each mode being a list of features that are used as filters. The optional
+// expression is ANDed with the filters.
 type FilterParams struct {
 	Size       int                `json:"size"`
 	Filters    []*model.FilterSet `json:"filters"`
 	Highlights []*model.FilterSet `json:"highlights"`
+	Expression *FilterExpression  `json:"expression,omitempty"`
 	Variables  []string           `json:"variables"`
 	DataMode   DataMode           `json:"dataMode"`
 	Invert     bool               `json:"invert"`
@@ -112,6 +130,16 @@ func GetBaselineFilter(filterParam *FilterParams) *FilterParams {
 			})
 		}
 	}
+	// the expression is only part of the baseline if it is made of baseline filters
+	if filterParam.Expression != nil && !filterParam.Expression.IsEmpty() {
+		isBaseline := true
+		for _, f := range filterParam.Expression.GetFilters() {
+			isBaseline = isBaseline && f.IsBaselineFilter
+		}
+		if isBaseline {
+			clone.Expression = filterParam.Expression.Clone()
+		}
+	}
 	clone.Variables = append(clone.Variables, filterParam.Variables...)
 	clone.Size = filterParam.Size
 	clone.DataMode = filterParam.DataMode
@@ -129,6 +157,9 @@ func (f *FilterParams) Clone() *FilterParams {
 	for _, highlights := range f.Highlights {
 		clone.Highlights = append(clone.Highlights, highlights.Clone())
 	}
+	if f.Expression != nil {
+		clone.Expression = f.Expression.Clone()
+	}
 	clone.Invert = f.Invert
 	clone.Variables = append(clone.Variables, f.Variables...)
 	clone.Size = f.Size
@@ -188,6 +219,13 @@ func (f *FilterParams) IsEmpty(ignoreBaselineFilters bool) bool {
 			}
 		}
 	}
+	if f.Expression != nil {
+		for _, filter := range f.Expression.GetFilters() {
+			if !filter.IsBaselineFilter || !ignoreBaselineFilters {
+				return false
+			}
+		}
+	}
 	return true
 }
 
@@ -565,6 +603,15 @@ func parseFilter(filter map[string]interface{}) (*model.Filter, error) {
 		return model.NewRowFilter(mode, indices), nil
 	}
 
+	// missing
+	if typ == MissingFilter {
+		key, ok := json.String(filter, "key")
+		if !ok {
+			return nil, errors.Errorf("no `key` provided for filter")
+		}
+		return NewMissingFilter(key, mode), nil
+	}
+
 	return nil, fmt.Errorf("filter not recognized")
 }
 
@@ -662,6 +709,18 @@ func ParseFilterParamsFromJSON(params map[string]interface{}) (*FilterParams, er
 			filterParams.Filters = append(filterParams.Filters, filterSet)
 		}
 	}
+	// parse the boolean filter expression
+	expression, ok := json.Get(params, "expression")
+	if ok {
+		filterParams.Expression, err = parseFilterExpression(expression)
+		if err != nil {
+			return nil, err
+		}
+		err = filterParams.Expression.Validate()
+		if err != nil {
+			return nil, err
+		}
+	}
 	// We might need to throw an error if no variables are passed?
 	variables, ok := json.StringArray(params, "variables")
 
@@ -0,0 +1,164 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package model
+
+import (
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+
+	"github.com/uncharted-distil/distil/api/util/json"
+)
+
+const (
+	// FilterExpressionAnd matches the rows matched by all of the child expressions.
+	FilterExpressionAnd = "and"
+	// FilterExpressionOr matches the rows matched by any of the child expressions.
+	FilterExpressionOr = "or"
+)
+
+// FilterExpression is a node of a boolean filter expression tree. A leaf node
+// holds a single filter, with exclude filters negating the match. A group node
+// combines its children using its operator. Any node can be negated, allowing
+// conditions such as "(region = A AND price > 10) OR flagged = true" that the
+// include / exclude filter sets cannot express.
+type FilterExpression struct {
+	Operator string              `json:"operator,omitempty"`
+	Not      bool                `json:"not,omitempty"`
+	Filter   *model.Filter       `json:"filter,omitempty"`
+	Children []*FilterExpression `json:"children,omitempty"`
+}
+
+// NewFilterExpression creates a group node combining the children using the
+// supplied operator.
+func NewFilterExpression(operator string, children ...*FilterExpression) *FilterExpression {
+	return &FilterExpression{
+		Operator: operator,
+		Children: children,
+	}
+}
+
+// NewFilterExpressionLeaf creates a leaf node matching a single filter.
+func NewFilterExpressionLeaf(filter *model.Filter) *FilterExpression {
+	return &FilterExpression{
+		Filter: filter,
+	}
+}
+
+// IsLeaf returns true if the node holds a single filter.
+func (e *FilterExpression) IsLeaf() bool {
+	return e.Filter != nil
+}
+
+// IsEmpty returns true if the expression does not contain any filter.
+func (e *FilterExpression) IsEmpty() bool {
+	return len(e.GetFilters()) == 0
+}
+
+// GetFilters returns the filters of every leaf of the expression.
+func (e *FilterExpression) GetFilters() []*model.Filter {
+	if e.IsLeaf() {
+		return []*model.Filter{e.Filter}
+	}
+	filters := []*model.Filter{}
+	for _, child := range e.Children {
+		filters = append(filters, child.GetFilters()...)
+	}
+	return filters
+}
+
+// Clone returns a deep copy of the expression.
+func (e *FilterExpression) Clone() *FilterExpression {
+	clone := &FilterExpression{
+		Operator: e.Operator,
+		Not:      e.Not,
+	}
+	if e.Filter != nil {
+		filter := *e.Filter
+		clone.Filter = &filter
+	}
+	for _, child := range e.Children {
+		clone.Children = append(clone.Children, child.Clone())
+	}
+	return clone
+}
+
+// Validate checks that the expression is well formed. Expressions are applied
+// to the dataset variables so result facets cannot be referenced.
+func (e *FilterExpression) Validate() error {
+	if e.IsLeaf() {
+		if len(e.Children) > 0 {
+			return errors.Errorf("filter expression leaf on `%s` cannot have children", e.Filter.Key)
+		}
+		key := e.Filter.Key
+		if IsPredictedKey(key) || IsErrorKey(key) || IsConfidenceKey(key) || IsRankKey(key) {
+			return errors.Errorf("filter expression cannot reference result variable `%s`", key)
+		}
+		return nil
+	}
+
+	if e.Operator != FilterExpressionAnd && e.Operator != FilterExpressionOr {
+		return errors.Errorf("unrecognized filter expression operator `%s`", e.Operator)
+	}
+	if len(e.Children) == 0 {
+		return errors.Errorf("filter expression `%s` group has no children", e.Operator)
+	}
+	for _, child := range e.Children {
+		err := child.Validate()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseFilterExpression(expression map[string]interface{}) (*FilterExpression, error) {
+	not, _ := json.Bool(expression, "not")
+
+	filter, ok := json.Get(expression, "filter")
+	if ok {
+		f, err := parseFilter(filter)
+		if err != nil {
+			return nil, err
+		}
+		return &FilterExpression{
+			Not:    not,
+			Filter: f,
+		}, nil
+	}
+
+	operator, ok := json.String(expression, "operator")
+	if !ok {
+		return nil, errors.Errorf("no `operator` or `filter` provided for filter expression")
+	}
+	children, ok := json.Array(expression, "children")
+	if !ok {
+		return nil, errors.Errorf("no `children` provided for filter expression")
+	}
+	parsed := &FilterExpression{
+		Operator: operator,
+		Not:      not,
+		Children: []*FilterExpression{},
+	}
+	for _, child := range children {
+		c, err := parseFilterExpression(child)
+		if err != nil {
+			return nil, err
+		}
+		parsed.Children = append(parsed.Children, c)
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,72 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uncharted-distil/distil-compute/model"
+)
+
+func TestParseFilterExpression(t *testing.T) {
+	raw := []byte(`{
+		"filters": {"invert": false, "list": []},
+		"highlights": {"invert": false, "list": []},
+		"expression": {
+			"operator": "or",
+			"children": [
+				{"operator": "and", "children": [
+					{"filter": {"type": "categorical", "mode": "include", "key": "region", "categories": ["A"]}},
+					{"filter": {"type": "numerical", "mode": "include", "key": "price", "min": 10, "max": 100}}
+				]},
+				{"not": true, "filter": {"type": "categorical", "mode": "include", "key": "flagged", "categories": ["false"]}}
+			]
+		}
+	}`)
+
+	params, err := ParseFilterParamsFromJSONRaw(raw)
+	assert.NoError(t, err)
+	assert.NotNil(t, params.Expression)
+	assert.Equal(t, FilterExpressionOr, params.Expression.Operator)
+	assert.Len(t, params.Expression.Children, 2)
+	assert.True(t, params.Expression.Children[1].Not)
+	assert.False(t, params.IsEmpty(false))
+
+	keys := []string{}
+	for _, f := range params.Expression.GetFilters() {
+		keys = append(keys, f.Key)
+	}
+	assert.Equal(t, []string{"region", "price", "flagged"}, keys)
+
+	// clones do not share filters
+	clone := params.Clone()
+	clone.Expression.Children[0].Children[0].Filter.Key = "area"
+	assert.Equal(t, "region", params.Expression.Children[0].Children[0].Filter.Key)
+
+	// non baseline expressions are not part of the baseline
+	assert.Nil(t, GetBaselineFilter(params).Expression)
+}
+
+func TestValidateFilterExpression(t *testing.T) {
+	leaf := NewFilterExpressionLeaf(model.NewCategoricalFilter("region", model.IncludeFilter, []string{"A"}))
+	assert.NoError(t, NewFilterExpression(FilterExpressionAnd, leaf).Validate())
+	assert.Error(t, NewFilterExpression("xor", leaf).Validate())
+	assert.Error(t, NewFilterExpression(FilterExpressionOr).Validate())
+
+	result := NewFilterExpressionLeaf(model.NewNumericalFilter(GetErrorKey("solution"), model.IncludeFilter, 0, 1))
+	assert.Error(t, NewFilterExpression(FilterExpressionOr, leaf, result).Validate())
+}
@@ -0,0 +1,37 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uncharted-distil/distil-compute/model"
+)
+
+func TestParseMissingFilter(t *testing.T) {
+	raw := []byte(`{
+		"filters": {"invert": false, "list": [[{"type": "missing", "mode": "exclude", "key": "price"}]]},
+		"highlights": {"invert": false, "list": []}
+	}`)
+
+	params, err := ParseFilterParamsFromJSONRaw(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, NewMissingFilter("price", model.ExcludeFilter), params.Filters[0].FeatureFilters[0].List[0])
+
+	_, err = ParseFilterParamsFromJSONRaw([]byte(`{"filters": {"list": [[{"type": "missing", "mode": "include"}]]}}`))
+	assert.Error(t, err)
+}
@@ -85,6 +85,11 @@ func ExpandFilterParams(dataset string, filterParams *FilterParams, includeHidde
 				}
 			}
 		}
+		if updatedFilterParams.Expression != nil {
+			for _, f := range updatedFilterParams.Expression.GetFilters() {
+				UpdateFilterKey(metaStore, dataset, updatedFilterParams.DataMode, f, variable)
+			}
+		}
 	}
 
 	// create variable lookup
@@ -0,0 +1,85 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	// LineageOperationClone denotes a dataset cloned from another.
+	LineageOperationClone = "clone"
+	// LineageOperationSave denotes a dataset saved with the filters applied.
+	LineageOperationSave = "save"
+	// LineageOperationJoin denotes a dataset joined from two others.
+	LineageOperationJoin = "join"
+	// LineageOperationUnion denotes the union of two datasets.
+	LineageOperationUnion = "union"
+	// LineageOperationAddField denotes a field added to a dataset.
+	LineageOperationAddField = "add-field"
+	// LineageOperationPrediction denotes a dataset imported for predictions.
+	LineageOperationPrediction = "prediction"
+	// LineageOperationPredictionResult denotes a dataset created from prediction results.
+	LineageOperationPredictionResult = "prediction-result"
+)
+
+// LineageEdge records how a dataset was derived from its source datasets.
+// Operations that modify a dataset in place list the dataset as its own source.
+type LineageEdge struct {
+	ID         string                 `json:"id"`
+	Operation  string                 `json:"operation"`
+	Target     string                 `json:"target"`
+	Sources    []string               `json:"sources"`
+	Parameters map[string]interface{} `json:"parameters"`
+	Filters    *FilterParams          `json:"filters"`
+	Created    time.Time              `json:"created"`
+}
+
+// LineageNode is a dataset in a lineage graph.
+type LineageNode struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Exists  bool   `json:"exists"`
+	Deleted bool   `json:"deleted"`
+}
+
+// LineageGraph is the directed acyclic graph of derivations leading to a
+// dataset.
+type LineageGraph struct {
+	Dataset string         `json:"dataset"`
+	Nodes   []*LineageNode `json:"nodes"`
+	Edges   []*LineageEdge `json:"edges"`
+}
+
+// NewLineageEdge creates a new lineage edge for the derivation of the target
+// dataset.
+func NewLineageEdge(operation string, target string, sources []string, parameters map[string]interface{}, filters *FilterParams) *LineageEdge {
+	if parameters == nil {
+		parameters = map[string]interface{}{}
+	}
+
+	return &LineageEdge{
+		ID:         uuid.Must(uuid.NewV4()).String(),
+		Operation:  operation,
+		Target:     target,
+		Sources:    sources,
+		Parameters: parameters,
+		Filters:    filters,
+		Created:    time.Now(),
+	}
+}
@@ -46,6 +46,11 @@ type ExportedModel struct {
 	Variables        []string            `json:"variables"`
 	VariableDetails  []*SolutionVariable `json:"variableDetails"`
 	Deleted          bool                `json:"deleted"`
+	Version          int                 `json:"version"`
+	Stage            string              `json:"stage"`
+	Lineage          *LineageGraph       `json:"lineage"`
+	Metrics          []*SolutionScore    `json:"metrics"`
+	CreatedTime      time.Time           `json:"created"`
 }
 
 // Request represents the request metadata.
@@ -59,6 +64,16 @@ type Request struct {
 	Filters         *FilterParams `json:"filters"`
 }
 
+// SolutionStatusEvent represents a persisted status update of a solution request.
+type SolutionStatusEvent struct {
+	RequestID   string    `json:"requestId"`
+	SolutionID  string    `json:"solutionId"`
+	ResultID    string    `json:"resultId"`
+	Progress    string    `json:"progress"`
+	Error       string    `json:"error"`
+	CreatedTime time.Time `json:"timestamp"`
+}
+
 // Prediction represents the prediction metadata.
 type Prediction struct {
 	RequestID        string    `json:"requestId"`
@@ -166,6 +181,9 @@ type SolutionScore struct {
 	Metric         string  `json:"metric"`
 	Label          string  `json:"label"`
 	Score          float64 `json:"value"`
+	Mean           float64 `json:"mean"`
+	StdDev         float64 `json:"stdDev"`
+	Folds          int     `json:"folds"`
 	SortMultiplier float64 `json:"sortMultiplier"`
 }
 
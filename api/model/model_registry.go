@@ -0,0 +1,163 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package model
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// ModelStageStaging is the stage of a newly saved model version.
+	ModelStageStaging = "staging"
+	// ModelStageProduction is the stage of the model version in active use.
+	// At most one version of a model is in production at a time.
+	ModelStageProduction = "production"
+	// ModelStageArchived is the stage of a retired model version.
+	ModelStageArchived = "archived"
+)
+
+// ModelScoreDiff is the difference in a metric between two model versions.
+type ModelScoreDiff struct {
+	Metric         string  `json:"metric"`
+	Label          string  `json:"label"`
+	Base           float64 `json:"base"`
+	Other          float64 `json:"other"`
+	Delta          float64 `json:"delta"`
+	SortMultiplier float64 `json:"sortMultiplier"`
+}
+
+// ModelRankDiff is the difference in the rank of a feature shared by two
+// model versions.
+type ModelRankDiff struct {
+	Key         string  `json:"key"`
+	DisplayName string  `json:"displayName"`
+	Base        float64 `json:"base"`
+	Other       float64 `json:"other"`
+	Delta       float64 `json:"delta"`
+}
+
+// ModelVersionComparison captures the differences between two versions of
+// a registered model.
+type ModelVersionComparison struct {
+	ModelName       string            `json:"modelName"`
+	BaseVersion     int               `json:"baseVersion"`
+	OtherVersion    int               `json:"otherVersion"`
+	Scores          []*ModelScoreDiff `json:"scores"`
+	FeaturesAdded   []string          `json:"featuresAdded"`
+	FeaturesRemoved []string          `json:"featuresRemoved"`
+	FeaturesCommon  []string          `json:"featuresCommon"`
+	Ranks           []*ModelRankDiff  `json:"ranks"`
+}
+
+// IsModelStage returns true if the stage is a valid model stage.
+func IsModelStage(stage string) bool {
+	return stage == ModelStageStaging || stage == ModelStageProduction || stage == ModelStageArchived
+}
+
+// NextModelVersion returns the version to assign to a newly registered model
+// given all existing versions of that model, including deleted ones, so that
+// version numbers are never reused.
+func NextModelVersion(versions []*ExportedModel) int {
+	next := 1
+	for _, v := range versions {
+		if v.Version >= next {
+			next = v.Version + 1
+		}
+	}
+	return next
+}
+
+// CompareModelVersions diffs the scores, features and variable rankings of
+// two model versions. Deltas are computed as other - base.
+func CompareModelVersions(base *ExportedModel, other *ExportedModel) *ModelVersionComparison {
+	comparison := &ModelVersionComparison{
+		ModelName:       base.ModelName,
+		BaseVersion:     base.Version,
+		OtherVersion:    other.Version,
+		Scores:          []*ModelScoreDiff{},
+		FeaturesAdded:   []string{},
+		FeaturesRemoved: []string{},
+		FeaturesCommon:  []string{},
+		Ranks:           []*ModelRankDiff{},
+	}
+
+	// only metrics computed for both versions can be compared
+	otherScores := make(map[string]*SolutionScore)
+	for _, s := range other.Metrics {
+		otherScores[s.Metric] = s
+	}
+	for _, s := range base.Metrics {
+		o, ok := otherScores[s.Metric]
+		if !ok {
+			continue
+		}
+		comparison.Scores = append(comparison.Scores, &ModelScoreDiff{
+			Metric:         s.Metric,
+			Label:          s.Label,
+			Base:           s.Score,
+			Other:          o.Score,
+			Delta:          o.Score - s.Score,
+			SortMultiplier: s.SortMultiplier,
+		})
+	}
+
+	baseVars := make(map[string]*SolutionVariable)
+	for _, v := range base.VariableDetails {
+		baseVars[v.Key] = v
+	}
+	otherVars := make(map[string]*SolutionVariable)
+	for _, v := range other.VariableDetails {
+		otherVars[v.Key] = v
+	}
+
+	for _, v := range base.VariableDetails {
+		o, ok := otherVars[v.Key]
+		if !ok {
+			comparison.FeaturesRemoved = append(comparison.FeaturesRemoved, v.Key)
+			continue
+		}
+		comparison.FeaturesCommon = append(comparison.FeaturesCommon, v.Key)
+		comparison.Ranks = append(comparison.Ranks, &ModelRankDiff{
+			Key:         v.Key,
+			DisplayName: v.DisplayName,
+			Base:        v.Rank,
+			Other:       o.Rank,
+			Delta:       o.Rank - v.Rank,
+		})
+	}
+	for _, v := range other.VariableDetails {
+		if baseVars[v.Key] == nil {
+			comparison.FeaturesAdded = append(comparison.FeaturesAdded, v.Key)
+		}
+	}
+
+	sort.Strings(comparison.FeaturesAdded)
+	sort.Strings(comparison.FeaturesRemoved)
+	sort.Strings(comparison.FeaturesCommon)
+
+	// largest rank changes first
+	sort.SliceStable(comparison.Ranks, func(i, j int) bool {
+		di := math.Abs(comparison.Ranks[i].Delta)
+		dj := math.Abs(comparison.Ranks[j].Delta)
+		if di != dj {
+			return di > dj
+		}
+		return comparison.Ranks[i].Key < comparison.Ranks[j].Key
+	})
+
+	return comparison
+}
@@ -0,0 +1,77 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextModelVersion(t *testing.T) {
+	assert.Equal(t, 1, NextModelVersion(nil))
+	assert.Equal(t, 4, NextModelVersion([]*ExportedModel{
+		{Version: 1},
+		{Version: 3, Deleted: true},
+		{Version: 2},
+	}))
+}
+
+func TestCompareModelVersions(t *testing.T) {
+	base := &ExportedModel{
+		ModelName: "model",
+		Version:   1,
+		Metrics: []*SolutionScore{
+			{Metric: "accuracy", Score: 0.75},
+			{Metric: "f1Macro", Score: 0.5},
+		},
+		VariableDetails: []*SolutionVariable{
+			{Key: "a", Rank: 0.5},
+			{Key: "b", Rank: 0.25},
+			{Key: "c", Rank: 0.1},
+		},
+	}
+	other := &ExportedModel{
+		ModelName: "model",
+		Version:   2,
+		Metrics: []*SolutionScore{
+			{Metric: "accuracy", Score: 0.8},
+		},
+		VariableDetails: []*SolutionVariable{
+			{Key: "d", Rank: 0.3},
+			{Key: "b", Rank: 0.05},
+			{Key: "a", Rank: 0.55},
+		},
+	}
+
+	comparison := CompareModelVersions(base, other)
+	assert.Equal(t, 1, comparison.BaseVersion)
+	assert.Equal(t, 2, comparison.OtherVersion)
+
+	assert.Len(t, comparison.Scores, 1)
+	assert.Equal(t, "accuracy", comparison.Scores[0].Metric)
+	assert.InDelta(t, 0.05, comparison.Scores[0].Delta, epsilon)
+
+	assert.Equal(t, []string{"d"}, comparison.FeaturesAdded)
+	assert.Equal(t, []string{"c"}, comparison.FeaturesRemoved)
+	assert.Equal(t, []string{"a", "b"}, comparison.FeaturesCommon)
+
+	assert.Len(t, comparison.Ranks, 2)
+	assert.Equal(t, "b", comparison.Ranks[0].Key)
+	assert.InDelta(t, -0.2, comparison.Ranks[0].Delta, epsilon)
+	assert.Equal(t, "a", comparison.Ranks[1].Key)
+	assert.InDelta(t, 0.05, comparison.Ranks[1].Delta, epsilon)
+}
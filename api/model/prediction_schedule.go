@@ -0,0 +1,55 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package model
+
+import (
+	"time"
+)
+
+const (
+	// PredictionRunRunning flags a scheduled prediction run in progress.
+	PredictionRunRunning = "running"
+	// PredictionRunComplete flags a scheduled prediction run that completed successfully.
+	PredictionRunComplete = "complete"
+	// PredictionRunFailed flags a scheduled prediction run that ended in error.
+	PredictionRunFailed = "failed"
+)
+
+// PredictionSchedule represents the recurring prediction of a fitted solution
+// against the files found in an input location.
+type PredictionSchedule struct {
+	ScheduleID       string    `json:"scheduleId"`
+	FittedSolutionID string    `json:"fittedSolutionId"`
+	InputPath        string    `json:"inputPath"`
+	Cron             string    `json:"cron"`
+	OutputPath       string    `json:"outputPath"`
+	Format           string    `json:"format"`
+	CreatedTime      time.Time `json:"timestamp"`
+}
+
+// PredictionScheduleRun represents a single run of a prediction schedule on
+// one input file.
+type PredictionScheduleRun struct {
+	RunID            string    `json:"runId"`
+	ScheduleID       string    `json:"scheduleId"`
+	InputPath        string    `json:"inputPath"`
+	Status           string    `json:"status"`
+	ProduceRequestID string    `json:"produceRequestId"`
+	OutputPath       string    `json:"outputPath"`
+	Error            string    `json:"error"`
+	StartTime        time.Time `json:"startTime"`
+	EndTime          time.Time `json:"endTime"`
+}
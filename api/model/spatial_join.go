@@ -0,0 +1,49 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package model
+
+const (
+	// SpatialJoinIntersects matches geometries that intersect.
+	SpatialJoinIntersects = "intersects"
+	// SpatialJoinContains matches right geometries contained in the left geometry.
+	SpatialJoinContains = "contains"
+	// SpatialJoinNearest matches right geometries within a distance (in meters) of the left geometry.
+	SpatialJoinNearest = "nearest"
+)
+
+// SpatialJoin captures the parameters of a join on the geometries of two datasets.
+// Every left row is matched to at most one right row, the nearest satisfying the
+// predicate, so that the left rows are preserved as they are by a regular join.
+// Right rows at the same distance are ordered by d3m index and the first is kept.
+type SpatialJoin struct {
+	Predicate string
+	Distance  float64
+	Inner     bool
+	Left      *SpatialJoinSide
+	Right     *SpatialJoinSide
+}
+
+// SpatialJoinSide captures the stored data of one side of a spatial join.
+type SpatialJoinSide struct {
+	StorageName string
+	GeometryCol string
+	Columns     []string
+}
+
+// IsValidSpatialPredicate returns true if the predicate is a supported spatial join predicate.
+func IsValidSpatialPredicate(predicate string) bool {
+	return predicate == SpatialJoinIntersects || predicate == SpatialJoinContains || predicate == SpatialJoinNearest
+}
@@ -0,0 +1,95 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package model
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/uncharted-distil/distil/api/util"
+)
+
+const (
+	// SplitTypeBasic denotes a random train/test split.
+	SplitTypeBasic = "basic"
+	// SplitTypeStratified denotes a train/test split stratified on the target.
+	SplitTypeStratified = "stratified"
+	// SplitTypeSemiSupervised denotes a stratified split keeping unlabeled rows in train.
+	SplitTypeSemiSupervised = "semi-supervised"
+	// SplitTypeTimeseries denotes a split on a timestamp value.
+	SplitTypeTimeseries = "timeseries"
+	// SplitTypeKFold denotes k-fold cross validation.
+	SplitTypeKFold = "kfold"
+	// SplitTypeStratifiedKFold denotes k-fold cross validation stratified on the target.
+	SplitTypeStratifiedKFold = "stratified-kfold"
+	// SplitTypeGroupKFold denotes k-fold cross validation keeping groups within a single fold.
+	SplitTypeGroupKFold = "group-kfold"
+	// SplitTypeRollingOrigin denotes time series cross validation over an expanding window.
+	SplitTypeRollingOrigin = "rolling-origin"
+)
+
+// SplitManifest records the exact train/test partitioning used for a solution
+// request so that a later search can be run against the identical split. For
+// cross validation, FoldTest lists the test rows of every fold, with the train
+// rows of a fold being all the other rows.
+type SplitManifest struct {
+	RequestID       string     `json:"requestId"`
+	SourceRequestID string     `json:"sourceRequestId,omitempty"`
+	Dataset         string     `json:"dataset"`
+	Splitter        string     `json:"splitter"`
+	Seed            int64      `json:"seed"`
+	TrainTestSplit  float64    `json:"trainTestSplit"`
+	Folds           int        `json:"folds,omitempty"`
+	FoldTest        [][]string `json:"foldTest,omitempty"`
+	Train           []string   `json:"train"`
+	Test            []string   `json:"test"`
+	CreatedTime     time.Time  `json:"createdTime"`
+}
+
+// LoadSplitManifest reads a split manifest from disk.
+func LoadSplitManifest(manifestPath string) (*SplitManifest, error) {
+	data, err := ioutil.ReadFile(manifestPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read split manifest")
+	}
+
+	manifest := &SplitManifest{}
+	err = json.Unmarshal(data, manifest)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse split manifest")
+	}
+
+	return manifest, nil
+}
+
+// WriteSplitManifest writes a split manifest to disk.
+func WriteSplitManifest(manifestPath string, manifest *SplitManifest) error {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return errors.Wrapf(err, "unable to serialize split manifest")
+	}
+
+	err = util.WriteFileWithDirs(manifestPath, data, os.ModePerm)
+	if err != nil {
+		return errors.Wrapf(err, "unable to write split manifest")
+	}
+
+	return nil
+}
@@ -77,11 +77,19 @@ const (
 // storage client.
 type DataStorageCtor func() (DataStorage, error)
 
+// DatasetStreamWriter receives the rows of a dataset as they are read from
+// storage so that large datasets never need to be held in memory.
+type DatasetStreamWriter interface {
+	WriteHeader(header []string) error
+	WriteRows(rows [][]string) error
+}
+
 // DataStorage defines the functions available to query the underlying data storage.
 type DataStorage interface {
 	FetchNumRows(storageName string, variables []*model.Variable) (int, error)
 	FetchData(dataset string, storageName string, filterParams *FilterParams, includeGroupingCol bool, orderByVar *model.Variable) (*FilteredData, error)
 	FetchDataset(dataset string, storageName string, includeMetadata bool, limitSelectedFields bool, filterParams *FilterParams) ([][]string, error)
+	StreamDataset(dataset string, storageName string, includeMetadata bool, limitSelectedFields bool, filterParams *FilterParams, writer DatasetStreamWriter) error
 	FetchResultDataset(dataset string, storageName string, predictionName string, features []string, resultURI string, includeExplain bool) ([][]string, error)
 	FetchSummary(dataset string, storageName string, varName string, filterParams *FilterParams, mode SummaryMode) (*VariableSummary, error)
 	FetchSummaryByResult(dataset string, storageName string, varName string, resultURI string, filterParams *FilterParams, extrema *Extrema, mode SummaryMode) (*VariableSummary, error)
@@ -103,6 +111,9 @@ type DataStorage interface {
 		seriesIDs []string, operation TimeseriesOp, resultUUID string, filterParams *FilterParams) ([]*TimeseriesData, error)
 	FetchCategoryCounts(storageName string, variable *model.Variable) (map[string]int, error)
 	FetchSolutionFeatureWeights(dataset string, storageName string, resultURI string, d3mIndex int64) (*SolutionFeatureWeight, error)
+	FetchTileImages(dataset string, storageName string, groupingCol string, coordinatesCol string, polygonCol string,
+		bounds *model.Bounds, resultURI string, limit int) ([]*TileImage, error)
+	FetchSpatialJoin(join *SpatialJoin) ([][]string, error)
 	// Dataset manipulation
 	IsValidDataType(dataset string, storageName string, varName string, varType string) (bool, error)
 	SetDataType(dataset string, storageName string, varName string, varType string) error
@@ -141,12 +152,19 @@ type SolutionStorage interface {
 	PersistRequest(requestID string, dataset string, progress string, createdTime time.Time) error
 	PersistRequestFeature(requestID string, featureName string, featureType string) error
 	PersistRequestFilters(requestID string, filters *FilterParams) error
+	PersistRequestSplitManifest(requestID string, manifest *SplitManifest) error
 	PersistSolution(requestID string, solutionID string, explainedSolutionID string, createdTime time.Time) error
 	PersistSolutionWeight(solutionID string, featureName string, featureIndex int64, weight float64) error
 	PersistSolutionState(solutionID string, progress string, createdTime time.Time) error
 	PersistSolutionResult(solutionID string, fittedSolutionID string, produceRequestID string, resultType string, resultUUID string, resultURI string, progress string, createdTime time.Time) error
 	PersistSolutionExplainedOutput(resultUUID string, explainOutput map[string]*SolutionExplainResult) error
 	PersistSolutionScore(solutionID string, metric string, score float64) error
+	PersistSolutionCrossValidationScore(solutionID string, metric string, mean float64, stdDev float64, folds int) error
+	PersistSolutionStatusEvent(event *SolutionStatusEvent) error
+	PersistPredictionSchedule(schedule *PredictionSchedule) error
+	PersistPredictionScheduleRun(run *PredictionScheduleRun) error
+	UpdatePredictionScheduleRun(run *PredictionScheduleRun) error
+	DeletePredictionSchedule(scheduleID string) error
 	UpdateRequest(requestID string, progress string, updatedTime time.Time) error
 	UpdateSolution(solutionID string, explainedSolutionID string) error
 	FetchRequest(requestID string) (*Request, error)
@@ -155,6 +173,8 @@ type SolutionStorage interface {
 	FetchRequestByDatasetTarget(dataset string, target string) ([]*Request, error)
 	FetchRequestFeatures(requestID string) ([]*Feature, error)
 	FetchRequestFilters(requestID string, features []*Feature) (*FilterParams, error)
+	FetchRequestSplitManifest(requestID string) (*SplitManifest, error)
+	FetchSolutionStatusEvents(requestID string) ([]*SolutionStatusEvent, error)
 	FetchSolution(solutionID string) (*Solution, error)
 	FetchExplainValues(dataset string, storageName string, d3mIndex []int, resultUUID string) ([]SolutionExplainValues, error)
 	FetchSolutionsByDatasetTarget(dataset string, target string) ([]*Solution, error)
@@ -169,6 +189,9 @@ type SolutionStorage interface {
 	FetchSolutionScores(solutionID string) ([]*SolutionScore, error)
 	FetchPrediction(requestID string) (*Prediction, error)
 	FetchPredictionsByFittedSolutionID(fittedSolutionID string) ([]*Prediction, error)
+	FetchPredictionSchedule(scheduleID string) (*PredictionSchedule, error)
+	FetchPredictionSchedules() ([]*PredictionSchedule, error)
+	FetchPredictionScheduleRuns(scheduleID string) ([]*PredictionScheduleRun, error)
 }
 
 // MetadataStorageCtor represents a client constructor to instantiate a
@@ -204,6 +227,10 @@ type MetadataStorage interface {
 
 	// CloneDataset creates a copy of an existing dataset
 	CloneDataset(dataset string, datasetNew string, storageNameNew string, folderNew string) error
+
+	// Dataset lineage
+	PersistLineageEdge(edge *LineageEdge) error
+	FetchLineageEdges(dataset string) ([]*LineageEdge, error)
 }
 
 // ExportedModelStorageCtor represents a client constructor to instantiate a
@@ -219,4 +246,5 @@ type ExportedModelStorage interface {
 	FetchModels(includeDeleted bool) ([]*ExportedModel, error)
 	SearchModels(terms string, includeDeleted bool) ([]*ExportedModel, error)
 	DeleteModel(fittedSolutionID string) error
+	FetchModelVersions(modelName string) ([]*ExportedModel, error)
 }
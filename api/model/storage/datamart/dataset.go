@@ -170,3 +170,13 @@ func (s *Storage) searchREST(searchText string, baseDataset *api.Dataset) ([]*ap
 
 	return datasets, nil
 }
+
+// PersistLineageEdge stores a lineage edge in the datamart.
+func (s *Storage) PersistLineageEdge(edge *api.LineageEdge) error {
+	return errors.Errorf("Not implemented")
+}
+
+// FetchLineageEdges returns the lineage edges of a dataset from the datamart.
+func (s *Storage) FetchLineageEdges(dataset string) ([]*api.LineageEdge, error) {
+	return nil, errors.Errorf("Not implemented")
+}
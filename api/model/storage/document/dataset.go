@@ -0,0 +1,238 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package document
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/metadata"
+	"github.com/uncharted-distil/distil-compute/model"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+// datasetDocument is the stored form of a dataset. Variables are parsed
+// separately since their grouping is an interface.
+type datasetDocument struct {
+	api.Dataset
+	Variables []json.RawMessage `json:"variables"`
+}
+
+// variableDocument is the stored form of a variable.
+type variableDocument struct {
+	model.Variable
+	Grouping json.RawMessage `json:"grouping"`
+}
+
+// ParseDataset parses a stored dataset document, flagging the dataset with
+// the provenance of the store it was read from.
+func ParseDataset(raw []byte, provenance string) (*api.Dataset, error) {
+	doc := &datasetDocument{}
+	err := json.Unmarshal(raw, doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse dataset document")
+	}
+
+	dataset := &doc.Dataset
+	dataset.Variables = []*model.Variable{}
+	for _, rawVariable := range doc.Variables {
+		variable, err := parseVariable(rawVariable)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse variables of dataset '%s'", dataset.ID)
+		}
+		dataset.Variables = append(dataset.Variables, variable)
+	}
+	dataset.Provenance = provenance
+	if dataset.Type == "" {
+		dataset.Type = api.DatasetTypeModelling
+	}
+	if dataset.BandExpressions == nil {
+		dataset.BandExpressions = []*api.BandExpression{}
+	}
+
+	return dataset, nil
+}
+
+func parseVariable(raw []byte) (*model.Variable, error) {
+	doc := &variableDocument{}
+	err := json.Unmarshal(raw, doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse variable document")
+	}
+
+	variable := &doc.Variable
+	variable.Grouping, err = parseGrouping(doc.Grouping)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse grouping of variable '%s'", variable.Key)
+	}
+	if variable.Role == nil {
+		variable.Role = []string{}
+	}
+	if variable.SuggestedTypes == nil {
+		variable.SuggestedTypes = []*model.SuggestedType{}
+	}
+	if variable.DisplayName == "" {
+		variable.DisplayName = variable.HeaderName
+	}
+
+	return variable, nil
+}
+
+func parseGrouping(raw json.RawMessage) (model.BaseGrouping, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	header := struct {
+		Type string `json:"type"`
+	}{}
+	err := json.Unmarshal(raw, &header)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read grouping type")
+	}
+
+	var grouping model.BaseGrouping
+	if header.Type == "" {
+		return nil, nil
+	} else if model.IsTimeSeries(header.Type) {
+		grouping = &model.TimeseriesGrouping{}
+	} else if model.IsGeoCoordinate(header.Type) {
+		grouping = &model.GeoCoordinateGrouping{}
+	} else if model.IsMultiBandImage(header.Type) {
+		grouping = &model.MultiBandImageGrouping{}
+	} else if model.IsGeoBounds(header.Type) {
+		grouping = &model.GeoBoundsGrouping{}
+	} else {
+		return nil, errors.Errorf("unrecognized grouping type '%s'", header.Type)
+	}
+
+	err = json.Unmarshal(raw, grouping)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse %s grouping", header.Type)
+	}
+	return grouping, nil
+}
+
+// FilterVariables applies the variable visibility flags and hides deleted
+// variables.
+func FilterVariables(variables []*model.Variable, includeIndex bool, includeMeta bool, includeSystemData bool) []*model.Variable {
+	filtered := []*model.Variable{}
+	for _, variable := range variables {
+		if variable.Deleted {
+			continue
+		}
+		if !includeIndex && len(variable.Role) > 0 && model.IsIndexRole(variable.Role[0]) {
+			continue
+		}
+		if !includeMeta && variable.HasRole(model.VarDistilRoleMetadata) {
+			continue
+		}
+		// systemData and padding is virtually the same thing, the only difference being fitting will require it
+		if !includeSystemData && (variable.HasRole(model.VarDistilRoleSystemData) || variable.HasRole(model.VarDistilRolePadding)) {
+			continue
+		}
+		filtered = append(filtered, variable)
+	}
+	return filtered
+}
+
+// DatasetSearchFields returns the dataset text that is matched by searches.
+func DatasetSearchFields(dataset *api.Dataset) []string {
+	fields := []string{dataset.ID, dataset.Folder, dataset.Name, dataset.Description, dataset.SummaryML}
+	for _, v := range dataset.Variables {
+		if !v.Deleted {
+			fields = append(fields, v.HeaderName)
+		}
+	}
+	return fields
+}
+
+// NewIngestedDataset builds the dataset to store for newly ingested metadata.
+func NewIngestedDataset(datasetSource metadata.DatasetSource, meta *model.Metadata, provenance string) (*api.Dataset, error) {
+	if len(meta.DataResources) > 1 && meta.SchemaSource != model.SchemaSourceOriginal {
+		return nil, errors.New("metadata variables not merged into a single dataset")
+	}
+
+	mainDR := meta.GetMainDataResource()
+
+	// clear refers to
+	for _, v := range mainDR.Variables {
+		v.RefersTo = nil
+	}
+	var origins []*api.JoinSuggestion
+	if meta.DatasetOrigins != nil {
+		origins = make([]*api.JoinSuggestion, len(meta.DatasetOrigins))
+		for i, ds := range meta.DatasetOrigins {
+			origins[i] = &api.JoinSuggestion{
+				DatasetOrigin: &model.DatasetOrigin{
+					SearchResult:  ds.SearchResult,
+					Provenance:    ds.Provenance,
+					SourceDataset: ds.SourceDataset,
+				},
+			}
+		}
+	}
+
+	typ := api.DatasetType(meta.Type)
+	if typ == "" {
+		typ = api.DatasetTypeModelling
+	}
+
+	name := meta.Name
+	if name == "" || name == "NULL" {
+		name = meta.ID
+	}
+	storageName := meta.StorageName
+	if storageName == "" {
+		storageName = model.NormalizeDatasetID(name)
+	}
+
+	return &api.Dataset{
+		ID:              meta.ID,
+		Name:            name,
+		StorageName:     storageName,
+		Folder:          meta.DatasetFolder,
+		Description:     meta.Description,
+		Summary:         meta.Summary,
+		SummaryML:       meta.SummaryMachine,
+		Variables:       mainDR.Variables,
+		NumRows:         meta.NumRows,
+		NumBytes:        meta.NumBytes,
+		Provenance:      provenance,
+		Source:          datasetSource,
+		JoinSuggestions: origins,
+		Type:            typ,
+		LearningDataset: meta.LearningDataset,
+		Clone:           meta.Clone,
+		Immutable:       meta.Immutable,
+		BandExpressions: []*api.BandExpression{},
+	}, nil
+}
+
+// CloneDataset turns the dataset into a clone stored under a new id.
+func CloneDataset(ds *api.Dataset, datasetNew string, storageNameNew string, folderNew string) {
+	// update the id to match the new info
+	ds.ParentDataset = ds.ID
+	ds.ID = datasetNew
+	ds.StorageName = storageNameNew
+	ds.Name = datasetNew
+	ds.Folder = folderNew
+	ds.Source = metadata.Augmented
+	// cloned datasets CAN be altered
+	ds.Immutable = false
+	ds.Clone = true
+}
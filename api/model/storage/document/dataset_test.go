@@ -0,0 +1,78 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package document
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uncharted-distil/distil-compute/model"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+func TestParseDataset(t *testing.T) {
+	dataset := &api.Dataset{
+		ID:   "ts",
+		Name: "ts",
+		Variables: []*model.Variable{
+			{Key: "d3mIndex", HeaderName: "d3mIndex", Role: []string{model.RoleIndex}},
+			{
+				Key:        "series",
+				HeaderName: "series",
+				Type:       model.TimeSeriesType,
+				Grouping: &model.TimeseriesGrouping{
+					Grouping: model.Grouping{Type: model.TimeSeriesType, IDCol: "id"},
+					XCol:     "time",
+					YCol:     "value",
+				},
+			},
+			{Key: "old", HeaderName: "old", Deleted: true},
+		},
+	}
+	raw, err := json.Marshal(dataset)
+	assert.NoError(t, err)
+
+	parsed, err := ParseDataset(raw, "postgres")
+	assert.NoError(t, err)
+	assert.Equal(t, "postgres", parsed.Provenance)
+	assert.Equal(t, api.DatasetTypeModelling, parsed.Type)
+	assert.Len(t, parsed.Variables, 3)
+
+	grouping, ok := parsed.Variables[1].Grouping.(*model.TimeseriesGrouping)
+	assert.True(t, ok)
+	assert.Equal(t, "id", grouping.IDCol)
+	assert.Equal(t, "value", grouping.YCol)
+	assert.Nil(t, parsed.Variables[0].Grouping)
+
+	visible := FilterVariables(parsed.Variables, false, true, true)
+	assert.Len(t, visible, 1)
+	assert.Equal(t, "series", visible[0].Key)
+}
+
+func TestCloneDataset(t *testing.T) {
+	ds := &api.Dataset{ID: "source", Name: "source", StorageName: "source", Immutable: true}
+
+	CloneDataset(ds, "copy", "copy_storage", "copy_folder")
+	assert.Equal(t, "copy", ds.ID)
+	assert.Equal(t, "copy", ds.Name)
+	assert.Equal(t, "copy_storage", ds.StorageName)
+	assert.Equal(t, "copy_folder", ds.Folder)
+	assert.Equal(t, "source", ds.ParentDataset)
+	assert.False(t, ds.Immutable)
+	assert.True(t, ds.Clone)
+}
@@ -0,0 +1,69 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package document
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+// ParseModel parses a stored exported model document.
+func ParseModel(raw []byte) (*api.ExportedModel, error) {
+	model := &api.ExportedModel{}
+	err := json.Unmarshal(raw, model)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse model")
+	}
+	if model.Stage == "" {
+		model.Stage = api.ModelStageStaging
+	}
+	return model, nil
+}
+
+// ModelSearchFields returns the exported model text that is matched by
+// searches.
+func ModelSearchFields(model *api.ExportedModel) []string {
+	fields := []string{model.FittedSolutionID, model.ModelName, model.ModelDescription, model.DatasetID, model.DatasetName}
+	if model.Target != nil {
+		fields = append(fields, model.Target.Key, model.Target.DisplayName)
+	}
+	return append(fields, model.Variables...)
+}
+
+// FirstModel returns the first of the queried models, or nil if there are
+// none.
+func FirstModel(models []*api.ExportedModel, err error) (*api.ExportedModel, error) {
+	if err != nil {
+		return nil, err
+	}
+	if len(models) > 0 {
+		return models[0], nil
+	}
+	return nil, nil
+}
+
+// ParseLineageEdge parses a stored lineage edge document.
+func ParseLineageEdge(raw []byte) (*api.LineageEdge, error) {
+	edge := &api.LineageEdge{}
+	err := json.Unmarshal(raw, edge)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse lineage edge")
+	}
+	return edge, nil
+}
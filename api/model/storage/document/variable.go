@@ -0,0 +1,315 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package document
+
+import (
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+// Store reads and updates stored dataset documents.
+type Store interface {
+	// FetchDatasetDocument returns the stored dataset, including deleted
+	// datasets and all of its variables. It returns nil if the dataset does
+	// not exist.
+	FetchDatasetDocument(dataset string) (*api.Dataset, error)
+	// UpdateDatasetDocument applies an update to the stored dataset. Updates
+	// of the same dataset must be applied one after the other rather than
+	// overwriting each other.
+	UpdateDatasetDocument(dataset string, update func(ds *api.Dataset) error) error
+}
+
+// VariableStorage implements the variable metadata access over the variables
+// held in stored dataset documents.
+type VariableStorage struct {
+	store Store
+}
+
+// NewVariableStorage creates a variable storage that reads and updates the
+// dataset documents of the store.
+func NewVariableStorage(store Store) *VariableStorage {
+	return &VariableStorage{
+		store: store,
+	}
+}
+
+// fetchExistingDataset returns the stored dataset, failing if it does not
+// exist.
+func (s *VariableStorage) fetchExistingDataset(dataset string) (*api.Dataset, error) {
+	ds, err := s.store.FetchDatasetDocument(dataset)
+	if err != nil {
+		return nil, err
+	}
+	if ds == nil {
+		return nil, errors.Errorf("dataset '%s' not found", dataset)
+	}
+	return ds, nil
+}
+
+// FetchVariables returns all the variables for the provided dataset.
+func (s *VariableStorage) FetchVariables(dataset string, includeIndex bool, includeMeta bool, includeSystemData bool) ([]*model.Variable, error) {
+	ds, err := s.fetchExistingDataset(dataset)
+	if err != nil {
+		return nil, err
+	}
+	return FilterVariables(ds.Variables, includeIndex, includeMeta, includeSystemData), nil
+}
+
+// FetchVariablesByName returns all the caller supplied variables.
+func (s *VariableStorage) FetchVariablesByName(dataset string, varKeys []string, includeIndex bool, includeMeta bool, includeSystemData bool) ([]*model.Variable, error) {
+	fetchedVariables, err := s.FetchVariables(dataset, includeIndex, includeMeta, includeSystemData)
+	if err != nil {
+		return nil, err
+	}
+
+	// put the var names into a set for quick lookup
+	varKeySet := map[string]bool{}
+	for _, key := range varKeys {
+		varKeySet[key] = true
+	}
+
+	// filter the returned variables to match our input list
+	filteredVariables := []*model.Variable{}
+	for _, variable := range fetchedVariables {
+		if varKeySet[variable.Key] || (includeIndex && variable.HasRole(model.VarDistilRoleIndex)) {
+			filteredVariables = append(filteredVariables, variable)
+		}
+	}
+	return filteredVariables, nil
+}
+
+// FetchVariablesDisplay returns all the display variables for the provided dataset.
+func (s *VariableStorage) FetchVariablesDisplay(dataset string) ([]*model.Variable, error) {
+	vars, err := s.FetchVariables(dataset, false, true, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to fetch dataset variables")
+	}
+
+	// only include a variable once
+	resultIncludes := make(map[string]bool)
+	result := make([]*model.Variable, 0)
+	for _, v := range vars {
+		if !resultIncludes[v.Key] {
+			result = append(result, v)
+			resultIncludes[v.Key] = true
+		}
+	}
+
+	return result, nil
+}
+
+// DoesVariableExist returns whether or not a variable exists.
+func (s *VariableStorage) DoesVariableExist(dataset string, varName string) (bool, error) {
+	ds, err := s.store.FetchDatasetDocument(dataset)
+	if err != nil {
+		return false, err
+	}
+	if ds == nil {
+		return false, nil
+	}
+
+	for _, v := range ds.Variables {
+		if v.Key == varName {
+			return !v.Deleted, nil
+		}
+	}
+	return false, nil
+}
+
+// FetchVariable returns the variable for the provided dataset and variable.
+func (s *VariableStorage) FetchVariable(dataset string, varName string) (*model.Variable, error) {
+	ds, err := s.fetchExistingDataset(dataset)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range ds.Variables {
+		if v.Key == varName {
+			return v, nil
+		}
+	}
+	return nil, errors.Errorf("unable to find variable `%s`", varName)
+}
+
+// FetchVariableDisplay returns the display variable for the provided dataset and variable.
+func (s *VariableStorage) FetchVariableDisplay(dataset string, varName string) (*model.Variable, error) {
+	variable, err := s.FetchVariable(dataset, varName)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to fetch variable")
+	}
+
+	// DisplayVariable will identify the variable to return.
+	// If not set, no other fetch is needed.
+	if variable.DisplayName != "" && variable.DisplayName != varName {
+		return s.FetchVariable(dataset, variable.DisplayName)
+	}
+
+	return variable, nil
+}
+
+// updateVariables applies an update to the full variable list of a dataset,
+// deleted variables included, and stores the result.
+func (s *VariableStorage) updateVariables(dataset string, update func(variables []*model.Variable) ([]*model.Variable, error)) error {
+	return s.store.UpdateDatasetDocument(dataset, func(ds *api.Dataset) error {
+		variables, err := update(ds.Variables)
+		if err != nil {
+			return err
+		}
+		ds.Variables = variables
+		return nil
+	})
+}
+
+// SetDataType updates the data type of the variable.
+func (s *VariableStorage) SetDataType(dataset string, varName string, varType string) error {
+	return s.updateVariables(dataset, func(variables []*model.Variable) ([]*model.Variable, error) {
+		for _, v := range variables {
+			if v.Key == varName {
+				v.Type = varType
+			}
+		}
+		return variables, nil
+	})
+}
+
+// SetExtrema updates the min & max values of the variable.
+func (s *VariableStorage) SetExtrema(dataset string, varName string, extrema *api.Extrema) error {
+	return s.updateVariables(dataset, func(variables []*model.Variable) ([]*model.Variable, error) {
+		for _, v := range variables {
+			if v.Key == varName {
+				v.Min = extrema.Min
+				v.Max = extrema.Max
+				v.Values = extrema.Values
+			}
+		}
+		return variables, nil
+	})
+}
+
+// AddVariable adds a new variable to the dataset.  If the varDisplayName is left blank it will be set to the key value.
+func (s *VariableStorage) AddVariable(dataset string, varName string, varDisplayName string, varType string, varDistilRole []string) error {
+	variable := newVariable(varName, varDisplayName, varType, varDistilRole)
+	return s.updateVariables(dataset, func(variables []*model.Variable) ([]*model.Variable, error) {
+		return addVariable(variables, variable)
+	})
+}
+
+func newVariable(varName string, varDisplayName string, varType string, varDistilRole []string) *model.Variable {
+	if varDisplayName == "" {
+		varDisplayName = varName
+	}
+
+	return &model.Variable{
+		Key:              varName,
+		HeaderName:       varName,
+		Type:             varType,
+		OriginalType:     varType,
+		OriginalVariable: varName,
+		DisplayName:      varDisplayName,
+		DistilRole:       varDistilRole,
+		Deleted:          false,
+		Immutable:        false,
+		SuggestedTypes:   make([]*model.SuggestedType, 0),
+	}
+}
+
+// addVariable appends the variable, or puts it in place of a deleted variable
+// with the same key.
+func addVariable(variables []*model.Variable, variable *model.Variable) ([]*model.Variable, error) {
+	for index, v := range variables {
+		if v.Key == variable.Key {
+			if !v.Deleted {
+				return nil, errors.Errorf("variable already exists under this key")
+			}
+
+			// deleted, add the new var in its place
+			variable.Index = index
+			variables[index] = variable
+			return variables, nil
+		}
+	}
+
+	variable.Index = len(variables)
+	return append(variables, variable), nil
+}
+
+// UpdateVariable replaces the variable with the supplied values.
+func (s *VariableStorage) UpdateVariable(dataset string, varName string, variableValue *model.Variable) error {
+	return s.updateVariables(dataset, func(variables []*model.Variable) ([]*model.Variable, error) {
+		for i, v := range variables {
+			if v.HeaderName == varName {
+				variables[i] = variableValue
+			}
+		}
+		return variables, nil
+	})
+}
+
+// DeleteVariable flags a variable as deleted.
+func (s *VariableStorage) DeleteVariable(dataset string, varName string) error {
+	return s.updateVariables(dataset, func(variables []*model.Variable) ([]*model.Variable, error) {
+		for _, v := range variables {
+			if v.Key == varName {
+				v.Deleted = true
+			}
+		}
+		return variables, nil
+	})
+}
+
+// AddGroupedVariable adds a grouping to the metadata. The variable and its
+// grouping are stored in a single update.
+func (s *VariableStorage) AddGroupedVariable(dataset string, varName string, varDisplayName string, varType string, varRole []string, grouping model.BaseGrouping) error {
+	variable := newVariable(varName, varDisplayName, varType, varRole)
+	return s.updateVariables(dataset, func(variables []*model.Variable) ([]*model.Variable, error) {
+		variables, err := addVariable(variables, variable)
+		if err != nil {
+			return nil, err
+		}
+
+		found := false
+		for _, v := range variables {
+			if v.HeaderName == varName {
+				v.Grouping = grouping
+				found = true
+			}
+		}
+		if !found {
+			return nil, errors.Errorf("no variable match found for grouping")
+		}
+		return variables, nil
+	})
+}
+
+// RemoveGroupedVariable removes a grouping from the metadata.
+func (s *VariableStorage) RemoveGroupedVariable(datasetName string, grouping model.BaseGrouping) error {
+	return s.updateVariables(datasetName, func(variables []*model.Variable) ([]*model.Variable, error) {
+		found := false
+		for _, v := range variables {
+			if v.HeaderName == grouping.GetIDCol() {
+				v.Grouping = nil
+				v.Type = v.OriginalType
+				found = true
+			}
+		}
+		if !found {
+			return nil, errors.Errorf("no variable match found for grouping")
+		}
+		return variables, nil
+	})
+}
@@ -0,0 +1,115 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package document
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/uncharted-distil/distil-compute/model"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+func TestAddVariable(t *testing.T) {
+	variables := []*model.Variable{
+		{Key: "d3mIndex", Index: 0},
+		{Key: "alpha", Index: 1, Deleted: true},
+	}
+
+	variables, err := addVariable(variables, newVariable("beta", "", model.RealType, nil))
+	assert.NoError(t, err)
+	assert.Len(t, variables, 3)
+	assert.Equal(t, 2, variables[2].Index)
+	assert.Equal(t, "beta", variables[2].DisplayName)
+
+	// deleted variables are replaced in place
+	variables, err = addVariable(variables, newVariable("alpha", "Alpha", model.IntegerType, nil))
+	assert.NoError(t, err)
+	assert.Len(t, variables, 3)
+	assert.Equal(t, 1, variables[1].Index)
+	assert.Equal(t, model.IntegerType, variables[1].Type)
+	assert.False(t, variables[1].Deleted)
+
+	_, err = addVariable(variables, newVariable("beta", "", model.RealType, nil))
+	assert.Error(t, err)
+}
+
+// testStore keeps dataset documents in memory.
+type testStore struct {
+	datasets map[string]*api.Dataset
+}
+
+func (s *testStore) FetchDatasetDocument(dataset string) (*api.Dataset, error) {
+	return s.datasets[dataset], nil
+}
+
+func (s *testStore) UpdateDatasetDocument(dataset string, update func(ds *api.Dataset) error) error {
+	ds := s.datasets[dataset]
+	if ds == nil {
+		return errors.Errorf("dataset '%s' not found", dataset)
+	}
+	return update(ds)
+}
+
+func TestVariableStorage(t *testing.T) {
+	store := &testStore{
+		datasets: map[string]*api.Dataset{
+			"ts": {
+				ID: "ts",
+				Variables: []*model.Variable{
+					{Key: "d3mIndex", HeaderName: "d3mIndex", Role: []string{model.RoleIndex}},
+					{Key: "value", HeaderName: "value", Type: model.RealType, OriginalType: model.RealType},
+				},
+			},
+		},
+	}
+	storage := NewVariableStorage(store)
+
+	grouping := &model.TimeseriesGrouping{
+		Grouping: model.Grouping{Type: model.TimeSeriesType, IDCol: "series"},
+		YCol:     "value",
+	}
+	err := storage.AddGroupedVariable("ts", "series", "Series", model.TimeSeriesType, nil, grouping)
+	assert.NoError(t, err)
+
+	variable, err := storage.FetchVariable("ts", "series")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, variable.Index)
+	assert.Equal(t, grouping, variable.Grouping)
+
+	err = storage.RemoveGroupedVariable("ts", grouping)
+	assert.NoError(t, err)
+	assert.Nil(t, variable.Grouping)
+
+	err = storage.DeleteVariable("ts", "value")
+	assert.NoError(t, err)
+	exists, err := storage.DoesVariableExist("ts", "value")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	variables, err := storage.FetchVariables("ts", false, false, false)
+	assert.NoError(t, err)
+	assert.Len(t, variables, 1)
+	assert.Equal(t, "series", variables[0].Key)
+
+	exists, err = storage.DoesVariableExist("missing", "value")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+	_, err = storage.FetchVariables("missing", true, true, true)
+	assert.Error(t, err)
+}
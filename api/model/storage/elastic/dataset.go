@@ -80,6 +80,8 @@ func (s *Storage) UpdateDataset(dataset *api.Dataset) error {
 		"immutable":       dataset.Immutable,
 		"parentDataset":   dataset.ParentDataset,
 		"deleted":         dataset.Deleted,
+		"sensor":          dataset.Sensor,
+		"bandExpressions": dataset.BandExpressions,
 	}
 
 	bytes, err := json.Marshal(source)
@@ -240,6 +242,19 @@ func (s *Storage) parseDatasets(res *elastic.SearchResult, includeDeleted bool,
 		if !ok {
 			parentDataset = ""
 		}
+		// extract the imaging sensor
+		sensor, ok := json.String(src, "sensor")
+		if !ok {
+			sensor = ""
+		}
+		// extract the user defined band expressions
+		bandExpressions := []*api.BandExpression{}
+		if src["bandExpressions"] != nil {
+			ok = json.Struct(src, &bandExpressions, "bandExpressions")
+			if !ok {
+				return nil, errors.Errorf("unable to parse band expressions")
+			}
+		}
 		// extract the machine learned summary
 		summaryMachine, ok := json.String(src, "summaryMachine")
 		if !ok {
@@ -274,7 +289,7 @@ func (s *Storage) parseDatasets(res *elastic.SearchResult, includeDeleted bool,
 		}
 
 		// extract the variables list
-		variables, err := s.parseVariables(hit, includeIndex, includeMeta, includeSystemData)
+		variables, err := s.parseVariables(hit, includeDeleted, includeIndex, includeMeta, includeSystemData)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to parse dataset")
 		}
@@ -336,6 +351,8 @@ func (s *Storage) parseDatasets(res *elastic.SearchResult, includeDeleted bool,
 			Clone:           clone,
 			ParentDataset:   parentDataset,
 			Deleted:         deleted,
+			Sensor:          sensor,
+			BandExpressions: bandExpressions,
 		})
 	}
 	return datasets, nil
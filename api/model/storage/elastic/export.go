@@ -0,0 +1,134 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package elastic
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	elastic "github.com/olivere/elastic/v7"
+	"github.com/pkg/errors"
+
+	es "github.com/uncharted-distil/distil/api/elastic"
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+const (
+	exportPageSize = 500
+)
+
+// NewExportStorage returns a storage client over the dataset, lineage and
+// model indices for exporting their full contents.
+func NewExportStorage(datasetIndex string, lineageIndex string, modelIndex string, clientCtor es.ClientCtor) (*Storage, error) {
+	esClient, err := clientCtor()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Storage{
+		client:       esClient,
+		datasetIndex: datasetIndex,
+		lineageIndex: lineageIndex,
+		modelIndex:   modelIndex,
+	}, nil
+}
+
+// scrollIndex pages through every document of an index.
+func (s *Storage) scrollIndex(index string, page func(res *elastic.SearchResult) error) error {
+	exists, err := s.client.IndexExists(index).Do(context.Background())
+	if err != nil {
+		return errors.Wrapf(err, "failed to complete check for existence of index %s", index)
+	}
+	if !exists {
+		return nil
+	}
+
+	scroll := s.client.Scroll(index).Size(exportPageSize)
+	defer func() {
+		_ = scroll.Clear(context.Background())
+	}()
+	for {
+		res, err := scroll.Do(context.Background())
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to scroll index %s", index)
+		}
+		err = page(res)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// ExportDatasets returns every dataset in the index, including deleted and
+// inference datasets along with their deleted variables, so that they can be
+// copied to another metadata storage.
+func (s *Storage) ExportDatasets() ([]*api.Dataset, error) {
+	datasets := []*api.Dataset{}
+	err := s.scrollIndex(s.datasetIndex, func(res *elastic.SearchResult) error {
+		parsed, err := s.parseDatasets(res, true, true, true, true)
+		if err != nil {
+			return err
+		}
+		datasets = append(datasets, parsed...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return datasets, nil
+}
+
+// ExportLineageEdges returns every lineage edge in the index.
+func (s *Storage) ExportLineageEdges() ([]*api.LineageEdge, error) {
+	edges := []*api.LineageEdge{}
+	err := s.scrollIndex(s.lineageIndex, func(res *elastic.SearchResult) error {
+		for _, hit := range res.Hits.Hits {
+			edge := &api.LineageEdge{}
+			err := json.Unmarshal(hit.Source, edge)
+			if err != nil {
+				return errors.Wrap(err, "failed to parse lineage edge")
+			}
+			edges = append(edges, edge)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return edges, nil
+}
+
+// ExportModels returns every exported model in the index, including deleted
+// models.
+func (s *Storage) ExportModels() ([]*api.ExportedModel, error) {
+	models := []*api.ExportedModel{}
+	err := s.scrollIndex(s.modelIndex, func(res *elastic.SearchResult) error {
+		parsed, err := s.parseModels(res, true)
+		if err != nil {
+			return err
+		}
+		models = append(models, parsed...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return models, nil
+}
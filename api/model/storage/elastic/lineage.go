@@ -0,0 +1,149 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package elastic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	elastic "github.com/olivere/elastic/v7"
+	"github.com/pkg/errors"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+const (
+	lineageListSize = 1000
+)
+
+// InitializeLineageStorage creates the ElasticSearch index for the lineage
+// edges if it does not already exist.
+func (s *Storage) InitializeLineageStorage() error {
+	exists, err := s.client.IndexExists(s.lineageIndex).Do(context.Background())
+	if err != nil {
+		return errors.Wrapf(err, "failed to complete check for existence of index %s", s.lineageIndex)
+	}
+	if exists {
+		return nil
+	}
+
+	// filters and parameters are stored but not indexed
+	body := `{
+		"mappings": {
+			"properties": {
+				"id": {
+					"type": "keyword"
+				},
+				"operation": {
+					"type": "keyword"
+				},
+				"target": {
+					"type": "keyword"
+				},
+				"sources": {
+					"type": "keyword"
+				},
+				"parameters": {
+					"type": "object",
+					"enabled": false
+				},
+				"filters": {
+					"type": "object",
+					"enabled": false
+				},
+				"created": {
+					"type": "date"
+				}
+			}
+		}
+	}`
+
+	created, err := s.client.
+		CreateIndex(s.lineageIndex).
+		BodyString(body).
+		Do(context.Background())
+	if err != nil {
+		return errors.Wrapf(err, "failed to create index %s", s.lineageIndex)
+	}
+	if !created.Acknowledged {
+		return fmt.Errorf("Failed to create new index %s", s.lineageIndex)
+	}
+	return nil
+}
+
+// PersistLineageEdge stores a lineage edge. Edges are immutable so storing an
+// edge that already exists fails.
+func (s *Storage) PersistLineageEdge(edge *api.LineageEdge) error {
+	err := s.InitializeLineageStorage()
+	if err != nil {
+		return err
+	}
+
+	bytes, err := json.Marshal(edge)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal lineage edge")
+	}
+
+	_, err = s.client.Index().
+		Index(s.lineageIndex).
+		Id(edge.ID).
+		OpType("create").
+		BodyString(string(bytes)).
+		Refresh("true").
+		Do(context.Background())
+	if err != nil {
+		return errors.Wrapf(err, "failed to add lineage edge to index `%s`", s.lineageIndex)
+	}
+
+	return nil
+}
+
+// FetchLineageEdges returns the lineage edges that derived the dataset,
+// ordered from oldest to newest.
+func (s *Storage) FetchLineageEdges(dataset string) ([]*api.LineageEdge, error) {
+	exists, err := s.client.IndexExists(s.lineageIndex).Do(context.Background())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to complete check for existence of index %s", s.lineageIndex)
+	}
+	if !exists {
+		return []*api.LineageEdge{}, nil
+	}
+
+	query := elastic.NewTermQuery("target", dataset)
+	res, err := s.client.Search().
+		Query(query).
+		Index(s.lineageIndex).
+		Sort("created", true).
+		FetchSource(true).
+		Size(lineageListSize).
+		Do(context.Background())
+	if err != nil {
+		return nil, errors.Wrap(err, "elasticsearch lineage fetch query failed")
+	}
+
+	edges := []*api.LineageEdge{}
+	for _, hit := range res.Hits.Hits {
+		edge := &api.LineageEdge{}
+		err = json.Unmarshal(hit.Source, edge)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse lineage edge")
+		}
+		edges = append(edges, edge)
+	}
+
+	return edges, nil
+}
@@ -17,6 +17,8 @@ package elastic
 
 import (
 	"context"
+	"sort"
+	"time"
 
 	elastic "github.com/olivere/elastic/v7"
 	"github.com/pkg/errors"
@@ -145,6 +147,32 @@ func (s *Storage) parseModels(res *elastic.SearchResult, includeDeleted bool) ([
 			return nil, errors.Wrap(err, "failed to parse solution variables")
 		}
 
+		// registry fields are absent on models saved before versioning
+		version := json.IntDefault(src, 0, "version")
+		stage := json.StringDefault(src, api.ModelStageStaging, "stage")
+
+		var lineage *api.LineageGraph
+		if json.Exists(src, "lineage") {
+			lineage = &api.LineageGraph{}
+			if !json.Struct(src, lineage, "lineage") {
+				return nil, errors.New("failed to parse model lineage")
+			}
+		}
+
+		metrics := []*api.SolutionScore{}
+		if json.Exists(src, "metrics") && !json.Struct(src, &metrics, "metrics") {
+			return nil, errors.New("failed to parse model metrics")
+		}
+
+		var created time.Time
+		createdRaw, ok := json.String(src, "created")
+		if ok {
+			created, err = time.Parse(time.RFC3339Nano, createdRaw)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to parse model created time")
+			}
+		}
+
 		// write everything out to result struct
 		models = append(models, &api.ExportedModel{
 			ModelName:        modelName,
@@ -157,6 +185,11 @@ func (s *Storage) parseModels(res *elastic.SearchResult, includeDeleted bool) ([
 			Variables:        variables,
 			VariableDetails:  variableDetails,
 			Deleted:          deleted,
+			Version:          version,
+			Stage:            stage,
+			Lineage:          lineage,
+			Metrics:          metrics,
+			CreatedTime:      created,
 		})
 	}
 	return models, nil
@@ -261,8 +294,49 @@ func (s *Storage) SearchModels(terms string, includeDeleted bool) ([]*api.Export
 	return s.parseModels(res, includeDeleted)
 }
 
-// DeleteModel deletes a model from ES.
+// DeleteModel flags a model as deleted in ES. The document is retained so
+// that its version number is never reassigned.
 func (s *Storage) DeleteModel(fittedSolutionID string) error {
-	_, err := s.client.Delete().Index(s.modelIndex).Id(fittedSolutionID).Refresh("true").Do(context.Background())
-	return err
+	_, err := s.client.Update().
+		Index(s.modelIndex).
+		Id(fittedSolutionID).
+		Doc(map[string]interface{}{"deleted": true}).
+		Refresh("true").
+		Do(context.Background())
+	if err != nil {
+		return errors.Wrapf(err, "failed to flag model `%s` as deleted", fittedSolutionID)
+	}
+	return nil
+}
+
+// FetchModelVersions returns all versions of the named model, including
+// deleted ones, ordered by version.
+func (s *Storage) FetchModelVersions(modelName string) ([]*api.ExportedModel, error) {
+	query := elastic.NewMatchPhraseQuery("modelName", modelName)
+	// execute the ES query
+	res, err := s.client.Search().
+		Query(query).
+		Index(s.modelIndex).
+		FetchSource(true).
+		Size(modelsListSize).
+		Do(context.Background())
+	if err != nil {
+		return nil, errors.Wrap(err, "elasticsearch model version query failed")
+	}
+	models, err := s.parseModels(res, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// the model name field is analyzed so only keep exact matches
+	versions := []*api.ExportedModel{}
+	for _, m := range models {
+		if m.ModelName == modelName {
+			versions = append(versions, m)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version < versions[j].Version
+	})
+	return versions, nil
 }
@@ -29,11 +29,12 @@ import (
 type Storage struct {
 	client       *elastic.Client
 	datasetIndex string
+	lineageIndex string
 	modelIndex   string
 }
 
 // NewMetadataStorage returns a constructor for a metadata storage.
-func NewMetadataStorage(datasetIndex string, initialize bool, clientCtor es.ClientCtor) model.MetadataStorageCtor {
+func NewMetadataStorage(datasetIndex string, lineageIndex string, initialize bool, clientCtor es.ClientCtor) model.MetadataStorageCtor {
 	return func() (model.MetadataStorage, error) {
 		esClient, err := clientCtor()
 		if err != nil {
@@ -43,6 +44,7 @@ func NewMetadataStorage(datasetIndex string, initialize bool, clientCtor es.Clie
 		storage := &Storage{
 			client:       esClient,
 			datasetIndex: datasetIndex,
+			lineageIndex: lineageIndex,
 		}
 
 		if initialize {
@@ -293,7 +295,7 @@ func (s *Storage) InitializeModelStorage(overwrite bool) error {
 				return fmt.Errorf("failed to create index `%s`, index could not be deleted", s.modelIndex)
 			}
 		} else {
-			return nil
+			return s.ensureModelRegistryMapping()
 		}
 	}
 
@@ -426,6 +428,23 @@ func (s *Storage) InitializeModelStorage(overwrite bool) error {
 							"type": "text"
 						}
 					}
+				},
+				"version": {
+					"type": "integer"
+				},
+				"stage": {
+					"type": "keyword"
+				},
+				"lineage": {
+					"type": "object",
+					"enabled": false
+				},
+				"metrics": {
+					"type": "object",
+					"enabled": false
+				},
+				"created": {
+					"type": "date"
 				}
 			}
 		}
@@ -444,3 +463,38 @@ func (s *Storage) InitializeModelStorage(overwrite bool) error {
 	}
 	return nil
 }
+
+// ensureModelRegistryMapping adds the model registry fields to a model index
+// created before models were versioned.
+func (s *Storage) ensureModelRegistryMapping() error {
+	body := `{
+		"properties": {
+			"version": {
+				"type": "integer"
+			},
+			"stage": {
+				"type": "keyword"
+			},
+			"lineage": {
+				"type": "object",
+				"enabled": false
+			},
+			"metrics": {
+				"type": "object",
+				"enabled": false
+			},
+			"created": {
+				"type": "date"
+			}
+		}
+	}`
+
+	_, err := s.client.PutMapping().
+		Index(s.modelIndex).
+		BodyString(body).
+		Do(context.Background())
+	if err != nil {
+		return errors.Wrapf(err, "failed to update mapping of index %s", s.modelIndex)
+	}
+	return nil
+}
@@ -190,7 +190,10 @@ func (s *Storage) parseVariable(searchHit *elastic.SearchHit, key string) (*mode
 	return nil, errors.Errorf("unable to find variable `%s`", key)
 }
 
-func (s *Storage) parseVariables(searchHit *elastic.SearchHit, includeIndex bool, includeMeta bool, includeSystemData bool) ([]*model.Variable, error) {
+// parseVariables extracts the variables of a dataset search hit. Deleted variables
+// are only kept when includeDeleted is set, which is limited to the migration
+// export and DatasetExists through parseDatasets.
+func (s *Storage) parseVariables(searchHit *elastic.SearchHit, includeDeleted bool, includeIndex bool, includeMeta bool, includeSystemData bool) ([]*model.Variable, error) {
 	// unmarshal the hit source
 	src, err := json.Unmarshal(searchHit.Source)
 	if err != nil {
@@ -230,7 +233,7 @@ func (s *Storage) parseVariables(searchHit *elastic.SearchHit, includeIndex bool
 	// hide hidden variables
 	var filtered []*model.Variable
 	for _, v := range variables {
-		if !v.Deleted {
+		if includeDeleted || !v.Deleted {
 			filtered = append(filtered, v)
 		}
 	}
@@ -425,7 +428,7 @@ func (s *Storage) FetchVariables(dataset string, includeIndex bool, includeMeta
 		return nil, errors.Errorf("elasticSearch variable fetch query len(hits) != 1 (len == %d) for dataset '%s'", len(res.Hits.Hits), datasetID)
 	}
 	// extract output into JSON ready structs
-	return s.parseVariables(res.Hits.Hits[0], includeIndex, includeMeta, includeSystemData)
+	return s.parseVariables(res.Hits.Hits[0], false, includeIndex, includeMeta, includeSystemData)
 }
 
 // FetchVariablesDisplay returns all the display variables for the provided index and dataset.
@@ -205,3 +205,13 @@ func matches(text string, terms []string) bool {
 	// if no terms provided, assume match
 	return len(terms) == 0
 }
+
+// PersistLineageEdge stores a lineage edge in the file system.
+func (s *Storage) PersistLineageEdge(edge *api.LineageEdge) error {
+	return errors.Errorf("Not implemented")
+}
+
+// FetchLineageEdges returns the lineage edges of a dataset from the file system.
+func (s *Storage) FetchLineageEdges(dataset string) ([]*api.LineageEdge, error) {
+	return nil, errors.Errorf("Not implemented")
+}
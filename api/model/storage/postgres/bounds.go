@@ -24,7 +24,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/uncharted-distil/distil-compute/model"
 	api "github.com/uncharted-distil/distil/api/model"
-	"github.com/uncharted-distil/distil/api/postgres"
+	"github.com/uncharted-distil/distil/api/model/storage/sqlbuilder"
 )
 
 // BoundsField defines behaviour for the remote sensing field type.
@@ -41,13 +41,13 @@ type geometryBucket struct {
 }
 
 func (g *geometryBucket) toGeometryString() string {
-	return buildBoundsGeometryString(g.bounds)
+	return sqlbuilder.BoundsGeometry(g.bounds)
 }
 
 // NewBoundsField creates a new field for remote sensing types.
 func NewBoundsField(storage *Storage, datasetName string, datasetStorageName string,
 	coordinatesCol string, polygonCol string, key string, label string, typ string, count string) *BoundsField {
-	count = getCountSQL(count)
+	count = sqlbuilder.CountSQL(count)
 
 	field := &BoundsField{
 		BasicField: BasicField{
@@ -111,7 +111,10 @@ func (f *BoundsField) fetchHistogram(filterParams *api.FilterParams, numBuckets
 	// create the filter for the query.
 	wheres := make([]string, 0)
 	params := make([]interface{}, 0)
-	wheres, params = f.Storage.buildFilteredQueryWhere(f.GetDatasetName(), wheres, params, "", filterParams)
+	wheres, params, err := f.Storage.builder.FilteredQueryWhere(f.GetDatasetName(), wheres, params, "", filterParams)
+	if err != nil {
+		return nil, err
+	}
 
 	where := ""
 	if len(wheres) > 0 {
@@ -411,21 +414,6 @@ func (f *BoundsField) FetchPredictedSummaryData(resultURI string, datasetResult
 	return nil, fmt.Errorf("not implemented")
 }
 
-func buildBoundsGeometryString(bounds *model.Bounds) string {
-	coords := []string{
-		pointToString(bounds.MinX, bounds.MinY, " "),
-		pointToString(bounds.MinX, bounds.MaxY, " "),
-		pointToString(bounds.MaxX, bounds.MaxY, " "),
-		pointToString(bounds.MaxX, bounds.MinY, " "),
-		pointToString(bounds.MinX, bounds.MinY, " "),
-	}
-	return fmt.Sprintf("POLYGON((%s))", strings.Join(coords, ","))
-}
-
-func pointToString(x float64, y float64, separator string) string {
-	return fmt.Sprintf("%f%s%f", x, separator, y)
-}
-
 func getGeoBoundsBuckets(xExtrema *api.Extrema, yExtrema *api.Extrema,
 	xNumBuckets int, yNumBuckets int) []*geometryBucket {
 	// build a list of bounds representing the buckets
@@ -460,17 +448,5 @@ func getGeoBoundsBuckets(xExtrema *api.Extrema, yExtrema *api.Extrema,
 }
 
 func (f *BoundsField) getDefaultFilter(inverse bool) string {
-	defaultValue := fmt.Sprintf("%v", postgres.DefaultPostgresValueFromD3MType(f.GetType()))
-
-	// null comparison is done with "is" rather than "="
-	op := "="
-	if defaultValue == "NULL" {
-		op = "is"
-	}
-	negate := ""
-	if inverse {
-		negate = "NOT"
-	}
-
-	return fmt.Sprintf("%s(\"%s\" %s %s)", negate, f.PolygonCol, op, defaultValue)
+	return f.Storage.builder.DefaultFilter(f.GetType(), f.PolygonCol, inverse)
 }
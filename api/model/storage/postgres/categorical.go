@@ -18,12 +18,12 @@ package postgres
 import (
 	"fmt"
 	"math"
-	"strings"
 
 	"github.com/jackc/pgx/v4"
 	"github.com/pkg/errors"
 	"github.com/uncharted-distil/distil-compute/model"
 	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/model/storage/sqlbuilder"
 )
 
 // CategoricalField defines behaviour for the categorical field type.
@@ -34,7 +34,7 @@ type CategoricalField struct {
 
 // NewCategoricalField creates a new field for categorical types.
 func NewCategoricalField(storage *Storage, datasetName string, datasetStorageName string, key string, label string, typ string, count string) *CategoricalField {
-	count = getCountSQL(count)
+	count = sqlbuilder.CountSQL(count)
 
 	field := &CategoricalField{
 		BasicField: BasicField{
@@ -55,7 +55,7 @@ func NewCategoricalField(storage *Storage, datasetName string, datasetStorageNam
 // and specifies a sub select query to pull the raw data.
 func NewCategoricalFieldSubSelect(storage *Storage, datasetName string,
 	datasetStorageName string, key string, label string, typ string, count string, fieldSubSelect func() string) *CategoricalField {
-	count = getCountSQL(count)
+	count = sqlbuilder.CountSQL(count)
 
 	field := &CategoricalField{
 		BasicField: BasicField{
@@ -115,7 +115,7 @@ func (f *CategoricalField) FetchSummaryData(resultURI string, filterParams *api.
 
 func (f *CategoricalField) fetchExtremaStorage() (*api.Extrema, error) {
 	// pull all unique values from the database
-	sql := fmt.Sprintf("SELECT DISTINCT \"%s\" FROM %s;", f.Key, f.GetDatasetStorageName())
+	sql := f.Storage.builder.Distinct(f.Key, f.GetDatasetStorageName())
 	res, err := f.Storage.client.Query(sql)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to fetch extrema for variable summaries from postgres")
@@ -158,16 +158,13 @@ func (f *CategoricalField) fetchHistogram(filterParams *api.FilterParams) (*api.
 	// create the filter for the query
 	wheres := make([]string, 0)
 	params := make([]interface{}, 0)
-	wheres, params = f.Storage.buildFilteredQueryWhere(f.GetDatasetName(), wheres, params, "", filterParams)
-
-	where := ""
-	if len(wheres) > 0 {
-		where = fmt.Sprintf("WHERE %s", strings.Join(wheres, " AND "))
+	wheres, params, err := f.Storage.builder.FilteredQueryWhere(f.GetDatasetName(), wheres, params, "", filterParams)
+	if err != nil {
+		return nil, err
 	}
 
 	// Get count by category.
-	query := fmt.Sprintf("SELECT \"%s\", COUNT(%s) AS count FROM %s %s GROUP BY \"%s\" ORDER BY count desc, \"%s\" LIMIT %d;",
-		f.Key, f.Count, fromClause, where, f.Key, f.Key, catResultLimit)
+	query := f.Storage.builder.CategoricalHistogram(fmt.Sprintf("\"%s\"", f.Key), f.Count, fromClause, wheres, catResultLimit)
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(query, params...)
@@ -191,22 +188,11 @@ func (f *CategoricalField) fetchHistogramByResult(resultURI string, filterParams
 	}
 
 	params = append(params, resultURI)
-
-	where := ""
-	if len(wheres) > 0 {
-		where = fmt.Sprintf("AND %s", strings.Join(wheres, " AND "))
-	}
+	wheres = append([]string{f.Storage.builder.ResultWhere(len(params)), "result.value != ''"}, wheres...)
 
 	// Get count by category.
-	query := fmt.Sprintf(
-		`SELECT data."%s", COUNT(%s) AS count
-		 FROM %s data INNER JOIN %s result ON data."%s" = result.index
-		 WHERE result.result_id = $%d AND result.value != '' %s
-		 GROUP BY data."%s"
-		 ORDER BY count desc, "%s" LIMIT %d;`,
-		f.Key, f.Count, fromClause, f.Storage.getResultTable(f.DatasetStorageName),
-		model.D3MIndexFieldName, len(params), where, f.Key,
-		f.Key, catResultLimit)
+	fromResult := sqlbuilder.DataResultJoin(fmt.Sprintf("%s data", fromClause), f.Storage.getResultTable(f.DatasetStorageName), "data")
+	query := f.Storage.builder.CategoricalHistogram(fmt.Sprintf("data.\"%s\"", f.Key), f.Count, fromResult, wheres, catResultLimit)
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(query, params...)
@@ -303,16 +289,10 @@ func (f *CategoricalField) fetchPredictedSummaryData(resultURI string, datasetRe
 		return nil, err
 	}
 
-	wheres = append(wheres, fmt.Sprintf("result.result_id = $%d AND result.target = $%d ", len(params)+1, len(params)+2))
+	wheres = append(wheres, f.Storage.builder.ResultTargetWhere(len(params)+1))
 	params = append(params, resultURI, targetName)
 
-	query := fmt.Sprintf(
-		`SELECT result.value, COUNT(%s) AS count
-		 FROM %s AS result INNER JOIN %s AS data ON result.index = data."%s"
-		 WHERE %s
-		 GROUP BY result.value
-		 ORDER BY count desc;`,
-		f.Count, datasetResult, f.DatasetStorageName, model.D3MIndexFieldName, strings.Join(wheres, " AND "))
+	query := f.Storage.builder.PredictedHistogram(f.Count, datasetResult, f.DatasetStorageName, wheres)
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(query, params...)
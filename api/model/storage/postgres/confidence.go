@@ -116,10 +116,7 @@ func (s *Storage) listExplainFields() []string {
 }
 
 func (s *Storage) fetchExplainExtrema(storageName string, explainFieldName string, resultURI string) (*api.Extrema, error) {
-	selectSQL := fmt.Sprintf(
-		"MIN((explain_values ->> '%s')::double precision) as min_val, MAX((explain_values ->> '%s')::double precision) as max_val",
-		explainFieldName, explainFieldName)
-	sql := fmt.Sprintf("SELECT %s FROM %s WHERE result_id = $1", selectSQL, s.getResultTable(storageName))
+	sql := s.builder.ExplainExtrema(explainFieldName, s.getResultTable(storageName))
 
 	rows, err := s.client.Query(sql, resultURI)
 	if err != nil {
@@ -154,10 +151,6 @@ func (s *Storage) fetchExplainExtrema(storageName string, explainFieldName strin
 
 func (s *Storage) explainSubSelect(storageName string, fieldName string, aliasName string) func() string {
 	return func() string {
-		return fmt.Sprintf(`(
-			SELECT (explain_values ->> '%s')::double precision as "%s", result_id as result_key, b.* from %s as d
-			inner join %s as b on b."d3mIndex" = d.index
-			) as data`,
-			fieldName, aliasName, s.getResultTable(storageName), storageName)
+		return s.builder.ExplainSubSelect(s.getResultTable(storageName), storageName, fieldName, aliasName)
 	}
 }
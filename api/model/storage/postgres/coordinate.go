@@ -23,6 +23,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/uncharted-distil/distil-compute/model"
 	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/model/storage/sqlbuilder"
 	"github.com/uncharted-distil/distil/api/postgres"
 )
 
@@ -37,7 +38,7 @@ type CoordinateField struct {
 
 // NewCoordinateField creates a new field for coordinate types.
 func NewCoordinateField(key string, storage *Storage, datasetName string, datasetStorageName string, xCol string, yCol string, label string, typ string, count string) *CoordinateField {
-	count = getCountSQL(count)
+	count = sqlbuilder.CountSQL(count)
 
 	field := &CoordinateField{
 		BasicField: BasicField{
@@ -101,7 +102,10 @@ func (f *CoordinateField) fetchHistogram(filterParams *api.FilterParams, numBuck
 	// create the filter for the query.
 	wheres := make([]string, 0)
 	params := make([]interface{}, 0)
-	wheres, params = f.Storage.buildFilteredQueryWhere(f.GetDatasetName(), wheres, params, "", filterParams)
+	wheres, params, err := f.Storage.builder.FilteredQueryWhere(f.GetDatasetName(), wheres, params, "", filterParams)
+	if err != nil {
+		return nil, err
+	}
 
 	where := ""
 	if len(wheres) > 0 {
@@ -125,8 +129,8 @@ func (f *CoordinateField) fetchHistogram(filterParams *api.FilterParams, numBuck
 	xNumBuckets, yNumBuckets := getEqualBivariateBuckets(numBuckets, xExtrema, yExtrema)
 
 	// generate a histogram query for each
-	xHistogramName, xBucketQuery, xHistogramQuery := xField.getHistogramAggQuery(xExtrema, xNumBuckets, "")
-	yHistogramName, yBucketQuery, yHistogramQuery := yField.getHistogramAggQuery(yExtrema, yNumBuckets, "")
+	xAgg := xField.getHistogramAgg(xExtrema, xNumBuckets, "")
+	yAgg := yField.getHistogramAgg(yExtrema, yNumBuckets, "")
 
 	// Get count by x & y
 	query := fmt.Sprintf(`SELECT %s as bucket, CAST(%s as double precision) AS %s, %s as bucket, CAST(%s as double precision) AS %s, COUNT(%s) AS count
@@ -134,8 +138,8 @@ func (f *CoordinateField) fetchHistogram(filterParams *api.FilterParams, numBuck
         WHERE "%s" != 'NaN' AND "%s" != 'NaN' %s
         GROUP BY %s, %s
         ORDER BY %s, %s;`,
-		xBucketQuery, xHistogramQuery, xHistogramName, yBucketQuery, yHistogramQuery, yHistogramName, f.Count,
-		f.DatasetStorageName, f.XCol, f.YCol, where, xBucketQuery, yBucketQuery, xHistogramName, yHistogramName)
+		xAgg.Bucket, xAgg.Value, xAgg.Name, yAgg.Bucket, yAgg.Value, yAgg.Name, f.Count,
+		f.DatasetStorageName, f.XCol, f.YCol, where, xAgg.Bucket, yAgg.Bucket, xAgg.Name, yAgg.Name)
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(query, params...)
@@ -186,8 +190,8 @@ func (f *CoordinateField) fetchHistogramByResult(resultURI string, filterParams
 	xNumBuckets, yNumBuckets := getEqualBivariateBuckets(numBuckets, xExtrema, yExtrema)
 
 	// create histograms given the the extrema
-	xHistogramName, xBucketQuery, xHistogramQuery := xField.getHistogramAggQuery(xExtrema, xNumBuckets, baseTableAlias)
-	yHistogramName, yBucketQuery, yHistogramQuery := yField.getHistogramAggQuery(yExtrema, yNumBuckets, baseTableAlias)
+	xAgg := xField.getHistogramAgg(xExtrema, xNumBuckets, baseTableAlias)
+	yAgg := yField.getHistogramAgg(yExtrema, yNumBuckets, baseTableAlias)
 
 	// Get count by x & y
 	query := fmt.Sprintf(`
@@ -196,9 +200,9 @@ func (f *CoordinateField) fetchHistogramByResult(resultURI string, filterParams
 		WHERE result.result_id = $%d AND "%s" != 'NaN' AND "%s" != 'NaN' %s
 		GROUP BY %s, %s
 		ORDER BY %s, %s;`,
-		xBucketQuery, xHistogramQuery, xHistogramName, yBucketQuery, yHistogramQuery, yHistogramName, f.Count,
+		xAgg.Bucket, xAgg.Value, xAgg.Name, yAgg.Bucket, yAgg.Value, yAgg.Name, f.Count,
 		f.DatasetStorageName, f.Storage.getResultTable(f.DatasetStorageName), model.D3MIndexFieldName,
-		len(params), f.XCol, f.YCol, where, xBucketQuery, yBucketQuery, xHistogramName, yHistogramName)
+		len(params), f.XCol, f.YCol, where, xAgg.Bucket, yAgg.Bucket, xAgg.Name, yAgg.Name)
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(query, params...)
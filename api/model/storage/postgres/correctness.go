@@ -17,12 +17,12 @@ package postgres
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/jackc/pgx/v4"
 	"github.com/pkg/errors"
 	"github.com/uncharted-distil/distil-compute/model"
 	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/model/storage/sqlbuilder"
 )
 
 // FetchCorrectnessSummary fetches a histogram of the residuals associated with a set of numerical predictions.
@@ -78,22 +78,12 @@ func (s *Storage) fetchHistogram(dataset string, storageName string, variable *m
 	if err != nil {
 		return nil, err
 	}
-	if countCol == "" {
-		countCol = "*"
-	} else {
-		countCol = fmt.Sprintf("DISTINCT \"%s\"", countCol)
-	}
+	countCol = sqlbuilder.CountSQL(countCol)
 
-	wheres = append(wheres, fmt.Sprintf("result.result_id = $%d AND result.target = $%d ", len(params)+1, len(params)+2))
+	wheres = append(wheres, s.builder.ResultTargetWhere(len(params)+1))
 	params = append(params, resultURI, targetName)
 
-	query := fmt.Sprintf(
-		`SELECT data."%s", result.value, COUNT(%s) AS count
-		 FROM %s AS result INNER JOIN %s AS data ON result.index = data."%s"
-		 WHERE %s
-		 GROUP BY result.value, data."%s"
-		 ORDER BY count desc;`,
-		targetName, countCol, storageNameResult, storageName, model.D3MIndexFieldName, strings.Join(wheres, " AND "), targetName)
+	query := s.builder.CorrectnessHistogram(targetName, countCol, storageNameResult, storageName, wheres)
 
 	// execute the postgres query
 	res, err := s.client.Query(query, params...)
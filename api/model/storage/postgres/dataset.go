@@ -30,6 +30,9 @@ import (
 
 const (
 	maxBatchSize = 250
+
+	datasetStreamCursorName = "dataset_stream"
+	datasetStreamChunkSize  = 10000
 )
 
 type joinDefinition struct {
@@ -75,13 +78,16 @@ func (s *Storage) SaveDataset(dataset string, storageName string, filterParams *
 func (s *Storage) deleteRows(dataset string, storageName string, filterParams *api.FilterParams) error {
 	wheres := []string{}
 	paramsFilter := make([]interface{}, 0)
-	wheres, paramsFilter = s.buildFilteredQueryWhere(dataset, wheres, paramsFilter, "", filterParams)
+	wheres, paramsFilter, err := s.builder.FilteredQueryWhere(dataset, wheres, paramsFilter, "", filterParams)
+	if err != nil {
+		return err
+	}
 	where := ""
 	if len(wheres) > 0 {
 		where = "WHERE " + strings.Join(wheres, " AND ")
 	}
 	sql := fmt.Sprintf("DELETE FROM %s %s;", storageName, where)
-	_, err := s.client.Exec(sql, paramsFilter...)
+	_, err = s.client.Exec(sql, paramsFilter...)
 	if err != nil {
 		return errors.Wrapf(err, "unable execute query to delete rows")
 	}
@@ -346,10 +352,112 @@ func unnestStringJSON(raw interface{}) map[string]string {
 // FetchDataset extracts the complete raw data from the database.
 func (s *Storage) FetchDataset(dataset string, storageName string,
 	includeMetadata bool, limitSelectedFields bool, filterParams *api.FilterParams) ([][]string, error) {
+	sql, paramsFilter, _, err := s.buildDatasetQuery(dataset, storageName, includeMetadata, limitSelectedFields, filterParams)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.Query(sql, paramsFilter...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable execute query to extract dataset")
+	}
+
+	return s.parseData(res)
+}
+
+// StreamDataset extracts the dataset through a server side cursor, passing
+// the rows to the writer one chunk at a time.
+func (s *Storage) StreamDataset(dataset string, storageName string, includeMetadata bool,
+	limitSelectedFields bool, filterParams *api.FilterParams, writer api.DatasetStreamWriter) error {
+	sql, paramsFilter, header, err := s.buildDatasetQuery(dataset, storageName, includeMetadata, limitSelectedFields, filterParams)
+	if err != nil {
+		return err
+	}
+	err = writer.WriteHeader(header)
+	if err != nil {
+		return err
+	}
+
+	// cursors only live for the duration of a transaction
+	tx, err := s.client.Begin()
+	if err != nil {
+		return errors.Wrapf(err, "unable to begin transaction")
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	cursorSQL := fmt.Sprintf("DECLARE %s NO SCROLL CURSOR FOR %s", datasetStreamCursorName, strings.TrimSuffix(sql, ";"))
+	_, err = tx.Exec(context.Background(), cursorSQL, paramsFilter...)
+	if err != nil {
+		return errors.Wrapf(err, "unable to declare cursor to extract dataset")
+	}
+
+	fetchSQL := fmt.Sprintf("FETCH FORWARD %d FROM %s;", datasetStreamChunkSize, datasetStreamCursorName)
+	for {
+		res, err := tx.Query(context.Background(), fetchSQL)
+		if err != nil {
+			return errors.Wrapf(err, "unable to fetch from dataset cursor")
+		}
+		rows, err := parseStreamedRows(res, len(header))
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			break
+		}
+
+		err = writer.WriteRows(rows)
+		if err != nil {
+			return err
+		}
+		if len(rows) < datasetStreamChunkSize {
+			break
+		}
+	}
+
+	_, err = tx.Exec(context.Background(), fmt.Sprintf("CLOSE %s;", datasetStreamCursorName))
+	if err != nil {
+		return errors.Wrapf(err, "unable to close dataset cursor")
+	}
+	err = tx.Commit(context.Background())
+	if err != nil {
+		return errors.Wrapf(err, "unable to commit transaction")
+	}
+
+	return nil
+}
+
+func parseStreamedRows(res pgx.Rows, columnCount int) ([][]string, error) {
+	defer res.Close()
+
+	rows := [][]string{}
+	for res.Next() {
+		columnValues, err := res.Values()
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read dataset row")
+		}
+		row := make([]string, columnCount)
+		for i, cv := range columnValues {
+			// all fields are selected as text
+			if cv != nil {
+				row[i] = cv.(string)
+			}
+		}
+		rows = append(rows, row)
+	}
+	if res.Err() != nil {
+		return nil, errors.Wrapf(res.Err(), "unable to read dataset rows")
+	}
+
+	return rows, nil
+}
+
+// buildDatasetQuery builds the query extracting the dataset, returning the
+// query, its parameters and the selected field names.
+func (s *Storage) buildDatasetQuery(dataset string, storageName string, includeMetadata bool,
+	limitSelectedFields bool, filterParams *api.FilterParams) (string, []interface{}, []string, error) {
 	// get data variables (to exclude metadata variables)
 	vars, err := s.metadata.FetchVariables(dataset, true, includeMetadata, false)
 	if err != nil {
-		return nil, err
+		return "", nil, nil, err
 	}
 	filteredVars := []*model.Variable{}
 
@@ -370,28 +478,30 @@ func (s *Storage) FetchDataset(dataset string, storageName string,
 		}
 	}
 	varNames := []string{}
+	header := []string{}
 	for _, v := range filteredVars {
 		fieldSelect := "COALESCE(CAST(\"%s\" as text), '') AS \"%s\""
 		if model.IsVector(v.Type) {
 			fieldSelect = "COALESCE(TRANSLATE(CAST(\"%s\" as text), '{}', ''), '') AS \"%s\""
 		}
 		varNames = append(varNames, fmt.Sprintf(fieldSelect, v.Key, v.Key))
+		header = append(header, v.Key)
 	}
 	wheres := []string{}
 	paramsFilter := make([]interface{}, 0)
-	wheres, paramsFilter = s.buildFilteredQueryWhere(dataset, wheres, paramsFilter, "", filterParams)
+	wheres, paramsFilter, err = s.builder.FilteredQueryWhere(dataset, wheres, paramsFilter, "", filterParams)
+	if err != nil {
+		return "", nil, nil, err
+	}
 	where := ""
 	if len(wheres) > 0 {
 		where = "WHERE " + strings.Join(wheres, " AND ")
 	}
 	sql := fmt.Sprintf("SELECT %s FROM %s %s;", strings.Join(varNames, ", "), getBaseTableName(storageName), where)
-	res, err := s.client.Query(sql, paramsFilter...)
-	if err != nil {
-		return nil, errors.Wrapf(err, "unable execute query to extract dataset")
-	}
 
-	return s.parseData(res)
+	return sql, paramsFilter, header, nil
 }
+
 func (s *Storage) createIndex(storageName string, colName string, colType string) error {
 	sql := postgres.GetIndexStatement(storageName, colName, colType)
 
@@ -754,13 +864,16 @@ func (s *Storage) insertBatchData(storageName string, varNames []string, inserts
 func (s *Storage) SetVariableValue(dataset string, storageName string, varName string, value string, filterParams *api.FilterParams) error {
 	wheres := []string{}
 	params := []interface{}{value}
-	wheres, params = s.buildFilteredQueryWhere(dataset, wheres, params, "", filterParams)
+	wheres, params, err := s.builder.FilteredQueryWhere(dataset, wheres, params, "", filterParams)
+	if err != nil {
+		return err
+	}
 	whereClause := ""
 	if len(wheres) > 0 {
 		whereClause = fmt.Sprintf("WHERE %s", strings.Join(wheres, " AND "))
 	}
 	sql := fmt.Sprintf("UPDATE %s_base SET \"%s\" = $1 %s;", storageName, varName, whereClause)
-	_, err := s.client.Exec(sql, params...)
+	_, err = s.client.Exec(sql, params...)
 	if err != nil {
 		return errors.Wrap(err, "Unable to update value stored in the database")
 	}
@@ -855,7 +968,10 @@ func (s *Storage) UpdateData(dataset string, storageName string, varName string,
 	// build the filter structure
 	wheres := []string{fmt.Sprintf("t.\"%s\" = b.\"%s\"::text", model.D3MIndexFieldName, model.D3MIndexFieldName)}
 	paramsFilter := make([]interface{}, 0)
-	wheres, paramsFilter = s.buildFilteredQueryWhere(dataset, wheres, paramsFilter, "b", filterParams)
+	wheres, paramsFilter, err = s.builder.FilteredQueryWhere(dataset, wheres, paramsFilter, "b", filterParams)
+	if err != nil {
+		return err
+	}
 
 	// geometries should be updated slightly differently
 	// they should be reduced to their centroid
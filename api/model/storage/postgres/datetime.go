@@ -18,13 +18,12 @@ package postgres
 import (
 	"fmt"
 	"strconv"
-	"strings"
-	"time"
 
 	"github.com/jackc/pgx/v4"
 	"github.com/pkg/errors"
 	"github.com/uncharted-distil/distil-compute/model"
 	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/model/storage/sqlbuilder"
 )
 
 // DateTimeField defines behaviour for the numerical field type.
@@ -35,7 +34,7 @@ type DateTimeField struct {
 
 // NewDateTimeField creates a new field for numerical types.
 func NewDateTimeField(storage *Storage, datasetName string, datasetStorageName string, key string, label string, typ string, count string) *DateTimeField {
-	count = getCountSQL(count)
+	count = sqlbuilder.CountSQL(count)
 
 	field := &DateTimeField{
 		BasicField: BasicField{
@@ -55,7 +54,7 @@ func NewDateTimeField(storage *Storage, datasetName string, datasetStorageName s
 // NewDateTimeFieldSubSelect creates a new field for numerical types
 // and specifies a sub select query to pull the raw data.
 func NewDateTimeFieldSubSelect(storage *Storage, datasetName string, datasetStorageName string, key string, label string, typ string, count string, fieldSubSelect func() string) *DateTimeField {
-	count = getCountSQL(count)
+	count = sqlbuilder.CountSQL(count)
 
 	field := &DateTimeField{
 		BasicField: BasicField{
@@ -122,7 +121,11 @@ func (f *DateTimeField) fetchHistogramWithJoins(filterParams *api.FilterParams,
 	fromClause := f.getFromClause(true)
 
 	// create the filter for the query.
-	wheres, params = f.Storage.buildFilteredQueryWhere(f.GetDatasetName(), wheres, params, "", filterParams)
+	wheres, params, err := f.Storage.builder.FilteredQueryWhere(f.GetDatasetName(), wheres, params, "", filterParams)
+	if err != nil {
+		return nil, err
+	}
+	filterWheres := wheres
 	wheres = append(wheres, f.getDefaultFilter(true))
 
 	// need the extrema to calculate the histogram interval
@@ -133,18 +136,12 @@ func (f *DateTimeField) fetchHistogramWithJoins(filterParams *api.FilterParams,
 
 	// for each returned aggregation, create a histogram aggregation. Bucket
 	// size is derived from the min/max and desired bucket count.
-	histogramName, bucketQuery, histogramQuery := f.getHistogramAggQuery(extrema, numBuckets, "")
-
-	where := ""
-	if len(wheres) > 0 {
-		where = fmt.Sprintf("WHERE %s", strings.Join(wheres, " AND "))
-	}
+	agg := f.getHistogramAgg(extrema, numBuckets, "")
 
 	joinSQL := createJoinStatements(joins)
 
 	// Create the complete query string.
-	query := fmt.Sprintf("SELECT %s as bucket, CAST(%s as double precision) AS %s, COUNT(%s) AS count FROM %s %s %s GROUP BY %s ORDER BY %s;",
-		bucketQuery, histogramQuery, histogramName, f.Count, fromClause, joinSQL, where, bucketQuery, histogramName)
+	query := f.Storage.builder.Histogram(agg, f.Count, fmt.Sprintf("%s %s", fromClause, joinSQL), wheres)
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(query, params...)
@@ -155,25 +152,32 @@ func (f *DateTimeField) fetchHistogramWithJoins(filterParams *api.FilterParams,
 		defer res.Close()
 	}
 
-	return f.parseHistogram(res, extrema, numBuckets)
+	histogram, err := f.parseHistogram(res, extrema, numBuckets)
+	if err != nil {
+		return nil, err
+	}
+
+	histogram.Missing, err = f.fetchMissingBucket(fmt.Sprintf("%s %s", fromClause, joinSQL), filterWheres, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return histogram, nil
 }
 
 func (f *DateTimeField) fetchHistogramByResult(resultURI string, filterParams *api.FilterParams, extrema *api.Extrema, numBuckets int) (*api.Histogram, error) {
 	fromClause := f.getFromClause(false)
 
 	// get filter where / params
-	wheres, params, err := f.Storage.buildResultQueryFilters(f.GetDatasetName(), f.DatasetStorageName, resultURI, filterParams, "")
+	wheres, params, err := f.Storage.buildResultQueryFilters(f.GetDatasetName(), f.DatasetStorageName, resultURI, filterParams, baseTableAlias)
 	if err != nil {
 		return nil, err
 	}
+	filterWheres := wheres
 	wheres = append(wheres, f.getDefaultFilter(true))
 
 	params = append(params, resultURI)
-
-	where := ""
-	if len(wheres) > 0 {
-		where = fmt.Sprintf("AND %s", strings.Join(wheres, " AND "))
-	}
+	wheres = append([]string{f.Storage.builder.ResultWhere(len(params))}, wheres...)
 
 	// need the extrema to calculate the histogram interval
 	if extrema == nil {
@@ -187,18 +191,11 @@ func (f *DateTimeField) fetchHistogramByResult(resultURI string, filterParams *a
 	}
 	// for each returned aggregation, create a histogram aggregation. Bucket
 	// size is derived from the min/max and desired bucket count.
-	histogramName, bucketQuery, histogramQuery := f.getHistogramAggQuery(extrema, numBuckets, baseTableAlias)
+	agg := f.getHistogramAgg(extrema, numBuckets, baseTableAlias)
 
 	// Create the complete query string.
-	query := fmt.Sprintf(`
-		SELECT %s as bucket, CAST(%s as double precision) AS %s, COUNT(%s) AS count
-		FROM %s INNER JOIN %s result ON %s."%s" = result.index
-		WHERE result.result_id = $%d %s
-		GROUP BY %s
-		ORDER BY %s;`,
-		bucketQuery, histogramQuery, histogramName, f.Count, fromClause,
-		f.Storage.getResultTable(f.DatasetStorageName), baseTableAlias,
-		model.D3MIndexFieldName, len(params), where, bucketQuery, histogramName)
+	fromResult := sqlbuilder.DataResultJoin(fromClause, f.Storage.getResultTable(f.DatasetStorageName), baseTableAlias)
+	query := f.Storage.builder.Histogram(agg, f.Count, fromResult, wheres)
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(query, params...)
@@ -209,16 +206,27 @@ func (f *DateTimeField) fetchHistogramByResult(resultURI string, filterParams *a
 		defer res.Close()
 	}
 
-	return f.parseHistogram(res, extrema, numBuckets)
+	histogram, err := f.parseHistogram(res, extrema, numBuckets)
+	if err != nil {
+		return nil, err
+	}
+
+	missingWheres := append([]string{f.Storage.builder.ResultWhere(len(params))}, filterWheres...)
+	histogram.Missing, err = f.fetchMissingBucket(fromResult, missingWheres, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return histogram, nil
 }
 
 func (f *DateTimeField) fetchExtrema() (*api.Extrema, error) {
 	fromClause := f.getFromClause(true)
 	// add min / max aggregation
-	aggQuery := f.getMinMaxAggsQuery()
+	aggQuery := f.getMinMaxAggsQuery("")
 
 	// create a query that does min and max aggregations for each variable
-	queryString := fmt.Sprintf("SELECT %s FROM %s WHERE %s;", aggQuery, fromClause, f.getDefaultFilter(true))
+	queryString := f.Storage.builder.Select(aggQuery, fromClause, []string{f.getDefaultFilter(true)})
 
 	// execute the postgres query
 	// NOTE: We may want to use the regular Query operation since QueryRow
@@ -234,29 +242,12 @@ func (f *DateTimeField) fetchExtrema() (*api.Extrema, error) {
 	return f.parseExtrema(res)
 }
 
-func (f *DateTimeField) getHistogramAggQuery(extrema *api.Extrema, numBuckets int, alias string) (string, string, string) {
+func (f *DateTimeField) getHistogramAgg(extrema *api.Extrema, numBuckets int, alias string) *sqlbuilder.HistogramAgg {
 	interval := extrema.GetBucketInterval(numBuckets)
 
-	// get histogram agg name & query string.
-	histogramAggName := fmt.Sprintf("\"%s%s\"", api.HistogramAggPrefix, extrema.Key)
-
-	bucketQueryString := ""
-	if alias != "" {
-		alias = alias + "."
-	}
-	// if only a single value, then return a simple count.
-	if extrema.Max == extrema.Min {
-		// want to return the count under bucket 0.
-		// specify a cast because the group by clause sees 0 as not a number
-		bucketQueryString = "cast(0 as integer)"
-	} else {
-		bucketQueryString = fmt.Sprintf("width_bucket(cast(extract(epoch from %s\"%s\") as integer), %d, %d, %d) - 1",
-			alias, extrema.Key, int(extrema.Min), int(extrema.Max), extrema.GetBucketCount(numBuckets))
-	}
-
-	histogramQueryString := fmt.Sprintf("(%s) * %d + %d", bucketQueryString, int(interval), int(extrema.Min))
-
-	return histogramAggName, bucketQueryString, histogramQueryString
+	field := f.Storage.builder.Dialect.Epoch(sqlbuilder.FullName(alias, extrema.Key))
+	return f.Storage.builder.HistogramAgg(extrema.Key, field, float64(int(extrema.Min)), float64(int(extrema.Max)),
+		float64(int(interval)), extrema.GetBucketCount(numBuckets))
 }
 
 func (f *DateTimeField) parseHistogram(rows pgx.Rows, extrema *api.Extrema, numBuckets int) (*api.Histogram, error) {
@@ -319,8 +310,8 @@ func (f *DateTimeField) parseHistogram(rows pgx.Rows, extrema *api.Extrema, numB
 }
 
 func (f *DateTimeField) parseExtrema(rows pgx.Rows) (*api.Extrema, error) {
-	var minValue *time.Time
-	var maxValue *time.Time
+	var minValue *float64
+	var maxValue *float64
 	if rows != nil {
 		// Expect one row of data.
 		exists := rows.Next()
@@ -340,32 +331,26 @@ func (f *DateTimeField) parseExtrema(rows pgx.Rows) (*api.Extrema, error) {
 	return &api.Extrema{
 		Key:  f.Key,
 		Type: f.Type,
-		Min:  float64(minValue.Unix()),
-		Max:  float64(maxValue.Unix()),
+		Min:  *minValue,
+		Max:  *maxValue,
 	}, nil
 }
 
-func (f *DateTimeField) getMinMaxAggsQuery() string {
-	// get min / max agg names
-	minAggName := api.MinAggPrefix + f.Key
-	maxAggName := api.MaxAggPrefix + f.Key
+func (f *DateTimeField) getMinMaxAggsQuery(alias string) string {
+	fieldTyped := f.Storage.builder.Dialect.Epoch(sqlbuilder.FullName(alias, f.Key))
 
-	// create aggregations
-	queryPart := fmt.Sprintf("MIN(\"%s\") AS \"%s\", MAX(\"%s\") AS \"%s\"",
-		f.Key, minAggName, f.Key, maxAggName)
-	// add aggregations
-	return queryPart
+	return f.Storage.builder.MinMax(f.Key, fieldTyped)
 }
 
 func (f *DateTimeField) fetchExtremaByURI(resultURI string) (*api.Extrema, error) {
 	fromClause := f.getFromClause(false)
 
 	// add min / max aggregation
-	aggQuery := f.getMinMaxAggsQuery()
+	aggQuery := f.getMinMaxAggsQuery(baseTableAlias)
 
 	// create a query that does min and max aggregations for each variable
-	queryString := fmt.Sprintf("SELECT %s FROM %s INNER JOIN %s result ON %s.\"%s\" = result.index WHERE result.result_id = $1 AND %s;",
-		aggQuery, fromClause, f.Storage.getResultTable(f.DatasetStorageName), baseTableAlias, model.D3MIndexFieldName, f.getDefaultFilter(true))
+	fromResult := sqlbuilder.DataResultJoin(fromClause, f.Storage.getResultTable(f.DatasetStorageName), baseTableAlias)
+	queryString := f.Storage.builder.Select(aggQuery, fromResult, []string{f.Storage.builder.ResultWhere(1), f.getDefaultFilter(true)})
 
 	// execute the postgres query
 	// NOTE: We may want to use the regular Query operation since QueryRow
@@ -427,7 +412,7 @@ func (f *DateTimeField) fetchPredictedSummaryData(resultURI string, datasetResul
 	}
 	// for each returned aggregation, create a histogram aggregation. Bucket
 	// size is derived from the min/max and desired bucket count.
-	histogramName, bucketQuery, histogramQuery := f.getResultHistogramAggQuery(extrema, resultVariable, numBuckets)
+	agg := f.getResultHistogramAgg(extrema, resultVariable, numBuckets)
 
 	// get filter where / params
 	wheres, params, err := f.Storage.buildResultQueryFilters(f.GetDatasetName(), f.DatasetStorageName, resultURI, filterParams, baseTableAlias)
@@ -435,18 +420,12 @@ func (f *DateTimeField) fetchPredictedSummaryData(resultURI string, datasetResul
 		return nil, err
 	}
 
-	wheres = append(wheres, fmt.Sprintf("result.result_id = $%d AND result.target = $%d ", len(params)+1, len(params)+2))
+	wheres = append(wheres, f.Storage.builder.ResultTargetWhere(len(params)+1))
 	params = append(params, resultURI, f.Key)
 
 	// Create the complete query string.
-	query := fmt.Sprintf(`
-		SELECT %s as bucket, CAST(%s as double precision) AS %s, COUNT(%s) AS count
-		FROM %s data INNER JOIN %s result ON data."%s" = result.index
-		WHERE %s
-		GROUP BY %s
-		ORDER BY %s;`,
-		bucketQuery, histogramQuery, histogramName, f.Count, f.DatasetStorageName, datasetResult,
-		model.D3MIndexFieldName, strings.Join(wheres, " AND "), bucketQuery, histogramName)
+	fromResult := sqlbuilder.DataResultJoin(fmt.Sprintf("%s data", f.DatasetStorageName), datasetResult, "data")
+	query := f.Storage.builder.Histogram(agg, f.Count, fromResult, wheres)
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(query, params...)
@@ -459,43 +438,22 @@ func (f *DateTimeField) fetchPredictedSummaryData(resultURI string, datasetResul
 }
 
 func (f *DateTimeField) getResultMinMaxAggsQuery(resultVariable *model.Variable) string {
-	// get min / max agg names
-	minAggName := api.MinAggPrefix + resultVariable.Key
-	maxAggName := api.MaxAggPrefix + resultVariable.Key
-
-	// Only numeric types should occur.
-	fieldTyped := fmt.Sprintf("cast(\"%s\" as double precision)", resultVariable.Key)
+	// predicted date times are stored as epoch values.
+	fieldTyped := f.Storage.builder.Dialect.Numeric(fmt.Sprintf("\"%s\"", resultVariable.Key))
 
-	// create aggregations
-	queryPart := fmt.Sprintf("MIN(%s) AS \"%s\", MAX(%s) AS \"%s\"", fieldTyped, minAggName, fieldTyped, maxAggName)
-	// add aggregations
-	return queryPart
+	return f.Storage.builder.MinMax(resultVariable.Key, fieldTyped)
 }
 
-func (f *DateTimeField) getResultHistogramAggQuery(extrema *api.Extrema, resultVariable *model.Variable, numBuckets int) (string, string, string) {
+func (f *DateTimeField) getResultHistogramAgg(extrema *api.Extrema, resultVariable *model.Variable, numBuckets int) *sqlbuilder.HistogramAgg {
 	// compute the bucket interval for the histogram
 	interval := extrema.GetBucketInterval(numBuckets)
-
-	// Only numeric types should occur.
-	fieldTyped := fmt.Sprintf("cast(\"%s\" as double precision)", resultVariable.Key)
-
-	// get histogram agg name & query string.
-	histogramAggName := fmt.Sprintf("\"%s%s\"", api.HistogramAggPrefix, extrema.Key)
 	rounded := extrema.GetBucketMinMax(numBuckets)
 
-	bucketQueryString := ""
-	// if only a single value, then return a simple count.
-	if rounded.Max == rounded.Min {
-		// want to return the count under bucket 0.
-		// specify a cast because the group by clause sees 0 as not a number
-		bucketQueryString = "cast(0 as integer)"
-	} else {
-		bucketQueryString = fmt.Sprintf("width_bucket(%s, %d, %d, %d) - 1",
-			fieldTyped, int(rounded.Min), int(rounded.Max), extrema.GetBucketCount(numBuckets))
-	}
-	histogramQueryString := fmt.Sprintf("(%s) * %d + %d", bucketQueryString, int(interval), int(rounded.Min))
+	// predicted date times are stored as epoch values.
+	fieldTyped := f.Storage.builder.Dialect.Numeric(fmt.Sprintf("result.\"%s\"", resultVariable.Key))
 
-	return histogramAggName, bucketQueryString, histogramQueryString
+	return f.Storage.builder.HistogramAgg(extrema.Key, fieldTyped, float64(int(rounded.Min)), float64(int(rounded.Max)),
+		float64(int(interval)), extrema.GetBucketCount(numBuckets))
 }
 
 func (f *DateTimeField) fetchResultsExtrema(resultURI string, dataset string, resultVariable *model.Variable) (*api.Extrema, error) {
@@ -503,7 +461,11 @@ func (f *DateTimeField) fetchResultsExtrema(resultURI string, dataset string, re
 	aggQuery := f.getResultMinMaxAggsQuery(resultVariable)
 
 	// create a query that does min and max aggregations for each variable
-	queryString := fmt.Sprintf("SELECT %s FROM %s WHERE result_id = $1 AND target = $2;", aggQuery, dataset)
+	wheres := []string{
+		fmt.Sprintf("result_id = %s", f.Storage.builder.Param(1)),
+		fmt.Sprintf("target = %s", f.Storage.builder.Param(2)),
+	}
+	queryString := f.Storage.builder.Select(aggQuery, dataset, wheres)
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(queryString, resultURI, f.Key)
@@ -521,6 +483,8 @@ func (f *DateTimeField) getFromClause(alias bool) string {
 		fromClause = f.subSelect()
 		if alias {
 			fromClause = fmt.Sprintf("%s AS nested INNER JOIN %s AS %s on nested.\"%s\" = %s.\"%s\"", fromClause, f.DatasetStorageName, baseTableAlias, model.D3MIndexFieldName, baseTableAlias, model.D3MIndexFieldName)
+		} else {
+			fromClause = fmt.Sprintf("%s AS %s", fromClause, baseTableAlias)
 		}
 	}
 
@@ -529,10 +493,10 @@ func (f *DateTimeField) getFromClause(alias bool) string {
 
 func (f *DateTimeField) fetchExtremaStorage() (*api.Extrema, error) {
 	// add min / max aggregation
-	aggQuery := f.getMinMaxAggsQuery()
+	aggQuery := f.getMinMaxAggsQuery("")
 
 	// create a query that does min and max aggregations for each variable
-	queryString := fmt.Sprintf("SELECT %s FROM %s;", aggQuery, f.GetDatasetStorageName())
+	queryString := f.Storage.builder.Select(aggQuery, f.GetDatasetStorageName(), nil)
 
 	// execute the postgres query
 	// NOTE: We may want to use the regular Query operation since QueryRow
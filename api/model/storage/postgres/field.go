@@ -18,9 +18,9 @@ package postgres
 import (
 	"fmt"
 
+	"github.com/pkg/errors"
 	"github.com/uncharted-distil/distil-compute/model"
 	api "github.com/uncharted-distil/distil/api/model"
-	"github.com/uncharted-distil/distil/api/postgres"
 )
 
 const (
@@ -100,19 +100,25 @@ func (b *BasicField) fetchExtremaByURI(resultURI string) (*api.Extrema, error) {
 }
 
 func (b *BasicField) getDefaultFilter(inverse bool) string {
-	defaultValue := fmt.Sprintf("%v", postgres.DefaultPostgresValueFromD3MType(b.GetType()))
+	return b.Storage.builder.DefaultFilter(b.GetType(), b.GetKey(), inverse)
+}
 
-	// null comparison is done with "is" rather than "="
-	op := "="
-	if defaultValue == "NULL" {
-		op = "is"
-	}
-	negate := ""
-	if inverse {
-		negate = "NOT"
+// fetchMissingBucket counts the rows that match the supplied filters but are
+// excluded from the histogram because the field value is missing.
+func (b *BasicField) fetchMissingBucket(fromClause string, wheres []string, params []interface{}) (*api.Bucket, error) {
+	missingWheres := append(append([]string{}, wheres...), b.getDefaultFilter(false))
+	query := b.Storage.builder.MissingCount(b.Count, fromClause, missingWheres)
+
+	var count int64
+	err := b.Storage.client.QueryRow(query, params...).Scan(&count)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch missing value count from Postgres")
 	}
 
-	return fmt.Sprintf("%s(\"%s\" %s %s)", negate, b.GetKey(), op, defaultValue)
+	return &api.Bucket{
+		Key:   api.MissingFilter,
+		Count: count,
+	}, nil
 }
 
 func (b *BasicField) getFromClause(alias bool) string {
@@ -143,6 +149,13 @@ func updateClusterFilters(metadataStorage api.MetadataStorage, dataset string, f
 				}
 			}
 		}
+		if filterParams.Expression != nil {
+			for _, h := range filterParams.Expression.GetFilters() {
+				if err := updateClusterFilter(metadataStorage, dataset, filterParams.DataMode, h); err != nil {
+					return err
+				}
+			}
+		}
 	}
 	return nil
 }
@@ -169,13 +182,3 @@ func updateClusterFilter(metadataStorage api.MetadataStorage, dataset string, da
 func (b BasicField) updateClusterHighlight(filterParams *api.FilterParams, mode api.SummaryMode) error {
 	return updateClusterFilters(b.GetStorage().metadata, b.GetDatasetName(), filterParams, mode)
 }
-
-func getCountSQL(count string) string {
-	if count == "" {
-		count = "*"
-	} else {
-		count = fmt.Sprintf("DISTINCT \"%s\"", count)
-	}
-
-	return count
-}
@@ -24,6 +24,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/uncharted-distil/distil-compute/model"
 	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/model/storage/sqlbuilder"
 )
 
 // ImageField defines behaviour for the image field type.
@@ -33,7 +34,7 @@ type ImageField struct {
 
 // NewImageField creates a new field for image types.
 func NewImageField(storage *Storage, datasetName string, datasetStorageName string, key string, label string, typ string, count string) *ImageField {
-	count = getCountSQL(count)
+	count = sqlbuilder.CountSQL(count)
 
 	field := &ImageField{
 		BasicField: BasicField{
@@ -92,7 +93,7 @@ func (f *ImageField) FetchSummaryData(resultURI string, filterParams *api.Filter
 
 // selects the target feature for the summary based on the mode - for images that's default vs. cluster display
 func (f *ImageField) featureVarName(mode api.SummaryMode) string {
-	clusterCol := featureVarName(f.Key)
+	clusterCol := sqlbuilder.FeatureVarName(f.Key)
 	if mode == api.ClusterMode && api.HasClusterData(f.GetDatasetName(), clusterCol, f.GetStorage().metadata) {
 		return clusterCol
 	}
@@ -138,7 +139,10 @@ func (f *ImageField) fetchHistogram(filterParams *api.FilterParams, mode api.Sum
 	// create the filter for the query.
 	wheres := make([]string, 0)
 	params := make([]interface{}, 0)
-	wheres, params = f.Storage.buildFilteredQueryWhere(f.GetDatasetName(), wheres, params, "", filterParams)
+	wheres, params, err := f.Storage.builder.FilteredQueryWhere(f.GetDatasetName(), wheres, params, "", filterParams)
+	if err != nil {
+		return nil, err
+	}
 
 	prefixedVarName := f.featureVarName(mode)
 
@@ -0,0 +1,94 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package postgres
+
+import (
+	"regexp"
+	"strings"
+
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/model/storage/document"
+	"github.com/uncharted-distil/distil/api/postgres"
+)
+
+const (
+	// MetadataProvenance is the provenance of datasets stored in postgres.
+	MetadataProvenance = "postgres"
+)
+
+var (
+	searchTermRegex = regexp.MustCompile(`[^\pL\pN]+`)
+)
+
+// MetadataStorage accesses the dataset metadata and exported models stored
+// as JSONB documents in postgres.
+type MetadataStorage struct {
+	*document.VariableStorage
+	client postgres.DatabaseDriver
+}
+
+// NewMetadataStorage returns a constructor for a metadata storage.
+func NewMetadataStorage(clientCtor postgres.ClientCtor) api.MetadataStorageCtor {
+	return func() (api.MetadataStorage, error) {
+		return newMetadataStorage(clientCtor)
+	}
+}
+
+// NewExportedModelStorage returns a constructor for an exported model storage.
+func NewExportedModelStorage(clientCtor postgres.ClientCtor) api.ExportedModelStorageCtor {
+	return func() (api.ExportedModelStorage, error) {
+		return newMetadataStorage(clientCtor)
+	}
+}
+
+// NewMetadataStorageClient returns a metadata storage client. It is used when
+// the postgres specific functionality, such as initialization, is needed.
+func NewMetadataStorageClient(clientCtor postgres.ClientCtor) (*MetadataStorage, error) {
+	return newMetadataStorage(clientCtor)
+}
+
+func newMetadataStorage(clientCtor postgres.ClientCtor) (*MetadataStorage, error) {
+	client, err := clientCtor()
+	if err != nil {
+		return nil, err
+	}
+
+	storage := &MetadataStorage{
+		client: client,
+	}
+	storage.VariableStorage = document.NewVariableStorage(storage)
+
+	return storage, nil
+}
+
+// InitializeMetadataStorage creates the metadata tables if they do not exist.
+func (s *MetadataStorage) InitializeMetadataStorage() error {
+	return postgres.CreateMetadataStorageTables(s.client)
+}
+
+// searchQuery builds a full text query matching documents containing any of
+// the terms, including as a prefix, in the same spirit as the elasticsearch
+// multi match queries it replaces.
+func searchQuery(terms string) string {
+	words := searchTermRegex.Split(strings.ToLower(terms), -1)
+	prefixes := []string{}
+	for _, w := range words {
+		if w != "" {
+			prefixes = append(prefixes, w+":*")
+		}
+	}
+	return strings.Join(prefixes, " | ")
+}
@@ -0,0 +1,258 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/metadata"
+	"github.com/uncharted-distil/distil-compute/model"
+
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/model/storage/document"
+	"github.com/uncharted-distil/distil/api/postgres"
+)
+
+func datasetSearchText(dataset *api.Dataset) string {
+	return strings.Join(document.DatasetSearchFields(dataset), " ")
+}
+
+func (s *MetadataStorage) parseDatasetRows(rows pgx.Rows, includeIndex bool, includeMeta bool, includeSystemData bool) ([]*api.Dataset, error) {
+	datasets := []*api.Dataset{}
+	if rows == nil {
+		return datasets, nil
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var raw []byte
+		err := rows.Scan(&raw)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to parse dataset metadata from Postgres")
+		}
+
+		dataset, err := document.ParseDataset(raw, MetadataProvenance)
+		if err != nil {
+			return nil, err
+		}
+		dataset.Variables = document.FilterVariables(dataset.Variables, includeIndex, includeMeta, includeSystemData)
+		datasets = append(datasets, dataset)
+	}
+	err := rows.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading data from postgres")
+	}
+
+	return datasets, nil
+}
+
+// FetchDatasetDocument returns the stored dataset, including deleted
+// datasets and all of its variables. It returns nil if the dataset does not
+// exist.
+func (s *MetadataStorage) FetchDatasetDocument(dataset string) (*api.Dataset, error) {
+	sql := fmt.Sprintf("SELECT document FROM %s WHERE dataset_id = $1;", postgres.DatasetMetadataTableName)
+
+	var raw []byte
+	err := s.client.QueryRow(sql, dataset).Scan(&raw)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull dataset metadata from Postgres")
+	}
+
+	return document.ParseDataset(raw, MetadataProvenance)
+}
+
+// UpdateDatasetDocument applies an update to the stored dataset. The dataset
+// row is locked from the read until the write is committed so that concurrent
+// updates are applied one after the other rather than overwriting each other.
+func (s *MetadataStorage) UpdateDatasetDocument(dataset string, update func(ds *api.Dataset) error) error {
+	tx, err := s.client.Begin()
+	if err != nil {
+		return errors.Wrap(err, "unable to begin transaction")
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	sql := fmt.Sprintf("SELECT document FROM %s WHERE dataset_id = $1 FOR UPDATE;", postgres.DatasetMetadataTableName)
+	var raw []byte
+	err = tx.QueryRow(context.Background(), sql, dataset).Scan(&raw)
+	if err == pgx.ErrNoRows {
+		return errors.Errorf("dataset '%s' not found", dataset)
+	} else if err != nil {
+		return errors.Wrap(err, "Unable to pull dataset metadata from Postgres")
+	}
+	ds, err := document.ParseDataset(raw, MetadataProvenance)
+	if err != nil {
+		return err
+	}
+
+	err = update(ds)
+	if err != nil {
+		return err
+	}
+
+	sql, params, err := getPersistDatasetSQL(ds)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(context.Background(), sql, params...)
+	if err != nil {
+		return errors.Wrapf(err, "failed to persist dataset metadata to PostGres")
+	}
+
+	err = tx.Commit(context.Background())
+	if err != nil {
+		return errors.Wrap(err, "unable to commit transaction")
+	}
+	return nil
+}
+
+// ImportDataset is not supported (postgres datasets are already ingested).
+func (s *MetadataStorage) ImportDataset(id string, uri string) (string, error) {
+	return "", errors.Errorf("Not Supported")
+}
+
+// CloneDataset stores a copy of an existing dataset's metadata under a new
+// id.
+func (s *MetadataStorage) CloneDataset(dataset string, datasetNew string, storageNameNew string, folderNew string) error {
+	ds, err := s.FetchDataset(dataset, true, true, false)
+	if err != nil {
+		return err
+	}
+	if ds == nil {
+		return errors.Errorf("dataset '%s' not found", dataset)
+	}
+
+	document.CloneDataset(ds, datasetNew, storageNameNew, folderNew)
+
+	return s.UpdateDataset(ds)
+}
+
+// UpdateDataset inserts or replaces the dataset metadata document.
+func (s *MetadataStorage) UpdateDataset(dataset *api.Dataset) error {
+	sql, params, err := getPersistDatasetSQL(dataset)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Exec(sql, params...)
+
+	return errors.Wrapf(err, "failed to persist dataset metadata to PostGres")
+}
+
+func getPersistDatasetSQL(dataset *api.Dataset) (string, []interface{}, error) {
+	raw, err := json.Marshal(dataset)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to marshal dataset document")
+	}
+
+	sql := fmt.Sprintf(`INSERT INTO %s (dataset_id, type, deleted, document, search) VALUES ($1, $2, $3, $4, to_tsvector('simple', $5))
+		ON CONFLICT (dataset_id) DO UPDATE SET type = EXCLUDED.type, deleted = EXCLUDED.deleted, document = EXCLUDED.document, search = EXCLUDED.search;`,
+		postgres.DatasetMetadataTableName)
+
+	return sql, []interface{}{dataset.ID, string(dataset.Type), dataset.Deleted, string(raw), datasetSearchText(dataset)}, nil
+}
+
+// IngestDataset stores the metadata of a newly ingested dataset.
+func (s *MetadataStorage) IngestDataset(datasetSource metadata.DatasetSource, meta *model.Metadata) error {
+	dataset, err := document.NewIngestedDataset(datasetSource, meta, MetadataProvenance)
+	if err != nil {
+		return err
+	}
+
+	return s.UpdateDataset(dataset)
+}
+
+// DeleteDataset deletes a dataset from postgres.
+func (s *MetadataStorage) DeleteDataset(dataset string, softDelete bool) error {
+	if !softDelete {
+		sql := fmt.Sprintf("DELETE FROM %s WHERE dataset_id = $1;", postgres.DatasetMetadataTableName)
+		_, err := s.client.Exec(sql, dataset)
+		return errors.Wrapf(err, "failed to delete dataset metadata from PostGres")
+	}
+
+	// update the deleted flag
+	return s.UpdateDatasetDocument(dataset, func(ds *api.Dataset) error {
+		ds.Deleted = true
+		return nil
+	})
+}
+
+// FetchDatasets returns all datasets that are not deleted, excluding
+// inference datasets.
+func (s *MetadataStorage) FetchDatasets(includeIndex bool, includeMeta bool, includeSystemData bool) ([]*api.Dataset, error) {
+	sql := fmt.Sprintf("SELECT document FROM %s WHERE NOT deleted AND type <> $1 ORDER BY dataset_id;", postgres.DatasetMetadataTableName)
+
+	rows, err := s.client.Query(sql, string(api.DatasetTypeInference))
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull dataset metadata from Postgres")
+	}
+
+	return s.parseDatasetRows(rows, includeIndex, includeMeta, includeSystemData)
+}
+
+// FetchDataset returns a dataset that is not deleted, or nil if there is no
+// such dataset.
+func (s *MetadataStorage) FetchDataset(datasetName string, includeIndex bool, includeMeta bool, includeSystemData bool) (*api.Dataset, error) {
+	ds, err := s.FetchDatasetDocument(datasetName)
+	if err != nil {
+		return nil, err
+	}
+	if ds == nil || ds.Deleted {
+		return nil, nil
+	}
+	ds.Variables = document.FilterVariables(ds.Variables, includeIndex, includeMeta, includeSystemData)
+
+	return ds, nil
+}
+
+// SearchDatasets returns the datasets that match any of the search terms,
+// best matches first.
+func (s *MetadataStorage) SearchDatasets(terms string, baseDataset *api.Dataset, includeIndex bool, includeMeta bool, includeSystemData bool) ([]*api.Dataset, error) {
+	query := searchQuery(terms)
+	if query == "" {
+		return []*api.Dataset{}, nil
+	}
+
+	sql := fmt.Sprintf(`SELECT document FROM %s WHERE NOT deleted AND search @@ to_tsquery('simple', $1)
+		ORDER BY ts_rank(search, to_tsquery('simple', $1)) DESC, dataset_id;`, postgres.DatasetMetadataTableName)
+
+	rows, err := s.client.Query(sql, query)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to search dataset metadata in Postgres")
+	}
+
+	return s.parseDatasetRows(rows, includeIndex, includeMeta, includeSystemData)
+}
+
+// DatasetExists returns true if a dataset exists in postgres, whether deleted
+// or not.
+func (s *MetadataStorage) DatasetExists(dataset string) (bool, error) {
+	sql := fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM %s WHERE dataset_id = $1);", postgres.DatasetMetadataTableName)
+
+	var exists bool
+	err := s.client.QueryRow(sql, dataset).Scan(&exists)
+	if err != nil {
+		return false, errors.Wrap(err, "Unable to check for dataset metadata in Postgres")
+	}
+
+	return exists, nil
+}
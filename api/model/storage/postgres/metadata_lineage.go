@@ -0,0 +1,75 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package postgres
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/model/storage/document"
+	"github.com/uncharted-distil/distil/api/postgres"
+)
+
+// PersistLineageEdge stores a lineage edge. Edges are immutable so storing an
+// edge that already exists fails.
+func (s *MetadataStorage) PersistLineageEdge(edge *api.LineageEdge) error {
+	raw, err := json.Marshal(edge)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal lineage edge")
+	}
+
+	sql := fmt.Sprintf("INSERT INTO %s (edge_id, target, document, created_time) VALUES ($1, $2, $3, $4);", postgres.DatasetLineageTableName)
+
+	_, err = s.client.Exec(sql, edge.ID, edge.Target, string(raw), edge.Created)
+
+	return errors.Wrapf(err, "failed to persist lineage edge to PostGres")
+}
+
+// FetchLineageEdges returns the lineage edges that derived the dataset,
+// ordered from oldest to newest.
+func (s *MetadataStorage) FetchLineageEdges(dataset string) ([]*api.LineageEdge, error) {
+	sql := fmt.Sprintf("SELECT document FROM %s WHERE target = $1 ORDER BY created_time;", postgres.DatasetLineageTableName)
+
+	rows, err := s.client.Query(sql, dataset)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull lineage edges from Postgres")
+	}
+	defer rows.Close()
+
+	edges := []*api.LineageEdge{}
+	for rows.Next() {
+		var raw []byte
+		err = rows.Scan(&raw)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to parse lineage edge from Postgres")
+		}
+
+		edge, err := document.ParseLineageEdge(raw)
+		if err != nil {
+			return nil, err
+		}
+		edges = append(edges, edge)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading data from postgres")
+	}
+
+	return edges, nil
+}
@@ -0,0 +1,129 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package postgres
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/model/storage/document"
+	"github.com/uncharted-distil/distil/api/postgres"
+)
+
+func modelSearchText(model *api.ExportedModel) string {
+	return strings.Join(document.ModelSearchFields(model), " ")
+}
+
+func (s *MetadataStorage) parseModelRows(rows pgx.Rows) ([]*api.ExportedModel, error) {
+	defer rows.Close()
+
+	models := []*api.ExportedModel{}
+	for rows.Next() {
+		var raw []byte
+		err := rows.Scan(&raw)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to parse exported model from Postgres")
+		}
+
+		model, err := document.ParseModel(raw)
+		if err != nil {
+			return nil, err
+		}
+		models = append(models, model)
+	}
+	err := rows.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading data from postgres")
+	}
+
+	return models, nil
+}
+
+func (s *MetadataStorage) queryModels(where string, params ...interface{}) ([]*api.ExportedModel, error) {
+	sql := fmt.Sprintf("SELECT document FROM %s %s;", postgres.ExportedModelTableName, where)
+
+	rows, err := s.client.Query(sql, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull exported models from Postgres")
+	}
+
+	return s.parseModelRows(rows)
+}
+
+// PersistExportedModel inserts or replaces an exported model.
+func (s *MetadataStorage) PersistExportedModel(model *api.ExportedModel) error {
+	raw, err := json.Marshal(model)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal model")
+	}
+
+	sql := fmt.Sprintf(`INSERT INTO %s (fitted_solution_id, model_name, deleted, document, search) VALUES ($1, $2, $3, $4, to_tsvector('simple', $5))
+		ON CONFLICT (fitted_solution_id) DO UPDATE SET model_name = EXCLUDED.model_name, deleted = EXCLUDED.deleted, document = EXCLUDED.document, search = EXCLUDED.search;`,
+		postgres.ExportedModelTableName)
+
+	_, err = s.client.Exec(sql, model.FittedSolutionID, model.ModelName, model.Deleted, string(raw), modelSearchText(model))
+
+	return errors.Wrapf(err, "failed to persist exported model to PostGres")
+}
+
+// FetchModel returns a model by the name assigned to it by the user.
+func (s *MetadataStorage) FetchModel(modelName string) (*api.ExportedModel, error) {
+	return document.FirstModel(s.queryModels("WHERE model_name = $1 ORDER BY (document->>'version')::int DESC", modelName))
+}
+
+// FetchModelByID returns a model using the model's fitted solution ID.
+func (s *MetadataStorage) FetchModelByID(fittedSolutionID string) (*api.ExportedModel, error) {
+	return document.FirstModel(s.queryModels("WHERE fitted_solution_id = $1", fittedSolutionID))
+}
+
+// FetchModels returns all exported models.
+func (s *MetadataStorage) FetchModels(includeDeleted bool) ([]*api.ExportedModel, error) {
+	return s.queryModels("WHERE $1 OR NOT deleted ORDER BY model_name, fitted_solution_id", includeDeleted)
+}
+
+// SearchModels returns the models that match any of the search terms, best
+// matches first.
+func (s *MetadataStorage) SearchModels(terms string, includeDeleted bool) ([]*api.ExportedModel, error) {
+	query := searchQuery(terms)
+	if query == "" {
+		return []*api.ExportedModel{}, nil
+	}
+
+	return s.queryModels(`WHERE ($2 OR NOT deleted) AND search @@ to_tsquery('simple', $1)
+		ORDER BY ts_rank(search, to_tsquery('simple', $1)) DESC, fitted_solution_id`, query, includeDeleted)
+}
+
+// DeleteModel flags a model as deleted. The model is retained so that its
+// version number is never reassigned.
+func (s *MetadataStorage) DeleteModel(fittedSolutionID string) error {
+	sql := fmt.Sprintf("UPDATE %s SET deleted = true, document = jsonb_set(document, '{deleted}', 'true') WHERE fitted_solution_id = $1;",
+		postgres.ExportedModelTableName)
+
+	_, err := s.client.Exec(sql, fittedSolutionID)
+
+	return errors.Wrapf(err, "failed to delete exported model from PostGres")
+}
+
+// FetchModelVersions returns all versions of the named model, including
+// deleted ones, ordered by version.
+func (s *MetadataStorage) FetchModelVersions(modelName string) ([]*api.ExportedModel, error) {
+	return s.queryModels("WHERE model_name = $1 ORDER BY (document->>'version')::int", modelName)
+}
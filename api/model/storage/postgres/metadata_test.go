@@ -0,0 +1,38 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+func TestSearchQuery(t *testing.T) {
+	assert.Equal(t, "baseball:* | player:* | 1:*", searchQuery("Baseball player_1"))
+	assert.Equal(t, "a:* | b:* | s:*", searchQuery("  a & b's"))
+	assert.Equal(t, "", searchQuery(" !& "))
+}
+
+func TestGetPersistDatasetSQL(t *testing.T) {
+	sql, params, err := getPersistDatasetSQL(&api.Dataset{ID: "ts", Deleted: true})
+	assert.NoError(t, err)
+	assert.Contains(t, sql, "ON CONFLICT (dataset_id) DO UPDATE")
+	assert.Equal(t, "ts", params[0])
+	assert.Equal(t, true, params[2])
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/uncharted-distil/distil-compute/model"
 	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/model/storage/sqlbuilder"
 	"github.com/uncharted-distil/distil/api/postgres"
 )
 
@@ -37,7 +38,7 @@ type MultiBandImageField struct {
 // NewMultiBandImageField creates a new field for mutli band image types.
 func NewMultiBandImageField(storage *Storage, datasetName string, datasetStorageName string, clusterCol string, key string, label string, typ string,
 	idCol string, bandCol string) *MultiBandImageField {
-	count := getCountSQL(idCol)
+	count := sqlbuilder.CountSQL(idCol)
 
 	field := &MultiBandImageField{
 		BasicField: BasicField{
@@ -143,7 +144,10 @@ func (f *MultiBandImageField) fetchHistogram(filterParams *api.FilterParams, mod
 	// create the filter for the query.
 	wheres := make([]string, 0)
 	params := make([]interface{}, 0)
-	wheres, params = f.Storage.buildFilteredQueryWhere(f.GetDatasetName(), wheres, params, "", filterParams)
+	wheres, params, err := f.Storage.builder.FilteredQueryWhere(f.GetDatasetName(), wheres, params, "", filterParams)
+	if err != nil {
+		return nil, err
+	}
 
 	prefixedVarName := f.featureVarName(mode)
 
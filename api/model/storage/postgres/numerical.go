@@ -18,12 +18,12 @@ package postgres
 import (
 	"fmt"
 	"strconv"
-	"strings"
 
 	"github.com/jackc/pgx/v4"
 	"github.com/pkg/errors"
 	"github.com/uncharted-distil/distil-compute/model"
 	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/model/storage/sqlbuilder"
 	log "github.com/unchartedsoftware/plog"
 )
 
@@ -42,7 +42,7 @@ type NumericalStats struct {
 
 // NewNumericalField creates a new field for numerical types.
 func NewNumericalField(storage *Storage, datasetName string, datasetStorageName string, key string, label string, typ string, count string) *NumericalField {
-	count = getCountSQL(count)
+	count = sqlbuilder.CountSQL(count)
 
 	field := &NumericalField{
 		BasicField: BasicField{
@@ -62,7 +62,7 @@ func NewNumericalField(storage *Storage, datasetName string, datasetStorageName
 // NewNumericalFieldSubSelect creates a new field for numerical types
 // and specifies a sub select query to pull the raw data.
 func NewNumericalFieldSubSelect(storage *Storage, datasetName string, datasetStorageName string, key string, label string, typ string, count string, fieldSubSelect func() string) *NumericalField {
-	count = getCountSQL(count)
+	count = sqlbuilder.CountSQL(count)
 
 	field := &NumericalField{
 		BasicField: BasicField{
@@ -128,7 +128,11 @@ func (f *NumericalField) fetchHistogramWithJoins(filterParams *api.FilterParams,
 	fromClause := f.getFromClause(true)
 
 	// create the filter for the query.
-	wheres, params = f.Storage.buildFilteredQueryWhere(f.GetDatasetName(), wheres, params, "", filterParams)
+	wheres, params, err := f.Storage.builder.FilteredQueryWhere(f.GetDatasetName(), wheres, params, "", filterParams)
+	if err != nil {
+		return nil, err
+	}
+	filterWheres := wheres
 	wheres = append(wheres, f.getDefaultFilter(true))
 
 	// need the extrema to calculate the histogram interval
@@ -143,18 +147,12 @@ func (f *NumericalField) fetchHistogramWithJoins(filterParams *api.FilterParams,
 
 	// for each returned aggregation, create a histogram aggregation. Bucket
 	// size is derived from the min/max and desired bucket count.
-	histogramName, bucketQuery, histogramQuery := f.getHistogramAggQuery(extrema, numBuckets, "")
-
-	where := ""
-	if len(wheres) > 0 {
-		where = fmt.Sprintf("WHERE %s", strings.Join(wheres, " AND "))
-	}
+	agg := f.getHistogramAgg(extrema, numBuckets, "")
 
 	joinSQL := createJoinStatements(joins)
 
 	// Create the complete query string.
-	query := fmt.Sprintf("SELECT %s as bucket, CAST(%s as double precision) AS %s, COUNT(%s) AS count FROM %s %s %s GROUP BY %s ORDER BY %s;",
-		bucketQuery, histogramQuery, histogramName, f.Count, fromClause, joinSQL, where, bucketQuery, histogramName)
+	query := f.Storage.builder.Histogram(agg, f.Count, fmt.Sprintf("%s %s", fromClause, joinSQL), wheres)
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(query, params...)
@@ -170,6 +168,11 @@ func (f *NumericalField) fetchHistogramWithJoins(filterParams *api.FilterParams,
 		return nil, err
 	}
 
+	histogram.Missing, err = f.fetchMissingBucket(fmt.Sprintf("%s %s", fromClause, joinSQL), filterWheres, params)
+	if err != nil {
+		return nil, err
+	}
+
 	stats, err := f.FetchNumericalStats(filterParams)
 	if err != nil {
 		return nil, err
@@ -187,14 +190,11 @@ func (f *NumericalField) fetchHistogramByResult(resultURI string, filterParams *
 	if err != nil {
 		return nil, err
 	}
+	filterWheres := wheres
 	wheres = append(wheres, f.getDefaultFilter(true))
 
 	params = append(params, resultURI)
-
-	where := ""
-	if len(wheres) > 0 {
-		where = fmt.Sprintf("AND %s", strings.Join(wheres, " AND "))
-	}
+	wheres = append([]string{f.Storage.builder.ResultWhere(len(params))}, wheres...)
 
 	// need the extrema to calculate the histogram interval
 	if extrema == nil {
@@ -211,18 +211,11 @@ func (f *NumericalField) fetchHistogramByResult(resultURI string, filterParams *
 	}
 	// for each returned aggregation, create a histogram aggregation. Bucket
 	// size is derived from the min/max and desired bucket count.
-	histogramName, bucketQuery, histogramQuery := f.getHistogramAggQuery(extrema, numBuckets, baseTableAlias)
+	agg := f.getHistogramAgg(extrema, numBuckets, baseTableAlias)
 
 	// Create the complete query string.
-	query := fmt.Sprintf(`
-		SELECT %s as bucket, CAST(%s as double precision) AS %s, COUNT(%s) AS count
-		FROM %s INNER JOIN %s result ON %s."%s" = result.index
-		WHERE result.result_id = $%d %s
-		GROUP BY %s
-		ORDER BY %s;`,
-		bucketQuery, histogramQuery, histogramName, f.Count, fromClause,
-		f.Storage.getResultTable(f.DatasetStorageName), baseTableAlias,
-		model.D3MIndexFieldName, len(params), where, bucketQuery, histogramName)
+	fromResult := sqlbuilder.DataResultJoin(fromClause, f.Storage.getResultTable(f.DatasetStorageName), baseTableAlias)
+	query := f.Storage.builder.Histogram(agg, f.Count, fromResult, wheres)
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(query, params...)
@@ -238,6 +231,12 @@ func (f *NumericalField) fetchHistogramByResult(resultURI string, filterParams *
 		return nil, err
 	}
 
+	missingWheres := append([]string{f.Storage.builder.ResultWhere(len(params))}, filterWheres...)
+	histogram.Missing, err = f.fetchMissingBucket(fromResult, missingWheres, params)
+	if err != nil {
+		return nil, err
+	}
+
 	stats, err := f.FetchNumericalStatsByResult(resultURI, filterParams)
 	if err != nil {
 		return nil, err
@@ -255,7 +254,7 @@ func (f *NumericalField) fetchExtrema() (*api.Extrema, error) {
 
 	// create a query that does min and max aggregations for each variable
 	// need to ignore the NaN values
-	queryString := fmt.Sprintf("SELECT %s FROM %s WHERE %s;", aggQuery, fromClause, f.getDefaultFilter(true))
+	queryString := f.Storage.builder.Select(aggQuery, fromClause, []string{f.getDefaultFilter(true)})
 
 	// execute the postgres query
 	// NOTE: We may want to use the regular Query operation since QueryRow
@@ -271,29 +270,12 @@ func (f *NumericalField) fetchExtrema() (*api.Extrema, error) {
 	return f.parseExtrema(res)
 }
 
-func (f *NumericalField) getHistogramAggQuery(extrema *api.Extrema, numBuckets int, alias string) (string, string, string) {
+func (f *NumericalField) getHistogramAgg(extrema *api.Extrema, numBuckets int, alias string) *sqlbuilder.HistogramAgg {
 	interval := extrema.GetBucketInterval(numBuckets)
-
-	// get histogram agg name & query string.
-	histogramAggName := fmt.Sprintf("\"%s%s\"", api.HistogramAggPrefix, extrema.Key)
 	rounded := extrema.GetBucketMinMax(numBuckets)
 
-	bucketQueryString := ""
-	if alias != "" {
-		alias = alias + "."
-	}
-	// if only a single value, then return a simple count.
-	if rounded.Max == rounded.Min {
-		// want to return the count under bucket 0.
-		bucketQueryString = fmt.Sprintf("(%s\"%s\" - %s\"%s\")", alias, extrema.Key, alias, extrema.Key)
-	} else {
-		bucketQueryString = fmt.Sprintf("width_bucket(%s\"%s\", %g, %g, %d) - 1",
-			alias, extrema.Key, rounded.Min, rounded.Max, extrema.GetBucketCount(numBuckets))
-	}
-
-	histogramQueryString := fmt.Sprintf("(%s) * %g + %g", bucketQueryString, interval, rounded.Min)
-
-	return histogramAggName, bucketQueryString, histogramQueryString
+	field := sqlbuilder.FullName(alias, extrema.Key)
+	return f.Storage.builder.HistogramAgg(extrema.Key, field, rounded.Min, rounded.Max, interval, extrema.GetBucketCount(numBuckets))
 }
 
 func (f *NumericalField) parseHistogram(rows pgx.Rows, extrema *api.Extrema, numBuckets int) (*api.Histogram, error) {
@@ -385,15 +367,7 @@ func (f *NumericalField) parseExtrema(rows pgx.Rows) (*api.Extrema, error) {
 }
 
 func (f *NumericalField) getMinMaxAggsQuery() string {
-	// get min / max agg names
-	minAggName := api.MinAggPrefix + f.Key
-	maxAggName := api.MaxAggPrefix + f.Key
-
-	// create aggregations
-	queryPart := fmt.Sprintf("MIN(\"%s\") AS \"%s\", MAX(\"%s\") AS \"%s\"",
-		f.Key, minAggName, f.Key, maxAggName)
-	// add aggregations
-	return queryPart
+	return f.Storage.builder.MinMax(f.Key, fmt.Sprintf("\"%s\"", f.Key))
 }
 
 func (f *NumericalField) fetchExtremaByURI(resultURI string) (*api.Extrema, error) {
@@ -403,8 +377,8 @@ func (f *NumericalField) fetchExtremaByURI(resultURI string) (*api.Extrema, erro
 	aggQuery := f.getMinMaxAggsQuery()
 
 	// create a query that does min and max aggregations for each variable
-	queryString := fmt.Sprintf("SELECT %s FROM %s INNER JOIN %s result ON %s.\"%s\" = result.index WHERE result.result_id = $1 AND %s;",
-		aggQuery, fromClause, f.Storage.getResultTable(f.DatasetStorageName), baseTableAlias, model.D3MIndexFieldName, f.getDefaultFilter(true))
+	fromResult := sqlbuilder.DataResultJoin(fromClause, f.Storage.getResultTable(f.DatasetStorageName), baseTableAlias)
+	queryString := f.Storage.builder.Select(aggQuery, fromResult, []string{f.Storage.builder.ResultWhere(1), f.getDefaultFilter(true)})
 
 	// execute the postgres query
 	// NOTE: We may want to use the regular Query operation since QueryRow
@@ -466,7 +440,7 @@ func (f *NumericalField) fetchPredictedSummaryData(resultURI string, datasetResu
 	}
 	// for each returned aggregation, create a histogram aggregation. Bucket
 	// size is derived from the min/max and desired bucket count.
-	histogramName, bucketQuery, histogramQuery := f.getResultHistogramAggQuery(extrema, resultVariable, numBuckets)
+	agg := f.getResultHistogramAgg(extrema, resultVariable, numBuckets)
 
 	// get filter where / params
 	wheres, params, err := f.Storage.buildResultQueryFilters(f.GetDatasetName(), f.DatasetStorageName, resultURI, filterParams, baseTableAlias)
@@ -474,19 +448,13 @@ func (f *NumericalField) fetchPredictedSummaryData(resultURI string, datasetResu
 		return nil, err
 	}
 
-	wheres = append(wheres, fmt.Sprintf("result.result_id = $%d AND result.target = $%d ", len(params)+1, len(params)+2))
+	wheres = append(wheres, f.Storage.builder.ResultTargetWhere(len(params)+1))
 	params = append(params, resultURI, f.Key)
-	wheres = append(wheres, fmt.Sprintf("%s != ''", resultVariable.Key))
+	wheres = append(wheres, fmt.Sprintf("result.\"%s\" != ''", resultVariable.Key))
 
 	// Create the complete query string.
-	query := fmt.Sprintf(`
-		SELECT %s as bucket, CAST(%s as double precision) AS %s, COUNT(%s) AS count
-		FROM %s data INNER JOIN %s result ON data."%s" = result.index
-		WHERE %s
-		GROUP BY %s
-		ORDER BY %s;`,
-		bucketQuery, histogramQuery, histogramName, f.Count, f.DatasetStorageName, datasetResult,
-		model.D3MIndexFieldName, strings.Join(wheres, " AND "), bucketQuery, histogramName)
+	fromResult := sqlbuilder.DataResultJoin(fmt.Sprintf("%s data", f.DatasetStorageName), datasetResult, "data")
+	query := f.Storage.builder.Histogram(agg, f.Count, fromResult, wheres)
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(query, params...)
@@ -499,43 +467,21 @@ func (f *NumericalField) fetchPredictedSummaryData(resultURI string, datasetResu
 }
 
 func (f *NumericalField) getResultMinMaxAggsQuery(resultVariable *model.Variable) string {
-	// get min / max agg names
-	minAggName := api.MinAggPrefix + resultVariable.Key
-	maxAggName := api.MaxAggPrefix + resultVariable.Key
-
 	// Only numeric types should occur.
-	fieldTyped := fmt.Sprintf("CAST(CASE WHEN \"%s\" = '' THEN 'NaN' ELSE \"%s\" END as double precision)", resultVariable.Key, resultVariable.Key)
+	fieldTyped := f.Storage.builder.Dialect.Numeric(fmt.Sprintf("\"%s\"", resultVariable.Key))
 
-	// create aggregations
-	queryPart := fmt.Sprintf("MIN(%s) AS \"%s\", MAX(%s) AS \"%s\"", fieldTyped, minAggName, fieldTyped, maxAggName)
-
-	// add aggregations
-	return queryPart
+	return f.Storage.builder.MinMax(resultVariable.Key, fieldTyped)
 }
 
-func (f *NumericalField) getResultHistogramAggQuery(extrema *api.Extrema, resultVariable *model.Variable, numBuckets int) (string, string, string) {
+func (f *NumericalField) getResultHistogramAgg(extrema *api.Extrema, resultVariable *model.Variable, numBuckets int) *sqlbuilder.HistogramAgg {
 	// compute the bucket interval for the histogram
 	interval := extrema.GetBucketInterval(numBuckets)
-
-	// Only numeric types should occur.
-	fieldTyped := fmt.Sprintf("CAST(CASE WHEN \"%s\" = '' THEN 'NaN' ELSE \"%s\" END as double precision)", resultVariable.Key, resultVariable.Key)
-
-	// get histogram agg name & query string.
-	histogramAggName := fmt.Sprintf("\"%s%s\"", api.HistogramAggPrefix, extrema.Key)
 	rounded := extrema.GetBucketMinMax(numBuckets)
 
-	bucketQueryString := ""
-	// if only a single value, then return a simple count.
-	if rounded.Max == rounded.Min {
-		// want to return the count under bucket 0.
-		bucketQueryString = fmt.Sprintf("(\"%s\" - \"%s\")", fieldTyped, fieldTyped)
-	} else {
-		bucketQueryString = fmt.Sprintf("width_bucket(%s, %g, %g, %d) - 1",
-			fieldTyped, rounded.Min, rounded.Max, extrema.GetBucketCount(numBuckets))
-	}
-	histogramQueryString := fmt.Sprintf("(%s) * %g + %g", bucketQueryString, interval, rounded.Min)
+	// Only numeric types should occur.
+	fieldTyped := f.Storage.builder.Dialect.Numeric(fmt.Sprintf("result.\"%s\"", resultVariable.Key))
 
-	return histogramAggName, bucketQueryString, histogramQueryString
+	return f.Storage.builder.HistogramAgg(extrema.Key, fieldTyped, rounded.Min, rounded.Max, interval, extrema.GetBucketCount(numBuckets))
 }
 
 func (f *NumericalField) fetchResultsExtrema(resultURI string, dataset string, resultVariable *model.Variable) (*api.Extrema, error) {
@@ -543,7 +489,12 @@ func (f *NumericalField) fetchResultsExtrema(resultURI string, dataset string, r
 	aggQuery := f.getResultMinMaxAggsQuery(resultVariable)
 
 	// create a query that does min and max aggregations for each variable
-	queryString := fmt.Sprintf("SELECT %s FROM %s WHERE result_id = $1 AND target = $2 AND %s != '';", aggQuery, dataset, resultVariable.Key)
+	wheres := []string{
+		fmt.Sprintf("result_id = %s", f.Storage.builder.Param(1)),
+		fmt.Sprintf("target = %s", f.Storage.builder.Param(2)),
+		fmt.Sprintf("\"%s\" != ''", resultVariable.Key),
+	}
+	queryString := f.Storage.builder.Select(aggQuery, dataset, wheres)
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(queryString, resultURI, f.Key)
@@ -562,16 +513,14 @@ func (f *NumericalField) FetchNumericalStats(filterParams *api.FilterParams) (*N
 	// create the filter for the query.
 	wheres := make([]string, 0)
 	params := make([]interface{}, 0)
-	wheres, params = f.Storage.buildFilteredQueryWhere(f.GetDatasetName(), wheres, params, "", filterParams)
-	wheres = append(wheres, f.getDefaultFilter(true))
-
-	where := ""
-	if len(wheres) > 0 {
-		where = fmt.Sprintf("WHERE %s", strings.Join(wheres, " AND "))
+	wheres, params, err := f.Storage.builder.FilteredQueryWhere(f.GetDatasetName(), wheres, params, "", filterParams)
+	if err != nil {
+		return nil, err
 	}
+	wheres = append(wheres, f.getDefaultFilter(true))
 
 	// Create the complete query string.
-	query := fmt.Sprintf("SELECT coalesce(stddev(\"%s\"), 0) as stddev, avg(\"%s\") as avg FROM %s %s;", f.Key, f.Key, fromClause, where)
+	query := f.Storage.builder.Stats(f.Key, fromClause, wheres)
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(query, params...)
@@ -597,15 +546,11 @@ func (f *NumericalField) FetchNumericalStatsByResult(resultURI string, filterPar
 	wheres = append(wheres, f.getDefaultFilter(true))
 
 	params = append(params, resultURI)
-
-	where := ""
-	if len(wheres) > 0 {
-		where = fmt.Sprintf("AND %s", strings.Join(wheres, " AND "))
-	}
+	wheres = append([]string{f.Storage.builder.ResultWhere(len(params))}, wheres...)
 
 	// Create the complete query string.
-	query := fmt.Sprintf("SELECT coalesce(stddev(\"%s\"), 0) as stddev, avg(\"%s\") as avg FROM %s INNER JOIN %s result ON %s.\"%s\" = result.index WHERE result.result_id = $%d %s;",
-		f.Key, f.Key, fromClause, f.Storage.getResultTable(f.DatasetStorageName), baseTableAlias, model.D3MIndexFieldName, len(params), where)
+	fromResult := sqlbuilder.DataResultJoin(fromClause, f.Storage.getResultTable(f.DatasetStorageName), baseTableAlias)
+	query := f.Storage.builder.Stats(f.Key, fromResult, wheres)
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(query, params...)
@@ -662,6 +607,8 @@ func (f *NumericalField) getFromClause(alias bool) string {
 		if alias {
 			fromClause = fmt.Sprintf("%s AS nested INNER JOIN %s AS %s on nested.\"%s\" = %s.\"%s\"",
 				fromClause, f.DatasetStorageName, baseTableAlias, model.D3MIndexFieldName, baseTableAlias, model.D3MIndexFieldName)
+		} else {
+			fromClause = fmt.Sprintf("%s AS %s", fromClause, baseTableAlias)
 		}
 	}
 
@@ -672,10 +619,10 @@ func (f *NumericalField) fetchExtremaStorage() (*api.Extrema, error) {
 	aggQuery := f.getMinMaxAggsQuery()
 
 	// numerical columns need to filter NaN out
-	filter := fmt.Sprintf("WHERE \"%s\" != 'NaN'", f.Key)
+	filter := fmt.Sprintf("\"%s\" != 'NaN'", f.Key)
 
 	// create a query that does min and max aggregations for each variable
-	queryString := fmt.Sprintf("SELECT %s FROM %s %s;", aggQuery, f.GetDatasetStorageName(), filter)
+	queryString := f.Storage.builder.Select(aggQuery, f.GetDatasetStorageName(), []string{filter})
 
 	// execute the postgres query
 	// NOTE: We may want to use the regular Query operation since QueryRow
@@ -98,13 +98,23 @@ func (s *Storage) PersistSolutionResult(solutionID string, fittedSolutionID stri
 
 // PersistSolutionScore persist the solution score to Postgres.
 func (s *Storage) PersistSolutionScore(solutionID string, metric string, score float64) error {
-	sql := fmt.Sprintf("INSERT INTO %s (solution_id, metric, score) VALUES ($1, $2, $3);", postgres.SolutionScoreTableName)
+	sql := fmt.Sprintf("INSERT INTO %s (solution_id, metric, score, score_std_dev, fold_count) VALUES ($1, $2, $3, 0, 1);", postgres.SolutionScoreTableName)
 
 	_, err := s.client.Exec(sql, solutionID, metric, score)
 
 	return err
 }
 
+// PersistSolutionCrossValidationScore persists the mean and standard deviation
+// of a solution score across cross validation folds to Postgres.
+func (s *Storage) PersistSolutionCrossValidationScore(solutionID string, metric string, mean float64, stdDev float64, folds int) error {
+	sql := fmt.Sprintf("INSERT INTO %s (solution_id, metric, score, score_std_dev, fold_count) VALUES ($1, $2, $3, $4, $5);", postgres.SolutionScoreTableName)
+
+	_, err := s.client.Exec(sql, solutionID, metric, mean, stdDev, folds)
+
+	return err
+}
+
 // FetchSolution pulls solution information from Postgres.
 func (s *Storage) FetchSolution(solutionID string) (*api.Solution, error) {
 	sql := fmt.Sprintf("SELECT request_id, solution_id, explained_solution_id, created_time FROM %s WHERE solution_id = $1 ORDER BY created_time desc LIMIT 1;", postgres.SolutionTableName)
@@ -525,7 +535,7 @@ func (s *Storage) FetchSolutionResultByProduceRequestID(produceRequestID string)
 
 // FetchSolutionScores pulls solution score from Postgres.
 func (s *Storage) FetchSolutionScores(solutionID string) ([]*api.SolutionScore, error) {
-	sql := fmt.Sprintf("SELECT solution_id, metric, score FROM %s WHERE solution_id = $1;", postgres.SolutionScoreTableName)
+	sql := fmt.Sprintf("SELECT solution_id, metric, score, coalesce(score_std_dev, 0), coalesce(fold_count, 1) FROM %s WHERE solution_id = $1;", postgres.SolutionScoreTableName)
 
 	rows, err := s.client.Query(sql, solutionID)
 	if err != nil {
@@ -540,8 +550,10 @@ func (s *Storage) FetchSolutionScores(solutionID string) ([]*api.SolutionScore,
 		var solutionID string
 		var metric string
 		var score float64
+		var stdDev float64
+		var folds int
 
-		err = rows.Scan(&solutionID, &metric, &score)
+		err = rows.Scan(&solutionID, &metric, &score, &stdDev, &folds)
 		if err != nil {
 			return nil, errors.Wrap(err, "Unable to parse result score from Postgres")
 		}
@@ -551,6 +563,9 @@ func (s *Storage) FetchSolutionScores(solutionID string) ([]*api.SolutionScore,
 			Metric:         metric,
 			Label:          compute.GetMetricLabel(metric),
 			Score:          score,
+			Mean:           score,
+			StdDev:         stdDev,
+			Folds:          folds,
 			SortMultiplier: compute.GetMetricScoreMultiplier(metric),
 		})
 	}
@@ -0,0 +1,159 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+
+	api "github.com/uncharted-distil/distil/api/model"
+	postgres "github.com/uncharted-distil/distil/api/postgres"
+)
+
+const (
+	predictionScheduleFields    = "schedule_id, fitted_solution_id, input_path, cron, output_path, format, created_time"
+	predictionScheduleRunFields = "run_id, schedule_id, input_path, status, produce_request_id, output_path, error, start_time, end_time"
+)
+
+// PersistPredictionSchedule persists a prediction schedule to Postgres.
+func (s *Storage) PersistPredictionSchedule(schedule *api.PredictionSchedule) error {
+	sql := fmt.Sprintf("INSERT INTO %s (%s) VALUES ($1, $2, $3, $4, $5, $6, $7);", postgres.PredictionScheduleTableName, predictionScheduleFields)
+
+	_, err := s.client.Exec(sql, schedule.ScheduleID, schedule.FittedSolutionID, schedule.InputPath,
+		schedule.Cron, schedule.OutputPath, schedule.Format, schedule.CreatedTime)
+
+	return errors.Wrapf(err, "failed to persist prediction schedule to PostGres")
+}
+
+// DeletePredictionSchedule removes a prediction schedule from Postgres.  The run history
+// of the schedule is kept.
+func (s *Storage) DeletePredictionSchedule(scheduleID string) error {
+	sql := fmt.Sprintf("DELETE FROM %s WHERE schedule_id = $1;", postgres.PredictionScheduleTableName)
+
+	_, err := s.client.Exec(sql, scheduleID)
+
+	return errors.Wrapf(err, "failed to delete prediction schedule from PostGres")
+}
+
+// PersistPredictionScheduleRun persists the start of a prediction schedule run to Postgres.
+func (s *Storage) PersistPredictionScheduleRun(run *api.PredictionScheduleRun) error {
+	sql := fmt.Sprintf("INSERT INTO %s (%s) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);", postgres.PredictionScheduleRunTableName, predictionScheduleRunFields)
+
+	_, err := s.client.Exec(sql, run.RunID, run.ScheduleID, run.InputPath, run.Status, run.ProduceRequestID,
+		run.OutputPath, run.Error, run.StartTime, run.EndTime)
+
+	return errors.Wrapf(err, "failed to persist prediction schedule run to PostGres")
+}
+
+// UpdatePredictionScheduleRun updates the outcome of a prediction schedule run in Postgres.
+func (s *Storage) UpdatePredictionScheduleRun(run *api.PredictionScheduleRun) error {
+	sql := fmt.Sprintf("UPDATE %s SET status = $1, produce_request_id = $2, output_path = $3, error = $4, end_time = $5 WHERE run_id = $6;",
+		postgres.PredictionScheduleRunTableName)
+
+	_, err := s.client.Exec(sql, run.Status, run.ProduceRequestID, run.OutputPath, run.Error, run.EndTime, run.RunID)
+
+	return errors.Wrapf(err, "failed to update prediction schedule run in PostGres")
+}
+
+// FetchPredictionSchedule pulls a prediction schedule from Postgres.
+func (s *Storage) FetchPredictionSchedule(scheduleID string) (*api.PredictionSchedule, error) {
+	sql := fmt.Sprintf("SELECT %s FROM %s WHERE schedule_id = $1;", predictionScheduleFields, postgres.PredictionScheduleTableName)
+
+	rows, err := s.client.Query(sql, scheduleID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull prediction schedule from Postgres")
+	}
+	if rows != nil {
+		defer rows.Close()
+	}
+
+	schedules, err := s.loadPredictionSchedules(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(schedules) == 0 {
+		return nil, errors.Errorf("prediction schedule '%s' not found", scheduleID)
+	}
+
+	return schedules[0], nil
+}
+
+// FetchPredictionSchedules pulls all prediction schedules from Postgres.
+func (s *Storage) FetchPredictionSchedules() ([]*api.PredictionSchedule, error) {
+	sql := fmt.Sprintf("SELECT %s FROM %s ORDER BY created_time;", predictionScheduleFields, postgres.PredictionScheduleTableName)
+
+	rows, err := s.client.Query(sql)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull prediction schedules from Postgres")
+	}
+	if rows != nil {
+		defer rows.Close()
+	}
+
+	return s.loadPredictionSchedules(rows)
+}
+
+// FetchPredictionScheduleRuns pulls the run history of a prediction schedule from Postgres,
+// most recent first.
+func (s *Storage) FetchPredictionScheduleRuns(scheduleID string) ([]*api.PredictionScheduleRun, error) {
+	sql := fmt.Sprintf("SELECT %s FROM %s WHERE schedule_id = $1 ORDER BY start_time desc;", predictionScheduleRunFields, postgres.PredictionScheduleRunTableName)
+
+	rows, err := s.client.Query(sql, scheduleID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull prediction schedule runs from Postgres")
+	}
+	if rows != nil {
+		defer rows.Close()
+	}
+
+	runs := []*api.PredictionScheduleRun{}
+	for rows.Next() {
+		run := &api.PredictionScheduleRun{}
+		err = rows.Scan(&run.RunID, &run.ScheduleID, &run.InputPath, &run.Status, &run.ProduceRequestID,
+			&run.OutputPath, &run.Error, &run.StartTime, &run.EndTime)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to parse prediction schedule run from Postgres")
+		}
+		runs = append(runs, run)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading data from postgres")
+	}
+
+	return runs, nil
+}
+
+func (s *Storage) loadPredictionSchedules(rows pgx.Rows) ([]*api.PredictionSchedule, error) {
+	schedules := []*api.PredictionSchedule{}
+	for rows.Next() {
+		schedule := &api.PredictionSchedule{}
+		err := rows.Scan(&schedule.ScheduleID, &schedule.FittedSolutionID, &schedule.InputPath, &schedule.Cron,
+			&schedule.OutputPath, &schedule.Format, &schedule.CreatedTime)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to parse prediction schedule from Postgres")
+		}
+		schedules = append(schedules, schedule)
+	}
+	err := rows.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading data from postgres")
+	}
+
+	return schedules, nil
+}
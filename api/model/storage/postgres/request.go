@@ -16,7 +16,7 @@
 package postgres
 
 import (
-	"fmt"
+	"encoding/json"
 	"strings"
 	"time"
 
@@ -24,12 +24,11 @@ import (
 	"github.com/pkg/errors"
 	"github.com/uncharted-distil/distil-compute/model"
 	api "github.com/uncharted-distil/distil/api/model"
-	postgres "github.com/uncharted-distil/distil/api/postgres"
 )
 
 // PersistRequest persists a request to Postgres.
 func (s *Storage) PersistRequest(requestID string, dataset string, progress string, createdTime time.Time) error {
-	sql := fmt.Sprintf("INSERT INTO %s (request_id, dataset, progress, created_time, last_updated_time) VALUES ($1, $2, $3, $4, $4);", postgres.RequestTableName)
+	sql := s.builder.PersistRequest()
 
 	_, err := s.client.Exec(sql, requestID, dataset, progress, createdTime)
 
@@ -38,7 +37,7 @@ func (s *Storage) PersistRequest(requestID string, dataset string, progress stri
 
 // UpdateRequest updates a request in Postgres.
 func (s *Storage) UpdateRequest(requestID string, progress string, updatedTime time.Time) error {
-	sql := fmt.Sprintf("UPDATE %s SET progress = $1, last_updated_time = $2 WHERE request_id = $3;", postgres.RequestTableName)
+	sql := s.builder.UpdateRequest()
 
 	_, err := s.client.Exec(sql, progress, updatedTime, requestID)
 
@@ -47,7 +46,7 @@ func (s *Storage) UpdateRequest(requestID string, progress string, updatedTime t
 
 // PersistRequestFeature persists request feature information to Postgres.
 func (s *Storage) PersistRequestFeature(requestID string, featureName string, featureType string) error {
-	sql := fmt.Sprintf("INSERT INTO %s (request_id, feature_name, feature_type) VALUES ($1, $2, $3);", postgres.RequestFeatureTableName)
+	sql := s.builder.PersistRequestFeature()
 
 	_, err := s.client.Exec(sql, requestID, featureName, featureType)
 
@@ -56,9 +55,7 @@ func (s *Storage) PersistRequestFeature(requestID string, featureName string, fe
 
 // PersistRequestFilters persists request filters information to Postgres.
 func (s *Storage) PersistRequestFilters(requestID string, filters *api.FilterParams) error {
-	sql := fmt.Sprintf(
-		"INSERT INTO %s (request_id, feature_name, filter_type, filter_mode, filter_min, filter_max, filter_min_x, filter_max_x, filter_min_y, filter_max_y, filter_categories, filter_indices) "+
-			"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);", postgres.RequestFilterTableName)
+	sql := s.builder.PersistRequestFilter()
 
 	for _, filterSet := range filters.Filters {
 		for _, filterFeatures := range filterSet.FeatureFilters {
@@ -84,16 +81,57 @@ func (s *Storage) PersistRequestFilters(requestID string, filters *api.FilterPar
 					if err != nil {
 						return errors.Wrap(err, "failed to persist row filter")
 					}
+				case api.MissingFilter:
+					_, err := s.client.Exec(sql, requestID, filter.Key, api.MissingFilter, filter.Mode, 0, 0, 0, 0, 0, 0, "", "")
+					if err != nil {
+						return errors.Wrap(err, "failed to persist missing filter")
+					}
 				}
 			}
 		}
 	}
+
+	// the expression cannot be flattened into filter rows so it is stored as a document
+	if filters.Expression != nil {
+		expressionJSON, err := json.Marshal(filters.Expression)
+		if err != nil {
+			return errors.Wrap(err, "failed to serialize filter expression")
+		}
+		sql = s.builder.PersistRequestFilterExpression()
+		_, err = s.client.Exec(sql, requestID, string(expressionJSON))
+		if err != nil {
+			return errors.Wrap(err, "failed to persist filter expression")
+		}
+	}
 	return nil
 }
 
+// PersistRequestSplitManifest persists the train/test split manifest of a request to Postgres.
+func (s *Storage) PersistRequestSplitManifest(requestID string, manifest *api.SplitManifest) error {
+	sql := s.builder.PersistRequestSplitManifest()
+
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return errors.Wrap(err, "failed to serialize split manifest")
+	}
+
+	_, err = s.client.Exec(sql, requestID, manifest.Splitter, manifest.Seed, manifest.TrainTestSplit, string(manifestJSON), manifest.CreatedTime)
+
+	return errors.Wrapf(err, "failed to persist request split manifest to PostGres")
+}
+
+// PersistSolutionStatusEvent persists a status update of a solution request to Postgres.
+func (s *Storage) PersistSolutionStatusEvent(event *api.SolutionStatusEvent) error {
+	sql := s.builder.PersistSolutionStatusEvent()
+
+	_, err := s.client.Exec(sql, event.RequestID, event.SolutionID, event.ResultID, event.Progress, event.Error, event.CreatedTime)
+
+	return errors.Wrapf(err, "failed to persist solution status event to PostGres")
+}
+
 // FetchRequest pulls request information from Postgres.
 func (s *Storage) FetchRequest(requestID string) (*api.Request, error) {
-	sql := fmt.Sprintf("SELECT request_id, dataset, progress, created_time, last_updated_time FROM %s WHERE request_id = $1 ORDER BY created_time desc LIMIT 1;", postgres.RequestTableName)
+	sql := s.builder.FetchRequest()
 
 	rows, err := s.client.Query(sql, requestID)
 	if err != nil {
@@ -114,9 +152,7 @@ func (s *Storage) FetchRequest(requestID string) (*api.Request, error) {
 // FetchRequestByResultUUID pulls request information from Postgres using
 // a result UUID.
 func (s *Storage) FetchRequestByResultUUID(resultUUID string) (*api.Request, error) {
-	sql := fmt.Sprintf("SELECT req.request_id, req.dataset, req.progress, req.created_time, req.last_updated_time "+
-		"FROM %s as req INNER JOIN %s as sol ON req.request_id = sol.request_id INNER JOIN %s as sol_res ON sol.solution_id = sol_res.solution_id "+
-		"WHERE sol_res.result_uuid = $1;", postgres.RequestTableName, postgres.SolutionTableName, postgres.SolutionResultTableName)
+	sql := s.builder.FetchRequestByResultUUID()
 
 	rows, err := s.client.Query(sql, resultUUID)
 	if err != nil {
@@ -139,9 +175,7 @@ func (s *Storage) FetchRequestByResultUUID(resultUUID string) (*api.Request, err
 // FetchRequestBySolutionID pulls request information from Postgres using
 // a solution ID.
 func (s *Storage) FetchRequestBySolutionID(solutionID string) (*api.Request, error) {
-	sql := fmt.Sprintf("SELECT req.request_id, req.dataset, req.progress, req.created_time, req.last_updated_time "+
-		"FROM %s as req INNER JOIN %s as sol ON req.request_id = sol.request_id "+
-		"WHERE sol.solution_id = $1;", postgres.RequestTableName, postgres.SolutionTableName)
+	sql := s.builder.FetchRequestBySolutionID()
 
 	rows, err := s.client.Query(sql, solutionID)
 	if err != nil {
@@ -164,9 +198,7 @@ func (s *Storage) FetchRequestBySolutionID(solutionID string) (*api.Request, err
 // FetchRequestByFittedSolutionID pulls request information from Postgres using
 // a fitted solution ID.
 func (s *Storage) FetchRequestByFittedSolutionID(fittedSolutionID string) (*api.Request, error) {
-	sql := fmt.Sprintf("SELECT req.request_id, req.dataset, req.progress, req.created_time, req.last_updated_time "+
-		"FROM %s as req INNER JOIN %s as sol ON req.request_id = sol.request_id INNER JOIN %s sr on sr.solution_id = sol.solution_id "+
-		"WHERE sr.fitted_solution_id = $1;", postgres.RequestTableName, postgres.SolutionTableName, postgres.SolutionResultTableName)
+	sql := s.builder.FetchRequestByFittedSolutionID()
 
 	rows, err := s.client.Query(sql, fittedSolutionID)
 	if err != nil {
@@ -221,7 +253,7 @@ func (s *Storage) loadRequest(rows pgx.Rows) (*api.Request, error) {
 
 // FetchRequestFeatures pulls request feature information from Postgres.
 func (s *Storage) FetchRequestFeatures(requestID string) ([]*api.Feature, error) {
-	sql := fmt.Sprintf("SELECT request_id, feature_name, feature_type FROM %s WHERE request_id = $1;", postgres.RequestFeatureTableName)
+	sql := s.builder.FetchRequestFeatures()
 
 	rows, err := s.client.Query(sql, requestID)
 	if err != nil {
@@ -233,11 +265,10 @@ func (s *Storage) FetchRequestFeatures(requestID string) ([]*api.Feature, error)
 
 	results := make([]*api.Feature, 0)
 	for rows.Next() {
-		var requestID string
 		var featureName string
 		var featureType string
 
-		err = rows.Scan(&requestID, &featureName, &featureType)
+		err = rows.Scan(&featureName, &featureType)
 		if err != nil {
 			return nil, errors.Wrap(err, "Unable to parse request features from Postgres")
 		}
@@ -256,9 +287,80 @@ func (s *Storage) FetchRequestFeatures(requestID string) ([]*api.Feature, error)
 	return results, nil
 }
 
+// FetchRequestSplitManifest pulls the train/test split manifest of a request from Postgres.
+func (s *Storage) FetchRequestSplitManifest(requestID string) (*api.SplitManifest, error) {
+	sql := s.builder.FetchRequestSplitManifest()
+
+	rows, err := s.client.Query(sql, requestID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull request split manifest from Postgres")
+	}
+	if rows != nil {
+		defer rows.Close()
+	}
+	if !rows.Next() {
+		return nil, errors.Errorf("no split manifest for request %s", requestID)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading data from postgres")
+	}
+
+	var manifestJSON []byte
+	err = rows.Scan(&manifestJSON)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to parse request split manifest from Postgres")
+	}
+
+	manifest := &api.SplitManifest{}
+	err = json.Unmarshal(manifestJSON, manifest)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to parse request split manifest")
+	}
+
+	return manifest, nil
+}
+
+// fetchRequestFilterExpression pulls the filter expression of a request, if any.
+func (s *Storage) fetchRequestFilterExpression(requestID string) (*api.FilterExpression, error) {
+	sql := s.builder.FetchRequestFilterExpression()
+
+	rows, err := s.client.Query(sql, requestID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull request filter expression from Postgres")
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		err = rows.Err()
+		if err != nil {
+			return nil, errors.Wrapf(err, "error reading data from postgres")
+		}
+		return nil, nil
+	}
+
+	var expressionJSON []byte
+	err = rows.Scan(&expressionJSON)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to parse request filter expression from Postgres")
+	}
+
+	expression := &api.FilterExpression{}
+	err = json.Unmarshal(expressionJSON, expression)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to parse request filter expression")
+	}
+
+	return expression, nil
+}
+
 // FetchRequestFilters pulls request filter information from Postgres.
 func (s *Storage) FetchRequestFilters(requestID string, features []*api.Feature) (*api.FilterParams, error) {
-	sql := fmt.Sprintf("SELECT request_id, feature_name, filter_type, filter_mode, filter_min, filter_max, filter_min_x, filter_max_x, filter_min_y, filter_max_y, filter_categories, filter_indices FROM %s WHERE request_id = $1;", postgres.RequestFilterTableName)
+	expression, err := s.fetchRequestFilterExpression(requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	sql := s.builder.FetchRequestFilters()
 
 	rows, err := s.client.Query(sql, requestID)
 	if err != nil {
@@ -270,7 +372,6 @@ func (s *Storage) FetchRequestFilters(requestID string, features []*api.Feature)
 
 	filters := []*model.Filter{}
 	for rows.Next() {
-		var requestID string
 		var featureName string
 		var filterType string
 		var filterMode string
@@ -283,7 +384,7 @@ func (s *Storage) FetchRequestFilters(requestID string, features []*api.Feature)
 		var filterCategories string
 		var filterIndices string
 
-		err = rows.Scan(&requestID, &featureName, &filterType, &filterMode, &filterMin, &filterMax, &filterMinX, &filterMaxX, &filterMinY, &filterMaxY, &filterCategories, &filterIndices)
+		err = rows.Scan(&featureName, &filterType, &filterMode, &filterMin, &filterMax, &filterMinX, &filterMaxX, &filterMinY, &filterMaxY, &filterCategories, &filterIndices)
 		if err != nil {
 			return nil, errors.Wrap(err, "Unable to parse request filters from Postgres")
 		}
@@ -331,6 +432,11 @@ func (s *Storage) FetchRequestFilters(requestID string, features []*api.Feature)
 				filterMode,
 				strings.Split(filterIndices, ","),
 			))
+		case api.MissingFilter:
+			filters = append(filters, api.NewMissingFilter(
+				featureName,
+				filterMode,
+			))
 		}
 	}
 	err = rows.Err()
@@ -339,6 +445,7 @@ func (s *Storage) FetchRequestFilters(requestID string, features []*api.Feature)
 	}
 
 	filterParams := api.NewFilterParamsFromFilters(filters)
+	filterParams.Expression = expression
 
 	for _, feature := range features {
 		filterParams.Variables = append(filterParams.Variables, feature.FeatureName)
@@ -350,24 +457,7 @@ func (s *Storage) FetchRequestFilters(requestID string, features []*api.Feature)
 // FetchRequestByDatasetTarget pulls requests associated with a given dataset and target from postgres.
 func (s *Storage) FetchRequestByDatasetTarget(dataset string, target string) ([]*api.Request, error) {
 	// get the solution ids
-	sql := fmt.Sprintf("SELECT DISTINCT ON(request.request_id) request.request_id, request.dataset, request.progress, request.created_time, request.last_updated_time "+
-		"FROM %s request INNER JOIN %s rf ON request.request_id = rf.request_id "+
-		"INNER JOIN %s solution ON request.request_id = solution.request_id",
-		postgres.RequestTableName, postgres.RequestFeatureTableName, postgres.SolutionTableName)
-	params := make([]interface{}, 0)
-
-	if dataset != "" {
-		sql = fmt.Sprintf("%s AND request.dataset = $%d", sql, len(params)+1)
-		params = append(params, dataset)
-	}
-	if target != "" {
-		sql = fmt.Sprintf("%s AND rf.feature_name = $%d AND rf.feature_type = $%d ORDER BY request.request_id, request.last_updated_time DESC",
-			sql, len(params)+1, len(params)+2)
-		params = append(params, target)
-		params = append(params, model.FeatureTypeTarget)
-	}
-
-	sql = fmt.Sprintf("%s;", sql)
+	sql, params := s.builder.FetchRequestByDatasetTarget(dataset, target)
 	rows, err := s.client.Query(sql, params...)
 	if err != nil {
 		return nil, err
@@ -390,3 +480,33 @@ func (s *Storage) FetchRequestByDatasetTarget(dataset string, target string) ([]
 	}
 	return requests, nil
 }
+
+// FetchSolutionStatusEvents pulls the status updates of a solution request from Postgres, in the order
+// they were persisted.
+func (s *Storage) FetchSolutionStatusEvents(requestID string) ([]*api.SolutionStatusEvent, error) {
+	sql := s.builder.FetchSolutionStatusEvents()
+
+	rows, err := s.client.Query(sql, requestID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull solution status events from Postgres")
+	}
+	if rows != nil {
+		defer rows.Close()
+	}
+
+	events := []*api.SolutionStatusEvent{}
+	for rows.Next() {
+		event := &api.SolutionStatusEvent{}
+		err = rows.Scan(&event.RequestID, &event.SolutionID, &event.ResultID, &event.Progress, &event.Error, &event.CreatedTime)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to parse solution status event from Postgres")
+		}
+		events = append(events, event)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading data from postgres")
+	}
+
+	return events, nil
+}
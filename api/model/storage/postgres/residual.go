@@ -17,11 +17,11 @@ package postgres
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/uncharted-distil/distil-compute/model"
 	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/model/storage/sqlbuilder"
 )
 
 // FetchResidualsExtremaByURI fetches the residual extrema by resultURI.
@@ -96,49 +96,22 @@ func (s *Storage) fetchResidualsSummary(dataset string, storageName string, vari
 	return nil, errors.Errorf("variable of type %s - should be numeric", variable.Type)
 }
 
-func getErrorTyped(alias string, variableName string) string {
-	fullName := fmt.Sprintf("\"%s\"", variableName)
-	if alias != "" {
-		fullName = fmt.Sprintf("%s.%s", alias, fullName)
-	}
-	return fmt.Sprintf("(cast(value as double precision) - cast(%s as double precision))", fullName)
-}
-
-func (s *Storage) getResidualsHistogramAggQuery(extrema *api.Extrema, variableName string, resultVariable *model.Variable, numBuckets int, alias string) (string, string, string) {
+func (s *Storage) getResidualsHistogramAgg(extrema *api.Extrema, variableName string, resultVariable *model.Variable, numBuckets int, alias string) *sqlbuilder.HistogramAgg {
 	// compute the bucket interval for the histogram
 	interval := extrema.GetBucketInterval(numBuckets)
-
-	// Only numeric types should occur.
-	errorTyped := getErrorTyped(alias, variableName)
-
-	// get histogram agg name & query string.
-	histogramAggName := fmt.Sprintf("\"%s%s\"", api.HistogramAggPrefix, extrema.Key)
 	rounded := extrema.GetBucketMinMax(numBuckets)
-	bucketQueryString := fmt.Sprintf("width_bucket(%s, %g, %g, %d) - 1",
-		errorTyped, rounded.Min, rounded.Max, extrema.GetBucketCount(numBuckets))
-	histogramQueryString := fmt.Sprintf("(%s) * %g + %g", bucketQueryString, interval, rounded.Min)
 
-	return histogramAggName, bucketQueryString, histogramQueryString
-}
+	// Only numeric types should occur.
+	errorTyped := s.builder.ErrorTyped(alias, variableName)
 
-func getResultJoin(alias string, storageName string) string {
-	// FROM clause to join result and base data on d3mIdex value
-	return fmt.Sprintf("%s_result as %s inner join %s as data on data.\"%s\" = %s.index",
-		storageName, alias, storageName, model.D3MIndexFieldName, alias)
+	return s.builder.HistogramAgg(extrema.Key, errorTyped, rounded.Min, rounded.Max, interval, extrema.GetBucketCount(numBuckets))
 }
 
-func getResidualsMinMaxAggsQuery(variableName string, resultVariable *model.Variable) string {
-	// get min / max agg names
-	minAggName := api.MinAggPrefix + resultVariable.Key
-	maxAggName := api.MaxAggPrefix + resultVariable.Key
-
+func (s *Storage) getResidualsMinMaxAggsQuery(variableName string, resultVariable *model.Variable) string {
 	// Only numeric types should occur.
-	errorTyped := getErrorTyped("", variableName)
+	errorTyped := s.builder.ErrorTyped("", variableName)
 
-	// create aggregations
-	queryPart := fmt.Sprintf("MIN(%s) AS \"%s\", MAX(%s) AS \"%s\"", errorTyped, minAggName, errorTyped, maxAggName)
-
-	return queryPart
+	return s.builder.MinMax(resultVariable.Key, errorTyped)
 }
 
 func (s *Storage) fetchResidualsExtrema(resultURI string, storageName string, variable *model.Variable,
@@ -149,13 +122,18 @@ func (s *Storage) fetchResidualsExtrema(resultURI string, storageName string, va
 		targetName = variable.Grouping.(*model.TimeseriesGrouping).YCol
 	}
 	// add min / max aggregation
-	aggQuery := getResidualsMinMaxAggsQuery(targetName, resultVariable)
+	aggQuery := s.getResidualsMinMaxAggsQuery(targetName, resultVariable)
 
 	// from clause to join result and base data
-	fromClause := getResultJoin("res", storageName)
+	fromClause := sqlbuilder.ResultJoin("res", storageName)
 
 	// create a query that does min and max aggregations for each variable
-	queryString := fmt.Sprintf("SELECT %s FROM %s WHERE result_id = $1 AND target = $2 AND value != '';", aggQuery, fromClause)
+	wheres := []string{
+		fmt.Sprintf("result_id = %s", s.builder.Param(1)),
+		fmt.Sprintf("target = %s", s.builder.Param(2)),
+		"value != ''",
+	}
+	queryString := s.builder.Select(aggQuery, fromClause, wheres)
 
 	// execute the postgres query
 	res, err := s.client.Query(queryString, resultURI, variable.Key)
@@ -191,9 +169,9 @@ func (s *Storage) fetchResidualsHistogram(resultURI string, datasetName, storage
 	}
 	// for each returned aggregation, create a histogram aggregation. Bucket
 	// size is derived from the min/max and desired bucket count.
-	histogramName, bucketQuery, histogramQuery := s.getResidualsHistogramAggQuery(extrema, variableName, resultVariable, numBuckets, baseTableAlias)
+	agg := s.getResidualsHistogramAgg(extrema, variableName, resultVariable, numBuckets, baseTableAlias)
 
-	fromClause := getResultJoin("result", storageName)
+	fromClause := sqlbuilder.ResultJoin("result", storageName)
 
 	// create the filter for the query
 
@@ -201,20 +179,13 @@ func (s *Storage) fetchResidualsHistogram(resultURI string, datasetName, storage
 	if err != nil {
 		return nil, err
 	}
-	where := ""
-	if len(wheres) > 0 {
-		where = fmt.Sprintf("AND %s", strings.Join(wheres, " AND "))
-	}
-
-	// Create the complete query string.
-	query := fmt.Sprintf(`
-		SELECT %s as bucket, CAST(%s as double precision) AS %s, COUNT(*) AS count
-		FROM %s
-		WHERE result.result_id = $%d AND result.target = $%d AND result.%s != '' %s
-		GROUP BY %s ORDER BY %s;`, bucketQuery, histogramQuery, histogramName,
-		fromClause, len(params)+1, len(params)+2, resultVariable.Key, where, bucketQuery, histogramName)
+	wheres = append(wheres, s.builder.ResultTargetWhere(len(params)+1), fmt.Sprintf("result.%s != ''", resultVariable.Key))
 	params = append(params, resultURI)
 	params = append(params, variable.Key)
+
+	// Create the complete query string.
+	query := s.builder.Histogram(agg, "*", fromClause, wheres)
+
 	// execute the postgres query
 	res, err := s.client.Query(query, params...)
 	if err != nil {
@@ -28,6 +28,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/uncharted-distil/distil-compute/model"
 	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/model/storage/sqlbuilder"
 	"github.com/uncharted-distil/distil/api/serialization"
 	jsonu "github.com/uncharted-distil/distil/api/util/json"
 	log "github.com/unchartedsoftware/plog"
@@ -552,117 +553,6 @@ func parsePostgresType(columnValue interface{}, description pgproto3.FieldDescri
 	return columnValue, nil
 }
 
-func isCorrectnessCategory(categoryName string) bool {
-	return strings.EqualFold(CorrectCategory, categoryName) || strings.EqualFold(categoryName, IncorrectCategory)
-}
-
-func addCorrectnessFilterToWhere(wheres []string, params []interface{}, correctnessFilters model.FilterObject, target *model.Variable) ([]string, []interface{}, error) {
-	wheresFilter := []string{}
-	for _, correctnessFilter := range correctnessFilters.List {
-		if len(correctnessFilter.Categories[0]) == 0 {
-			return nil, nil, fmt.Errorf("no category")
-		}
-		for _, category := range correctnessFilter.Categories {
-			// filter for result correctness which is based on well know category values
-			op := ""
-			if strings.EqualFold(category, CorrectCategory) {
-				op = "="
-			} else if strings.EqualFold(category, IncorrectCategory) {
-				op = "!="
-			}
-			if op == "" {
-				return nil, nil, errors.New(fmt.Sprintf("Error correctness category is not within set [%s, %s]", IncorrectCategory, CorrectCategory))
-			}
-			wheresFilter = append(wheresFilter, fmt.Sprintf("(predicted.value %s data.\"%s\")", op, target.Key))
-		}
-	}
-	return append(wheres, fmt.Sprintf("(%s)", strings.Join(wheresFilter, " OR "))), params, nil
-}
-
-func getFullName(alias string, column string) string {
-	fullName := fmt.Sprintf("\"%s\"", column)
-	if alias != "" {
-		fullName = fmt.Sprintf("%s.%s", alias, fullName)
-	}
-
-	return fullName
-}
-
-func addPredictedFilterToWhere(wheres []string, params []interface{}, predictedFilters model.FilterObject, target *model.Variable) ([]string, []interface{}, error) {
-	// Handle the predicted column, which is accessed as `value` in the result query
-	wheresFilter := []string{}
-	for _, predictedFilter := range predictedFilters.List {
-		where := ""
-		switch predictedFilter.Type {
-		case model.NumericalFilter:
-			// numerical range-based filter
-			where = fmt.Sprintf("(cast(predicted.value AS double precision) >= $%d AND cast(predicted.value AS double precision) <= $%d)", len(params)+1, len(params)+2)
-			params = append(params, *predictedFilter.Min)
-			params = append(params, *predictedFilter.Max)
-
-		case model.BivariateFilter:
-			// cast to double precision in case of string based representation
-			// hardcode [lat, lon] format for now
-			where = fmt.Sprintf("(predicted.value[2] >= $%d AND predicted.value[2] <= $%d AND predicted.value[1] >= $%d AND predicted.value[1] <= $%d)", len(params)+1, len(params)+2, len(params)+3, len(params)+4)
-			params = append(params, predictedFilter.Bounds.MinX)
-			params = append(params, predictedFilter.Bounds.MaxX)
-			params = append(params, predictedFilter.Bounds.MinY)
-			params = append(params, predictedFilter.Bounds.MaxY)
-
-		case model.CategoricalFilter:
-			// categorical label based filter, with checks for special correct/incorrect metafilters
-			categories := make([]string, 0)
-			offset := len(params) + 1
-
-			for i, category := range predictedFilter.Categories {
-				if !isCorrectnessCategory(category) {
-					categories = append(categories, fmt.Sprintf("$%d", offset+i))
-					params = append(params, category)
-				}
-			}
-
-			if len(categories) >= 1 {
-				where = fmt.Sprintf("(predicted.value IN (%s))", strings.Join(categories, ", "))
-			}
-
-		case model.RowFilter:
-			// row index based filter
-			indices := make([]string, 0)
-			offset := len(params) + 1
-			for i, d3mIndex := range predictedFilter.D3mIndices {
-				indices = append(indices, fmt.Sprintf("$%d", offset+i))
-				params = append(params, d3mIndex)
-
-			}
-			if len(indices) >= 1 {
-				where = fmt.Sprintf("(predicted.value IN (%s))", strings.Join(indices, ", "))
-			}
-
-		default:
-			return nil, nil, errors.Errorf("unexpected type %s for variable %s", predictedFilter.Type, predictedFilter.Key)
-		}
-
-		wheresFilter = append(wheresFilter, where)
-	}
-
-	// combine the different modes together into a single filter statement
-	return append(wheres, fmt.Sprintf("(%s)", strings.Join(wheresFilter, " OR "))), params, nil
-}
-
-func addErrorFilterToWhere(wheres []string, params []interface{}, alias string, targetName string, residualFilters model.FilterObject) ([]string, []interface{}, error) {
-	wheresFilter := []string{}
-	for _, residualFilter := range residualFilters.List {
-		// Add a clause to filter residuals to the existing where
-		typedError := getErrorTyped(alias, targetName)
-		wheresFilter = append(wheresFilter, fmt.Sprintf("(%s >= $%d AND %s <= $%d)", typedError, len(params)+1, typedError, len(params)+2))
-		params = append(params, *residualFilter.Min)
-		params = append(params, *residualFilter.Max)
-	}
-
-	// OR the clauses together
-	return append(wheres, fmt.Sprintf("(%s)", strings.Join(wheresFilter, " OR "))), params, nil
-}
-
 func addTableAlias(prefix string, fields []string, addToColumn bool) []string {
 	fieldsPrepended := make([]string, len(fields))
 	for i, f := range fields {
@@ -713,7 +603,7 @@ func (s *Storage) FetchResults(dataset string, storageName string, resultURI str
 	// need to create all filters by mode
 	for _, filterSet := range append(filterParams.Filters, filterParams.Highlights...) {
 		// break filters out groups for specific handling
-		filters, err := splitFilters(filterSet)
+		filters, err := sqlbuilder.SplitFilters(filterSet)
 		if err != nil {
 			return nil, err
 		}
@@ -721,48 +611,52 @@ func (s *Storage) FetchResults(dataset string, storageName string, resultURI str
 		wheresMode := make([]string, 0)
 		// Create the filter portion of the where clause.
 		where := ""
-		where, params = s.buildSelectionFilter(dataset, params, dataTableAlias, filters.genericFilters)
+		where, params, err = s.builder.SelectionFilter(dataset, params, dataTableAlias, filters.Generic)
+		if err != nil {
+			return nil, err
+		}
 		if len(where) > 0 {
 			wheresMode = append(wheresMode, where)
 		}
 
 		// Add the predicted filter into the where clause if it was included in the filter set
-		for _, pf := range filters.predictedFilters {
-			wheresMode, params, err = addPredictedFilterToWhere(wheresMode, params, pf, variable)
+		for _, pf := range filters.Predicted {
+			wheresMode, params, err = s.builder.PredictedWhere(wheresMode, params, "predicted", pf)
 			if err != nil {
 				return nil, errors.Wrap(err, "Could not add result to where clause")
 			}
 		}
 
 		// Add the correctness filter into the where clause if it was included in the filter set
-		for _, cf := range filters.correctnessFilters {
-			wheresMode, params, err = addCorrectnessFilterToWhere(wheresMode, params, cf, variable)
+		for _, cf := range filters.Correctness {
+			wheresMode, params, err = s.builder.CorrectnessWhere(wheresMode, params, "predicted", variable.Key, cf)
 			if err != nil {
 				return nil, errors.Wrap(err, "Could not add result to where clause")
 			}
 		}
 
 		// Add the error filter into the where clause if it was included in the filter set
-		for _, rf := range filters.residualFilters {
-			wheresMode, params, err = addErrorFilterToWhere(wheresMode, params, dataTableAlias, targetName, rf)
-			if err != nil {
-				return nil, errors.Wrap(err, "Could not add error to where clause")
-			}
+		for _, rf := range filters.Residual {
+			wheresMode, params = s.builder.ErrorWhere(wheresMode, params, dataTableAlias, targetName, rf)
 		}
 
 		// Add the error filter into the where clause if it was included in the filter set
 		// note that all special result filters CANNOT be exclusions
-		for _, cf := range filters.confidenceFilters {
-			wheresMode, params = s.buildConfidenceResultWhere(wheresMode, params, cf, "predicted")
+		for _, cf := range filters.Confidence {
+			wheresMode, params = s.builder.ConfidenceWhere(wheresMode, params, cf, "predicted")
 		}
-		for _, rf := range filters.rankFilters {
-			wheresMode, params = s.buildRankResultWhere(wheresMode, params, rf, "predicted")
+		for _, rf := range filters.Rank {
+			wheresMode, params = s.builder.RankWhere(wheresMode, params, rf, "predicted")
 		}
 
 		if len(wheresMode) > 0 {
-			wheres = append(wheres, combineClauses(filterSet.Mode, wheresMode, "AND"))
+			wheres = append(wheres, sqlbuilder.CombineClauses(filterSet.Mode, wheresMode, "AND"))
 		}
 	}
+	wheres, params, err = s.builder.FilterExpressionWhere(dataset, wheres, params, dataTableAlias, filterParams)
+	if err != nil {
+		return nil, err
+	}
 	// If this is a timeseries forecast we don't want to include the target, predicted target or error
 	// info in the returned data.  That information is fetched on a per-timeseries basis using the info
 	// provided by this call.
@@ -775,7 +669,7 @@ func (s *Storage) FetchResults(dataset string, storageName string, resultURI str
 		errorCol := api.GetErrorKey(id)
 		errorExpr := ""
 		if model.IsNumerical(variable.Type) && !predictionResultMode {
-			errorExpr = fmt.Sprintf("%s as \"%s\",", getErrorTyped(dataTableAlias, variable.Key), errorCol)
+			errorExpr = fmt.Sprintf("%s as \"%s\",", s.builder.ErrorTyped(dataTableAlias, variable.Key), errorCol)
 		}
 
 		targetColumnQuery := ""
@@ -787,8 +681,8 @@ func (s *Storage) FetchResults(dataset string, storageName string, resultURI str
 			distincts, predictedCol, targetColumnQuery, errorExpr, strings.Join(fieldsData, ", "), strings.Join(fieldsExplain, ", "))
 	}
 
-	wheres = append(wheres, fmt.Sprintf("predicted.result_id = $%d", len(params)+1))
-	wheres = append(wheres, fmt.Sprintf("predicted.target = $%d", len(params)+2))
+	wheres = append(wheres, fmt.Sprintf("predicted.result_id = %s", s.builder.Param(len(params)+1)))
+	wheres = append(wheres, fmt.Sprintf("predicted.target = %s", s.builder.Param(len(params)+2)))
 	wheres = append(wheres, "predicted.value != ''")
 	params = append(params, resultURI)
 	params = append(params, targetName)
@@ -0,0 +1,125 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+// FetchSpatialJoin joins the rows of two datasets on their geometries, returning the
+// requested columns of the left rows followed by those of their matching right rows.
+func (s *Storage) FetchSpatialJoin(join *api.SpatialJoin) ([][]string, error) {
+	sql, params, err := buildSpatialJoinQuery(join)
+	if err != nil {
+		return nil, err
+	}
+	fields := append(getSpatialJoinFields("l", join.Left), getSpatialJoinFields("r", join.Right)...)
+
+	rows, err := s.client.Query(sql, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to run spatial join")
+	}
+	defer rows.Close()
+
+	output := [][]string{}
+	for rows.Next() {
+		values := make([]*string, len(fields))
+		pointers := make([]interface{}, len(fields))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		err = rows.Scan(pointers...)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to parse spatial join row")
+		}
+
+		row := make([]string, len(fields))
+		for i, v := range values {
+			if v != nil {
+				row[i] = *v
+			}
+		}
+		output = append(output, row)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading spatial join rows")
+	}
+
+	return output, nil
+}
+
+// buildSpatialJoinQuery builds the query matching every left row to at most one
+// right row. The lateral join keeps the nearest right row satisfying the predicate,
+// breaking ties on the right d3m index so that repeated joins return the same match.
+func buildSpatialJoinQuery(join *api.SpatialJoin) (string, []interface{}, error) {
+	left := fmt.Sprintf("l.\"%s\"", join.Left.GeometryCol)
+	right := fmt.Sprintf("rt.\"%s\"", join.Right.GeometryCol)
+
+	params := []interface{}{}
+	var predicate string
+	switch join.Predicate {
+	case api.SpatialJoinIntersects:
+		predicate = fmt.Sprintf("ST_INTERSECTS(%s, %s)", left, right)
+	case api.SpatialJoinContains:
+		predicate = fmt.Sprintf("ST_CONTAINS(%s, %s)", left, right)
+	case api.SpatialJoinNearest:
+		// geometries are stored as lon / lat so distances need to be computed on the geography
+		params = append(params, join.Distance)
+		predicate = fmt.Sprintf("ST_DWITHIN(ST_SETSRID(%s, 4326)::geography, ST_SETSRID(%s, 4326)::geography, $1)", left, right)
+	default:
+		return "", nil, errors.Errorf("unsupported spatial join predicate '%s'", join.Predicate)
+	}
+
+	fields := append(getSpatialJoinFields("l", join.Left), getSpatialJoinFields("r", join.Right)...)
+	joinType := "LEFT"
+	if join.Inner {
+		joinType = "INNER"
+	}
+
+	sql := fmt.Sprintf(`
+		SELECT %s
+		FROM %s AS l
+		%s JOIN LATERAL (
+			SELECT * FROM %s AS rt
+			WHERE %s
+			ORDER BY %s <-> %s, rt."%s"
+			LIMIT 1
+		) AS r ON TRUE
+		ORDER BY l."%s";`,
+		strings.Join(fields, ", "), getBaseTableName(join.Left.StorageName), joinType,
+		getBaseTableName(join.Right.StorageName), predicate, left, right, model.D3MIndexFieldName, model.D3MIndexFieldName)
+
+	return sql, params, nil
+}
+
+func getSpatialJoinFields(alias string, side *api.SpatialJoinSide) []string {
+	fields := make([]string, len(side.Columns))
+	for i, c := range side.Columns {
+		if c == side.GeometryCol {
+			fields[i] = fmt.Sprintf("ST_ASTEXT(%s.\"%s\")", alias, c)
+		} else {
+			fields[i] = fmt.Sprintf("%s.\"%s\"::text", alias, c)
+		}
+	}
+	return fields
+}
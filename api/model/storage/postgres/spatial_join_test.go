@@ -0,0 +1,70 @@
+//
+//    Copyright © 2021 Uncharted Software Inc.
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package postgres
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+func createTestSpatialJoin(predicate string) *api.SpatialJoin {
+	return &api.SpatialJoin{
+		Predicate: predicate,
+		Distance:  500,
+		Left: &api.SpatialJoinSide{
+			StorageName: "regions",
+			GeometryCol: "__geo_bounds",
+			Columns:     []string{"d3mIndex", "name", "__geo_bounds"},
+		},
+		Right: &api.SpatialJoinSide{
+			StorageName: "sites",
+			GeometryCol: "__geo_location",
+			Columns:     []string{"site"},
+		},
+	}
+}
+
+func TestBuildSpatialJoinQuery(t *testing.T) {
+	sql, params, err := buildSpatialJoinQuery(createTestSpatialJoin(api.SpatialJoinIntersects))
+	assert.NoError(t, err)
+	assert.Empty(t, params)
+	assert.Contains(t, sql, `ST_INTERSECTS(l."__geo_bounds", rt."__geo_location")`)
+	assert.Contains(t, sql, "LEFT JOIN LATERAL")
+	assert.Contains(t, sql, `ST_ASTEXT(l."__geo_bounds")`)
+	assert.Contains(t, sql, `r."site"::text`)
+
+	// matches at the same distance are resolved on the right d3m index
+	assert.Contains(t, sql, `ORDER BY l."__geo_bounds" <-> rt."__geo_location", rt."d3mIndex"`)
+	assert.Equal(t, 1, strings.Count(sql, "LIMIT 1"))
+
+	join := createTestSpatialJoin(api.SpatialJoinNearest)
+	join.Inner = true
+	sql, params, err = buildSpatialJoinQuery(join)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{500.0}, params)
+	assert.Contains(t, sql, "ST_DWITHIN(")
+	assert.Contains(t, sql, "INNER JOIN LATERAL")
+
+	sql, _, err = buildSpatialJoinQuery(createTestSpatialJoin(api.SpatialJoinContains))
+	assert.NoError(t, err)
+	assert.Contains(t, sql, `ST_CONTAINS(l."__geo_bounds", rt."__geo_location")`)
+
+	_, _, err = buildSpatialJoinQuery(createTestSpatialJoin("touches"))
+	assert.Error(t, err)
+}
@@ -23,6 +23,7 @@ import (
 	log "github.com/unchartedsoftware/plog"
 
 	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/model/storage/sqlbuilder"
 	"github.com/uncharted-distil/distil/api/postgres"
 )
 
@@ -36,6 +37,7 @@ type Storage struct {
 	client      postgres.DatabaseDriver
 	batchClient postgres.DatabaseDriver
 	metadata    api.MetadataStorage
+	builder     *sqlbuilder.Builder
 }
 
 // NewDataStorage returns a constructor for a data storage.
@@ -75,6 +77,7 @@ func newStorage(clientCtor postgres.ClientCtor, batchClientCtor postgres.ClientC
 		client:      client,
 		batchClient: batchClient,
 		metadata:    metadata,
+		builder:     sqlbuilder.NewBuilder(sqlbuilder.Postgres, metadata),
 	}, nil
 }
 
@@ -24,6 +24,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/uncharted-distil/distil-compute/model"
 	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/model/storage/sqlbuilder"
 	postgres "github.com/uncharted-distil/distil/api/postgres"
 )
 
@@ -93,7 +94,10 @@ func (f *TextField) fetchHistogram(filterParams *api.FilterParams) (*api.Histogr
 	// create the filter for the query.
 	wheres := make([]string, 0)
 	params := make([]interface{}, 0)
-	wheres, params = f.Storage.buildFilteredQueryWhere(f.GetDatasetName(), wheres, params, "", filterParams)
+	wheres, params, err := f.Storage.builder.FilteredQueryWhere(f.GetDatasetName(), wheres, params, "", filterParams)
+	if err != nil {
+		return nil, err
+	}
 
 	where := ""
 	if len(wheres) > 0 {
@@ -110,7 +114,7 @@ func (f *TextField) fetchHistogram(filterParams *api.FilterParams) (*api.Histogr
 		"FROM (SELECT unnest(tsvector_to_array(to_tsvector(\"%s\"))) as stem %s FROM %s %s) as r "+
 		"LEFT OUTER JOIN %s as w on r.stem = w.stem "+
 		"GROUP BY COALESCE(w.word, r.stem) ORDER BY count desc, COALESCE(w.word, r.stem) LIMIT %d;",
-		f.Key, getCountSQL(f.Count), f.Key, countSubselect, f.DatasetStorageName, where, postgres.WordStemTableName, catResultLimit)
+		f.Key, sqlbuilder.CountSQL(f.Count), f.Key, countSubselect, f.DatasetStorageName, where, postgres.WordStemTableName, catResultLimit)
 
 	// execute the postgres query
 	res, err := f.Storage.client.Query(query, params...)
@@ -150,7 +154,7 @@ func (f *TextField) fetchHistogramByResult(resultURI string, filterParams *api.F
 		"FROM %s data INNER JOIN %s result ON data.\"%s\" = result.index WHERE result.result_id = $%d %s) as r "+
 		"LEFT OUTER JOIN %s as w on r.stem = w.stem "+
 		"GROUP BY COALESCE(w.word, r.stem) ORDER BY count desc, COALESCE(w.word, r.stem) LIMIT %d;",
-		f.Key, getCountSQL(f.Count), f.Key, countSubselect, f.DatasetStorageName, f.Storage.getResultTable(f.DatasetStorageName),
+		f.Key, sqlbuilder.CountSQL(f.Count), f.Key, countSubselect, f.DatasetStorageName, f.Storage.getResultTable(f.DatasetStorageName),
 		model.D3MIndexFieldName, len(params), where, postgres.WordStemTableName, catResultLimit)
 
 	// execute the postgres query
@@ -258,7 +262,7 @@ func (f *TextField) fetchPredictedSummaryData(resultURI string, datasetResult st
 		"FROM %s AS result INNER JOIN %s AS base ON result.index = base.\"d3mIndex\" "+
 		"WHERE %s) r LEFT OUTER JOIN %s word_b ON r.stem_b = word_b.stem LEFT OUTER JOIN %s word_v ON r.stem_v = word_v.stem "+
 		"GROUP BY COALESCE(word_v.word, r.stem_v), COALESCE(word_b.word, r.stem_b) "+
-		"ORDER BY count desc;", targetName, getCountSQL(f.Count), targetName, countSubselect, datasetResult,
+		"ORDER BY count desc;", targetName, sqlbuilder.CountSQL(f.Count), targetName, countSubselect, datasetResult,
 		f.DatasetStorageName, strings.Join(wheres, " AND "), postgres.WordStemTableName, postgres.WordStemTableName)
 
 	// execute the postgres query
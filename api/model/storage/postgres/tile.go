@@ -0,0 +1,79 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/model/storage/sqlbuilder"
+)
+
+// FetchTileImages returns the images whose bounds intersect the supplied bounds. When a
+// result URI is supplied the predicted value and confidence of each image is included.
+func (s *Storage) FetchTileImages(dataset string, storageName string, groupingCol string, coordinatesCol string, polygonCol string,
+	bounds *model.Bounds, resultURI string, limit int) ([]*api.TileImage, error) {
+	params := []interface{}{sqlbuilder.BoundsGeometry(bounds)}
+	fields := []string{
+		fmt.Sprintf("base.\"%s\"", groupingCol),
+		fmt.Sprintf("concat('{', base.\"%s\", '}')::double precision[]", coordinatesCol),
+	}
+	join := ""
+	if resultURI != "" {
+		params = append(params, resultURI)
+		fields = append(fields, "COALESCE(result.value, '')", fmt.Sprintf("(result.%s ->> 'confidence')::double precision", model.ExplainValues))
+		join = fmt.Sprintf("LEFT JOIN %s AS result ON CAST(base.\"%s\" AS double precision) = result.index AND result.result_id = $%d",
+			s.getResultTable(storageName), model.D3MIndexFieldName, len(params))
+	}
+
+	// intersects rather than within so that images crossing the tile edges are included
+	sql := fmt.Sprintf(`
+		SELECT DISTINCT ON (base."%s") %s
+		FROM %s AS base %s
+		WHERE ST_INTERSECTS(base."%s", $1)
+		ORDER BY base."%s"
+		LIMIT %d;`,
+		groupingCol, strings.Join(fields, ", "), getBaseTableName(storageName), join, polygonCol, groupingCol, limit)
+	rows, err := s.client.Query(sql, params...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to query tile images")
+	}
+	defer rows.Close()
+
+	images := []*api.TileImage{}
+	for rows.Next() {
+		image := &api.TileImage{}
+		if resultURI != "" {
+			err = rows.Scan(&image.GroupID, &image.Coordinates, &image.Prediction, &image.Confidence)
+		} else {
+			err = rows.Scan(&image.GroupID, &image.Coordinates)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse tile image")
+		}
+		images = append(images, image)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading tile images")
+	}
+
+	return images, nil
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/uncharted-distil/distil-compute/model"
 	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/model/storage/sqlbuilder"
 	log "github.com/unchartedsoftware/plog"
 )
 
@@ -411,7 +412,10 @@ func (s *Storage) FetchTimeseries(dataset string, storageName string, variableKe
 		wheres = append(wheres, fmt.Sprintf("\"%s\" = ANY(ARRAY[%s]::text[])", seriesIDColName, paramString))
 	}
 
-	wheres, params = s.buildFilteredQueryWhere(dataset, wheres, params, "", filterParams)
+	wheres, params, err := s.builder.FilteredQueryWhere(dataset, wheres, params, "", filterParams)
+	if err != nil {
+		return nil, err
+	}
 	where := ""
 	if len(wheres) > 0 {
 		where = fmt.Sprintf("WHERE %s", strings.Join(wheres, " AND "))
@@ -493,7 +497,10 @@ func (s *Storage) FetchTimeseriesForecast(dataset string, storageName string, va
 	paramString = paramString[:len(paramString)-1]
 	wheres = append(wheres, fmt.Sprintf("\"%s\" = ANY(ARRAY[%s]::text[])", seriesIDColName, paramString))
 
-	wheres, params = s.buildFilteredQueryWhere(dataset, wheres, params, "", filterParams)
+	wheres, params, err := s.builder.FilteredQueryWhere(dataset, wheres, params, "", filterParams)
+	if err != nil {
+		return nil, err
+	}
 
 	params = append(params, resultURI)
 	wheres = append(wheres, fmt.Sprintf("result.result_id = $%d", len(params)))
@@ -594,34 +601,35 @@ func (f *TimeSeriesField) FetchSummaryData(resultURI string, filterParams *api.F
 	}
 
 	genericfilters := &api.FilterParams{
-		Size:      filterParams.Size,
-		Variables: filterParams.Variables,
-		DataMode:  filterParams.DataMode,
-		Filters:   []*model.FilterSet{},
+		Size:       filterParams.Size,
+		Variables:  filterParams.Variables,
+		DataMode:   filterParams.DataMode,
+		Filters:    []*model.FilterSet{},
+		Expression: filterParams.Expression,
 	}
 	joins := make([]*joinDefinition, 0)
 	wheres := []string{}
 	params := []interface{}{}
 	for _, filterSet := range filterParams.Filters {
 		// split the filters to make sure the result based filters can be applied properly
-		filtersSplit, err := splitFilters(filterSet)
+		filtersSplit, err := sqlbuilder.SplitFilters(filterSet)
 		if err != nil {
 			return nil, err
 		}
 
-		if len(filtersSplit.residualFilters) > 0 {
+		if len(filtersSplit.Residual) > 0 {
 			clauses := []string{}
-			for _, residualFilter := range filtersSplit.residualFilters {
+			for _, residualFilter := range filtersSplit.Residual {
 				wheres, params, err = f.Storage.buildErrorResultWhere(wheres, params, residualFilter)
 				if err != nil {
 					return nil, err
 				}
 			}
-			wheres = append(wheres, combineClauses(filterSet.Mode, clauses, "AND"))
+			wheres = append(wheres, sqlbuilder.CombineClauses(filterSet.Mode, clauses, "AND"))
 		}
 
 		// combine the generic filters together into a single one
-		genericfilters.MergeFilterObjects(filtersSplit.genericFilters)
+		genericfilters.MergeFilterObjects(filtersSplit.Generic)
 	}
 	if len(wheres) > 0 {
 		joins = append(joins, &joinDefinition{
@@ -689,7 +697,10 @@ func (f *TimeSeriesField) fetchHistogram(filterParams *api.FilterParams, mode ap
 	// create the filter for the query.
 	wheres := make([]string, 0)
 	params := make([]interface{}, 0)
-	wheres, params = f.Storage.buildFilteredQueryWhere(f.GetDatasetName(), wheres, params, "", filterParams)
+	wheres, params, err := f.Storage.builder.FilteredQueryWhere(f.GetDatasetName(), wheres, params, "", filterParams)
+	if err != nil {
+		return nil, err
+	}
 
 	where := ""
 	if len(wheres) > 0 {
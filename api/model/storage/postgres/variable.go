@@ -149,7 +149,7 @@ func (s *Storage) fetchSummaryData(dataset string, storageName string, varName s
 
 	// if there are no filters, and we are returning the exclude set, we expect
 	// no results in the filtered set
-	if filterParams.Invert && filterParams.Filters == nil {
+	if filterParams.Invert && filterParams.Filters == nil && filterParams.Expression == nil {
 		summary.EmptyFilteredHistogram()
 	}
 
@@ -16,7 +16,6 @@
 package postgres
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -108,10 +107,5 @@ func (f *VectorField) isNumerical() bool {
 }
 
 func (f *VectorField) subSelect() string {
-	countSQL := ""
-	if f.Count != "" {
-		countSQL = fmt.Sprintf(", \"%s\"", f.Count)
-	}
-	return fmt.Sprintf("(SELECT \"%s\"%s, unnest(\"%s\") as \"%s\" FROM %s)",
-		model.D3MIndexFieldName, countSQL, f.Unnested, f.Key, f.DatasetStorageName)
+	return f.Storage.builder.Dialect.Unnest(f.DatasetStorageName, f.Unnested, f.Key, f.Count, f.isNumerical())
 }
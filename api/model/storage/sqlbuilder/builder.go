@@ -0,0 +1,110 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/uncharted-distil/distil-compute/model"
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+// Builder builds the statements shared by the sql storages, spelling them
+// for the dialect of the storage.
+type Builder struct {
+	Dialect  Dialect
+	metadata api.MetadataStorage
+}
+
+// NewBuilder creates a statement builder for a dialect. The metadata storage
+// is used to resolve the variables referenced by filters.
+func NewBuilder(dialect Dialect, metadata api.MetadataStorage) *Builder {
+	return &Builder{
+		Dialect:  dialect,
+		metadata: metadata,
+	}
+}
+
+// Param returns the placeholder of the parameter at the 1 based index.
+func (b *Builder) Param(index int) string {
+	return b.Dialect.Param(index)
+}
+
+// FullName returns the quoted column name, prefixed by the table alias if any.
+func FullName(alias string, column string) string {
+	fullName := fmt.Sprintf("\"%s\"", column)
+	if alias != "" {
+		fullName = fmt.Sprintf("%s.%s", alias, fullName)
+	}
+
+	return fullName
+}
+
+// FormatFilterKey returns the column a filter on the key applies to.
+func FormatFilterKey(alias string, key string) string {
+	if api.IsResultKey(key) {
+		return "result.value"
+	}
+	return FullName(alias, key)
+}
+
+// FeatureVarName returns the name of the cluster column of a variable.
+func FeatureVarName(varName string) string {
+	return fmt.Sprintf("%s%s", model.ClusterVarPrefix, varName)
+}
+
+// CountSQL returns the count expression of a histogram, counting the
+// distinct values of the count column if there is one.
+func CountSQL(count string) string {
+	if count == "" {
+		return "*"
+	}
+	return fmt.Sprintf("DISTINCT \"%s\"", count)
+}
+
+// ResultTable returns the name of the table holding the results of a dataset.
+func ResultTable(storageName string) string {
+	return fmt.Sprintf("%s_result", storageName)
+}
+
+// ResultJoin returns the FROM clause joining the results under the alias to
+// the base data.
+func ResultJoin(alias string, storageName string) string {
+	return fmt.Sprintf("%s AS %s INNER JOIN %s AS data ON data.\"%s\" = %s.\"index\"",
+		ResultTable(storageName), alias, storageName, model.D3MIndexFieldName, alias)
+}
+
+// ErrorTyped returns the residual of the result value against the target.
+func (b *Builder) ErrorTyped(alias string, variableName string) string {
+	return fmt.Sprintf("(%s - %s)", b.Dialect.Numeric("value"), b.Dialect.Real(FullName(alias, variableName)))
+}
+
+// BoundsGeometry returns the well known text of the polygon spanning the bounds.
+func BoundsGeometry(bounds *model.Bounds) string {
+	coords := []string{
+		pointToString(bounds.MinX, bounds.MinY, " "),
+		pointToString(bounds.MinX, bounds.MaxY, " "),
+		pointToString(bounds.MaxX, bounds.MaxY, " "),
+		pointToString(bounds.MaxX, bounds.MinY, " "),
+		pointToString(bounds.MinX, bounds.MinY, " "),
+	}
+	return fmt.Sprintf("POLYGON((%s))", strings.Join(coords, ","))
+}
+
+func pointToString(x float64, y float64, separator string) string {
+	return fmt.Sprintf("%f%s%f", x, separator, y)
+}
@@ -0,0 +1,328 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlbuilder
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+
+	"github.com/uncharted-distil/distil/api/postgres"
+	"github.com/uncharted-distil/distil/api/sqlite"
+)
+
+const (
+	// randomOrderMultiplier and randomOrderModulus define the multiplicative
+	// hash used to shuffle rows when the database has no seedable random function.
+	randomOrderMultiplier = 2654435761
+	randomOrderModulus    = 4294967291
+)
+
+var (
+	randomSeed = 0.2
+
+	// ErrUnsupportedFilter is returned when a filter cannot be expressed in
+	// the dialect of the storage.
+	ErrUnsupportedFilter = errors.New("unsupported filter")
+
+	// Postgres is the dialect of the postgres storage.
+	Postgres Dialect = &postgresDialect{}
+
+	// SQLite is the dialect of the embedded sqlite storage.
+	SQLite Dialect = &sqliteDialect{}
+)
+
+// SetRandomSeed sets the random seed to use when reading a subset of data from the database.
+func SetRandomSeed(seed float64) {
+	randomSeed = seed
+}
+
+// Dialect spells the parts of a statement that differ between databases.
+// Parameters are referenced by their placeholder as returned by Param.
+type Dialect interface {
+	// Name identifies the database in error messages.
+	Name() string
+	// Param returns the placeholder of the parameter at the 1 based index.
+	Param(index int) string
+	// Real casts an expression to a floating point number.
+	Real(expr string) string
+	// Numeric converts a text expression, such as a predicted value, to a
+	// floating point number.
+	Numeric(expr string) string
+	// Epoch returns the seconds since the epoch of a date time expression.
+	Epoch(expr string) string
+	// DateEpoch returns the seconds since the epoch of the date part of a
+	// date time expression.
+	DateEpoch(expr string) string
+	// Bucket returns the 0 based index of the histogram bucket spanning
+	// [min, max) holding the expression. Values below the range fall in
+	// bucket -1 and values at or above the max in an extra bucket.
+	Bucket(expr string, min float64, max float64, bucketCount int) string
+	// VectorContains matches vectors holding both parameters.
+	VectorContains(name string, first string, second string, numerical bool) string
+	// ArrayElement returns the element at the 1 based index of an array.
+	ArrayElement(name string, index int) string
+	// TextMatch matches an expression against a case insensitive pattern.
+	TextMatch(name string, param string) string
+	// TextPattern returns the parameter value of a text match pattern.
+	TextPattern(pattern string) interface{}
+	// IndexValue returns the parameter value of a d3m index.
+	IndexValue(d3mIndex string) interface{}
+	// JSONNumber extracts a number from a json document.
+	JSONNumber(document string, key string) string
+	// Within matches the geometries within the bounds parameter.
+	Within(name string, param string) (string, error)
+	// DefaultValue returns the value the typed views use for missing entries.
+	DefaultValue(typ string) string
+	// MissingValue matches the missing entries of a column.
+	MissingValue(typ string, name string) string
+	// Unnest selects one row for every element of the vector column, along
+	// with the index and count columns of the table.
+	Unnest(table string, column string, alias string, countColumn string, numerical bool) string
+	// RandomOrder returns the expression shuffling rows for the current seed.
+	RandomOrder(field string) string
+	// SeedRandom returns the statement seeding RandomOrder, if the database
+	// needs one.
+	SeedRandom() string
+}
+
+type postgresDialect struct{}
+
+func (d *postgresDialect) Name() string {
+	return "postgres"
+}
+
+func (d *postgresDialect) Param(index int) string {
+	return fmt.Sprintf("$%d", index)
+}
+
+func (d *postgresDialect) Real(expr string) string {
+	return fmt.Sprintf("cast(%s as double precision)", expr)
+}
+
+func (d *postgresDialect) Numeric(expr string) string {
+	return fmt.Sprintf("CAST(CASE WHEN %s = '' THEN 'NaN' ELSE %s END as double precision)", expr, expr)
+}
+
+func (d *postgresDialect) Epoch(expr string) string {
+	return fmt.Sprintf("cast(extract(epoch from %s) as double precision)", expr)
+}
+
+func (d *postgresDialect) DateEpoch(expr string) string {
+	return fmt.Sprintf("cast(extract(epoch from %s::date) as double precision)", expr)
+}
+
+func (d *postgresDialect) Bucket(expr string, min float64, max float64, bucketCount int) string {
+	// if only a single value, then return a simple count.
+	if min == max {
+		// specify a cast because the group by clause sees 0 as not a number
+		return "cast(0 as integer)"
+	}
+	return fmt.Sprintf("width_bucket(%s, %g, %g, %d) - 1", expr, min, max, bucketCount)
+}
+
+func (d *postgresDialect) VectorContains(name string, first string, second string, numerical bool) string {
+	// cast to a typed array in case of string based representation
+	elementType := "text"
+	if numerical {
+		elementType = "double precision"
+	}
+	return fmt.Sprintf("%s @> CAST(ARRAY[%s, %s] AS %s[])", name, first, second, elementType)
+}
+
+func (d *postgresDialect) ArrayElement(name string, index int) string {
+	return fmt.Sprintf("%s[%d]", name, index)
+}
+
+func (d *postgresDialect) TextMatch(name string, param string) string {
+	return fmt.Sprintf("%s ~* (%s)", name, param)
+}
+
+func (d *postgresDialect) TextPattern(pattern string) interface{} {
+	return pattern
+}
+
+func (d *postgresDialect) IndexValue(d3mIndex string) interface{} {
+	return d3mIndex
+}
+
+func (d *postgresDialect) JSONNumber(document string, key string) string {
+	return fmt.Sprintf("(%s ->> '%s')::double precision", document, key)
+}
+
+func (d *postgresDialect) Within(name string, param string) (string, error) {
+	return fmt.Sprintf("ST_WITHIN(%s, %s)", name, param), nil
+}
+
+func (d *postgresDialect) DefaultValue(typ string) string {
+	return fmt.Sprintf("%v", postgres.DefaultPostgresValueFromD3MType(typ))
+}
+
+// MissingValue matches the values the typed view uses for missing entries
+// (see postgres.DefaultPostgresValueFromD3MType).
+func (d *postgresDialect) MissingValue(typ string, name string) string {
+	switch typ {
+	case model.IndexType:
+		return fmt.Sprintf("%s IS NULL", name)
+	case model.LongitudeType, model.LatitudeType, model.RealType, model.GeoCoordinateType,
+		model.IntegerType, model.TimestampType:
+		return fmt.Sprintf("(%s IS NULL OR %s = 'NaN'::double precision)", name, name)
+	case model.DateTimeType:
+		return fmt.Sprintf("%s IS NULL", name)
+	case model.GeoBoundsType:
+		return fmt.Sprintf("(%s IS NULL OR ST_IsEmpty(%s))", name, name)
+	case model.RealVectorType, model.RealListType:
+		return fmt.Sprintf("(%s IS NULL OR cardinality(%s) = 0)", name, name)
+	default:
+		return fmt.Sprintf("(%s IS NULL OR %s = '')", name, name)
+	}
+}
+
+func (d *postgresDialect) Unnest(table string, column string, alias string, countColumn string, numerical bool) string {
+	countSQL := ""
+	if countColumn != "" {
+		countSQL = fmt.Sprintf(", \"%s\"", countColumn)
+	}
+	return fmt.Sprintf("(SELECT \"%s\"%s, unnest(\"%s\") AS \"%s\" FROM %s)",
+		model.D3MIndexFieldName, countSQL, column, alias, table)
+}
+
+func (d *postgresDialect) RandomOrder(field string) string {
+	return "random()"
+}
+
+func (d *postgresDialect) SeedRandom() string {
+	return fmt.Sprintf("SELECT setseed(%v);", randomSeed)
+}
+
+type sqliteDialect struct{}
+
+func (d *sqliteDialect) Name() string {
+	return "sqlite"
+}
+
+func (d *sqliteDialect) Param(index int) string {
+	return fmt.Sprintf("?%d", index)
+}
+
+func (d *sqliteDialect) Real(expr string) string {
+	return fmt.Sprintf("CAST(%s AS REAL)", expr)
+}
+
+// Numeric gives null for the values that are not numeric.
+func (d *sqliteDialect) Numeric(expr string) string {
+	return fmt.Sprintf("(CASE WHEN is_numeric(%s) THEN CAST(%s AS REAL) END)", expr, expr)
+}
+
+func (d *sqliteDialect) Epoch(expr string) string {
+	return fmt.Sprintf("CAST(strftime('%%s', %s) AS REAL)", expr)
+}
+
+func (d *sqliteDialect) DateEpoch(expr string) string {
+	return fmt.Sprintf("CAST(strftime('%%s', date(%s)) AS REAL)", expr)
+}
+
+// Bucket matches the postgres width_bucket function.
+func (d *sqliteDialect) Bucket(expr string, min float64, max float64, bucketCount int) string {
+	// if only a single value, then return a simple count.
+	if min == max {
+		return "0"
+	}
+	return fmt.Sprintf("max(-1, min(%d, CAST(floor((%s - %g) * %d / (%g - %g)) AS INTEGER)))",
+		bucketCount, expr, min, bucketCount, max, min)
+}
+
+// VectorContains expands the comma separated values the vectors are stored as.
+func (d *sqliteDialect) VectorContains(name string, first string, second string, numerical bool) string {
+	elements := fmt.Sprintf("json_each('[' || %s || ']')", name)
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM %s WHERE value = %s) AND EXISTS (SELECT 1 FROM %s WHERE value = %s)",
+		elements, first, elements, second)
+}
+
+func (d *sqliteDialect) ArrayElement(name string, index int) string {
+	return fmt.Sprintf("json_extract('[' || TRIM(%s, '{}[]') || ']', '$[%d]')", name, index-1)
+}
+
+// TextMatch relies on the regexp function registered by the sqlite client.
+func (d *sqliteDialect) TextMatch(name string, param string) string {
+	return fmt.Sprintf("%s REGEXP %s", name, param)
+}
+
+// TextPattern makes the match case insensitive like the postgres ~* operator.
+func (d *sqliteDialect) TextPattern(pattern string) interface{} {
+	return "(?i)" + pattern
+}
+
+// IndexValue converts the index to an integer so that it compares properly
+// with the integer index column.
+func (d *sqliteDialect) IndexValue(d3mIndex string) interface{} {
+	parsed, err := strconv.ParseInt(d3mIndex, 10, 64)
+	if err != nil {
+		return d3mIndex
+	}
+	return parsed
+}
+
+func (d *sqliteDialect) JSONNumber(document string, key string) string {
+	return fmt.Sprintf("CAST(json_extract(%s, '$.%s') AS REAL)", document, key)
+}
+
+// Within is not available since sqlite has no spatial functions.
+func (d *sqliteDialect) Within(name string, param string) (string, error) {
+	return "", errors.Wrapf(ErrUnsupportedFilter, "geobounds filter on %s is not supported by sqlite", name)
+}
+
+func (d *sqliteDialect) DefaultValue(typ string) string {
+	return fmt.Sprintf("%v", sqlite.DefaultValueFromD3MType(typ))
+}
+
+// MissingValue matches the values the typed view uses for missing entries
+// (see sqlite.DefaultValueFromD3MType).
+func (d *sqliteDialect) MissingValue(typ string, name string) string {
+	if d.DefaultValue(typ) == "NULL" {
+		return fmt.Sprintf("%s IS NULL", name)
+	}
+	return fmt.Sprintf("(%s IS NULL OR %s = '')", name, name)
+}
+
+// Unnest expands the comma separated values the vectors are stored as, read
+// as json arrays.
+func (d *sqliteDialect) Unnest(table string, column string, alias string, countColumn string, numerical bool) string {
+	countSQL := ""
+	if countColumn != "" {
+		countSQL = fmt.Sprintf(", v.\"%s\"", countColumn)
+	}
+	value := "elements.value"
+	if !numerical {
+		value = "CAST(elements.value AS TEXT)"
+	}
+	elements := fmt.Sprintf("'[' || TRIM(v.\"%s\", '{}[]') || ']'", column)
+	return fmt.Sprintf("(SELECT v.\"%s\"%s, %s AS \"%s\" FROM %s AS v, json_each(%s) AS elements WHERE json_valid(%s))",
+		model.D3MIndexFieldName, countSQL, value, alias, table, elements, elements)
+}
+
+// RandomOrder uses a multiplicative hash of the field since sqlite has no
+// seedable random function.
+func (d *sqliteDialect) RandomOrder(field string) string {
+	offset := int64(randomSeed*randomOrderModulus) % randomOrderModulus
+	return fmt.Sprintf("((%s + %d) * %d) %% %d", field, offset, randomOrderMultiplier, randomOrderModulus)
+}
+
+func (d *sqliteDialect) SeedRandom() string {
+	return ""
+}
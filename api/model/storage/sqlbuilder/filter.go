@@ -0,0 +1,772 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+const (
+	// CorrectCategory identifies the correct result meta-category.
+	CorrectCategory = "correct"
+
+	// IncorrectCategory identifies the incorrect result meta-category.
+	IncorrectCategory = "incorrect"
+)
+
+// ResultColumns resolves the target columns result filters compare against.
+type ResultColumns interface {
+	// ResultTargetName returns the name of the target column of a result.
+	ResultTargetName(storageName string, resultURI string) (string, error)
+	// ErrorTargetName returns the name of the target column the residuals of
+	// an error key are computed against.
+	ErrorTargetName(errorKey string) (string, error)
+}
+
+// Filters holds the filters of a filter set, split by the kind of column
+// they apply to.
+type Filters struct {
+	Generic     []model.FilterObject
+	Predicted   []model.FilterObject
+	Residual    []model.FilterObject
+	Correctness []model.FilterObject
+	Confidence  []model.FilterObject
+	Rank        []model.FilterObject
+}
+
+func (b *Builder) buildIncludeFilter(dataset string, wheres []string, params []interface{}, alias string, filter *model.Filter) ([]string, []interface{}, error) {
+
+	name := FormatFilterKey(alias, filter.Key)
+
+	switch filter.Type {
+	case model.DatetimeFilter:
+		// datetime
+		// extract epoch for comparison
+		typed := b.Dialect.DateEpoch(name)
+		where := fmt.Sprintf("%s >= %s AND %s < %s", typed, b.Param(len(params)+1), typed, b.Param(len(params)+2))
+		wheres = append(wheres, where)
+		params = append(params, *filter.Min)
+		params = append(params, *filter.Max)
+
+	case model.NumericalFilter:
+		// numerical
+		// cast in case of string based representation
+		typed := b.Dialect.Real(name)
+		where := fmt.Sprintf("%s >= %s AND %s < %s", typed, b.Param(len(params)+1), typed, b.Param(len(params)+2))
+		wheres = append(wheres, where)
+		params = append(params, *filter.Min)
+		params = append(params, *filter.Max)
+
+	case model.VectorFilter:
+		// vector
+		where := b.Dialect.VectorContains(name, b.Param(len(params)+1), b.Param(len(params)+2), filter.NestedType == model.NumericalFilter)
+		wheres = append(wheres, where)
+		params = append(params, *filter.Min)
+		params = append(params, *filter.Max)
+
+	case model.BivariateFilter:
+		// bivariate
+		// cast in case of string based representation
+		fields, err := b.getBivariateFilterKeys(dataset, filter.Key, alias)
+		if err != nil {
+			return nil, nil, err
+		}
+		x := b.Dialect.Real(fields[0])
+		y := b.Dialect.Real(fields[1])
+		where := fmt.Sprintf("%s >= %s AND %s < %s AND %s >= %s AND %s < %s",
+			x, b.Param(len(params)+1), x, b.Param(len(params)+2), y, b.Param(len(params)+3), y, b.Param(len(params)+4))
+		wheres = append(wheres, where)
+		params = append(params, filter.Bounds.MinX)
+		params = append(params, filter.Bounds.MaxX)
+		params = append(params, filter.Bounds.MinY)
+		params = append(params, filter.Bounds.MaxY)
+
+	case model.CategoricalFilter:
+		// categorical
+		categories := make([]string, 0)
+		offset := len(params) + 1
+		for i, category := range filter.Categories {
+			categories = append(categories, b.Param(offset+i))
+			if category != "<none>" {
+				params = append(params, category)
+			} else {
+				params = append(params, "")
+			}
+		}
+		where := fmt.Sprintf("%s IN (%s)", name, strings.Join(categories, ", "))
+		wheres = append(wheres, where)
+
+	case model.GeoBoundsFilter:
+		// geo bounds
+		where, err := b.Dialect.Within(name, b.Param(len(params)+1))
+		if err != nil {
+			return nil, nil, err
+		}
+		params = append(params, BoundsGeometry(filter.Bounds))
+		wheres = append(wheres, where)
+
+	case model.ClusterFilter:
+		// cluster
+		name = FormatFilterKey(alias, FeatureVarName(filter.Key))
+		categories := make([]string, 0)
+		offset := len(params) + 1
+		for i, category := range filter.Categories {
+			categories = append(categories, b.Param(offset+i))
+			params = append(params, category)
+		}
+		where := fmt.Sprintf("%s IN (%s)", name, strings.Join(categories, ", "))
+		wheres = append(wheres, where)
+	case model.RowFilter:
+		// row
+		indices := make([]string, 0)
+		offset := len(params) + 1
+		for i, d3mIndex := range filter.D3mIndices {
+			indices = append(indices, b.Param(offset+i))
+			params = append(params, b.Dialect.IndexValue(d3mIndex))
+		}
+		where := fmt.Sprintf("%s IN (%s)", name, strings.Join(indices, ", "))
+		wheres = append(wheres, where)
+	case model.TextFilter:
+		// text
+		offset := len(params) + 1
+		for i, category := range filter.Categories {
+			where := b.Dialect.TextMatch(name, b.Param(offset+i))
+			params = append(params, b.Dialect.TextPattern(category))
+			wheres = append(wheres, where)
+		}
+	case api.MissingFilter:
+		// missing
+		where, err := b.buildMissingFilterWhere(dataset, alias, filter.Key)
+		if err != nil {
+			return nil, nil, err
+		}
+		wheres = append(wheres, where)
+	}
+	return wheres, params, nil
+}
+
+func (b *Builder) buildMissingFilterWhere(dataset string, alias string, key string) (string, error) {
+	variable, err := b.metadata.FetchVariable(dataset, key)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to fetch variable '%s' for missing filter", key)
+	}
+
+	// a coordinate is missing if either of its components is missing
+	if variable.IsGrouping() && model.IsGeoCoordinate(variable.Grouping.GetType()) {
+		cg := variable.Grouping.(*model.GeoCoordinateGrouping)
+		return fmt.Sprintf("(%s OR %s)",
+			b.Dialect.MissingValue(model.RealType, FormatFilterKey(alias, cg.XCol)),
+			b.Dialect.MissingValue(model.RealType, FormatFilterKey(alias, cg.YCol))), nil
+	}
+
+	return b.Dialect.MissingValue(variable.Type, FormatFilterKey(alias, variable.Key)), nil
+}
+
+func (b *Builder) getBivariateFilterKeys(dataset string, key string, alias string) ([]string, error) {
+
+	fields := make([]string, 2)
+
+	// assume the name is a grouping and get it
+	g, err := b.metadata.FetchVariable(dataset, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if model.IsGeoBounds(g.Type) {
+		// only checking top left for now
+		name := FormatFilterKey(alias, g.Key)
+		fields[0] = b.Dialect.ArrayElement(name, 1)
+		fields[1] = b.Dialect.ArrayElement(name, 2)
+		return fields, nil
+	}
+
+	if g.IsGrouping() && model.IsGeoCoordinate(g.Grouping.GetType()) {
+		cg := g.Grouping.(*model.GeoCoordinateGrouping)
+		fields[0] = FormatFilterKey(alias, cg.XCol)
+		fields[1] = FormatFilterKey(alias, cg.YCol)
+		return fields, nil
+	}
+
+	return nil, errors.Errorf("unsupported field type %s for bivariate filter", g.Type)
+}
+
+// FilteredQueryWhere adds the clauses of the filter and highlight sets, as
+// well as of the filter expression, to the where clauses.
+func (b *Builder) FilteredQueryWhere(dataset string, wheres []string, params []interface{}, alias string, filterParams *api.FilterParams) ([]string, []interface{}, error) {
+
+	if filterParams == nil {
+		return wheres, params, nil
+	}
+
+	// an expression is inverted along with the filter sets as a whole, so the
+	// sets are only negated one by one when there is no expression
+	invertSets := filterParams.Invert && filterParams.Expression == nil
+
+	// exclusion set is the complement of the equivalent inclusion set
+	// ie: the exclusion set can be defined as NOT(inclusion set)
+	filterWheres := []string{}
+	filtersInclusive := []string{}
+	filtersExclusive := []string{}
+	highlightsInclusive := []string{}
+	highlightsExclusive := []string{}
+	var err error
+	for _, set := range filterParams.Filters {
+		where := ""
+		where, params, err = b.SelectionFilter(dataset, params, alias, set.FeatureFilters)
+		if err != nil {
+			return nil, nil, err
+		}
+		if where == "" {
+			continue
+		}
+		if set.Mode == model.ExcludeFilter {
+			filtersExclusive = append(filtersExclusive, where)
+		} else {
+			filtersInclusive = append(filtersInclusive, where)
+		}
+	}
+	for _, set := range filterParams.Highlights {
+		where := ""
+		where, params, err = b.SelectionFilter(dataset, params, alias, set.FeatureFilters)
+		if err != nil {
+			return nil, nil, err
+		}
+		if where == "" {
+			continue
+		}
+		if set.Mode == model.ExcludeFilter {
+			highlightsExclusive = append(highlightsExclusive, where)
+		} else {
+			highlightsInclusive = append(highlightsInclusive, where)
+		}
+	}
+	// OR all the inclusive filter sets because the data can be in any of them
+	if len(filtersInclusive) > 0 {
+		where := fmt.Sprintf("(%s)", strings.Join(filtersInclusive, " OR "))
+		if invertSets {
+			where = fmt.Sprintf("NOT%s", where)
+		}
+		filterWheres = append(filterWheres, where)
+	}
+
+	// AND all the exclusive filter sets because the data should not be in any of them
+	if len(filtersExclusive) > 0 {
+		where := fmt.Sprintf("(NOT(%s))", strings.Join(filtersExclusive, " OR "))
+		if invertSets {
+			where = fmt.Sprintf("NOT%s", where)
+		}
+		filterWheres = append(filterWheres, where)
+	}
+	// OR all the inclusive filter sets because the data can be in any of them
+	if len(highlightsInclusive) > 0 {
+		where := fmt.Sprintf("(%s)", strings.Join(highlightsInclusive, " OR "))
+		if invertSets {
+			where = fmt.Sprintf("NOT%s", where)
+		}
+		filterWheres = append(filterWheres, where)
+	}
+	// AND all the exclusive filter sets because the data should not be in any of them
+	if len(highlightsExclusive) > 0 {
+		where := fmt.Sprintf("(NOT(%s))", strings.Join(highlightsExclusive, " OR "))
+		if invertSets {
+			where = fmt.Sprintf("NOT%s", where)
+		}
+		filterWheres = append(filterWheres, where)
+	}
+
+	filterWheres, params, err = b.FilterExpressionWhere(dataset, filterWheres, params, alias, filterParams)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return append(wheres, filterWheres...), params, nil
+}
+
+// FilterExpressionWhere adds the clause of the boolean filter expression,
+// if any, to the where clauses built from the filter sets. Inverting negates
+// the conjunction of the filter set clauses and the expression together.
+func (b *Builder) FilterExpressionWhere(dataset string, wheres []string, params []interface{}, alias string, filterParams *api.FilterParams) ([]string, []interface{}, error) {
+	if filterParams == nil || filterParams.Expression == nil {
+		return wheres, params, nil
+	}
+
+	where, params, err := b.buildFilterExpression(dataset, params, alias, filterParams.Expression)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(where) > 0 {
+		wheres = append(wheres, where)
+	}
+	if filterParams.Invert && len(wheres) > 0 {
+		wheres = []string{fmt.Sprintf("NOT(%s)", strings.Join(wheres, " AND "))}
+	}
+
+	return wheres, params, nil
+}
+
+// buildFilterExpression compiles a filter expression tree into a single
+// clause, returning an empty clause if the expression has no filters.
+func (b *Builder) buildFilterExpression(dataset string, params []interface{}, alias string, expression *api.FilterExpression) (string, []interface{}, error) {
+	negate := expression.Not
+	where := ""
+	var err error
+	if expression.IsLeaf() {
+		wheres := []string{}
+		wheres, params, err = b.buildIncludeFilter(dataset, wheres, params, alias, expression.Filter)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(wheres) == 0 {
+			return "", params, nil
+		}
+		where = fmt.Sprintf("(%s)", strings.Join(wheres, " OR "))
+		// exclude leaves match the rows the filter does not
+		if expression.Filter.Mode == model.ExcludeFilter {
+			negate = !negate
+		}
+	} else {
+		clauses := []string{}
+		for _, child := range expression.Children {
+			clause := ""
+			clause, params, err = b.buildFilterExpression(dataset, params, alias, child)
+			if err != nil {
+				return "", nil, err
+			}
+			if len(clause) > 0 {
+				clauses = append(clauses, clause)
+			}
+		}
+		if len(clauses) == 0 {
+			return "", params, nil
+		}
+		operation := " AND "
+		if expression.Operator == api.FilterExpressionOr {
+			operation = " OR "
+		}
+		where = fmt.Sprintf("(%s)", strings.Join(clauses, operation))
+	}
+
+	if negate {
+		where = fmt.Sprintf("NOT%s", where)
+	}
+	return where, params, nil
+}
+
+// SelectionFilter builds the clause of the filters of a filter set.
+func (b *Builder) SelectionFilter(dataset string, params []interface{}, alias string, filters []model.FilterObject) (string, []interface{}, error) {
+	// filters acting on the same feature are OR
+	// Filters acting on different features are AND
+	var filtersByFeature []string
+	var err error
+	for _, filtersFeature := range filters {
+		featureWheres := []string{}
+		for _, filter := range filtersFeature.List {
+			featureWheres, params, err = b.buildIncludeFilter(dataset, featureWheres, params, alias, filter)
+			if err != nil {
+				return "", nil, err
+			}
+		}
+		if len(featureWheres) > 0 {
+			where := ""
+			if filtersFeature.Invert {
+				where = fmt.Sprintf("NOT(%s)", strings.Join(featureWheres, " OR "))
+			} else {
+				where = fmt.Sprintf("(%s)", strings.Join(featureWheres, " OR "))
+			}
+			filtersByFeature = append(filtersByFeature, where)
+		}
+	}
+
+	// AND all the filters by feature
+	// we now have a series of (FEATURE 1 == X OR FEATURE 1 == Y) expressions
+	// we need to AND them together to end up with (FEATURE 1 FILTERS) AND (FEATURE 2 FILTERS) AND ...
+	return strings.Join(filtersByFeature, " AND "), params, nil
+}
+
+// CorrectnessWhere adds the clause comparing the predicted values of the
+// result alias to the target column.
+func (b *Builder) CorrectnessWhere(wheres []string, params []interface{}, resultAlias string, targetName string, resultFilter model.FilterObject) ([]string, []interface{}, error) {
+	// correct/incorrect are well known categories that require the predicted category to be compared
+	// to the target category
+	wheresFilter := []string{}
+	for _, f := range resultFilter.List {
+		for _, category := range f.Categories {
+			op := ""
+			if strings.EqualFold(category, CorrectCategory) {
+				op = "="
+			} else if strings.EqualFold(category, IncorrectCategory) {
+				op = "!="
+			}
+			if op == "" {
+				return nil, nil, errors.Errorf("Error correctness category is not within set [%s, %s]", IncorrectCategory, CorrectCategory)
+			}
+			wheresFilter = append(wheresFilter, fmt.Sprintf("(%s.value %s data.\"%s\")", resultAlias, op, targetName))
+		}
+	}
+
+	return append(wheres, fmt.Sprintf("(%s)", strings.Join(wheresFilter, " OR "))), params, nil
+}
+
+// ErrorWhere adds the clause filtering the residuals against the target column.
+func (b *Builder) ErrorWhere(wheres []string, params []interface{}, alias string, targetName string, residualFilter model.FilterObject) ([]string, []interface{}) {
+	typedError := b.ErrorTyped(alias, targetName)
+
+	wheresFilter := []string{}
+	for _, f := range residualFilter.List {
+		wheresFilter = append(wheresFilter, fmt.Sprintf("(%s >= %s AND %s <= %s)", typedError, b.Param(len(params)+1), typedError, b.Param(len(params)+2)))
+		params = append(params, *f.Min)
+		params = append(params, *f.Max)
+	}
+
+	// OR the clauses together
+	return append(wheres, fmt.Sprintf("(%s)", strings.Join(wheresFilter, " OR "))), params
+}
+
+// PredictedWhere adds the clause filtering the predicted values of the result
+// alias. The correctness categories are left to CorrectnessWhere.
+func (b *Builder) PredictedWhere(wheres []string, params []interface{}, resultAlias string, predictedFilter model.FilterObject) ([]string, []interface{}, error) {
+	value := fmt.Sprintf("%s.value", resultAlias)
+
+	wheresFilter := []string{}
+	for _, f := range predictedFilter.List {
+		where := ""
+		switch f.Type {
+		case model.NumericalFilter:
+			// numerical range-based filter
+			typedValue := b.Dialect.Numeric(value)
+			where = fmt.Sprintf("(%s >= %s AND %s <= %s)", typedValue, b.Param(len(params)+1), typedValue, b.Param(len(params)+2))
+			params = append(params, *f.Min)
+			params = append(params, *f.Max)
+
+		case model.BivariateFilter:
+			// hardcode [lat, lon] format for now
+			lon := b.Dialect.Real(b.Dialect.ArrayElement(value, 2))
+			lat := b.Dialect.Real(b.Dialect.ArrayElement(value, 1))
+			where = fmt.Sprintf("(%s >= %s AND %s <= %s AND %s >= %s AND %s <= %s)",
+				lon, b.Param(len(params)+1), lon, b.Param(len(params)+2), lat, b.Param(len(params)+3), lat, b.Param(len(params)+4))
+			params = append(params, f.Bounds.MinX)
+			params = append(params, f.Bounds.MaxX)
+			params = append(params, f.Bounds.MinY)
+			params = append(params, f.Bounds.MaxY)
+
+		case model.CategoricalFilter:
+			// categorical label based filter, skipping the correct/incorrect metafilters
+			categories := make([]string, 0)
+			for _, category := range f.Categories {
+				if !isCorrectnessCategory(category) {
+					params = append(params, category)
+					categories = append(categories, b.Param(len(params)))
+				}
+			}
+			if len(categories) >= 1 {
+				where = fmt.Sprintf("(%s IN (%s))", value, strings.Join(categories, ", "))
+			}
+
+		case model.RowFilter:
+			// row index based filter
+			indices := make([]string, 0)
+			for _, d3mIndex := range f.D3mIndices {
+				params = append(params, d3mIndex)
+				indices = append(indices, b.Param(len(params)))
+			}
+			if len(indices) >= 1 {
+				where = fmt.Sprintf("(%s IN (%s))", value, strings.Join(indices, ", "))
+			}
+
+		default:
+			return nil, nil, errors.Errorf("unexpected type %s for variable %s", f.Type, f.Key)
+		}
+
+		wheresFilter = append(wheresFilter, where)
+	}
+
+	// combine the different modes together into a single filter statement
+	return append(wheres, fmt.Sprintf("(%s)", strings.Join(wheresFilter, " OR "))), params, nil
+}
+
+func isCorrectnessCategory(categoryName string) bool {
+	return strings.EqualFold(CorrectCategory, categoryName) || strings.EqualFold(categoryName, IncorrectCategory)
+}
+
+// ExplainValue returns a numeric value of the explain values of a result.
+func (b *Builder) ExplainValue(alias string, name string) string {
+	if alias != "" {
+		alias = alias + "."
+	}
+	return b.Dialect.JSONNumber(fmt.Sprintf("%sexplain_values", alias), name)
+}
+
+func (b *Builder) buildExplainValueWhere(wheres []string, params []interface{}, filter model.FilterObject, alias string, name string) ([]string, []interface{}) {
+	value := b.ExplainValue(alias, name)
+
+	wheresFilter := []string{}
+	for _, f := range filter.List {
+		wheresFilter = append(wheresFilter, fmt.Sprintf("(%s >= %s AND %s <= %s)", value, b.Param(len(params)+1), value, b.Param(len(params)+2)))
+		params = append(params, *f.Min)
+		params = append(params, *f.Max)
+	}
+
+	// Append the clause
+	return append(wheres, fmt.Sprintf("(%s)", strings.Join(wheresFilter, " OR "))), params
+}
+
+// ConfidenceWhere adds the clause filtering the confidence of the results.
+func (b *Builder) ConfidenceWhere(wheres []string, params []interface{}, confidenceFilter model.FilterObject, alias string) ([]string, []interface{}) {
+	return b.buildExplainValueWhere(wheres, params, confidenceFilter, alias, "confidence")
+}
+
+// RankWhere adds the clause filtering the rank of the results.
+func (b *Builder) RankWhere(wheres []string, params []interface{}, rankFilter model.FilterObject, alias string) ([]string, []interface{}) {
+	return b.buildExplainValueWhere(wheres, params, rankFilter, alias, "rank")
+}
+
+func (b *Builder) buildPredictedResultWhere(dataset string, wheres []string, params []interface{}, alias string, resultFilter model.FilterObject) ([]string, []interface{}, error) {
+	// handle the general category case
+	filterParams := &api.FilterParams{
+		Filters: []*model.FilterSet{{
+			FeatureFilters: []model.FilterObject{resultFilter},
+			Mode:           resultFilter.List[0].Mode,
+		}},
+	}
+	return b.FilteredQueryWhere(dataset, wheres, params, alias, filterParams)
+}
+
+// ResultQueryFilters builds the where clauses of a query joining the results
+// to the base data, handling the filters on the result columns.
+func (b *Builder) ResultQueryFilters(columns ResultColumns, dataset string, storageName string, resultURI string, filterParams *api.FilterParams, alias string) ([]string, []interface{}, error) {
+	params := make([]interface{}, 0)
+	wheresCombined := []string{}
+	if filterParams == nil {
+		return wheresCombined, params, nil
+	}
+
+	filtersComplete := append(filterParams.Highlights, filterParams.Filters...)
+
+	for _, filterSet := range filtersComplete {
+		// pull filters generated against the result facet out for special handling
+		filters, err := SplitFilters(filterSet)
+		if err != nil {
+			return nil, nil, err
+		}
+		// create the filter for the query
+		wheres := make([]string, 0)
+		where := ""
+		where, params, err = b.SelectionFilter(dataset, params, alias, filters.Generic)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(where) > 0 {
+			wheres = append(wheres, where)
+		}
+
+		// assemble split filters
+		for _, predictedFilter := range filters.Predicted {
+			wheres, params, err = b.buildPredictedResultWhere(dataset, wheres, params, alias, predictedFilter)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		for _, correctnessFilter := range filters.Correctness {
+			targetName, err := columns.ResultTargetName(storageName, resultURI)
+			if err != nil {
+				return nil, nil, err
+			}
+			wheres, params, err = b.CorrectnessWhere(wheres, params, "result", targetName, correctnessFilter)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		for _, residualFilter := range filters.Residual {
+			targetName, err := columns.ErrorTargetName(residualFilter.List[0].Key)
+			if err != nil {
+				return nil, nil, err
+			}
+			wheres, params = b.ErrorWhere(wheres, params, "", targetName, residualFilter)
+		}
+		for _, confidenceFilter := range filters.Confidence {
+			wheres, params = b.ConfidenceWhere(wheres, params, confidenceFilter, "result")
+		}
+		for _, rankFilter := range filters.Rank {
+			wheres, params = b.RankWhere(wheres, params, rankFilter, "result")
+		}
+
+		if len(wheres) > 0 {
+			wheresCombined = append(wheresCombined, CombineClauses(filterSet.Mode, wheres, "AND"))
+		}
+	}
+
+	return b.FilterExpressionWhere(dataset, wheresCombined, params, alias, filterParams)
+}
+
+// CombineClauses joins the non empty clauses with the operation, negating
+// the result for exclude filters.
+func CombineClauses(mode string, clauses []string, operation string) string {
+	clausesContent := []string{}
+	for _, clause := range clauses {
+		if len(clause) > 0 {
+			clausesContent = append(clausesContent, clause)
+		}
+	}
+	if len(clausesContent) == 0 {
+		return ""
+	}
+
+	whereCombined := fmt.Sprintf("(%s)", strings.Join(clausesContent, fmt.Sprintf(" %s ", operation)))
+	if mode == model.ExcludeFilter {
+		whereCombined = fmt.Sprintf("NOT%s", whereCombined)
+	}
+	return whereCombined
+}
+
+// SplitFilters splits the filters of a filter set by the kind of column they
+// apply to.
+func SplitFilters(filterSet *model.FilterSet) (*Filters, error) {
+	if filterSet == nil {
+		return &Filters{}, nil
+	}
+
+	// split fitlers into inclusion and exclusion sets
+	output := &Filters{
+		Generic:     []model.FilterObject{},
+		Predicted:   []model.FilterObject{},
+		Residual:    []model.FilterObject{},
+		Correctness: []model.FilterObject{},
+		Confidence:  []model.FilterObject{},
+		Rank:        []model.FilterObject{},
+	}
+
+	for _, featureFilters := range filterSet.FeatureFilters {
+		if api.IsPredictedKey(featureFilters.List[0].Key) {
+			output.Predicted = append(output.Predicted, featureFilters)
+		} else if api.IsErrorKey(featureFilters.List[0].Key) {
+			if featureFilters.List[0].Type == model.NumericalFilter {
+				output.Residual = append(output.Residual, featureFilters)
+			} else if featureFilters.List[0].Type == model.CategoricalFilter {
+				output.Correctness = append(output.Correctness, featureFilters)
+			}
+		} else if api.IsConfidenceKey(featureFilters.List[0].Key) {
+			output.Confidence = append(output.Confidence, featureFilters)
+		} else if api.IsRankKey(featureFilters.List[0].Key) {
+			output.Rank = append(output.Rank, featureFilters)
+		} else {
+			output.Generic = append(output.Generic, featureFilters)
+		}
+	}
+
+	return output, nil
+}
+
+// SelectStatement returns the fields of the variables to select from the
+// filtered data, including the index.
+func SelectStatement(variables []*model.Variable, filterVariables []string) string {
+	fields, _ := FilteredQueryField(variables, filterVariables, false)
+	return fields
+}
+
+// FilteredQueryField returns the fields to select along with the grouping
+// columns for which only a single row should be returned.
+func FilteredQueryField(variables []*model.Variable, filterVariables []string, distinct bool) (string, []string) {
+
+	distincts := make([]string, 0)
+	fields := make([]string, 0)
+	indexIncluded := false
+	for _, variable := range api.GetFilterVariables(filterVariables, variables) {
+		if variable.IsGrouping() {
+			continue
+		}
+
+		if variable.HasRole(model.VarDistilRoleGrouping) && distinct {
+			distincts = append(distincts, fmt.Sprintf("\"%s\"", variable.Key))
+		}
+
+		// derived metadata variables (ex: postgis geometry) should use the original variables
+		varKey := variable.Key
+		if variable.HasRole(model.VarDistilRoleMetadata) && variable.OriginalVariable != variable.Key {
+			varKey = variable.OriginalVariable
+		}
+
+		fields = append(fields, fmt.Sprintf("\"%s\"", varKey))
+		if varKey == model.D3MIndexFieldName {
+			indexIncluded = true
+		}
+
+	}
+	// if the index is not already in the field list, then append it
+	if !indexIncluded {
+		fields = append(fields, fmt.Sprintf("\"%s\"", model.D3MIndexFieldName))
+	}
+	return strings.Join(fields, ","), distincts
+}
+
+// FilteredData returns the query fetching a page of filtered data. Only the
+// first row of every group of the distinct columns is kept.
+func (b *Builder) FilteredData(storageName string, fields string, distincts []string, selectStatement string, wheres []string, orderBy string, size int) string {
+	query := fmt.Sprintf("SELECT %s FROM %s", fields, storageName)
+
+	wheresQuery := wheres
+	if len(distincts) > 0 {
+		// keep the first row of every group, reusing the numbered filter params
+		distinctWhere := ""
+		if len(wheres) > 0 {
+			distinctWhere = fmt.Sprintf("WHERE %s", strings.Join(wheres, " AND "))
+		}
+		wheresQuery = append(wheresQuery, fmt.Sprintf("\"%s\" IN (SELECT min(\"%s\") FROM %s %s GROUP BY %s)",
+			model.D3MIndexFieldName, model.D3MIndexFieldName, storageName, distinctWhere, strings.Join(distincts, ",")))
+	}
+	if len(wheresQuery) > 0 {
+		query = fmt.Sprintf("%s WHERE %s", query, strings.Join(wheresQuery, " AND "))
+	}
+
+	// order & limit the filtered data.
+	query = fmt.Sprintf("SELECT %s FROM (%s) data ORDER BY %s", selectStatement, query, orderBy)
+	if size > 0 {
+		query = fmt.Sprintf("%s LIMIT %d", query, size)
+	}
+	return query + ";"
+}
+
+// NumRows returns the query counting the rows of the table, counting each
+// group of the grouping variables once.
+func (b *Builder) NumRows(storageName string, variables []*model.Variable, tableAlias string, joinSQL string, filters []string) string {
+
+	countTarget := "*"
+
+	// a distinct count is only supported over a single expression by some
+	// databases so multiple groupings are concatenated
+	groupings := []string{}
+	groupingSet := map[string]bool{}
+	for _, v := range variables {
+		if v.IsGrouping() && v.Grouping.GetIDCol() != "" && !groupingSet[v.Grouping.GetIDCol()] {
+			groupingSet[v.Grouping.GetIDCol()] = true
+			groupings = append(groupings, fmt.Sprintf("\"%s\"", v.Grouping.GetIDCol()))
+		}
+	}
+
+	if len(groupings) > 0 {
+		countTarget = "DISTINCT " + strings.Join(groupings, " || '|' || ")
+	}
+
+	query := fmt.Sprintf("SELECT count(%s) FROM %s AS %s %s", countTarget, storageName, tableAlias, joinSQL)
+	if len(filters) > 0 {
+		query = fmt.Sprintf("%s WHERE %s", query, strings.Join(filters, " AND "))
+	}
+	return query
+}
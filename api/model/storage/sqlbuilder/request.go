@@ -0,0 +1,163 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlbuilder
+
+import (
+	"fmt"
+
+	"github.com/uncharted-distil/distil-compute/model"
+
+	"github.com/uncharted-distil/distil/api/postgres"
+)
+
+// RequestFields are the request columns read by the request queries, in
+// the order they are scanned.
+const RequestFields = "req.request_id, req.dataset, req.progress, req.created_time, req.last_updated_time"
+
+// params returns the placeholders of the parameters first to last.
+func (b *Builder) params(first int, last int) string {
+	placeholders := ""
+	for i := first; i <= last; i++ {
+		if i > first {
+			placeholders += ", "
+		}
+		placeholders += b.Param(i)
+	}
+	return placeholders
+}
+
+// PersistRequest inserts a request, created and updated at the same time.
+func (b *Builder) PersistRequest() string {
+	return fmt.Sprintf("INSERT INTO %s (request_id, dataset, progress, created_time, last_updated_time) VALUES (%s, %s);",
+		postgres.RequestTableName, b.params(1, 4), b.Param(4))
+}
+
+// UpdateRequest updates the progress of a request.
+func (b *Builder) UpdateRequest() string {
+	return fmt.Sprintf("UPDATE %s SET progress = %s, last_updated_time = %s WHERE request_id = %s;",
+		postgres.RequestTableName, b.Param(1), b.Param(2), b.Param(3))
+}
+
+// PersistRequestFeature inserts a feature of a request.
+func (b *Builder) PersistRequestFeature() string {
+	return fmt.Sprintf("INSERT INTO %s (request_id, feature_name, feature_type) VALUES (%s);",
+		postgres.RequestFeatureTableName, b.params(1, 3))
+}
+
+// PersistRequestFilter inserts a filter of a request.
+func (b *Builder) PersistRequestFilter() string {
+	return fmt.Sprintf(
+		"INSERT INTO %s (request_id, feature_name, filter_type, filter_mode, filter_min, filter_max, filter_min_x, filter_max_x, filter_min_y, filter_max_y, filter_categories, filter_indices) "+
+			"VALUES (%s);", postgres.RequestFilterTableName, b.params(1, 12))
+}
+
+// PersistRequestFilterExpression inserts the filter expression document of a request.
+func (b *Builder) PersistRequestFilterExpression() string {
+	return fmt.Sprintf("INSERT INTO %s (request_id, expression) VALUES (%s);",
+		postgres.RequestFilterExpressionTableName, b.params(1, 2))
+}
+
+// PersistRequestSplitManifest inserts the train/test split manifest of a request.
+func (b *Builder) PersistRequestSplitManifest() string {
+	return fmt.Sprintf("INSERT INTO %s (request_id, split_type, seed, train_test_split, manifest, created_time) VALUES (%s);",
+		postgres.RequestSplitTableName, b.params(1, 6))
+}
+
+// PersistSolutionStatusEvent inserts a status update of a solution request.
+func (b *Builder) PersistSolutionStatusEvent() string {
+	return fmt.Sprintf("INSERT INTO %s (request_id, solution_id, result_id, progress, error, created_time) VALUES (%s);",
+		postgres.SolutionStatusEventTableName, b.params(1, 6))
+}
+
+// FetchRequest selects the latest version of a request.
+func (b *Builder) FetchRequest() string {
+	return fmt.Sprintf("SELECT %s FROM %s AS req WHERE request_id = %s ORDER BY created_time desc LIMIT 1;",
+		RequestFields, postgres.RequestTableName, b.Param(1))
+}
+
+// FetchRequestByResultUUID selects the request that produced a result.
+func (b *Builder) FetchRequestByResultUUID() string {
+	return fmt.Sprintf("SELECT %s "+
+		"FROM %s as req INNER JOIN %s as sol ON req.request_id = sol.request_id INNER JOIN %s as sol_res ON sol.solution_id = sol_res.solution_id "+
+		"WHERE sol_res.result_uuid = %s;", RequestFields, postgres.RequestTableName, postgres.SolutionTableName, postgres.SolutionResultTableName, b.Param(1))
+}
+
+// FetchRequestBySolutionID selects the request that produced a solution.
+func (b *Builder) FetchRequestBySolutionID() string {
+	return fmt.Sprintf("SELECT %s "+
+		"FROM %s as req INNER JOIN %s as sol ON req.request_id = sol.request_id "+
+		"WHERE sol.solution_id = %s;", RequestFields, postgres.RequestTableName, postgres.SolutionTableName, b.Param(1))
+}
+
+// FetchRequestByFittedSolutionID selects the request that produced a fitted solution.
+func (b *Builder) FetchRequestByFittedSolutionID() string {
+	return fmt.Sprintf("SELECT %s "+
+		"FROM %s as req INNER JOIN %s as sol ON req.request_id = sol.request_id INNER JOIN %s sr on sr.solution_id = sol.solution_id "+
+		"WHERE sr.fitted_solution_id = %s;", RequestFields, postgres.RequestTableName, postgres.SolutionTableName, postgres.SolutionResultTableName, b.Param(1))
+}
+
+// FetchRequestFeatures selects the features of a request.
+func (b *Builder) FetchRequestFeatures() string {
+	return fmt.Sprintf("SELECT request_id, feature_name, feature_type FROM %s WHERE request_id = %s;",
+		postgres.RequestFeatureTableName, b.Param(1))
+}
+
+// FetchRequestSplitManifest selects the latest train/test split manifest of a request.
+func (b *Builder) FetchRequestSplitManifest() string {
+	return fmt.Sprintf("SELECT manifest FROM %s WHERE request_id = %s ORDER BY created_time desc LIMIT 1;",
+		postgres.RequestSplitTableName, b.Param(1))
+}
+
+// FetchRequestFilterExpression selects the filter expression document of a request.
+func (b *Builder) FetchRequestFilterExpression() string {
+	return fmt.Sprintf("SELECT expression FROM %s WHERE request_id = %s;",
+		postgres.RequestFilterExpressionTableName, b.Param(1))
+}
+
+// FetchRequestFilters selects the filters of a request.
+func (b *Builder) FetchRequestFilters() string {
+	return fmt.Sprintf("SELECT feature_name, filter_type, filter_mode, filter_min, filter_max, filter_min_x, filter_max_x, filter_min_y, filter_max_y, filter_categories, filter_indices FROM %s WHERE request_id = %s;",
+		postgres.RequestFilterTableName, b.Param(1))
+}
+
+// FetchRequestByDatasetTarget selects the requests of a dataset and target
+// that produced solutions. Empty values match all datasets or targets.
+func (b *Builder) FetchRequestByDatasetTarget(dataset string, target string) (string, []interface{}) {
+	query := fmt.Sprintf("SELECT DISTINCT %s "+
+		"FROM %s req INNER JOIN %s rf ON req.request_id = rf.request_id "+
+		"INNER JOIN %s solution ON req.request_id = solution.request_id",
+		RequestFields, postgres.RequestTableName, postgres.RequestFeatureTableName, postgres.SolutionTableName)
+	params := make([]interface{}, 0)
+
+	if dataset != "" {
+		query = fmt.Sprintf("%s AND req.dataset = %s", query, b.Param(len(params)+1))
+		params = append(params, dataset)
+	}
+	if target != "" {
+		query = fmt.Sprintf("%s AND rf.feature_name = %s AND rf.feature_type = %s", query, b.Param(len(params)+1), b.Param(len(params)+2))
+		params = append(params, target)
+		params = append(params, model.FeatureTypeTarget)
+	}
+
+	return fmt.Sprintf("%s ORDER BY req.request_id;", query), params
+}
+
+// FetchSolutionStatusEvents selects the status updates of a solution request
+// in the order they were persisted.
+func (b *Builder) FetchSolutionStatusEvents() string {
+	return fmt.Sprintf("SELECT request_id, solution_id, result_id, progress, error, created_time FROM %s WHERE request_id = %s ORDER BY event_id;",
+		postgres.SolutionStatusEventTableName, b.Param(1))
+}
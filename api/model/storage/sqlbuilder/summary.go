@@ -0,0 +1,160 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/uncharted-distil/distil-compute/model"
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+// HistogramAgg holds the expressions of a histogram aggregation. The bucket
+// expression gives the 0 based bucket of a row and the value expression the
+// lower bound of that bucket.
+type HistogramAgg struct {
+	Name   string
+	Bucket string
+	Value  string
+}
+
+// Where returns the WHERE clause of the conditions, if there are any.
+func Where(wheres []string) string {
+	if len(wheres) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("WHERE %s", strings.Join(wheres, " AND "))
+}
+
+// DataResultJoin returns the FROM clause joining the results of the result
+// table to the data of the aliased from clause.
+func DataResultJoin(from string, resultTable string, alias string) string {
+	return fmt.Sprintf("%s INNER JOIN %s result ON %s.\"%s\" = result.\"index\"",
+		from, resultTable, alias, model.D3MIndexFieldName)
+}
+
+// DefaultFilter matches the rows holding the default value of the typed
+// view, which stands for a missing value.
+func (b *Builder) DefaultFilter(typ string, key string, inverse bool) string {
+	defaultValue := b.Dialect.DefaultValue(typ)
+
+	// null comparison is done with "is" rather than "="
+	op := "="
+	if defaultValue == "NULL" {
+		op = "is"
+	}
+	negate := ""
+	if inverse {
+		negate = "NOT"
+	}
+
+	return fmt.Sprintf("%s(\"%s\" %s %s)", negate, key, op, defaultValue)
+}
+
+// MissingCount returns the query counting the rows matching the filters.
+func (b *Builder) MissingCount(count string, from string, wheres []string) string {
+	return fmt.Sprintf("SELECT COUNT(%s) FROM %s %s;", count, from, Where(wheres))
+}
+
+// ResultWhere matches the rows of a result, passed as the parameter at the index.
+func (b *Builder) ResultWhere(index int) string {
+	return fmt.Sprintf("result.result_id = %s", b.Param(index))
+}
+
+// ResultTargetWhere matches the rows of a result and target, passed as the
+// parameters at the index and the next one.
+func (b *Builder) ResultTargetWhere(index int) string {
+	return fmt.Sprintf("result.result_id = %s AND result.target = %s ", b.Param(index), b.Param(index+1))
+}
+
+// HistogramAgg builds the aggregation of the typed field over the buckets
+// spanning [min, max).
+func (b *Builder) HistogramAgg(key string, field string, min float64, max float64, interval float64, bucketCount int) *HistogramAgg {
+	bucket := b.Dialect.Bucket(field, min, max, bucketCount)
+	return &HistogramAgg{
+		Name:   fmt.Sprintf("\"%s%s\"", api.HistogramAggPrefix, key),
+		Bucket: bucket,
+		Value:  fmt.Sprintf("(%s) * %g + %g", bucket, interval, min),
+	}
+}
+
+// Histogram returns the query counting the rows of every bucket of the
+// aggregation, ordered by bucket.
+func (b *Builder) Histogram(agg *HistogramAgg, count string, from string, wheres []string) string {
+	return fmt.Sprintf("SELECT %s AS bucket, %s AS %s, COUNT(%s) AS count FROM %s %s GROUP BY 1 ORDER BY 2;",
+		agg.Bucket, b.Dialect.Real(agg.Value), agg.Name, count, from, Where(wheres))
+}
+
+// MinMax returns the aggregations of the extrema of the typed field.
+func (b *Builder) MinMax(key string, field string) string {
+	return fmt.Sprintf("%s AS \"%s%s\", %s AS \"%s%s\"",
+		b.Dialect.Real(fmt.Sprintf("MIN(%s)", field)), api.MinAggPrefix, key,
+		b.Dialect.Real(fmt.Sprintf("MAX(%s)", field)), api.MaxAggPrefix, key)
+}
+
+// Select returns a query of the expressions from the rows matching the filters.
+func (b *Builder) Select(expressions string, from string, wheres []string) string {
+	return fmt.Sprintf("SELECT %s FROM %s %s;", expressions, from, Where(wheres))
+}
+
+// Stats returns the query of the standard deviation and mean of a column.
+func (b *Builder) Stats(key string, from string, wheres []string) string {
+	return fmt.Sprintf("SELECT coalesce(stddev(\"%s\"), 0) AS stddev, avg(\"%s\") AS avg FROM %s %s;",
+		key, key, from, Where(wheres))
+}
+
+// Distinct returns the query of the distinct values of a column.
+func (b *Builder) Distinct(key string, table string) string {
+	return fmt.Sprintf("SELECT DISTINCT \"%s\" FROM %s;", key, table)
+}
+
+// CategoricalHistogram returns the query counting the rows of the most
+// frequent values of the column.
+func (b *Builder) CategoricalHistogram(column string, count string, from string, wheres []string, limit int) string {
+	return fmt.Sprintf("SELECT %s, COUNT(%s) AS count FROM %s %s GROUP BY %s ORDER BY count desc, %s LIMIT %d;",
+		column, count, from, Where(wheres), column, column, limit)
+}
+
+// PredictedHistogram returns the query counting the rows of every predicted value.
+func (b *Builder) PredictedHistogram(count string, resultTable string, storageName string, wheres []string) string {
+	return fmt.Sprintf("SELECT result.value, COUNT(%s) AS count FROM %s AS result INNER JOIN %s AS data ON result.\"index\" = data.\"%s\" %s GROUP BY result.value ORDER BY count desc;",
+		count, resultTable, storageName, model.D3MIndexFieldName, Where(wheres))
+}
+
+// CorrectnessHistogram returns the query counting the rows of every pair of
+// target and predicted values.
+func (b *Builder) CorrectnessHistogram(targetName string, count string, resultTable string, storageName string, wheres []string) string {
+	return fmt.Sprintf("SELECT data.\"%s\", result.value, COUNT(%s) AS count FROM %s AS result INNER JOIN %s AS data ON result.\"index\" = data.\"%s\" %s GROUP BY result.value, data.\"%s\" ORDER BY count desc;",
+		targetName, count, resultTable, storageName, model.D3MIndexFieldName, Where(wheres), targetName)
+}
+
+// ExplainExtrema returns the query of the extrema of an explain value of a result.
+func (b *Builder) ExplainExtrema(fieldName string, resultTable string) string {
+	value := b.ExplainValue("", fieldName)
+	return fmt.Sprintf("SELECT MIN(%s) AS min_val, MAX(%s) AS max_val FROM %s WHERE result_id = %s",
+		value, value, resultTable, b.Param(1))
+}
+
+// ExplainSubSelect returns the sub select joining an explain value of the
+// results to the base data.
+func (b *Builder) ExplainSubSelect(resultTable string, storageName string, fieldName string, aliasName string) string {
+	return fmt.Sprintf(`(
+			SELECT %s AS "%s", result_id AS result_key, b.* FROM %s AS d
+			INNER JOIN %s AS b ON b."%s" = d."index"
+			)`,
+		b.ExplainValue("d", fieldName), aliasName, resultTable, storageName, model.D3MIndexFieldName)
+}
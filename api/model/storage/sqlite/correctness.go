@@ -0,0 +1,180 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/model/storage/sqlbuilder"
+)
+
+// FetchCorrectnessSummary fetches a histogram of the residuals associated with a set of numerical predictions.
+func (s *Storage) FetchCorrectnessSummary(dataset string, storageName string, resultURI string, filterParams *api.FilterParams, mode api.SummaryMode) (*api.VariableSummary, error) {
+
+	storageNameResult := s.getResultTable(storageName)
+	targetName, err := s.getResultTargetName(storageNameResult, resultURI)
+	if err != nil {
+		return nil, err
+	}
+
+	variable, err := s.getResultTargetVariable(dataset, targetName)
+	if err != nil {
+		return nil, err
+	}
+
+	var baseline *api.Histogram
+	var filtered *api.Histogram
+	baseline, err = s.fetchHistogram(dataset, storageName, variable, targetName, resultURI, api.GetBaselineFilter(filterParams), mode)
+	if err != nil {
+		return nil, err
+	}
+	if !filterParams.IsEmpty(true) {
+		filtered, err = s.fetchHistogram(dataset, storageName, variable, targetName, resultURI, filterParams, mode)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &api.VariableSummary{
+		Label:    variable.DisplayName,
+		Key:      variable.Key,
+		Type:     model.CategoricalType,
+		VarType:  variable.Type,
+		Baseline: baseline,
+		Filtered: filtered,
+	}, nil
+}
+
+func (s *Storage) fetchHistogram(dataset string, storageName string, variable *model.Variable, targetName string, resultURI string, filterParams *api.FilterParams, mode api.SummaryMode) (*api.Histogram, error) {
+	storageNameResult := s.getResultTable(storageName)
+	err := updateClusterFilters(s.metadata, dataset, filterParams, mode)
+	if err != nil {
+		return nil, err
+	}
+	// get filter where / params
+	wheres, params, err := s.buildResultQueryFilters(dataset, storageName, resultURI, filterParams, baseTableAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	countCol, err := s.getCountCol(dataset, mode)
+	if err != nil {
+		return nil, err
+	}
+	countCol = sqlbuilder.CountSQL(countCol)
+
+	wheres = append(wheres, s.builder.ResultTargetWhere(len(params)+1))
+	params = append(params, resultURI, targetName)
+
+	query := s.builder.CorrectnessHistogram(targetName, countCol, storageNameResult, storageName, wheres)
+
+	// execute the sqlite query
+	res, err := s.client.Query(query, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch histograms for result summaries from sqlite")
+	}
+	defer res.Close()
+
+	return s.parseHistogram(res, variable)
+}
+
+func (s *Storage) getCountCol(dataset string, mode api.SummaryMode) (string, error) {
+	countCol := ""
+	if mode == api.MultiBandImageMode {
+		// remote sensing group should be distinct by group id
+		vars, err := s.metadata.FetchVariables(dataset, false, true, false)
+		if err != nil {
+			return "", err
+		}
+
+		for _, v := range vars {
+			if v.IsGrouping() {
+				countCol = v.Grouping.GetIDCol()
+			}
+		}
+
+	}
+
+	return countCol, nil
+}
+
+func (s *Storage) parseHistogram(rows *sql.Rows, variable *model.Variable) (*api.Histogram, error) {
+
+	termsAggName := api.TermsAggPrefix + variable.Key
+
+	// extract the counts
+	countMap := map[string]map[string]int64{}
+	for rows.Next() {
+		var predictedTerm string
+		var targetTerm interface{}
+		var bucketCount int64
+		err := rows.Scan(&targetTerm, &predictedTerm, &bucketCount)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("no %s histogram aggregation found", termsAggName))
+		}
+		if len(countMap[predictedTerm]) == 0 {
+			countMap[predictedTerm] = map[string]int64{}
+		}
+		countMap[predictedTerm][toString(targetTerm)] = bucketCount
+	}
+	err := rows.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading data from sqlite")
+	}
+
+	correctBucket := &api.Bucket{
+		Key: "Correct",
+	}
+	incorrectBucket := &api.Bucket{
+		Key: "Incorrect",
+	}
+
+	for predictedKey, targetCounts := range countMap {
+		for targetKey, count := range targetCounts {
+			if predictedKey == targetKey {
+				correctBucket.Count += count
+			} else {
+				incorrectBucket.Count += count
+			}
+		}
+	}
+
+	var min int64
+	var max int64
+	if incorrectBucket.Count < correctBucket.Count {
+		min = incorrectBucket.Count
+		max = correctBucket.Count
+	} else {
+		min = correctBucket.Count
+		max = incorrectBucket.Count
+	}
+
+	// assign histogram attributes
+	return &api.Histogram{
+		Buckets: []*api.Bucket{
+			correctBucket,
+			incorrectBucket,
+		},
+		Extrema: &api.Extrema{
+			Min: float64(min),
+			Max: float64(max),
+		},
+	}, nil
+}
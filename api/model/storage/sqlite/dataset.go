@@ -0,0 +1,778 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/sqlite"
+	log "github.com/unchartedsoftware/plog"
+)
+
+const (
+	datasetStreamChunkSize = 10000
+)
+
+type joinDefinition struct {
+	baseAlias     string
+	baseColumn    string
+	joinTableName string
+	joinAlias     string
+	joinColumn    string
+}
+
+func getBaseTableName(storageName string) string {
+	return fmt.Sprintf("%s_base", storageName)
+}
+
+func getVariableTableName(storageName string) string {
+	return fmt.Sprintf("%s_variable", storageName)
+}
+
+// SaveDataset is used for dropping the unused values based on filter param. (Only used in save_dataset route)
+func (s *Storage) SaveDataset(dataset string, storageName string, filterParams *api.FilterParams) error {
+	// due to values being dropped from base table result table is invalid
+	err := s.deleteRows(dataset, s.getResultTable(storageName), nil)
+	if err != nil {
+		return err
+	}
+	// due to values being dropped from base table explanation table is invalid
+	err = s.deleteRows(dataset, s.getSolutionFeatureWeightTable(storageName), nil)
+	if err != nil {
+		return err
+	}
+
+	if !filterParams.IsEmpty(false) {
+		err = s.deleteRows(dataset, getBaseTableName(storageName), filterParams)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteRows deletes rows based on filterParams
+func (s *Storage) deleteRows(dataset string, storageName string, filterParams *api.FilterParams) error {
+	wheres := []string{}
+	paramsFilter := make([]interface{}, 0)
+	wheres, paramsFilter, err := s.builder.FilteredQueryWhere(dataset, wheres, paramsFilter, "", filterParams)
+	if err != nil {
+		return err
+	}
+	where := ""
+	if len(wheres) > 0 {
+		where = "WHERE " + strings.Join(wheres, " AND ")
+	}
+	query := fmt.Sprintf("DELETE FROM %s %s;", storageName, where)
+	_, err = s.client.Exec(query, paramsFilter...)
+	if err != nil {
+		return errors.Wrapf(err, "unable execute query to delete rows")
+	}
+	return nil
+}
+
+// CloneDataset clones an existing dataset.
+func (s *Storage) CloneDataset(dataset string, storageName string, datasetNew string, storageNameNew string) error {
+	// need to clone base, variable, result, and weight tables
+	err := s.cloneTable(getBaseTableName(storageName), getBaseTableName(storageNameNew), true)
+	if err != nil {
+		return err
+	}
+
+	err = s.cloneTable(getVariableTableName(storageName), getVariableTableName(storageNameNew), true)
+	if err != nil {
+		return err
+	}
+
+	err = s.cloneTable(s.getResultTable(storageName), s.getResultTable(storageNameNew), false)
+	if err != nil {
+		return err
+	}
+
+	err = s.cloneTable(s.getSolutionFeatureWeightTable(storageName), s.getSolutionFeatureWeightTable(storageNameNew), false)
+	if err != nil {
+		return err
+	}
+
+	// need to create the view for the cloned dataset
+	fields, err := s.getExistingFields(dataset, getBaseTableName(storageNameNew))
+	if err != nil {
+		return err
+	}
+
+	err = s.createView(storageNameNew, fields)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteDataset drops all tables associated to the dataset
+func (s *Storage) DeleteDataset(storageName string) error {
+	// drop view
+	err := s.dropView(storageName)
+	if err != nil {
+		return err
+	}
+	// drop base table
+	err = s.dropTable(getBaseTableName(storageName))
+	if err != nil {
+		return err
+	}
+	// drop variable table
+	err = s.dropTable(getVariableTableName(storageName))
+	if err != nil {
+		return err
+	}
+	// drop result table
+	err = s.dropTable(s.getResultTable(storageName))
+	if err != nil {
+		return err
+	}
+	// drop explanation table
+	err = s.dropTable(s.getSolutionFeatureWeightTable(storageName))
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *Storage) dropView(view string) error {
+	query := fmt.Sprintf("DROP VIEW IF EXISTS %s", view)
+	_, err := s.client.Exec(query)
+	if err != nil {
+		return errors.Wrapf(err, "unable to drop view")
+	}
+	return nil
+}
+
+func (s *Storage) dropTable(table string) error {
+	query := fmt.Sprintf("DROP TABLE IF EXISTS %s", table)
+	_, err := s.client.Exec(query)
+	if err != nil {
+		return errors.Wrapf(err, "unable to drop table")
+	}
+	return nil
+}
+
+func (s *Storage) cloneTable(existingTable string, newTable string, copyData bool) error {
+	// sqlite has no LIKE clause so the original creation statement is reused
+	var creationSQL string
+	err := s.client.QueryRow("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?1;", existingTable).Scan(&creationSQL)
+	if err != nil {
+		return errors.Wrapf(err, "unable to read definition of table %s", existingTable)
+	}
+	creationSQL = strings.Replace(creationSQL, existingTable, newTable, 1)
+	_, err = s.client.Exec(creationSQL)
+	if err != nil {
+		return errors.Wrapf(err, "unable to clone table")
+	}
+
+	// if copy data insert data from other table
+	if copyData {
+		query := fmt.Sprintf("INSERT INTO %s SELECT * FROM %s;", newTable, existingTable)
+		_, err := s.client.Exec(query)
+		if err != nil {
+			return errors.Wrapf(err, "unable to clone table")
+		}
+	}
+
+	return nil
+}
+
+func (s *Storage) getDatabaseFields(tableName string) ([]string, error) {
+	cols, err := s.fetchStrings("SELECT name FROM pragma_table_info(?1);", tableName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch database column names from sqlite")
+	}
+
+	return cols, nil
+}
+
+func (s *Storage) getExistingFields(dataset string, storageName string) (map[string]*model.Variable, error) {
+	vars, err := api.FetchDatasetVariables(dataset, s.metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	// make sure they exist in the underlying database already
+	fields := make(map[string]*model.Variable)
+	for _, v := range vars {
+		exists, _ := s.DoesVariableExist(dataset, storageName, v.Key)
+		if exists {
+			fields[v.Key] = v
+		}
+	}
+
+	return fields, nil
+}
+
+// createView replaces the typed view over the base table. Sqlite can neither
+// replace nor rename a view so it is dropped and recreated.
+func (s *Storage) createView(storageName string, fields map[string]*model.Variable) error {
+	// Build the select statement of the query.
+	fieldList := make([]string, 0)
+	for _, v := range fields {
+		fieldList = append(fieldList, sqlite.GetViewField(v))
+	}
+
+	tx, err := s.client.Begin()
+	if err != nil {
+		return errors.Wrap(err, "unable to create transaction")
+	}
+
+	_, err = tx.Exec(fmt.Sprintf("DROP VIEW IF EXISTS %s;", storageName))
+	if err != nil {
+		_ = tx.Rollback()
+		return errors.Wrap(err, "Unable to drop existing view")
+	}
+
+	_, err = tx.Exec(fmt.Sprintf("CREATE VIEW %s AS SELECT %s FROM %s;", storageName, strings.Join(fieldList, ","), getBaseTableName(storageName)))
+	if err != nil {
+		_ = tx.Rollback()
+		return errors.Wrap(err, "Unable to create new view")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "unable to commit view update")
+	}
+
+	return nil
+}
+
+func (s *Storage) parseData(rows *sql.Rows) ([][]string, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read columns from sqlite")
+	}
+
+	output := [][]string{}
+	for rows.Next() {
+		columnValues, err := scanValues(rows, len(columns))
+		if err != nil {
+			return nil, err
+		}
+		if len(output) == 0 {
+			output = append(output, columns)
+		}
+
+		row := make([]string, len(columns))
+		for i, cv := range columnValues {
+			row[i] = toString(cv)
+		}
+		output = append(output, row)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading data from sqlite")
+	}
+
+	return output, nil
+}
+
+// FetchDataset extracts the complete raw data from the database.
+func (s *Storage) FetchDataset(dataset string, storageName string,
+	includeMetadata bool, limitSelectedFields bool, filterParams *api.FilterParams) ([][]string, error) {
+	fields, wheres, paramsFilter, _, err := s.buildDatasetSelect(dataset, storageName, includeMetadata, limitSelectedFields, filterParams)
+	if err != nil {
+		return nil, err
+	}
+	where := ""
+	if len(wheres) > 0 {
+		where = "WHERE " + strings.Join(wheres, " AND ")
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s %s;", strings.Join(fields, ", "), getBaseTableName(storageName), where)
+
+	res, err := s.client.Query(query, paramsFilter...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable execute query to extract dataset")
+	}
+	defer res.Close()
+
+	return s.parseData(res)
+}
+
+// StreamDataset extracts the dataset one chunk at a time, passing the rows to
+// the writer. Sqlite has no cursors so each chunk resumes after the last row
+// id of the previous chunk, which keeps every chunk an index seek rather than
+// rescanning the skipped rows.
+func (s *Storage) StreamDataset(dataset string, storageName string, includeMetadata bool,
+	limitSelectedFields bool, filterParams *api.FilterParams, writer api.DatasetStreamWriter) error {
+	fields, wheres, paramsFilter, header, err := s.buildDatasetSelect(dataset, storageName, includeMetadata, limitSelectedFields, filterParams)
+	if err != nil {
+		return err
+	}
+	err = writer.WriteHeader(header)
+	if err != nil {
+		return err
+	}
+
+	wheres = append(wheres, fmt.Sprintf("rowid > ?%d", len(paramsFilter)+1))
+	query := fmt.Sprintf("SELECT rowid, %s FROM %s WHERE %s ORDER BY rowid LIMIT %d;",
+		strings.Join(fields, ", "), getBaseTableName(storageName), strings.Join(wheres, " AND "), datasetStreamChunkSize)
+
+	lastRowID := int64(math.MinInt64)
+	for {
+		res, err := s.client.Query(query, append(paramsFilter, lastRowID)...)
+		if err != nil {
+			return errors.Wrapf(err, "unable to fetch dataset chunk")
+		}
+		rows, rowID, err := parseStreamedRows(res, len(header))
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			break
+		}
+		lastRowID = rowID
+
+		err = writer.WriteRows(rows)
+		if err != nil {
+			return err
+		}
+		if len(rows) < datasetStreamChunkSize {
+			break
+		}
+	}
+
+	return nil
+}
+
+// parseStreamedRows reads a chunk of rows selected after their row id,
+// returning the rows and the row id of the last one.
+func parseStreamedRows(res *sql.Rows, columnCount int) ([][]string, int64, error) {
+	defer res.Close()
+
+	rows := [][]string{}
+	var rowID int64
+	for res.Next() {
+		columnValues, err := scanValues(res, columnCount+1)
+		if err != nil {
+			return nil, 0, errors.Wrapf(err, "unable to read dataset row")
+		}
+		id, ok := columnValues[0].(int64)
+		if !ok {
+			return nil, 0, errors.Errorf("unexpected row id %v", columnValues[0])
+		}
+		rowID = id
+		row := make([]string, columnCount)
+		for i, cv := range columnValues[1:] {
+			// all fields are selected as text
+			row[i] = toString(cv)
+		}
+		rows = append(rows, row)
+	}
+	if res.Err() != nil {
+		return nil, 0, errors.Wrapf(res.Err(), "unable to read dataset rows")
+	}
+
+	return rows, rowID, nil
+}
+
+// buildDatasetSelect builds the parts of the query extracting the dataset,
+// returning the selected fields, the filter clauses, their parameters and the
+// selected field names.
+func (s *Storage) buildDatasetSelect(dataset string, storageName string, includeMetadata bool,
+	limitSelectedFields bool, filterParams *api.FilterParams) ([]string, []string, []interface{}, []string, error) {
+	// get data variables (to exclude metadata variables)
+	vars, err := s.metadata.FetchVariables(dataset, true, includeMetadata, false)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	filteredVars := []*model.Variable{}
+
+	selectedVars := map[string]bool{}
+	if filterParams != nil {
+		for _, v := range filterParams.Variables {
+			selectedVars[v] = true
+		}
+	}
+
+	// only include data with distilrole data and index
+	// limit vars to only those selected (if applicable)
+	for _, v := range vars {
+		if (v.IsTA2Field() ||
+			(v.HasRole(model.VarDistilRoleMetadata) && includeMetadata)) &&
+			(!limitSelectedFields || selectedVars[v.Key]) {
+			filteredVars = append(filteredVars, v)
+		}
+	}
+	varNames := []string{}
+	header := []string{}
+	for _, v := range filteredVars {
+		fieldSelect := "COALESCE(CAST(\"%s\" AS TEXT), '') AS \"%s\""
+		if model.IsVector(v.Type) {
+			fieldSelect = "COALESCE(TRIM(CAST(\"%s\" AS TEXT), '{}'), '') AS \"%s\""
+		}
+		varNames = append(varNames, fmt.Sprintf(fieldSelect, v.Key, v.Key))
+		header = append(header, v.Key)
+	}
+	wheres := []string{}
+	paramsFilter := make([]interface{}, 0)
+	wheres, paramsFilter, err = s.builder.FilteredQueryWhere(dataset, wheres, paramsFilter, "", filterParams)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	return varNames, wheres, paramsFilter, header, nil
+}
+
+func (s *Storage) createIndex(storageName string, colName string) error {
+	_, err := s.client.Exec(sqlite.GetIndexStatement(storageName, colName))
+	if err != nil {
+		return errors.Wrapf(err, "unable to create sqlite index")
+	}
+
+	return nil
+}
+
+// CreateIndices generates indices for the suppled fields on the "dataset"_base table
+func (s *Storage) CreateIndices(dataset string, indexFields []string) error {
+	variables, err := s.metadata.FetchVariables(dataset, true, true, true)
+	if err != nil {
+		return err
+	}
+	ds, err := s.metadata.FetchDataset(dataset, false, false, false)
+	if err != nil {
+		return err
+	}
+	mappedVariables := map[string]*model.Variable{}
+	for _, v := range variables {
+		mappedVariables[v.Key] = v
+	}
+	for _, fieldName := range indexFields {
+		field := mappedVariables[fieldName]
+		if field == nil {
+			return errors.Errorf("field '%s' not found in dataset", fieldName)
+		}
+		log.Infof("creating index on %s", field.Key)
+		err := s.createIndex(getBaseTableName(ds.StorageName), field.Key)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsValidDataType checks to see if a specified type is valid for a variable.
+func (s *Storage) IsValidDataType(dataset string, storageName string, varName string, varType string) (bool, error) {
+	exists, err := s.DoesVariableExist(dataset, getBaseTableName(storageName), varName)
+	if err != nil {
+		return false, errors.Wrap(err, "Unable to read existing fields")
+	}
+	if !exists {
+		return false, errors.Errorf("field '%s' not found in existing fields", varName)
+	}
+
+	// casts never fail in sqlite so count the values that are invalid for the type
+	verificationSQL := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE \"%s\" != '' AND NOT (%s);",
+		getBaseTableName(storageName), varName, sqlite.GetValidValueExpression(varType, varName))
+	var invalid int
+	err = s.client.QueryRow(verificationSQL).Scan(&invalid)
+	if err != nil {
+		return false, errors.Wrap(err, "unable to verify data type")
+	}
+
+	return invalid == 0, nil
+}
+
+// SetDataType updates the data type of the specified variable.
+// Multiple simultaneous calls to the function can result in discarded changes.
+func (s *Storage) SetDataType(dataset string, storageName string, varName string, varType string) error {
+	// geometry types need spatial support
+	if model.IsGeoBounds(varType) {
+		return errors.Errorf("geobounds type is not supported by the sqlite storage")
+	}
+
+	// get all existing fields to rebuild the view.
+	fields, err := s.getExistingFields(dataset, getBaseTableName(storageName))
+	if err != nil {
+		return errors.Wrap(err, "Unable to read existing fields")
+	}
+
+	// update field type in lookup.
+	if fields[varName] == nil {
+		return errors.Errorf("field '%s' not found in existing fields", varName)
+	}
+	fields[varName].Type = varType
+
+	// create view based on field lookup.
+	err = s.createView(storageName, fields)
+	if err != nil {
+		return errors.Wrap(err, "Unable to create the new view")
+	}
+
+	return nil
+}
+
+func (s *Storage) createViewFromMetadataFields(storageName string, fields map[string]*model.Variable) error {
+	dbFields := make(map[string]*model.Variable)
+
+	// map the types to db types.
+	for field, v := range fields {
+		dbFields[field] = &model.Variable{
+			Key:              v.Key,
+			OriginalVariable: v.OriginalVariable,
+			Type:             v.Type,
+		}
+	}
+
+	err := s.createView(storageName, dbFields)
+	if err != nil {
+		return errors.Wrap(err, "Unable to create the new view")
+	}
+
+	return nil
+}
+
+func containsField(fields []string, name string) bool {
+	for _, v := range fields {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// AddVariable adds a new variable to the dataset.
+func (s *Storage) AddVariable(dataset string, storageName string, key string, varType string, defaultVal string) error {
+	// check to make sure the column doesnt exist already
+	dbFields, err := s.getDatabaseFields(getBaseTableName(storageName))
+	if err != nil {
+		return errors.Wrap(err, "unable to read database fields")
+	}
+
+	if !containsField(dbFields, key) {
+		if model.IsGeoBounds(varType) {
+			return errors.Errorf("geobounds variable '%s' is not supported by the sqlite storage", key)
+		}
+
+		defaultClause := ""
+		if len(defaultVal) > 0 {
+			defaultClause = fmt.Sprintf(" DEFAULT '%s'", defaultVal)
+		}
+
+		// add the empty column to the base table and the explain table
+		query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN \"%s\" TEXT%s;", getBaseTableName(storageName), key, defaultClause)
+		_, err = s.client.Exec(query)
+		if err != nil {
+			return errors.Wrap(err, "unable to add new column to database table")
+		}
+
+		query = fmt.Sprintf("ALTER TABLE %s ADD COLUMN \"%s\" DOUBLE;", s.getSolutionFeatureWeightTable(storageName), key)
+		_, err = s.client.Exec(query)
+		if err != nil {
+			return errors.Wrap(err, "unable to add new column to database explain table")
+		}
+	}
+
+	// recreate the view with the new column
+	fields, err := s.getExistingFields(dataset, storageName)
+	if err != nil {
+		return errors.Wrap(err, "unable to read existing fields")
+	}
+
+	// need to add the field to the view
+	fields[key] = &model.Variable{
+		Key:              key,
+		OriginalVariable: key,
+		Type:             varType,
+	}
+
+	err = s.createViewFromMetadataFields(storageName, fields)
+	if err != nil {
+		return errors.Wrap(err, "unable to create the new view")
+	}
+
+	return nil
+}
+
+// AddField adds a new field to the data storage. This only adds a new column.
+// It does not add the column to other tables nor does it rebuild a view.
+func (s *Storage) AddField(dataset string, storageName string, varName string, varType string, defaultVal string) error {
+	// check to make sure the column doesnt exist already
+	dbFields, err := s.getDatabaseFields(storageName)
+	if err != nil {
+		return errors.Wrap(err, "unable to read database fields")
+	}
+
+	if containsField(dbFields, varName) {
+		return nil
+	}
+
+	defaultClause := ""
+	if len(defaultVal) > 0 {
+		defaultClause = fmt.Sprintf(" DEFAULT '%s'", defaultVal)
+	}
+	query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN \"%s\" %s%s;", storageName, varName, sqlite.MapD3MTypeToSQLiteType(varType), defaultClause)
+	_, err = s.client.Exec(query)
+	if err != nil {
+		return errors.Wrap(err, "unable to add new column to database table")
+	}
+
+	return nil
+}
+
+// DeleteVariable flags a variable as deleted.
+func (s *Storage) DeleteVariable(dataset string, storageName string, varName string) error {
+	// check if the variable is in the view
+	dbFields, err := s.getDatabaseFields(getBaseTableName(storageName))
+	if err != nil {
+		return errors.Wrap(err, "unable to read database fields")
+	}
+	if !containsField(dbFields, varName) {
+		return nil
+	}
+
+	// recreate the view without the field if it is in it
+	fields, err := s.getExistingFields(dataset, storageName)
+	if err != nil {
+		return errors.Wrap(err, "Unable to read existing fields")
+	}
+
+	if fields[varName] != nil {
+		delete(fields, varName)
+	}
+	err = s.createViewFromMetadataFields(storageName, fields)
+	if err != nil {
+		return errors.Wrap(err, "Unable to create the new view")
+	}
+
+	return nil
+}
+
+// InsertBatch batches the data to insert for increased performance.
+func (s *Storage) InsertBatch(storageName string, varNames []string, inserts [][]interface{}) error {
+	err := sqlite.InsertRows(s.client, storageName, varNames, inserts)
+	if err != nil {
+		return errors.Wrap(err, "unable to insert batches")
+	}
+
+	// update the stats to make sure sqlite runs the best queries possible
+	s.updateStats(storageName)
+
+	return nil
+}
+
+// SetVariableValue updates an entire column to specified value
+func (s *Storage) SetVariableValue(dataset string, storageName string, varName string, value string, filterParams *api.FilterParams) error {
+	wheres := []string{}
+	params := []interface{}{value}
+	wheres, params, err := s.builder.FilteredQueryWhere(dataset, wheres, params, "", filterParams)
+	if err != nil {
+		return err
+	}
+	whereClause := ""
+	if len(wheres) > 0 {
+		whereClause = fmt.Sprintf("WHERE %s", strings.Join(wheres, " AND "))
+	}
+	query := fmt.Sprintf("UPDATE %s SET \"%s\" = ?1 %s;", getBaseTableName(storageName), varName, whereClause)
+	_, err = s.client.Exec(query, params...)
+	if err != nil {
+		return errors.Wrap(err, "Unable to update value stored in the database")
+	}
+
+	return nil
+}
+
+// UpdateVariableBatch batches updates for a variable to increase performance.
+func (s *Storage) UpdateVariableBatch(storageName string, varName string, updates map[string]string) error {
+	return s.updateFromTempTable("", getBaseTableName(storageName), varName, updates, nil)
+}
+
+// UpdateData updates data stored using the d3m index as key, but also allows
+// for filtering in cases where the d3m index is not unique.
+func (s *Storage) UpdateData(dataset string, storageName string, varName string, updates map[string]string, filterParams *api.FilterParams) error {
+	return s.updateFromTempTable(dataset, storageName, varName, updates, filterParams)
+}
+
+// updateFromTempTable inserts the updates to a temp table and then updates
+// the table by joining on the d3m index, all within a single transaction.
+func (s *Storage) updateFromTempTable(dataset string, tableName string, varName string,
+	updates map[string]string, filterParams *api.FilterParams) error {
+	// build the filter structure before starting the transaction since the
+	// filters may need to read metadata
+	wheres := []string{fmt.Sprintf("CAST(t.\"%s\" AS INTEGER) = CAST(b.\"%s\" AS INTEGER)", model.D3MIndexFieldName, model.D3MIndexFieldName)}
+	paramsFilter := make([]interface{}, 0)
+	wheres, paramsFilter, err := s.builder.FilteredQueryWhere(dataset, wheres, paramsFilter, "b", filterParams)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.client.Begin()
+	if err != nil {
+		return errors.Wrap(err, "unable to create transaction")
+	}
+
+	tableNameTmp := fmt.Sprintf("%s_utmp", tableName)
+	dataSQL := fmt.Sprintf("CREATE TEMP TABLE \"%s\" (\"%s\" TEXT NOT NULL, \"%s\" TEXT);",
+		tableNameTmp, model.D3MIndexFieldName, varName)
+	_, err = tx.Exec(dataSQL)
+	if err != nil {
+		_ = tx.Rollback()
+		return errors.Wrap(err, "unable to create temp table")
+	}
+
+	insertSQL := fmt.Sprintf("INSERT INTO \"%s\" VALUES (?1, ?2);", tableNameTmp)
+	for d3mIndex, value := range updates {
+		_, err = tx.Exec(insertSQL, d3mIndex, value)
+		if err != nil {
+			_ = tx.Rollback()
+			return errors.Wrap(err, "unable to insert into temp table")
+		}
+	}
+
+	// run the update
+	updateSQL := fmt.Sprintf("UPDATE \"%s\" AS b SET \"%s\" = t.\"%s\" FROM \"%s\" AS t WHERE %s;",
+		tableName, varName, varName, tableNameTmp, strings.Join(wheres, " AND "))
+	_, err = tx.Exec(updateSQL, paramsFilter...)
+	if err != nil {
+		_ = tx.Rollback()
+		return errors.Wrap(err, "unable to update base data")
+	}
+
+	_, err = tx.Exec(fmt.Sprintf("DROP TABLE \"%s\";", tableNameTmp))
+	if err != nil {
+		_ = tx.Rollback()
+		return errors.Wrap(err, "unable to drop temp table")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "unable to commit bulk update")
+	}
+
+	return nil
+}
+
+func getJoinSQL(join *joinDefinition, inner bool) string {
+	joinType := "INNER JOIN"
+	if !inner {
+		joinType = "LEFT OUTER JOIN"
+	}
+	return fmt.Sprintf("%s %s AS %s ON %s.\"%s\" = %s.\"%s\"",
+		joinType, join.joinTableName, join.joinAlias, join.joinAlias,
+		join.joinColumn, join.baseAlias, join.baseColumn)
+}
@@ -0,0 +1,440 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/model/storage/sqlbuilder"
+)
+
+// DateTimeField defines behaviour for the date time field type. Values are
+// stored as text and converted to epoch seconds when summarized.
+type DateTimeField struct {
+	BasicField
+	subSelect func() string
+}
+
+// NewDateTimeField creates a new field for date time types.
+func NewDateTimeField(storage *Storage, datasetName string, datasetStorageName string, key string, label string, typ string, count string) *DateTimeField {
+	count = sqlbuilder.CountSQL(count)
+
+	field := &DateTimeField{
+		BasicField: BasicField{
+			Storage:            storage,
+			DatasetName:        datasetName,
+			DatasetStorageName: datasetStorageName,
+			Key:                key,
+			Label:              label,
+			Type:               typ,
+			Count:              count,
+		},
+	}
+
+	return field
+}
+
+// NewDateTimeFieldSubSelect creates a new field for date time types
+// and specifies a sub select query to pull the raw data.
+func NewDateTimeFieldSubSelect(storage *Storage, datasetName string, datasetStorageName string, key string, label string, typ string, count string, fieldSubSelect func() string) *DateTimeField {
+	count = sqlbuilder.CountSQL(count)
+
+	field := &DateTimeField{
+		BasicField: BasicField{
+			Storage:            storage,
+			DatasetName:        datasetName,
+			DatasetStorageName: datasetStorageName,
+			Key:                key,
+			Label:              label,
+			Type:               typ,
+			Count:              count,
+		},
+		subSelect: fieldSubSelect,
+	}
+
+	return field
+}
+
+// FetchSummaryData pulls summary data from the database and builds a histogram.
+func (f *DateTimeField) FetchSummaryData(resultURI string, filterParams *api.FilterParams, extrema *api.Extrema, mode api.SummaryMode) (*api.VariableSummary, error) {
+
+	var baseline *api.Histogram
+	var filtered *api.Histogram
+	var err error
+
+	if resultURI == "" {
+		baseline, err = f.fetchHistogram(api.GetBaselineFilter(filterParams), api.MaxNumBuckets)
+		if err != nil {
+			return nil, err
+		}
+		if !filterParams.IsEmpty(true) {
+			filtered, err = f.fetchHistogram(filterParams, api.MaxNumBuckets)
+			if err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		baseline, err = f.fetchHistogramByResult(resultURI, api.GetBaselineFilter(filterParams), extrema, api.MaxNumBuckets)
+		if err != nil {
+			return nil, err
+		}
+		if !filterParams.IsEmpty(true) {
+			filtered, err = f.fetchHistogramByResult(resultURI, filterParams, extrema, api.MaxNumBuckets)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &api.VariableSummary{
+		Label:    f.Label,
+		Key:      f.Key,
+		Type:     model.NumericalType,
+		VarType:  f.Type,
+		Baseline: baseline,
+		Filtered: filtered,
+	}, nil
+}
+
+func (f *DateTimeField) fetchHistogram(filterParams *api.FilterParams, numBuckets int) (*api.Histogram, error) {
+	return f.fetchHistogramWithJoins(filterParams, numBuckets, nil, []string{}, []interface{}{})
+}
+
+func (f *DateTimeField) fetchHistogramWithJoins(filterParams *api.FilterParams, numBuckets int, joins []*joinDefinition, wheres []string, params []interface{}) (*api.Histogram, error) {
+	fromClause := f.getFromClause(true)
+
+	// create the filter for the query.
+	wheres, params, err := f.Storage.builder.FilteredQueryWhere(f.GetDatasetName(), wheres, params, "", filterParams)
+	if err != nil {
+		return nil, err
+	}
+	filterWheres := wheres
+	wheres = append(wheres, f.getDefaultFilter(true))
+
+	// need the extrema to calculate the histogram interval
+	extrema, err := f.fetchExtrema()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch variable extrema for summary")
+	}
+
+	// for each returned aggregation, create a histogram aggregation. Bucket
+	// size is derived from the min/max and desired bucket count.
+	agg := f.getHistogramAgg(extrema, numBuckets, "")
+
+	joinSQL := createJoinStatements(joins)
+
+	// Create the complete query string.
+	query := f.Storage.builder.Histogram(agg, f.Count, fmt.Sprintf("%s %s", fromClause, joinSQL), wheres)
+
+	// execute the sqlite query
+	res, err := f.Storage.client.Query(query, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch histograms for variable summaries from sqlite")
+	}
+	histogram, err := f.parseHistogram(res, extrema, numBuckets)
+	res.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	histogram.Missing, err = f.fetchMissingBucket(fmt.Sprintf("%s %s", fromClause, joinSQL), filterWheres, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return histogram, nil
+}
+
+func (f *DateTimeField) fetchHistogramByResult(resultURI string, filterParams *api.FilterParams, extrema *api.Extrema, numBuckets int) (*api.Histogram, error) {
+	fromClause := f.getFromClause(false)
+
+	// get filter where / params
+	wheres, params, err := f.Storage.buildResultQueryFilters(f.GetDatasetName(), f.DatasetStorageName, resultURI, filterParams, baseTableAlias)
+	if err != nil {
+		return nil, err
+	}
+	filterWheres := wheres
+	wheres = append(wheres, f.getDefaultFilter(true))
+
+	params = append(params, resultURI)
+	wheres = append([]string{f.Storage.builder.ResultWhere(len(params))}, wheres...)
+
+	// need the extrema to calculate the histogram interval
+	if extrema == nil {
+		extrema, err = f.fetchExtremaByURI(resultURI)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to fetch variable extrema for summary")
+		}
+	} else {
+		extrema.Key = f.Key
+		extrema.Type = f.Type
+	}
+	// for each returned aggregation, create a histogram aggregation. Bucket
+	// size is derived from the min/max and desired bucket count.
+	agg := f.getHistogramAgg(extrema, numBuckets, baseTableAlias)
+
+	// Create the complete query string.
+	fromResult := sqlbuilder.DataResultJoin(fromClause, f.Storage.getResultTable(f.DatasetStorageName), baseTableAlias)
+	query := f.Storage.builder.Histogram(agg, f.Count, fromResult, wheres)
+
+	// execute the sqlite query
+	res, err := f.Storage.client.Query(query, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch histograms for variable summaries from sqlite")
+	}
+	histogram, err := f.parseHistogram(res, extrema, numBuckets)
+	res.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	missingWheres := append([]string{f.Storage.builder.ResultWhere(len(params))}, filterWheres...)
+	histogram.Missing, err = f.fetchMissingBucket(fromResult, missingWheres, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return histogram, nil
+}
+
+func (f *DateTimeField) fetchExtrema() (*api.Extrema, error) {
+	fromClause := f.getFromClause(true)
+	// add min / max aggregation
+	aggQuery := f.getMinMaxAggsQuery("")
+
+	// create a query that does min and max aggregations for each variable
+	queryString := f.Storage.builder.Select(aggQuery, fromClause, []string{f.getDefaultFilter(true)})
+
+	return f.parseExtrema(f.Storage.client.QueryRow(queryString))
+}
+
+func (f *DateTimeField) getHistogramAgg(extrema *api.Extrema, numBuckets int, alias string) *sqlbuilder.HistogramAgg {
+	interval := extrema.GetBucketInterval(numBuckets)
+
+	field := f.Storage.builder.Dialect.Epoch(sqlbuilder.FullName(alias, extrema.Key))
+	return f.Storage.builder.HistogramAgg(extrema.Key, field, float64(int(extrema.Min)), float64(int(extrema.Max)),
+		float64(int(interval)), extrema.GetBucketCount(numBuckets))
+}
+
+func (f *DateTimeField) parseHistogram(rows *sql.Rows, extrema *api.Extrema, numBuckets int) (*api.Histogram, error) {
+	// get histogram agg name
+	histogramAggName := api.HistogramAggPrefix + extrema.Key
+
+	// Parse bucket results.
+	interval := extrema.GetBucketInterval(numBuckets)
+
+	buckets := make([]*api.Bucket, extrema.GetBucketCount(numBuckets))
+	key := extrema.Min
+	for i := 0; i < len(buckets); i++ {
+		keyString := ""
+		if model.IsFloatingPoint(extrema.Type) {
+			keyString = fmt.Sprintf("%f", key)
+		} else {
+			keyString = strconv.Itoa(int(key))
+		}
+
+		buckets[i] = &api.Bucket{
+			Key:   keyString,
+			Count: 0,
+		}
+
+		key = key + interval
+	}
+
+	err := parseHistogramBuckets(rows, buckets, histogramAggName)
+	if err != nil {
+		return nil, err
+	}
+
+	// assign histogram attributes
+	return &api.Histogram{
+		Extrema: extrema,
+		Buckets: buckets,
+	}, nil
+}
+
+func (f *DateTimeField) parseExtrema(row *sql.Row) (*api.Extrema, error) {
+	var minValue *float64
+	var maxValue *float64
+	err := row.Scan(&minValue, &maxValue)
+	if err != nil {
+		return nil, errors.Wrap(err, "no min / max aggregation found")
+	}
+	// check values exist
+	if minValue == nil || maxValue == nil {
+		return nil, errors.Errorf("no min / max aggregation values found")
+	}
+	// assign attributes
+	return &api.Extrema{
+		Key:  f.Key,
+		Type: f.Type,
+		Min:  *minValue,
+		Max:  *maxValue,
+	}, nil
+}
+
+func (f *DateTimeField) getMinMaxAggsQuery(alias string) string {
+	fieldTyped := f.Storage.builder.Dialect.Epoch(sqlbuilder.FullName(alias, f.Key))
+
+	return f.Storage.builder.MinMax(f.Key, fieldTyped)
+}
+
+func (f *DateTimeField) fetchExtremaByURI(resultURI string) (*api.Extrema, error) {
+	fromClause := f.getFromClause(false)
+
+	// add min / max aggregation
+	aggQuery := f.getMinMaxAggsQuery(baseTableAlias)
+
+	// create a query that does min and max aggregations for each variable
+	fromResult := sqlbuilder.DataResultJoin(fromClause, f.Storage.getResultTable(f.DatasetStorageName), baseTableAlias)
+	queryString := f.Storage.builder.Select(aggQuery, fromResult, []string{f.Storage.builder.ResultWhere(1), f.getDefaultFilter(true)})
+
+	return f.parseExtrema(f.Storage.client.QueryRow(queryString, resultURI))
+}
+
+// FetchPredictedSummaryData pulls data from the result table and builds
+// the numerical histogram for the field.
+func (f *DateTimeField) FetchPredictedSummaryData(resultURI string, datasetResult string, filterParams *api.FilterParams, extrema *api.Extrema, mode api.SummaryMode) (*api.VariableSummary, error) {
+	var baseline *api.Histogram
+	var filtered *api.Histogram
+	var err error
+
+	baseline, err = f.fetchPredictedSummaryData(resultURI, datasetResult, nil, extrema, api.MaxNumBuckets)
+	if err != nil {
+		return nil, err
+	}
+	if !filterParams.IsEmpty(true) {
+		filtered, err = f.fetchPredictedSummaryData(resultURI, datasetResult, filterParams, extrema, api.MaxNumBuckets)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &api.VariableSummary{
+		Label:    f.Label,
+		Key:      f.Key,
+		Type:     model.NumericalType,
+		VarType:  f.Type,
+		Baseline: baseline,
+		Filtered: filtered,
+	}, nil
+}
+
+func (f *DateTimeField) fetchPredictedSummaryData(resultURI string, datasetResult string, filterParams *api.FilterParams, extrema *api.Extrema, numBuckets int) (*api.Histogram, error) {
+	resultVariable := &model.Variable{
+		Key:  "value",
+		Type: model.StringType,
+	}
+
+	// need the extrema to calculate the histogram interval
+	var err error
+	if extrema == nil {
+		extrema, err = f.fetchResultsExtrema(resultURI, datasetResult, resultVariable)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to fetch result variable extrema for summary")
+		}
+	} else {
+		extrema.Key = f.Key
+		extrema.Type = f.Type
+	}
+	// for each returned aggregation, create a histogram aggregation. Bucket
+	// size is derived from the min/max and desired bucket count.
+	agg := f.getResultHistogramAgg(extrema, resultVariable, numBuckets)
+
+	// get filter where / params
+	wheres, params, err := f.Storage.buildResultQueryFilters(f.GetDatasetName(), f.DatasetStorageName, resultURI, filterParams, baseTableAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	wheres = append(wheres, f.Storage.builder.ResultTargetWhere(len(params)+1))
+	params = append(params, resultURI, f.Key)
+
+	// Create the complete query string.
+	fromResult := sqlbuilder.DataResultJoin(fmt.Sprintf("%s data", f.DatasetStorageName), datasetResult, "data")
+	query := f.Storage.builder.Histogram(agg, f.Count, fromResult, wheres)
+
+	// execute the sqlite query
+	res, err := f.Storage.client.Query(query, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch histograms for result variable summaries from sqlite")
+	}
+	defer res.Close()
+
+	return f.parseHistogram(res, extrema, numBuckets)
+}
+
+func (f *DateTimeField) getResultMinMaxAggsQuery(resultVariable *model.Variable) string {
+	// predicted date times are stored as epoch values.
+	fieldTyped := f.Storage.builder.Dialect.Numeric(fmt.Sprintf("\"%s\"", resultVariable.Key))
+
+	return f.Storage.builder.MinMax(resultVariable.Key, fieldTyped)
+}
+
+func (f *DateTimeField) getResultHistogramAgg(extrema *api.Extrema, resultVariable *model.Variable, numBuckets int) *sqlbuilder.HistogramAgg {
+	// compute the bucket interval for the histogram
+	interval := extrema.GetBucketInterval(numBuckets)
+	rounded := extrema.GetBucketMinMax(numBuckets)
+
+	// predicted date times are stored as epoch values.
+	fieldTyped := f.Storage.builder.Dialect.Numeric(fmt.Sprintf("result.\"%s\"", resultVariable.Key))
+
+	return f.Storage.builder.HistogramAgg(extrema.Key, fieldTyped, float64(int(rounded.Min)), float64(int(rounded.Max)),
+		float64(int(interval)), extrema.GetBucketCount(numBuckets))
+}
+
+func (f *DateTimeField) fetchResultsExtrema(resultURI string, dataset string, resultVariable *model.Variable) (*api.Extrema, error) {
+	// add min / max aggregation
+	aggQuery := f.getResultMinMaxAggsQuery(resultVariable)
+
+	// create a query that does min and max aggregations for each variable
+	wheres := []string{
+		fmt.Sprintf("result_id = %s", f.Storage.builder.Param(1)),
+		fmt.Sprintf("target = %s", f.Storage.builder.Param(2)),
+	}
+	queryString := f.Storage.builder.Select(aggQuery, dataset, wheres)
+
+	return f.parseExtrema(f.Storage.client.QueryRow(queryString, resultURI, f.Key))
+}
+
+func (f *DateTimeField) getFromClause(alias bool) string {
+	fromClause := fmt.Sprintf("%s AS %s", f.DatasetStorageName, baseTableAlias)
+	if f.subSelect != nil {
+		fromClause = f.subSelect()
+		if alias {
+			fromClause = fmt.Sprintf("%s AS nested INNER JOIN %s AS %s on nested.\"%s\" = %s.\"%s\"",
+				fromClause, f.DatasetStorageName, baseTableAlias, model.D3MIndexFieldName, baseTableAlias, model.D3MIndexFieldName)
+		} else {
+			fromClause = fmt.Sprintf("%s AS %s", fromClause, baseTableAlias)
+		}
+	}
+
+	return fromClause
+}
+
+func (f *DateTimeField) fetchExtremaStorage() (*api.Extrema, error) {
+	// add min / max aggregation
+	aggQuery := f.getMinMaxAggsQuery("")
+
+	// create a query that does min and max aggregations for each variable
+	queryString := f.Storage.builder.Select(aggQuery, f.GetDatasetStorageName(), nil)
+
+	return f.parseExtrema(f.Storage.client.QueryRow(queryString))
+}
@@ -0,0 +1,302 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/model/storage/sqlbuilder"
+)
+
+func getVariableByKey(key string, variables []*model.Variable) *model.Variable {
+	for _, variable := range variables {
+		if variable.IsGrouping() && variable.Grouping.GetIDCol() == key {
+			return variable
+		}
+		if variable.Key == key && !variable.HasRole(model.VarDistilRoleGrouping) {
+			return variable
+		}
+	}
+	return nil
+}
+
+// parseValue converts a raw database value to the type expected by the
+// client. Vectors are stored as comma separated text.
+func parseValue(value interface{}, typ string) interface{} {
+	if (model.IsVector(typ) || model.IsList(typ)) && value != nil {
+		text := toString(value)
+		parsed := []float64{}
+		if text == "" {
+			return parsed
+		}
+		for _, field := range strings.Split(strings.Trim(text, "{}[]"), ",") {
+			f, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil {
+				return text
+			}
+			parsed = append(parsed, f)
+		}
+		return parsed
+	}
+
+	return value
+}
+
+func (s *Storage) parseFilteredData(dataset string, filterVariables []*model.Variable, numRows int, includeGroupingCol bool, rows *sql.Rows) (*api.FilteredData, error) {
+	result := &api.FilteredData{
+		NumRows: numRows,
+		Values:  make([][]*api.FilteredDataValue, 0),
+	}
+
+	if rows != nil {
+		fields, err := rows.Columns()
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read columns from sqlite")
+		}
+
+		// Parse the columns.  We can potentially have multiple variables map to the same result
+		// (timeries variables that use the same grouping column) so we iterate over the filter variable
+		// list to find any that map.
+		columns := map[string]*api.Column{}
+		fieldIndexMap := []int{}
+		fieldTypes := []string{}
+		for _, variable := range filterVariables {
+			// loop through the filter vars and find the key associated with each
+			for fieldIdx, fieldKey := range fields {
+				if variable.IsGrouping() && variable.Grouping.GetIDCol() == fieldKey {
+					columns[variable.Key] = &api.Column{
+						Key:   variable.Key,
+						Label: variable.DisplayName,
+						Type:  variable.Type,
+						Index: len(columns),
+					}
+					fieldIndexMap = append(fieldIndexMap, fieldIdx)
+					fieldTypes = append(fieldTypes, variable.Type)
+				} else if fieldKey == variable.Key && (includeGroupingCol || !variable.HasRole(model.VarDistilRoleGrouping)) {
+					columns[variable.Key] = &api.Column{
+						Key:   variable.Key,
+						Label: variable.DisplayName,
+						Type:  variable.Type,
+						Index: len(columns),
+					}
+					fieldIndexMap = append(fieldIndexMap, fieldIdx)
+					fieldTypes = append(fieldTypes, variable.Type)
+				}
+			}
+		}
+		result.Columns = columns
+
+		// Parse the row data.
+		for rows.Next() {
+			columnValues, err := scanValues(rows, len(fields))
+			if err != nil {
+				return nil, err
+			}
+
+			// filtered data has no weights associated with it
+			// we use the field index map to ensure that the column structure and row data structures
+			// align
+			weightedValues := make([]*api.FilteredDataValue, len(fieldIndexMap))
+			for colIdx, fieldIdx := range fieldIndexMap {
+				weightedValues[colIdx] = &api.FilteredDataValue{
+					Value: parseValue(columnValues[fieldIdx], fieldTypes[colIdx]),
+				}
+			}
+
+			result.Values = append(result.Values, weightedValues)
+		}
+		err = rows.Err()
+		if err != nil {
+			return nil, errors.Wrapf(err, "error reading data from sqlite")
+		}
+	} else {
+		result.Columns = map[string]*api.Column{}
+	}
+
+	return result, nil
+}
+
+// resultColumns resolves the result target columns for the shared filter
+// builder.
+type resultColumns struct {
+	storage *Storage
+}
+
+func (c *resultColumns) ResultTargetName(storageName string, resultURI string) (string, error) {
+	return c.storage.getResultTargetName(c.storage.getResultTable(storageName), resultURI)
+}
+
+func (c *resultColumns) ErrorTargetName(errorKey string) (string, error) {
+	// Error keys are a string of the form <result_uuid>:error.  We need to pull the solution ID out so we can find the name of the target var.
+	resultUUID := api.StripKeySuffix(errorKey)
+	request, err := c.storage.FetchRequestByResultUUID(resultUUID)
+	if err != nil {
+		return "", err
+	}
+
+	// Fetch the target variable.  For grouped variables, the target will be one of the component
+	// variables.
+	targetVariable, err := c.storage.getResultTargetVariable(request.Dataset, request.TargetFeature())
+	if err != nil {
+		return "", err
+	}
+	if targetVariable.IsGrouping() && model.IsTimeSeries(targetVariable.Grouping.GetType()) {
+		tsg := targetVariable.Grouping.(*model.TimeseriesGrouping)
+		targetVariable, err = c.storage.getResultTargetVariable(request.Dataset, tsg.YCol)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return targetVariable.Key, nil
+}
+
+func (s *Storage) buildFilteredResultQueryField(variables []*model.Variable, targetVariable *model.Variable, filterVariables []string) ([]string, []string, error) {
+
+	distincts := make([]string, 0)
+	fields := make([]string, 0)
+	groupingCols := map[string]bool{}
+	for _, variable := range api.GetFilterVariables(filterVariables, variables) {
+		if variable.IsGrouping() {
+			continue
+		}
+
+		if strings.Compare(targetVariable.Key, variable.Key) != 0 {
+
+			if variable.HasRole(model.VarDistilRoleGrouping) && !groupingCols[variable.Key] {
+				groupingCols[variable.Key] = true // don't duplicate columns in our distinct
+				distincts = append(distincts, fmt.Sprintf("\"%s\"", variable.Key))
+			}
+
+			fields = append(fields, fmt.Sprintf("\"%s\"", variable.Key))
+		}
+	}
+	fields = append(fields, fmt.Sprintf("\"%s\"", model.D3MIndexFieldName))
+	return distincts, fields, nil
+}
+
+func (s *Storage) buildErrorResultWhere(wheres []string, params []interface{}, residualFilter model.FilterObject) ([]string, []interface{}, error) {
+	targetName, err := (&resultColumns{s}).ErrorTargetName(residualFilter.List[0].Key)
+	if err != nil {
+		return nil, nil, err
+	}
+	wheres, params = s.builder.ErrorWhere(wheres, params, "", targetName, residualFilter)
+	return wheres, params, nil
+}
+
+func (s *Storage) buildResultQueryFilters(dataset string, storageName string, resultURI string, filterParams *api.FilterParams, alias string) ([]string, []interface{}, error) {
+	return s.builder.ResultQueryFilters(&resultColumns{s}, dataset, storageName, resultURI, filterParams, alias)
+}
+
+// FetchNumRows pulls the number of rows in the table.
+func (s *Storage) FetchNumRows(storageName string, variables []*model.Variable) (int, error) {
+	return s.fetchNumRowsJoined(storageName, variables, nil, nil, nil)
+}
+
+// FetchNumRowsFiltered pulls the number of filtered rows in the table.
+func (s *Storage) FetchNumRowsFiltered(storageName string, variables []*model.Variable, filters []string, params []interface{}) (int, error) {
+	return s.fetchNumRowsJoined(storageName, variables, filters, params, nil)
+}
+
+// fetchNumRowsJoined pulls the number of rows in the table.
+func (s *Storage) fetchNumRowsJoined(storageName string, variables []*model.Variable, filters []string, params []interface{}, join *joinDefinition) (int, error) {
+	joinSQL := ""
+	tableAlias := "base_data"
+	if join != nil {
+		tableAlias = join.baseAlias
+		joinSQL = getJoinSQL(join, true)
+	}
+
+	query := s.builder.NumRows(storageName, variables, tableAlias, joinSQL, filters)
+	var numRows int
+	err := s.client.QueryRow(query, params...).Scan(&numRows)
+	if err != nil {
+		return -1, errors.Wrap(err, "sqlite row query failed")
+	}
+	return numRows, nil
+}
+
+// FetchData creates a sqlite query to fetch a set of rows.  Applies filters to restrict the
+// results to a user selected set of fields, with rows further filtered based on allowed ranges and
+// categories.
+func (s *Storage) FetchData(dataset string, storageName string, filterParams *api.FilterParams, includeGroupingCol bool, orderByVar *model.Variable) (*api.FilteredData, error) {
+	variables, err := s.metadata.FetchVariables(dataset, true, true, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not pull variables from metadata storage")
+	}
+
+	numRows, err := s.FetchNumRows(storageName, variables)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not pull num rows")
+	}
+
+	selectStatement := sqlbuilder.SelectStatement(variables, filterParams.Variables)
+	// standard order by
+	orderByClause := s.builder.Dialect.RandomOrder(fmt.Sprintf("\"%s\"", model.D3MIndexFieldName))
+	if orderByVar != nil {
+		// if exist change order by clause
+		orderByClause = "\"" + orderByVar.HeaderName + "\" DESC"
+		// check if the order by variable exists in the supplied list of vars
+		existInFilter := api.GetFilterVariables(filterParams.Variables, []*model.Variable{orderByVar})
+		if len(existInFilter) == 0 {
+			// if it does not exist add it for the inner query (in order to sort from the outer query)
+			filterParams.Variables = append(filterParams.Variables, orderByVar.HeaderName)
+		}
+	}
+	fields, distincts := sqlbuilder.FilteredQueryField(variables, filterParams.Variables, !includeGroupingCol)
+
+	wheres := make([]string, 0)
+	params := make([]interface{}, 0)
+	wheres, params, err = s.builder.FilteredQueryWhere(dataset, wheres, params, "", filterParams)
+	if err != nil {
+		return nil, err
+	}
+	query := s.builder.FilteredData(storageName, fields, distincts, selectStatement, wheres, orderByClause, filterParams.Size)
+
+	// get the list of variables that are include by our current filter state
+	filterVariables, err := s.metadata.FetchVariablesByName(dataset, filterParams.Variables, true, true, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// execute the sqlite query
+	res, err := s.client.Query(query, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "sqlite filtered data query failed")
+	}
+
+	// parse the result
+	filteredData, err := s.parseFilteredData(dataset, filterVariables, numRows, includeGroupingCol, res)
+	res.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	// Add the num filtered rows
+	numRowsFiltered, err := s.FetchNumRowsFiltered(storageName, variables, wheres, params)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not pull filtered num rows")
+	}
+	filteredData.NumRowsFiltered = numRowsFiltered
+
+	return filteredData, nil
+}
@@ -0,0 +1,129 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/model/storage/document"
+	"github.com/uncharted-distil/distil/api/sqlite"
+)
+
+const (
+	// MetadataProvenance is the provenance of datasets stored in sqlite.
+	MetadataProvenance = "sqlite"
+)
+
+var (
+	searchTermRegex = regexp.MustCompile(`[^\pL\pN]+`)
+)
+
+// MetadataStorage accesses the dataset metadata and exported models stored
+// as JSON documents in sqlite.
+type MetadataStorage struct {
+	*document.VariableStorage
+	client *sql.DB
+}
+
+// NewMetadataStorage returns a constructor for a metadata storage.
+func NewMetadataStorage(clientCtor sqlite.ClientCtor) api.MetadataStorageCtor {
+	return func() (api.MetadataStorage, error) {
+		return newMetadataStorage(clientCtor)
+	}
+}
+
+// NewExportedModelStorage returns a constructor for an exported model storage.
+func NewExportedModelStorage(clientCtor sqlite.ClientCtor) api.ExportedModelStorageCtor {
+	return func() (api.ExportedModelStorage, error) {
+		return newMetadataStorage(clientCtor)
+	}
+}
+
+// NewMetadataStorageClient returns a metadata storage client. It is used when
+// the sqlite specific functionality, such as initialization, is needed.
+func NewMetadataStorageClient(clientCtor sqlite.ClientCtor) (*MetadataStorage, error) {
+	return newMetadataStorage(clientCtor)
+}
+
+func newMetadataStorage(clientCtor sqlite.ClientCtor) (*MetadataStorage, error) {
+	client, err := clientCtor()
+	if err != nil {
+		return nil, err
+	}
+
+	storage := &MetadataStorage{
+		client: client,
+	}
+	storage.VariableStorage = document.NewVariableStorage(storage)
+
+	return storage, nil
+}
+
+// InitializeMetadataStorage creates the metadata tables if they do not exist.
+func (s *MetadataStorage) InitializeMetadataStorage() error {
+	return sqlite.CreateMetadataStorageTables(s.client)
+}
+
+// searchTerms splits the search string into the lower case words to match.
+func searchTerms(terms string) []string {
+	words := []string{}
+	for _, w := range searchTermRegex.Split(strings.ToLower(terms), -1) {
+		if w != "" {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// searchText normalizes the text stored for searching so that it can be
+// matched against the search terms.
+func searchText(fields []string) string {
+	return strings.Join(searchTerms(strings.Join(fields, " ")), " ")
+}
+
+// searchWhere builds a clause matching documents containing any of the
+// terms, including as part of a word. Sqlite has no full text ranking so the
+// matches are ranked by the caller using searchRank.
+func searchWhere(terms []string, params []interface{}) (string, []interface{}) {
+	clauses := make([]string, len(terms))
+	for i, term := range terms {
+		params = append(params, "%"+term+"%")
+		clauses[i] = fmt.Sprintf("search LIKE ?%d", len(params))
+	}
+	return "(" + strings.Join(clauses, " OR ") + ")", params
+}
+
+// searchRank orders the matches by the number of terms they contain, keeping
+// the existing order for equal ranks.
+func searchRank(texts []string, terms []string) []int {
+	counts := make([]int, len(texts))
+	order := make([]int, len(texts))
+	for i, text := range texts {
+		order[i] = i
+		for _, term := range terms {
+			counts[i] += strings.Count(text, term)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return counts[order[a]] > counts[order[b]]
+	})
+	return order
+}
@@ -0,0 +1,275 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/metadata"
+	"github.com/uncharted-distil/distil-compute/model"
+
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/model/storage/document"
+	"github.com/uncharted-distil/distil/api/postgres"
+)
+
+func datasetSearchText(dataset *api.Dataset) string {
+	return searchText(document.DatasetSearchFields(dataset))
+}
+
+// fetchDocuments runs a query returning the document and search text columns.
+// When search terms are supplied the documents are ordered by relevance.
+func (s *MetadataStorage) fetchDocuments(query string, terms []string, params ...interface{}) ([][]byte, error) {
+	rows, err := s.client.Query(query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	documents := [][]byte{}
+	texts := []string{}
+	for rows.Next() {
+		var raw string
+		var text sql.NullString
+		err = rows.Scan(&raw, &text)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, []byte(raw))
+		texts = append(texts, text.String)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(terms) == 0 {
+		return documents, nil
+	}
+
+	ranked := make([][]byte, len(documents))
+	for i, index := range searchRank(texts, terms) {
+		ranked[i] = documents[index]
+	}
+	return ranked, nil
+}
+
+func (s *MetadataStorage) queryDatasets(query string, terms []string, includeIndex bool, includeMeta bool, includeSystemData bool, params ...interface{}) ([]*api.Dataset, error) {
+	documents, err := s.fetchDocuments(query, terms, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull dataset metadata from SQLite")
+	}
+
+	datasets := []*api.Dataset{}
+	for _, raw := range documents {
+		dataset, err := document.ParseDataset(raw, MetadataProvenance)
+		if err != nil {
+			return nil, err
+		}
+		dataset.Variables = document.FilterVariables(dataset.Variables, includeIndex, includeMeta, includeSystemData)
+		datasets = append(datasets, dataset)
+	}
+
+	return datasets, nil
+}
+
+// FetchDatasetDocument returns the stored dataset, including deleted
+// datasets and all of its variables. It returns nil if the dataset does not
+// exist.
+func (s *MetadataStorage) FetchDatasetDocument(dataset string) (*api.Dataset, error) {
+	query := fmt.Sprintf("SELECT document FROM %s WHERE dataset_id = ?1;", postgres.DatasetMetadataTableName)
+
+	var raw string
+	err := s.client.QueryRow(query, dataset).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull dataset metadata from SQLite")
+	}
+
+	return document.ParseDataset([]byte(raw), MetadataProvenance)
+}
+
+// UpdateDatasetDocument applies an update to the stored dataset. The read and
+// the write happen in one transaction, which holds the single sqlite
+// connection, so concurrent updates are applied one after the other rather
+// than overwriting each other.
+func (s *MetadataStorage) UpdateDatasetDocument(dataset string, update func(ds *api.Dataset) error) error {
+	tx, err := s.client.Begin()
+	if err != nil {
+		return errors.Wrap(err, "unable to begin transaction")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := fmt.Sprintf("SELECT document FROM %s WHERE dataset_id = ?1;", postgres.DatasetMetadataTableName)
+	var raw string
+	err = tx.QueryRow(query, dataset).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return errors.Errorf("dataset '%s' not found", dataset)
+	} else if err != nil {
+		return errors.Wrap(err, "Unable to pull dataset metadata from SQLite")
+	}
+	ds, err := document.ParseDataset([]byte(raw), MetadataProvenance)
+	if err != nil {
+		return err
+	}
+
+	err = update(ds)
+	if err != nil {
+		return err
+	}
+
+	query, params, err := getPersistDatasetSQL(ds)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(query, params...)
+	if err != nil {
+		return errors.Wrapf(err, "failed to persist dataset metadata to SQLite")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "unable to commit transaction")
+	}
+	return nil
+}
+
+// ImportDataset is not supported (sqlite datasets are already ingested).
+func (s *MetadataStorage) ImportDataset(id string, uri string) (string, error) {
+	return "", errors.Errorf("Not Supported")
+}
+
+// CloneDataset stores a copy of an existing dataset's metadata under a new
+// id.
+func (s *MetadataStorage) CloneDataset(dataset string, datasetNew string, storageNameNew string, folderNew string) error {
+	ds, err := s.FetchDataset(dataset, true, true, false)
+	if err != nil {
+		return err
+	}
+	if ds == nil {
+		return errors.Errorf("dataset '%s' not found", dataset)
+	}
+
+	document.CloneDataset(ds, datasetNew, storageNameNew, folderNew)
+
+	return s.UpdateDataset(ds)
+}
+
+// UpdateDataset inserts or replaces the dataset metadata document.
+func (s *MetadataStorage) UpdateDataset(dataset *api.Dataset) error {
+	query, params, err := getPersistDatasetSQL(dataset)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Exec(query, params...)
+
+	return errors.Wrapf(err, "failed to persist dataset metadata to SQLite")
+}
+
+func getPersistDatasetSQL(dataset *api.Dataset) (string, []interface{}, error) {
+	raw, err := json.Marshal(dataset)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to marshal dataset document")
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (dataset_id, type, deleted, document, search) VALUES (?1, ?2, ?3, ?4, ?5)
+		ON CONFLICT (dataset_id) DO UPDATE SET type = excluded.type, deleted = excluded.deleted, document = excluded.document, search = excluded.search;`,
+		postgres.DatasetMetadataTableName)
+
+	return query, []interface{}{dataset.ID, string(dataset.Type), dataset.Deleted, string(raw), datasetSearchText(dataset)}, nil
+}
+
+// IngestDataset stores the metadata of a newly ingested dataset.
+func (s *MetadataStorage) IngestDataset(datasetSource metadata.DatasetSource, meta *model.Metadata) error {
+	dataset, err := document.NewIngestedDataset(datasetSource, meta, MetadataProvenance)
+	if err != nil {
+		return err
+	}
+
+	return s.UpdateDataset(dataset)
+}
+
+// DeleteDataset deletes a dataset from sqlite.
+func (s *MetadataStorage) DeleteDataset(dataset string, softDelete bool) error {
+	if !softDelete {
+		query := fmt.Sprintf("DELETE FROM %s WHERE dataset_id = ?1;", postgres.DatasetMetadataTableName)
+		_, err := s.client.Exec(query, dataset)
+		return errors.Wrapf(err, "failed to delete dataset metadata from SQLite")
+	}
+
+	// update the deleted flag
+	return s.UpdateDatasetDocument(dataset, func(ds *api.Dataset) error {
+		ds.Deleted = true
+		return nil
+	})
+}
+
+// FetchDatasets returns all datasets that are not deleted, excluding
+// inference datasets.
+func (s *MetadataStorage) FetchDatasets(includeIndex bool, includeMeta bool, includeSystemData bool) ([]*api.Dataset, error) {
+	query := fmt.Sprintf("SELECT document, search FROM %s WHERE NOT deleted AND type <> ?1 ORDER BY dataset_id;", postgres.DatasetMetadataTableName)
+
+	return s.queryDatasets(query, nil, includeIndex, includeMeta, includeSystemData, string(api.DatasetTypeInference))
+}
+
+// FetchDataset returns a dataset that is not deleted, or nil if there is no
+// such dataset.
+func (s *MetadataStorage) FetchDataset(datasetName string, includeIndex bool, includeMeta bool, includeSystemData bool) (*api.Dataset, error) {
+	ds, err := s.FetchDatasetDocument(datasetName)
+	if err != nil {
+		return nil, err
+	}
+	if ds == nil || ds.Deleted {
+		return nil, nil
+	}
+	ds.Variables = document.FilterVariables(ds.Variables, includeIndex, includeMeta, includeSystemData)
+
+	return ds, nil
+}
+
+// SearchDatasets returns the datasets that match any of the search terms,
+// best matches first.
+func (s *MetadataStorage) SearchDatasets(terms string, baseDataset *api.Dataset, includeIndex bool, includeMeta bool, includeSystemData bool) ([]*api.Dataset, error) {
+	words := searchTerms(terms)
+	if len(words) == 0 {
+		return []*api.Dataset{}, nil
+	}
+
+	where, params := searchWhere(words, []interface{}{})
+	query := fmt.Sprintf("SELECT document, search FROM %s WHERE NOT deleted AND %s ORDER BY dataset_id;",
+		postgres.DatasetMetadataTableName, where)
+
+	return s.queryDatasets(query, words, includeIndex, includeMeta, includeSystemData, params...)
+}
+
+// DatasetExists returns true if a dataset exists in sqlite, whether deleted
+// or not.
+func (s *MetadataStorage) DatasetExists(dataset string) (bool, error) {
+	query := fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM %s WHERE dataset_id = ?1);", postgres.DatasetMetadataTableName)
+
+	var exists bool
+	err := s.client.QueryRow(query, dataset).Scan(&exists)
+	if err != nil {
+		return false, errors.Wrap(err, "Unable to check for dataset metadata in SQLite")
+	}
+
+	return exists, nil
+}
@@ -0,0 +1,119 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlite
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/model/storage/document"
+	"github.com/uncharted-distil/distil/api/postgres"
+)
+
+const (
+	modelVersionOrder = "CAST(json_extract(document, '$.version') AS INTEGER)"
+)
+
+func modelSearchText(model *api.ExportedModel) string {
+	return searchText(document.ModelSearchFields(model))
+}
+
+func parseModelDocuments(documents [][]byte) ([]*api.ExportedModel, error) {
+	models := []*api.ExportedModel{}
+	for _, raw := range documents {
+		model, err := document.ParseModel(raw)
+		if err != nil {
+			return nil, err
+		}
+		models = append(models, model)
+	}
+
+	return models, nil
+}
+
+func (s *MetadataStorage) queryModels(where string, terms []string, params ...interface{}) ([]*api.ExportedModel, error) {
+	query := fmt.Sprintf("SELECT document, search FROM %s %s;", postgres.ExportedModelTableName, where)
+
+	documents, err := s.fetchDocuments(query, terms, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull exported models from SQLite")
+	}
+
+	return parseModelDocuments(documents)
+}
+
+// PersistExportedModel inserts or replaces an exported model.
+func (s *MetadataStorage) PersistExportedModel(model *api.ExportedModel) error {
+	raw, err := json.Marshal(model)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal model")
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (fitted_solution_id, model_name, deleted, document, search) VALUES (?1, ?2, ?3, ?4, ?5)
+		ON CONFLICT (fitted_solution_id) DO UPDATE SET model_name = excluded.model_name, deleted = excluded.deleted, document = excluded.document, search = excluded.search;`,
+		postgres.ExportedModelTableName)
+
+	_, err = s.client.Exec(query, model.FittedSolutionID, model.ModelName, model.Deleted, string(raw), modelSearchText(model))
+
+	return errors.Wrapf(err, "failed to persist exported model to SQLite")
+}
+
+// FetchModel returns a model by the name assigned to it by the user.
+func (s *MetadataStorage) FetchModel(modelName string) (*api.ExportedModel, error) {
+	return document.FirstModel(s.queryModels(fmt.Sprintf("WHERE model_name = ?1 ORDER BY %s DESC", modelVersionOrder), nil, modelName))
+}
+
+// FetchModelByID returns a model using the model's fitted solution ID.
+func (s *MetadataStorage) FetchModelByID(fittedSolutionID string) (*api.ExportedModel, error) {
+	return document.FirstModel(s.queryModels("WHERE fitted_solution_id = ?1", nil, fittedSolutionID))
+}
+
+// FetchModels returns all exported models.
+func (s *MetadataStorage) FetchModels(includeDeleted bool) ([]*api.ExportedModel, error) {
+	return s.queryModels("WHERE ?1 OR NOT deleted ORDER BY model_name, fitted_solution_id", nil, includeDeleted)
+}
+
+// SearchModels returns the models that match any of the search terms, best
+// matches first.
+func (s *MetadataStorage) SearchModels(terms string, includeDeleted bool) ([]*api.ExportedModel, error) {
+	words := searchTerms(terms)
+	if len(words) == 0 {
+		return []*api.ExportedModel{}, nil
+	}
+
+	where, params := searchWhere(words, []interface{}{includeDeleted})
+	return s.queryModels(fmt.Sprintf("WHERE (?1 OR NOT deleted) AND %s ORDER BY fitted_solution_id", where), words, params...)
+}
+
+// DeleteModel flags a model as deleted. The model is retained so that its
+// version number is never reassigned.
+func (s *MetadataStorage) DeleteModel(fittedSolutionID string) error {
+	query := fmt.Sprintf("UPDATE %s SET deleted = 1, document = json_set(document, '$.deleted', json('true')) WHERE fitted_solution_id = ?1;",
+		postgres.ExportedModelTableName)
+
+	_, err := s.client.Exec(query, fittedSolutionID)
+
+	return errors.Wrapf(err, "failed to delete exported model from SQLite")
+}
+
+// FetchModelVersions returns all versions of the named model, including
+// deleted ones, ordered by version.
+func (s *MetadataStorage) FetchModelVersions(modelName string) ([]*api.ExportedModel, error) {
+	return s.queryModels(fmt.Sprintf("WHERE model_name = ?1 ORDER BY %s", modelVersionOrder), nil, modelName)
+}
@@ -0,0 +1,104 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlite
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uncharted-distil/distil-compute/model"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+func TestConcurrentVariableUpdates(t *testing.T) {
+	storage, metaStorage := createTestStorage(t)
+	meta := ingestTestDataset(t, storage, metaStorage)
+
+	// every variable is kept when they are added concurrently
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, metaStorage.AddVariable(meta.ID, fmt.Sprintf("feature_%d", i), "", model.RealType, []string{model.VarDistilRoleData}))
+		}(i)
+	}
+	wg.Wait()
+
+	variables, err := metaStorage.FetchVariables(meta.ID, true, true, true)
+	assert.NoError(t, err)
+	assert.Len(t, variables, 13)
+
+	assert.NoError(t, metaStorage.DeleteDataset(meta.ID, true))
+	dataset, err := metaStorage.FetchDataset(meta.ID, true, true, true)
+	assert.NoError(t, err)
+	assert.Nil(t, dataset)
+	exists, err := metaStorage.DatasetExists(meta.ID)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	assert.Error(t, metaStorage.DeleteDataset("missing", true))
+}
+
+// testStreamWriter collects the streamed rows.
+type testStreamWriter struct {
+	header []string
+	rows   [][]string
+	chunks int
+}
+
+func (w *testStreamWriter) WriteHeader(header []string) error {
+	w.header = header
+	return nil
+}
+
+func (w *testStreamWriter) WriteRows(rows [][]string) error {
+	w.rows = append(w.rows, rows...)
+	w.chunks++
+	return nil
+}
+
+func TestStreamDataset(t *testing.T) {
+	storage, metaStorage := createTestStorage(t)
+	extraRows := [][]string{}
+	for i := 5; i < datasetStreamChunkSize+10; i++ {
+		extraRows = append(extraRows, []string{fmt.Sprintf("%d", i), "setosa", "1"})
+	}
+	meta := ingestTestDataset(t, storage, metaStorage, extraRows...)
+
+	writer := &testStreamWriter{}
+	err := storage.StreamDataset(meta.ID, meta.StorageName, false, false, nil, writer)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"d3mIndex", "species", "length"}, writer.header)
+	assert.Equal(t, 2, writer.chunks)
+	assert.Len(t, writer.rows, datasetStreamChunkSize+10)
+	for i, row := range writer.rows {
+		assert.Equal(t, fmt.Sprintf("%d", i), row[0])
+	}
+
+	// filters are applied to every chunk
+	writer = &testStreamWriter{}
+	filterParams := api.NewFilterParamsFromFilters([]*model.Filter{
+		model.NewCategoricalFilter("species", model.IncludeFilter, []string{"virginica"}),
+	})
+	err = storage.StreamDataset(meta.ID, meta.StorageName, false, false, filterParams, writer)
+	assert.NoError(t, err)
+	assert.Len(t, writer.rows, 2)
+	assert.Equal(t, []string{"2", "virginica", "5.5"}, writer.rows[0])
+}
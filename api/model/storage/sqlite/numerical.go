@@ -0,0 +1,566 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/model/storage/sqlbuilder"
+	log "github.com/unchartedsoftware/plog"
+)
+
+// NumericalField defines behaviour for the numerical field type.
+type NumericalField struct {
+	BasicField
+	subSelect func() string
+}
+
+// NumericalStats contains summary information on a numerical fields.
+type NumericalStats struct {
+	StdDev          float64
+	Mean            float64
+	NoDataAvailable bool
+}
+
+// NewNumericalField creates a new field for numerical types.
+func NewNumericalField(storage *Storage, datasetName string, datasetStorageName string, key string, label string, typ string, count string) *NumericalField {
+	count = sqlbuilder.CountSQL(count)
+
+	field := &NumericalField{
+		BasicField: BasicField{
+			Storage:            storage,
+			DatasetName:        datasetName,
+			DatasetStorageName: datasetStorageName,
+			Key:                key,
+			Label:              label,
+			Type:               typ,
+			Count:              count,
+		},
+	}
+
+	return field
+}
+
+// NewNumericalFieldSubSelect creates a new field for numerical types
+// and specifies a sub select query to pull the raw data.
+func NewNumericalFieldSubSelect(storage *Storage, datasetName string, datasetStorageName string, key string, label string, typ string, count string, fieldSubSelect func() string) *NumericalField {
+	count = sqlbuilder.CountSQL(count)
+
+	field := &NumericalField{
+		BasicField: BasicField{
+			Storage:            storage,
+			DatasetName:        datasetName,
+			DatasetStorageName: datasetStorageName,
+			Key:                key,
+			Label:              label,
+			Type:               typ,
+			Count:              count,
+		},
+		subSelect: fieldSubSelect,
+	}
+
+	return field
+}
+
+// FetchSummaryData pulls summary data from the database and builds a histogram.
+func (f *NumericalField) FetchSummaryData(resultURI string, filterParams *api.FilterParams, extrema *api.Extrema, mode api.SummaryMode) (*api.VariableSummary, error) {
+
+	var baseline *api.Histogram
+	var filtered *api.Histogram
+	var err error
+
+	if resultURI == "" {
+		baseline, err = f.fetchHistogram(api.GetBaselineFilter(filterParams), api.MaxNumBuckets)
+		if err != nil {
+			return nil, err
+		}
+		if !filterParams.IsEmpty(true) {
+			filtered, err = f.fetchHistogram(filterParams, api.MaxNumBuckets)
+			if err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		baseline, err = f.fetchHistogramByResult(resultURI, api.GetBaselineFilter(filterParams), extrema, api.MaxNumBuckets)
+		if err != nil {
+			return nil, err
+		}
+		if !filterParams.IsEmpty(true) {
+			filtered, err = f.fetchHistogramByResult(resultURI, filterParams, extrema, api.MaxNumBuckets)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &api.VariableSummary{
+		Label:    f.Label,
+		Key:      f.Key,
+		Type:     model.NumericalType,
+		VarType:  f.Type,
+		Baseline: baseline,
+		Filtered: filtered,
+	}, nil
+}
+
+func (f *NumericalField) fetchHistogram(filterParams *api.FilterParams, numBuckets int) (*api.Histogram, error) {
+	return f.fetchHistogramWithJoins(filterParams, numBuckets, nil, []string{}, []interface{}{})
+}
+
+func (f *NumericalField) fetchHistogramWithJoins(filterParams *api.FilterParams, numBuckets int, joins []*joinDefinition, wheres []string, params []interface{}) (*api.Histogram, error) {
+	fromClause := f.getFromClause(true)
+
+	// create the filter for the query.
+	wheres, params, err := f.Storage.builder.FilteredQueryWhere(f.GetDatasetName(), wheres, params, "", filterParams)
+	if err != nil {
+		return nil, err
+	}
+	filterWheres := wheres
+	wheres = append(wheres, f.getDefaultFilter(true))
+
+	// need the extrema to calculate the histogram interval
+	extrema, err := f.fetchExtrema()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch variable extrema for summary")
+	}
+	if extrema == nil {
+		log.Warnf("no extrema retrieved for variable summary")
+		return nil, nil
+	}
+
+	// for each returned aggregation, create a histogram aggregation. Bucket
+	// size is derived from the min/max and desired bucket count.
+	agg := f.getHistogramAgg(extrema, numBuckets, "")
+
+	joinSQL := createJoinStatements(joins)
+
+	// Create the complete query string.
+	query := f.Storage.builder.Histogram(agg, f.Count, fmt.Sprintf("%s %s", fromClause, joinSQL), wheres)
+
+	// execute the sqlite query
+	res, err := f.Storage.client.Query(query, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch histograms for variable summaries from sqlite")
+	}
+	histogram, err := f.parseHistogram(res, extrema, numBuckets)
+	res.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	histogram.Missing, err = f.fetchMissingBucket(fmt.Sprintf("%s %s", fromClause, joinSQL), filterWheres, params)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := f.FetchNumericalStats(filterParams)
+	if err != nil {
+		return nil, err
+	}
+	histogram.StdDev = stats.StdDev
+	histogram.Mean = stats.Mean
+
+	return histogram, nil
+}
+
+func (f *NumericalField) fetchHistogramByResult(resultURI string, filterParams *api.FilterParams, extrema *api.Extrema, numBuckets int) (*api.Histogram, error) {
+	fromClause := f.getFromClause(false)
+	// get filter where / params
+	wheres, params, err := f.Storage.buildResultQueryFilters(f.GetDatasetName(), f.DatasetStorageName, resultURI, filterParams, baseTableAlias)
+	if err != nil {
+		return nil, err
+	}
+	filterWheres := wheres
+	wheres = append(wheres, f.getDefaultFilter(true))
+
+	params = append(params, resultURI)
+	wheres = append([]string{f.Storage.builder.ResultWhere(len(params))}, wheres...)
+
+	// need the extrema to calculate the histogram interval
+	if extrema == nil {
+		extrema, err = f.fetchExtremaByURI(resultURI)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to fetch variable extrema for summary")
+		}
+		if extrema == nil {
+			return nil, nil
+		}
+	} else {
+		extrema.Key = f.Key
+		extrema.Type = f.Type
+	}
+	// for each returned aggregation, create a histogram aggregation. Bucket
+	// size is derived from the min/max and desired bucket count.
+	agg := f.getHistogramAgg(extrema, numBuckets, baseTableAlias)
+
+	// Create the complete query string.
+	fromResult := sqlbuilder.DataResultJoin(fromClause, f.Storage.getResultTable(f.DatasetStorageName), baseTableAlias)
+	query := f.Storage.builder.Histogram(agg, f.Count, fromResult, wheres)
+
+	// execute the sqlite query
+	res, err := f.Storage.client.Query(query, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch histograms for variable summaries from sqlite")
+	}
+	histogram, err := f.parseHistogram(res, extrema, numBuckets)
+	res.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	missingWheres := append([]string{f.Storage.builder.ResultWhere(len(params))}, filterWheres...)
+	histogram.Missing, err = f.fetchMissingBucket(fromResult, missingWheres, params)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := f.FetchNumericalStatsByResult(resultURI, filterParams)
+	if err != nil {
+		return nil, err
+	}
+	histogram.StdDev = stats.StdDev
+	histogram.Mean = stats.Mean
+
+	return histogram, nil
+}
+
+func (f *NumericalField) fetchExtrema() (*api.Extrema, error) {
+	fromClause := f.getFromClause(true)
+	// add min / max aggregation
+	aggQuery := f.getMinMaxAggsQuery()
+
+	// create a query that does min and max aggregations for each variable
+	// need to ignore the missing values
+	queryString := f.Storage.builder.Select(aggQuery, fromClause, []string{f.getDefaultFilter(true)})
+
+	return f.parseExtrema(f.Storage.client.QueryRow(queryString))
+}
+
+func (f *NumericalField) getHistogramAgg(extrema *api.Extrema, numBuckets int, alias string) *sqlbuilder.HistogramAgg {
+	interval := extrema.GetBucketInterval(numBuckets)
+	rounded := extrema.GetBucketMinMax(numBuckets)
+
+	field := sqlbuilder.FullName(alias, extrema.Key)
+	return f.Storage.builder.HistogramAgg(extrema.Key, field, rounded.Min, rounded.Max, interval, extrema.GetBucketCount(numBuckets))
+}
+
+func parseHistogramBuckets(rows *sql.Rows, buckets []*api.Bucket, histogramAggName string) error {
+	for rows.Next() {
+		var bucketValue float64
+		var bucketCount int64
+		var bucket int64
+		err := rows.Scan(&bucket, &bucketValue, &bucketCount)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("no %s histogram aggregation found", histogramAggName))
+		}
+
+		if bucket < 0 {
+			// Due to float representation, sometimes the lowest value <
+			// first bucket interval and so ends up in bucket -1.
+			buckets[0].Count = bucketCount
+		} else if bucket < int64(len(buckets)) {
+			buckets[bucket].Count = bucketCount
+		} else {
+			// Since the max can match the limit, an extra bucket may exist.
+			// Add the value to the second to last bucket.
+			buckets[len(buckets)-1].Count += bucketCount
+		}
+	}
+	err := rows.Err()
+	if err != nil {
+		return errors.Wrapf(err, "error reading data from sqlite")
+	}
+
+	return nil
+}
+
+func (f *NumericalField) parseHistogram(rows *sql.Rows, extrema *api.Extrema, numBuckets int) (*api.Histogram, error) {
+	// get histogram agg name
+	histogramAggName := api.HistogramAggPrefix + extrema.Key
+
+	// Parse bucket results.
+	interval := extrema.GetBucketInterval(numBuckets)
+
+	buckets := make([]*api.Bucket, extrema.GetBucketCount(numBuckets))
+	rounded := extrema.GetBucketMinMax(numBuckets)
+	key := rounded.Min
+	for i := 0; i < len(buckets); i++ {
+		keyString := ""
+		if model.IsFloatingPoint(extrema.Type) {
+			keyString = fmt.Sprintf("%f", key)
+		} else {
+			keyString = strconv.Itoa(int(key))
+		}
+
+		buckets[i] = &api.Bucket{
+			Key:   keyString,
+			Count: 0,
+		}
+
+		key = key + interval
+	}
+
+	err := parseHistogramBuckets(rows, buckets, histogramAggName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Assign histogram attributes.  Extrema reflects the extrema of the data the histogram
+	// is created from, not the extrema of the buckets.
+	return &api.Histogram{
+		Extrema: extrema,
+		Buckets: buckets,
+	}, nil
+}
+
+func (f *NumericalField) parseExtrema(row *sql.Row) (*api.Extrema, error) {
+	var minValue *float64
+	var maxValue *float64
+	err := row.Scan(&minValue, &maxValue)
+	if err != nil {
+		return nil, errors.Wrap(err, "no min / max aggregation found")
+	}
+	// check values exist
+	if minValue == nil || maxValue == nil {
+		log.Warnf("no min / max aggregation values found")
+		return nil, nil
+	}
+	// assign attributes
+	return &api.Extrema{
+		Key:  f.Key,
+		Type: f.Type,
+		Min:  *minValue,
+		Max:  *maxValue,
+	}, nil
+}
+
+func (f *NumericalField) getMinMaxAggsQuery() string {
+	return f.Storage.builder.MinMax(f.Key, fmt.Sprintf("\"%s\"", f.Key))
+}
+
+func (f *NumericalField) fetchExtremaByURI(resultURI string) (*api.Extrema, error) {
+	fromClause := f.getFromClause(false)
+
+	// add min / max aggregation
+	aggQuery := f.getMinMaxAggsQuery()
+
+	// create a query that does min and max aggregations for each variable
+	fromResult := sqlbuilder.DataResultJoin(fromClause, f.Storage.getResultTable(f.DatasetStorageName), baseTableAlias)
+	queryString := f.Storage.builder.Select(aggQuery, fromResult, []string{f.Storage.builder.ResultWhere(1), f.getDefaultFilter(true)})
+
+	return f.parseExtrema(f.Storage.client.QueryRow(queryString, resultURI))
+}
+
+// FetchPredictedSummaryData pulls data from the result table and builds
+// the numerical histogram for the field.
+func (f *NumericalField) FetchPredictedSummaryData(resultURI string, datasetResult string, filterParams *api.FilterParams, extrema *api.Extrema, mode api.SummaryMode) (*api.VariableSummary, error) {
+	var baseline *api.Histogram
+	var filtered *api.Histogram
+	var err error
+
+	baseline, err = f.fetchPredictedSummaryData(resultURI, datasetResult, nil, extrema, api.MaxNumBuckets)
+	if err != nil {
+		return nil, err
+	}
+	if !filterParams.IsEmpty(true) {
+		filtered, err = f.fetchPredictedSummaryData(resultURI, datasetResult, filterParams, extrema, api.MaxNumBuckets)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &api.VariableSummary{
+		Label:    f.Label,
+		Key:      f.Key,
+		Type:     model.NumericalType,
+		VarType:  f.Type,
+		Baseline: baseline,
+		Filtered: filtered,
+	}, nil
+}
+
+func (f *NumericalField) fetchPredictedSummaryData(resultURI string, datasetResult string, filterParams *api.FilterParams, extrema *api.Extrema, numBuckets int) (*api.Histogram, error) {
+	resultVariable := &model.Variable{
+		Key:  "value",
+		Type: model.StringType,
+	}
+
+	// need the extrema to calculate the histogram interval
+	var err error
+	if extrema == nil {
+		extrema, err = f.fetchResultsExtrema(resultURI, datasetResult, resultVariable)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to fetch result variable extrema for summary")
+		}
+		if extrema == nil {
+			return nil, nil
+		}
+	} else {
+		extrema.Key = f.Key
+		extrema.Type = f.Type
+	}
+	// for each returned aggregation, create a histogram aggregation. Bucket
+	// size is derived from the min/max and desired bucket count.
+	agg := f.getResultHistogramAgg(extrema, resultVariable, numBuckets)
+
+	// get filter where / params
+	wheres, params, err := f.Storage.buildResultQueryFilters(f.GetDatasetName(), f.DatasetStorageName, resultURI, filterParams, baseTableAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	wheres = append(wheres, f.Storage.builder.ResultTargetWhere(len(params)+1))
+	params = append(params, resultURI, f.Key)
+	wheres = append(wheres, fmt.Sprintf("result.\"%s\" != ''", resultVariable.Key))
+
+	// Create the complete query string.
+	fromResult := sqlbuilder.DataResultJoin(fmt.Sprintf("%s data", f.DatasetStorageName), datasetResult, "data")
+	query := f.Storage.builder.Histogram(agg, f.Count, fromResult, wheres)
+
+	// execute the sqlite query
+	res, err := f.Storage.client.Query(query, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch histograms for result variable summaries from sqlite")
+	}
+	defer res.Close()
+
+	return f.parseHistogram(res, extrema, numBuckets)
+}
+
+func (f *NumericalField) getResultMinMaxAggsQuery(resultVariable *model.Variable) string {
+	// Only numeric types should occur.
+	fieldTyped := f.Storage.builder.Dialect.Numeric(fmt.Sprintf("\"%s\"", resultVariable.Key))
+
+	return f.Storage.builder.MinMax(resultVariable.Key, fieldTyped)
+}
+
+func (f *NumericalField) getResultHistogramAgg(extrema *api.Extrema, resultVariable *model.Variable, numBuckets int) *sqlbuilder.HistogramAgg {
+	// compute the bucket interval for the histogram
+	interval := extrema.GetBucketInterval(numBuckets)
+	rounded := extrema.GetBucketMinMax(numBuckets)
+
+	// Only numeric types should occur.
+	fieldTyped := f.Storage.builder.Dialect.Numeric(fmt.Sprintf("result.\"%s\"", resultVariable.Key))
+
+	return f.Storage.builder.HistogramAgg(extrema.Key, fieldTyped, rounded.Min, rounded.Max, interval, extrema.GetBucketCount(numBuckets))
+}
+
+func (f *NumericalField) fetchResultsExtrema(resultURI string, dataset string, resultVariable *model.Variable) (*api.Extrema, error) {
+	// add min / max aggregation
+	aggQuery := f.getResultMinMaxAggsQuery(resultVariable)
+
+	// create a query that does min and max aggregations for each variable
+	wheres := []string{
+		fmt.Sprintf("result_id = %s", f.Storage.builder.Param(1)),
+		fmt.Sprintf("target = %s", f.Storage.builder.Param(2)),
+		fmt.Sprintf("\"%s\" != ''", resultVariable.Key),
+	}
+	queryString := f.Storage.builder.Select(aggQuery, dataset, wheres)
+
+	return f.parseExtrema(f.Storage.client.QueryRow(queryString, resultURI, f.Key))
+}
+
+// FetchNumericalStats gets the variable's numerical summary info (mean, stddev).
+func (f *NumericalField) FetchNumericalStats(filterParams *api.FilterParams) (*NumericalStats, error) {
+	fromClause := f.getFromClause(true)
+
+	// create the filter for the query.
+	wheres := make([]string, 0)
+	params := make([]interface{}, 0)
+	wheres, params, err := f.Storage.builder.FilteredQueryWhere(f.GetDatasetName(), wheres, params, "", filterParams)
+	if err != nil {
+		return nil, err
+	}
+	wheres = append(wheres, f.getDefaultFilter(true))
+
+	// Create the complete query string.
+	query := f.Storage.builder.Stats(f.Key, fromClause, wheres)
+
+	return f.parseStats(f.Storage.client.QueryRow(query, params...))
+}
+
+// FetchNumericalStatsByResult gets the variable's numerical summary info (mean, stddev) for a result set.
+func (f *NumericalField) FetchNumericalStatsByResult(resultURI string, filterParams *api.FilterParams) (*NumericalStats, error) {
+	fromClause := f.getFromClause(false)
+
+	// get filter where / params
+	wheres, params, err := f.Storage.buildResultQueryFilters(f.GetDatasetName(), f.DatasetStorageName, resultURI, filterParams, baseTableAlias)
+	if err != nil {
+		return nil, err
+	}
+	wheres = append(wheres, f.getDefaultFilter(true))
+
+	params = append(params, resultURI)
+	wheres = append([]string{f.Storage.builder.ResultWhere(len(params))}, wheres...)
+
+	// Create the complete query string.
+	fromResult := sqlbuilder.DataResultJoin(fromClause, f.Storage.getResultTable(f.DatasetStorageName), baseTableAlias)
+	query := f.Storage.builder.Stats(f.Key, fromResult, wheres)
+
+	return f.parseStats(f.Storage.client.QueryRow(query, params...))
+}
+
+func (f *NumericalField) parseStats(row *sql.Row) (*NumericalStats, error) {
+	var stddev *float64
+	var mean *float64
+	err := row.Scan(&stddev, &mean)
+	if err != nil {
+		return nil, errors.Wrap(err, "no stats found")
+	}
+
+	stats := &NumericalStats{}
+	if stddev != nil {
+		stats.StdDev = *stddev
+	}
+	if mean != nil {
+		stats.Mean = *mean
+	}
+	if mean == nil && stddev == nil {
+		stats.NoDataAvailable = true
+	}
+
+	return stats, nil
+}
+
+func (f *NumericalField) getFromClause(alias bool) string {
+	fromClause := fmt.Sprintf("%s AS %s", f.DatasetStorageName, baseTableAlias)
+	if f.subSelect != nil {
+		fromClause = f.subSelect()
+		if alias {
+			fromClause = fmt.Sprintf("%s AS nested INNER JOIN %s AS %s on nested.\"%s\" = %s.\"%s\"",
+				fromClause, f.DatasetStorageName, baseTableAlias, model.D3MIndexFieldName, baseTableAlias, model.D3MIndexFieldName)
+		} else {
+			fromClause = fmt.Sprintf("%s AS %s", fromClause, baseTableAlias)
+		}
+	}
+
+	return fromClause
+}
+
+func (f *NumericalField) fetchExtremaStorage() (*api.Extrema, error) {
+	aggQuery := f.getMinMaxAggsQuery()
+
+	// numerical columns need to filter the missing values out
+	filter := fmt.Sprintf("\"%s\" IS NOT NULL", f.Key)
+
+	// create a query that does min and max aggregations for each variable
+	queryString := f.Storage.builder.Select(aggQuery, f.GetDatasetStorageName(), []string{filter})
+
+	return f.parseExtrema(f.Storage.client.QueryRow(queryString))
+}
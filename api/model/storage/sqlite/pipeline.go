@@ -0,0 +1,461 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/uncharted-distil/distil-compute/model"
+	"github.com/uncharted-distil/distil-compute/primitive/compute"
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/postgres"
+)
+
+const solutionResultFields = "result.solution_id, result.fitted_solution_id, result.produce_request_id, result.result_type, result.result_uuid, " +
+	"result.result_uri, result.progress, result.created_time, request.dataset"
+
+// PersistSolution persists the solution to SQLite.
+func (s *Storage) PersistSolution(requestID string, solutionID string, explainedSolutionID string, createdTime time.Time) error {
+	sql := fmt.Sprintf("INSERT INTO %s (request_id, solution_id, explained_solution_id, created_time) VALUES (?1, ?2, ?3, ?4);", postgres.SolutionTableName)
+
+	_, err := s.client.Exec(sql, requestID, solutionID, explainedSolutionID, createdTime)
+	if err != nil {
+		return errors.Wrap(err, "unable to persist solution")
+	}
+
+	return nil
+}
+
+// UpdateSolution updates the solution in SQLite.
+func (s *Storage) UpdateSolution(solutionID string, explainedSolutionID string) error {
+	sql := fmt.Sprintf("UPDATE %s SET explained_solution_id = ?2 WHERE solution_id = ?1;", postgres.SolutionTableName)
+
+	_, err := s.client.Exec(sql, solutionID, explainedSolutionID)
+	if err != nil {
+		return errors.Wrap(err, "unable to update solution")
+	}
+
+	return nil
+}
+
+// PersistSolutionWeight persists the solution feature weight to SQLite.
+func (s *Storage) PersistSolutionWeight(solutionID string, featureName string, featureIndex int64, weight float64) error {
+	sql := fmt.Sprintf("INSERT INTO %s (solution_id, feature_name, feature_index, weight) VALUES (?1, ?2, ?3, ?4);", postgres.SolutionFeatureWeightTableName)
+
+	_, err := s.client.Exec(sql, solutionID, featureName, featureIndex, weight)
+
+	return err
+}
+
+// PersistSolutionState persists the solution state to SQLite.
+func (s *Storage) PersistSolutionState(solutionID string, progress string, createdTime time.Time) error {
+	sql := fmt.Sprintf("INSERT INTO %s (solution_id, progress, created_time) VALUES (?1, ?2, ?3);", postgres.SolutionStateTableName)
+
+	_, err := s.client.Exec(sql, solutionID, progress, createdTime)
+
+	return err
+}
+
+// PersistSolutionExplainedOutput persists the explained result data to sqlite.
+func (s *Storage) PersistSolutionExplainedOutput(resultUUID string, explainOutput map[string]*api.SolutionExplainResult) error {
+	sql := fmt.Sprintf("INSERT INTO %s (result_id, explain_uri, explain_type) VALUES (?1, ?2, ?3)", postgres.SolutionResultExplainOutputTableName)
+	for typ, uri := range explainOutput {
+		_, err := s.client.Exec(sql, resultUUID, uri.ResultURI, typ)
+		if err != nil {
+			return errors.Wrap(err, "unable to persist solution result explain output")
+		}
+	}
+
+	return nil
+}
+
+// PersistSolutionResult persists the solution result metadata to SQLite.
+func (s *Storage) PersistSolutionResult(solutionID string, fittedSolutionID string, produceRequestID string, resultType string,
+	resultUUID string, resultURI string, progress string, createdTime time.Time) error {
+	sql := fmt.Sprintf("INSERT INTO %s (solution_id, fitted_solution_id, produce_request_id, result_type, result_uuid, result_uri, progress, created_time) VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8);", postgres.SolutionResultTableName)
+
+	_, err := s.client.Exec(sql, solutionID, fittedSolutionID, produceRequestID, resultType, resultUUID, resultURI, progress, createdTime)
+	if err != nil {
+		return errors.Wrap(err, "unable to persist solution result")
+	}
+
+	return nil
+}
+
+// PersistSolutionScore persist the solution score to SQLite.
+func (s *Storage) PersistSolutionScore(solutionID string, metric string, score float64) error {
+	sql := fmt.Sprintf("INSERT INTO %s (solution_id, metric, score, score_std_dev, fold_count) VALUES (?1, ?2, ?3, 0, 1);", postgres.SolutionScoreTableName)
+
+	_, err := s.client.Exec(sql, solutionID, metric, score)
+
+	return err
+}
+
+// PersistSolutionCrossValidationScore persists the mean and standard deviation
+// of a solution score across cross validation folds to SQLite.
+func (s *Storage) PersistSolutionCrossValidationScore(solutionID string, metric string, mean float64, stdDev float64, folds int) error {
+	sql := fmt.Sprintf("INSERT INTO %s (solution_id, metric, score, score_std_dev, fold_count) VALUES (?1, ?2, ?3, ?4, ?5);", postgres.SolutionScoreTableName)
+
+	_, err := s.client.Exec(sql, solutionID, metric, mean, stdDev, folds)
+
+	return err
+}
+
+// FetchSolution pulls solution information from SQLite.
+func (s *Storage) FetchSolution(solutionID string) (*api.Solution, error) {
+	query := fmt.Sprintf("SELECT request_id, solution_id, explained_solution_id, created_time FROM %s WHERE solution_id = ?1 ORDER BY created_time desc LIMIT 1;", postgres.SolutionTableName)
+
+	solution := &api.Solution{}
+	var explainedSolutionID sql.NullString
+	err := s.client.QueryRow(query, solutionID).Scan(&solution.RequestID, &solution.SolutionID, &explainedSolutionID, &solution.CreatedTime)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to parse solution from SQLite")
+	}
+	solution.ExplainedSolutionID = explainedSolutionID.String
+
+	solution.State, err = s.FetchSolutionState(solutionID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to parse solution result from SQLite")
+	}
+
+	solution.Results, err = s.FetchSolutionResults(solutionID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to parse solution result from SQLite")
+	}
+
+	solution.Scores, err = s.FetchSolutionScores(solutionID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to parse solution scores from SQLite")
+	}
+
+	solution.IsBad = false
+	return solution, nil
+}
+
+// FetchSolutionWeights fetches solution feature weights from SQLite.
+func (s *Storage) FetchSolutionWeights(solutionID string) ([]*api.SolutionWeight, error) {
+	sql := fmt.Sprintf("SELECT solution_id, feature_name, feature_index, weight FROM %s WHERE solution_id = ?1;", postgres.SolutionFeatureWeightTableName)
+
+	rows, err := s.client.Query(sql, solutionID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull solution feature weights from SQLite")
+	}
+	defer rows.Close()
+
+	results := make([]*api.SolutionWeight, 0)
+	for rows.Next() {
+		weight := &api.SolutionWeight{}
+		err := rows.Scan(&weight.SolutionID, &weight.FeatureName, &weight.FeatureIndex, &weight.Weight)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to parse solution feature weight from SQLite")
+		}
+		results = append(results, weight)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading data from sqlite")
+	}
+
+	return results, nil
+}
+
+func (s *Storage) parseSolutionResult(rows *sql.Rows) ([]*api.SolutionResult, error) {
+	defer rows.Close()
+
+	results := make([]*api.SolutionResult, 0)
+	for rows.Next() {
+		result := &api.SolutionResult{}
+		err := rows.Scan(&result.SolutionID, &result.FittedSolutionID, &result.ProduceRequestID, &result.ResultType,
+			&result.ResultUUID, &result.ResultURI, &result.Progress, &result.CreatedTime, &result.Dataset)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to parse solution results from SQLite")
+		}
+		results = append(results, result)
+	}
+	err := rows.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading data from sqlite")
+	}
+
+	return results, nil
+}
+
+func (s *Storage) parseSolutionFeatureWeight(resultURI string, rows *sql.Rows) (*api.SolutionFeatureWeight, error) {
+	result := &api.SolutionFeatureWeight{
+		ResultURI: resultURI,
+	}
+
+	if rows.Next() {
+		columns, err := rows.Columns()
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to extract fields from query result")
+		}
+
+		columnValues, err := scanValues(rows, len(columns))
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to extract fields from query result")
+		}
+
+		output := make(map[string]float64)
+		for i := 0; i < len(columnValues); i++ {
+			value, ok := toFloat(columnValues[i])
+			if !ok {
+				continue
+			}
+			if columns[i] == model.D3MIndexFieldName {
+				result.D3MIndex = int64(value)
+			} else if columns[i] != "result_id" {
+				output[columns[i]] = value
+			}
+		}
+
+		result.Weights = output
+	}
+	err := rows.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading data from sqlite")
+	}
+
+	return result, nil
+}
+
+// FetchSolutionFeatureWeights fetches solution feature weights from SQLite.
+func (s *Storage) FetchSolutionFeatureWeights(dataset string, storageName string, resultURI string, d3mIndex int64) (*api.SolutionFeatureWeight, error) {
+	sql := fmt.Sprintf("SELECT * FROM %s WHERE result_id = ?1 and \"d3mIndex\" = ?2;",
+		s.getSolutionFeatureWeightTable(storageName))
+
+	rows, err := s.client.Query(sql, resultURI, d3mIndex)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull solution feature weights from SQLite")
+	}
+	defer rows.Close()
+
+	result, err := s.parseSolutionFeatureWeight(resultURI, rows)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to parse solution feature weight from SQLite")
+	}
+
+	return result, nil
+}
+
+// FetchSolutionState pulls solution state information from SQLite.
+func (s *Storage) FetchSolutionState(solutionID string) (*api.SolutionState, error) {
+	query := fmt.Sprintf("SELECT solution_id, progress, created_time "+
+		"FROM %s AS state "+
+		"WHERE state.solution_id = ?1 "+
+		"ORDER BY state.created_time desc LIMIT 1;", postgres.SolutionStateTableName)
+
+	state := &api.SolutionState{}
+	err := s.client.QueryRow(query, solutionID).Scan(&state.SolutionID, &state.Progress, &state.CreatedTime)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "Unable to pull solution state from SQLite")
+	}
+
+	return state, nil
+}
+
+func (s *Storage) fetchSolutionResults(where string, param string, limit string) ([]*api.SolutionResult, error) {
+	sql := fmt.Sprintf("SELECT %s "+
+		"FROM %s AS result INNER JOIN %s AS solution ON result.solution_id = solution.solution_id "+
+		"INNER JOIN %s AS request ON solution.request_id = request.request_id "+
+		"WHERE %s %s;", solutionResultFields, postgres.SolutionResultTableName, postgres.SolutionTableName, postgres.RequestTableName, where, limit)
+
+	rows, err := s.client.Query(sql, param)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull solution results from SQLite")
+	}
+
+	results, err := s.parseSolutionResult(rows)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to parse solution results from SQLite")
+	}
+
+	return results, nil
+}
+
+// FetchSolutionResults pulls solution result information from SQLite.
+func (s *Storage) FetchSolutionResults(solutionID string) ([]*api.SolutionResult, error) {
+	return s.fetchSolutionResults("result.solution_id = ?1 AND result.result_type = 'test'", solutionID, "ORDER BY result.created_time desc LIMIT 1")
+}
+
+// FetchSolutionResultsByFittedSolutionID pulls solution result information from SQLite.
+func (s *Storage) FetchSolutionResultsByFittedSolutionID(fittedSolutionID string) ([]*api.SolutionResult, error) {
+	return s.fetchSolutionResults("result.fitted_solution_id = ?1 AND result.result_type = 'test'", fittedSolutionID, "ORDER BY result.created_time desc LIMIT 1")
+}
+
+// FetchSolutionResultByUUID pulls solution result information from SQLite.
+func (s *Storage) FetchSolutionResultByUUID(resultUUID string) (*api.SolutionResult, error) {
+	results, err := s.fetchSolutionResults("result.result_uuid = ?1", resultUUID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// load the solution result explain output
+	explained, err := s.fetchSolutionResultOutputExplain(resultUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	var res *api.SolutionResult
+	if len(results) > 0 {
+		res = results[0]
+		res.ExplainOutput = explained
+	}
+
+	return res, nil
+}
+
+func (s *Storage) fetchSolutionResultOutputExplain(resultID string) ([]*api.SolutionResultExplainOutput, error) {
+	sql := fmt.Sprintf("SELECT result_id, explain_uri, explain_type FROM %s WHERE result_id = ?1", postgres.SolutionResultExplainOutputTableName)
+
+	rows, err := s.client.Query(sql, resultID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull solution results from SQLite")
+	}
+	defer rows.Close()
+
+	results := []*api.SolutionResultExplainOutput{}
+	for rows.Next() {
+		explain := &api.SolutionResultExplainOutput{}
+		err := rows.Scan(&explain.ResultID, &explain.URI, &explain.Type)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to parse solution results explain output from SQLite")
+		}
+		results = append(results, explain)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading data from sqlite")
+	}
+
+	return results, nil
+}
+
+// FetchSolutionResultByProduceRequestID pulls solution result information from SQLite.
+func (s *Storage) FetchSolutionResultByProduceRequestID(produceRequestID string) (*api.SolutionResult, error) {
+	results, err := s.fetchSolutionResults("result.produce_request_id = ?1", produceRequestID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var res *api.SolutionResult
+	if len(results) > 0 {
+		res = results[0]
+	}
+
+	return res, nil
+}
+
+// FetchSolutionScores pulls solution score from SQLite.
+func (s *Storage) FetchSolutionScores(solutionID string) ([]*api.SolutionScore, error) {
+	sql := fmt.Sprintf("SELECT solution_id, metric, score, coalesce(score_std_dev, 0), coalesce(fold_count, 1) FROM %s WHERE solution_id = ?1;", postgres.SolutionScoreTableName)
+
+	rows, err := s.client.Query(sql, solutionID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull solution score from SQLite")
+	}
+	defer rows.Close()
+
+	var results []*api.SolutionScore
+	for rows.Next() {
+		var solutionID string
+		var metric string
+		var score float64
+		var stdDev float64
+		var folds int
+
+		err = rows.Scan(&solutionID, &metric, &score, &stdDev, &folds)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to parse result score from SQLite")
+		}
+
+		results = append(results, &api.SolutionScore{
+			SolutionID:     solutionID,
+			Metric:         metric,
+			Label:          compute.GetMetricLabel(metric),
+			Score:          score,
+			Mean:           score,
+			StdDev:         stdDev,
+			Folds:          folds,
+			SortMultiplier: compute.GetMetricScoreMultiplier(metric),
+		})
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading data from sqlite")
+	}
+
+	return results, nil
+}
+
+// FetchSolutionsByDatasetTarget fetches all solutions that apply to a particular dataset and target.
+func (s *Storage) FetchSolutionsByDatasetTarget(dataset string, target string) ([]*api.Solution, error) {
+	// get the solution ids
+	sql := fmt.Sprintf("SELECT DISTINCT solution.solution_id "+
+		"FROM %s request INNER JOIN %s rf ON request.request_id = rf.request_id "+
+		"INNER JOIN %s solution ON request.request_id = solution.request_id ",
+		postgres.RequestTableName, postgres.RequestFeatureTableName, postgres.SolutionTableName)
+	params := make([]interface{}, 0)
+
+	if dataset != "" {
+		sql = fmt.Sprintf("%s AND request.dataset = ?%d", sql, len(params)+1)
+		params = append(params, dataset)
+	}
+	if target != "" {
+		sql = fmt.Sprintf("%s AND rf.feature_name = ?%d AND rf.feature_type = ?%d", sql, len(params)+1, len(params)+2)
+		params = append(params, target)
+		params = append(params, model.FeatureTypeTarget)
+	}
+
+	return s.fetchSolutionsByQuery(fmt.Sprintf("%s;", sql), params...)
+}
+
+// FetchSolutionsByRequestID fetches solutions associated with a given request.
+func (s *Storage) FetchSolutionsByRequestID(requestID string) ([]*api.Solution, error) {
+	// get the solution ids
+	sql := fmt.Sprintf("SELECT DISTINCT solution.solution_id "+
+		"FROM %s request INNER JOIN %s rf ON request.request_id = rf.request_id "+
+		"INNER JOIN %s solution ON request.request_id = solution.request_id "+
+		"AND request.request_id = ?1;",
+		postgres.RequestTableName, postgres.RequestFeatureTableName, postgres.SolutionTableName)
+
+	return s.fetchSolutionsByQuery(sql, requestID)
+}
+
+func (s *Storage) fetchSolutionsByQuery(sql string, params ...interface{}) ([]*api.Solution, error) {
+	solutionIDs, err := s.fetchStrings(sql, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to parse solution id from SQLite")
+	}
+
+	solutions := []*api.Solution{}
+	for _, solutionID := range solutionIDs {
+		solution, err := s.FetchSolution(solutionID)
+		if err != nil {
+			return nil, err
+		}
+		solutions = append(solutions, solution)
+	}
+
+	return solutions, nil
+}
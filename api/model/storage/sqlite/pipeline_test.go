@@ -0,0 +1,88 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uncharted-distil/distil-compute/model"
+)
+
+func TestSolutionStorage(t *testing.T) {
+	storage, _ := createTestStorage(t)
+
+	created := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	assert.NoError(t, storage.PersistRequest("request", "flowers", "PENDING", created))
+	assert.NoError(t, storage.PersistRequestFeature("request", "species", model.FeatureTypeTarget))
+	assert.NoError(t, storage.PersistSolution("request", "solution", "", created))
+	assert.NoError(t, storage.UpdateSolution("solution", "explained"))
+	assert.NoError(t, storage.PersistSolutionState("solution", "RUNNING", created))
+	assert.NoError(t, storage.PersistSolutionState("solution", "COMPLETED", created.Add(time.Minute)))
+	assert.NoError(t, storage.PersistSolutionResult("solution", "fitted", "produce", "test", "result", "predictions.csv", "COMPLETED", created))
+	assert.NoError(t, storage.PersistSolutionScore("solution", "accuracy", 0.75))
+	assert.NoError(t, storage.PersistSolutionCrossValidationScore("solution", "f1Macro", 0.6, 0.1, 5))
+	assert.NoError(t, storage.PersistSolutionWeight("solution", "length", 2, 0.4))
+
+	solution, err := storage.FetchSolution("solution")
+	assert.NoError(t, err)
+	assert.Equal(t, "request", solution.RequestID)
+	assert.Equal(t, "explained", solution.ExplainedSolutionID)
+	assert.True(t, created.Equal(solution.CreatedTime))
+	assert.Equal(t, "COMPLETED", solution.State.Progress)
+	assert.Len(t, solution.Results, 1)
+	assert.Equal(t, "fitted", solution.Results[0].FittedSolutionID)
+	assert.Equal(t, "flowers", solution.Results[0].Dataset)
+
+	scores := map[string][]float64{}
+	for _, score := range solution.Scores {
+		scores[score.Metric] = []float64{score.Mean, score.StdDev, float64(score.Folds)}
+	}
+	assert.Equal(t, map[string][]float64{"accuracy": {0.75, 0, 1}, "f1Macro": {0.6, 0.1, 5}}, scores)
+
+	result, err := storage.FetchSolutionResultByUUID("result")
+	assert.NoError(t, err)
+	assert.Equal(t, "produce", result.ProduceRequestID)
+	result, err = storage.FetchSolutionResultByProduceRequestID("produce")
+	assert.NoError(t, err)
+	assert.Equal(t, "result", result.ResultUUID)
+	result, err = storage.FetchSolutionResultByUUID("missing")
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+
+	solutions, err := storage.FetchSolutionsByRequestID("request")
+	assert.NoError(t, err)
+	assert.Len(t, solutions, 1)
+	solutions, err = storage.FetchSolutionsByDatasetTarget("flowers", "species")
+	assert.NoError(t, err)
+	assert.Len(t, solutions, 1)
+	solutions, err = storage.FetchSolutionsByDatasetTarget("flowers", "length")
+	assert.NoError(t, err)
+	assert.Len(t, solutions, 0)
+
+	request, err := storage.FetchRequestBySolutionID("solution")
+	assert.NoError(t, err)
+	assert.Equal(t, "flowers", request.Dataset)
+
+	weights, err := storage.FetchSolutionWeights("solution")
+	assert.NoError(t, err)
+	assert.Len(t, weights, 1)
+	assert.Equal(t, 0.4, weights[0].Weight)
+
+	_, err = storage.FetchSolution("missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,124 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlite
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/postgres"
+)
+
+const predictionFields = "request_id, dataset, target, fitted_solution_id, progress, created_time, last_updated_time"
+
+// PersistPrediction persists a prediction request to SQLite.
+func (s *Storage) PersistPrediction(requestID string, dataset string, target string, fittedSolutionID string, progress string, createdTime time.Time) error {
+	sql := fmt.Sprintf("INSERT INTO %s (%s) VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?6);", postgres.PredictionTableName, predictionFields)
+
+	_, err := s.client.Exec(sql, requestID, dataset, target, fittedSolutionID, progress, createdTime)
+	if err != nil {
+		return errors.Wrapf(err, "failed to persist prediction request to SQLite")
+	}
+	return nil
+}
+
+// FetchPrediction pulls the specified prediction.
+func (s *Storage) FetchPrediction(requestID string) (*api.Prediction, error) {
+	sql := fmt.Sprintf("SELECT %s FROM %s WHERE request_id = ?1 ORDER BY created_time desc LIMIT 1;", predictionFields, postgres.PredictionTableName)
+
+	predictions, err := s.fetchPredictions(sql, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if len(predictions) == 0 {
+		return nil, errors.Errorf("no prediction request %s", requestID)
+	}
+
+	return predictions[0], nil
+}
+
+// FetchPredictionsByFittedSolutionID fetches all prediction requests using a given
+// fitted solution id.
+func (s *Storage) FetchPredictionsByFittedSolutionID(fittedSolutionID string) ([]*api.Prediction, error) {
+	sql := fmt.Sprintf("SELECT %s FROM %s WHERE fitted_solution_id = ?1 ORDER BY created_time desc;", predictionFields, postgres.PredictionTableName)
+
+	return s.fetchPredictions(sql, fittedSolutionID)
+}
+
+func (s *Storage) fetchPredictions(sql string, param string) ([]*api.Prediction, error) {
+	rows, err := s.client.Query(sql, param)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull request from SQLite")
+	}
+	defer rows.Close()
+
+	predictions := []*api.Prediction{}
+	for rows.Next() {
+		prediction := &api.Prediction{}
+		err = rows.Scan(&prediction.RequestID, &prediction.Dataset, &prediction.Target, &prediction.FittedSolutionID,
+			&prediction.Progress, &prediction.CreatedTime, &prediction.LastUpdatedTime)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to parse prediction request from SQLite")
+		}
+		predictions = append(predictions, prediction)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading data from sqlite")
+	}
+
+	return predictions, nil
+}
+
+// FetchPredictionResultByProduceRequestID pulls prediction result information from SQLite.  This is slightly different
+// than FetchSolutionResultByProduceRequestID in that it joins with data from the `prediction` rather than the `request` table
+// to extract additional information.
+func (s *Storage) FetchPredictionResultByProduceRequestID(produceRequestID string) (*api.SolutionResult, error) {
+	return s.fetchPredictionResult("result.produce_request_id = ?1", produceRequestID)
+}
+
+// FetchPredictionResultByUUID pulls solution result information from SQLite.
+func (s *Storage) FetchPredictionResultByUUID(resultUUID string) (*api.SolutionResult, error) {
+	return s.fetchPredictionResult("result.result_uuid = ?1", resultUUID)
+}
+
+func (s *Storage) fetchPredictionResult(where string, param string) (*api.SolutionResult, error) {
+	sql := fmt.Sprintf("SELECT result.solution_id, result.fitted_solution_id, result.produce_request_id, result.result_type, result.result_uuid, "+
+		"result.result_uri, result.progress, result.created_time, prediction.dataset "+
+		"FROM %s AS result INNER JOIN %s AS solution ON result.solution_id = solution.solution_id "+
+		"INNER JOIN %s AS prediction ON result.produce_request_id = prediction.request_id "+
+		"WHERE %s;", postgres.SolutionResultTableName, postgres.SolutionTableName, postgres.PredictionTableName, where)
+
+	rows, err := s.client.Query(sql, param)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull prediction results from SQLite")
+	}
+
+	results, err := s.parseSolutionResult(rows)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to parse prediction results from SQLite")
+	}
+
+	var res *api.SolutionResult
+	if len(results) > 0 {
+		res = results[0]
+	}
+
+	return res, nil
+}
@@ -0,0 +1,364 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+// PersistRequest persists a request to SQLite.
+func (s *Storage) PersistRequest(requestID string, dataset string, progress string, createdTime time.Time) error {
+	_, err := s.client.Exec(s.builder.PersistRequest(), requestID, dataset, progress, createdTime)
+
+	return errors.Wrapf(err, "failed to persist request to SQLite")
+}
+
+// UpdateRequest updates a request in SQLite.
+func (s *Storage) UpdateRequest(requestID string, progress string, updatedTime time.Time) error {
+	_, err := s.client.Exec(s.builder.UpdateRequest(), progress, updatedTime, requestID)
+
+	return errors.Wrapf(err, "failed to update request in SQLite")
+}
+
+// PersistRequestFeature persists request feature information to SQLite.
+func (s *Storage) PersistRequestFeature(requestID string, featureName string, featureType string) error {
+	_, err := s.client.Exec(s.builder.PersistRequestFeature(), requestID, featureName, featureType)
+
+	return errors.Wrapf(err, "failed to persist request feature in SQLite")
+}
+
+// PersistRequestFilters persists request filters information to SQLite.
+func (s *Storage) PersistRequestFilters(requestID string, filters *api.FilterParams) error {
+	sql := s.builder.PersistRequestFilter()
+
+	for _, filterSet := range filters.Filters {
+		for _, filterFeatures := range filterSet.FeatureFilters {
+			for _, filter := range filterFeatures.List {
+				switch filter.Type {
+				case model.NumericalFilter:
+					_, err := s.client.Exec(sql, requestID, filter.Key, model.NumericalFilter, filter.Mode, filter.Min, filter.Max, 0, 0, 0, 0, "", "")
+					if err != nil {
+						return errors.Wrap(err, "failed to persist numerical filter")
+					}
+				case model.BivariateFilter, model.GeoBoundsFilter:
+					_, err := s.client.Exec(sql, requestID, filter.Key, filter.Type, filter.Mode, 0, 0, filter.Bounds.MinX, filter.Bounds.MaxX, filter.Bounds.MinY, filter.Bounds.MaxY, "", "")
+					if err != nil {
+						return errors.Wrap(err, "failed to persist bivariate filter")
+					}
+				case model.CategoricalFilter, model.TextFilter:
+					_, err := s.client.Exec(sql, requestID, filter.Key, filter.Type, filter.Mode, 0, 0, 0, 0, 0, 0, strings.Join(filter.Categories, ","), "")
+					if err != nil {
+						return errors.Wrap(err, "failed to persist categorical filter")
+					}
+				case model.RowFilter:
+					_, err := s.client.Exec(sql, requestID, "", model.RowFilter, filter.Mode, 0, 0, 0, 0, 0, 0, "", strings.Join(filter.D3mIndices, ","))
+					if err != nil {
+						return errors.Wrap(err, "failed to persist row filter")
+					}
+				case api.MissingFilter:
+					_, err := s.client.Exec(sql, requestID, filter.Key, api.MissingFilter, filter.Mode, 0, 0, 0, 0, 0, 0, "", "")
+					if err != nil {
+						return errors.Wrap(err, "failed to persist missing filter")
+					}
+				}
+			}
+		}
+	}
+
+	// the expression cannot be flattened into filter rows so it is stored as a document
+	if filters.Expression != nil {
+		expressionJSON, err := json.Marshal(filters.Expression)
+		if err != nil {
+			return errors.Wrap(err, "failed to serialize filter expression")
+		}
+		_, err = s.client.Exec(s.builder.PersistRequestFilterExpression(), requestID, string(expressionJSON))
+		if err != nil {
+			return errors.Wrap(err, "failed to persist filter expression")
+		}
+	}
+	return nil
+}
+
+// PersistRequestSplitManifest persists the train/test split manifest of a request to SQLite.
+func (s *Storage) PersistRequestSplitManifest(requestID string, manifest *api.SplitManifest) error {
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return errors.Wrap(err, "failed to serialize split manifest")
+	}
+
+	_, err = s.client.Exec(s.builder.PersistRequestSplitManifest(), requestID, manifest.Splitter, manifest.Seed, manifest.TrainTestSplit, string(manifestJSON), manifest.CreatedTime)
+
+	return errors.Wrapf(err, "failed to persist request split manifest to SQLite")
+}
+
+// PersistSolutionStatusEvent persists a status update of a solution request to SQLite.
+func (s *Storage) PersistSolutionStatusEvent(event *api.SolutionStatusEvent) error {
+	_, err := s.client.Exec(s.builder.PersistSolutionStatusEvent(), event.RequestID, event.SolutionID, event.ResultID, event.Progress, event.Error, event.CreatedTime)
+
+	return errors.Wrapf(err, "failed to persist solution status event to SQLite")
+}
+
+// FetchRequest pulls request information from SQLite.
+func (s *Storage) FetchRequest(requestID string) (*api.Request, error) {
+	return s.fetchSingleRequest(s.builder.FetchRequest(), requestID, fmt.Sprintf("no request %s", requestID))
+}
+
+// FetchRequestByResultUUID pulls request information from SQLite using
+// a result UUID.
+func (s *Storage) FetchRequestByResultUUID(resultUUID string) (*api.Request, error) {
+	return s.fetchSingleRequest(s.builder.FetchRequestByResultUUID(), resultUUID, fmt.Sprintf("no request for result UUID %s", resultUUID))
+}
+
+// FetchRequestBySolutionID pulls request information from SQLite using
+// a solution ID.
+func (s *Storage) FetchRequestBySolutionID(solutionID string) (*api.Request, error) {
+	return s.fetchSingleRequest(s.builder.FetchRequestBySolutionID(), solutionID, fmt.Sprintf("no request for solution %s", solutionID))
+}
+
+// FetchRequestByFittedSolutionID pulls request information from SQLite using
+// a fitted solution ID.
+func (s *Storage) FetchRequestByFittedSolutionID(fittedSolutionID string) (*api.Request, error) {
+	return s.fetchSingleRequest(s.builder.FetchRequestByFittedSolutionID(), fittedSolutionID, fmt.Sprintf("no request for solution %s", fittedSolutionID))
+}
+
+func (s *Storage) fetchSingleRequest(sql string, param string, notFound string) (*api.Request, error) {
+	requests, err := s.fetchRequests(sql, param)
+	if err != nil {
+		return nil, err
+	}
+	if len(requests) == 0 {
+		return nil, errors.New(notFound)
+	}
+
+	return requests[0], nil
+}
+
+// fetchRequests reads all the requests returned by the query before loading
+// their features and filters since the rows hold the only connection.
+func (s *Storage) fetchRequests(sql string, params ...interface{}) ([]*api.Request, error) {
+	rows, err := s.client.Query(sql, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull request from SQLite")
+	}
+	requests, err := scanRequests(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, request := range requests {
+		request.Features, err = s.FetchRequestFeatures(request.RequestID)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to get request features from SQLite")
+		}
+
+		request.Filters, err = s.FetchRequestFilters(request.RequestID, request.Features)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to get request filters from SQLite")
+		}
+	}
+
+	return requests, nil
+}
+
+func scanRequests(rows *sql.Rows) ([]*api.Request, error) {
+	defer rows.Close()
+
+	requests := []*api.Request{}
+	for rows.Next() {
+		request := &api.Request{}
+		err := rows.Scan(&request.RequestID, &request.Dataset, &request.Progress, &request.CreatedTime, &request.LastUpdatedTime)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to parse request from SQLite")
+		}
+		requests = append(requests, request)
+	}
+	err := rows.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading data from sqlite")
+	}
+
+	return requests, nil
+}
+
+// FetchRequestFeatures pulls request feature information from SQLite.
+func (s *Storage) FetchRequestFeatures(requestID string) ([]*api.Feature, error) {
+	rows, err := s.client.Query(s.builder.FetchRequestFeatures(), requestID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull request features from SQLite")
+	}
+	defer rows.Close()
+
+	results := make([]*api.Feature, 0)
+	for rows.Next() {
+		feature := &api.Feature{}
+		err = rows.Scan(&feature.RequestID, &feature.FeatureName, &feature.FeatureType)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to parse request features from SQLite")
+		}
+		results = append(results, feature)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading data from sqlite")
+	}
+
+	return results, nil
+}
+
+// FetchRequestSplitManifest pulls the train/test split manifest of a request from SQLite.
+func (s *Storage) FetchRequestSplitManifest(requestID string) (*api.SplitManifest, error) {
+	var manifestJSON string
+	err := s.client.QueryRow(s.builder.FetchRequestSplitManifest(), requestID).Scan(&manifestJSON)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.Errorf("no split manifest for request %s", requestID)
+		}
+		return nil, errors.Wrap(err, "Unable to pull request split manifest from SQLite")
+	}
+
+	manifest := &api.SplitManifest{}
+	err = json.Unmarshal([]byte(manifestJSON), manifest)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to parse request split manifest")
+	}
+
+	return manifest, nil
+}
+
+// fetchRequestFilterExpression pulls the filter expression of a request, if any.
+func (s *Storage) fetchRequestFilterExpression(requestID string) (*api.FilterExpression, error) {
+	var expressionJSON string
+	err := s.client.QueryRow(s.builder.FetchRequestFilterExpression(), requestID).Scan(&expressionJSON)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "Unable to pull request filter expression from SQLite")
+	}
+
+	expression := &api.FilterExpression{}
+	err = json.Unmarshal([]byte(expressionJSON), expression)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to parse request filter expression")
+	}
+
+	return expression, nil
+}
+
+// FetchRequestFilters pulls request filter information from SQLite.
+func (s *Storage) FetchRequestFilters(requestID string, features []*api.Feature) (*api.FilterParams, error) {
+	expression, err := s.fetchRequestFilterExpression(requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.client.Query(s.builder.FetchRequestFilters(), requestID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull request filters from SQLite")
+	}
+	defer rows.Close()
+
+	filters := []*model.Filter{}
+	for rows.Next() {
+		var featureName string
+		var filterType string
+		var filterMode string
+		var filterMin float64
+		var filterMax float64
+		var filterMinX float64
+		var filterMaxX float64
+		var filterMinY float64
+		var filterMaxY float64
+		var filterCategories string
+		var filterIndices string
+
+		err = rows.Scan(&featureName, &filterType, &filterMode, &filterMin, &filterMax, &filterMinX, &filterMaxX, &filterMinY, &filterMaxY, &filterCategories, &filterIndices)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to parse request filters from SQLite")
+		}
+
+		switch filterType {
+		case model.CategoricalFilter:
+			filters = append(filters, model.NewCategoricalFilter(featureName, filterMode, strings.Split(filterCategories, ",")))
+		case model.TextFilter:
+			filters = append(filters, model.NewTextFilter(featureName, filterMode, strings.Split(filterCategories, ",")))
+		case model.NumericalFilter:
+			filters = append(filters, model.NewNumericalFilter(featureName, filterMode, filterMin, filterMax))
+		case model.BivariateFilter:
+			filters = append(filters, model.NewBivariateFilter(featureName, filterMode, filterMinX, filterMaxX, filterMinY, filterMaxY))
+		case model.GeoBoundsFilter:
+			filters = append(filters, model.NewGeoBoundsFilter(featureName, filterMode, filterMinX, filterMaxX, filterMinY, filterMaxY))
+		case model.RowFilter:
+			filters = append(filters, model.NewRowFilter(filterMode, strings.Split(filterIndices, ",")))
+		case api.MissingFilter:
+			filters = append(filters, api.NewMissingFilter(featureName, filterMode))
+		}
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading data from sqlite")
+	}
+
+	filterParams := api.NewFilterParamsFromFilters(filters)
+	filterParams.Expression = expression
+
+	for _, feature := range features {
+		filterParams.Variables = append(filterParams.Variables, feature.FeatureName)
+	}
+
+	return filterParams, nil
+}
+
+// FetchRequestByDatasetTarget pulls requests associated with a given dataset and target from sqlite.
+func (s *Storage) FetchRequestByDatasetTarget(dataset string, target string) ([]*api.Request, error) {
+	query, params := s.builder.FetchRequestByDatasetTarget(dataset, target)
+
+	return s.fetchRequests(query, params...)
+}
+
+// FetchSolutionStatusEvents pulls the status updates of a solution request from SQLite, in the order
+// they were persisted.
+func (s *Storage) FetchSolutionStatusEvents(requestID string) ([]*api.SolutionStatusEvent, error) {
+	rows, err := s.client.Query(s.builder.FetchSolutionStatusEvents(), requestID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to pull solution status events from SQLite")
+	}
+	defer rows.Close()
+
+	events := []*api.SolutionStatusEvent{}
+	for rows.Next() {
+		event := &api.SolutionStatusEvent{}
+		err = rows.Scan(&event.RequestID, &event.SolutionID, &event.ResultID, &event.Progress, &event.Error, &event.CreatedTime)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to parse solution status event from SQLite")
+		}
+		events = append(events, event)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading data from sqlite")
+	}
+
+	return events, nil
+}
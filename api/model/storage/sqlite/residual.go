@@ -0,0 +1,191 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlite
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/model/storage/sqlbuilder"
+)
+
+// FetchResidualsExtremaByURI fetches the residual extrema by resultURI.
+func (s *Storage) FetchResidualsExtremaByURI(dataset string, storageName string, resultURI string) (*api.Extrema, error) {
+	storageNameResult := s.getResultTable(storageName)
+	targetName, err := s.getResultTargetName(storageNameResult, resultURI)
+	if err != nil {
+		return nil, err
+	}
+	targetVariable, err := s.getResultTargetVariable(dataset, targetName)
+	if err != nil {
+		return nil, err
+	}
+	resultVariable := &model.Variable{
+		Key:  "value",
+		Type: model.StringType,
+	}
+	return s.fetchResidualsExtrema(resultURI, storageName, targetVariable, resultVariable)
+}
+
+// FetchResidualsSummary fetches a histogram of the residuals associated with a set of numerical predictions.
+func (s *Storage) FetchResidualsSummary(dataset string, storageName string, resultURI string, filterParams *api.FilterParams, extrema *api.Extrema, mode api.SummaryMode) (*api.VariableSummary, error) {
+	storageNameResult := s.getResultTable(storageName)
+	targetName, err := s.getResultTargetName(storageNameResult, resultURI)
+	if err != nil {
+		return nil, err
+	}
+
+	variable, err := s.getResultTargetVariable(dataset, targetName)
+	if err != nil {
+		return nil, err
+	}
+
+	var baseline *api.Histogram
+	var filtered *api.Histogram
+	baseline, err = s.fetchResidualsSummary(dataset, storageName, variable, resultURI, nil, extrema, api.MaxNumBuckets, mode)
+	if err != nil {
+		return nil, err
+	}
+	if !filterParams.IsEmpty(true) {
+		filtered, err = s.fetchResidualsSummary(dataset, storageName, variable, resultURI, filterParams, extrema, api.MaxNumBuckets, mode)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &api.VariableSummary{
+		Label:    variable.DisplayName,
+		Key:      variable.Key,
+		Type:     model.NumericalType,
+		VarType:  variable.Type,
+		Baseline: baseline,
+		Filtered: filtered,
+	}, nil
+}
+
+func (s *Storage) fetchResidualsSummary(dataset string, storageName string, variable *model.Variable, resultURI string, filterParams *api.FilterParams, extrema *api.Extrema, numBuckets int, mode api.SummaryMode) (*api.Histogram, error) {
+	// Just return a nil in the case where we were asked to return residuals for a non-numeric variable.
+	if model.IsNumerical(variable.Type) || variable.Type == model.TimeSeriesType {
+		// update the highlight key to use the cluster if necessary
+		if err := updateClusterFilters(s.metadata, dataset, filterParams, mode); err != nil {
+			return nil, err
+		}
+
+		// fetch numeric histograms
+		residuals, err := s.fetchResidualsHistogram(resultURI, dataset, storageName, variable, filterParams, extrema, numBuckets)
+		if err != nil {
+			return nil, err
+		}
+		return residuals, nil
+	}
+	return nil, errors.Errorf("variable of type %s - should be numeric", variable.Type)
+}
+
+func (s *Storage) getResidualsHistogramAgg(extrema *api.Extrema, variableName string, resultVariable *model.Variable, numBuckets int, alias string) *sqlbuilder.HistogramAgg {
+	// compute the bucket interval for the histogram
+	interval := extrema.GetBucketInterval(numBuckets)
+	rounded := extrema.GetBucketMinMax(numBuckets)
+
+	// Only numeric types should occur.
+	errorTyped := s.builder.ErrorTyped(alias, variableName)
+
+	return s.builder.HistogramAgg(extrema.Key, errorTyped, rounded.Min, rounded.Max, interval, extrema.GetBucketCount(numBuckets))
+}
+
+func (s *Storage) getResidualsMinMaxAggsQuery(variableName string, resultVariable *model.Variable) string {
+	// Only numeric types should occur.
+	errorTyped := s.builder.ErrorTyped("", variableName)
+
+	return s.builder.MinMax(resultVariable.Key, errorTyped)
+}
+
+func (s *Storage) fetchResidualsExtrema(resultURI string, storageName string, variable *model.Variable,
+	resultVariable *model.Variable) (*api.Extrema, error) {
+
+	targetName := variable.Key
+	if model.IsTimeSeries(variable.Type) {
+		targetName = variable.Grouping.(*model.TimeseriesGrouping).YCol
+	}
+	// add min / max aggregation
+	aggQuery := s.getResidualsMinMaxAggsQuery(targetName, resultVariable)
+
+	// from clause to join result and base data
+	fromClause := sqlbuilder.ResultJoin("res", storageName)
+
+	// create a query that does min and max aggregations for each variable
+	wheres := []string{
+		fmt.Sprintf("result_id = %s", s.builder.Param(1)),
+		fmt.Sprintf("target = %s", s.builder.Param(2)),
+		"value != ''",
+	}
+	queryString := s.builder.Select(aggQuery, fromClause, wheres)
+
+	return s.parseExtrema(s.client.QueryRow(queryString, resultURI, variable.Key), variable)
+}
+
+func (s *Storage) fetchResidualsHistogram(resultURI string, datasetName, storageName string, variable *model.Variable, filterParams *api.FilterParams,
+	extrema *api.Extrema, numBuckets int) (*api.Histogram, error) {
+	resultVariable := &model.Variable{
+		Key:  "value",
+		Type: model.StringType,
+	}
+
+	// need the extrema to calculate the histogram interval
+	var err error
+	if extrema == nil {
+		extrema, err = s.fetchResidualsExtrema(resultURI, storageName, variable, resultVariable)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to fetch result variable extrema for summary")
+		}
+	} else {
+		extrema.Key = variable.Key
+		extrema.Type = variable.Type
+	}
+	variableName := variable.Key
+	if model.IsTimeSeries(variable.Type) {
+		variableName = variable.Grouping.(*model.TimeseriesGrouping).YCol
+	}
+	// for each returned aggregation, create a histogram aggregation. Bucket
+	// size is derived from the min/max and desired bucket count.
+	agg := s.getResidualsHistogramAgg(extrema, variableName, resultVariable, numBuckets, baseTableAlias)
+
+	fromClause := sqlbuilder.ResultJoin("result", storageName)
+
+	// create the filter for the query
+	wheres, params, err := s.buildResultQueryFilters(datasetName, storageName, resultURI, filterParams, baseTableAlias)
+	if err != nil {
+		return nil, err
+	}
+	wheres = append(wheres, s.builder.ResultTargetWhere(len(params)+1), fmt.Sprintf("result.%s != ''", resultVariable.Key))
+	params = append(params, resultURI)
+	params = append(params, variable.Key)
+
+	// Create the complete query string.
+	query := s.builder.Histogram(agg, "*", fromClause, wheres)
+
+	// execute the sqlite query
+	res, err := s.client.Query(query, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch histograms for result variable summaries from sqlite")
+	}
+	defer res.Close()
+
+	field := NewNumericalField(s, datasetName, storageName, variable.Key, variable.DisplayName, variable.Type, "")
+
+	return field.parseHistogram(res, extrema, numBuckets)
+}
@@ -0,0 +1,844 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/model/storage/sqlbuilder"
+	"github.com/uncharted-distil/distil/api/postgres"
+	"github.com/uncharted-distil/distil/api/serialization"
+	log "github.com/unchartedsoftware/plog"
+)
+
+const (
+	resultTableSuffix        = "_result"
+	featureWeightTableSuffix = "_explain"
+	dataTableAlias           = "data"
+	confidenceName           = "confidence"
+	rankName                 = "rank"
+	weightsColumnPrefix      = "__weights_"
+	predictedExplainColumn   = "__predicted_explain"
+)
+
+func (s *Storage) getResultTable(storageName string) string {
+	return fmt.Sprintf("%s%s", storageName, resultTableSuffix)
+}
+
+func (s *Storage) getSolutionFeatureWeightTable(storageName string) string {
+	return fmt.Sprintf("%s%s", storageName, featureWeightTableSuffix)
+}
+
+func (s *Storage) getResultTargetName(storageName string, resultURI string) (string, error) {
+	// Assume only a single target / result. Read the target name from the
+	// database table.
+	query := fmt.Sprintf("SELECT target FROM %s WHERE result_id = ?1 LIMIT 1;", storageName)
+
+	var targetName string
+	err := s.client.QueryRow(query, resultURI).Scan(&targetName)
+	if err == sql.ErrNoRows {
+		return "", errors.Errorf("Target feature for result URI `%s` not found", resultURI)
+	} else if err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf("Unable to get target variable name from results for result URI `%s`", resultURI))
+	}
+
+	return targetName, nil
+}
+
+func (s *Storage) getResultTargetVariable(dataset string, targetName string) (*model.Variable, error) {
+	variable, err := s.metadata.FetchVariable(dataset, targetName)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to get target variable information")
+	}
+	return variable, nil
+}
+
+// PersistSolutionFeatureWeight persists the solution feature weight to SQLite.
+func (s *Storage) PersistSolutionFeatureWeight(dataset string, storageName string, resultURI string, weights [][]string) error {
+	// weight structure is header row and then one row / d3m index
+	// keep only weights that are tied to a variable in the base dataset
+	fieldsDatabase, err := s.getDatabaseFields(s.getSolutionFeatureWeightTable(storageName))
+	if err != nil {
+		return err
+	}
+	fieldsMetadata, err := s.metadata.FetchVariables(dataset, true, true, false)
+	if err != nil {
+		return err
+	}
+	fieldsMetadataMap := mapFields(fieldsMetadata)
+
+	// build the map of shap output -> explain index
+	fieldsWeight := weights[0]
+	fieldsMap := make(map[int]int)
+	fields := []string{"result_id"}
+	for _, dbField := range fieldsDatabase {
+		for i := 0; i < len(fieldsWeight); i++ {
+			if fieldsMetadataMap[dbField] != nil && fieldsMetadataMap[dbField].Key == fieldsWeight[i] {
+				// before we append the field, the length will give us the index for the field we will append
+				fieldsMap[i] = len(fields)
+				fields = append(fields, dbField)
+			}
+		}
+	}
+
+	values := make([][]interface{}, 0)
+	for _, row := range weights[1:] {
+		// parse into floats for storage (and add result uri)
+		parsedWeights := make([]interface{}, len(fields))
+		parsedWeights[0] = resultURI
+		for i := 0; i < len(row); i++ {
+			weightIndex, ok := fieldsMap[i]
+			if ok {
+				w, err := strconv.ParseFloat(row[i], 64)
+				if err != nil {
+					return errors.Wrap(err, "failed to parse feature weight")
+				}
+				parsedWeights[weightIndex] = w
+			}
+		}
+
+		values = append(values, parsedWeights)
+	}
+
+	// batch the data to the storage
+	err = s.InsertBatch(s.getSolutionFeatureWeightTable(storageName), fields, values)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert result in database")
+	}
+
+	return nil
+}
+
+// PersistExplainedResult stores the additional explained output.
+func (s *Storage) PersistExplainedResult(dataset string, storageName string, resultURI string, explainResult *api.SolutionExplainResult) error {
+	if explainResult == nil {
+		return nil
+	}
+
+	// build the explain lookup
+	params := make([][]interface{}, 0)
+	for _, row := range explainResult.Values[1:] {
+		parsedExplainValues, err := explainResult.ParsingFunction(row)
+		if err != nil {
+			return err
+		}
+		explainJSON, err := json.Marshal(parsedExplainValues)
+		if err != nil {
+			return errors.Wrap(err, "unable to marshal explain values")
+		}
+		params = append(params, []interface{}{string(explainJSON), resultURI, row[explainResult.D3MIndexIndex]})
+	}
+
+	// sqlite updates in place cheaply so there is no need for a temp table
+	tx, err := s.client.Begin()
+	if err != nil {
+		return errors.Wrap(err, "unable to create transaction")
+	}
+
+	updateSQL := fmt.Sprintf("UPDATE %s SET explain_values = ?1 WHERE result_id = ?2 AND \"index\" = CAST(?3 AS INTEGER);",
+		s.getResultTable(storageName))
+	for _, p := range params {
+		_, err = tx.Exec(updateSQL, p...)
+		if err != nil {
+			_ = tx.Rollback()
+			return errors.Wrap(err, "unable to update explain values")
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "unable to commit explain values update")
+	}
+
+	return nil
+}
+
+func (s *Storage) hasExplainOutput(storageName string, resultURI string) (bool, error) {
+	query := fmt.Sprintf("SELECT COUNT(explain_values) FROM %s WHERE result_id = ?1;", s.getResultTable(storageName))
+
+	var count int
+	err := s.client.QueryRow(query, resultURI).Scan(&count)
+	if err != nil {
+		return false, errors.Wrapf(err, "unable execute query to verify presence of explain output")
+	}
+
+	return count > 0, nil
+}
+
+// FetchResultDataset extracts the complete results and base table data.
+func (s *Storage) FetchResultDataset(dataset string, storageName string, predictionName string,
+	features []string, resultURI string, includeExplain bool) ([][]string, error) {
+	fields := []string{}
+	for _, v := range features {
+		fields = append(fields, fmt.Sprintf("COALESCE(\"%s\", '') AS \"%s\"", v, v))
+	}
+	fields = append(fields, fmt.Sprintf("result.value AS \"%s\"", predictionName))
+	if includeExplain {
+		// make sure there is explain output before including it!
+		hasExplain, err := s.hasExplainOutput(storageName, resultURI)
+		if err != nil || hasExplain {
+			fields = append(fields, fmt.Sprintf("result.explain_values AS \"_explain_%s\"", predictionName))
+		}
+	}
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s base
+		INNER JOIN %s result on CAST(base."%s" AS INTEGER) = result."index"
+		WHERE result.result_id = ?1;`,
+		strings.Join(fields, ", "), getBaseTableName(storageName), s.getResultTable(storageName), model.D3MIndexFieldName)
+	res, err := s.client.Query(query, resultURI)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable execute query to extract dataset")
+	}
+	defer res.Close()
+
+	return s.parseData(res)
+}
+
+// FetchExplainValues : fetches fetches explain values
+func (s *Storage) FetchExplainValues(dataset string, storageName string, d3mIndex []int, resultUUID string) ([]api.SolutionExplainValues, error) {
+	// create the filter for the query.
+	params := []interface{}{resultUUID}
+	indices := make([]string, len(d3mIndex))
+	for i, index := range d3mIndex {
+		params = append(params, index)
+		indices[i] = fmt.Sprintf("?%d", len(params))
+	}
+
+	query := fmt.Sprintf(`
+	SELECT r.%s
+	FROM %s AS r INNER JOIN %s AS s ON r.result_id = s.result_uri
+	WHERE s.solution_id = ?1 AND r."index" IN (%s)
+	LIMIT %d`,
+		model.ExplainValues, s.getResultTable(storageName), postgres.SolutionResultTableName,
+		strings.Join(indices, ", "), len(d3mIndex))
+	res, err := s.client.Query(query, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch explanation values from sqlite")
+	}
+	defer res.Close()
+
+	result := make([]api.SolutionExplainValues, 0)
+	for res.Next() {
+		buffer := api.SolutionExplainValues{GradCAM: [][]float64{{0, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}}}
+		var explainJSON sql.NullString
+		err := res.Scan(&explainJSON)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan explanation values from sqlite")
+		}
+		if explainJSON.Valid {
+			err = json.Unmarshal([]byte(explainJSON.String), &buffer)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to parse explanation values")
+			}
+		}
+		result = append(result, buffer)
+	}
+	err = res.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading data from sqlite")
+	}
+
+	return result, nil
+}
+
+// PersistResult stores the solution result to SQLite.
+func (s *Storage) PersistResult(dataset string, storageName string, resultURI string, target string) error {
+	// Read the results file.
+	datasetStorage := serialization.GetStorage(resultURI)
+	records, err := datasetStorage.ReadData(resultURI)
+	if err != nil {
+		return err
+	}
+
+	// currently only support a single result column.
+	if len(records[0]) > 4 {
+		log.Warnf("Result contains %d columns, expected 2, 3 or 4 (explanations).  Additional columns will be ignored.", len(records[0]))
+	}
+
+	// Fetch the actual target variable (this can be different than the requested target for grouped variables)
+	targetVariable, err := s.getResultTargetVariable(dataset, target)
+	if err != nil {
+		return err
+	}
+
+	// Translate from display name to storage name.
+	targetHeaderName, err := s.getHeaderName(dataset, targetVariable.Key)
+	if err != nil {
+		return errors.Wrap(err, "unable to map target name")
+	}
+
+	// We can't guarantee that the order of the variables in the returned result matches our
+	// locally stored indices, so we fetch by name from the source to be safe.
+	fieldsHeader := map[string]int{}
+	for i, v := range records[0] {
+		fieldsHeader[v] = i
+	}
+	targetIndex, ok := fieldsHeader[targetHeaderName]
+	if !ok {
+		return errors.Errorf("unable to find target col '%s' in result header", targetHeaderName)
+	}
+	d3mIndexIndex, ok := fieldsHeader[model.D3MIndexFieldName]
+	if !ok {
+		return errors.Errorf("unabled to find d3m index col '%s' in result header", model.D3MIndexFieldName)
+	}
+	confidenceIndex, ok := fieldsHeader[confidenceName]
+	if !ok {
+		confidenceIndex = -1
+	}
+	rankIndex, ok := fieldsHeader[rankName]
+	if !ok {
+		rankIndex = -1
+	}
+
+	// build the batch data
+	insertData := make([][]interface{}, 0)
+	indicesParsed := make(map[int64]bool)
+	for i := 1; i < len(records); i++ {
+		// Each data row is index, target.
+		// handle the parsed result/error - should be an int some TA2 systems return floats
+		parsedValFloat, err := strconv.ParseFloat(records[i][d3mIndexIndex], 64)
+		if err != nil {
+			return errors.Wrap(err, "failed csv index parsing")
+		}
+		parsedVal := int64(parsedValFloat)
+
+		// assume (FOR NOW!!!) multi index results will be the same for a given
+		// d3m index so store only 1 / index to not have duplicate query results
+		if indicesParsed[parsedVal] {
+			continue
+		}
+		indicesParsed[parsedVal] = true
+
+		dataForInsert := []interface{}{resultURI, parsedVal, targetVariable.Key, records[i][targetIndex]}
+		explainValues, err := s.parseExplainValues(records[i], confidenceIndex, rankIndex)
+		if err != nil {
+			return err
+		}
+		if explainValues != nil {
+			explainJSON, err := json.Marshal(explainValues)
+			if err != nil {
+				return errors.Wrap(err, "unable to marshal explain values")
+			}
+			dataForInsert = append(dataForInsert, string(explainJSON))
+		}
+
+		insertData = append(insertData, dataForInsert)
+	}
+
+	fields := []string{"result_id", "index", "target", "value"}
+	if confidenceIndex+rankIndex > -2 {
+		fields = append(fields, "explain_values")
+	}
+
+	// store all results to the storage
+	err = s.InsertBatch(s.getResultTable(storageName), fields, insertData)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert result in database")
+	}
+
+	return nil
+}
+
+func (s *Storage) parseExplainValues(record []string, confidenceIndex int, rankIndex int) (*api.SolutionExplainValues, error) {
+	// -1 + -1 = -2 => no confidence nor ranking
+	if confidenceIndex+rankIndex == -2 {
+		return nil, nil
+	}
+
+	// can have ranking, confidence or both
+	explain := &api.SolutionExplainValues{}
+	if confidenceIndex >= 0 {
+		cfs, err := strconv.ParseFloat(record[confidenceIndex], 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed confidence value parsing")
+		}
+		explain.Confidence = cfs
+	}
+	if rankIndex >= 0 {
+		rs, err := strconv.ParseFloat(record[rankIndex], 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed rank value parsing")
+		}
+		explain.Rank = rs
+	}
+
+	return explain, nil
+}
+
+func (s *Storage) parseFilteredResults(variables []*model.Variable, rows *sql.Rows, target *model.Variable) (*api.FilteredData, error) {
+	result := &api.FilteredData{
+		Values:  make([][]*api.FilteredDataValue, 0),
+		Columns: map[string]*api.Column{},
+	}
+
+	fields, err := rows.Columns()
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to extract fields from query result")
+	}
+
+	// Parse the columns. Weights and explain values are matched by name
+	// rather than position and are not returned as columns.
+	columnKeys := make([]string, len(fields))
+	predictedCol := -1
+	explainCol := -1
+	for i, key := range fields {
+		var label, typ string
+		if api.IsPredictedKey(key) {
+			label = "Predicted " + api.StripKeySuffix(key)
+			typ = target.Type
+			predictedCol = len(result.Columns)
+		} else if api.IsErrorKey(key) {
+			label = "Error"
+			typ = target.Type
+		} else if strings.HasPrefix(key, weightsColumnPrefix) {
+			continue
+		} else if key == predictedExplainColumn {
+			explainCol = i
+			continue
+		} else {
+			v := getVariableByKey(key, variables)
+			key = v.Key
+			label = v.DisplayName
+			if key == target.Key {
+				typ = target.Type
+			} else {
+				typ = v.Type
+			}
+		}
+
+		columnKeys[i] = key
+		result.Columns[key] = &api.Column{
+			Key:   key,
+			Label: label,
+			Type:  typ,
+			Index: len(result.Columns),
+		}
+	}
+
+	// Parse the row data.
+	for rows.Next() {
+		columnValues, err := scanValues(rows, len(fields))
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to extract fields from query result")
+		}
+
+		weightedValues := make([]*api.FilteredDataValue, len(result.Columns))
+		for i, key := range columnKeys {
+			if key != "" {
+				column := result.Columns[key]
+				weightedValues[column.Index] = &api.FilteredDataValue{
+					Value: parseValue(columnValues[i], column.Type),
+				}
+			}
+		}
+
+		// match weights with values by name (skip the d3m index weight)
+		for i, field := range fields {
+			if !strings.HasPrefix(field, weightsColumnPrefix) {
+				continue
+			}
+			key := strings.TrimPrefix(field, weightsColumnPrefix)
+			column := result.Columns[key]
+			weight, ok := toFloat(columnValues[i])
+			if ok && column != nil && key != model.D3MIndexFieldName {
+				weightedValues[column.Index].Weight = weight
+			}
+		}
+
+		if explainCol >= 0 && columnValues[explainCol] != nil {
+			explainValuesParsed := &api.SolutionExplainValues{}
+			err = json.Unmarshal([]byte(toString(columnValues[explainCol])), explainValuesParsed)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to parse explain values")
+			}
+			weightedValues[predictedCol].Rank = api.NullableFloat64(explainValuesParsed.Rank)
+			weightedValues[predictedCol].Confidence = api.NullableFloat64(explainValuesParsed.Confidence)
+		} else if predictedCol >= 0 {
+			weightedValues[predictedCol].Confidence = api.NullableFloat64(math.NaN())
+		}
+		result.Values = append(result.Values, weightedValues)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading data from sqlite")
+	}
+
+	return result, nil
+}
+
+func addTableAlias(prefix string, fields []string, addToColumn bool) []string {
+	fieldsPrepended := make([]string, len(fields))
+	for i, f := range fields {
+		// field name is quoted so need to prefix accordingly
+		name := f
+		if addToColumn {
+			unquoted := name[1 : len(name)-1]
+			name = fmt.Sprintf("%s AS \"__%s_%s\"", name, prefix, unquoted)
+		}
+		fieldsPrepended[i] = fmt.Sprintf("%s.%s", prefix, name)
+	}
+
+	return fieldsPrepended
+}
+
+// FetchResults pulls the results from the SQLite database.  Note the generalized `id` string parameter - this will be
+// a solution ID if this is a solution result, or a produce request ID is this is a predictions result.
+func (s *Storage) FetchResults(dataset string, storageName string, resultURI string, id string, filterParams *api.FilterParams, predictionResultMode bool) (*api.FilteredData, error) {
+	storageNameResult := s.getResultTable(storageName)
+	targetName, err := s.getResultTargetName(storageNameResult, resultURI)
+	if err != nil {
+		return nil, err
+	}
+
+	// fetch the variable info to resolve its type - skip the first column since that will be the d3m_index value
+	variable, err := s.getResultTargetVariable(dataset, targetName)
+	if err != nil {
+		return nil, err
+	}
+
+	// fetch variable metadata
+	variables, err := s.metadata.FetchVariables(dataset, true, true, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not pull variables from metadata storage")
+	}
+
+	// generate variable list for inclusion in query select
+	distincts, fields, err := s.buildFilteredResultQueryField(variables, variable, filterParams.Variables)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not build field list")
+	}
+	fieldsData := addTableAlias(dataTableAlias, fields, false)
+	fieldsExplain := addTableAlias("weights", fields, true)
+
+	wheres := make([]string, 0)
+	params := make([]interface{}, 0)
+
+	// need to create all filters by mode
+	for _, filterSet := range append(filterParams.Filters, filterParams.Highlights...) {
+		// break filters out groups for specific handling
+		filters, err := sqlbuilder.SplitFilters(filterSet)
+		if err != nil {
+			return nil, err
+		}
+
+		wheresMode := make([]string, 0)
+		// Create the filter portion of the where clause.
+		where := ""
+		where, params, err = s.builder.SelectionFilter(dataset, params, dataTableAlias, filters.Generic)
+		if err != nil {
+			return nil, err
+		}
+		if len(where) > 0 {
+			wheresMode = append(wheresMode, where)
+		}
+
+		// Add the predicted filter into the where clause if it was included in the filter set
+		for _, pf := range filters.Predicted {
+			wheresMode, params, err = s.builder.PredictedWhere(wheresMode, params, "predicted", pf)
+			if err != nil {
+				return nil, errors.Wrap(err, "Could not add result to where clause")
+			}
+		}
+
+		// Add the correctness filter into the where clause if it was included in the filter set
+		for _, cf := range filters.Correctness {
+			wheresMode, params, err = s.builder.CorrectnessWhere(wheresMode, params, "predicted", variable.Key, cf)
+			if err != nil {
+				return nil, errors.Wrap(err, "Could not add result to where clause")
+			}
+		}
+
+		// Add the error filter into the where clause if it was included in the filter set
+		for _, rf := range filters.Residual {
+			wheresMode, params = s.builder.ErrorWhere(wheresMode, params, dataTableAlias, targetName, rf)
+		}
+
+		// Add the error filter into the where clause if it was included in the filter set
+		// note that all special result filters CANNOT be exclusions
+		for _, cf := range filters.Confidence {
+			wheresMode, params = s.builder.ConfidenceWhere(wheresMode, params, cf, "predicted")
+		}
+		for _, rf := range filters.Rank {
+			wheresMode, params = s.builder.RankWhere(wheresMode, params, rf, "predicted")
+		}
+
+		if len(wheresMode) > 0 {
+			wheres = append(wheres, sqlbuilder.CombineClauses(filterSet.Mode, wheresMode, "AND"))
+		}
+	}
+	wheres, params, err = s.builder.FilterExpressionWhere(dataset, wheres, params, dataTableAlias, filterParams)
+	if err != nil {
+		return nil, err
+	}
+	// If this is a timeseries forecast we don't want to include the target, predicted target or error
+	// info in the returned data.  That information is fetched on a per-timeseries basis using the info
+	// provided by this call.
+	selectedVars := ""
+	if isTimeSeriesValue(variables, variable) {
+		selectedVars = fmt.Sprintf("%s ", strings.Join(fieldsData, ", "))
+	} else {
+		predictedCol := api.GetPredictedKey(id)
+		// If our results are numerical we need to compute residuals and store them in a column called 'error'
+		errorCol := api.GetErrorKey(id)
+		errorExpr := ""
+		if model.IsNumerical(variable.Type) && !predictionResultMode {
+			errorExpr = fmt.Sprintf("%s AS \"%s\",", s.builder.ErrorTyped(dataTableAlias, variable.Key), errorCol)
+		}
+
+		targetColumnQuery := ""
+		if !predictionResultMode {
+			targetColumnQuery = fmt.Sprintf("data.\"%s\" AS \"%s\", ", targetName, targetName)
+		}
+
+		selectedVars = fmt.Sprintf("predicted.value AS \"%s\", predicted.explain_values AS \"%s\", %s %s %s, %s ",
+			predictedCol, predictedExplainColumn, targetColumnQuery, errorExpr, strings.Join(fieldsData, ", "), strings.Join(fieldsExplain, ", "))
+	}
+
+	wheres = append(wheres, fmt.Sprintf("predicted.result_id = %s", s.builder.Param(len(params)+1)))
+	wheres = append(wheres, fmt.Sprintf("predicted.target = %s", s.builder.Param(len(params)+2)))
+	wheres = append(wheres, "predicted.value != ''")
+	params = append(params, resultURI)
+	params = append(params, targetName)
+
+	whereStatement := strings.Join(wheres, " AND ")
+
+	// sqlite has no DISTINCT ON so a single row per group is kept by grouping
+	// on the distinct columns
+	groupBy := ""
+	if len(distincts) > 0 {
+		groupBy = fmt.Sprintf(" GROUP BY %s", strings.Join(addTableAlias(dataTableAlias, distincts, false), ", "))
+	}
+
+	query := fmt.Sprintf(
+		"SELECT %s"+
+			"FROM %s AS predicted INNER JOIN %s AS data ON data.\"%s\" = predicted.\"index\" "+
+			"LEFT OUTER JOIN %s AS weights ON weights.\"%s\" = predicted.\"index\" AND weights.result_id = predicted.result_id "+
+			"WHERE %s%s",
+		selectedVars, storageNameResult, storageName, model.D3MIndexFieldName,
+		s.getSolutionFeatureWeightTable(storageName), model.D3MIndexFieldName,
+		whereStatement, groupBy)
+
+	// Do not return the whole result set to the client.
+	query = fmt.Sprintf("%s LIMIT %d;", query, filterParams.Size)
+
+	rows, err := s.client.Query(query, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error querying results")
+	}
+	filteredData, err := s.parseFilteredResults(variables, rows, variable)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	joinDef := &joinDefinition{
+		baseAlias:     "data",
+		baseColumn:    model.D3MIndexFieldName,
+		joinTableName: storageNameResult,
+		joinAlias:     "predicted",
+		joinColumn:    "index",
+	}
+	numRows, err := s.fetchNumRowsJoined(storageName, variables, []string{fmt.Sprintf("predicted.result_id = %s", s.builder.Param(1))}, []interface{}{resultURI}, joinDef)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not pull num rows")
+	}
+	numRowsFiltered, err := s.fetchNumRowsJoined(storageName, variables, wheres, params, joinDef)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not pull filtered num rows")
+	}
+	filteredData.NumRows = numRows
+	filteredData.NumRowsFiltered = numRowsFiltered
+
+	weights, err := s.getAverageWeights(dataset, storageName, storageNameResult, resultURI, variables, whereStatement, params)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range filteredData.Columns {
+		c.Weight = weights[c.Key]
+	}
+
+	return filteredData, nil
+}
+
+func (s *Storage) getAverageWeights(dataset string, storageName string, storageNameResult string, resultURI string,
+	variables []*model.Variable, whereStatement string, params []interface{}) (map[string]float64, error) {
+	variablesSQL := []string{}
+	for _, v := range variables {
+		if v.IsTA2Field() && !model.IsIndexRole(v.SelectedRole) && !v.IsGrouping() {
+			variablesSQL = append(variablesSQL, fmt.Sprintf("AVG(weights.\"%s\") AS \"%s\"", v.Key, v.Key))
+		}
+	}
+	if len(variablesSQL) == 0 {
+		return map[string]float64{}, nil
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s AS weights INNER JOIN %s AS data ON data.\"%s\" = weights.\"%s\" "+
+		"INNER JOIN %s AS predicted ON data.\"%s\" = predicted.\"index\" WHERE %s",
+		strings.Join(variablesSQL, ", "), s.getSolutionFeatureWeightTable(storageName),
+		storageName, model.D3MIndexFieldName, model.D3MIndexFieldName, storageNameResult, model.D3MIndexFieldName, whereStatement)
+	rows, err := s.client.Query(query, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to query for average result weights")
+	}
+	defer rows.Close()
+
+	featureWeights, err := s.parseSolutionFeatureWeight(resultURI, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return featureWeights.Weights, nil
+}
+
+// FetchResultsExtremaByURI fetches the results extrema by resultURI.
+func (s *Storage) FetchResultsExtremaByURI(dataset string, storageName string, resultURI string) (*api.Extrema, error) {
+	storageNameResult := s.getResultTable(storageName)
+	targetName, err := s.getResultTargetName(storageNameResult, resultURI)
+	if err != nil {
+		return nil, err
+	}
+	targetVariable, err := s.getResultTargetVariable(dataset, targetName)
+	if err != nil {
+		return nil, err
+	}
+
+	resultVariable := &model.Variable{
+		Key:  "value",
+		Type: model.StringType,
+	}
+
+	field := NewNumericalField(s, dataset, storageName, targetVariable.Key, targetVariable.DisplayName, targetVariable.Type, "")
+
+	return field.fetchResultsExtrema(resultURI, storageNameResult, resultVariable)
+}
+
+// FetchPredictedSummary gets the summary data about a target variable from the
+// results table.
+func (s *Storage) FetchPredictedSummary(dataset string, storageName string, resultURI string, filterParams *api.FilterParams, extrema *api.Extrema, mode api.SummaryMode) (*api.VariableSummary, error) {
+	storageNameResult := s.getResultTable(storageName)
+	weightTableName := s.getSolutionFeatureWeightTable(storageName)
+	targetName, err := s.getResultTargetName(storageNameResult, resultURI)
+	if err != nil {
+		return nil, err
+	}
+
+	variable, err := s.getResultTargetVariable(dataset, targetName)
+	if err != nil {
+		return nil, err
+	}
+
+	countCol, err := s.getCountCol(dataset, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	// use the variable type to guide the summary creation
+	var field Field
+	if model.IsNumerical(variable.Type) {
+		field = NewNumericalField(s, dataset, storageName, variable.Key, variable.DisplayName, variable.Type, countCol)
+	} else if model.IsCategorical(variable.Type) {
+		field = NewCategoricalField(s, dataset, storageName, variable.Key, variable.DisplayName, variable.Type, countCol)
+	} else if model.IsVector(variable.Type) {
+		field = NewVectorField(s, dataset, storageName, variable.Key, variable.DisplayName, variable.Type)
+	} else if model.IsTimeSeries(variable.Type) {
+		field = NewNumericalField(s, dataset, storageName, variable.Key, variable.DisplayName, variable.Type, "")
+	} else {
+		return nil, errors.Errorf("variable %s of type %s does not support summary", variable.Key, variable.Type)
+	}
+
+	// update the highlight key to use the cluster if necessary
+	if err = updateClusterFilters(s.metadata, dataset, filterParams, mode); err != nil {
+		return nil, err
+	}
+
+	summary, err := field.FetchPredictedSummaryData(resultURI, storageNameResult, filterParams, extrema, mode)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to fetch result summary")
+	}
+
+	// add dataset
+	summary.Dataset = dataset
+
+	weighted, err := s.getIsWeighted(resultURI, weightTableName)
+	if err != nil {
+		return nil, err
+	}
+	summary.Weighted = weighted
+	return summary, nil
+}
+
+func (s *Storage) getIsWeighted(resultURI string, weightTableName string) (bool, error) {
+	query := fmt.Sprintf("SELECT EXISTS (SELECT * FROM %s WHERE result_id = ?1 LIMIT 1);", weightTableName)
+
+	var weighted bool
+	err := s.client.QueryRow(query, resultURI).Scan(&weighted)
+	if err != nil {
+		return false, errors.Wrap(err, "Unable to query weight state")
+	}
+
+	return weighted, nil
+}
+
+func (s *Storage) getHeaderName(dataset string, key string) (string, error) {
+	variable, err := s.metadata.FetchVariable(dataset, key)
+	if err != nil {
+		return "", errors.Wrap(err, "unable fetch variable for name mapping")
+	}
+
+	if variable.IsGrouping() && model.IsTimeSeries(variable.Grouping.GetType()) {
+		tsg := variable.Grouping.(*model.TimeseriesGrouping)
+		variable, err = s.metadata.FetchVariable(dataset, tsg.YCol)
+		if err != nil {
+			return "", errors.Wrap(err, "unable fetch variable for name mapping")
+		}
+	}
+
+	return variable.HeaderName, nil
+}
+
+func mapFields(fields []*model.Variable) map[string]*model.Variable {
+	mapped := make(map[string]*model.Variable)
+	for _, f := range fields {
+		mapped[f.Key] = f
+	}
+
+	return mapped
+}
+
+func isTimeSeriesValue(variables []*model.Variable, targetVariable *model.Variable) bool {
+	key := targetVariable.Key
+	if targetVariable.IsGrouping() {
+		key = targetVariable.Grouping.(*model.TimeseriesGrouping).YCol
+	}
+	for _, v := range variables {
+		if v.IsGrouping() && model.IsTimeSeries(v.Grouping.GetType()) {
+			tsg := v.Grouping.(*model.TimeseriesGrouping)
+			if tsg.YCol == key {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlite
+
+import (
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uncharted-distil/distil-compute/model"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+func TestRegressionResults(t *testing.T) {
+	storage, metaStorage := createTestStorage(t)
+	meta := ingestTestDataset(t, storage, metaStorage)
+
+	resultURI := path.Join(t.TempDir(), "predictions.csv")
+	err := ioutil.WriteFile(resultURI, []byte("d3mIndex,length\n0,2.0\n1,1.0\n2,5.0\n3,4.5\n4,6.0\n"), 0644)
+	assert.NoError(t, err)
+	assert.NoError(t, storage.PersistResult(meta.ID, meta.StorageName, resultURI, "length"))
+
+	extrema, err := storage.FetchResultsExtremaByURI(meta.ID, meta.StorageName, resultURI)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, extrema.Min)
+	assert.Equal(t, 6.0, extrema.Max)
+
+	// residuals are the predicted minus the actual values
+	extrema, err = storage.FetchResidualsExtremaByURI(meta.ID, meta.StorageName, resultURI)
+	assert.NoError(t, err)
+	assert.True(t, extrema.Min <= -0.5)
+	assert.True(t, extrema.Max >= 0.5)
+
+	results, err := storage.FetchResults(meta.ID, meta.StorageName, resultURI, "solution", &api.FilterParams{Size: 100}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, results.NumRows)
+	assert.Len(t, results.Values, 5)
+
+	// data filters apply to the results
+	filterParams := api.NewFilterParamsFromFilters([]*model.Filter{
+		model.NewCategoricalFilter("species", model.IncludeFilter, []string{"virginica"}),
+	})
+	filterParams.Size = 100
+	results, err = storage.FetchResults(meta.ID, meta.StorageName, resultURI, "solution", filterParams, false)
+	assert.NoError(t, err)
+	assert.Len(t, results.Values, 2)
+
+	summary, err := storage.FetchPredictedSummary(meta.ID, meta.StorageName, resultURI, &api.FilterParams{}, nil, api.DefaultMode)
+	assert.NoError(t, err)
+	assert.Equal(t, meta.ID, summary.Dataset)
+	assert.False(t, summary.Weighted)
+	total := int64(0)
+	for _, b := range summary.Baseline.Buckets {
+		total += b.Count
+	}
+	assert.Equal(t, int64(5), total)
+
+	// results can only be stored for variables of the dataset
+	assert.Error(t, storage.PersistResult(meta.ID, meta.StorageName, resultURI, "missing"))
+}
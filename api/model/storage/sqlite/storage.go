@@ -0,0 +1,242 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	log "github.com/unchartedsoftware/plog"
+
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/model/storage/sqlbuilder"
+	"github.com/uncharted-distil/distil/api/postgres"
+	"github.com/uncharted-distil/distil/api/sqlite"
+)
+
+const (
+	// ProvenanceStorage label for storage valid types
+	ProvenanceStorage = "storage-valid"
+)
+
+// Storage accesses the underlying embedded sqlite database. It mirrors the
+// postgres storage so that a single node deployment can run without any
+// external service. Spatial queries are not supported.
+type Storage struct {
+	client   *sql.DB
+	metadata api.MetadataStorage
+	builder  *sqlbuilder.Builder
+}
+
+// NewDataStorage returns a constructor for a data storage.
+func NewDataStorage(clientCtor sqlite.ClientCtor, metadataCtor api.MetadataStorageCtor) api.DataStorageCtor {
+	return func() (api.DataStorage, error) {
+		return newStorage(clientCtor, metadataCtor)
+	}
+}
+
+// NewSolutionStorage returns a constructor for a solution storage.
+func NewSolutionStorage(clientCtor sqlite.ClientCtor, metadataCtor api.MetadataStorageCtor) api.SolutionStorageCtor {
+	return func() (api.SolutionStorage, error) {
+		return newStorage(clientCtor, metadataCtor)
+	}
+}
+
+func newStorage(clientCtor sqlite.ClientCtor, metadataCtor api.MetadataStorageCtor) (*Storage, error) {
+	client, err := clientCtor()
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := metadataCtor()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Storage{
+		client:   client,
+		metadata: metadata,
+		builder:  sqlbuilder.NewBuilder(sqlbuilder.SQLite, metadata),
+	}, nil
+}
+
+// GetStorageName returns a valid unique name to use for a given dataset name.
+func (s *Storage) GetStorageName(dataset string) (string, error) {
+	// format normalize the dataset
+	storageName := model.NormalizeDatasetID(dataset)
+
+	// get all database tables and views
+	existingTables := map[string]bool{}
+	rows, err := s.client.Query("SELECT name FROM sqlite_master WHERE type IN ('table', 'view');")
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to get list of existing tables")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var existingName string
+		err = rows.Scan(&existingName)
+		if err != nil {
+			return "", errors.Wrapf(err, "unable to scan table name")
+		}
+		existingTables[existingName] = true
+	}
+	err = rows.Err()
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to read list of existing tables")
+	}
+
+	// get a unique value
+	currentName := storageName
+	for i := 1; existingTables[currentName]; i++ {
+		currentName = fmt.Sprintf("%s_%d", storageName, i)
+	}
+
+	return currentName, nil
+}
+
+// updateStats runs an ANALYZE query on the table specified
+func (s *Storage) updateStats(storageName string) {
+	_, err := s.client.Exec(fmt.Sprintf("ANALYZE \"%s\"", storageName))
+	if err != nil {
+		log.Warnf("error updating sqlite stats for %s: %+v", storageName, err)
+	}
+}
+
+// VerifyData checks each column in the table against every supported type, then updates what types are valid in the SuggestedType
+func (s *Storage) VerifyData(datasetID string, tableName string) error {
+	tableName = getBaseTableName(tableName)
+	ds, err := s.metadata.FetchDataset(datasetID, true, true, false)
+	if err != nil {
+		return err
+	}
+
+	// if the values of the column are valid for a type, add the potential type to column
+	for _, v := range ds.Variables {
+		// ignore index columns
+		if model.IsIndexRole(v.SelectedRole) {
+			continue
+		}
+		suggestedMap := make(map[string]bool)
+		for _, t := range v.SuggestedTypes {
+			suggestedMap[t.Type] = true
+		}
+		for _, j := range postgres.GetValidTypes() {
+			if sqlite.IsColumnType(s.client, tableName, v, j) {
+				d3mTypes, err := postgres.MapPostgresTypeToD3MType(j)
+				if err != nil {
+					continue
+				}
+				for _, k := range d3mTypes {
+					if suggestedMap[k] {
+						continue
+					}
+					suggestedMap[k] = true
+					suggestedType := model.SuggestedType{Probability: 0, Type: k, Provenance: ProvenanceStorage}
+					v.SuggestedTypes = append(v.SuggestedTypes, &suggestedType)
+				}
+			}
+		}
+	}
+
+	log.Infof("update metadata with complete list of suggested types")
+	return s.metadata.UpdateDataset(ds)
+}
+
+// scanValues reads the current row as a slice of driver values.
+func scanValues(rows *sql.Rows, count int) ([]interface{}, error) {
+	values := make([]interface{}, count)
+	pointers := make([]interface{}, count)
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	err := rows.Scan(pointers...)
+	if err != nil {
+		return nil, err
+	}
+
+	// text columns are returned as bytes
+	for i, v := range values {
+		if b, ok := v.([]byte); ok {
+			values[i] = string(b)
+		}
+	}
+
+	return values, nil
+}
+
+// toFloat converts a numeric driver value to a float.
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// toString converts a driver value to its string representation, using an
+// empty string for nulls.
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// fetchStrings runs a query returning a single text column and reads all the
+// values before releasing the connection.
+func (s *Storage) fetchStrings(query string, params ...interface{}) ([]string, error) {
+	rows, err := s.client.Query(query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := []string{}
+	for rows.Next() {
+		var value string
+		err = rows.Scan(&value)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
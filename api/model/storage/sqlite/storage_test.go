@@ -0,0 +1,243 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlite
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uncharted-distil/distil-compute/metadata"
+	"github.com/uncharted-distil/distil-compute/model"
+
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/sqlite"
+)
+
+func createTestStorage(t *testing.T) (*Storage, *MetadataStorage) {
+	clientCtor := sqlite.NewClient(path.Join(t.TempDir(), "distil.db"))
+
+	metaStorage, err := NewMetadataStorageClient(clientCtor)
+	assert.NoError(t, err)
+	assert.NoError(t, metaStorage.InitializeMetadataStorage())
+
+	client, err := clientCtor()
+	assert.NoError(t, err)
+	assert.NoError(t, sqlite.CreateSolutionStorageTables(client))
+
+	storage, err := newStorage(clientCtor, func() (api.MetadataStorage, error) { return metaStorage, nil })
+	assert.NoError(t, err)
+
+	return storage, metaStorage
+}
+
+func ingestTestDataset(t *testing.T, storage *Storage, metaStorage *MetadataStorage, extraRows ...[]string) *model.Metadata {
+	meta := model.NewMetadata("flowers", "flowers", "", "flowers")
+	dr := model.NewDataResource("learningData", model.ResTypeTable, nil)
+	dr.Variables = []*model.Variable{
+		model.NewVariable(0, "d3mIndex", "d3mIndex", "d3mIndex", "d3mIndex", model.IndexType, model.IndexType, "", []string{model.RoleIndex}, []string{model.VarDistilRoleIndex}, nil, nil, false),
+		model.NewVariable(1, "species", "species", "species", "species", model.CategoricalType, model.CategoricalType, "", []string{model.RoleAttribute}, nil, nil, nil, false),
+		model.NewVariable(2, "length", "length", "length", "length", model.RealType, model.RealType, "", []string{model.RoleAttribute}, nil, nil, nil, false),
+	}
+	meta.DataResources = []*model.DataResource{dr}
+	rows := [][]string{
+		{"0", "setosa", "1.5"},
+		{"1", "setosa", "1.0"},
+		{"2", "virginica", "5.5"},
+		{"3", "versicolor", "4.0"},
+		{"4", "virginica", "6.0"},
+	}
+	ingestTestRows(t, storage, metaStorage, meta, append(rows, extraRows...))
+
+	return meta
+}
+
+func ingestTestRows(t *testing.T, storage *Storage, metaStorage *MetadataStorage, meta *model.Metadata, rows [][]string) {
+	assert.NoError(t, metaStorage.IngestDataset(metadata.Seed, meta))
+
+	db, err := sqlite.NewDatabase(func() (*sql.DB, error) { return storage.client, nil }, 1000)
+	assert.NoError(t, err)
+	ds, err := db.InitializeDataset(meta)
+	assert.NoError(t, err)
+	assert.NoError(t, db.InitializeTable(meta.StorageName, ds))
+	assert.NoError(t, db.StoreMetadata(meta.StorageName))
+	assert.NoError(t, db.CreateResultTable(meta.StorageName))
+	for _, row := range rows {
+		assert.NoError(t, db.IngestRow(meta.StorageName, row))
+	}
+	assert.NoError(t, db.InsertRemainingRows())
+}
+
+func TestStorageEndToEnd(t *testing.T) {
+	storage, metaStorage := createTestStorage(t)
+	meta := ingestTestDataset(t, storage, metaStorage)
+
+	dataset, err := metaStorage.FetchDataset(meta.ID, false, false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, MetadataProvenance, dataset.Provenance)
+	assert.Len(t, dataset.Variables, 2)
+
+	// filter the data
+	filterParams := api.NewFilterParamsFromFilters([]*model.Filter{
+		model.NewCategoricalFilter("species", model.IncludeFilter, []string{"virginica", "versicolor"}),
+	})
+	filterParams.Size = 100
+	data, err := storage.FetchData(meta.ID, meta.StorageName, filterParams, false, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, data.NumRows)
+	assert.Equal(t, 3, data.NumRowsFiltered)
+	assert.Len(t, data.Values, 3)
+
+	// summarize a categorical and a numerical variable
+	summary, err := storage.FetchSummary(meta.ID, meta.StorageName, "species", filterParams, api.DefaultMode)
+	assert.NoError(t, err)
+	counts := map[string]int64{}
+	for _, b := range summary.Baseline.Buckets {
+		counts[b.Key] = b.Count
+	}
+	assert.Equal(t, map[string]int64{"setosa": 2, "virginica": 2, "versicolor": 1}, counts)
+
+	summary, err = storage.FetchSummary(meta.ID, meta.StorageName, "length", filterParams, api.DefaultMode)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, summary.Baseline.Extrema.Min)
+	assert.Equal(t, 6.0, summary.Baseline.Extrema.Max)
+	total := int64(0)
+	for _, b := range summary.Filtered.Buckets {
+		total += b.Count
+	}
+	assert.Equal(t, int64(3), total)
+
+	// (species = virginica AND length >= 5.8) OR species = setosa
+	expressionParams := &api.FilterParams{
+		Size: 100,
+		Expression: api.NewFilterExpression(api.FilterExpressionOr,
+			api.NewFilterExpression(api.FilterExpressionAnd,
+				api.NewFilterExpressionLeaf(model.NewCategoricalFilter("species", model.IncludeFilter, []string{"virginica"})),
+				api.NewFilterExpressionLeaf(model.NewNumericalFilter("length", model.IncludeFilter, 5.8, 10)),
+			),
+			api.NewFilterExpressionLeaf(model.NewCategoricalFilter("species", model.IncludeFilter, []string{"setosa"})),
+		),
+	}
+	data, err = storage.FetchData(meta.ID, meta.StorageName, expressionParams, false, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, data.NumRowsFiltered)
+
+	expressionParams.InvertFilters()
+	data, err = storage.FetchData(meta.ID, meta.StorageName, expressionParams, false, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, data.NumRowsFiltered)
+	expressionParams.InvertFilters()
+
+	// the expression is combined with the filter sets and inverted along with them
+	combinedParams := api.NewFilterParamsFromFilters([]*model.Filter{
+		model.NewCategoricalFilter("species", model.IncludeFilter, []string{"virginica", "versicolor"}),
+	})
+	combinedParams.Size = 100
+	combinedParams.Expression = api.NewFilterExpressionLeaf(model.NewNumericalFilter("length", model.IncludeFilter, 5, 10))
+	data, err = storage.FetchData(meta.ID, meta.StorageName, combinedParams, false, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, data.NumRowsFiltered)
+
+	combinedParams.Invert = true
+	data, err = storage.FetchData(meta.ID, meta.StorageName, combinedParams, false, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, data.NumRowsFiltered)
+
+	// the expression is stored with the request filters
+	assert.NoError(t, storage.PersistRequestFilters("request", expressionParams))
+	requestFilters, err := storage.FetchRequestFilters("request", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, expressionParams.Expression, requestFilters.Expression)
+
+	// store and read back a result
+	resultURI := path.Join(t.TempDir(), "predictions.csv")
+	err = ioutil.WriteFile(resultURI, []byte("d3mIndex,species,confidence\n0,setosa,0.9\n1,virginica,0.6\n2,virginica,0.8\n3,versicolor,0.7\n4,setosa,0.5\n"), 0644)
+	assert.NoError(t, err)
+	assert.NoError(t, storage.PersistResult(meta.ID, meta.StorageName, resultURI, "species"))
+
+	results, err := storage.FetchResults(meta.ID, meta.StorageName, resultURI, "solution", &api.FilterParams{Size: 100}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, results.NumRows)
+
+	summary, err = storage.FetchCorrectnessSummary(meta.ID, meta.StorageName, resultURI, &api.FilterParams{}, api.DefaultMode)
+	assert.NoError(t, err)
+	counts = map[string]int64{}
+	for _, b := range summary.Baseline.Buckets {
+		counts[b.Key] = b.Count
+	}
+	assert.Equal(t, map[string]int64{"Correct": 3, "Incorrect": 2}, counts)
+
+	// search the metadata
+	datasets, err := metaStorage.SearchDatasets("flower", nil, false, false, false)
+	assert.NoError(t, err)
+	assert.Len(t, datasets, 1)
+}
+
+func TestMissingValues(t *testing.T) {
+	storage, metaStorage := createTestStorage(t)
+	meta := ingestTestDataset(t, storage, metaStorage, []string{"5", "versicolor", ""}, []string{"6", "", "2.5"})
+
+	// missing numerical values
+	filterParams := api.NewFilterParamsFromFilters([]*model.Filter{
+		api.NewMissingFilter("length", model.IncludeFilter),
+	})
+	filterParams.Size = 100
+	data, err := storage.FetchData(meta.ID, meta.StorageName, filterParams, false, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, data.NumRowsFiltered)
+
+	// missing categorical values
+	filterParams = api.NewFilterParamsFromFilters([]*model.Filter{
+		api.NewMissingFilter("species", model.ExcludeFilter),
+	})
+	filterParams.Size = 100
+	data, err = storage.FetchData(meta.ID, meta.StorageName, filterParams, false, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, data.NumRowsFiltered)
+
+	// missing filters on unknown variables fail rather than being dropped
+	filterParams = api.NewFilterParamsFromFilters([]*model.Filter{
+		api.NewMissingFilter("unknown", model.IncludeFilter),
+	})
+	filterParams.Size = 100
+	_, err = storage.FetchData(meta.ID, meta.StorageName, filterParams, false, nil)
+	assert.Error(t, err)
+
+	// the missing count is reported separately from the histogram buckets
+	filterParams = api.NewFilterParamsFromFilters([]*model.Filter{
+		model.NewCategoricalFilter("species", model.IncludeFilter, []string{"versicolor"}),
+	})
+	summary, err := storage.FetchSummary(meta.ID, meta.StorageName, "length", filterParams, api.DefaultMode)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), summary.Baseline.Missing.Count)
+	assert.Equal(t, int64(1), summary.Filtered.Missing.Count)
+	total := int64(0)
+	for _, b := range summary.Baseline.Buckets {
+		total += b.Count
+	}
+	assert.Equal(t, int64(6), total)
+
+	// missing filters are stored with the request filters
+	filterParams = api.NewFilterParamsFromFilters([]*model.Filter{
+		api.NewMissingFilter("length", model.IncludeFilter),
+	})
+	assert.NoError(t, storage.PersistRequestFilters("request", filterParams))
+	requestFilters, err := storage.FetchRequestFilters("request", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, filterParams.Filters, requestFilters.Filters)
+}
@@ -0,0 +1,324 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+var (
+	wordRegex = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+	// stopWords are the common english words dropped from the summaries, which
+	// postgres does when building a text search vector.
+	stopWords = map[string]bool{
+		"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "but": true,
+		"by": true, "for": true, "from": true, "had": true, "has": true, "have": true, "he": true, "her": true,
+		"his": true, "i": true, "if": true, "in": true, "into": true, "is": true, "it": true, "its": true,
+		"of": true, "on": true, "or": true, "our": true, "she": true, "so": true, "than": true, "that": true,
+		"the": true, "their": true, "them": true, "then": true, "there": true, "these": true, "they": true,
+		"this": true, "to": true, "was": true, "we": true, "were": true, "what": true, "when": true,
+		"which": true, "who": true, "will": true, "with": true, "you": true, "your": true,
+	}
+)
+
+// TextField defines behaviour for the text field type. Sqlite has no text
+// search support so the words are extracted and counted when reading the rows.
+type TextField struct {
+	BasicField
+}
+
+// NewTextField creates a new field for text types.
+func NewTextField(storage *Storage, datasetName string, datasetStorageName string, key string, label string, typ string, count string) *TextField {
+	field := &TextField{
+		BasicField: BasicField{
+			Storage:            storage,
+			DatasetName:        datasetName,
+			DatasetStorageName: datasetStorageName,
+			Key:                key,
+			Label:              label,
+			Type:               typ,
+			Count:              count,
+		},
+	}
+
+	return field
+}
+
+// FetchSummaryData pulls summary data from the database and builds a histogram.
+func (f *TextField) FetchSummaryData(resultURI string, filterParams *api.FilterParams, extrema *api.Extrema, mode api.SummaryMode) (*api.VariableSummary, error) {
+	var baseline *api.Histogram
+	var filtered *api.Histogram
+	var err error
+
+	if resultURI == "" {
+		baseline, err = f.fetchHistogram(api.GetBaselineFilter(filterParams))
+		if err != nil {
+			return nil, err
+		}
+		if !filterParams.IsEmpty(true) {
+			filtered, err = f.fetchHistogram(filterParams)
+			if err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		baseline, err = f.fetchHistogramByResult(resultURI, api.GetBaselineFilter(filterParams))
+		if err != nil {
+			return nil, err
+		}
+		if !filterParams.IsEmpty(true) {
+			filtered, err = f.fetchHistogramByResult(resultURI, filterParams)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &api.VariableSummary{
+		Label:    f.Label,
+		Key:      f.Key,
+		Type:     model.CategoricalType,
+		VarType:  f.Type,
+		Baseline: baseline,
+		Filtered: filtered,
+	}, nil
+}
+
+func (f *TextField) getCountSelect(alias string) string {
+	if f.Count == "" {
+		return ""
+	}
+	if alias != "" {
+		alias = alias + "."
+	}
+	return fmt.Sprintf(", %s\"%s\"", alias, f.Count)
+}
+
+func (f *TextField) fetchHistogram(filterParams *api.FilterParams) (*api.Histogram, error) {
+	// create the filter for the query.
+	wheres := make([]string, 0)
+	params := make([]interface{}, 0)
+	wheres, params, err := f.Storage.builder.FilteredQueryWhere(f.GetDatasetName(), wheres, params, "", filterParams)
+	if err != nil {
+		return nil, err
+	}
+
+	where := ""
+	if len(wheres) > 0 {
+		where = fmt.Sprintf("WHERE %s", strings.Join(wheres, " AND "))
+	}
+
+	query := fmt.Sprintf("SELECT \"%s\"%s FROM %s %s;", f.Key, f.getCountSelect(""), f.DatasetStorageName, where)
+
+	// execute the sqlite query
+	res, err := f.Storage.client.Query(query, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch text histogram for variable summaries from sqlite")
+	}
+	defer res.Close()
+
+	return f.parseHistogram(res)
+}
+
+func (f *TextField) fetchHistogramByResult(resultURI string, filterParams *api.FilterParams) (*api.Histogram, error) {
+
+	// get filter where / params
+	wheres, params, err := f.Storage.buildResultQueryFilters(f.GetDatasetName(), f.DatasetStorageName, resultURI, filterParams, baseTableAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	params = append(params, resultURI)
+
+	where := ""
+	if len(wheres) > 0 {
+		where = fmt.Sprintf("AND %s", strings.Join(wheres, " AND "))
+	}
+
+	query := fmt.Sprintf("SELECT data.\"%s\"%s FROM %s data INNER JOIN %s result ON data.\"%s\" = result.\"index\" WHERE result.result_id = ?%d %s;",
+		f.Key, f.getCountSelect(baseTableAlias), f.DatasetStorageName, f.Storage.getResultTable(f.DatasetStorageName),
+		model.D3MIndexFieldName, len(params), where)
+
+	// execute the sqlite query
+	res, err := f.Storage.client.Query(query, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch text histogram for variable summaries from sqlite")
+	}
+	defer res.Close()
+
+	return f.parseHistogram(res)
+}
+
+// parseHistogram counts the words found in the first column of the rows. When
+// a count column is used, the words are counted once per distinct value of
+// the second column.
+func (f *TextField) parseHistogram(rows *sql.Rows) (*api.Histogram, error) {
+	termsAggName := api.TermsAggPrefix + f.Key
+
+	counts := map[string]int64{}
+	distinct := map[string]map[string]bool{}
+	for rows.Next() {
+		var text sql.NullString
+		var countKey sql.NullString
+		var err error
+		if f.Count != "" {
+			err = rows.Scan(&text, &countKey)
+		} else {
+			err = rows.Scan(&text)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("no %s histogram aggregation found", termsAggName))
+		}
+
+		for _, word := range extractWords(text.String) {
+			if f.Count == "" {
+				counts[word]++
+				continue
+			}
+			if distinct[word] == nil {
+				distinct[word] = map[string]bool{}
+			}
+			if !distinct[word][countKey.String] {
+				distinct[word][countKey.String] = true
+				counts[word]++
+			}
+		}
+	}
+	err := rows.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading data from sqlite")
+	}
+
+	return buildTermsHistogram(counts), nil
+}
+
+// extractWords splits the text into lower case words, dropping stop words.
+func extractWords(text string) []string {
+	words := []string{}
+	for _, word := range wordRegex.FindAllString(strings.ToLower(text), -1) {
+		if !stopWords[word] {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// buildTermsHistogram creates the histogram of the most frequent terms,
+// ordered by count then term.
+func buildTermsHistogram(counts map[string]int64) *api.Histogram {
+	terms := make([]string, 0, len(counts))
+	for term := range counts {
+		terms = append(terms, term)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if counts[terms[i]] != counts[terms[j]] {
+			return counts[terms[i]] > counts[terms[j]]
+		}
+		return terms[i] < terms[j]
+	})
+	if len(terms) > catResultLimit {
+		terms = terms[:catResultLimit]
+	}
+
+	buckets := make([]*api.Bucket, 0)
+	min := int64(math.MaxInt32)
+	max := int64(-math.MaxInt32)
+	for _, term := range terms {
+		bucketCount := counts[term]
+		buckets = append(buckets, &api.Bucket{
+			Key:   term,
+			Count: bucketCount,
+		})
+		if bucketCount < min {
+			min = bucketCount
+		}
+		if bucketCount > max {
+			max = bucketCount
+		}
+	}
+
+	// assign histogram attributes
+	return &api.Histogram{
+		Buckets: buckets,
+		Extrema: &api.Extrema{
+			Min: float64(min),
+			Max: float64(max),
+		},
+	}
+}
+
+// FetchPredictedSummaryData pulls data from the result table and builds
+// the categorical histogram for the field.
+func (f *TextField) FetchPredictedSummaryData(resultURI string, datasetResult string, filterParams *api.FilterParams, extrema *api.Extrema, mode api.SummaryMode) (*api.VariableSummary, error) {
+	var baseline *api.Histogram
+	var filtered *api.Histogram
+	var err error
+
+	baseline, err = f.fetchPredictedSummaryData(resultURI, datasetResult, nil, extrema)
+	if err != nil {
+		return nil, err
+	}
+	if !filterParams.IsEmpty(true) {
+		filtered, err = f.fetchPredictedSummaryData(resultURI, datasetResult, filterParams, extrema)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &api.VariableSummary{
+		Label:    f.Label,
+		Key:      f.Key,
+		Type:     model.CategoricalType,
+		VarType:  f.Type,
+		Baseline: baseline,
+		Filtered: filtered,
+	}, nil
+}
+
+func (f *TextField) fetchPredictedSummaryData(resultURI string, datasetResult string, filterParams *api.FilterParams, extrema *api.Extrema) (*api.Histogram, error) {
+	targetName := f.Key
+
+	// get filter where / params
+	wheres, params, err := f.Storage.buildResultQueryFilters(f.GetDatasetName(), f.DatasetStorageName, resultURI, filterParams, "base")
+	if err != nil {
+		return nil, err
+	}
+
+	wheres = append(wheres, fmt.Sprintf("result.result_id = ?%d AND result.target = ?%d ", len(params)+1, len(params)+2))
+	params = append(params, resultURI, targetName)
+
+	// the predicted words are counted
+	query := fmt.Sprintf("SELECT result.value%s FROM %s AS result INNER JOIN %s AS base ON result.\"index\" = base.\"%s\" WHERE %s;",
+		f.getCountSelect("base"), datasetResult, f.DatasetStorageName, model.D3MIndexFieldName, strings.Join(wheres, " AND "))
+
+	// execute the sqlite query
+	res, err := f.Storage.client.Query(query, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch histograms for result summaries from sqlite")
+	}
+	defer res.Close()
+
+	return f.parseHistogram(res)
+}
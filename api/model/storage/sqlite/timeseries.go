@@ -0,0 +1,724 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	api "github.com/uncharted-distil/distil/api/model"
+	pgstorage "github.com/uncharted-distil/distil/api/model/storage/postgres"
+	"github.com/uncharted-distil/distil/api/model/storage/sqlbuilder"
+)
+
+// TimeSeriesField defines behaviour for the timeseries field type.
+type TimeSeriesField struct {
+	BasicField
+	IDCol      string
+	ClusterCol string
+	XCol       string
+	XColType   string
+	YCol       string
+	YColType   string
+}
+
+// NewTimeSeriesField creates a new field for timeseries types.
+func NewTimeSeriesField(storage *Storage, datasetName string, datasetStorageName string, clusterCol string, key string, label string, typ string,
+	idCol string, xCol string, xColType string, yCol string, yColType string) *TimeSeriesField {
+	field := &TimeSeriesField{
+		BasicField: BasicField{
+			Storage:            storage,
+			DatasetName:        datasetName,
+			DatasetStorageName: datasetStorageName,
+			Label:              label,
+			Type:               typ,
+			Key:                key,
+		},
+		IDCol:      idCol,
+		XCol:       xCol,
+		XColType:   xColType,
+		YCol:       yCol,
+		YColType:   yColType,
+		ClusterCol: clusterCol,
+	}
+
+	return field
+}
+
+// getTimeTyped returns the expression converting the time column of a
+// timeseries to a number. Date times are returned as seconds since the epoch.
+func (s *Storage) getTimeTyped(field string, isDateTime bool) string {
+	if isDateTime {
+		return s.builder.Dialect.Epoch(field)
+	}
+	return s.builder.Dialect.Numeric(field)
+}
+
+// getSeriesKeySelect returns the expression selecting the series key, falling
+// back to an empty key for datasets holding a single series.
+func getSeriesKeySelect(alias string, seriesIDColName string) string {
+	if seriesIDColName == "" || seriesIDColName == "null" {
+		return "''"
+	}
+	return fmt.Sprintf("CAST(%s AS TEXT)", sqlbuilder.FullName(alias, seriesIDColName))
+}
+
+// toObservationTime converts a time value as returned by the database to the
+// time of an observation, which is in milliseconds for date times.
+func toObservationTime(value float64, isDateTime bool) float64 {
+	if isDateTime {
+		return value * 1000
+	}
+	return value
+}
+
+// Calculate the Min, Max, and Mean of a list of TimerseriesObservation
+func getMinMaxMean(timeseries []*api.TimeseriesObservation) (float64, float64, float64) {
+	min := math.Inf(1)
+	max := math.Inf(-1)
+	sum := float64(0)
+
+	var value float64
+	for _, timeserie := range timeseries {
+		value = float64(timeserie.Value)
+		if !math.IsNaN(value) {
+			min = math.Min(value, min)
+			max = math.Max(value, max)
+			sum += value
+		}
+	}
+
+	// Check that the values have been updated
+	if !math.IsInf(min, 0) && !math.IsInf(max, 0) {
+		return min, max, sum / float64(len(timeseries))
+	}
+
+	// Otherwise, send a NaN
+	var null = math.NaN()
+	return null, null, null
+}
+
+func (f *TimeSeriesField) fetchRepresentationTimeSeries(categoryBuckets []*api.Bucket, mode api.SummaryMode) ([]string, error) {
+	keyColName := f.keyColName(mode)
+
+	// pull sample row containing bucket
+	query := fmt.Sprintf("SELECT \"%s\" FROM %s WHERE \"%s\" = ?1 LIMIT 1;",
+		f.IDCol, f.DatasetStorageName, keyColName)
+
+	timeseriesExemplars := []string{}
+	for _, bucket := range categoryBuckets {
+		var exemplar interface{}
+		err := f.Storage.client.QueryRow(query, bucket.Key).Scan(&exemplar)
+		if err == sql.ErrNoRows {
+			continue
+		} else if err != nil {
+			return nil, errors.Wrap(err, "failed to fetch timeseries exemplar from sqlite")
+		}
+		timeseriesExemplars = append(timeseriesExemplars, toString(exemplar))
+	}
+
+	if len(timeseriesExemplars) == 0 {
+		return nil, errors.New("No exemplars found for timeseries data")
+	}
+
+	return timeseriesExemplars, nil
+}
+
+// fetchTimeSet pulls the sorted distinct time values across all the series.
+func (s *Storage) fetchTimeSet(storageName string, xColName string, isDateTime bool) ([]float64, error) {
+	xTyped := s.getTimeTyped(fmt.Sprintf("\"%s\"", xColName), isDateTime)
+	query := fmt.Sprintf("SELECT DISTINCT %s FROM %s WHERE %s IS NOT NULL ORDER BY 1;", xTyped, storageName, xTyped)
+
+	rows, err := s.client.Query(query)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch timeseries set from sqlite")
+	}
+	defer rows.Close()
+
+	timeSet := []float64{}
+	for rows.Next() {
+		var value float64
+		err = rows.Scan(&value)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse timeseries set from sqlite")
+		}
+		timeSet = append(timeSet, value)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading data from sqlite")
+	}
+
+	return timeSet, nil
+}
+
+// parseTimeseries reads the (time, value, series) rows and aligns every series
+// on the full time set, merging duplicate time values using the supplied
+// operation.
+func (s *Storage) parseTimeseries(rows *sql.Rows, timeSet []float64, isDateTime bool, duplicateOperation func(float64, float64, int64) float64) (map[string][]*api.TimeseriesObservation, error) {
+	values := map[string]map[float64]float64{}
+	duplicates := map[string]map[float64]int64{}
+	for rows.Next() {
+		var time sql.NullFloat64
+		var value sql.NullFloat64
+		var key string
+		err := rows.Scan(&time, &value, &key)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse row result")
+		}
+		if !time.Valid {
+			continue
+		}
+		val := math.NaN()
+		if value.Valid {
+			val = value.Float64
+		}
+
+		if values[key] == nil {
+			values[key] = map[float64]float64{}
+			duplicates[key] = map[float64]int64{}
+		}
+		duplicates[key][time.Float64]++
+		existing, ok := values[key][time.Float64]
+		if ok && !math.IsNaN(existing) {
+			values[key][time.Float64] = duplicateOperation(existing, val, duplicates[key][time.Float64])
+			continue
+		}
+		values[key][time.Float64] = val
+	}
+	err := rows.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading data from sqlite")
+	}
+
+	result := map[string][]*api.TimeseriesObservation{}
+	for key, series := range values {
+		arr := []*api.TimeseriesObservation{}
+		for _, t := range timeSet {
+			val, ok := series[t]
+			if !ok {
+				val = math.NaN()
+			}
+			arr = append(arr, &api.TimeseriesObservation{Value: api.NullableFloat64(val), Time: toObservationTime(t, isDateTime)})
+		}
+		result[key] = arr
+	}
+
+	return result, nil
+}
+
+func (s *Storage) parseTimeseriesForecast(rows *sql.Rows, isDateTime bool) (map[string][]*api.TimeseriesObservation, error) {
+	result := map[string][]*api.TimeseriesObservation{}
+	for rows.Next() {
+		var time sql.NullFloat64
+		var value sql.NullFloat64
+		var explainValuesJSON string
+		var key string
+		err := rows.Scan(&time, &value, &explainValuesJSON, &key)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse row result")
+		}
+		if !time.Valid {
+			continue
+		}
+		val := math.NaN()
+		if value.Valid {
+			val = value.Float64
+		}
+
+		explainValues := api.SolutionExplainValues{}
+		err = json.Unmarshal([]byte(explainValuesJSON), &explainValues)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse explain values")
+		}
+
+		result[key] = append(result[key], &api.TimeseriesObservation{
+			Value:          api.NullableFloat64(val),
+			Time:           toObservationTime(time.Float64, isDateTime),
+			ConfidenceLow:  api.NullableFloat64(explainValues.LowConfidence),
+			ConfidenceHigh: api.NullableFloat64(explainValues.HighConfidence),
+		})
+	}
+	err := rows.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading data from sqlite")
+	}
+
+	return result, nil
+}
+
+func buildTimeseriesData(varKey string, response map[string][]*api.TimeseriesObservation, isDateTime bool) []*api.TimeseriesData {
+	result := []*api.TimeseriesData{}
+	for seriesID, el := range response {
+		// Calculate Min/Max/Mean
+		var min, max, mean = getMinMaxMean(el)
+		result = append(result, &api.TimeseriesData{
+			VarKey:     varKey,
+			SeriesID:   seriesID,
+			Timeseries: el,
+			IsDateTime: isDateTime,
+			Min:        min,
+			Max:        max,
+			Mean:       mean,
+		})
+	}
+	return result
+}
+
+// buildSeriesIDWhere adds the clause restricting the rows to the requested series.
+func buildSeriesIDWhere(wheres []string, params []interface{}, alias string, seriesIDColName string, seriesIDs []string) ([]string, []interface{}) {
+	in := make([]string, len(seriesIDs))
+	for i, v := range seriesIDs {
+		params = append(params, v)
+		in[i] = fmt.Sprintf("?%d", len(params))
+	}
+	wheres = append(wheres, fmt.Sprintf("CAST(%s AS TEXT) IN (%s)", sqlbuilder.FullName(alias, seriesIDColName), strings.Join(in, ", ")))
+	return wheres, params
+}
+
+// FetchTimeseries fetches a timeseries.
+func (s *Storage) FetchTimeseries(dataset string, storageName string, variableKey string, seriesIDColName string, xColName string, yColName string, timeseriesURI []string,
+	duplicateOperation api.TimeseriesOp, filterParams *api.FilterParams) ([]*api.TimeseriesData, error) {
+	// create the filter for the query.
+	wheres := make([]string, 0)
+	params := make([]interface{}, 0)
+
+	if seriesIDColName != "null" && seriesIDColName != "" {
+		if len(timeseriesURI) == 0 {
+			return nil, errors.New("No timeseries IDs passed in")
+		}
+		wheres, params = buildSeriesIDWhere(wheres, params, "", seriesIDColName, timeseriesURI)
+	}
+
+	wheres, params, err := s.builder.FilteredQueryWhere(dataset, wheres, params, "", filterParams)
+	if err != nil {
+		return nil, err
+	}
+	where := ""
+	if len(wheres) > 0 {
+		where = fmt.Sprintf("WHERE %s", strings.Join(wheres, " AND "))
+	}
+
+	xColVariable, err := s.metadata.FetchVariable(dataset, xColName)
+	if err != nil {
+		return nil, err
+	}
+	isDateTime := xColVariable.Type == model.DateTimeType
+
+	timeSet, err := s.fetchTimeSet(storageName, xColName, isDateTime)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf("SELECT %s, %s, %s FROM %s %s ORDER BY 3, 1;",
+		s.getTimeTyped(fmt.Sprintf("\"%s\"", xColName), isDateTime), s.builder.Dialect.Numeric(fmt.Sprintf("\"%s\"", yColName)),
+		getSeriesKeySelect("", seriesIDColName), storageName, where)
+
+	// execute the sqlite query
+	res, err := s.client.Query(query, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch timeseries from sqlite")
+	}
+	defer res.Close()
+
+	response, err := s.parseTimeseries(res, timeSet, isDateTime, pgstorage.GetTimeseriesOperations(duplicateOperation))
+	if err != nil {
+		return nil, err
+	}
+
+	return buildTimeseriesData(variableKey, response, isDateTime), nil
+}
+
+// FetchTimeseriesForecast fetches a timeseries.
+func (s *Storage) FetchTimeseriesForecast(dataset string, storageName string, varKey string, seriesIDColName string, xColName string, yColName string,
+	seriesIDs []string, duplicateOperation api.TimeseriesOp, resultURI string, filterParams *api.FilterParams) ([]*api.TimeseriesData, error) {
+	// create the filter for the query.
+	wheres := make([]string, 0)
+	params := make([]interface{}, 0)
+	if len(seriesIDs) > 0 {
+		wheres, params = buildSeriesIDWhere(wheres, params, baseTableAlias, seriesIDColName, seriesIDs)
+	}
+
+	wheres, params, err := s.builder.FilteredQueryWhere(dataset, wheres, params, baseTableAlias, filterParams)
+	if err != nil {
+		return nil, err
+	}
+
+	params = append(params, resultURI)
+	wheres = append(wheres, fmt.Sprintf("result.result_id = ?%d", len(params)))
+	wheres = append(wheres, "result.value != ''")
+
+	xColVariable, err := s.metadata.FetchVariable(dataset, xColName)
+	if err != nil {
+		return nil, err
+	}
+	isDateTime := xColVariable.Type == model.DateTimeType
+
+	query := fmt.Sprintf(`
+		SELECT %s, %s, COALESCE(result.explain_values, '{}'), %s
+		FROM %s AS data INNER JOIN %s AS result ON data."%s" = result."index"
+		WHERE %s
+		ORDER BY 4, 1;`,
+		s.getTimeTyped(sqlbuilder.FullName(baseTableAlias, xColName), isDateTime), s.builder.Dialect.Numeric("result.value"),
+		getSeriesKeySelect(baseTableAlias, seriesIDColName), storageName, s.getResultTable(storageName),
+		model.D3MIndexFieldName, strings.Join(wheres, " AND "))
+
+	// execute the sqlite query
+	res, err := s.client.Query(query, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch timeseries from sqlite")
+	}
+	defer res.Close()
+
+	response, err := s.parseTimeseriesForecast(res, isDateTime)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildTimeseriesData(varKey, response, isDateTime), nil
+}
+
+// FetchSummaryData pulls summary data from the database and builds a histogram.
+func (f *TimeSeriesField) FetchSummaryData(resultURI string, filterParams *api.FilterParams, extrema *api.Extrema, mode api.SummaryMode) (*api.VariableSummary, error) {
+	var baseline *api.Histogram
+	var filtered *api.Histogram
+	var err error
+
+	// update the highlight key to use the cluster if necessary
+	if err = f.updateClusterHighlight(filterParams, mode); err != nil {
+		return nil, err
+	}
+
+	if resultURI == "" {
+		baseline, err = f.fetchHistogram(api.GetBaselineFilter(filterParams), mode)
+		if err != nil {
+			return nil, err
+		}
+		if !filterParams.IsEmpty(true) {
+			filtered, err = f.fetchHistogram(filterParams, mode)
+			if err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		baseline, err = f.fetchHistogramByResult(resultURI, api.GetBaselineFilter(filterParams), mode)
+		if err != nil {
+			return nil, err
+		}
+		if !filterParams.IsEmpty(true) {
+			filtered, err = f.fetchHistogramByResult(resultURI, filterParams, mode)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	genericfilters := &api.FilterParams{
+		Size:       filterParams.Size,
+		Variables:  filterParams.Variables,
+		DataMode:   filterParams.DataMode,
+		Filters:    []*model.FilterSet{},
+		Expression: filterParams.Expression,
+	}
+	joins := make([]*joinDefinition, 0)
+	wheres := []string{}
+	params := []interface{}{}
+	for _, filterSet := range filterParams.Filters {
+		// split the filters to make sure the result based filters can be applied properly
+		filtersSplit, err := sqlbuilder.SplitFilters(filterSet)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(filtersSplit.Residual) > 0 {
+			clauses := []string{}
+			for _, residualFilter := range filtersSplit.Residual {
+				clauses, params, err = f.Storage.buildErrorResultWhere(clauses, params, residualFilter)
+				if err != nil {
+					return nil, err
+				}
+			}
+			wheres = append(wheres, sqlbuilder.CombineClauses(filterSet.Mode, clauses, "AND"))
+		}
+
+		// combine the generic filters together into a single one
+		genericfilters.MergeFilterObjects(filtersSplit.Generic)
+	}
+	if len(wheres) > 0 {
+		joins = append(joins, &joinDefinition{
+			baseAlias:  f.DatasetStorageName,
+			baseColumn: f.IDCol,
+			joinAlias:  "r",
+			joinColumn: "k",
+			joinTableName: fmt.Sprintf("(SELECT DISTINCT b.\"%s\" AS k FROM %s AS b INNER JOIN %s AS r ON b.\"%s\" = r.\"index\" WHERE r.value != '' AND %s)",
+				f.IDCol, f.GetDatasetStorageName(), f.Storage.getResultTable(f.GetDatasetStorageName()), model.D3MIndexFieldName, strings.Join(wheres, " AND ")),
+		},
+		)
+	}
+
+	// clear filters since they are used in subselect
+	wheres = []string{}
+
+	// Handle timeseries that use a timestamp/int as their time value, or those that use a date time.
+	var timelineField TimelineField
+	if f.XColType == model.DateTimeType {
+		timelineField = NewDateTimeField(f.Storage, f.DatasetName, f.DatasetStorageName, f.XCol, f.XCol, f.XColType, f.Count)
+	} else if f.XColType == model.TimestampType || f.XColType == model.IntegerType {
+		timelineField = NewNumericalField(f.Storage, f.DatasetName, f.DatasetStorageName, f.XCol, f.XCol, f.XColType, f.Count)
+	} else {
+		return nil, errors.Errorf("unsupported timeseries field variable type %s:%s", f.XCol, f.XColType)
+	}
+
+	timelineBaseline, err := timelineField.fetchHistogramWithJoins(nil, api.MaxNumBuckets, joins, wheres, params)
+	if err != nil {
+		return nil, err
+	}
+
+	timeline, err := timelineField.fetchHistogramWithJoins(genericfilters, api.MaxNumBuckets, joins, wheres, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.VariableSummary{
+		Key:              f.Key,
+		Label:            f.Label,
+		Type:             model.CategoricalType,
+		VarType:          f.Type,
+		Baseline:         baseline,
+		Filtered:         filtered,
+		Timeline:         timeline,
+		TimelineBaseline: timelineBaseline,
+		TimelineType:     f.XColType,
+	}, nil
+}
+
+func (f *TimeSeriesField) keyColName(mode api.SummaryMode) string {
+	if mode == api.ClusterMode && api.HasClusterData(f.GetDatasetName(), f.ClusterCol, f.GetStorage().metadata) {
+		return f.ClusterCol
+	}
+	return f.IDCol
+}
+
+func (f *TimeSeriesField) fetchHistogram(filterParams *api.FilterParams, mode api.SummaryMode) (*api.Histogram, error) {
+	// When there's no grouping key we can
+	if f.IDCol == "" {
+		histogram := f.generateUngroupedData()
+		return histogram, nil
+	}
+
+	// create the filter for the query.
+	wheres := make([]string, 0)
+	params := make([]interface{}, 0)
+	wheres, params, err := f.Storage.builder.FilteredQueryWhere(f.GetDatasetName(), wheres, params, "", filterParams)
+	if err != nil {
+		return nil, err
+	}
+
+	where := ""
+	if len(wheres) > 0 {
+		where = fmt.Sprintf("WHERE %s", strings.Join(wheres, " AND "))
+	}
+
+	// Get count by category.
+	colName := f.keyColName(mode)
+
+	query := fmt.Sprintf("SELECT \"%s\", COUNT(DISTINCT \"%s\") AS __count__ FROM %s %s GROUP BY \"%s\" ORDER BY __count__ desc, \"%s\" LIMIT %d;",
+		colName, f.IDCol, f.DatasetStorageName, where, colName, colName, timeSeriesCatResultLimit)
+
+	return f.fetchHistogramWithExemplars(query, params, mode)
+}
+
+func (f *TimeSeriesField) fetchHistogramByResult(resultURI string, filterParams *api.FilterParams, mode api.SummaryMode) (*api.Histogram, error) {
+	// get filter where / params
+	wheres, params, err := f.Storage.buildResultQueryFilters(f.GetDatasetName(), f.DatasetStorageName, resultURI, filterParams, baseTableAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	params = append(params, resultURI)
+
+	where := ""
+	if len(wheres) > 0 {
+		where = fmt.Sprintf("AND %s", strings.Join(wheres, " AND "))
+	}
+
+	keyColName := f.keyColName(mode)
+
+	// Get count by category.
+	query := fmt.Sprintf(
+		`SELECT data."%s", COUNT(DISTINCT data."%s") AS __count__
+		 FROM %s data INNER JOIN %s result ON data."%s" = result."index"
+		 WHERE result.result_id = ?%d AND result.value != '' %s
+		 GROUP BY data."%s"
+		 ORDER BY __count__ desc, data."%s" LIMIT %d;`,
+		keyColName, f.IDCol, f.DatasetStorageName, f.Storage.getResultTable(f.DatasetStorageName),
+		model.D3MIndexFieldName, len(params), where, keyColName,
+		keyColName, timeSeriesCatResultLimit)
+
+	return f.fetchHistogramWithExemplars(query, params, mode)
+}
+
+// fetchHistogramWithExemplars runs the series count query and attaches an
+// exemplar series to each of the returned buckets.
+func (f *TimeSeriesField) fetchHistogramWithExemplars(query string, params []interface{}, mode api.SummaryMode) (*api.Histogram, error) {
+	// execute the sqlite query
+	res, err := f.Storage.client.Query(query, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch histograms for variable summaries from sqlite")
+	}
+	histogram, err := f.parseHistogram(res, mode)
+	res.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := f.fetchRepresentationTimeSeries(histogram.Buckets, mode)
+	if err != nil {
+		return nil, err
+	}
+	histogram.Exemplars = files
+	return histogram, nil
+}
+
+func (f *TimeSeriesField) parseHistogram(rows *sql.Rows, mode api.SummaryMode) (*api.Histogram, error) {
+	keyColName := f.keyColName(mode)
+
+	termsAggName := api.TermsAggPrefix + keyColName
+
+	// Parse bucket results.
+	buckets := make([]*api.Bucket, 0)
+	min := int64(math.MaxInt32)
+	max := int64(-math.MaxInt32)
+
+	for rows.Next() {
+		var term sql.NullString
+		var bucketCount int64
+		err := rows.Scan(&term, &bucketCount)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("no %s histogram aggregation found", termsAggName))
+		}
+
+		buckets = append(buckets, &api.Bucket{
+			Key:   term.String,
+			Count: bucketCount,
+		})
+		if bucketCount < min {
+			min = bucketCount
+		}
+		if bucketCount > max {
+			max = bucketCount
+		}
+	}
+	err := rows.Err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading data from sqlite")
+	}
+
+	// assign histogram attributes
+	return &api.Histogram{
+		Buckets: buckets,
+		Extrema: &api.Extrema{
+			Min: float64(min),
+			Max: float64(max),
+		},
+	}, nil
+}
+
+// For timeseries that exists as a single series in a file (ie. each row is just (timestamp, value))
+// we don't need to do any aggregation queries.  A single category is returned in the histogram,
+// and the exemplar name is set to the timeseries key.
+func (f *TimeSeriesField) generateUngroupedData() *api.Histogram {
+	return &api.Histogram{
+		Buckets: []*api.Bucket{
+			{
+				Key:   f.Key,
+				Count: 1,
+			},
+		},
+		Extrema: &api.Extrema{
+			Min: float64(1),
+			Max: float64(1),
+		},
+		Exemplars: []string{f.Key},
+	}
+}
+
+// FetchPredictedSummaryData pulls predicted data from the result table and builds
+// the timeseries histogram for the field.
+func (f *TimeSeriesField) FetchPredictedSummaryData(resultURI string, datasetResult string, filterParams *api.FilterParams, extrema *api.Extrema, mode api.SummaryMode) (*api.VariableSummary, error) {
+	var baseline *api.Histogram
+	var filtered *api.Histogram
+	var err error
+
+	// update the highlight key to use the cluster if necessary
+	if err = f.updateClusterHighlight(filterParams, mode); err != nil {
+		return nil, err
+	}
+
+	baseline, err = f.fetchPredictedSummaryData(resultURI, datasetResult, nil, extrema, mode)
+	if err != nil {
+		return nil, err
+	}
+	if !filterParams.IsEmpty(true) {
+		filtered, err = f.fetchPredictedSummaryData(resultURI, datasetResult, filterParams, extrema, mode)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &api.VariableSummary{
+		Key:      f.Key,
+		Label:    f.Label,
+		Type:     model.CategoricalType,
+		VarType:  f.Type,
+		Baseline: baseline,
+		Filtered: filtered,
+	}, nil
+}
+
+func (f *TimeSeriesField) fetchPredictedSummaryData(resultURI string, datasetResult string, filterParams *api.FilterParams, extrema *api.Extrema, mode api.SummaryMode) (*api.Histogram, error) {
+	// get filter where / params
+	wheres, params, err := f.Storage.buildResultQueryFilters(f.GetDatasetName(), f.DatasetStorageName, resultURI, filterParams, baseTableAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	params = append(params, resultURI)
+
+	where := ""
+	if len(wheres) > 0 {
+		where = fmt.Sprintf("AND %s", strings.Join(wheres, " AND "))
+	}
+
+	keyColName := f.keyColName(mode)
+
+	// Get count by category.
+	query := fmt.Sprintf(
+		`SELECT data."%s", COUNT(DISTINCT data."%s") AS __count__
+		 FROM %s data INNER JOIN %s result ON data."%s" = result."index"
+		 WHERE result.result_id = ?%d %s
+		 GROUP BY data."%s"
+		 ORDER BY __count__ desc, data."%s" LIMIT %d;`,
+		keyColName, f.IDCol, f.DatasetStorageName, f.Storage.getResultTable(f.DatasetStorageName),
+		model.D3MIndexFieldName, len(params), where, keyColName,
+		keyColName, timeSeriesCatResultLimit)
+
+	return f.fetchHistogramWithExemplars(query, params, mode)
+}
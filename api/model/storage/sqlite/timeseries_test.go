@@ -0,0 +1,117 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlite
+
+import (
+	"io/ioutil"
+	"math"
+	"path"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uncharted-distil/distil-compute/model"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+func ingestTestTimeseries(t *testing.T, storage *Storage, metaStorage *MetadataStorage) *model.Metadata {
+	meta := model.NewMetadata("sales", "sales", "", "sales")
+	dr := model.NewDataResource("learningData", model.ResTypeTable, nil)
+	dr.Variables = []*model.Variable{
+		model.NewVariable(0, "d3mIndex", "d3mIndex", "d3mIndex", "d3mIndex", model.IndexType, model.IndexType, "", []string{model.RoleIndex}, []string{model.VarDistilRoleIndex}, nil, nil, false),
+		model.NewVariable(1, "store", "store", "store", "store", model.CategoricalType, model.CategoricalType, "", []string{model.RoleAttribute}, nil, nil, nil, false),
+		model.NewVariable(2, "day", "day", "day", "day", model.IntegerType, model.IntegerType, "", []string{model.RoleAttribute}, nil, nil, nil, false),
+		model.NewVariable(3, "amount", "amount", "amount", "amount", model.RealType, model.RealType, "", []string{model.RoleAttribute}, nil, nil, nil, false),
+	}
+	meta.DataResources = []*model.DataResource{dr}
+	ingestTestRows(t, storage, metaStorage, meta, [][]string{
+		{"0", "a", "1", "1"},
+		{"1", "a", "2", "2"},
+		{"2", "a", "2", "4"},
+		{"3", "b", "1", "10"},
+		{"4", "b", "3", "30"},
+	})
+
+	return meta
+}
+
+func observationValues(series *api.TimeseriesData) []float64 {
+	values := []float64{}
+	for _, o := range series.Timeseries {
+		values = append(values, float64(o.Value))
+	}
+	return values
+}
+
+func TestFetchTimeseries(t *testing.T) {
+	storage, metaStorage := createTestStorage(t)
+	meta := ingestTestTimeseries(t, storage, metaStorage)
+
+	series, err := storage.FetchTimeseries(meta.ID, meta.StorageName, "amount", "store", "day", "amount", []string{"a", "b"}, api.TimeseriesAddOp, nil)
+	assert.NoError(t, err)
+	assert.Len(t, series, 2)
+	sort.Slice(series, func(i, j int) bool { return series[i].SeriesID < series[j].SeriesID })
+
+	// every series is aligned on the days of all series, with duplicate days
+	// merged and missing days as NaN
+	a := observationValues(series[0])
+	assert.Equal(t, "a", series[0].SeriesID)
+	assert.Equal(t, []float64{1, 6}, a[:2])
+	assert.True(t, math.IsNaN(a[2]))
+	assert.Equal(t, 1.0, series[0].Min)
+	assert.Equal(t, 6.0, series[0].Max)
+	assert.Equal(t, []float64{1, 2, 3}, []float64{series[0].Timeseries[0].Time, series[0].Timeseries[1].Time, series[0].Timeseries[2].Time})
+
+	b := observationValues(series[1])
+	assert.Equal(t, 10.0, b[0])
+	assert.True(t, math.IsNaN(b[1]))
+	assert.Equal(t, 30.0, b[2])
+
+	// duplicates can be merged with other operations
+	series, err = storage.FetchTimeseries(meta.ID, meta.StorageName, "amount", "store", "day", "amount", []string{"a"}, api.TimeseriesMaxOp, nil)
+	assert.NoError(t, err)
+	assert.Len(t, series, 1)
+	assert.Equal(t, 4.0, float64(series[0].Timeseries[1].Value))
+
+	// the filters apply to the observations
+	filterParams := api.NewFilterParamsFromFilters([]*model.Filter{
+		model.NewNumericalFilter("amount", model.IncludeFilter, 0, 3),
+	})
+	series, err = storage.FetchTimeseries(meta.ID, meta.StorageName, "amount", "store", "day", "amount", []string{"a"}, api.TimeseriesAddOp, filterParams)
+	assert.NoError(t, err)
+	assert.Equal(t, 2.0, float64(series[0].Timeseries[1].Value))
+
+	_, err = storage.FetchTimeseries(meta.ID, meta.StorageName, "amount", "store", "day", "amount", nil, api.TimeseriesAddOp, nil)
+	assert.Error(t, err)
+}
+
+func TestFetchTimeseriesForecast(t *testing.T) {
+	storage, metaStorage := createTestStorage(t)
+	meta := ingestTestTimeseries(t, storage, metaStorage)
+
+	resultURI := path.Join(t.TempDir(), "predictions.csv")
+	err := ioutil.WriteFile(resultURI, []byte("d3mIndex,amount\n0,1.5\n1,2.5\n3,11\n4,29\n"), 0644)
+	assert.NoError(t, err)
+	assert.NoError(t, storage.PersistResult(meta.ID, meta.StorageName, resultURI, "amount"))
+
+	series, err := storage.FetchTimeseriesForecast(meta.ID, meta.StorageName, "amount", "store", "day", "amount", []string{"b"}, api.TimeseriesAddOp, resultURI, nil)
+	assert.NoError(t, err)
+	assert.Len(t, series, 1)
+	assert.Equal(t, "b", series[0].SeriesID)
+	assert.Equal(t, []float64{11, 29}, observationValues(series[0]))
+	assert.Equal(t, []float64{1, 3}, []float64{series[0].Timeseries[0].Time, series[0].Timeseries[1].Time})
+}
@@ -0,0 +1,35 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlite
+
+import (
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+// FetchTileImages is not available since the tiles rely on geometry queries
+// that sqlite does not provide.
+func (s *Storage) FetchTileImages(dataset string, storageName string, groupingCol string, coordinatesCol string, polygonCol string,
+	bounds *model.Bounds, resultURI string, limit int) ([]*api.TileImage, error) {
+	return nil, errors.New("tile images are not supported by the sqlite storage")
+}
+
+// FetchSpatialJoin is not available since the join relies on geometry queries
+// that sqlite does not provide.
+func (s *Storage) FetchSpatialJoin(join *api.SpatialJoin) ([][]string, error) {
+	return nil, errors.New("spatial joins are not supported by the sqlite storage")
+}
@@ -0,0 +1,48 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package model
+
+// TileImage is a multiband image whose bounds intersect a map tile, along with the
+// result values used to colour it when a result set is overlaid.
+type TileImage struct {
+	GroupID     string
+	Coordinates []float64
+	Prediction  string
+	Confidence  *float64
+}
+
+// Bounds returns the min x, min y, max x and max y of the image coordinates.
+func (t *TileImage) Bounds() (float64, float64, float64, float64) {
+	minX, minY := 180.0, 90.0
+	maxX, maxY := -180.0, -90.0
+	for i := 0; i+1 < len(t.Coordinates); i += 2 {
+		x := t.Coordinates[i]
+		y := t.Coordinates[i+1]
+		if x < minX {
+			minX = x
+		}
+		if x > maxX {
+			maxX = x
+		}
+		if y < minY {
+			minY = y
+		}
+		if y > maxY {
+			maxY = y
+		}
+	}
+	return minX, minY, maxX, maxY
+}
@@ -0,0 +1,318 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package model
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// TimeseriesIntervalMinute resamples a timeseries into minute buckets
+	TimeseriesIntervalMinute = "minute"
+	// TimeseriesIntervalHour resamples a timeseries into hourly buckets
+	TimeseriesIntervalHour = "hour"
+	// TimeseriesIntervalDay resamples a timeseries into daily buckets
+	TimeseriesIntervalDay = "day"
+	// TimeseriesIntervalWeek resamples a timeseries into weekly buckets starting on Monday
+	TimeseriesIntervalWeek = "week"
+	// TimeseriesIntervalMonth resamples a timeseries into calendar month buckets
+	TimeseriesIntervalMonth = "month"
+)
+
+// TimeseriesResample defines the calendar interval to bucket timeseries
+// observations into, and the operation used to aggregate each bucket.
+type TimeseriesResample struct {
+	Interval  string       `json:"interval"`
+	Operation TimeseriesOp `json:"operation"`
+}
+
+// TimeseriesSampling defines how a timeseries should be reduced before being
+// returned to the client. Resampling is applied before downsampling.
+type TimeseriesSampling struct {
+	Resample  *TimeseriesResample `json:"resample,omitempty"`
+	MaxPoints int                 `json:"maxPoints,omitempty"`
+}
+
+// Validate checks that the sampling parameters are supported.
+func (s *TimeseriesSampling) Validate() error {
+	if s.MaxPoints < 0 {
+		return errors.Errorf("max points must be positive")
+	}
+	if s.Resample == nil {
+		return nil
+	}
+	switch s.Resample.Interval {
+	case TimeseriesIntervalMinute, TimeseriesIntervalHour, TimeseriesIntervalDay, TimeseriesIntervalWeek, TimeseriesIntervalMonth:
+	default:
+		return errors.Errorf("unsupported resample interval '%s'", s.Resample.Interval)
+	}
+	switch s.Resample.Operation {
+	case "", TimeseriesAddOp, TimeseriesMinOp, TimeseriesMaxOp, TimeseriesMeanOp:
+	default:
+		return errors.Errorf("unsupported resample operation '%s'", s.Resample.Operation)
+	}
+	return nil
+}
+
+// IsEmpty returns true if the sampling leaves the timeseries untouched.
+func (s *TimeseriesSampling) IsEmpty() bool {
+	return s == nil || (s.Resample == nil && s.MaxPoints == 0)
+}
+
+// Apply resamples and downsamples the timeseries in place, updating its
+// summary statistics to match the returned observations.
+func (s *TimeseriesSampling) Apply(data *TimeseriesData) error {
+	if s.IsEmpty() {
+		return nil
+	}
+
+	observations := data.Timeseries
+	if s.Resample != nil {
+		if !data.IsDateTime {
+			return errors.Errorf("timeseries '%s' does not have a datetime axis and cannot be resampled", data.SeriesID)
+		}
+		var err error
+		observations, err = ResampleTimeseries(observations, s.Resample.Interval, s.Resample.Operation)
+		if err != nil {
+			return err
+		}
+	}
+	if s.MaxPoints > 0 {
+		observations = DownsampleTimeseries(observations, s.MaxPoints)
+	}
+
+	data.Timeseries = observations
+	if s.Resample != nil {
+		// aggregated values no longer share the raw extrema
+		data.Min, data.Max, data.Mean = getTimeseriesStats(observations)
+	}
+
+	return nil
+}
+
+// ResampleTimeseries buckets observations into UTC calendar intervals, with
+// each bucket's values aggregated by the supplied operation. The confidence
+// bounds of a bucket are the widest bounds of its observations. Observation
+// times are expected to be epoch milliseconds. Missing values are ignored, and
+// buckets without any values are returned as missing.
+func ResampleTimeseries(observations []*TimeseriesObservation, interval string, operation TimeseriesOp) ([]*TimeseriesObservation, error) {
+	if operation == "" {
+		operation = TimeseriesDefaultOp
+	}
+
+	buckets := map[float64]*timeseriesBucket{}
+	keys := []float64{}
+	for _, o := range observations {
+		key, err := truncateTimeseriesTime(o.Time, interval)
+		if err != nil {
+			return nil, err
+		}
+		bucket, ok := buckets[key]
+		if !ok {
+			bucket = &timeseriesBucket{}
+			buckets[key] = bucket
+			keys = append(keys, key)
+		}
+		bucket.add(o)
+	}
+	sort.Float64s(keys)
+
+	resampled := make([]*TimeseriesObservation, len(keys))
+	for i, key := range keys {
+		bucket := buckets[key]
+		resampled[i] = &TimeseriesObservation{
+			Time:           key,
+			Value:          NullableFloat64(aggregateTimeseriesValues(bucket.values, operation)),
+			ConfidenceLow:  NullableFloat64(aggregateTimeseriesValues(bucket.confidenceLow, TimeseriesMinOp)),
+			ConfidenceHigh: NullableFloat64(aggregateTimeseriesValues(bucket.confidenceHigh, TimeseriesMaxOp)),
+		}
+	}
+
+	return resampled, nil
+}
+
+// DownsampleTimeseries reduces the observations to at most maxPoints using
+// the largest triangle three buckets algorithm, which keeps the points that
+// contribute most to the visual shape of the series. The first and last
+// observations are always kept.
+func DownsampleTimeseries(observations []*TimeseriesObservation, maxPoints int) []*TimeseriesObservation {
+	if maxPoints <= 0 || len(observations) <= maxPoints {
+		return observations
+	}
+
+	sorted := make([]*TimeseriesObservation, len(observations))
+	copy(sorted, observations)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time < sorted[j].Time })
+
+	if maxPoints == 1 {
+		return sorted[:1]
+	} else if maxPoints == 2 {
+		return []*TimeseriesObservation{sorted[0], sorted[len(sorted)-1]}
+	}
+
+	sampled := make([]*TimeseriesObservation, 0, maxPoints)
+	sampled = append(sampled, sorted[0])
+
+	// the first and last points are fixed so the rest are split evenly
+	bucketSize := float64(len(sorted)-2) / float64(maxPoints-2)
+	selected := sorted[0]
+	for i := 0; i < maxPoints-2; i++ {
+		start := int(float64(i)*bucketSize) + 1
+		end := int(float64(i+1)*bucketSize) + 1
+
+		// the average of the next bucket is the third point of the triangle
+		nextStart := end
+		nextEnd := int(float64(i+2)*bucketSize) + 1
+		if nextEnd > len(sorted) {
+			nextEnd = len(sorted)
+		}
+		avgTime, avgValue := averageTimeseriesPoint(sorted[nextStart:nextEnd], selected)
+
+		maxArea := -1.0
+		next := sorted[start]
+		for _, o := range sorted[start:end] {
+			area := triangleArea(selected, o, avgTime, avgValue)
+			if area > maxArea {
+				maxArea = area
+				next = o
+			}
+		}
+		sampled = append(sampled, next)
+		selected = next
+	}
+
+	return append(sampled, sorted[len(sorted)-1])
+}
+
+type timeseriesBucket struct {
+	values         []float64
+	confidenceLow  []float64
+	confidenceHigh []float64
+}
+
+func (b *timeseriesBucket) add(o *TimeseriesObservation) {
+	b.values = appendTimeseriesValue(b.values, float64(o.Value))
+	b.confidenceLow = appendTimeseriesValue(b.confidenceLow, float64(o.ConfidenceLow))
+	b.confidenceHigh = appendTimeseriesValue(b.confidenceHigh, float64(o.ConfidenceHigh))
+}
+
+func appendTimeseriesValue(values []float64, value float64) []float64 {
+	if math.IsNaN(value) {
+		return values
+	}
+	return append(values, value)
+}
+
+func aggregateTimeseriesValues(values []float64, operation TimeseriesOp) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+
+	result := values[0]
+	for _, v := range values[1:] {
+		switch operation {
+		case TimeseriesMinOp:
+			result = math.Min(result, v)
+		case TimeseriesMaxOp:
+			result = math.Max(result, v)
+		default:
+			result += v
+		}
+	}
+	if operation == TimeseriesMeanOp {
+		result = result / float64(len(values))
+	}
+
+	return result
+}
+
+func truncateTimeseriesTime(epochMillis float64, interval string) (float64, error) {
+	t := time.Unix(0, int64(epochMillis)*int64(time.Millisecond)).UTC()
+
+	var truncated time.Time
+	switch interval {
+	case TimeseriesIntervalMinute:
+		truncated = t.Truncate(time.Minute)
+	case TimeseriesIntervalHour:
+		truncated = t.Truncate(time.Hour)
+	case TimeseriesIntervalDay:
+		truncated = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	case TimeseriesIntervalWeek:
+		// weeks start on monday
+		offset := (int(t.Weekday()) + 6) % 7
+		truncated = time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.UTC)
+	case TimeseriesIntervalMonth:
+		truncated = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return 0, errors.Errorf("unsupported resample interval '%s'", interval)
+	}
+
+	return float64(truncated.UnixNano() / int64(time.Millisecond)), nil
+}
+
+func averageTimeseriesPoint(observations []*TimeseriesObservation, fallback *TimeseriesObservation) (float64, float64) {
+	sumTime := 0.0
+	sumValue := 0.0
+	count := 0
+	for _, o := range observations {
+		if math.IsNaN(float64(o.Value)) {
+			continue
+		}
+		sumTime += o.Time
+		sumValue += float64(o.Value)
+		count++
+	}
+	if count == 0 {
+		return fallback.Time, float64(fallback.Value)
+	}
+
+	return sumTime / float64(count), sumValue / float64(count)
+}
+
+func triangleArea(a *TimeseriesObservation, b *TimeseriesObservation, cTime float64, cValue float64) float64 {
+	area := math.Abs((a.Time-cTime)*(float64(b.Value)-float64(a.Value))-(a.Time-b.Time)*(cValue-float64(a.Value))) / 2
+	if math.IsNaN(area) {
+		// missing values never take precedence over observed ones
+		return 0
+	}
+	return area
+}
+
+func getTimeseriesStats(observations []*TimeseriesObservation) (float64, float64, float64) {
+	min := math.Inf(1)
+	max := math.Inf(-1)
+	sum := 0.0
+	count := 0
+	for _, o := range observations {
+		value := float64(o.Value)
+		if math.IsNaN(value) {
+			continue
+		}
+		min = math.Min(min, value)
+		max = math.Max(max, value)
+		sum += value
+		count++
+	}
+	if count == 0 {
+		return math.NaN(), math.NaN(), math.NaN()
+	}
+
+	return min, max, sum / float64(count)
+}
@@ -0,0 +1,115 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package model
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const hourMillis = float64(60 * 60 * 1000)
+
+func TestResampleTimeseries(t *testing.T) {
+	// 2021-01-01 00:00 UTC at hourly resolution, spanning two days
+	start := float64(1609459200000)
+	observations := []*TimeseriesObservation{}
+	for i := 0; i < 48; i++ {
+		observations = append(observations, &TimeseriesObservation{
+			Time:           start + float64(i)*hourMillis,
+			Value:          NullableFloat64(i),
+			ConfidenceLow:  NullableFloat64(math.NaN()),
+			ConfidenceHigh: NullableFloat64(math.NaN()),
+		})
+	}
+	observations[30].Value = NullableFloat64(math.NaN())
+
+	resampled, err := ResampleTimeseries(observations, TimeseriesIntervalDay, TimeseriesMaxOp)
+	assert.NoError(t, err)
+	assert.Len(t, resampled, 2)
+	assert.Equal(t, start, resampled[0].Time)
+	assert.Equal(t, start+24*hourMillis, resampled[1].Time)
+	assert.Equal(t, NullableFloat64(23), resampled[0].Value)
+	assert.Equal(t, NullableFloat64(47), resampled[1].Value)
+	assert.True(t, math.IsNaN(float64(resampled[0].ConfidenceLow)))
+
+	// the missing value is ignored by the mean
+	resampled, err = ResampleTimeseries(observations[24:], TimeseriesIntervalDay, TimeseriesMeanOp)
+	assert.NoError(t, err)
+	assert.Equal(t, NullableFloat64((24*35.5-30)/23), resampled[0].Value)
+
+	// 2021-01-01 is a friday so the week starts on the previous monday
+	resampled, err = ResampleTimeseries(observations, TimeseriesIntervalWeek, TimeseriesAddOp)
+	assert.NoError(t, err)
+	assert.Len(t, resampled, 1)
+	assert.Equal(t, start-4*24*hourMillis, resampled[0].Time)
+
+	// confidence bounds keep the widest bounds of the bucket whatever the operation
+	forecast := []*TimeseriesObservation{
+		{Time: start, Value: 2, ConfidenceLow: 1, ConfidenceHigh: 3},
+		{Time: start + hourMillis, Value: 5, ConfidenceLow: 4, ConfidenceHigh: 7},
+		{Time: start + 2*hourMillis, Value: 3, ConfidenceLow: NullableFloat64(math.NaN()), ConfidenceHigh: NullableFloat64(math.NaN())},
+	}
+	resampled, err = ResampleTimeseries(forecast, TimeseriesIntervalDay, TimeseriesAddOp)
+	assert.NoError(t, err)
+	assert.Len(t, resampled, 1)
+	assert.Equal(t, NullableFloat64(10), resampled[0].Value)
+	assert.Equal(t, NullableFloat64(1), resampled[0].ConfidenceLow)
+	assert.Equal(t, NullableFloat64(7), resampled[0].ConfidenceHigh)
+
+	_, err = ResampleTimeseries(observations, "decade", TimeseriesAddOp)
+	assert.Error(t, err)
+}
+
+func TestDownsampleTimeseries(t *testing.T) {
+	observations := []*TimeseriesObservation{}
+	for i := 0; i < 100; i++ {
+		observations = append(observations, &TimeseriesObservation{Time: float64(i), Value: 0})
+	}
+	observations[42].Value = 100
+
+	sampled := DownsampleTimeseries(observations, 10)
+	assert.Len(t, sampled, 10)
+	assert.Equal(t, observations[0], sampled[0])
+	assert.Equal(t, observations[99], sampled[9])
+	assert.Contains(t, sampled, observations[42])
+
+	assert.Equal(t, observations, DownsampleTimeseries(observations, 200))
+}
+
+func TestTimeseriesSamplingApply(t *testing.T) {
+	data := &TimeseriesData{
+		SeriesID: "a",
+		Timeseries: []*TimeseriesObservation{
+			{Time: 0, Value: 1},
+			{Time: hourMillis / 2, Value: 2},
+			{Time: hourMillis, Value: 4},
+		},
+		IsDateTime: true,
+	}
+	sampling := &TimeseriesSampling{Resample: &TimeseriesResample{Interval: TimeseriesIntervalHour, Operation: TimeseriesAddOp}}
+	assert.NoError(t, sampling.Validate())
+	assert.NoError(t, sampling.Apply(data))
+	assert.Len(t, data.Timeseries, 2)
+	assert.Equal(t, 3.0, data.Min)
+	assert.Equal(t, 4.0, data.Max)
+
+	data.IsDateTime = false
+	assert.Error(t, sampling.Apply(data))
+
+	assert.Error(t, (&TimeseriesSampling{Resample: &TimeseriesResample{Interval: "hour", Operation: "median"}}).Validate())
+}
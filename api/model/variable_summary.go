@@ -43,6 +43,7 @@ type Bucket struct {
 type Histogram struct {
 	Extrema         *Extrema             `json:"extrema,omitempty"`
 	DefaultBucket   *Bucket              `json:"defaultBucket"`
+	Missing         *Bucket              `json:"missing,omitempty"`
 	Buckets         []*Bucket            `json:"buckets"`
 	CategoryBuckets map[string][]*Bucket `json:"categoryBuckets"`
 	Exemplars       []string             `json:"exemplars"`
@@ -77,8 +77,24 @@ const (
 	RequestFeatureTableName = "request_feature"
 	// RequestFilterTableName is the name of the table for the request filters.
 	RequestFilterTableName = "request_filter"
+	// RequestSplitTableName is the name of the table for the request split manifests.
+	RequestSplitTableName = "request_split"
+	// RequestFilterExpressionTableName is the name of the table for the request filter expressions.
+	RequestFilterExpressionTableName = "request_filter_expression"
+	// SolutionStatusEventTableName is the name of the table for the solution request status events.
+	SolutionStatusEventTableName = "solution_status_event"
+	// PredictionScheduleTableName is the name of the table for the prediction schedules.
+	PredictionScheduleTableName = "prediction_schedule"
+	// PredictionScheduleRunTableName is the name of the table for the prediction schedule run history.
+	PredictionScheduleRunTableName = "prediction_schedule_run"
 	// WordStemTableName is the name of the table for the word stems.
 	WordStemTableName = "word_stem"
+	// DatasetMetadataTableName is the name of the table for the dataset metadata documents.
+	DatasetMetadataTableName = "dataset_metadata"
+	// DatasetLineageTableName is the name of the table for the dataset lineage edges.
+	DatasetLineageTableName = "dataset_lineage"
+	// ExportedModelTableName is the name of the table for the exported model documents.
+	ExportedModelTableName = "exported_model"
 
 	requestTableCreationSQL = `CREATE TABLE %s (
 			request_id			text,
@@ -133,6 +149,68 @@ const (
 			filter_categories	varchar(200),
 			filter_indices		varchar(200)
 		);`
+	requestFilterExpressionTableCreationSQL = `CREATE TABLE %s (
+			request_id			text,
+			expression			jsonb
+		);`
+	requestSplitTableCreationSQL = `CREATE TABLE %s (
+			request_id			text,
+			split_type			varchar(40),
+			seed				bigint,
+			train_test_split	double precision,
+			manifest			jsonb,
+			created_time		timestamp
+		);`
+	solutionStatusEventTableCreationSQL = `CREATE TABLE %s (
+			event_id		bigserial,
+			request_id		text,
+			solution_id		text,
+			result_id		text,
+			progress		varchar(40),
+			error			text,
+			created_time	timestamp
+		);`
+	predictionScheduleTableCreationSQL = `CREATE TABLE %s (
+			schedule_id			text,
+			fitted_solution_id	text,
+			input_path			text,
+			cron				varchar(100),
+			output_path			text,
+			format				varchar(20),
+			created_time		timestamp
+		);`
+	predictionScheduleRunTableCreationSQL = `CREATE TABLE %s (
+			run_id				text,
+			schedule_id			text,
+			input_path			text,
+			status				varchar(40),
+			produce_request_id	text,
+			output_path			text,
+			error				text,
+			start_time			timestamp,
+			end_time			timestamp
+		);`
+	datasetMetadataTableCreationSQL = `CREATE TABLE IF NOT EXISTS %s (
+			dataset_id		text PRIMARY KEY,
+			type			varchar(40),
+			deleted			boolean,
+			document		jsonb,
+			search			tsvector
+		);`
+	datasetLineageTableCreationSQL = `CREATE TABLE IF NOT EXISTS %s (
+			edge_id			text PRIMARY KEY,
+			target			text,
+			document		jsonb,
+			created_time	timestamp
+		);`
+	exportedModelTableCreationSQL = `CREATE TABLE IF NOT EXISTS %s (
+			fitted_solution_id	text PRIMARY KEY,
+			model_name			text,
+			deleted				boolean,
+			document			jsonb,
+			search				tsvector
+		);`
+	searchIndexCreationSQL             = `CREATE INDEX IF NOT EXISTS %s_search ON %s USING GIN (search);`
 	modelFeatureWeightTableCreationSQL = `CREATE TABLE %s (
 			result_id	text	NOT NULL,
 			%s
@@ -140,7 +218,9 @@ const (
 	solutionScoreTableCreationSQL = `CREATE TABLE %s (
 			solution_id	text,
 			metric		varchar(40),
-			score		double precision
+			score		double precision,
+			score_std_dev	double precision,
+			fold_count	integer
 		);`
 	solutionResultTableCreationSQL = `CREATE TABLE %s (
 			solution_id			text,
@@ -258,6 +338,36 @@ func (d *Database) CreateSolutionMetadataTables() error {
 		return errors.Wrap(err, "failed to drop table")
 	}
 
+	_ = d.DropTable(RequestFilterExpressionTableName)
+	_, err = d.Client.Exec(fmt.Sprintf(requestFilterExpressionTableCreationSQL, RequestFilterExpressionTableName))
+	if err != nil {
+		return errors.Wrap(err, "failed to drop table")
+	}
+
+	_ = d.DropTable(RequestSplitTableName)
+	_, err = d.Client.Exec(fmt.Sprintf(requestSplitTableCreationSQL, RequestSplitTableName))
+	if err != nil {
+		return errors.Wrap(err, "failed to drop table")
+	}
+
+	_ = d.DropTable(SolutionStatusEventTableName)
+	_, err = d.Client.Exec(fmt.Sprintf(solutionStatusEventTableCreationSQL, SolutionStatusEventTableName))
+	if err != nil {
+		return errors.Wrap(err, "failed to drop table")
+	}
+
+	_ = d.DropTable(PredictionScheduleTableName)
+	_, err = d.Client.Exec(fmt.Sprintf(predictionScheduleTableCreationSQL, PredictionScheduleTableName))
+	if err != nil {
+		return errors.Wrap(err, "failed to drop table")
+	}
+
+	_ = d.DropTable(PredictionScheduleRunTableName)
+	_, err = d.Client.Exec(fmt.Sprintf(predictionScheduleRunTableCreationSQL, PredictionScheduleRunTableName))
+	if err != nil {
+		return errors.Wrap(err, "failed to drop table")
+	}
+
 	_ = d.DropTable(SolutionTableName)
 	_, err = d.Client.Exec(fmt.Sprintf(solutionTableCreationSQL, SolutionTableName))
 	if err != nil {
@@ -302,6 +412,36 @@ func (d *Database) CreateSolutionMetadataTables() error {
 	return nil
 }
 
+// CreateMetadataStorageTables creates the tables holding the dataset metadata,
+// lineage and exported models if they do not already exist. Unlike the solution
+// tables they are never dropped since they are the system of record.
+func CreateMetadataStorageTables(client DatabaseDriver) error {
+	_, err := client.Exec(fmt.Sprintf(datasetMetadataTableCreationSQL, DatasetMetadataTableName))
+	if err != nil {
+		return errors.Wrap(err, "failed to create dataset metadata table")
+	}
+	_, err = client.Exec(fmt.Sprintf(searchIndexCreationSQL, DatasetMetadataTableName, DatasetMetadataTableName))
+	if err != nil {
+		return errors.Wrap(err, "failed to create dataset metadata search index")
+	}
+
+	_, err = client.Exec(fmt.Sprintf(datasetLineageTableCreationSQL, DatasetLineageTableName))
+	if err != nil {
+		return errors.Wrap(err, "failed to create dataset lineage table")
+	}
+
+	_, err = client.Exec(fmt.Sprintf(exportedModelTableCreationSQL, ExportedModelTableName))
+	if err != nil {
+		return errors.Wrap(err, "failed to create exported model table")
+	}
+	_, err = client.Exec(fmt.Sprintf(searchIndexCreationSQL, ExportedModelTableName, ExportedModelTableName))
+	if err != nil {
+		return errors.Wrap(err, "failed to create exported model search index")
+	}
+
+	return nil
+}
+
 // insertFromSourceUnique function only inserting unique rows.
 func insertFromSourceUnique(d *Database, tableName string, ds *Dataset) error {
 	// first ingest to a temporary table
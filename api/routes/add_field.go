@@ -22,6 +22,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/uncharted-distil/distil-compute/model"
 	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/task"
 	"goji.io/v3/pat"
 )
 
@@ -107,6 +108,17 @@ func AddFieldHandler(metaCtor api.MetadataStorageCtor, dataCtor api.DataStorageC
 			handleError(w, err)
 			return
 		}
+		err = task.RecordLineage(metaStorage, api.LineageOperationAddField, dataset, []string{dataset}, map[string]interface{}{
+			"name":         name,
+			"displayName":  displayName,
+			"fieldType":    fieldType,
+			"defaultValue": defaultValue,
+			"distilRoles":  distilRoles,
+		}, nil)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
 		// marshal output into JSON
 		err = handleJSON(w, map[string]interface{}{
 			"result": "success",
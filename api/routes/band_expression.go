@@ -0,0 +1,193 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"goji.io/v3/pat"
+
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/util/imagery"
+	"github.com/uncharted-distil/distil/api/util/json"
+)
+
+// BandExpressionsHandler returns the band expressions registered for a dataset.
+func BandExpressionsHandler(metaCtor api.MetadataStorageCtor) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dataset := pat.Param(r, "dataset")
+
+		metaStorage, err := metaCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		ds, err := metaStorage.FetchDataset(dataset, false, false, false)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		err = handleJSON(w, map[string]interface{}{
+			"bandExpressions": ds.BandExpressions,
+		})
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal band expressions into JSON"))
+			return
+		}
+	}
+}
+
+// BandExpressionCreateHandler registers a band math expression for a dataset so that
+// it can be referenced as a band combination when rendering multiband images.
+func BandExpressionCreateHandler(metaCtor api.MetadataStorageCtor) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dataset := pat.Param(r, "dataset")
+
+		params, err := getPostParameters(r)
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable to parse post parameters"))
+			return
+		}
+
+		expression := &api.BandExpression{
+			ID:          strings.ToLower(json.StringDefault(params, "", "id")),
+			DisplayName: json.StringDefault(params, "", "displayName"),
+			Expression:  json.StringDefault(params, "", "expression"),
+			Ramp:        json.StringDefault(params, "", "ramp"),
+			Min:         json.FloatDefault(params, 0, "min"),
+			Max:         json.FloatDefault(params, 0, "max"),
+		}
+		if expression.ID == "" || expression.Expression == "" {
+			handleErrorType(w, errors.New("band expression requires an id and an expression"), http.StatusBadRequest)
+			return
+		}
+		if expression.DisplayName == "" {
+			expression.DisplayName = expression.ID
+		}
+
+		metaStorage, err := metaCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		ds, err := metaStorage.FetchDataset(dataset, true, true, true)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		sensor, err := imagery.GetSensorProfile(ds.Sensor)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		if sensor.BandCombinations[expression.ID] != nil || ds.GetBandExpression(expression.ID) != nil {
+			handleErrorType(w, errors.Errorf("band combination '%s' already exists", expression.ID), http.StatusConflict)
+			return
+		}
+
+		// validate the expression against the dataset sensor before storing it
+		_, err = imagery.NewExpressionBandCombination(expression.ID, expression.DisplayName, expression.Expression,
+			expression.Ramp, expression.Min, expression.Max, sensor)
+		if err != nil {
+			handleErrorType(w, err, http.StatusBadRequest)
+			return
+		}
+
+		ds.BandExpressions = append(ds.BandExpressions, expression)
+		err = metaStorage.UpdateDataset(ds)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		err = handleJSON(w, expression)
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal band expression into JSON"))
+			return
+		}
+	}
+}
+
+// BandExpressionDeleteHandler removes a band math expression from a dataset.
+func BandExpressionDeleteHandler(metaCtor api.MetadataStorageCtor) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dataset := pat.Param(r, "dataset")
+		expressionID := strings.ToLower(pat.Param(r, "expression-id"))
+
+		metaStorage, err := metaCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		ds, err := metaStorage.FetchDataset(dataset, true, true, true)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		if ds.GetBandExpression(expressionID) == nil {
+			handleErrorType(w, errors.Errorf("band expression '%s' does not exist", expressionID), http.StatusNotFound)
+			return
+		}
+
+		expressions := []*api.BandExpression{}
+		for _, e := range ds.BandExpressions {
+			if e.ID != expressionID {
+				expressions = append(expressions, e)
+			}
+		}
+		ds.BandExpressions = expressions
+		err = metaStorage.UpdateDataset(ds)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		// the expression ID can be reused so previously rendered images need to go
+		imagery.ClearCache()
+
+		err = handleJSON(w, map[string]interface{}{
+			"result": "success",
+		})
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal result into JSON"))
+			return
+		}
+	}
+}
+
+// getBandCombination resolves a band combination ID to either one of the sensor's
+// band combinations or a band expression registered on the dataset.
+func getBandCombination(ds *api.Dataset, sensor *imagery.SensorProfile, bandCombo string) (*imagery.BandCombination, error) {
+	id := strings.ToLower(bandCombo)
+	if combination, ok := sensor.BandCombinations[id]; ok {
+		return combination, nil
+	}
+
+	expression := ds.GetBandExpression(id)
+	if expression == nil {
+		return nil, errors.Errorf("unhandled band combination %s for sensor %s", id, sensor.ID)
+	}
+
+	return imagery.NewExpressionBandCombination(expression.ID, expression.DisplayName, expression.Expression,
+		expression.Ramp, expression.Min, expression.Max, sensor)
+}
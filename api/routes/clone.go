@@ -92,6 +92,11 @@ func CloningHandler(metaCtor api.MetadataStorageCtor, dataCtor api.DataStorageCt
 			handleError(w, err)
 			return
 		}
+		err = task.RecordLineage(metaStorage, api.LineageOperationClone, datasetClone, []string{dataset}, nil, expandedFilterParams)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
 
 		// marshal output into JSON
 		err = handleJSON(w, map[string]interface{}{"success": true, "clonedDatasetName": datasetClone})
@@ -179,6 +184,17 @@ func CloningResultsHandler(metaCtor api.MetadataStorageCtor, dataCtor api.DataSt
 			handleError(w, err)
 			return
 		}
+		err = task.RecordLineage(metaStorage, api.LineageOperationPredictionResult, newDatasetID,
+			[]string{parsedParams.predictionDataset, parsedParams.sourceDataset}, map[string]interface{}{
+				"produceRequestId": predictionRequestID,
+				"resultUri":        pred.ResultURI,
+				"target":           parsedParams.targetName,
+				"features":         parsedParams.features,
+			}, nil)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
 
 		// marshal output into JSON
 		err = handleJSON(w, map[string]interface{}{"success": true, "newDatasetID": newDatasetID})
@@ -57,7 +57,7 @@ func DataHandler(storageCtor api.DataStorageCtor, metaCtor api.MetadataStorageCt
 		}
 		var data *api.FilteredData
 
-		if len(filterParams.Filters) < 1 && filterParams.Invert {
+		if len(filterParams.Filters) < 1 && filterParams.Expression == nil && filterParams.Invert {
 			// inverted empty filter means return no data
 			err = handleJSON(w, api.EmptyFilterData())
 			if err != nil {
@@ -0,0 +1,66 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"goji.io/v3/pat"
+
+	"github.com/uncharted-distil/distil/api/env"
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/util"
+)
+
+// DataQualityHandler returns the data quality report generated when the
+// dataset was ingested.
+func DataQualityHandler(metaCtor api.MetadataStorageCtor, config *env.Config) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dataset := pat.Param(r, "dataset")
+
+		storage, err := metaCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		ds, err := storage.FetchDataset(dataset, false, false, false)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		reportPath := api.GetDataQualityReportPath(ds, config.DataQualityOutputPath)
+		if !util.FileExists(reportPath) {
+			handleErrorType(w, errors.Errorf("no data quality report exists for dataset %s", dataset), http.StatusNotFound)
+			return
+		}
+
+		report, err := api.LoadDataQualityReport(reportPath)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		// marshal data and sent the response back
+		err = handleJSON(w, report)
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal data quality report into JSON"))
+			return
+		}
+	}
+}
@@ -21,10 +21,17 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
 	"github.com/paulmach/orb"
 	geo "github.com/paulmach/orb/geojson"
 	"github.com/pkg/errors"
@@ -34,6 +41,8 @@ import (
 	"github.com/uncharted-distil/distil-compute/primitive/compute"
 	"github.com/uncharted-distil/distil/api/env"
 	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/serialization"
+	"github.com/uncharted-distil/distil/api/task"
 	"github.com/uncharted-distil/distil/api/util"
 	log "github.com/unchartedsoftware/plog"
 )
@@ -59,8 +68,9 @@ func ExportHandler(client *compute.Client, exportPath string, logger *env.Discov
 	}
 }
 
-// ExportResultHandler will return a CSV file containing the results of a prediction.
-// Data is transformed into a string using a naive print statement.
+// ExportResultHandler will return a file containing the results of a prediction.
+// CSV and geojson data is transformed into a string using a naive print statement,
+// while parquet, arrow and xlsx retain the numeric types.
 func ExportResultHandler(solutionCtor api.SolutionStorageCtor, dataCtor api.DataStorageCtor, metaCtor api.MetadataStorageCtor) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 
@@ -94,84 +104,113 @@ func ExportResultHandler(solutionCtor api.SolutionStorageCtor, dataCtor api.Data
 			return
 		}
 
-		// get the solution result (which is actually the prediction result) using the predict request ID
-		predictResult, err := solution.FetchPredictionResultByProduceRequestID(produceRequestID)
+		contentType, extension, output, err := exportPredictionResult(solution, data, meta, produceRequestID, format)
 		if err != nil {
 			handleError(w, err)
 			return
 		}
 
-		// get the original solution ID out of the result
-		solutionID := predictResult.SolutionID
-
-		// get the filters
-		req, err := solution.FetchRequestBySolutionID(solutionID)
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment;filename=TheCSVFileName.%s", extension))
+		_, err = w.Write(output)
 		if err != nil {
 			handleError(w, err)
 			return
 		}
-		if req == nil {
-			handleError(w, errors.Errorf("solution id `%s` cannot be mapped to result URI", solutionID))
-			return
-		}
+	}
+}
 
-		// if no result, return an empty map
-		if predictResult == nil {
-			err = handleJSON(w, make(map[string]interface{}))
-			if err != nil {
-				handleError(w, errors.Wrap(err, "unable marshal version into JSON and write response"))
-			}
-			return
-		}
+// exportPredictionResult formats the results of a prediction for export.
+func exportPredictionResult(solution api.SolutionStorage, data api.DataStorage, meta api.MetadataStorage,
+	produceRequestID string, format string) (string, string, []byte, error) {
+	// get the solution result (which is actually the prediction result) using the predict request ID
+	predictResult, err := solution.FetchPredictionResultByProduceRequestID(produceRequestID)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if predictResult == nil {
+		return "", "", nil, errors.Errorf("no prediction result found for produce request `%s`", produceRequestID)
+	}
 
-		// Expand any grouped variables defined in filters into their subcomponents
-		dataset := predictResult.Dataset
+	// get the original solution ID out of the result
+	solutionID := predictResult.SolutionID
 
-		ds, err := meta.FetchDataset(dataset, false, false, false)
-		if err != nil {
-			handleError(w, err)
-			return
-		}
-		storageName := ds.StorageName
+	// get the filters
+	req, err := solution.FetchRequestBySolutionID(solutionID)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if req == nil {
+		return "", "", nil, errors.Errorf("solution id `%s` cannot be mapped to result URI", solutionID)
+	}
+
+	// Expand any grouped variables defined in filters into their subcomponents
+	dataset := predictResult.Dataset
+
+	ds, err := meta.FetchDataset(dataset, false, false, false)
+	if err != nil {
+		return "", "", nil, err
+	}
+	storageName := ds.StorageName
+
+	// get row count for export
+	rowCount, err := data.FetchNumRows(storageName, ds.Variables)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if rowCount >= 0 {
+		req.Filters.Size = rowCount
+	}
+	filterParams, err := api.ExpandFilterParams(dataset, req.Filters, false, meta)
+	if err != nil {
+		return "", "", nil, err
+	}
 
-		// get row count for export
-		rowCount, err := data.FetchNumRows(storageName, ds.Variables)
+	results, err := data.FetchResults(dataset, storageName, predictResult.ResultURI, produceRequestID, filterParams, true)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	// replace any NaN values with an empty string
+	resultsTransformed := transformDataForClient(results, api.EmptyString)
+
+	// write out the result in the requested format
+	return createExportedData(req.TargetFeature(), format, resultsTransformed)
+}
+
+// NewPredictionResultExporter creates an exporter writing the results of a prediction
+// in one of the supported export formats.
+func NewPredictionResultExporter(solutionCtor api.SolutionStorageCtor, dataCtor api.DataStorageCtor,
+	metaCtor api.MetadataStorageCtor) task.PredictionExporter {
+	return func(produceRequestID string, format string) (string, []byte, error) {
+		solution, err := solutionCtor()
 		if err != nil {
-			handleError(w, err)
-			return
-		}
-		if rowCount >= 0 {
-			req.Filters.Size = rowCount
+			return "", nil, err
 		}
-		filterParams, err := api.ExpandFilterParams(dataset, req.Filters, false, meta)
+		data, err := dataCtor()
 		if err != nil {
-			handleError(w, err)
-			return
+			return "", nil, err
 		}
-
-		results, err := data.FetchResults(dataset, storageName, predictResult.ResultURI, produceRequestID, filterParams, true)
+		meta, err := metaCtor()
 		if err != nil {
-			handleError(w, err)
-			return
+			return "", nil, err
 		}
 
-		// replace any NaN values with an empty string
-		resultsTransformed := transformDataForClient(results, api.EmptyString)
-
-		// write out the result to CSV
-		contentType, extension, output, err := createExportedData(req.TargetFeature(), format, resultsTransformed)
+		_, extension, output, err := exportPredictionResult(solution, data, meta, produceRequestID, format)
 		if err != nil {
-			handleError(w, err)
-			return
+			return "", nil, err
 		}
 
-		w.Header().Set("Content-Type", contentType)
-		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment;filename=TheCSVFileName.%s", extension))
-		_, err = w.Write(output)
-		if err != nil {
-			handleError(w, err)
-			return
-		}
+		return extension, output, nil
+	}
+}
+
+func isExportFormat(format string) bool {
+	switch format {
+	case "csv", "geojson", "parquet", "arrow", "xlsx":
+		return true
+	default:
+		return false
 	}
 }
 
@@ -181,6 +220,12 @@ func createExportedData(target string, format string, results *FilteredDataClien
 		return exportCSV(results)
 	case "geojson":
 		return exportGeoJSON(target, results)
+	case "parquet":
+		return exportParquet(results)
+	case "arrow":
+		return exportArrow(results)
+	case "xlsx":
+		return exportExcel(results)
 	default:
 		return "", "", nil, errors.Errorf("unsupported export format '%s'", format)
 	}
@@ -216,6 +261,182 @@ func exportCSV(results *FilteredDataClient) (string, string, []byte, error) {
 	return "text/csv", "csv", outputBuffer.Bytes(), nil
 }
 
+// exportColumn describes a column of a typed export.
+type exportColumn struct {
+	label   string
+	numeric bool
+}
+
+// createTypedExportData converts the results to typed rows, storing numeric
+// columns as float64 and adding the confidence and rank of the predictions
+// as numeric columns following the predicted column. Missing values are nil.
+func createTypedExportData(results *FilteredDataClient) ([]*exportColumn, [][]interface{}) {
+	columns := []*exportColumn{}
+	predictedIndex := -1
+	for _, c := range results.Columns {
+		columns = append(columns, &exportColumn{
+			label:   c.Label,
+			numeric: model.IsNumerical(c.Type),
+		})
+		if api.IsPredictedKey(c.Key) {
+			predictedIndex = c.Index
+			columns = append(columns, &exportColumn{label: "Confidence", numeric: true}, &exportColumn{label: "Rank", numeric: true})
+		}
+	}
+
+	rows := make([][]interface{}, len(results.Values))
+	for i, row := range results.Values {
+		typedRow := make([]interface{}, 0, len(columns))
+		for _, c := range results.Columns {
+			var value *api.FilteredDataValue
+			if c.Index < len(row) {
+				value = row[c.Index]
+			}
+			if value == nil {
+				typedRow = append(typedRow, nil)
+			} else if model.IsNumerical(c.Type) {
+				typedRow = append(typedRow, toExportFloat(value.Value))
+			} else {
+				typedRow = append(typedRow, fmt.Sprintf("%v", value.Value))
+			}
+
+			if c.Index == predictedIndex {
+				if value == nil {
+					typedRow = append(typedRow, nil, nil)
+				} else {
+					typedRow = append(typedRow, toExportFloat(float64(value.Confidence)), toExportFloat(float64(value.Rank)))
+				}
+			}
+		}
+		rows[i] = typedRow
+	}
+
+	return columns, rows
+}
+
+// toExportFloat returns the value as a float64, or nil if the value is
+// missing or not a number.
+func toExportFloat(value interface{}) interface{} {
+	var f float64
+	switch v := value.(type) {
+	case float64:
+		f = v
+	case float32:
+		f = float64(v)
+	case int:
+		f = float64(v)
+	case int32:
+		f = float64(v)
+	case int64:
+		f = float64(v)
+	case string:
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil
+		}
+		f = parsed
+	default:
+		return nil
+	}
+	if math.IsNaN(f) {
+		return nil
+	}
+
+	return f
+}
+
+func exportParquet(results *FilteredDataClient) (string, string, []byte, error) {
+	columns, rows := createTypedExportData(results)
+	header := make([]string, len(columns))
+	numeric := make([]bool, len(columns))
+	for i, c := range columns {
+		header[i] = c.label
+		numeric[i] = c.numeric
+	}
+
+	outputBuffer := &bytes.Buffer{}
+	err := serialization.NewParquet().WriteTypedData(outputBuffer, header, numeric, rows)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	return "application/octet-stream", "parquet", outputBuffer.Bytes(), nil
+}
+
+func exportArrow(results *FilteredDataClient) (string, string, []byte, error) {
+	columns, rows := createTypedExportData(results)
+	fields := make([]arrow.Field, len(columns))
+	for i, c := range columns {
+		fields[i] = arrow.Field{Name: c.label, Type: arrow.BinaryTypes.String, Nullable: true}
+		if c.numeric {
+			fields[i].Type = arrow.PrimitiveTypes.Float64
+		}
+	}
+	schema := arrow.NewSchema(fields, nil)
+
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+	for _, row := range rows {
+		for i, value := range row {
+			if value == nil {
+				builder.Field(i).AppendNull()
+			} else if columns[i].numeric {
+				builder.Field(i).(*array.Float64Builder).Append(value.(float64))
+			} else {
+				builder.Field(i).(*array.StringBuilder).Append(value.(string))
+			}
+		}
+	}
+	record := builder.NewRecord()
+	defer record.Release()
+
+	outputBuffer := &bytes.Buffer{}
+	// the streaming format avoids the need for a seekable output
+	wr := ipc.NewWriter(outputBuffer, ipc.WithSchema(schema))
+	err := wr.Write(record)
+	if err != nil {
+		return "", "", nil, errors.Wrap(err, "unable to write arrow record")
+	}
+	err = wr.Close()
+	if err != nil {
+		return "", "", nil, errors.Wrap(err, "unable to close arrow writer")
+	}
+
+	return "application/vnd.apache.arrow.stream", "arrows", outputBuffer.Bytes(), nil
+}
+
+func exportExcel(results *FilteredDataClient) (string, string, []byte, error) {
+	columns, rows := createTypedExportData(results)
+	file := excelize.NewFile()
+	sheet := file.GetSheetName(0)
+
+	header := make([]interface{}, len(columns))
+	for i, c := range columns {
+		header[i] = c.label
+	}
+	err := file.SetSheetRow(sheet, "A1", &header)
+	if err != nil {
+		return "", "", nil, errors.Wrap(err, "unable to write excel header")
+	}
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return "", "", nil, errors.Wrap(err, "unable to determine excel cell")
+		}
+		err = file.SetSheetRow(sheet, cell, &row)
+		if err != nil {
+			return "", "", nil, errors.Wrap(err, "unable to write excel row")
+		}
+	}
+
+	outputBuffer, err := file.WriteToBuffer()
+	if err != nil {
+		return "", "", nil, errors.Wrap(err, "unable to write excel file")
+	}
+
+	return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "xlsx", outputBuffer.Bytes(), nil
+}
+
 func exportGeoJSON(target string, results *FilteredDataClient) (string, string, []byte, error) {
 	if !canExportGeoJSON(results) {
 		return "", "", nil, errors.Errorf("unable to export results to geo json")
@@ -0,0 +1,124 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+	log "github.com/unchartedsoftware/plog"
+	"goji.io/v3/pat"
+
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/serialization"
+	"github.com/uncharted-distil/distil/api/task"
+)
+
+// flushWriter flushes every write to the client so that the response is
+// streamed in chunks rather than buffered.
+type flushWriter struct {
+	writer  io.Writer
+	flusher http.Flusher
+	written bool
+}
+
+func (f *flushWriter) Write(p []byte) (int, error) {
+	f.written = true
+	n, err := f.writer.Write(p)
+	if f.flusher != nil {
+		f.flusher.Flush()
+	}
+	return n, err
+}
+
+// ExportDatasetHandler streams the filtered rows of a dataset to the client
+// as CSV or parquet.
+func ExportDatasetHandler(metaCtor api.MetadataStorageCtor, dataCtor api.DataStorageCtor) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dataset := pat.Param(r, "dataset")
+		format := pat.Param(r, "format")
+
+		params, err := getPostParameters(r)
+		if err != nil {
+			handleError(w, errors.Wrap(err, "Unable to parse post parameters"))
+			return
+		}
+		filterParams, err := api.ParseFilterParamsFromJSON(params)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		metaStorage, err := metaCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		dataStorage, err := dataCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		// replace any grouped variables in filter params with the group's
+		expandedFilterParams, err := api.ExpandFilterParams(dataset, filterParams, false, metaStorage)
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable to expand filter params"))
+			return
+		}
+
+		output := &flushWriter{writer: w}
+		if flusher, ok := w.(http.Flusher); ok {
+			output.flusher = flusher
+		}
+		writer, contentType, err := createStreamWriter(format, output)
+		if err != nil {
+			handleErrorType(w, err, http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment;filename=%s.%s", dataset, format))
+
+		// the status is sent with the first chunk so errors can only be logged past that point
+		err = task.StreamExportDataset(dataset, metaStorage, dataStorage, expandedFilterParams, writer)
+		if err != nil && !output.written {
+			handleError(w, err)
+			return
+		} else if err != nil {
+			log.Errorf("%+v", errors.Wrapf(err, "unable to stream dataset '%s'", dataset))
+			return
+		}
+		err = writer.Close()
+		if err != nil {
+			log.Errorf("%+v", errors.Wrapf(err, "unable to complete dataset '%s' stream", dataset))
+			return
+		}
+	}
+}
+
+func createStreamWriter(format string, output io.Writer) (serialization.StreamWriter, string, error) {
+	switch format {
+	case "csv":
+		return serialization.NewCSVStreamWriter(output), "text/csv", nil
+	case "parquet":
+		return serialization.NewParquetStreamWriter(output), "application/octet-stream", nil
+	default:
+		return nil, "", errors.Errorf("unsupported export format '%s'", format)
+	}
+}
@@ -0,0 +1,60 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uncharted-distil/distil-compute/model"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+func TestCreateTypedExportData(t *testing.T) {
+	results := &FilteredDataClient{
+		Columns: []*api.Column{
+			{Label: "d3mIndex", Key: "d3mIndex", Type: model.IndexType, Index: 0},
+			{Label: "species", Key: "species", Type: model.CategoricalType, Index: 1},
+			{Label: "length", Key: "length", Type: model.RealType, Index: 2},
+			{Label: "predicted", Key: api.GetPredictedKey("solution"), Type: model.RealType, Index: 3},
+		},
+		Values: [][]*api.FilteredDataValue{
+			{{Value: 0}, {Value: "setosa"}, {Value: "1.5"}, {Value: 1.25, Confidence: 0.9, Rank: 1}},
+			{{Value: 1}, nil, {Value: "unknown"}, {Value: math.NaN(), Confidence: api.NullableFloat64(math.NaN()), Rank: 2}},
+			{{Value: int64(2)}, {Value: "virginica"}},
+		},
+	}
+
+	columns, rows := createTypedExportData(results)
+
+	labels := []string{}
+	numeric := []bool{}
+	for _, c := range columns {
+		labels = append(labels, c.label)
+		numeric = append(numeric, c.numeric)
+	}
+	assert.Equal(t, []string{"d3mIndex", "species", "length", "predicted", "Confidence", "Rank"}, labels)
+	assert.Equal(t, []bool{true, false, true, true, true, true}, numeric)
+
+	// missing values and values that are not numbers are exported as nil
+	assert.Equal(t, [][]interface{}{
+		{0.0, "setosa", 1.5, 1.25, 0.9, 1.0},
+		{1.0, nil, nil, nil, nil, 2.0},
+		{2.0, "virginica", nil, nil, nil, nil},
+	}, rows)
+}
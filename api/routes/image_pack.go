@@ -221,6 +221,16 @@ func getMultiBandImages(multiBandPackRequest *ImagePackRequest, optramMap map[st
 		log.Error(err)
 		return
 	}
+	sensor, err := imagery.GetSensorProfile(res.Sensor)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	combination, err := getBandCombination(res, sensor, multiBandPackRequest.Band)
+	if err != nil {
+		log.Error(err)
+		return
+	}
 
 	sourcePath := env.ResolvePath(res.Source, res.Folder)
 	metaDisk, err := metadata.LoadMetadataFromOriginalSchema(path.Join(sourcePath, compute.D3MDataSchema), false)
@@ -260,7 +270,7 @@ func getMultiBandImages(multiBandPackRequest *ImagePackRequest, optramMap map[st
 		if val, ok := optramMap[geoHash]; ok {
 			edge = val
 		}
-		img, err := imagery.ImageFromCombination(sourcePath, bandMapping[imageID], multiBandPackRequest.Band, imageScale, &edge, multiBandPackRequest.Ramp, options)
+		img, err := imagery.ImageFromBandCombination(sourcePath, sensor, bandMapping[imageID], combination, imageScale, &edge, multiBandPackRequest.Ramp, options)
 		if err != nil {
 			handleThreadError(&errorIDs, &imageID, &err)
 			continue
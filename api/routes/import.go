@@ -34,7 +34,8 @@ import (
 	"github.com/uncharted-distil/distil/api/util"
 )
 
-// ImportHandler imports a dataset to the local file system and then ingests it.
+// ImportHandler starts a background job that imports a dataset to the local file
+// system and then ingests it. The job id is returned immediately.
 func ImportHandler(dataCtor api.DataStorageCtor, datamartCtors map[string]api.MetadataStorageCtor,
 	fileMetaCtor api.MetadataStorageCtor, esMetaCtor api.MetadataStorageCtor,
 	config *env.Config) func(http.ResponseWriter, *http.Request) {
@@ -54,100 +55,220 @@ func ImportHandler(dataCtor api.DataStorageCtor, datamartCtors map[string]api.Me
 			return
 		}
 
-		esMetaStorage, err := esMetaCtor()
+		err = importer.ValidateOptions(params)
 		if err != nil {
-			handleError(w, errors.Wrap(err, "Unable to initialize metadata storage connection"))
+			handleErrorType(w, err, http.StatusBadRequest)
 			return
 		}
-		ingestParamsOriginal := &task.IngestParams{
-			Source:   sourceParsed,
-			DataCtor: dataCtor,
-			MetaCtor: esMetaCtor,
-			ID:       datasetIDSource,
-			Type:     api.DatasetTypeModelling,
-		}
 
-		imp := getImporter(provenance, params, esMetaStorage, config)
-		err = imp.Initialize(params, ingestParamsOriginal)
+		esMetaStorage, err := esMetaCtor()
 		if err != nil {
-			handleError(w, errors.Wrap(err, "Unable to initialize import"))
+			handleError(w, errors.Wrap(err, "Unable to initialize metadata storage connection"))
 			return
 		}
 
-		ingestSteps, ingestParams, err := imp.PrepareImport()
+		request := &importRequest{
+			datasetID:     datasetIDSource,
+			source:        sourceParsed,
+			provenance:    provenance,
+			params:        params,
+			dataCtor:      dataCtor,
+			datamartCtors: datamartCtors,
+			fileMetaCtor:  fileMetaCtor,
+			esMetaCtor:    esMetaCtor,
+			esMetaStorage: esMetaStorage,
+			config:        config,
+		}
+		job, err := task.StartImportJob(datasetIDSource, request.run)
 		if err != nil {
-			handleError(w, errors.Wrap(err, "Unable to prepare import"))
+			handleError(w, err)
 			return
 		}
 
-		ingestParams.DataCtor = dataCtor
-		ingestParams.MetaCtor = esMetaCtor
-		ingestParams.ID = datasetIDSource
-		ingestParams.Type = api.DatasetTypeModelling
-
-		meta, err := createMetadataStorageForSource(ingestParams.Source, provenance, datamartCtors, fileMetaCtor, esMetaCtor)
+		// marshal data and sent the response back
+		err = handleJSON(w, map[string]interface{}{
+			"jobId":   job.ID,
+			"dataset": datasetIDSource,
+			"status":  job.Status,
+			"result":  "submitted"})
 		if err != nil {
-			handleError(w, err)
+			handleError(w, errors.Wrap(err, "unable marshal import job into JSON"))
 			return
 		}
+	}
+}
 
-		log.Infof("Importing dataset '%s' from '%s'", ingestParams.ID, ingestParams.Path)
-		dsPath, err := meta.ImportDataset(ingestParams.ID, ingestParams.Path)
+// ImportJobHandler returns the status, current step and logs of an import job.
+func ImportJobHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		job, err := task.GetImportJob(pat.Param(r, "job-id"))
 		if err != nil {
-			handleError(w, err)
+			handleErrorType(w, err, http.StatusNotFound)
 			return
 		}
-		// update dataset description
-		if params["description"] != nil {
-			ds, err := api.LoadDiskDatasetFromFolder(dsPath)
-			if err != nil {
-				handleError(w, err)
-				return
-			}
-			ds.Dataset.Metadata.Description = params["description"].(string)
-			err = ds.SaveDataset()
-			if err != nil {
-				handleError(w, err)
-				return
-			}
-		}
-		// ingest the imported dataset
-		ingestConfig := task.NewConfig(*config)
 
-		// Check if the imported dataset should be sampled
-		if params["nosample"] != nil {
-			ingestConfig.SampleRowLimit = math.MaxInt32 // Maximum int value.
+		err = handleJSON(w, job)
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal import job into JSON"))
+			return
 		}
+	}
+}
 
-		err = moveResources(ingestParams.GetSchemaDocPath())
+// ImportJobsHandler lists the known import jobs.
+func ImportJobsHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := handleJSON(w, task.GetImportJobs())
 		if err != nil {
-			handleError(w, err)
+			handleError(w, errors.Wrap(err, "unable marshal import jobs into JSON"))
 			return
 		}
-		log.Infof("Ingesting dataset '%s'", ingestParams.Path)
-		ingestResult, err := task.IngestDataset(ingestParams, ingestConfig, ingestSteps)
+	}
+}
+
+// CancelImportJobHandler cancels an import job, cleaning up anything it
+// created before it was stopped.
+func CancelImportJobHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		job, err := task.CancelImportJob(pat.Param(r, "job-id"))
 		if err != nil {
-			handleError(w, err)
+			handleErrorType(w, err, http.StatusNotFound)
 			return
 		}
 
-		err = imp.CleanupImport(ingestResult)
+		err = handleJSON(w, job)
 		if err != nil {
-			handleError(w, err)
+			handleError(w, errors.Wrap(err, "unable marshal import job into JSON"))
 			return
 		}
+	}
+}
 
-		// marshal data and sent the response back
-		err = handleJSON(w, map[string]interface{}{
-			"dataset":  ingestResult.DatasetID,
-			"sampled":  ingestResult.Sampled,
-			"rowCount": ingestResult.RowCount,
-			"result":   "ingested"})
+type importRequest struct {
+	datasetID     string
+	source        metadata.DatasetSource
+	provenance    string
+	params        map[string]interface{}
+	dataCtor      api.DataStorageCtor
+	datamartCtors map[string]api.MetadataStorageCtor
+	fileMetaCtor  api.MetadataStorageCtor
+	esMetaCtor    api.MetadataStorageCtor
+	esMetaStorage api.MetadataStorage
+	config        *env.Config
+}
+
+func (i *importRequest) run(job *task.ImportJob) (map[string]interface{}, error) {
+	ingestParamsOriginal := &task.IngestParams{
+		Source:   i.source,
+		DataCtor: i.dataCtor,
+		MetaCtor: i.esMetaCtor,
+		ID:       i.datasetID,
+		Type:     api.DatasetTypeModelling,
+	}
+
+	job.SetStep("prepare")
+	imp := getImporter(i.provenance, i.params, i.esMetaStorage, i.config)
+	err := imp.Initialize(i.params, ingestParamsOriginal)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to initialize import")
+	}
+
+	ingestSteps, ingestParams, err := imp.PrepareImport()
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to prepare import")
+	}
+
+	ingestParams.DataCtor = i.dataCtor
+	ingestParams.MetaCtor = i.esMetaCtor
+	ingestParams.ID = i.datasetID
+	ingestParams.Type = api.DatasetTypeModelling
+	if job.Cancelled() {
+		return nil, cancelImport(job, imp, ingestParams)
+	}
+
+	meta, err := createMetadataStorageForSource(ingestParams.Source, i.provenance, i.datamartCtors, i.fileMetaCtor, i.esMetaCtor)
+	if err != nil {
+		return nil, err
+	}
+
+	job.SetStep("import")
+	log.Infof("Importing dataset '%s' from '%s'", ingestParams.ID, ingestParams.Path)
+	dsPath, err := meta.ImportDataset(ingestParams.ID, ingestParams.Path)
+	if err != nil {
+		return nil, err
+	}
+	// update dataset description
+	if i.params["description"] != nil {
+		ds, err := api.LoadDiskDatasetFromFolder(dsPath)
 		if err != nil {
-			handleError(w, errors.Wrap(err, "unable marshal result histogram into JSON"))
-			return
+			return nil, err
 		}
+		ds.Dataset.Metadata.Description = i.params["description"].(string)
+		err = ds.SaveDataset()
+		if err != nil {
+			return nil, err
+		}
+	}
+	// ingest the imported dataset
+	ingestConfig := task.NewConfig(*i.config)
+
+	// Check if the imported dataset should be sampled
+	if i.params["nosample"] != nil {
+		ingestConfig.SampleRowLimit = math.MaxInt32 // Maximum int value.
 	}
+
+	err = moveResources(ingestParams.GetSchemaDocPath())
+	if err != nil {
+		return nil, err
+	}
+	if job.Cancelled() {
+		return nil, cancelImport(job, imp, ingestParams)
+	}
+
+	job.SetStep("ingest")
+	log.Infof("Ingesting dataset '%s'", ingestParams.Path)
+	ingestResult, err := task.IngestDatasetContext(job.Context(), ingestParams, ingestConfig, ingestSteps)
+	if err == task.ErrIngestCancelled {
+		return nil, cancelImport(job, imp, ingestParams)
+	} else if err != nil {
+		return nil, err
+	}
+
+	job.SetStep("cleanup")
+	err = imp.CleanupImport(ingestResult)
+	if err != nil {
+		return nil, err
+	}
+	job.Log(fmt.Sprintf("ingested dataset '%s'", ingestResult.DatasetID))
+
+	return map[string]interface{}{
+		"dataset":  ingestResult.DatasetID,
+		"sampled":  ingestResult.Sampled,
+		"rowCount": ingestResult.RowCount,
+		"result":   "ingested",
+	}, nil
+}
+
+// cancelImport cleans up the partially imported dataset and returns the
+// cancellation error to report for the job.
+func cancelImport(job *task.ImportJob, imp importer.Importer, ingestParams *task.IngestParams) error {
+	job.SetStep("cancel")
+	partialResult := &task.IngestResult{
+		Cancelled: true,
+		Params:    ingestParams,
+	}
+	// only trust the checkpoint if it was written by this job
+	status, err := task.GetIngestStatus(ingestParams.ID)
+	if err == nil && !status.Created.Before(job.Created) {
+		partialResult.DatasetID = status.IngestedDatasetID
+	}
+
+	err = imp.CleanupImport(partialResult)
+	if err != nil {
+		job.Log(fmt.Sprintf("unable to clean up cancelled import: %v", err))
+	}
+
+	return task.ErrIngestCancelled
 }
 
 func createMetadataStorageForSource(datasetSource metadata.DatasetSource, provenance string,
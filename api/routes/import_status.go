@@ -0,0 +1,85 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"math"
+	"net/http"
+
+	"github.com/pkg/errors"
+	log "github.com/unchartedsoftware/plog"
+	"goji.io/v3/pat"
+
+	"github.com/uncharted-distil/distil/api/env"
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/task"
+)
+
+// ImportStatusHandler returns the per step progress of a dataset ingest, whether
+// it is still running, completed or failed.
+func ImportStatusHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		datasetID := pat.Param(r, "datasetID")
+
+		status, err := task.GetIngestStatus(datasetID)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		// marshal data and sent the response back
+		err = handleJSON(w, status)
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal ingest status into JSON"))
+			return
+		}
+	}
+}
+
+// ImportResumeHandler resumes a failed dataset ingest from its last successful step.
+func ImportResumeHandler(dataCtor api.DataStorageCtor, esMetaCtor api.MetadataStorageCtor, config *env.Config) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		datasetID := pat.Param(r, "datasetID")
+		params, err := getPostParameters(r)
+		if err != nil {
+			handleError(w, errors.Wrap(err, "Unable to parse post parameters"))
+			return
+		}
+
+		ingestConfig := task.NewConfig(*config)
+		if params != nil && params["nosample"] != nil {
+			ingestConfig.SampleRowLimit = math.MaxInt32 // Maximum int value.
+		}
+
+		log.Infof("Resuming ingest of dataset '%s'", datasetID)
+		ingestResult, err := task.ResumeIngestDataset(datasetID, dataCtor, esMetaCtor, ingestConfig)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		// marshal data and sent the response back
+		err = handleJSON(w, map[string]interface{}{
+			"dataset":  ingestResult.DatasetID,
+			"sampled":  ingestResult.Sampled,
+			"rowCount": ingestResult.RowCount,
+			"result":   "ingested"})
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal result histogram into JSON"))
+			return
+		}
+	}
+}
@@ -37,6 +37,13 @@ type MultiBandCombinationDesc struct {
 	Ramp        bool                      `json:"ramp"`
 }
 
+// SensorDesc provides a sensor profile ID, display name and band labels.
+type SensorDesc struct {
+	ID          string   `json:"id"`
+	DisplayName string   `json:"displayName"`
+	Bands       []string `json:"bands"`
+}
+
 // ModelMetricDesc provides a scoring ID, display name, and description.
 type ModelMetricDesc struct {
 	ID          util.MetricID `json:"id"`
@@ -80,7 +87,15 @@ func IndexDataHandler(ctor api.MetadataStorageCtor) func(http.ResponseWriter, *h
 				return
 			}
 
-			combinations = getBandCombinations(env.ResolvePath(ds.Source, ds.Folder))
+			sensor, err := imagery.GetSensorProfile(ds.Sensor)
+			if err != nil {
+				handleError(w, err)
+				return
+			}
+
+			combinations = getBandCombinations(env.ResolvePath(ds.Source, ds.Folder), sensor, ds.BandExpressions)
+		case "sensors":
+			combinations = getSensors()
 		default:
 			err = errors.Errorf("unrecognized index data type '%s'", typ)
 		}
@@ -98,12 +113,12 @@ func IndexDataHandler(ctor api.MetadataStorageCtor) func(http.ResponseWriter, *h
 	}
 }
 
-func getBandCombinations(augmentFolder string) *Combinations {
+func getBandCombinations(augmentFolder string, sensor *imagery.SensorProfile, expressions []*api.BandExpression) *Combinations {
 	optramSupported := true
 	// check augmented folder for optram variables
 	_, err := os.Stat(strings.Join([]string{augmentFolder, imagery.OPTRAMJSONFile}, "/"))
-	size := len(imagery.SentinelBandCombinations)
-	if err != nil {
+	size := len(sensor.BandCombinations)
+	if _, ok := sensor.BandCombinations[imagery.OPTRAM]; err != nil && ok {
 		// file does not exist remove optram
 		optramSupported = false
 		// decrease size of result array
@@ -111,7 +126,7 @@ func getBandCombinations(augmentFolder string) *Combinations {
 	}
 	combinationsList := make([]interface{}, size)
 	idx := 0
-	for _, value := range imagery.SentinelBandCombinations {
+	for _, value := range sensor.BandCombinations {
 
 		// if not supported make sure not to add band combination to results
 		if !optramSupported && value.ID == imagery.OPTRAM {
@@ -123,9 +138,22 @@ func getBandCombinations(augmentFolder string) *Combinations {
 
 		idx++
 	}
+	// user defined expressions are always rendered through a ramp
+	for _, e := range expressions {
+		combinationsList = append(combinationsList, &MultiBandCombinationDesc{imagery.BandCombinationID(e.ID), e.DisplayName, true})
+	}
 	return &Combinations{combinationsList}
 }
 
+func getSensors() *Combinations {
+	profiles := imagery.GetSensorProfiles()
+	sensorList := make([]interface{}, len(profiles))
+	for i, p := range profiles {
+		sensorList[i] = &SensorDesc{p.ID, p.DisplayName, p.Bands}
+	}
+	return &Combinations{sensorList}
+}
+
 func getModelMetrics(task string) *Combinations {
 	var taskMetrics map[string]util.Metric
 
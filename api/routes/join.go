@@ -99,6 +99,12 @@ func JoinHandler(dataCtor api.DataStorageCtor, metaCtor api.MetadataStorageCtor)
 			return
 		}
 
+		// spatial joins match rows on their geometries rather than on column values
+		if params["spatialJoin"] != nil {
+			spatialJoin(w, dataStorage, meta, leftJoin, rightJoin, params)
+			return
+		}
+
 		leftVariables, err := parseVariables(datasetLeft["variables"].([]interface{}))
 		if err != nil {
 			handleError(w, errors.Wrap(err, "unable to parse left variables"))
@@ -526,3 +532,49 @@ func union(w http.ResponseWriter, dataStorage api.DataStorage, metaStorage api.M
 		return
 	}
 }
+
+func spatialJoin(w http.ResponseWriter, dataStorage api.DataStorage, metaStorage api.MetadataStorage,
+	joinLeft *task.JoinSpec, joinRight *task.JoinSpec, params map[string]interface{}) {
+	predicate := json.StringDefault(params, "", "spatialJoin", "predicate")
+	distance := json.FloatDefault(params, 0, "spatialJoin", "distance")
+	if !api.IsValidSpatialPredicate(predicate) {
+		handleErrorType(w, errors.Errorf("unsupported spatial join predicate '%s'", predicate), http.StatusBadRequest)
+		return
+	}
+
+	// the geometry columns are found through the groupings of the stored variables
+	for _, joinSpec := range []*task.JoinSpec{joinLeft, joinRight} {
+		meta, err := getDiskMetadata(joinSpec.DatasetID, metaStorage, false)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		ds, err := metaStorage.FetchDataset(joinSpec.DatasetID, true, true, true)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		joinSpec.ExistingMetadata = meta
+		joinSpec.UpdatedVariables = ds.Variables
+	}
+
+	path, data, err := task.JoinSpatial(dataStorage, joinLeft, joinRight, predicate, distance, params["operation"].(string))
+	if err != nil {
+		handleError(w, errors.Wrap(err, "unable to spatially join datasets"))
+		return
+	}
+
+	// marshal output into JSON
+	bytes, err := json.Marshal(map[string]interface{}{"path": path, "data": transformDataForClient(data, api.EmptyString)})
+	if err != nil {
+		handleError(w, errors.Wrap(err, "unable marshal filtered data result into JSON"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(bytes)
+	if err != nil {
+		handleError(w, errors.Wrap(err, "unable to write filtered data to response writer"))
+		return
+	}
+}
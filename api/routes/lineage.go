@@ -0,0 +1,62 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"goji.io/v3/pat"
+
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/task"
+)
+
+// LineageHandler returns the graph of the derivations that produced a dataset.
+func LineageHandler(metaCtor api.MetadataStorageCtor) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dataset := pat.Param(r, "dataset")
+
+		metaStorage, err := metaCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		exists, err := metaStorage.DatasetExists(dataset)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		if !exists {
+			handleErrorType(w, errors.Errorf("dataset %s does not exist", dataset), http.StatusNotFound)
+			return
+		}
+
+		graph, err := task.FetchLineage(dataset, metaStorage)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		// marshal data and sent the response back
+		err = handleJSON(w, graph)
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal lineage into JSON"))
+			return
+		}
+	}
+}
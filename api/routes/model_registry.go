@@ -0,0 +1,131 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"goji.io/v3/pat"
+
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/task"
+	"github.com/uncharted-distil/distil/api/util/json"
+)
+
+// ModelVersionsHandler lists the versions of a registered model.
+func ModelVersionsHandler(modelCtor api.ExportedModelStorageCtor) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		modelName := pat.Param(r, "model")
+
+		storage, err := modelCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		versions, err := task.FetchModelVersions(modelName, storage)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		err = handleJSON(w, map[string]interface{}{"modelName": modelName, "versions": versions})
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal model versions into JSON"))
+			return
+		}
+	}
+}
+
+// ModelStageHandler moves a model version to the stage supplied in the
+// request body.
+func ModelStageHandler(modelCtor api.ExportedModelStorageCtor) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		modelName := pat.Param(r, "model")
+		version, err := strconv.Atoi(pat.Param(r, "version"))
+		if err != nil {
+			handleErrorType(w, errors.Wrap(err, "unable to parse model version"), http.StatusBadRequest)
+			return
+		}
+
+		params, err := getPostParameters(r)
+		if err != nil {
+			handleError(w, errors.Wrap(err, "Unable to parse post parameters"))
+			return
+		}
+		stage := json.StringDefault(params, "", "stage")
+		if !api.IsModelStage(stage) {
+			handleErrorType(w, errors.Errorf("'%s' is not a valid model stage", stage), http.StatusBadRequest)
+			return
+		}
+
+		storage, err := modelCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		model, err := task.SetModelStage(modelName, version, stage, storage)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		err = handleJSON(w, model)
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal model into JSON"))
+			return
+		}
+	}
+}
+
+// ModelCompareHandler diffs the scores, features and variable rankings of two
+// versions of a model.
+func ModelCompareHandler(modelCtor api.ExportedModelStorageCtor) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		modelName := pat.Param(r, "model")
+		base, err := strconv.Atoi(pat.Param(r, "base"))
+		if err != nil {
+			handleErrorType(w, errors.Wrap(err, "unable to parse base model version"), http.StatusBadRequest)
+			return
+		}
+		other, err := strconv.Atoi(pat.Param(r, "other"))
+		if err != nil {
+			handleErrorType(w, errors.Wrap(err, "unable to parse other model version"), http.StatusBadRequest)
+			return
+		}
+
+		storage, err := modelCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		comparison, err := task.CompareModelVersions(modelName, base, other, storage)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		err = handleJSON(w, comparison)
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal model comparison into JSON"))
+			return
+		}
+	}
+}
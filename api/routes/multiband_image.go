@@ -19,14 +19,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"path"
 	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
-	"github.com/uncharted-distil/distil-compute/metadata"
 	"github.com/uncharted-distil/distil-compute/model"
-	"github.com/uncharted-distil/distil-compute/primitive/compute"
 	c_util "github.com/uncharted-distil/distil-image-upscale/c_util"
 	"github.com/uncharted-distil/distil/api/env"
 	api "github.com/uncharted-distil/distil/api/model"
@@ -75,19 +72,16 @@ func MultiBandImageHandler(ctor api.MetadataStorageCtor, dataCtor api.DataStorag
 			handleError(w, err)
 			return
 		}
-		sourcePath := env.ResolvePath(res.Source, res.Folder)
-
-		// need to read the dataset doc to determine the path to the data resource
-		metaDisk, err := metadata.LoadMetadataFromOriginalSchema(path.Join(sourcePath, compute.D3MDataSchema), false)
+		sensor, err := imagery.GetSensorProfile(res.Sensor)
 		if err != nil {
 			handleError(w, err)
 			return
 		}
-		for _, dr := range metaDisk.DataResources {
-			if dr.IsCollection && dr.ResType == model.ResTypeImage {
-				sourcePath = model.GetResourcePathFromFolder(sourcePath, dr)
-				break
-			}
+		// need to read the dataset doc to determine the path to the data resource
+		sourcePath, err := getImageSourcePath(res)
+		if err != nil {
+			handleError(w, err)
+			return
 		}
 		options := imagery.Options{Gain: 2.5, Gamma: 2.2, GainL: 1.0, Scale: false} // default options for color correction
 		if paramOption != "" {
@@ -124,7 +118,12 @@ func MultiBandImageHandler(ctor api.MetadataStorageCtor, dataCtor api.DataStorag
 			edge = optramMap[geoHash]
 		}
 
-		img, err := imagery.ImageFromCombination(sourcePath, bandMapping[imageID], bandCombo, imageScale, &edge, ramp, options)
+		combination, err := getBandCombination(res, sensor, bandCombo)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		img, err := imagery.ImageFromBandCombination(sourcePath, sensor, bandMapping[imageID], combination, imageScale, &edge, ramp, options)
 		if err != nil {
 			handleError(w, err)
 			return
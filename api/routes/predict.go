@@ -0,0 +1,80 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	comp "github.com/uncharted-distil/distil/api/compute"
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/task"
+)
+
+// PredictHandler generates predictions from a JSON predict request, returning the
+// prediction result once the predictions are complete.
+func PredictHandler(metaCtor api.MetadataStorageCtor, dataCtor api.DataStorageCtor,
+	solutionCtor api.SolutionStorageCtor, modelCtor api.ExportedModelStorageCtor) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		defer r.Body.Close()
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable to read predict request"))
+			return
+		}
+
+		request, err := comp.NewPredictRequest(body)
+		if err != nil {
+			handleErrorType(w, errors.Wrap(err, "unable to unmarshal predict request"), http.StatusBadRequest)
+			return
+		}
+
+		metaStorage, err := metaCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		dataStorage, err := dataCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		solutionStorage, err := solutionCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		modelStorage, err := modelCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		result, err := task.CreatePredictions(request, metaStorage, dataStorage, solutionStorage, modelStorage)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		err = handleJSON(w, result)
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal prediction result into JSON"))
+			return
+		}
+	}
+}
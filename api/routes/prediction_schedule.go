@@ -0,0 +1,151 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"goji.io/v3/pat"
+
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/task"
+	"github.com/uncharted-distil/distil/api/util/json"
+)
+
+// PredictionSchedulesHandler lists the registered prediction schedules.
+func PredictionSchedulesHandler(solutionCtor api.SolutionStorageCtor) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		solution, err := solutionCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		schedules, err := solution.FetchPredictionSchedules()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		err = handleJSON(w, map[string]interface{}{"schedules": schedules})
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal prediction schedules into JSON"))
+			return
+		}
+	}
+}
+
+// PredictionScheduleCreateHandler registers a fitted solution to be run on a schedule
+// against the files found in an input folder or glob.
+func PredictionScheduleCreateHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params, err := getPostParameters(r)
+		if err != nil {
+			handleError(w, errors.Wrap(err, "Unable to parse post parameters"))
+			return
+		}
+
+		fittedSolutionID := json.StringDefault(params, "", "fittedSolutionId")
+		inputPath := json.StringDefault(params, "", "inputPath")
+		cronExpression := json.StringDefault(params, "", "cron")
+		outputPath := json.StringDefault(params, "", "outputPath")
+		format := json.StringDefault(params, "csv", "format")
+		if fittedSolutionID == "" || inputPath == "" || cronExpression == "" || outputPath == "" {
+			handleErrorType(w, errors.New("fittedSolutionId, inputPath, cron and outputPath are all required"), http.StatusBadRequest)
+			return
+		}
+		if !isExportFormat(format) {
+			handleErrorType(w, errors.Errorf("unsupported export format '%s'", format), http.StatusBadRequest)
+			return
+		}
+
+		schedule, err := task.CreatePredictionSchedule(fittedSolutionID, inputPath, cronExpression, outputPath, format)
+		if err != nil {
+			handleErrorType(w, err, http.StatusBadRequest)
+			return
+		}
+
+		err = handleJSON(w, schedule)
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal prediction schedule into JSON"))
+			return
+		}
+	}
+}
+
+// PredictionScheduleDeleteHandler removes a prediction schedule.
+func PredictionScheduleDeleteHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		scheduleID := pat.Param(r, "schedule-id")
+
+		err := task.DeletePredictionSchedule(scheduleID)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		err = handleJSON(w, map[string]interface{}{"scheduleId": scheduleID})
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal response into JSON"))
+			return
+		}
+	}
+}
+
+// PredictionScheduleRunHandler runs a prediction schedule immediately.
+func PredictionScheduleRunHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		scheduleID := pat.Param(r, "schedule-id")
+
+		err := task.RunPredictionSchedule(scheduleID)
+		if err != nil {
+			handleErrorType(w, err, http.StatusConflict)
+			return
+		}
+
+		err = handleJSON(w, map[string]interface{}{"scheduleId": scheduleID})
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal response into JSON"))
+			return
+		}
+	}
+}
+
+// PredictionScheduleRunsHandler returns the run history of a prediction schedule.
+func PredictionScheduleRunsHandler(solutionCtor api.SolutionStorageCtor) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		scheduleID := pat.Param(r, "schedule-id")
+
+		solution, err := solutionCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		runs, err := solution.FetchPredictionScheduleRuns(scheduleID)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		err = handleJSON(w, map[string]interface{}{"runs": runs})
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal prediction schedule runs into JSON"))
+			return
+		}
+	}
+}
@@ -0,0 +1,75 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	comp "github.com/uncharted-distil/distil/api/compute"
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/task"
+)
+
+// QueryHandler ranks the rows of a dataset by their nearness to the labelled rows
+// of a JSON query request.
+func QueryHandler(metaCtor api.MetadataStorageCtor, dataCtor api.DataStorageCtor) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		defer r.Body.Close()
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable to read query request"))
+			return
+		}
+
+		request, err := comp.NewQueryRequest(body)
+		if err != nil {
+			handleErrorType(w, errors.Wrap(err, "unable to parse query request"), http.StatusBadRequest)
+			return
+		}
+
+		metaStorage, err := metaCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		dataStorage, err := dataCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		_, err = task.Query(task.QueryParams{
+			DataStorage: dataStorage,
+			MetaStorage: metaStorage,
+			Dataset:     request.DatasetID,
+			TargetName:  request.Target,
+			Filters:     request.Filters,
+		})
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable to execute query request"))
+			return
+		}
+
+		err = handleJSON(w, map[string]interface{}{"progress": "done", "datasetId": request.DatasetID})
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal query response into JSON"))
+			return
+		}
+	}
+}
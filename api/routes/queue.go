@@ -0,0 +1,36 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/uncharted-distil/distil/api/compute"
+)
+
+// QueueHandler lists the pipelines in progress and queued for processing,
+// with their equivalence key, priority, age, requesters and operations.
+func QueueHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := handleJSON(w, compute.GetQueueStatus())
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal queue status into JSON"))
+			return
+		}
+	}
+}
@@ -90,7 +90,7 @@ func SaveHandler(modelStorageCtor api.ExportedModelStorageCtor, solutionStorageC
 				return
 			}
 
-			err = modelStorage.PersistExportedModel(exported)
+			err = task.RegisterModel(exported, modelStorage)
 		} else {
 			_, err = task.SaveSolution(solutionID)
 		}
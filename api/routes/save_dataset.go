@@ -116,5 +116,11 @@ func SaveDatasetHandler(metaCtor api.MetadataStorageCtor, dataCtor api.DataStora
 			handleError(w, err)
 			return
 		}
+		err = task.RecordLineage(metaStorage, api.LineageOperationSave, dataset, []string{dataset},
+			map[string]interface{}{"datasetName": ds.Name}, expandedFilterParams)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
 	}
 }
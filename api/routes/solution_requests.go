@@ -114,6 +114,35 @@ func SolutionRequestHandler(solutionCtor model.SolutionStorageCtor) func(http.Re
 	}
 }
 
+// SolutionRequestSplitHandler fetches the train/test split manifest of a
+// solution request. The request ID can be supplied as `splitRequestId` in a
+// later solution request to search against the identical split.
+func SolutionRequestSplitHandler(solutionCtor model.SolutionStorageCtor) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// extract route parameters
+		requestID := pat.Param(r, "request-id")
+
+		solution, err := solutionCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		manifest, err := solution.FetchRequestSplitManifest(requestID)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		// marshal data and sent the response back
+		err = handleJSON(w, manifest)
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal split manifest into JSON"))
+			return
+		}
+	}
+}
+
 func handleNullParameter(value string) string {
 	if value == "null" {
 		return ""
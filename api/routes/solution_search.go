@@ -0,0 +1,163 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/primitive/compute"
+	log "github.com/unchartedsoftware/plog"
+	"goji.io/v3/pat"
+
+	comp "github.com/uncharted-distil/distil/api/compute"
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/task"
+	jutil "github.com/uncharted-distil/distil/api/util/json"
+)
+
+// CreateSolutionSearchHandler starts a solution search from a JSON solution request,
+// returning the ID of the request.  The search runs server side, and its progress
+// can be followed through the solution search events route.
+func CreateSolutionSearchHandler(client *compute.Client, metaCtor api.MetadataStorageCtor,
+	dataCtor api.DataStorageCtor, solutionCtor api.SolutionStorageCtor) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		defer r.Body.Close()
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable to read solution request"))
+			return
+		}
+
+		metaStorage, err := metaCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		dataStorage, err := dataCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		solutionStorage, err := solutionCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		search, err := task.CreateSolutionSearch(client, body, metaStorage, dataStorage, solutionStorage)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		err = handleJSON(w, map[string]interface{}{"requestId": search.Request.RequestID})
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal solution request into JSON"))
+			return
+		}
+	}
+}
+
+// StopSolutionSearchHandler cancels any pending work of a running solution search.
+func StopSolutionSearchHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestID := pat.Param(r, "request-id")
+
+		search := comp.GetSolutionSearch(requestID)
+		if search == nil {
+			handleErrorType(w, errors.Errorf("solution request '%s' is not running", requestID), http.StatusNotFound)
+			return
+		}
+		search.Cancel()
+
+		err := handleJSON(w, map[string]interface{}{"requestId": requestID})
+		if err != nil {
+			handleError(w, errors.Wrap(err, "unable marshal stop response into JSON"))
+			return
+		}
+	}
+}
+
+// SolutionSearchEventsHandler streams the status updates of a solution search as
+// server-sent events.  The updates sent so far are replayed first, followed by live
+// updates until the search completes.
+func SolutionSearchEventsHandler(solutionCtor api.SolutionStorageCtor) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestID := pat.Param(r, "request-id")
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			handleError(w, errors.New("response writer does not support streaming"))
+			return
+		}
+
+		solutionStorage, err := solutionCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		// the headers go out with the first event, which may come from the replay
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+
+		// listener calls are never concurrent, and stop once unsubscribed
+		done, unsubscribe, err := comp.SubscribeSolutionSearch(solutionStorage, requestID, func(status comp.SolutionStatus) {
+			event := jutil.StructToMap(status)
+			if status.Error != nil {
+				event["error"] = status.Error.Error()
+			}
+			err := writeServerSentEvent(w, flusher, "status", event)
+			if err != nil {
+				log.Warnf("unable to send status of solution request %s: %v", requestID, err)
+			}
+		})
+		if err != nil {
+			handleErrorType(w, err, http.StatusNotFound)
+			return
+		}
+		defer unsubscribe()
+
+		select {
+		case <-done:
+		case <-r.Context().Done():
+			return
+		}
+
+		err = writeServerSentEvent(w, flusher, "complete", map[string]interface{}{"requestId": requestID})
+		if err != nil {
+			log.Warnf("unable to send completion of solution request %s: %v", requestID, err)
+		}
+	}
+}
+
+func writeServerSentEvent(w http.ResponseWriter, flusher http.Flusher, event string, data interface{}) error {
+	bytes, err := json.Marshal(data)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal event data")
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, bytes)
+	if err != nil {
+		return errors.Wrap(err, "unable to write event")
+	}
+	flusher.Flush()
+
+	return nil
+}
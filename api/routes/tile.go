@@ -0,0 +1,273 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package routes
+
+import (
+	"image/color"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/metadata"
+	"github.com/uncharted-distil/distil-compute/model"
+	"github.com/uncharted-distil/distil-compute/primitive/compute"
+	"github.com/uncharted-distil/distil/api/env"
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/util/imagery"
+	log "github.com/unchartedsoftware/plog"
+	"goji.io/v3/pat"
+)
+
+const (
+	// maxTileImages caps the number of images rendered into a single tile.
+	maxTileImages = 256
+	// defaultOverlayOpacity is the opacity of result overlays when none is supplied.
+	defaultOverlayOpacity = 128
+	overlayPrediction     = "prediction"
+	overlayConfidence     = "confidence"
+)
+
+// TileHandler renders a web mercator XYZ map tile by mosaicing all the multiband
+// images of a dataset intersecting the tile. The predictions or confidences of a
+// result set can optionally be overlaid on each image.
+func TileHandler(metaCtor api.MetadataStorageCtor, dataCtor api.DataStorageCtor, solutionCtor api.SolutionStorageCtor) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dataset := pat.Param(r, "dataset")
+		bandCombo := pat.Param(r, "band-combination")
+		z, errZ := strconv.Atoi(pat.Param(r, "z"))
+		x, errX := strconv.Atoi(pat.Param(r, "x"))
+		y, errY := strconv.Atoi(pat.Param(r, "y"))
+		if errZ != nil || errX != nil || errY != nil || !imagery.ValidTile(z, x, y) {
+			handleErrorType(w, errors.Errorf("invalid tile coordinates"), http.StatusBadRequest)
+			return
+		}
+
+		query := r.URL.Query()
+		resultUUID := query.Get("result")
+		overlay := query.Get("overlay")
+		if overlay == "" {
+			overlay = overlayPrediction
+		}
+		if overlay != overlayPrediction && overlay != overlayConfidence {
+			handleErrorType(w, errors.Errorf("unsupported overlay '%s'", overlay), http.StatusBadRequest)
+			return
+		}
+		opacity := uint8(defaultOverlayOpacity)
+		if query.Get("opacity") != "" {
+			value, err := strconv.ParseFloat(query.Get("opacity"), 64)
+			if err != nil || value < 0 || value > 1 {
+				handleErrorType(w, errors.Errorf("opacity must be between 0 and 1"), http.StatusBadRequest)
+				return
+			}
+			opacity = uint8(value * 255)
+		}
+		colorScale := imagery.GetColorScale(query.Get("colorScale"))
+		ramp := query.Get("ramp")
+
+		metaStorage, err := metaCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		dataStorage, err := dataCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		solutionStorage, err := solutionCtor()
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
+		ds, err := metaStorage.FetchDataset(dataset, false, false, false)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		sensor, err := imagery.GetSensorProfile(ds.Sensor)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		combination, err := getBandCombination(ds, sensor, bandCombo)
+		if err != nil {
+			handleErrorType(w, err, http.StatusBadRequest)
+			return
+		}
+
+		// find the image grouping and the geometry created for its bounds
+		groupingCol := ""
+		var geoBounds *model.GeoBoundsGrouping
+		for _, v := range ds.Variables {
+			if !v.IsGrouping() {
+				continue
+			}
+			if model.IsMultiBandImage(v.Type) {
+				groupingCol = v.Grouping.(*model.MultiBandImageGrouping).IDCol
+			} else if g, ok := v.Grouping.(*model.GeoBoundsGrouping); ok {
+				geoBounds = g
+			}
+		}
+		if groupingCol == "" || geoBounds == nil {
+			handleErrorType(w, errors.Errorf("dataset '%s' does not contain geo referenced multiband images", dataset), http.StatusBadRequest)
+			return
+		}
+
+		resultURI := ""
+		if resultUUID != "" {
+			result, err := solutionStorage.FetchSolutionResultByUUID(resultUUID)
+			if err != nil {
+				handleError(w, err)
+				return
+			}
+			if result == nil {
+				result, err = solutionStorage.FetchPredictionResultByUUID(resultUUID)
+				if err != nil {
+					handleError(w, err)
+					return
+				}
+			}
+			if result == nil {
+				handleErrorType(w, errors.Errorf("result '%s' not found", resultUUID), http.StatusNotFound)
+				return
+			}
+			resultURI = result.ResultURI
+		}
+
+		minX, minY, maxX, maxY := imagery.TileBounds(z, x, y)
+		bounds := &model.Bounds{MinX: minX, MinY: minY, MaxX: maxX, MaxY: maxY}
+		images, err := dataStorage.FetchTileImages(dataset, ds.StorageName, groupingCol, geoBounds.CoordinatesCol,
+			geoBounds.PolygonCol, bounds, resultURI, maxTileImages)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		if len(images) == maxTileImages {
+			log.Warnf("tile %d/%d/%d of dataset '%s' limited to %d images", z, x, y, dataset, maxTileImages)
+		}
+
+		chips := []*imagery.TileChip{}
+		if len(images) > 0 {
+			groupIDs := make([]string, len(images))
+			for i, image := range images {
+				groupIDs[i] = image.GroupID
+			}
+			bandMapping, err := getBandMapping(ds, groupIDs, dataStorage)
+			if err != nil {
+				handleError(w, err)
+				return
+			}
+			sourcePath, err := getImageSourcePath(ds)
+			if err != nil {
+				handleError(w, err)
+				return
+			}
+
+			overlayColor := newOverlayColorer(dataStorage, ds, resultURI, overlay, colorScale)
+			options := imagery.Options{Gain: 2.5, Gamma: 2.2, GainL: 1.0, Scale: false}
+			for _, image := range images {
+				chip := &imagery.TileChip{}
+				chip.MinX, chip.MinY, chip.MaxX, chip.MaxY = image.Bounds()
+
+				// images covering only a small part of the tile can be rendered at thumbnail scale
+				imageScale := imagery.ImageScale{}
+				width, height := imagery.TilePixelSize(z, chip.MinX, chip.MinY, chip.MaxX, chip.MaxY)
+				if width < ThumbnailDimensions && height < ThumbnailDimensions {
+					imageScale = imagery.ImageScale{Width: ThumbnailDimensions, Height: ThumbnailDimensions}
+				}
+				chip.Image, err = imagery.ImageFromBandCombination(sourcePath, sensor, bandMapping[image.GroupID], combination,
+					imageScale, &imagery.OptramEdges{}, ramp, options)
+				if err != nil {
+					handleError(w, err)
+					return
+				}
+				if resultURI != "" {
+					chip.Overlay, err = overlayColor(image)
+					if err != nil {
+						handleError(w, err)
+						return
+					}
+				}
+				chips = append(chips, chip)
+			}
+		}
+
+		imageBytes, err := imagery.ImageToPNG(imagery.MosaicTile(z, x, y, chips, opacity))
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		_, err = w.Write(imageBytes)
+		if err != nil {
+			handleError(w, errors.Wrap(err, "failed to write tile bytes"))
+			return
+		}
+	}
+}
+
+// getImageSourcePath reads the dataset doc to determine the folder containing the
+// image collection of a dataset.
+func getImageSourcePath(ds *api.Dataset) (string, error) {
+	sourcePath := env.ResolvePath(ds.Source, ds.Folder)
+	metaDisk, err := metadata.LoadMetadataFromOriginalSchema(path.Join(sourcePath, compute.D3MDataSchema), false)
+	if err != nil {
+		return "", err
+	}
+	for _, dr := range metaDisk.DataResources {
+		if dr.IsCollection && dr.ResType == model.ResTypeImage {
+			return model.GetResourcePathFromFolder(sourcePath, dr), nil
+		}
+	}
+	return sourcePath, nil
+}
+
+// newOverlayColorer returns a function mapping the result of an image to its overlay colour.
+// Numeric predictions are scaled over the extrema of the result set, anything else is
+// treated as a category.
+func newOverlayColorer(dataStorage api.DataStorage, ds *api.Dataset, resultURI string, overlay string,
+	colorScale func(float64) *color.RGBA) func(*api.TileImage) (*color.RGBA, error) {
+	var extrema *api.Extrema
+	return func(image *api.TileImage) (*color.RGBA, error) {
+		if overlay == overlayConfidence {
+			if image.Confidence == nil {
+				return nil, nil
+			}
+			return colorScale(*image.Confidence), nil
+		}
+
+		if image.Prediction == "" {
+			return nil, nil
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(image.Prediction), 64)
+		if err != nil {
+			return imagery.CategoryColor(image.Prediction), nil
+		}
+		if extrema == nil {
+			extrema, err = dataStorage.FetchResultsExtremaByURI(ds.ID, ds.StorageName, resultURI)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if extrema.Max <= extrema.Min {
+			return colorScale(0), nil
+		}
+		return colorScale((value - extrema.Min) / (extrema.Max - extrema.Min)), nil
+	}
+}
@@ -97,6 +97,12 @@ func TimeseriesHandler(metaCtor api.MetadataStorageCtor, ctorStorage api.DataSto
 			timeseries = append(timeseries, timeseriesData...)
 		}
 
+		err = sampleTimeseries(timeseries, params)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
 		err = handleJSON(w, timeseries)
 		if err != nil {
 			handleError(w, errors.Wrap(err, "unable marshal dataset result into JSON"))
@@ -112,6 +118,7 @@ type timeseriesParams struct {
 	} `json:"timeseries"`
 	DuplicateOperation string          `json:"duplicateOperation"`
 	FilterParams       json.RawMessage `json:"filterParams"`
+	api.TimeseriesSampling
 }
 
 // parse post parameters into a structure
@@ -127,5 +134,21 @@ func parsePostParms(r *http.Request) (*timeseriesParams, error) {
 		return nil, errors.Wrap(err, "unable to unmarshall post request params")
 	}
 
+	if err = params.TimeseriesSampling.Validate(); err != nil {
+		return nil, err
+	}
+
 	return &params, nil
 }
+
+// sampleTimeseries resamples and downsamples the timeseries as requested by
+// the post parameters.
+func sampleTimeseries(timeseries []*api.TimeseriesData, params *timeseriesParams) error {
+	for _, t := range timeseries {
+		err := params.TimeseriesSampling.Apply(t)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
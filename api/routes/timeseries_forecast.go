@@ -146,10 +146,27 @@ func TimeseriesForecastHandler(metaCtor api.MetadataStorageCtor, dataCtor api.Da
 			forecasts = append(forecasts, forecastData...)
 		}
 
+		// compute the train/test split for visualization purposes from the raw
+		// series since sampling can drop or move the points it is based on
+		splits := make([]compute.TimeStampSplit, len(timeseries))
+		for idx, t := range timeseries {
+			splits[idx] = compute.SplitTimeSeries(t.Timeseries, trainTestSplitTimeSeries)
+		}
+
+		err = sampleTimeseries(timeseries, params)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		err = sampleTimeseries(forecasts, params)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+
 		result := []*TimeseriesForecastResult{}
 		for idx, t := range timeseries {
-			// Recompute train/test split info for visualization purposes
-			split := compute.SplitTimeSeries(t.Timeseries, trainTestSplitTimeSeries)
+			split := splits[idx]
 			forecast := forecasts[idx]
 			result = append(result, &TimeseriesForecastResult{
 				VarKey:            t.VarKey,
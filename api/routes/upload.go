@@ -32,6 +32,7 @@ import (
 	"github.com/uncharted-distil/distil/api/dataset"
 	"github.com/uncharted-distil/distil/api/env"
 	"github.com/uncharted-distil/distil/api/rest"
+	"github.com/uncharted-distil/distil/api/task/importer"
 	"github.com/uncharted-distil/distil/api/util"
 )
 
@@ -66,18 +67,21 @@ func UploadHandler(config *env.Config) func(http.ResponseWriter, *http.Request)
 
 			// Create a form file reader.  It will be combined with a file writer in a subsequent step.
 			var formFile multipart.File
-			formFile, _, err = r.FormFile("file")
+			var formHeader *multipart.FileHeader
+			formFile, formHeader, err = r.FormFile("file")
 			if err != nil {
 				handleError(w, errors.Wrap(err, "unable to receive file from request"))
+				return
 			}
 			defer formFile.Close()
 
 			// Figure out what type of dataset we've got
 			if typ == "table" {
+				// keep the raw file extension so the right dataset constructor gets used on import
 				log.Infof("Uploaded table dataset '%s'", datasetName)
 				tmpPath := env.GetTmpPath()
-				csvFilename := path.Join(tmpPath, fmt.Sprintf("%s_raw.csv", datasetName))
-				outputPath = util.GetUniqueName(csvFilename)
+				rawFilename := path.Join(tmpPath, fmt.Sprintf("%s_raw%s", datasetName, importer.RawFileExtension(formHeader.Filename)))
+				outputPath = util.GetUniqueName(rawFilename)
 				err = util.WriteFormFileWithDirs(outputPath, formFile, os.ModePerm)
 			} else if typ == "media" {
 				// Expand the data into temp storage
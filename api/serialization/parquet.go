@@ -18,6 +18,7 @@ package serialization
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
@@ -26,6 +27,7 @@ import (
 
 	log "github.com/unchartedsoftware/plog"
 	"github.com/xitongsys/parquet-go-source/local"
+	sourcewriter "github.com/xitongsys/parquet-go-source/writer"
 	"github.com/xitongsys/parquet-go/parquet"
 	"github.com/xitongsys/parquet-go/reader"
 	"github.com/xitongsys/parquet-go/writer"
@@ -224,6 +226,38 @@ func (d *Parquet) WriteData(uri string, data [][]string) error {
 	return nil
 }
 
+// WriteTypedData writes data to a parquet stream, storing the numeric columns
+// as doubles. Nil values are written as nulls.
+func (d *Parquet) WriteTypedData(output io.Writer, header []string, numeric []bool, data [][]interface{}) error {
+	md := make([]string, len(header))
+	for i, c := range header {
+		if numeric[i] {
+			md[i] = fmt.Sprintf("name=%s, type=DOUBLE, repetitiontype=OPTIONAL", c)
+		} else {
+			md[i] = fmt.Sprintf("name=%s, type=UTF8, repetitiontype=OPTIONAL", c)
+		}
+	}
+
+	pw, err := writer.NewCSVWriter(md, sourcewriter.NewWriterFile(output), 1)
+	if err != nil {
+		return errors.Wrap(err, "unable to create parquet writer")
+	}
+	pw.CompressionType = parquet.CompressionCodec_UNCOMPRESSED
+
+	for i, row := range data {
+		err = pw.Write(row)
+		if err != nil {
+			return errors.Wrapf(err, "error writing row %d/%d to parquet stream", i, len(data))
+		}
+	}
+	err = pw.WriteStop()
+	if err != nil {
+		return errors.Wrap(err, "error ending parquet write")
+	}
+
+	return nil
+}
+
 // ReadRawVariables reads the metadata and extracts the field names.
 func (d *Parquet) ReadRawVariables(uri string) ([]string, error) {
 	fr, err := local.NewLocalFileReader(uri)
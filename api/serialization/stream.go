@@ -0,0 +1,146 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package serialization
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+
+	sourcewriter "github.com/xitongsys/parquet-go-source/writer"
+)
+
+const (
+	// row groups are buffered in memory until written so keep them small
+	// enough to bound the memory used by a streamed export
+	parquetStreamRowGroupSize = 16 * 1024 * 1024
+)
+
+// StreamWriter writes data to an output stream as it is received.
+type StreamWriter interface {
+	WriteHeader(header []string) error
+	WriteRows(rows [][]string) error
+	Close() error
+}
+
+// CSVStreamWriter writes streamed data as CSV.
+type CSVStreamWriter struct {
+	writer *csv.Writer
+}
+
+// ParquetStreamWriter writes streamed data as parquet.
+type ParquetStreamWriter struct {
+	output io.Writer
+	writer *writer.CSVWriter
+}
+
+// NewCSVStreamWriter creates a stream writer that outputs CSV.
+func NewCSVStreamWriter(output io.Writer) *CSVStreamWriter {
+	return &CSVStreamWriter{
+		writer: csv.NewWriter(output),
+	}
+}
+
+// WriteHeader writes the CSV header.
+func (c *CSVStreamWriter) WriteHeader(header []string) error {
+	err := c.writer.Write(header)
+	if err != nil {
+		return errors.Wrap(err, "unable to write csv header")
+	}
+
+	return nil
+}
+
+// WriteRows writes the rows to the output, flushing once all are written.
+func (c *CSVStreamWriter) WriteRows(rows [][]string) error {
+	err := c.writer.WriteAll(rows)
+	if err != nil {
+		return errors.Wrap(err, "unable to write csv rows")
+	}
+
+	return nil
+}
+
+// Close flushes any buffered data.
+func (c *CSVStreamWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+// NewParquetStreamWriter creates a stream writer that outputs parquet. The
+// writer is initialized when the header is written.
+func NewParquetStreamWriter(output io.Writer) *ParquetStreamWriter {
+	return &ParquetStreamWriter{
+		output: output,
+	}
+}
+
+// WriteHeader creates the parquet schema from the header.
+func (p *ParquetStreamWriter) WriteHeader(header []string) error {
+	md := make([]string, len(header))
+	for i, c := range header {
+		md[i] = fmt.Sprintf("name=%s, type=UTF8", c)
+	}
+
+	pw, err := writer.NewCSVWriter(md, sourcewriter.NewWriterFile(p.output), 1)
+	if err != nil {
+		return errors.Wrap(err, "unable to create parquet writer")
+	}
+	pw.CompressionType = parquet.CompressionCodec_UNCOMPRESSED
+	pw.RowGroupSize = parquetStreamRowGroupSize
+	p.writer = pw
+
+	return nil
+}
+
+// WriteRows adds the rows to the parquet output. Rows are written out
+// whenever a row group is complete.
+func (p *ParquetStreamWriter) WriteRows(rows [][]string) error {
+	if p.writer == nil {
+		return errors.New("parquet header must be written before rows")
+	}
+
+	for i, rowData := range rows {
+		row := make([]interface{}, len(rowData))
+		for ic, c := range rowData {
+			row[ic] = c
+		}
+		err := p.writer.Write(row)
+		if err != nil {
+			return errors.Wrapf(err, "error writing row %d/%d to parquet stream", i, len(rows))
+		}
+	}
+
+	return nil
+}
+
+// Close writes the remaining row group and the parquet footer.
+func (p *ParquetStreamWriter) Close() error {
+	if p.writer == nil {
+		return nil
+	}
+
+	err := p.writer.WriteStop()
+	if err != nil {
+		return errors.Wrap(err, "error ending parquet write")
+	}
+
+	return nil
+}
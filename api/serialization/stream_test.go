@@ -0,0 +1,80 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package serialization
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamWriters(t *testing.T) {
+	header := []string{"d3mIndex", "species", "length"}
+	chunks := [][][]string{
+		{{"0", "setosa", "1.5"}, {"1", "virginica, tall", "6.0"}},
+		{{"2", "versicolor", ""}},
+	}
+	expected := [][]string{header}
+	for _, chunk := range chunks {
+		expected = append(expected, chunk...)
+	}
+
+	formats := map[string]struct {
+		create func(output *os.File) StreamWriter
+		read   func(uri string) ([][]string, error)
+	}{
+		"csv": {
+			create: func(output *os.File) StreamWriter { return NewCSVStreamWriter(output) },
+			read:   NewCSV().ReadData,
+		},
+		"parquet": {
+			create: func(output *os.File) StreamWriter { return NewParquetStreamWriter(output) },
+			read:   NewParquet().ReadData,
+		},
+	}
+	for name, format := range formats {
+		t.Run(name, func(t *testing.T) {
+			uri := path.Join(t.TempDir(), "stream."+name)
+			output, err := os.Create(uri)
+			assert.NoError(t, err)
+
+			// rows are written as they are streamed from the data storage
+			w := format.create(output)
+			assert.NoError(t, w.WriteHeader(header))
+			for _, chunk := range chunks {
+				assert.NoError(t, w.WriteRows(chunk))
+			}
+			assert.NoError(t, w.Close())
+			assert.NoError(t, output.Close())
+
+			data, err := format.read(uri)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, data)
+		})
+	}
+}
+
+func TestParquetStreamWriterHeader(t *testing.T) {
+	output, err := os.Create(path.Join(t.TempDir(), "stream.parquet"))
+	assert.NoError(t, err)
+	defer output.Close()
+
+	w := NewParquetStreamWriter(output)
+	assert.Error(t, w.WriteRows([][]string{{"0"}}))
+	assert.NoError(t, w.Close())
+}
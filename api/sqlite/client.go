@@ -0,0 +1,222 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/pkg/errors"
+	log "github.com/unchartedsoftware/plog"
+)
+
+const (
+	// MemoryPath is the path of the in memory database, shared by every
+	// client of the process.
+	MemoryPath = ":memory:"
+
+	driverName = "sqlite3_distil"
+)
+
+var (
+	mu      = &sync.Mutex{}
+	clients map[string]*sql.DB
+
+	regexMu    = &sync.Mutex{}
+	regexCache map[string]*regexp.Regexp
+)
+
+func init() {
+	clients = make(map[string]*sql.DB)
+	regexCache = make(map[string]*regexp.Regexp)
+
+	sql.Register(driverName, &sqlite3.SQLiteDriver{
+		ConnectHook: registerFunctions,
+	})
+}
+
+// ClientCtor represents a client constructor to instantiate a sqlite client.
+type ClientCtor func() (*sql.DB, error)
+
+// NewClient instantiates and returns a new sqlite client constructor. Clients
+// are cached by path so that every constructor using the same path shares a
+// single database handle.
+func NewClient(path string) ClientCtor {
+	return func() (*sql.DB, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if client, ok := clients[path]; ok {
+			return client, nil
+		}
+
+		dsn := fmt.Sprintf("file:%s?_busy_timeout=10000&_journal_mode=WAL", path)
+		if path == MemoryPath {
+			dsn = "file::memory:"
+		}
+		log.Infof("opening sqlite database %s", path)
+		client, err := sql.Open(driverName, dsn)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to open sqlite database %s", path)
+		}
+
+		// sqlite only supports a single writer and the in memory database only
+		// lives as long as its connection, so all queries go through a single
+		// connection. Callers must therefore close their rows before issuing
+		// another query.
+		client.SetMaxOpenConns(1)
+		client.SetConnMaxLifetime(0)
+
+		err = client.Ping()
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to connect to sqlite database %s", path)
+		}
+
+		clients[path] = client
+		return client, nil
+	}
+}
+
+// registerFunctions adds the functions postgres provides natively and the
+// storage queries rely on.
+func registerFunctions(conn *sqlite3.SQLiteConn) error {
+	err := conn.RegisterFunc("regexp", matchRegex, true)
+	if err != nil {
+		return errors.Wrap(err, "unable to register regexp function")
+	}
+	err = conn.RegisterFunc("floor", floor, true)
+	if err != nil {
+		return errors.Wrap(err, "unable to register floor function")
+	}
+	err = conn.RegisterFunc("is_numeric", isNumeric, true)
+	if err != nil {
+		return errors.Wrap(err, "unable to register is_numeric function")
+	}
+	err = conn.RegisterAggregator("stddev", newStdDev, true)
+	if err != nil {
+		return errors.Wrap(err, "unable to register stddev aggregate")
+	}
+
+	return nil
+}
+
+// matchRegex implements the REGEXP operator.
+func matchRegex(pattern string, value interface{}) (bool, error) {
+	if value == nil {
+		return false, nil
+	}
+
+	regexMu.Lock()
+	re, ok := regexCache[pattern]
+	if !ok {
+		var err error
+		re, err = regexp.Compile(pattern)
+		if err != nil {
+			regexMu.Unlock()
+			return false, errors.Wrapf(err, "invalid regular expression '%s'", pattern)
+		}
+		regexCache[pattern] = re
+	}
+	regexMu.Unlock()
+
+	switch v := value.(type) {
+	case string:
+		return re.MatchString(v), nil
+	case []byte:
+		return re.Match(v), nil
+	default:
+		return re.MatchString(fmt.Sprintf("%v", v)), nil
+	}
+}
+
+// floor rounds the value down, returning null for nulls.
+func floor(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int64:
+		return v
+	case float64:
+		return math.Floor(v)
+	default:
+		return nil
+	}
+}
+
+// isNumeric returns true if the value can be parsed as a finite number. NaN
+// is excluded since sqlite stores it as null.
+func isNumeric(value interface{}) bool {
+	var text string
+	switch v := value.(type) {
+	case int64:
+		return true
+	case float64:
+		return !math.IsNaN(v) && !math.IsInf(v, 0)
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		return false
+	}
+
+	parsed, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	return err == nil && !math.IsNaN(parsed) && !math.IsInf(parsed, 0)
+}
+
+// stdDev is the sample standard deviation aggregate, matching the postgres
+// stddev function. It uses Welford's algorithm to remain stable.
+type stdDev struct {
+	count int64
+	mean  float64
+	m2    float64
+}
+
+func newStdDev() *stdDev {
+	return &stdDev{}
+}
+
+func (s *stdDev) Step(value interface{}) {
+	var x float64
+	switch v := value.(type) {
+	case int64:
+		x = float64(v)
+	case float64:
+		x = v
+	default:
+		// nulls and text are ignored like in postgres
+		return
+	}
+	if math.IsNaN(x) {
+		return
+	}
+
+	s.count++
+	delta := x - s.mean
+	s.mean += delta / float64(s.count)
+	s.m2 += delta * (x - s.mean)
+}
+
+func (s *stdDev) Done() interface{} {
+	if s.count < 2 {
+		return nil
+	}
+	return math.Sqrt(s.m2 / float64(s.count-1))
+}
@@ -0,0 +1,649 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	log "github.com/unchartedsoftware/plog"
+
+	"github.com/uncharted-distil/distil/api/postgres"
+)
+
+const (
+	// Database data types, named after their postgres equivalent so that the
+	// postgres type mappings can be reused.
+	dataTypeText    = "TEXT"
+	dataTypeDouble  = "double precision"
+	dataTypeFloat   = "FLOAT8"
+	dataTypeVector  = "FLOAT8[]"
+	dataTypeInteger = "INTEGER"
+	dataTypeDate    = "TIMESTAMP"
+	dataTypeBool    = "BOOLEAN"
+	dataTypeCoord   = "SPECIAL_COORD"
+	dataTypeReal    = "REAL"
+	dataTypeExplain = "DOUBLE"
+
+	metadataTableCreationSQL = `CREATE TABLE %s (
+			name	TEXT	NOT NULL,
+			role	TEXT,
+			type	TEXT
+		);`
+	resultTableCreationSQL = `CREATE TABLE %s (
+			result_id	TEXT	NOT NULL,
+			"index"		INTEGER,
+			target		TEXT,
+			value		TEXT,
+			explain_values TEXT
+		);`
+	requestTableCreationSQL = `CREATE TABLE IF NOT EXISTS %s (
+			request_id			TEXT,
+			dataset				TEXT,
+			progress			TEXT,
+			created_time		TIMESTAMP,
+			last_updated_time	TIMESTAMP
+		);`
+	predictionTableCreationSQL = `CREATE TABLE IF NOT EXISTS %s (
+			request_id			TEXT,
+			dataset				TEXT,
+			target				TEXT,
+			fitted_solution_id	TEXT,
+			progress			TEXT,
+			created_time		TIMESTAMP,
+			last_updated_time	TIMESTAMP
+		);`
+	solutionTableCreationSQL = `CREATE TABLE IF NOT EXISTS %s (
+			request_id				TEXT,
+			solution_id				TEXT,
+			explained_solution_id	TEXT,
+			created_time			TIMESTAMP,
+			deleted					BOOLEAN
+		);`
+	solutionFeatureWeightTableCreationSQL = `CREATE TABLE IF NOT EXISTS %s (
+			solution_id		TEXT,
+			feature_name	TEXT,
+			feature_index	INTEGER,
+			weight			REAL
+		);`
+	solutionStateTableCreationSQL = `CREATE TABLE IF NOT EXISTS %s (
+			solution_id		TEXT,
+			progress		TEXT,
+			created_time	TIMESTAMP
+		);`
+	requestFeatureTableCreationSQL = `CREATE TABLE IF NOT EXISTS %s (
+			request_id		TEXT,
+			feature_name	TEXT,
+			feature_type	TEXT
+		);`
+	requestFilterTableCreationSQL = `CREATE TABLE IF NOT EXISTS %s (
+			request_id			TEXT,
+			feature_name		TEXT,
+			filter_type			TEXT,
+			filter_mode			TEXT,
+			filter_min			REAL,
+			filter_max			REAL,
+			filter_min_x		REAL,
+			filter_max_x		REAL,
+			filter_min_y		REAL,
+			filter_max_y		REAL,
+			filter_categories	TEXT,
+			filter_indices		TEXT
+		);`
+	requestFilterExpressionTableCreationSQL = `CREATE TABLE IF NOT EXISTS %s (
+			request_id			TEXT,
+			expression			TEXT
+		);`
+	requestSplitTableCreationSQL = `CREATE TABLE IF NOT EXISTS %s (
+			request_id			TEXT,
+			split_type			TEXT,
+			seed				INTEGER,
+			train_test_split	REAL,
+			manifest			TEXT,
+			created_time		TIMESTAMP
+		);`
+	solutionStatusEventTableCreationSQL = `CREATE TABLE IF NOT EXISTS %s (
+			event_id		INTEGER PRIMARY KEY AUTOINCREMENT,
+			request_id		TEXT,
+			solution_id		TEXT,
+			result_id		TEXT,
+			progress		TEXT,
+			error			TEXT,
+			created_time	TIMESTAMP
+		);`
+	predictionScheduleTableCreationSQL = `CREATE TABLE IF NOT EXISTS %s (
+			schedule_id			TEXT,
+			fitted_solution_id	TEXT,
+			input_path			TEXT,
+			cron				TEXT,
+			output_path			TEXT,
+			format				TEXT,
+			created_time		TIMESTAMP
+		);`
+	predictionScheduleRunTableCreationSQL = `CREATE TABLE IF NOT EXISTS %s (
+			run_id				TEXT,
+			schedule_id			TEXT,
+			input_path			TEXT,
+			status				TEXT,
+			produce_request_id	TEXT,
+			output_path			TEXT,
+			error				TEXT,
+			start_time			TIMESTAMP,
+			end_time			TIMESTAMP
+		);`
+	solutionScoreTableCreationSQL = `CREATE TABLE IF NOT EXISTS %s (
+			solution_id		TEXT,
+			metric			TEXT,
+			score			REAL,
+			score_std_dev	REAL,
+			fold_count		INTEGER
+		);`
+	solutionResultTableCreationSQL = `CREATE TABLE IF NOT EXISTS %s (
+			solution_id			TEXT,
+			fitted_solution_id	TEXT,
+			produce_request_id	TEXT,
+			result_type			TEXT,
+			result_uuid			TEXT,
+			result_uri			TEXT,
+			progress			TEXT,
+			created_time		TIMESTAMP
+		);`
+	solutionResultExplainTableCreationSQL = `CREATE TABLE IF NOT EXISTS %s (
+			result_id		TEXT,
+			explain_uri		TEXT,
+			explain_type	TEXT
+		);`
+	datasetMetadataTableCreationSQL = `CREATE TABLE IF NOT EXISTS %s (
+			dataset_id		TEXT PRIMARY KEY,
+			type			TEXT,
+			deleted			BOOLEAN,
+			document		TEXT,
+			search			TEXT
+		);`
+	datasetLineageTableCreationSQL = `CREATE TABLE IF NOT EXISTS %s (
+			edge_id			TEXT PRIMARY KEY,
+			target			TEXT,
+			document		TEXT,
+			created_time	TIMESTAMP
+		);`
+	exportedModelTableCreationSQL = `CREATE TABLE IF NOT EXISTS %s (
+			fitted_solution_id	TEXT PRIMARY KEY,
+			model_name			TEXT,
+			deleted				BOOLEAN,
+			document			TEXT,
+			search				TEXT
+		);`
+	modelFeatureWeightTableCreationSQL = `CREATE TABLE %s (
+			result_id	TEXT	NOT NULL,
+			%s
+		);`
+
+	baseTableSuffix     = "_base"
+	resultTableSuffix   = "_result"
+	variableTableSuffix = "_variable"
+	explainTableSuffix  = "_explain"
+)
+
+var (
+	nonNullableTypes = map[string]bool{
+		"index":   true,
+		"integer": true,
+		"float":   true,
+		"real":    true,
+	}
+	resultIndices = []string{
+		"result_id",
+	}
+	solutionTables = []struct {
+		name        string
+		creationSQL string
+	}{
+		{postgres.PredictionTableName, predictionTableCreationSQL},
+		{postgres.RequestTableName, requestTableCreationSQL},
+		{postgres.RequestFeatureTableName, requestFeatureTableCreationSQL},
+		{postgres.RequestFilterTableName, requestFilterTableCreationSQL},
+		{postgres.RequestFilterExpressionTableName, requestFilterExpressionTableCreationSQL},
+		{postgres.RequestSplitTableName, requestSplitTableCreationSQL},
+		{postgres.SolutionStatusEventTableName, solutionStatusEventTableCreationSQL},
+		{postgres.PredictionScheduleTableName, predictionScheduleTableCreationSQL},
+		{postgres.PredictionScheduleRunTableName, predictionScheduleRunTableCreationSQL},
+		{postgres.SolutionTableName, solutionTableCreationSQL},
+		{postgres.SolutionFeatureWeightTableName, solutionFeatureWeightTableCreationSQL},
+		{postgres.SolutionStateTableName, solutionStateTableCreationSQL},
+		{postgres.SolutionResultTableName, solutionResultTableCreationSQL},
+		{postgres.SolutionResultExplainOutputTableName, solutionResultExplainTableCreationSQL},
+		{postgres.SolutionScoreTableName, solutionScoreTableCreationSQL},
+	}
+)
+
+// Database is a struct representing a full logical sqlite database.
+type Database struct {
+	Client    *sql.DB
+	Tables    map[string]*postgres.Dataset
+	BatchSize int
+}
+
+// NewDatabase creates a new database instance.
+func NewDatabase(clientCtor ClientCtor, batchSize int) (*Database, error) {
+	client, err := clientCtor()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Database{
+		Client:    client,
+		Tables:    make(map[string]*postgres.Dataset),
+		BatchSize: batchSize,
+	}, nil
+}
+
+// CreateSolutionMetadataTables drops and recreates the solution tables.
+func (d *Database) CreateSolutionMetadataTables() error {
+	log.Infof("Creating solution metadata tables.")
+
+	for _, table := range solutionTables {
+		err := d.DropTable(table.name)
+		if err != nil {
+			return errors.Wrap(err, "failed to drop table")
+		}
+	}
+
+	return CreateSolutionStorageTables(d.Client)
+}
+
+// CreateSolutionStorageTables creates the solution tables if they do not
+// already exist. Unlike postgres, the embedded database is not set up by a
+// separate ingest step so the tables are created on startup.
+func CreateSolutionStorageTables(client *sql.DB) error {
+	for _, table := range solutionTables {
+		_, err := client.Exec(fmt.Sprintf(table.creationSQL, table.name))
+		if err != nil {
+			return errors.Wrapf(err, "failed to create table %s", table.name)
+		}
+	}
+
+	return nil
+}
+
+// CreateMetadataStorageTables creates the tables holding the dataset metadata,
+// lineage and exported models if they do not already exist.
+func CreateMetadataStorageTables(client *sql.DB) error {
+	_, err := client.Exec(fmt.Sprintf(datasetMetadataTableCreationSQL, postgres.DatasetMetadataTableName))
+	if err != nil {
+		return errors.Wrap(err, "failed to create dataset metadata table")
+	}
+
+	_, err = client.Exec(fmt.Sprintf(datasetLineageTableCreationSQL, postgres.DatasetLineageTableName))
+	if err != nil {
+		return errors.Wrap(err, "failed to create dataset lineage table")
+	}
+
+	_, err = client.Exec(fmt.Sprintf(exportedModelTableCreationSQL, postgres.ExportedModelTableName))
+	if err != nil {
+		return errors.Wrap(err, "failed to create exported model table")
+	}
+
+	return nil
+}
+
+// CreateResultTable creates an empty table for the solution results.
+func (d *Database) CreateResultTable(tableName string) error {
+	resultTableName := fmt.Sprintf("%s%s", tableName, resultTableSuffix)
+
+	err := d.DropTable(resultTableName)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Creating result table %s", resultTableName)
+	_, err = d.Client.Exec(fmt.Sprintf(resultTableCreationSQL, resultTableName))
+	if err != nil {
+		return err
+	}
+
+	log.Infof("creating indices on result table %s", resultTableName)
+	for i, index := range resultIndices {
+		indexStatement := fmt.Sprintf("CREATE INDEX idx%d_%s ON %s (%s)", i+1, resultTableName, resultTableName, index)
+		_, err = d.Client.Exec(indexStatement)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// StoreMetadata stores the variable information to the specified table.
+func (d *Database) StoreMetadata(tableName string) error {
+	variableTableName := fmt.Sprintf("%s%s", tableName, variableTableSuffix)
+
+	err := d.DropTable(variableTableName)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Creating variable table %s", variableTableName)
+	_, err = d.Client.Exec(fmt.Sprintf(metadataTableCreationSQL, variableTableName))
+	if err != nil {
+		return err
+	}
+
+	insertStatement := fmt.Sprintf("INSERT INTO %s (name, role, type) VALUES (?1, ?2, ?3);", variableTableName)
+	for _, v := range d.Tables[tableName].Variables {
+		// sqlite has no array type so roles are stored as json
+		role, err := json.Marshal(v.DistilRole)
+		if err != nil {
+			return errors.Wrapf(err, "unable to marshal variable role")
+		}
+		_, err = d.Client.Exec(insertStatement, v.Key, string(role), v.Type)
+		if err != nil {
+			return errors.Wrapf(err, "unable to store variable in sqlite")
+		}
+	}
+
+	return nil
+}
+
+// DeleteDataset deletes all tables & views for a dataset.
+func (d *Database) DeleteDataset(name string) {
+	_ = d.DropView(name)
+	_ = d.DropTable(fmt.Sprintf("%s%s", name, baseTableSuffix))
+	_ = d.DropTable(fmt.Sprintf("%s%s", name, resultTableSuffix))
+	_ = d.DropTable(fmt.Sprintf("%s%s", name, variableTableSuffix))
+	_ = d.DropTable(fmt.Sprintf("%s%s", name, explainTableSuffix))
+}
+
+// IngestRow parses the raw csv data and stores it to the table specified.
+// The previously parsed metadata is used to map columns.
+func (d *Database) IngestRow(tableName string, data []string) error {
+	ds := d.Tables[tableName]
+
+	variables := ds.Variables
+	values := make([]interface{}, len(variables))
+	for i := 0; i < len(variables); i++ {
+		// Default columns that have an empty column.
+		if data[i] == "" && nonNullableTypes[variables[i].Type] {
+			values[i] = nil
+		} else {
+			values[i] = data[i]
+		}
+	}
+	ds.AddInsertFromSource(values)
+
+	if ds.GetInsertSourceLength() >= d.BatchSize {
+		err := d.executeInserts(tableName)
+		if err != nil {
+			return errors.Wrap(err, "unable to insert to table "+tableName)
+		}
+
+		ds.ResetBatch()
+	}
+
+	return nil
+}
+
+// InsertRemainingRows empties all batches and inserts the data to the database.
+func (d *Database) InsertRemainingRows() error {
+	for tableName, ds := range d.Tables {
+		if ds.GetInsertSourceLength() > 0 {
+			err := d.executeInserts(tableName)
+			if err != nil {
+				return errors.Wrap(err, "unable to insert remaining rows for table "+tableName)
+			}
+			ds.ResetBatch()
+		}
+
+		_, err := d.Client.Exec(fmt.Sprintf("ANALYZE \"%s%s\"", tableName, baseTableSuffix))
+		if err != nil {
+			log.Warnf("error updating stats for %s: %+v", tableName, err)
+		}
+	}
+
+	return nil
+}
+
+func (d *Database) executeInserts(tableName string) error {
+	ds := d.Tables[tableName]
+
+	return InsertRows(d.Client, fmt.Sprintf("%s%s", tableName, baseTableSuffix), ds.GetColumns(), ds.GetInsertSource())
+}
+
+// InsertRows inserts the rows to the table within a single transaction, which
+// is by far the fastest way to load data into sqlite.
+func InsertRows(client *sql.DB, tableName string, columns []string, rows [][]interface{}) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	params := make([]string, len(columns))
+	for i := range columns {
+		params[i] = fmt.Sprintf("?%d", i+1)
+	}
+	insertSQL := fmt.Sprintf("INSERT INTO \"%s\" (\"%s\") VALUES (%s);",
+		tableName, strings.Join(columns, "\", \""), strings.Join(params, ", "))
+
+	tx, err := client.Begin()
+	if err != nil {
+		return errors.Wrap(err, "unable to create transaction")
+	}
+	for _, row := range rows {
+		_, err = tx.Exec(insertSQL, row...)
+		if err != nil {
+			_ = tx.Rollback()
+			return errors.Wrapf(err, "unable to insert rows to sqlite")
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "unable to commit inserted rows")
+	}
+
+	return nil
+}
+
+// DropTable drops the specified table from the database.
+func (d *Database) DropTable(tableName string) error {
+	log.Infof("Dropping table %s", tableName)
+	_, err := d.Client.Exec(fmt.Sprintf("DROP TABLE IF EXISTS \"%s\";", tableName))
+
+	return err
+}
+
+// DropView drops the specified view from the database.
+func (d *Database) DropView(viewName string) error {
+	log.Infof("Dropping view %s", viewName)
+	_, err := d.Client.Exec(fmt.Sprintf("DROP VIEW IF EXISTS \"%s\";", viewName))
+
+	return err
+}
+
+// InitializeDataset initializes the dataset with the provided metadata.
+func (d *Database) InitializeDataset(meta *model.Metadata) (*postgres.Dataset, error) {
+	for _, v := range meta.GetMainDataResource().Variables {
+		if v.Type == model.GeoBoundsType || v.OriginalType == model.GeoBoundsType {
+			return nil, errors.Errorf("geobounds variable '%s' is not supported by the sqlite storage", v.Key)
+		}
+	}
+
+	return postgres.NewDataset(meta.ID, meta.Name, meta.Description, meta.GetMainDataResource().Variables, ""), nil
+}
+
+// InitializeTable creates the base table holding the raw text data, the typed
+// view on top of it and the feature weight table.
+func (d *Database) InitializeTable(tableName string, ds *postgres.Dataset) error {
+	d.Tables[tableName] = ds
+
+	varsTable := make([]string, len(ds.Variables))
+	varsView := make([]string, len(ds.Variables))
+	varsExplain := make([]string, len(ds.Variables))
+	for i, variable := range ds.Variables {
+		varsTable[i] = fmt.Sprintf("\"%s\" %s", variable.Key, dataTypeText)
+		varsView[i] = GetViewField(variable)
+		varsExplain[i] = fmt.Sprintf("\"%s\" %s", variable.Key, dataTypeExplain)
+	}
+
+	log.Infof("Creating table %s%s", tableName, baseTableSuffix)
+	_, err := d.Client.Exec(fmt.Sprintf("CREATE TABLE \"%s%s\" (%s);", tableName, baseTableSuffix, strings.Join(varsTable, ",\n")))
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Creating view %s", tableName)
+	_, err = d.Client.Exec(fmt.Sprintf("CREATE VIEW \"%s\" AS SELECT %s FROM \"%s%s\";",
+		tableName, strings.Join(varsView, ",\n"), tableName, baseTableSuffix))
+	if err != nil {
+		return err
+	}
+
+	explainName := fmt.Sprintf("%s%s", tableName, explainTableSuffix)
+	log.Infof("Creating table %s", explainName)
+	_, err = d.Client.Exec(fmt.Sprintf(modelFeatureWeightTableCreationSQL, explainName, strings.Join(varsExplain, ",\n")))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetIndexStatement returns the index SQL statement for a field.
+func GetIndexStatement(tableName string, fieldName string) string {
+	return fmt.Sprintf("CREATE INDEX IF NOT EXISTS \"%s_%s\" ON \"%s\" (\"%s\");", tableName, fieldName, tableName, fieldName)
+}
+
+// CreateIndex will create an index in sqlite on the specified field.
+func (d *Database) CreateIndex(tableName string, fieldName string) error {
+	_, err := d.Client.Exec(GetIndexStatement(tableName, fieldName))
+	if err != nil {
+		return errors.Wrapf(err, "unable to create sqlite index")
+	}
+
+	return nil
+}
+
+// MapD3MTypeToSQLiteType generates a sqlite type from a d3m type.
+func MapD3MTypeToSQLiteType(typ string) string {
+	switch typ {
+	case model.IndexType:
+		return dataTypeInteger
+	case model.IntegerType, model.LongitudeType, model.LatitudeType, model.RealType, model.TimestampType:
+		return dataTypeReal
+	default:
+		return dataTypeText
+	}
+}
+
+// DefaultValueFromD3MType generates the value a missing entry of a d3m type
+// takes in the typed view.
+func DefaultValueFromD3MType(typ string) interface{} {
+	switch typ {
+	case model.IndexType:
+		return 0
+	case model.LongitudeType, model.LatitudeType, model.RealType, model.IntegerType,
+		model.TimestampType, model.DateTimeType:
+		// sqlite stores NaN as null so every missing numeric value is null
+		return "NULL"
+	default:
+		return "''"
+	}
+}
+
+// GetViewField returns a SQL string that does a typed select, defaulting as necessary.
+func GetViewField(variable *model.Variable) string {
+	field := fmt.Sprintf("\"%s\"", variable.Key)
+
+	viewField := ""
+	switch MapD3MTypeToSQLiteType(variable.Type) {
+	case dataTypeInteger:
+		viewField = fmt.Sprintf("CAST(%s AS INTEGER)", field)
+	case dataTypeReal:
+		viewField = fmt.Sprintf("CASE WHEN is_numeric(%s) THEN CAST(%s AS REAL) END", field, field)
+	default:
+		if variable.Type == model.DateTimeType {
+			viewField = fmt.Sprintf("NULLIF(%s, '')", field)
+		} else {
+			viewField = fmt.Sprintf("COALESCE(%s, '')", field)
+		}
+	}
+
+	return fmt.Sprintf("%s AS %s", viewField, field)
+}
+
+// GetValidValueExpression returns a SQL boolean expression that is true when
+// the raw text field holds a value that is valid for the d3m type. Unlike
+// postgres, sqlite never fails a cast so the validity has to be tested.
+func GetValidValueExpression(typ string, field string) string {
+	switch typ {
+	case model.IndexType:
+		return getValidValueExpressionForDBType(dataTypeInteger, field)
+	case model.IntegerType, model.LongitudeType, model.LatitudeType, model.RealType, model.TimestampType:
+		return getValidValueExpressionForDBType(dataTypeFloat, field)
+	case model.DateTimeType:
+		return getValidValueExpressionForDBType(dataTypeDate, field)
+	case model.RealVectorType, model.RealListType:
+		return getValidValueExpressionForDBType(dataTypeVector, field)
+	case model.BoolType:
+		return getValidValueExpressionForDBType(dataTypeBool, field)
+	default:
+		return "1"
+	}
+}
+
+func getValidValueExpressionForDBType(dbType string, field string) string {
+	fieldQuote := fmt.Sprintf("\"%s\"", field)
+	switch dbType {
+	case dataTypeText:
+		return "1"
+	case dataTypeFloat, dataTypeDouble:
+		return fmt.Sprintf("is_numeric(%s)", fieldQuote)
+	case dataTypeInteger:
+		return fmt.Sprintf("(is_numeric(%[1]s) AND CAST(%[1]s AS REAL) = CAST(%[1]s AS INTEGER))", fieldQuote)
+	case dataTypeDate:
+		return fmt.Sprintf("julianday(%s) IS NOT NULL", fieldQuote)
+	case dataTypeBool:
+		return fmt.Sprintf("lower(%s) IN ('true', 'false', 't', 'f', 'yes', 'no', 'y', 'n', 'on', 'off', '1', '0')", fieldQuote)
+	case dataTypeVector:
+		return fmt.Sprintf("json_valid('[' || %s || ']')", fieldQuote)
+	case dataTypeCoord:
+		return fmt.Sprintf("(json_valid('[' || %[1]s || ']') AND json_array_length('[' || %[1]s || ']') = 8)", fieldQuote)
+	default:
+		return "0"
+	}
+}
+
+// IsColumnType can be use to check columns potential types. The column is
+// of the type if it has values and every value is valid for the type.
+func IsColumnType(client *sql.DB, tableName string, variable *model.Variable, colType string) bool {
+	valid := getValidValueExpressionForDBType(colType, variable.Key)
+	if valid == "0" {
+		return false
+	}
+
+	query := fmt.Sprintf("SELECT COUNT(*), COALESCE(SUM(CASE WHEN %s THEN 0 ELSE 1 END), 0) FROM \"%s\" WHERE \"%s\" != '';",
+		valid, tableName, variable.Key)
+
+	var count int
+	var invalid int
+	err := client.QueryRow(query).Scan(&count, &invalid)
+	if err != nil {
+		return false
+	}
+
+	return count > 0 && invalid == 0
+}
@@ -0,0 +1,119 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uncharted-distil/distil-compute/model"
+)
+
+func createTestMetadata() *model.Metadata {
+	meta := model.NewMetadata("flowers", "flowers", "", "flowers")
+	dr := model.NewDataResource("learningData", model.ResTypeTable, nil)
+	dr.Variables = []*model.Variable{
+		model.NewVariable(0, "d3mIndex", "d3mIndex", "d3mIndex", "d3mIndex", model.IndexType, model.IndexType, "", []string{model.RoleIndex}, []string{model.VarDistilRoleIndex}, nil, nil, false),
+		model.NewVariable(1, "species", "species", "species", "species", model.CategoricalType, model.CategoricalType, "", []string{model.RoleAttribute}, []string{model.VarDistilRoleData}, nil, nil, false),
+		model.NewVariable(2, "length", "length", "length", "length", model.RealType, model.RealType, "", []string{model.RoleAttribute}, []string{model.VarDistilRoleData}, nil, nil, false),
+	}
+	meta.DataResources = []*model.DataResource{dr}
+	return meta
+}
+
+func countRows(t *testing.T, client *sql.DB, tableName string) int {
+	var count int
+	err := client.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM \"%s\";", tableName)).Scan(&count)
+	assert.NoError(t, err)
+	return count
+}
+
+func TestIngestDataset(t *testing.T) {
+	db, err := NewDatabase(NewClient(path.Join(t.TempDir(), "distil.db")), 2)
+	assert.NoError(t, err)
+
+	meta := createTestMetadata()
+	ds, err := db.InitializeDataset(meta)
+	assert.NoError(t, err)
+	assert.NoError(t, db.InitializeTable(meta.StorageName, ds))
+	assert.NoError(t, db.StoreMetadata(meta.StorageName))
+	assert.NoError(t, db.CreateResultTable(meta.StorageName))
+
+	rows := [][]string{
+		{"0", "setosa", "1.5"},
+		{"1", "setosa", ""},
+		{"2", "virginica", "5.5"},
+		{"3", "", "abc"},
+		{"4", "virginica", "6"},
+	}
+	for _, row := range rows {
+		assert.NoError(t, db.IngestRow(meta.StorageName, row))
+	}
+
+	// full batches are inserted as they fill up
+	baseTable := fmt.Sprintf("%s%s", meta.StorageName, baseTableSuffix)
+	assert.Equal(t, 4, countRows(t, db.Client, baseTable))
+	assert.NoError(t, db.InsertRemainingRows())
+	assert.Equal(t, 5, countRows(t, db.Client, baseTable))
+
+	// the view types the raw text, with missing and invalid numbers as null
+	res, err := db.Client.Query(fmt.Sprintf("SELECT \"d3mIndex\", species, length FROM \"%s\" ORDER BY \"d3mIndex\";", meta.StorageName))
+	assert.NoError(t, err)
+	species := []string{}
+	lengths := []sql.NullFloat64{}
+	for res.Next() {
+		var index int64
+		var s string
+		var length sql.NullFloat64
+		assert.NoError(t, res.Scan(&index, &s, &length))
+		species = append(species, s)
+		lengths = append(lengths, length)
+	}
+	assert.NoError(t, res.Close())
+	assert.Equal(t, []string{"setosa", "setosa", "virginica", "", "virginica"}, species)
+	assert.Equal(t, []sql.NullFloat64{{Float64: 1.5, Valid: true}, {}, {Float64: 5.5, Valid: true}, {}, {Float64: 6, Valid: true}}, lengths)
+
+	// the variables are stored with their roles
+	var role string
+	err = db.Client.QueryRow(fmt.Sprintf("SELECT role FROM %s%s WHERE name = 'length';", meta.StorageName, variableTableSuffix)).Scan(&role)
+	assert.NoError(t, err)
+	assert.Equal(t, `["data"]`, role)
+
+	assert.NoError(t, db.CreateIndex(baseTable, "species"))
+	assert.True(t, IsColumnType(db.Client, baseTable, meta.GetMainDataResource().Variables[0], dataTypeInteger))
+	assert.False(t, IsColumnType(db.Client, baseTable, meta.GetMainDataResource().Variables[2], dataTypeFloat))
+
+	db.DeleteDataset(meta.StorageName)
+	var tables int
+	err = db.Client.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name LIKE ?1;", meta.StorageName+"%").Scan(&tables)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, tables)
+}
+
+func TestInitializeDatasetGeoBounds(t *testing.T) {
+	db, err := NewDatabase(NewClient(path.Join(t.TempDir(), "distil.db")), 2)
+	assert.NoError(t, err)
+
+	meta := createTestMetadata()
+	dr := meta.GetMainDataResource()
+	dr.Variables = append(dr.Variables, model.NewVariable(3, "bounds", "bounds", "bounds", "bounds", model.GeoBoundsType, model.GeoBoundsType, "", nil, nil, nil, nil, false))
+
+	_, err = db.InitializeDataset(meta)
+	assert.Error(t, err)
+}
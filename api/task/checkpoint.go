@@ -0,0 +1,330 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/unchartedsoftware/plog"
+
+	"github.com/uncharted-distil/distil/api/env"
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/util"
+)
+
+const (
+	// IngestStepMerge merges all data resources into a single file.
+	IngestStepMerge = "merge"
+	// IngestStepClean cleans the merged data.
+	IngestStepClean = "clean"
+	// IngestStepClassify determines the variable types.
+	IngestStepClassify = "classify"
+	// IngestStepRank ranks the variable importance.
+	IngestStepRank = "rank"
+	// IngestStepSummarize summarizes the dataset content.
+	IngestStepSummarize = "summarize"
+	// IngestStepGeocode geocodes place names.
+	IngestStepGeocode = "geocode"
+	// IngestStepSample samples large datasets.
+	IngestStepSample = "sample"
+	// IngestStepIngest stores the metadata and the data.
+	IngestStepIngest = "ingest"
+	// IngestStepDataQuality builds the data quality report.
+	IngestStepDataQuality = "data-quality"
+	// IngestStepSetGroups creates the initial groupings.
+	IngestStepSetGroups = "set-groups"
+	// IngestStepFeaturize featurizes the dataset for downstream efficiencies.
+	IngestStepFeaturize = "featurize"
+	// IngestStepUpdateExtremas updates the variable extremas.
+	IngestStepUpdateExtremas = "update-extremas"
+)
+
+var (
+	ingestPipeline = []*ingestStep{
+		{name: IngestStepMerge, run: ingestMergeStep},
+		{name: IngestStepClean, run: ingestCleanStep},
+		{name: IngestStepClassify, run: ingestClassifyStep},
+		{name: IngestStepRank, run: ingestRankStep},
+		{name: IngestStepSummarize, run: ingestSummarizeStep},
+		{name: IngestStepGeocode, run: ingestGeocodeStep},
+		{name: IngestStepSample, run: ingestSampleStep},
+		{name: IngestStepIngest, run: ingestIngestStep},
+		{name: IngestStepDataQuality, run: ingestDataQualityStep},
+		{name: IngestStepSetGroups, run: ingestSetGroupsStep},
+		{name: IngestStepFeaturize, run: ingestFeaturizeStep},
+		{name: IngestStepUpdateExtremas, run: ingestUpdateExtremasStep},
+	}
+
+	runningIngests   = map[string]*IngestStatus{}
+	ingestStatusLock = &sync.RWMutex{}
+)
+
+type ingestStep struct {
+	name string
+	run  func(state *ingestState) error
+}
+
+// ingestState is the state threaded through the ingest steps.
+type ingestState struct {
+	params             *IngestParams
+	config             *IngestTaskConfig
+	steps              *IngestSteps
+	metaStorage        api.MetadataStorage
+	dataStorage        api.DataStorage
+	originalSchemaFile string
+	latestSchemaOutput string
+	datasetID          string
+	sampled            bool
+	rowCount           int
+}
+
+// IngestCheckpoint records the outcome of a single ingest step.
+type IngestCheckpoint struct {
+	Step       string    `json:"step"`
+	SchemaPath string    `json:"schemaPath"`
+	Started    time.Time `json:"started"`
+	Duration   float64   `json:"duration"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// IngestStatus captures the progress of an ingest, with one checkpoint per
+// executed step. It is persisted after every step so a failed ingest can be
+// resumed.
+type IngestStatus struct {
+	DatasetID         string              `json:"datasetId"`
+	IngestedDatasetID string              `json:"ingestedDatasetId"`
+	CurrentStep       string              `json:"currentStep"`
+	StepCount         int                 `json:"stepCount"`
+	Checkpoints       []*IngestCheckpoint `json:"checkpoints"`
+	Sampled           bool                `json:"sampled"`
+	RowCount          int                 `json:"rowCount"`
+	Running           bool                `json:"running"`
+	Complete          bool                `json:"complete"`
+	Cancelled         bool                `json:"cancelled"`
+	Created           time.Time           `json:"created"`
+	Params            *IngestParams       `json:"params"`
+	Steps             *IngestSteps        `json:"steps"`
+}
+
+func newIngestStatus(params *IngestParams, steps *IngestSteps) *IngestStatus {
+	return &IngestStatus{
+		DatasetID:   params.ID,
+		StepCount:   len(ingestPipeline),
+		Checkpoints: []*IngestCheckpoint{},
+		Params:      params,
+		Steps:       steps,
+		Created:     time.Now(),
+	}
+}
+
+// GetIngestStatus returns the status of the ingest of a dataset, whether it is
+// still running or not.
+func GetIngestStatus(datasetID string) (*IngestStatus, error) {
+	ingestStatusLock.RLock()
+	status, ok := runningIngests[datasetID]
+	if ok {
+		copied := status.clone()
+		ingestStatusLock.RUnlock()
+		return copied, nil
+	}
+	ingestStatusLock.RUnlock()
+
+	return loadIngestStatus(datasetID)
+}
+
+func loadIngestStatus(datasetID string) (*IngestStatus, error) {
+	filename, err := getCheckpointFilename(datasetID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.Errorf("no ingest checkpoint found for dataset '%s'", datasetID)
+		}
+		return nil, errors.Wrapf(err, "unable to read ingest checkpoint")
+	}
+
+	status := &IngestStatus{}
+	err = json.Unmarshal(data, status)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse ingest checkpoint")
+	}
+	status.Running = false
+
+	return status, nil
+}
+
+func isIngestRunning(datasetID string) bool {
+	ingestStatusLock.RLock()
+	defer ingestStatusLock.RUnlock()
+
+	_, ok := runningIngests[datasetID]
+	return ok
+}
+
+func trackIngest(status *IngestStatus) {
+	ingestStatusLock.Lock()
+	defer ingestStatusLock.Unlock()
+
+	status.Running = true
+	runningIngests[status.DatasetID] = status
+}
+
+func untrackIngest(status *IngestStatus) {
+	ingestStatusLock.Lock()
+	defer ingestStatusLock.Unlock()
+
+	status.Running = false
+	status.CurrentStep = ""
+	delete(runningIngests, status.DatasetID)
+}
+
+// getCheckpointFilename returns the checkpoint file of a dataset. The dataset
+// id can come from a request so ids that would resolve outside the checkpoint
+// folder are rejected.
+func getCheckpointFilename(datasetID string) (string, error) {
+	if datasetID == "" || strings.ContainsAny(datasetID, `/\`) {
+		return "", errors.Errorf("invalid dataset id '%s' for an ingest checkpoint", datasetID)
+	}
+
+	return path.Join(env.GetCheckpointPath(), fmt.Sprintf("%s.json", datasetID)), nil
+}
+
+func (s *IngestStatus) clone() *IngestStatus {
+	copied := *s
+	copied.Checkpoints = make([]*IngestCheckpoint, len(s.Checkpoints))
+	for i, c := range s.Checkpoints {
+		checkpoint := *c
+		copied.Checkpoints[i] = &checkpoint
+	}
+
+	return &copied
+}
+
+func (s *IngestStatus) stepCompleted(step string) bool {
+	ingestStatusLock.RLock()
+	defer ingestStatusLock.RUnlock()
+
+	for _, c := range s.Checkpoints {
+		if c.Step == step && c.Error == "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *IngestStatus) lastCompletedStep() string {
+	ingestStatusLock.RLock()
+	defer ingestStatusLock.RUnlock()
+
+	for i := len(s.Checkpoints) - 1; i >= 0; i-- {
+		if s.Checkpoints[i].Error == "" {
+			return s.Checkpoints[i].Step
+		}
+	}
+
+	return ""
+}
+
+// latestSchemaPath returns the schema output by the last successful step,
+// or the supplied default if no step has completed.
+func (s *IngestStatus) latestSchemaPath(defaultPath string) string {
+	ingestStatusLock.RLock()
+	defer ingestStatusLock.RUnlock()
+
+	for i := len(s.Checkpoints) - 1; i >= 0; i-- {
+		if s.Checkpoints[i].Error == "" && s.Checkpoints[i].SchemaPath != "" {
+			return s.Checkpoints[i].SchemaPath
+		}
+	}
+
+	return defaultPath
+}
+
+func (s *IngestStatus) startStep(step string) {
+	ingestStatusLock.Lock()
+	defer ingestStatusLock.Unlock()
+
+	log.Infof("starting ingest step '%s' for dataset '%s'", step, s.DatasetID)
+	s.CurrentStep = step
+}
+
+func (s *IngestStatus) recordStep(step string, schemaPath string, duration time.Duration, stepErr error) {
+	ingestStatusLock.Lock()
+	checkpoint := &IngestCheckpoint{
+		Step:       step,
+		SchemaPath: schemaPath,
+		Started:    time.Now().Add(-duration),
+		Duration:   duration.Seconds(),
+	}
+	if stepErr != nil {
+		checkpoint.Error = stepErr.Error()
+	}
+	s.Checkpoints = append(s.Checkpoints, checkpoint)
+	ingestStatusLock.Unlock()
+
+	s.persist()
+}
+
+func (s *IngestStatus) complete() {
+	ingestStatusLock.Lock()
+	s.Complete = true
+	s.CurrentStep = ""
+	ingestStatusLock.Unlock()
+
+	s.persist()
+}
+
+func (s *IngestStatus) cancel() {
+	ingestStatusLock.Lock()
+	s.Cancelled = true
+	s.CurrentStep = ""
+	ingestStatusLock.Unlock()
+
+	s.persist()
+}
+
+// persist writes the checkpoint to disk. Failing to write a checkpoint only
+// prevents resuming so it does not fail the ingest.
+func (s *IngestStatus) persist() {
+	ingestStatusLock.RLock()
+	data, err := json.MarshalIndent(s, "", "  ")
+	ingestStatusLock.RUnlock()
+	if err != nil {
+		log.Warnf("unable to marshal ingest checkpoint for dataset '%s': %v", s.DatasetID, err)
+		return
+	}
+
+	filename, err := getCheckpointFilename(s.DatasetID)
+	if err != nil {
+		log.Warnf("unable to write ingest checkpoint: %v", err)
+		return
+	}
+	err = util.WriteFileWithDirs(filename, data, os.ModePerm)
+	if err != nil {
+		log.Warnf("unable to write ingest checkpoint for dataset '%s': %v", s.DatasetID, err)
+	}
+}
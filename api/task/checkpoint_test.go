@@ -0,0 +1,143 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uncharted-distil/distil/api/env"
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+func initializeCheckpointTest(t *testing.T, steps []*ingestStep) {
+	cfg, err := env.LoadConfig()
+	assert.NoError(t, err)
+	cfg.D3MOutputDir = "test_data"
+	cfg.D3MInputDir = "test_data"
+	cfg.DatamartImportFolder = "test_data"
+	assert.NoError(t, env.Initialize(&cfg))
+
+	pipeline := ingestPipeline
+	ingestPipeline = steps
+	t.Cleanup(func() {
+		ingestPipeline = pipeline
+		os.RemoveAll(env.GetCheckpointPath())
+	})
+}
+
+func createCheckpointTestParams(datasetID string) *IngestParams {
+	return &IngestParams{
+		ID:       datasetID,
+		Path:     "test_data/test_1",
+		DataCtor: func() (api.DataStorage, error) { return nil, nil },
+		MetaCtor: func() (api.MetadataStorage, error) { return nil, nil },
+	}
+}
+
+func TestResumeIngestDataset(t *testing.T) {
+	runs := map[string]int{}
+	failSecond := true
+	initializeCheckpointTest(t, []*ingestStep{
+		{name: "first", run: func(state *ingestState) error {
+			runs["first"]++
+			state.latestSchemaOutput = "first/datasetDoc.json"
+			state.datasetID = "checkpoint_resume_ingested"
+			return nil
+		}},
+		{name: "second", run: func(state *ingestState) error {
+			runs["second"]++
+			// the schema output by the completed step is restored on resume
+			assert.Equal(t, "first/datasetDoc.json", state.latestSchemaOutput)
+			if failSecond {
+				return errors.New("second step failed")
+			}
+			return nil
+		}},
+		{name: "third", run: func(state *ingestState) error {
+			runs["third"]++
+			return nil
+		}},
+	})
+	config := &IngestTaskConfig{}
+
+	_, err := IngestDataset(createCheckpointTestParams("checkpoint_resume"), config, &IngestSteps{})
+	assert.Error(t, err)
+
+	// the failed step is recorded in the persisted checkpoint
+	status, err := GetIngestStatus("checkpoint_resume")
+	assert.NoError(t, err)
+	assert.False(t, status.Running)
+	assert.False(t, status.Complete)
+	assert.Len(t, status.Checkpoints, 2)
+	assert.Equal(t, "", status.Checkpoints[0].Error)
+	assert.Equal(t, "second step failed", status.Checkpoints[1].Error)
+	assert.Equal(t, "first", status.lastCompletedStep())
+
+	// resuming skips the completed step and reruns the failed one
+	failSecond = false
+	dataCtor := func() (api.DataStorage, error) { return nil, nil }
+	metaCtor := func() (api.MetadataStorage, error) { return nil, nil }
+	result, err := ResumeIngestDataset("checkpoint_resume", dataCtor, metaCtor, config)
+	assert.NoError(t, err)
+	assert.Equal(t, "checkpoint_resume_ingested", result.DatasetID)
+	assert.Equal(t, map[string]int{"first": 1, "second": 2, "third": 1}, runs)
+
+	status, err = GetIngestStatus("checkpoint_resume")
+	assert.NoError(t, err)
+	assert.True(t, status.Complete)
+
+	_, err = ResumeIngestDataset("checkpoint_resume", dataCtor, metaCtor, config)
+	assert.Error(t, err)
+}
+
+func TestResumeCancelledIngest(t *testing.T) {
+	initializeCheckpointTest(t, []*ingestStep{
+		{name: "first", run: func(state *ingestState) error { return nil }},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := IngestDatasetContext(ctx, createCheckpointTestParams("checkpoint_cancel"), &IngestTaskConfig{}, &IngestSteps{})
+	assert.Equal(t, ErrIngestCancelled, err)
+
+	status, err := GetIngestStatus("checkpoint_cancel")
+	assert.NoError(t, err)
+	assert.True(t, status.Cancelled)
+
+	_, err = ResumeIngestDataset("checkpoint_cancel", nil, nil, &IngestTaskConfig{})
+	assert.Error(t, err)
+
+	_, err = GetIngestStatus("checkpoint_unknown")
+	assert.Error(t, err)
+}
+
+func TestCheckpointOutsideFolder(t *testing.T) {
+	initializeCheckpointTest(t, []*ingestStep{})
+
+	_, err := GetIngestStatus("../checkpoint_outside")
+	assert.EqualError(t, err, "invalid dataset id '../checkpoint_outside' for an ingest checkpoint")
+
+	_, err = ResumeIngestDataset("nested/checkpoint_outside", nil, nil, &IngestTaskConfig{})
+	assert.Error(t, err)
+
+	_, err = getCheckpointFilename("")
+	assert.Error(t, err)
+}
@@ -81,7 +81,7 @@ func Classify(schemaPath string, dataset string, config *IngestTaskConfig) (stri
 		return "", errors.Wrap(err, "unable to create Simon pipeline")
 	}
 
-	datasetURI, err := submitPipeline([]string{schemaDoc}, pip, true)
+	datasetURI, err := submitPipeline([]string{schemaDoc}, pip, true, pipelinePriorityBatch, dataset, "classify")
 	if err != nil {
 		return "", errors.Wrap(err, "unable to run Simon pipeline")
 	}
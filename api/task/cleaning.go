@@ -65,7 +65,7 @@ func Clean(schemaFile string, dataset string, params *IngestParams, config *Inge
 	}
 
 	// pipeline execution assumes datasetDoc.json as schema file
-	datasetURI, err := submitPipeline([]string{outputPath.sourceFolder}, pip, true)
+	datasetURI, err := submitPipeline([]string{outputPath.sourceFolder}, pip, true, pipelinePriorityBatch, dataset, "cleaning")
 	if err != nil {
 		return "", errors.Wrap(err, "unable to run format pipeline")
 	}
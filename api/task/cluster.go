@@ -107,7 +107,7 @@ func Cluster(dataset *api.Dataset, variable string, useKMeans bool, clusterCount
 		return false, nil, err
 	}
 
-	datasetURI, err := submitPipeline([]string{datasetInputDir}, step, true)
+	datasetURI, err := submitPipeline([]string{datasetInputDir}, step, true, pipelinePriorityInteractive, dataset.ID, "clustering")
 	if err != nil {
 		return false, nil, err
 	}
@@ -0,0 +1,225 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	log "github.com/unchartedsoftware/plog"
+
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/serialization"
+)
+
+const (
+	// distinct values are not tracked past this count to bound memory usage
+	dataQualityMaxDistinct = 10000
+	// categoricals are flagged when the ratio of distinct values to present values exceeds this ratio
+	highCardinalityRatio = 0.5
+	// categoricals with fewer distinct values than this are never flagged
+	highCardinalityMinDistinct = 50
+)
+
+var (
+	// coordinate values outside these ranges are counted as out of range
+	coordinateRanges = map[string][2]float64{
+		model.LatitudeType:  {-90, 90},
+		model.LongitudeType: {-180, 180},
+	}
+)
+
+// typeValidator reports whether all the values of a variable are valid for a
+// type.
+type typeValidator func(variable *model.Variable, typ string) (bool, error)
+
+type variableQualityTracker struct {
+	report   *api.VariableQualityReport
+	index    int
+	distinct map[string]bool
+	present  int
+}
+
+// DataQuality builds the data quality report of an ingested dataset from the
+// data in the schema file and the types stored in the metadata, writing it next
+// to the dataset metadata where GetDataQualityReportPath expects it. Candidate
+// types are validated by the data storage, as when changing a variable type.
+func DataQuality(schemaFile string, datasetID string, dataStorage api.DataStorage, metaStorage api.MetadataStorage, reportRelativePath string) (*api.DataQualityReport, error) {
+	ds, err := metaStorage.FetchDataset(datasetID, true, true, false)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := serialization.ReadMetadata(schemaFile)
+	if err != nil {
+		return nil, err
+	}
+	dataPath := model.GetResourcePath(schemaFile, meta.GetMainDataResource())
+	data, err := serialization.GetStorage(dataPath).ReadData(dataPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read data for data quality report")
+	}
+
+	// use the same location the report is read from
+	outputPath := api.GetDataQualityReportPath(ds, reportRelativePath)
+	report, err := buildDataQualityReport(datasetID, ds.Variables, data, func(variable *model.Variable, typ string) (bool, error) {
+		return dataStorage.IsValidDataType(ds.ID, ds.StorageName, variable.Key, typ)
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = api.WriteDataQualityReport(outputPath, report)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("wrote data quality report for dataset '%s' to '%s'", datasetID, outputPath)
+
+	return report, nil
+}
+
+func buildDataQualityReport(datasetID string, variables []*model.Variable, data [][]string, isValidType typeValidator) (*api.DataQualityReport, error) {
+	report := &api.DataQualityReport{
+		Dataset:   datasetID,
+		Variables: []*api.VariableQualityReport{},
+		Created:   time.Now(),
+	}
+	if len(data) == 0 {
+		return report, nil
+	}
+	header := data[0]
+	rows := data[1:]
+	report.RowCount = len(rows)
+
+	// map the header to the stored variables, ignoring anything not in the data
+	headerIndices := map[string]int{}
+	for i, h := range header {
+		headerIndices[h] = i
+	}
+	trackers := []*variableQualityTracker{}
+	d3mIndexColumn := -1
+	for _, v := range variables {
+		index, ok := headerIndices[v.HeaderName]
+		if !ok {
+			continue
+		}
+		if v.Key == model.D3MIndexFieldName {
+			d3mIndexColumn = index
+		}
+		tracker, err := newVariableQualityTracker(v, index, isValidType)
+		if err != nil {
+			return nil, err
+		}
+		trackers = append(trackers, tracker)
+	}
+
+	seenIndices := map[string]bool{}
+	for _, row := range rows {
+		if d3mIndexColumn >= 0 && d3mIndexColumn < len(row) {
+			if seenIndices[row[d3mIndexColumn]] {
+				report.DuplicateIndexRows++
+			}
+			seenIndices[row[d3mIndexColumn]] = true
+		}
+
+		for _, t := range trackers {
+			value := ""
+			if t.index < len(row) {
+				value = row[t.index]
+			}
+			t.add(value)
+		}
+	}
+
+	for _, t := range trackers {
+		report.Variables = append(report.Variables, t.finalize(report.RowCount))
+	}
+
+	return report, nil
+}
+
+func newVariableQualityTracker(variable *model.Variable, index int, isValidType typeValidator) (*variableQualityTracker, error) {
+	report := &api.VariableQualityReport{
+		Key:               variable.Key,
+		Type:              variable.Type,
+		TypeValid:         map[string]bool{},
+		TypeProbabilities: map[string]float64{},
+	}
+
+	// candidate types are the current type and every suggested type
+	candidates := []string{variable.Type}
+	for _, st := range variable.SuggestedTypes {
+		candidates = append(candidates, st.Type)
+		if st.Provenance != "" && st.Probability > report.TypeProbabilities[st.Type] {
+			report.TypeProbabilities[st.Type] = st.Probability
+		}
+	}
+	for _, c := range candidates {
+		if _, ok := report.TypeValid[c]; ok {
+			continue
+		}
+		valid, err := isValidType(variable, c)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to validate type '%s' of variable '%s'", c, variable.Key)
+		}
+		report.TypeValid[c] = valid
+	}
+
+	return &variableQualityTracker{
+		report:   report,
+		index:    index,
+		distinct: map[string]bool{},
+	}, nil
+}
+
+func (t *variableQualityTracker) add(value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		t.report.MissingCount++
+		return
+	}
+	t.present++
+
+	if len(t.distinct) < dataQualityMaxDistinct {
+		t.distinct[value] = true
+	} else if !t.distinct[value] {
+		t.report.DistinctCapped = true
+	}
+
+	// values that are not numbers are covered by the type validation
+	if bounds, ok := coordinateRanges[t.report.Type]; ok {
+		f, err := strconv.ParseFloat(value, 64)
+		if err == nil && (f < bounds[0] || f > bounds[1]) {
+			t.report.OutOfRangeCount++
+		}
+	}
+}
+
+func (t *variableQualityTracker) finalize(rowCount int) *api.VariableQualityReport {
+	report := t.report
+	if rowCount > 0 {
+		report.MissingRate = float64(report.MissingCount) / float64(rowCount)
+	}
+	report.DistinctCount = len(t.distinct)
+	report.Constant = report.DistinctCount <= 1 && !report.DistinctCapped
+	report.HighCardinality = model.IsCategorical(report.Type) && t.present > 0 &&
+		report.DistinctCount >= highCardinalityMinDistinct &&
+		float64(report.DistinctCount)/float64(t.present) > highCardinalityRatio
+
+	return report
+}
@@ -0,0 +1,125 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/uncharted-distil/distil-compute/model"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+func newQualityTestVariable(key string, typ string, suggested ...string) *model.Variable {
+	v := model.NewVariable(0, key, key, key, key, typ, typ, "", []string{model.RoleAttribute}, nil, nil, nil, false)
+	for _, st := range suggested {
+		v.SuggestedTypes = append(v.SuggestedTypes, &model.SuggestedType{Type: st, Probability: 0.5, Provenance: "test"})
+	}
+	return v
+}
+
+// validateQualityTestType accepts integers for every variable but the one named
+// mismatch, and any other type.
+func validateQualityTestType(variable *model.Variable, typ string) (bool, error) {
+	return typ != model.IntegerType || variable.Key != "mismatch", nil
+}
+
+func TestBuildDataQualityReport(t *testing.T) {
+	tests := []struct {
+		name     string
+		variable *model.Variable
+		values   []string
+		expected *api.VariableQualityReport
+	}{
+		{
+			name:     "missing values",
+			variable: newQualityTestVariable("missing", model.CategoricalType),
+			values:   []string{"a", "", " ", "a"},
+			expected: &api.VariableQualityReport{MissingCount: 2, MissingRate: 0.5, DistinctCount: 1, Constant: true,
+				TypeValid: map[string]bool{model.CategoricalType: true}},
+		},
+		{
+			name:     "type mismatch",
+			variable: newQualityTestVariable("mismatch", model.StringType, model.IntegerType),
+			values:   []string{"1", "2", "three", "4"},
+			expected: &api.VariableQualityReport{DistinctCount: 4,
+				TypeValid: map[string]bool{model.StringType: true, model.IntegerType: false}},
+		},
+		{
+			name:     "valid suggested type",
+			variable: newQualityTestVariable("match", model.StringType, model.IntegerType),
+			values:   []string{"1", "2", "3", "4"},
+			expected: &api.VariableQualityReport{DistinctCount: 4,
+				TypeValid: map[string]bool{model.StringType: true, model.IntegerType: true}},
+		},
+		{
+			name:     "latitude range",
+			variable: newQualityTestVariable("lat", model.LatitudeType),
+			values:   []string{"-90", "45.5", "90.1", "-120"},
+			expected: &api.VariableQualityReport{DistinctCount: 4, OutOfRangeCount: 2,
+				TypeValid: map[string]bool{model.LatitudeType: true}},
+		},
+		{
+			name:     "longitude range",
+			variable: newQualityTestVariable("lon", model.LongitudeType),
+			values:   []string{"-180", "179.9", "180.5", "west"},
+			expected: &api.VariableQualityReport{DistinctCount: 4, OutOfRangeCount: 1,
+				TypeValid: map[string]bool{model.LongitudeType: true}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := [][]string{{test.variable.HeaderName}}
+			for _, value := range test.values {
+				data = append(data, []string{value})
+			}
+
+			report, err := buildDataQualityReport("quality", []*model.Variable{test.variable}, data, validateQualityTestType)
+			assert.NoError(t, err)
+			assert.Equal(t, len(test.values), report.RowCount)
+			assert.Len(t, report.Variables, 1)
+
+			actual := report.Variables[0]
+			assert.Equal(t, test.variable.Key, actual.Key)
+			assert.Equal(t, test.expected.MissingCount, actual.MissingCount)
+			assert.Equal(t, test.expected.MissingRate, actual.MissingRate)
+			assert.Equal(t, test.expected.DistinctCount, actual.DistinctCount)
+			assert.Equal(t, test.expected.Constant, actual.Constant)
+			assert.Equal(t, test.expected.OutOfRangeCount, actual.OutOfRangeCount)
+			assert.Equal(t, test.expected.TypeValid, actual.TypeValid)
+		})
+	}
+}
+
+func TestBuildDataQualityReportIndex(t *testing.T) {
+	variables := []*model.Variable{
+		newQualityTestVariable(model.D3MIndexFieldName, model.IndexType),
+		newQualityTestVariable("value", model.RealType),
+	}
+	data := [][]string{{model.D3MIndexFieldName, "value"}, {"0", "1.5"}, {"1", "2"}, {"1", "2"}}
+
+	report, err := buildDataQualityReport("quality", variables, data, validateQualityTestType)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.DuplicateIndexRows)
+
+	_, err = buildDataQualityReport("quality", variables, data, func(variable *model.Variable, typ string) (bool, error) {
+		return false, errors.New("storage unavailable")
+	})
+	assert.Error(t, err)
+}
@@ -132,6 +132,43 @@ func ExportDataset(dataset string, metaStorage api.MetadataStorage, dataStorage
 	return exportDiskDataset(dataset, dataset, env.ResolvePath(metadata.Augmented, dataset), metaStorage, dataStorage, false, filterParams)
 }
 
+// headerMappingWriter replaces the variable keys of the streamed header with
+// the variable header names.
+type headerMappingWriter struct {
+	api.DatasetStreamWriter
+	headerNames map[string]string
+}
+
+func (h *headerMappingWriter) WriteHeader(header []string) error {
+	mapped := make([]string, len(header))
+	for i, key := range header {
+		mapped[i] = key
+		if name, ok := h.headerNames[key]; ok {
+			mapped[i] = name
+		}
+	}
+
+	return h.DatasetStreamWriter.WriteHeader(mapped)
+}
+
+// StreamExportDataset streams the filtered dataset to the writer without
+// loading the full dataset in memory.
+func StreamExportDataset(dataset string, metaStorage api.MetadataStorage, dataStorage api.DataStorage,
+	filterParams *api.FilterParams, writer api.DatasetStreamWriter) error {
+	metaDataset, err := metaStorage.FetchDataset(dataset, true, false, false)
+	if err != nil {
+		return err
+	}
+
+	headerNames := map[string]string{}
+	for _, v := range metaDataset.Variables {
+		headerNames[v.Key] = v.HeaderName
+	}
+
+	return dataStorage.StreamDataset(dataset, metaDataset.StorageName, false, false, filterParams,
+		&headerMappingWriter{DatasetStreamWriter: writer, headerNames: headerNames})
+}
+
 // CreateDatasetFromResult creates a new dataset based on a result set & the input
 // to the model
 func CreateDatasetFromResult(newDatasetName string, predictionDataset string, sourceDataset string, features []string,
@@ -33,9 +33,9 @@ import (
 	"github.com/uncharted-distil/distil/api/util/json"
 )
 
-func submitForBatch(pip *description.FullySpecifiedPipeline) func(string) (string, error) {
+func submitForBatch(pip *description.FullySpecifiedPipeline, dataset string) func(string) (string, error) {
 	return func(schemaFile string) (string, error) {
-		return submitPipeline([]string{schemaFile}, pip, true)
+		return submitPipeline([]string{schemaFile}, pip, true, pipelinePriorityBatch, dataset, "featurize")
 	}
 }
 
@@ -79,7 +79,7 @@ func FeaturizeDataset(originalSchemaFile string, schemaFile string, dataset stri
 	}
 
 	// pipeline execution assumes datasetDoc.json as schema file
-	datasetURI, err := batchSubmitDataset(schemaFile, dataset, config.DatasetBatchSize, submitForBatch(pip))
+	datasetURI, err := batchSubmitDataset(schemaFile, dataset, config.DatasetBatchSize, submitForBatch(pip, dataset))
 	if err != nil {
 		return "", "", err
 	}
@@ -180,6 +180,19 @@ func SetGroups(datasetID string, rawGroupings []map[string]interface{}, data api
 		if err != nil {
 			return err
 		}
+
+		// the grouping struct has no room for the sensor so it is recorded on the dataset
+		if sensor, ok := multiBandImageGroupings[0]["sensor"].(string); ok && sensor != "" {
+			ds, err := meta.FetchDataset(datasetID, true, true, true)
+			if err != nil {
+				return err
+			}
+			ds.Sensor = sensor
+			err = meta.UpdateDataset(ds)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	geoBoundsGroupings := getGeoBoundsGrouping(rawGroupings)
@@ -303,5 +316,11 @@ func createGeoboundsField(datasetID string, storageName string, coordinateField
 		return err
 	}
 
+	// index the geometry for the spatial queries
+	err = data.CreateIndices(datasetID, []string{geometryField})
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -0,0 +1,243 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+	log "github.com/unchartedsoftware/plog"
+)
+
+const (
+	// ImportJobPending flags a job that has not started yet.
+	ImportJobPending = "pending"
+	// ImportJobRunning flags a job that is currently running.
+	ImportJobRunning = "running"
+	// ImportJobComplete flags a job that completed successfully.
+	ImportJobComplete = "complete"
+	// ImportJobFailed flags a job that ended in error.
+	ImportJobFailed = "failed"
+	// ImportJobCancelled flags a job that was cancelled before completing.
+	ImportJobCancelled = "cancelled"
+
+	// importJobTTL is how long finished jobs are kept for status requests.
+	importJobTTL = 24 * time.Hour
+)
+
+var (
+	// ErrIngestCancelled is returned when an ingest is cancelled between steps.
+	ErrIngestCancelled = errors.New("ingest cancelled")
+
+	importJobs     = map[string]*ImportJob{}
+	importJobsLock = &sync.RWMutex{}
+)
+
+// ImportJobFunc runs the import for a job, checking the job context for
+// cancellation between the long running phases.
+type ImportJobFunc func(job *ImportJob) (map[string]interface{}, error)
+
+// ImportJob tracks a dataset import running in the background.
+type ImportJob struct {
+	ID        string                 `json:"id"`
+	DatasetID string                 `json:"datasetId"`
+	Status    string                 `json:"status"`
+	Step      string                 `json:"step"`
+	Logs      []string               `json:"logs"`
+	Result    map[string]interface{} `json:"result,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+	Ingest    *IngestStatus          `json:"ingest,omitempty"`
+	Created   time.Time              `json:"created"`
+	Updated   time.Time              `json:"updated"`
+	ctx       context.Context
+	cancel    context.CancelFunc
+}
+
+// StartImportJob creates a new import job and runs it in the background.
+func StartImportJob(datasetID string, run ImportJobFunc) (*ImportJob, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to generate import job id")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &ImportJob{
+		ID:        id.String(),
+		DatasetID: datasetID,
+		Status:    ImportJobPending,
+		Logs:      []string{},
+		Created:   time.Now(),
+		Updated:   time.Now(),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+
+	importJobsLock.Lock()
+	pruneImportJobs(time.Now())
+	importJobs[job.ID] = job
+	importJobsLock.Unlock()
+
+	go job.run(run)
+
+	return job.snapshot(), nil
+}
+
+// GetImportJob returns a snapshot of the import job with the specified id.
+func GetImportJob(jobID string) (*ImportJob, error) {
+	importJobsLock.RLock()
+	job, ok := importJobs[jobID]
+	importJobsLock.RUnlock()
+	if !ok {
+		return nil, errors.Errorf("import job '%s' not found", jobID)
+	}
+
+	return job.snapshot(), nil
+}
+
+// GetImportJobs returns a snapshot of every known import job, most recent first.
+func GetImportJobs() []*ImportJob {
+	importJobsLock.Lock()
+	pruneImportJobs(time.Now())
+	jobs := make([]*ImportJob, 0, len(importJobs))
+	for _, job := range importJobs {
+		jobs = append(jobs, job)
+	}
+	importJobsLock.Unlock()
+
+	snapshots := make([]*ImportJob, len(jobs))
+	for i, job := range jobs {
+		snapshots[i] = job.snapshot()
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Created.After(snapshots[j].Created)
+	})
+
+	return snapshots
+}
+
+// CancelImportJob requests the cancellation of a pending or running import job.
+// The job stops at the next step boundary and cleans up what was created.
+func CancelImportJob(jobID string) (*ImportJob, error) {
+	importJobsLock.RLock()
+	job, ok := importJobs[jobID]
+	importJobsLock.RUnlock()
+	if !ok {
+		return nil, errors.Errorf("import job '%s' not found", jobID)
+	}
+
+	importJobsLock.RLock()
+	done := job.finished()
+	importJobsLock.RUnlock()
+	if done {
+		return nil, errors.Errorf("import job '%s' already finished", jobID)
+	}
+
+	job.Log("cancellation requested")
+	job.cancel()
+
+	return job.snapshot(), nil
+}
+
+// pruneImportJobs drops the finished jobs that have not been updated within
+// the TTL. The caller must hold the write lock.
+func pruneImportJobs(now time.Time) {
+	for id, job := range importJobs {
+		if job.finished() && now.Sub(job.Updated) > importJobTTL {
+			delete(importJobs, id)
+		}
+	}
+}
+
+// Context returns the context used to signal the job cancellation.
+func (j *ImportJob) Context() context.Context {
+	return j.ctx
+}
+
+// Cancelled returns true if the job was asked to stop.
+func (j *ImportJob) Cancelled() bool {
+	return j.ctx.Err() != nil
+}
+
+// SetStep updates the current phase of the job.
+func (j *ImportJob) SetStep(step string) {
+	j.Log(fmt.Sprintf("starting %s", step))
+
+	importJobsLock.Lock()
+	defer importJobsLock.Unlock()
+	j.Step = step
+}
+
+// Log adds a message to the job log.
+func (j *ImportJob) Log(message string) {
+	log.Infof("import job '%s': %s", j.ID, message)
+
+	importJobsLock.Lock()
+	defer importJobsLock.Unlock()
+	j.Logs = append(j.Logs, fmt.Sprintf("%s %s", time.Now().Format(time.RFC3339), message))
+	j.Updated = time.Now()
+}
+
+func (j *ImportJob) run(run ImportJobFunc) {
+	importJobsLock.Lock()
+	j.Status = ImportJobRunning
+	importJobsLock.Unlock()
+
+	result, err := run(j)
+
+	importJobsLock.Lock()
+	defer importJobsLock.Unlock()
+	j.Updated = time.Now()
+	j.Step = ""
+	if err != nil {
+		j.Error = err.Error()
+		if j.ctx.Err() != nil {
+			j.Status = ImportJobCancelled
+		} else {
+			j.Status = ImportJobFailed
+		}
+		log.Errorf("import job '%s' did not complete: %+v", j.ID, err)
+	} else {
+		j.Status = ImportJobComplete
+		j.Result = result
+	}
+	j.cancel()
+}
+
+func (j *ImportJob) finished() bool {
+	return j.Status != ImportJobPending && j.Status != ImportJobRunning
+}
+
+func (j *ImportJob) snapshot() *ImportJob {
+	importJobsLock.RLock()
+	copied := *j
+	copied.Logs = append([]string{}, j.Logs...)
+	importJobsLock.RUnlock()
+
+	// include the per step ingest progress when available
+	if copied.DatasetID != "" {
+		status, err := GetIngestStatus(copied.DatasetID)
+		if err == nil && !status.Created.Before(copied.Created) {
+			copied.Ingest = status
+		}
+	}
+
+	return &copied
+}
@@ -0,0 +1,42 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPruneImportJobs(t *testing.T) {
+	now := time.Now()
+	importJobsLock.Lock()
+	importJobs["stale-complete"] = &ImportJob{ID: "stale-complete", Status: ImportJobComplete, Updated: now.Add(-2 * importJobTTL)}
+	importJobs["stale-running"] = &ImportJob{ID: "stale-running", Status: ImportJobRunning, Updated: now.Add(-2 * importJobTTL)}
+	importJobs["recent-failed"] = &ImportJob{ID: "recent-failed", Status: ImportJobFailed, Updated: now}
+	pruneImportJobs(now)
+	_, staleComplete := importJobs["stale-complete"]
+	_, staleRunning := importJobs["stale-running"]
+	_, recentFailed := importJobs["recent-failed"]
+	delete(importJobs, "stale-running")
+	delete(importJobs, "recent-failed")
+	importJobsLock.Unlock()
+
+	assert.False(t, staleComplete)
+	assert.True(t, staleRunning)
+	assert.True(t, recentFailed)
+}
@@ -38,5 +38,9 @@ func (d *Datamart) PrepareImport() (*task.IngestSteps, *task.IngestParams, error
 
 // CleanupImport removes temporary files and structures created during the import.
 func (d *Datamart) CleanupImport(ingestResult *task.IngestResult) error {
+	if ingestResult.Cancelled {
+		return cleanupCancelledIngest(ingestResult)
+	}
+
 	return nil
 }
@@ -8,3 +8,29 @@ type Importer interface {
 	PrepareImport() (*task.IngestSteps, *task.IngestParams, error)
 	CleanupImport(ingestResult *task.IngestResult) error
 }
+
+// cleanupCancelledIngest removes the metadata and the database tables of a
+// dataset whose ingest was cancelled part way through.
+func cleanupCancelledIngest(ingestResult *task.IngestResult) error {
+	if ingestResult.DatasetID == "" || ingestResult.Params == nil {
+		// nothing was stored yet
+		return nil
+	}
+
+	metaStorage, err := ingestResult.Params.MetaCtor()
+	if err != nil {
+		return err
+	}
+
+	dataStorage, err := ingestResult.Params.DataCtor()
+	if err != nil {
+		return err
+	}
+
+	ds, err := metaStorage.FetchDataset(ingestResult.DatasetID, true, true, true)
+	if err != nil {
+		return err
+	}
+
+	return task.DeleteDataset(ds, metaStorage, dataStorage, false)
+}
@@ -22,6 +22,8 @@ type Joined struct {
 	config                *env.Config
 	joinedDataset         map[string]interface{}
 	originalDataset       map[string]interface{}
+	originalDatasetID     string
+	joinedDatasetID       string
 	leftCols              []string
 	rightCols             []string
 	sourceLearningDataset string
@@ -75,6 +77,15 @@ func (j *Joined) Initialize(params map[string]interface{}, ingestParams *task.In
 	}
 	j.joinedDataset = joinedDataset
 
+	j.originalDatasetID, ok = json.String(originalDataset, "id")
+	if !ok {
+		return errors.Errorf("unable to parse original dataset id")
+	}
+	j.joinedDatasetID, ok = json.String(joinedDataset, "id")
+	if !ok {
+		return errors.Errorf("unable to parse joined dataset id")
+	}
+
 	j.leftCols, ok = json.StringArray(params, "leftCols")
 	if !ok {
 		return errors.Errorf("unable to parse left cols")
@@ -112,15 +123,15 @@ func (j *Joined) PrepareImport() (*task.IngestSteps, *task.IngestParams, error)
 	} else if originalLearningDataset != "" {
 		ingestSteps.SkipFeaturization = true
 		j.sourceLearningDataset = originalLearningDataset
-		j.updateDatasetID = strings.Join([]string{j.originalDataset["id"].(string), j.joinedDataset["id"].(string)}, "-")
+		j.updateDatasetID = strings.Join([]string{j.originalDatasetID, j.joinedDatasetID}, "-")
 	} else if joinedLearningDataset != "" {
 		ingestSteps.SkipFeaturization = true
 		j.sourceLearningDataset = joinedLearningDataset
-		j.updateDatasetID = strings.Join([]string{j.originalDataset["id"].(string), j.joinedDataset["id"].(string)}, "-")
+		j.updateDatasetID = strings.Join([]string{j.originalDatasetID, j.joinedDatasetID}, "-")
 	}
 
 	log.Infof("creating joined dataset '%s' from '%s'", j.datasetID, j.sourcePath)
-	creationResult, err := createDataset(j.sourcePath, j.datasetID, j.config)
+	creationResult, err := createDataset(j.sourcePath, j.datasetID, nil, j.config)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -164,8 +175,8 @@ func (j *Joined) PrepareImport() (*task.IngestSteps, *task.IngestParams, error)
 
 	// set the definitive types based on the currently stored metadata
 	definitiveVars := append(
-		getVariablesDefault(j.originalDataset["id"].(string), j.meta),
-		getVariablesDefault(j.joinedDataset["id"].(string), j.meta)...,
+		getVariablesDefault(j.originalDatasetID, j.meta),
+		getVariablesDefault(j.joinedDatasetID, j.meta)...,
 	)
 	ingestParams.DefinitiveTypes = api.MapVariables(definitiveVars, func(variable *model.Variable) string { return variable.Key })
 
@@ -175,12 +186,27 @@ func (j *Joined) PrepareImport() (*task.IngestSteps, *task.IngestParams, error)
 
 // CleanupImport removes temporary files and structures created during the import.
 func (j *Joined) CleanupImport(ingestResult *task.IngestResult) error {
+	if ingestResult.Cancelled {
+		util.Delete(j.sourcePath)
+		return cleanupCancelledIngest(ingestResult)
+	}
+
 	// if there is a source learning dataset, then sync it properly with the newly imported dataset
 	err := syncPrefeaturizedDataset(ingestResult.DatasetID, j.updateDatasetID, j.sourceLearningDataset, j.meta, j.joinType == "Inner")
 	if err != nil {
 		return err
 	}
 
+	err = task.RecordLineage(j.meta, api.LineageOperationJoin, ingestResult.DatasetID,
+		[]string{j.originalDatasetID, j.joinedDatasetID}, map[string]interface{}{
+			"joinType":  j.joinType,
+			"leftCols":  j.leftCols,
+			"rightCols": j.rightCols,
+		}, nil)
+	if err != nil {
+		return err
+	}
+
 	util.Delete(j.sourcePath)
 
 	return nil
@@ -0,0 +1,32 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uncharted-distil/distil/api/task"
+)
+
+func TestJoinedInitializeDatasetIDs(t *testing.T) {
+	params := map[string]interface{}{
+		"path":            "joined",
+		"originalDataset": map[string]interface{}{"id": "left"},
+		"joinedDataset":   map[string]interface{}{"id": "right"},
+		"leftCols":        []interface{}{"a"},
+		"rightCols":       []interface{}{"b"},
+	}
+	joined := &Joined{}
+	err := joined.Initialize(params, &task.IngestParams{ID: "left"})
+	assert.NoError(t, err)
+	assert.Equal(t, "left", joined.originalDatasetID)
+	assert.Equal(t, "right", joined.joinedDatasetID)
+
+	params["joinedDataset"] = map[string]interface{}{"id": 3}
+	err = joined.Initialize(params, &task.IngestParams{ID: "left"})
+	assert.Error(t, err)
+
+	union := &Union{}
+	err = union.Initialize(params, &task.IngestParams{ID: "left"})
+	assert.Error(t, err)
+}
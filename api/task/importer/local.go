@@ -20,6 +20,7 @@ import (
 type Local struct {
 	sourcePath string
 	datasetID  string
+	options    map[string]interface{}
 	config     *env.Config
 }
 
@@ -42,6 +43,7 @@ func (l *Local) Initialize(params map[string]interface{}, ingestParams *task.Ing
 
 	l.sourcePath = params["path"].(string)
 	l.datasetID = ingestParams.ID
+	l.options = params
 
 	return nil
 }
@@ -57,7 +59,7 @@ func (l *Local) PrepareImport() (*task.IngestSteps, *task.IngestParams, error) {
 	}
 
 	log.Infof("Creating dataset '%s' from '%s'", l.datasetID, l.sourcePath)
-	creationResult, err := createDataset(l.sourcePath, l.datasetID, l.config)
+	creationResult, err := createDataset(l.sourcePath, l.datasetID, l.options, l.config)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -77,6 +79,13 @@ func (l *Local) PrepareImport() (*task.IngestSteps, *task.IngestParams, error) {
 
 // CleanupImport removes temporary files and structures created during the import.
 func (l *Local) CleanupImport(ingestResult *task.IngestResult) error {
+	if ingestResult.Cancelled {
+		err := cleanupCancelledIngest(ingestResult)
+		if err != nil {
+			return err
+		}
+	}
+
 	if !util.IsInDirectory(env.GetPublicPath(), l.sourcePath) {
 		util.Delete(l.sourcePath)
 	}
@@ -84,16 +93,6 @@ func (l *Local) CleanupImport(ingestResult *task.IngestResult) error {
 	return nil
 }
 
-func rawDatasetIsTabular(datasetPath string) bool {
-	// check if datasetPath is a folder
-	if !util.FileExists(datasetPath) || util.IsDirectory(datasetPath) {
-		return false
-	}
-
-	// check if it is a csv file
-	return path.Ext(datasetPath) == ".csv"
-}
-
 func createTableDataset(datasetPath string, datasetName string) (task.DatasetConstructor, error) {
 	data, err := ioutil.ReadFile(datasetPath)
 	if err != nil {
@@ -108,7 +107,7 @@ func createTableDataset(datasetPath string, datasetName string) (task.DatasetCon
 	return ds, nil
 }
 
-func createDataset(datasetPath string, datasetName string, config *env.Config) (*creationResult, error) {
+func createDataset(datasetPath string, datasetName string, options map[string]interface{}, config *env.Config) (*creationResult, error) {
 	if util.IsArchiveFile(datasetPath) {
 		// expand the archive
 		expandedInfo, err := dataset.ExpandZipDataset(datasetPath, datasetName)
@@ -120,36 +119,16 @@ func createDataset(datasetPath string, datasetName string, config *env.Config) (
 	}
 
 	// create the dataset constructors for downstream processing
-	var ds task.DatasetConstructor
-	var err error
-	var groups []map[string]interface{}
-	var indexFields []string
-	if util.IsDatasetDir(datasetPath) {
-		ds, err = dataset.NewD3MDataset(datasetName, datasetPath)
-	} else if rawDatasetIsTabular(datasetPath) {
-		ds, err = createTableDataset(datasetPath, datasetName)
-	} else {
-		// check to see what type of files it contains
-		var fileType string
-		fileType, err = dataset.CheckFileType(datasetPath)
-		if err != nil {
-			return nil, err
-		}
-		if fileType == "png" || fileType == "jpeg" || fileType == "jpg" {
-			ds, err = dataset.NewMediaDatasetFromExpanded(datasetName, fileType, "jpeg", "", datasetPath)
-		} else if fileType == "tif" {
-			ds, err = dataset.NewSatelliteDatasetFromExpanded(datasetName, fileType, "", datasetPath)
-			groups = []map[string]interface{}{dataset.CreateSatelliteGrouping(), dataset.CreateGeoBoundsGrouping()}
-			indexFields = dataset.GetSatelliteIndexFields()
-		} else if fileType == "txt" {
-			ds, err = dataset.NewMediaDatasetFromExpanded(datasetName, "txt", "txt", "", datasetPath)
-		} else {
-			err = errors.Errorf("unsupported archived file type %s", fileType)
-		}
+	contentType, err := DetectContentType(datasetPath)
+	if err != nil {
+		return nil, err
 	}
+	log.Infof("creating dataset constructor for content type '%s'", contentType)
+	rawCtor, err := GetConstructor(contentType, datasetName, datasetPath, options)
 	if err != nil {
 		return nil, err
 	}
+	ds := rawCtor.Constructor
 
 	// create the formatted d3m dataset
 	outputPath := path.Join(config.D3MOutputDir, config.AugmentedSubFolder)
@@ -162,8 +141,8 @@ func createDataset(datasetPath string, datasetName string, config *env.Config) (
 	return &creationResult{
 		name:           datasetName,
 		path:           formattedPath,
-		groups:         groups,
-		indexFields:    indexFields,
+		groups:         rawCtor.Groups,
+		indexFields:    rawCtor.IndexFields,
 		definitiveVars: ds.GetDefinitiveTypes(),
 	}, nil
 }
@@ -0,0 +1,273 @@
+package importer
+
+import (
+	"path"
+	"strings"
+
+	"github.com/h2non/filetype"
+	"github.com/pkg/errors"
+
+	"github.com/uncharted-distil/distil/api/dataset"
+	"github.com/uncharted-distil/distil/api/task"
+	"github.com/uncharted-distil/distil/api/util"
+	"github.com/uncharted-distil/distil/api/util/json"
+)
+
+const (
+	// ContentTypeD3M is a folder holding a D3M formatted dataset.
+	ContentTypeD3M = "d3m"
+	// ContentTypeCSV is a raw csv file.
+	ContentTypeCSV = "csv"
+	// ContentTypeExcel is an excel workbook.
+	ContentTypeExcel = "xlsx"
+	// ContentTypeJSONLines is a file with one json object per line.
+	ContentTypeJSONLines = "jsonl"
+	// ContentTypeParquet is a raw parquet file.
+	ContentTypeParquet = "parquet"
+	// ContentTypePNG is a folder of png images.
+	ContentTypePNG = "png"
+	// ContentTypeJPEG is a folder of jpeg images.
+	ContentTypeJPEG = "jpeg"
+	// ContentTypeJPG is a folder of jpeg images using the short extension.
+	ContentTypeJPG = "jpg"
+	// ContentTypeTIF is a folder of geotiff satellite images.
+	ContentTypeTIF = "tif"
+	// ContentTypeTXT is a folder of text documents.
+	ContentTypeTXT = "txt"
+	// ContentTypeGeoJSON is a GeoJSON feature collection.
+	ContentTypeGeoJSON = "geojson"
+	// ContentTypeShapefile is a folder holding a shapefile and its sidecar files.
+	ContentTypeShapefile = "shp"
+	// ContentTypeGeoPackage is a GeoPackage database.
+	ContentTypeGeoPackage = "gpkg"
+)
+
+// ConstructorFactory creates a dataset constructor for the raw data found at
+// the supplied path. Options are the parameters supplied with the import.
+type ConstructorFactory func(datasetName string, datasetPath string, options map[string]interface{}) (*RawDatasetConstructor, error)
+
+// RawDatasetConstructor pairs a dataset constructor with the groupings and
+// index fields to apply when ingesting the resulting dataset.
+type RawDatasetConstructor struct {
+	Constructor task.DatasetConstructor
+	Groups      []map[string]interface{}
+	IndexFields []string
+}
+
+var (
+	constructorRegistry = map[string]ConstructorFactory{}
+	extensionRegistry   = map[string]string{}
+
+	// stringOptions are the import options that must be strings when supplied.
+	stringOptions = []string{"sheet", "sensor", "layer"}
+)
+
+func init() {
+	RegisterConstructor(ContentTypeD3M, createD3MConstructor)
+	RegisterConstructor(ContentTypeCSV, createTableConstructor, ".csv")
+	RegisterConstructor(ContentTypeExcel, createExcelConstructor, ".xlsx")
+	RegisterConstructor(ContentTypeJSONLines, createJSONLinesConstructor, ".jsonl", ".ndjson")
+	RegisterConstructor(ContentTypeParquet, createParquetConstructor, ".parquet")
+	RegisterConstructor(ContentTypePNG, createImageConstructor)
+	RegisterConstructor(ContentTypeJPEG, createImageConstructor)
+	RegisterConstructor(ContentTypeJPG, createImageConstructor)
+	RegisterConstructor(ContentTypeTIF, createSatelliteConstructor)
+	RegisterConstructor(ContentTypeTXT, createTextConstructor)
+	RegisterConstructor(ContentTypeGeoJSON, createVectorConstructor, ".geojson")
+	RegisterConstructor(ContentTypeShapefile, createVectorConstructor, ".shp")
+	RegisterConstructor(ContentTypeGeoPackage, createVectorConstructor, ".gpkg")
+}
+
+// RegisterConstructor registers the factory to use for raw data of the given
+// content type. Extensions identify single raw files of that content type.
+// Registration is expected to happen during package initialization.
+func RegisterConstructor(contentType string, factory ConstructorFactory, extensions ...string) {
+	constructorRegistry[contentType] = factory
+	for _, ext := range extensions {
+		extensionRegistry[strings.ToLower(ext)] = contentType
+	}
+}
+
+// GetConstructor returns the dataset constructor registered for the content type.
+func GetConstructor(contentType string, datasetName string, datasetPath string, options map[string]interface{}) (*RawDatasetConstructor, error) {
+	factory, ok := constructorRegistry[contentType]
+	if !ok {
+		return nil, errors.Errorf("unsupported archived file type %s", contentType)
+	}
+
+	return factory(datasetName, datasetPath, options)
+}
+
+// ValidateOptions checks that the supplied import options have the expected
+// types, allowing malformed requests to be rejected before the import starts.
+func ValidateOptions(options map[string]interface{}) error {
+	for _, key := range stringOptions {
+		_, err := getStringOption(options, key)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func getStringOption(options map[string]interface{}, key string) (string, error) {
+	if options == nil || options[key] == nil {
+		return "", nil
+	}
+
+	value, ok := options[key].(string)
+	if !ok {
+		return "", errors.Errorf("import option `%s` must be a string", key)
+	}
+
+	return value, nil
+}
+
+// DetectContentType determines the content type of the raw data found at the
+// supplied path. Single files are identified by extension, falling back to
+// their content, while folders are identified by the first file found.
+func DetectContentType(datasetPath string) (string, error) {
+	if util.IsDatasetDir(datasetPath) {
+		return ContentTypeD3M, nil
+	}
+
+	if util.IsDirectory(datasetPath) {
+		return dataset.CheckFileType(datasetPath)
+	}
+
+	if contentType, ok := extensionRegistry[strings.ToLower(path.Ext(datasetPath))]; ok {
+		return contentType, nil
+	}
+
+	kind, err := filetype.MatchFile(datasetPath)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to determine type of raw file")
+	}
+
+	return kind.Extension, nil
+}
+
+// RawFileExtension returns the extension to use when storing an uploaded raw
+// file, defaulting to csv when the extension is not registered.
+func RawFileExtension(filename string) string {
+	ext := strings.ToLower(path.Ext(filename))
+	if _, ok := extensionRegistry[ext]; ok {
+		return ext
+	}
+
+	return ".csv"
+}
+
+func createD3MConstructor(datasetName string, datasetPath string, options map[string]interface{}) (*RawDatasetConstructor, error) {
+	ds, err := dataset.NewD3MDataset(datasetName, datasetPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RawDatasetConstructor{Constructor: ds}, nil
+}
+
+func createTableConstructor(datasetName string, datasetPath string, options map[string]interface{}) (*RawDatasetConstructor, error) {
+	ds, err := createTableDataset(datasetPath, datasetName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RawDatasetConstructor{Constructor: ds}, nil
+}
+
+func createExcelConstructor(datasetName string, datasetPath string, options map[string]interface{}) (*RawDatasetConstructor, error) {
+	sheet, err := getStringOption(options, "sheet")
+	if err != nil {
+		return nil, err
+	}
+
+	ds, err := dataset.NewExcelDataset(datasetName, datasetPath, sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RawDatasetConstructor{Constructor: ds}, nil
+}
+
+func createJSONLinesConstructor(datasetName string, datasetPath string, options map[string]interface{}) (*RawDatasetConstructor, error) {
+	ds, err := dataset.NewJSONLinesDataset(datasetName, datasetPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RawDatasetConstructor{Constructor: ds}, nil
+}
+
+func createParquetConstructor(datasetName string, datasetPath string, options map[string]interface{}) (*RawDatasetConstructor, error) {
+	ds, err := dataset.NewParquetDataset(datasetName, datasetPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RawDatasetConstructor{Constructor: ds}, nil
+}
+
+func createImageConstructor(datasetName string, datasetPath string, options map[string]interface{}) (*RawDatasetConstructor, error) {
+	fileType, err := dataset.CheckFileType(datasetPath)
+	if err != nil {
+		return nil, err
+	}
+
+	ds, err := dataset.NewMediaDatasetFromExpanded(datasetName, fileType, "jpeg", "", datasetPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RawDatasetConstructor{Constructor: ds}, nil
+}
+
+func createSatelliteConstructor(datasetName string, datasetPath string, options map[string]interface{}) (*RawDatasetConstructor, error) {
+	sensor, err := getStringOption(options, "sensor")
+	if err != nil {
+		return nil, err
+	}
+
+	ds, err := dataset.NewSatelliteDatasetFromExpanded(datasetName, ContentTypeTIF, sensor, "", datasetPath)
+	if err != nil {
+		return nil, err
+	}
+	if options != nil {
+		// images more obscured than the thresholds are dropped during ingest
+		ds.MaxCloudFraction = json.FloatDefault(options, ds.MaxCloudFraction, "maxCloudFraction")
+		ds.MaxNodataFraction = json.FloatDefault(options, ds.MaxNodataFraction, "maxNodataFraction")
+	}
+
+	return &RawDatasetConstructor{
+		Constructor: ds,
+		Groups:      []map[string]interface{}{dataset.CreateSatelliteGrouping(ds.Sensor), dataset.CreateGeoBoundsGrouping()},
+		IndexFields: dataset.GetSatelliteIndexFields(),
+	}, nil
+}
+
+func createTextConstructor(datasetName string, datasetPath string, options map[string]interface{}) (*RawDatasetConstructor, error) {
+	ds, err := dataset.NewMediaDatasetFromExpanded(datasetName, ContentTypeTXT, ContentTypeTXT, "", datasetPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RawDatasetConstructor{Constructor: ds}, nil
+}
+
+func createVectorConstructor(datasetName string, datasetPath string, options map[string]interface{}) (*RawDatasetConstructor, error) {
+	layer, err := getStringOption(options, "layer")
+	if err != nil {
+		return nil, err
+	}
+
+	ds, err := dataset.NewVectorDataset(datasetName, datasetPath, layer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RawDatasetConstructor{
+		Constructor: ds,
+		Groups:      []map[string]interface{}{dataset.CreateGeoBoundsGrouping()},
+	}, nil
+}
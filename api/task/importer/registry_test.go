@@ -0,0 +1,24 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateOptions(t *testing.T) {
+	assert.NoError(t, ValidateOptions(nil))
+	assert.NoError(t, ValidateOptions(map[string]interface{}{"sheet": "data", "description": "test"}))
+	assert.Error(t, ValidateOptions(map[string]interface{}{"sheet": 2.0}))
+
+	_, err := GetConstructor(ContentTypeExcel, "test", "test.xlsx", map[string]interface{}{"sheet": true})
+	assert.Error(t, err)
+
+	assert.Error(t, ValidateOptions(map[string]interface{}{"sensor": []interface{}{"sentinel-2"}}))
+	_, err = GetConstructor(ContentTypeTIF, "test", "test", map[string]interface{}{"sensor": 2.0})
+	assert.Error(t, err)
+
+	assert.Error(t, ValidateOptions(map[string]interface{}{"layer": map[string]interface{}{}}))
+	_, err = GetConstructor(ContentTypeGeoJSON, "test", "test.geojson", map[string]interface{}{"layer": 1.0})
+	assert.Error(t, err)
+}
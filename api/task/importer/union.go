@@ -14,6 +14,7 @@ import (
 	api "github.com/uncharted-distil/distil/api/model"
 	"github.com/uncharted-distil/distil/api/task"
 	"github.com/uncharted-distil/distil/api/util"
+	"github.com/uncharted-distil/distil/api/util/json"
 )
 
 // Union can be used to import datasets that have been combined using the union operation.
@@ -23,6 +24,8 @@ type Union struct {
 	config                *env.Config
 	joinedDataset         map[string]interface{}
 	originalDataset       map[string]interface{}
+	originalDatasetID     string
+	joinedDatasetID       string
 	sourceLearningDataset string
 	updateDatasetID       string
 	meta                  api.MetadataStorage
@@ -67,6 +70,15 @@ func (u *Union) Initialize(params map[string]interface{}, ingestParams *task.Ing
 	}
 	u.joinedDataset = joinedDataset
 
+	u.originalDatasetID, ok = json.String(originalDataset, "id")
+	if !ok {
+		return errors.Errorf("unable to parse original dataset id")
+	}
+	u.joinedDatasetID, ok = json.String(joinedDataset, "id")
+	if !ok {
+		return errors.Errorf("unable to parse joined dataset id")
+	}
+
 	u.datasetID = ingestParams.ID
 
 	return nil
@@ -89,7 +101,7 @@ func (u *Union) PrepareImport() (*task.IngestSteps, *task.IngestParams, error) {
 	if originalLearningDataset != "" && joinedLearningDataset != "" {
 		ingestSteps.SkipFeaturization = true
 		u.sourceLearningDataset = fmt.Sprintf("%s-union-%s", path.Base(originalLearningDataset), path.Base(joinedLearningDataset))
-		u.updateDatasetID = strings.Join([]string{u.originalDataset["id"].(string), u.joinedDataset["id"].(string)}, "-")
+		u.updateDatasetID = strings.Join([]string{u.originalDatasetID, u.joinedDatasetID}, "-")
 	} else if originalLearningDataset != "" {
 		return nil, nil, errors.Errorf("both the original and joining datasets need to be prefeaturized")
 	} else if joinedLearningDataset != "" {
@@ -97,7 +109,7 @@ func (u *Union) PrepareImport() (*task.IngestSteps, *task.IngestParams, error) {
 	}
 
 	log.Infof("creating union dataset '%s' from '%s'", u.datasetID, u.sourcePath)
-	creationResult, err := createDataset(u.sourcePath, u.datasetID, u.config)
+	creationResult, err := createDataset(u.sourcePath, u.datasetID, nil, u.config)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -133,8 +145,8 @@ func (u *Union) PrepareImport() (*task.IngestSteps, *task.IngestParams, error) {
 
 	// set the definitive types based on the currently stored metadata
 	definitiveVars := append(
-		getVariablesDefault(u.originalDataset["id"].(string), u.meta),
-		getVariablesDefault(u.joinedDataset["id"].(string), u.meta)...,
+		getVariablesDefault(u.originalDatasetID, u.meta),
+		getVariablesDefault(u.joinedDatasetID, u.meta)...,
 	)
 	ingestParams.DefinitiveTypes = api.MapVariables(definitiveVars, func(variable *model.Variable) string { return variable.Key })
 
@@ -144,6 +156,11 @@ func (u *Union) PrepareImport() (*task.IngestSteps, *task.IngestParams, error) {
 
 // CleanupImport removes temporary files and structures created during the import.
 func (u *Union) CleanupImport(ingestResult *task.IngestResult) error {
+	if ingestResult.Cancelled {
+		util.Delete(u.sourcePath)
+		return cleanupCancelledIngest(ingestResult)
+	}
+
 	// update dataset to set learning data
 	ds, err := u.meta.FetchDataset(u.datasetID, true, true, true)
 	if err != nil {
@@ -155,6 +172,12 @@ func (u *Union) CleanupImport(ingestResult *task.IngestResult) error {
 		return err
 	}
 
+	err = task.RecordLineage(u.meta, api.LineageOperationUnion, ingestResult.DatasetID,
+		[]string{u.originalDatasetID, u.joinedDatasetID}, nil, nil)
+	if err != nil {
+		return err
+	}
+
 	util.Delete(u.sourcePath)
 	return nil
 }
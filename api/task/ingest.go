@@ -16,6 +16,7 @@
 package task
 
 import (
+	"context"
 	"fmt"
 	"path"
 	"time"
@@ -31,6 +32,7 @@ import (
 	api "github.com/uncharted-distil/distil/api/model"
 	"github.com/uncharted-distil/distil/api/postgres"
 	"github.com/uncharted-distil/distil/api/serialization"
+	"github.com/uncharted-distil/distil/api/sqlite"
 )
 
 const (
@@ -54,6 +56,8 @@ type IngestTaskConfig struct {
 	DatabasePort                     int
 	DatabaseBatchSize                int
 	DatabaseLogLevel                 string
+	DataStorage                      string
+	SQLitePath                       string
 	ImputeEnabled                    bool
 	SummaryOutputPathRelative        string
 	SummaryMachineOutputPathRelative string
@@ -62,16 +66,17 @@ type IngestTaskConfig struct {
 	HardFail                         bool
 	IngestOverwrite                  bool
 	SampleRowLimit                   int
+	DataQualityOutputPathRelative    string
 }
 
 // IngestSteps is a collection of parameters that specify ingest behaviour.
 type IngestSteps struct {
-	ClassificationOverwrite bool
-	VerifyMetadata          bool
-	FallbackMerged          bool
-	CreateMetadataTables    bool
-	CheckMatch              bool
-	SkipFeaturization       bool
+	ClassificationOverwrite bool `json:"classificationOverwrite"`
+	VerifyMetadata          bool `json:"verifyMetadata"`
+	FallbackMerged          bool `json:"fallbackMerged"`
+	CreateMetadataTables    bool `json:"createMetadataTables"`
+	CheckMatch              bool `json:"checkMatch"`
+	SkipFeaturization       bool `json:"skipFeaturization"`
 }
 
 // NewDefaultClient creates a new client to use when submitting pipelines.
@@ -104,6 +109,8 @@ func NewConfig(config env.Config) *IngestTaskConfig {
 		DatabasePort:                     config.PostgresPort,
 		DatabaseBatchSize:                config.PostgresBatchSize,
 		DatabaseLogLevel:                 config.PostgresLogLevel,
+		DataStorage:                      config.DataStorage,
+		SQLitePath:                       config.SQLitePath,
 		SummaryOutputPathRelative:        config.SummaryPath,
 		SummaryMachineOutputPathRelative: config.SummaryMachinePath,
 		SummaryEnabled:                   config.SummaryEnabled,
@@ -112,6 +119,7 @@ func NewConfig(config env.Config) *IngestTaskConfig {
 		IngestOverwrite:                  config.IngestOverwrite,
 		SampleRowLimit:                   config.IngestSampleRowLimit,
 		ImputeEnabled:                    config.ImputeEnabled,
+		DataQualityOutputPathRelative:    config.DataQualityOutputPath,
 	}
 }
 
@@ -120,20 +128,22 @@ type IngestResult struct {
 	DatasetID string
 	Sampled   bool
 	RowCount  int
+	Cancelled bool
+	Params    *IngestParams
 }
 
 // IngestParams contains the parameters needed to ingest a dataset
 type IngestParams struct {
-	Source          metadata.DatasetSource
-	DataCtor        api.DataStorageCtor
-	MetaCtor        api.MetadataStorageCtor
-	ID              string
-	Origins         []*model.DatasetOrigin
-	Type            api.DatasetType
-	Path            string
-	RawGroupings    []map[string]interface{}
-	IndexFields     []string
-	DefinitiveTypes map[string]*model.Variable
+	Source          metadata.DatasetSource     `json:"source"`
+	DataCtor        api.DataStorageCtor        `json:"-"`
+	MetaCtor        api.MetadataStorageCtor    `json:"-"`
+	ID              string                     `json:"id"`
+	Origins         []*model.DatasetOrigin     `json:"origins"`
+	Type            api.DatasetType            `json:"type"`
+	Path            string                     `json:"path"`
+	RawGroupings    []map[string]interface{}   `json:"rawGroupings"`
+	IndexFields     []string                   `json:"indexFields"`
+	DefinitiveTypes map[string]*model.Variable `json:"definitiveTypes"`
 }
 
 // GetSchemaDocPath returns the schema path to use when ingesting.
@@ -146,7 +156,44 @@ func (i *IngestParams) GetSchemaDocPath() string {
 }
 
 // IngestDataset executes the complete ingest process for the specified dataset.
+// Every step writes a checkpoint so that a failed ingest can be resumed.
 func IngestDataset(params *IngestParams, config *IngestTaskConfig, steps *IngestSteps) (*IngestResult, error) {
+	return IngestDatasetContext(context.Background(), params, config, steps)
+}
+
+// IngestDatasetContext executes the complete ingest process for the specified
+// dataset, stopping between steps if the context is cancelled.
+func IngestDatasetContext(ctx context.Context, params *IngestParams, config *IngestTaskConfig, steps *IngestSteps) (*IngestResult, error) {
+	status := newIngestStatus(params, steps)
+	return runIngest(ctx, status, params, config, steps)
+}
+
+// ResumeIngestDataset resumes a previously failed ingest from the last step
+// that completed successfully.
+func ResumeIngestDataset(datasetID string, dataCtor api.DataStorageCtor, metaCtor api.MetadataStorageCtor, config *IngestTaskConfig) (*IngestResult, error) {
+	status, err := loadIngestStatus(datasetID)
+	if err != nil {
+		return nil, err
+	}
+	if status.Complete {
+		return nil, errors.Errorf("ingest of dataset '%s' already completed", datasetID)
+	}
+	if status.Cancelled {
+		return nil, errors.Errorf("ingest of dataset '%s' was cancelled", datasetID)
+	}
+	if isIngestRunning(datasetID) {
+		return nil, errors.Errorf("ingest of dataset '%s' is still running", datasetID)
+	}
+
+	params := status.Params
+	params.DataCtor = dataCtor
+	params.MetaCtor = metaCtor
+	log.Infof("resuming ingest of dataset '%s' after step '%s'", datasetID, status.lastCompletedStep())
+
+	return runIngest(context.Background(), status, params, config, status.Steps)
+}
+
+func runIngest(ctx context.Context, status *IngestStatus, params *IngestParams, config *IngestTaskConfig, steps *IngestSteps) (*IngestResult, error) {
 	metaStorage, err := params.MetaCtor()
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to initialize metadata storage")
@@ -157,128 +204,237 @@ func IngestDataset(params *IngestParams, config *IngestTaskConfig, steps *Ingest
 		return nil, errors.Wrap(err, "unable to initialize data storage")
 	}
 
-	originalSchemaFile := params.GetSchemaDocPath()
-	latestSchemaOutput := originalSchemaFile
+	state := &ingestState{
+		params:             params,
+		config:             config,
+		steps:              steps,
+		metaStorage:        metaStorage,
+		dataStorage:        dataStorage,
+		originalSchemaFile: params.GetSchemaDocPath(),
+		latestSchemaOutput: status.latestSchemaPath(params.GetSchemaDocPath()),
+		datasetID:          status.IngestedDatasetID,
+		sampled:            status.Sampled,
+		rowCount:           status.RowCount,
+	}
+
+	trackIngest(status)
+	defer untrackIngest(status)
+
+	for _, step := range ingestPipeline {
+		if status.stepCompleted(step.name) {
+			log.Infof("skipping ingest step '%s' since it already completed", step.name)
+			continue
+		}
+		if ctx.Err() != nil {
+			log.Infof("ingest of dataset '%s' cancelled before step '%s'", status.DatasetID, step.name)
+			status.cancel()
+			return nil, ErrIngestCancelled
+		}
+
+		status.startStep(step.name)
+		start := time.Now()
+		err = step.run(state)
+		status.IngestedDatasetID = state.datasetID
+		status.Sampled = state.sampled
+		status.RowCount = state.rowCount
+		status.recordStep(step.name, state.latestSchemaOutput, time.Since(start), err)
+		if err != nil {
+			return nil, err
+		}
+	}
+	status.complete()
+
+	return &IngestResult{
+		DatasetID: state.datasetID,
+		Sampled:   state.sampled,
+		RowCount:  state.rowCount,
+		Params:    params,
+	}, nil
+}
 
-	output, err := Merge(latestSchemaOutput, params.ID, config)
+func ingestMergeStep(state *ingestState) error {
+	output, err := Merge(state.latestSchemaOutput, state.params.ID, state.config)
 	if err != nil {
-		return nil, errors.Wrap(err, "unable to merge all data into a single file")
+		return errors.Wrap(err, "unable to merge all data into a single file")
 	}
-	latestSchemaOutput = output
+	state.latestSchemaOutput = output
 	log.Infof("finished merging the dataset")
 
-	output, err = Clean(latestSchemaOutput, params.ID, params, config)
+	return nil
+}
+
+func ingestCleanStep(state *ingestState) error {
+	output, err := Clean(state.latestSchemaOutput, state.params.ID, state.params, state.config)
 	if err != nil {
-		return nil, errors.Wrap(err, "unable to clean all data")
+		return errors.Wrap(err, "unable to clean all data")
 	}
-	latestSchemaOutput = output
+	state.latestSchemaOutput = output
 	log.Infof("finished cleaning the dataset")
 
-	if config.ClassificationEnabled {
-		if steps.ClassificationOverwrite || !classificationExists(latestSchemaOutput, config) {
-			_, err = Classify(latestSchemaOutput, params.ID, config)
-			if err != nil {
-				if config.HardFail {
-					return nil, errors.Wrap(err, "unable to classify fields")
-				}
-				log.Errorf("unable to classify fields: %+v", err)
+	return nil
+}
+
+func ingestClassifyStep(state *ingestState) error {
+	if !state.config.ClassificationEnabled {
+		log.Infof("classification disabled")
+		return nil
+	}
+
+	if state.steps.ClassificationOverwrite || !classificationExists(state.latestSchemaOutput, state.config) {
+		_, err := Classify(state.latestSchemaOutput, state.params.ID, state.config)
+		if err != nil {
+			if state.config.HardFail {
+				return errors.Wrap(err, "unable to classify fields")
 			}
-			log.Infof("finished classifying the dataset")
-		} else {
-			log.Infof("skipping classification because it already exists")
+			log.Errorf("unable to classify fields: %+v", err)
 		}
+		log.Infof("finished classifying the dataset")
 	} else {
-		log.Infof("classification disabled")
+		log.Infof("skipping classification because it already exists")
 	}
 
-	_, err = Rank(latestSchemaOutput, params.ID, config)
+	return nil
+}
+
+func ingestRankStep(state *ingestState) error {
+	_, err := Rank(state.latestSchemaOutput, state.params.ID, state.config)
 	if err != nil {
 		log.Errorf("unable to rank field importance: %v", err)
 	}
 	log.Infof("finished ranking the dataset")
 
-	if config.SummaryEnabled {
-		_, err = Summarize(latestSchemaOutput, params.ID, config)
-		log.Infof("finished summarizing the dataset")
-		if err != nil {
-			if config.HardFail {
-				return nil, errors.Wrap(err, "unable to summarize the dataset")
-			}
-			log.Errorf("unable to summarize the dataset: %v", err)
-		}
-	} else {
+	return nil
+}
+
+func ingestSummarizeStep(state *ingestState) error {
+	if !state.config.SummaryEnabled {
 		log.Infof("summarization disabled")
+		return nil
 	}
 
-	if config.GeocodingEnabled {
-		output, err = GeocodeForwardDataset(latestSchemaOutput, params.ID, config)
-		if err != nil {
-			return nil, errors.Wrap(err, "unable to geocode all data")
+	_, err := Summarize(state.latestSchemaOutput, state.params.ID, state.config)
+	log.Infof("finished summarizing the dataset")
+	if err != nil {
+		if state.config.HardFail {
+			return errors.Wrap(err, "unable to summarize the dataset")
 		}
-		latestSchemaOutput = output
-		log.Infof("finished geocoding the dataset")
+		log.Errorf("unable to summarize the dataset: %v", err)
 	}
 
+	return nil
+}
+
+func ingestGeocodeStep(state *ingestState) error {
+	if !state.config.GeocodingEnabled {
+		return nil
+	}
+
+	output, err := GeocodeForwardDataset(state.latestSchemaOutput, state.params.ID, state.config)
+	if err != nil {
+		return errors.Wrap(err, "unable to geocode all data")
+	}
+	state.latestSchemaOutput = output
+	log.Infof("finished geocoding the dataset")
+
+	return nil
+}
+
+func ingestSampleStep(state *ingestState) error {
 	// not sure if better to call canSample here, or as the first part of the sample step
-	sampled := false
-	rowCount := 0
-	if canSample(latestSchemaOutput, config) {
-		log.Infof("sampling dataset")
-		latestSchemaOutput, sampled, rowCount, err = Sample(originalSchemaFile, latestSchemaOutput, params.ID, config)
-		if err != nil {
-			return nil, errors.Wrap(err, "unable to sample dataset")
-		}
-		log.Infof("finished sampling dataset")
+	if !canSample(state.latestSchemaOutput, state.config) {
+		return nil
+	}
+
+	log.Infof("sampling dataset")
+	output, sampled, rowCount, err := Sample(state.originalSchemaFile, state.latestSchemaOutput, state.params.ID, state.config)
+	if err != nil {
+		return errors.Wrap(err, "unable to sample dataset")
 	}
+	state.latestSchemaOutput = output
+	state.sampled = sampled
+	state.rowCount = rowCount
+	log.Infof("finished sampling dataset")
 
-	datasetID, err := Ingest(originalSchemaFile, latestSchemaOutput, dataStorage, metaStorage, params, config, steps)
+	return nil
+}
+
+func ingestIngestStep(state *ingestState) error {
+	datasetID, err := Ingest(state.originalSchemaFile, state.latestSchemaOutput, state.dataStorage,
+		state.metaStorage, state.params, state.config, state.steps)
 	if err != nil {
-		return nil, errors.Wrap(err, "unable to ingest ranked data")
+		return errors.Wrap(err, "unable to ingest ranked data")
 	}
+	state.datasetID = datasetID
 	log.Infof("finished ingesting the dataset")
 
-	// set the known grouping information
-	if params.RawGroupings != nil {
-		log.Infof("creating groupings in metadata")
-		err = SetGroups(datasetID, params.RawGroupings, dataStorage, metaStorage, config)
-		if err != nil {
-			return nil, errors.Wrap(err, "unable to set grouping")
+	return nil
+}
+
+func ingestDataQualityStep(state *ingestState) error {
+	_, err := DataQuality(state.latestSchemaOutput, state.datasetID, state.dataStorage, state.metaStorage, state.config.DataQualityOutputPathRelative)
+	if err != nil {
+		if state.config.HardFail {
+			return errors.Wrap(err, "unable to build data quality report")
 		}
-		log.Infof("done creating groupings in metadata")
+		log.Errorf("unable to build data quality report: %v", err)
+		return nil
+	}
+	log.Infof("finished building the data quality report")
+
+	return nil
+}
+
+func ingestSetGroupsStep(state *ingestState) error {
+	// set the known grouping information
+	if state.params.RawGroupings == nil {
+		return nil
+	}
+
+	log.Infof("creating groupings in metadata")
+	err := SetGroups(state.datasetID, state.params.RawGroupings, state.dataStorage, state.metaStorage, state.config)
+	if err != nil {
+		return errors.Wrap(err, "unable to set grouping")
 	}
+	log.Infof("done creating groupings in metadata")
 
+	return nil
+}
+
+func ingestFeaturizeStep(state *ingestState) error {
 	// featurize dataset for downstream efficiencies
-	if config.FeaturizationEnabled && !steps.SkipFeaturization && canFeaturize(datasetID, metaStorage) {
-		ingestedDataset, err := metaStorage.FetchDataset(datasetID, true, true, false)
-		if err != nil {
-			return nil, errors.Wrap(err, "unable to load metadata")
-		}
-		_, featurizedDatasetPath, err := FeaturizeDataset(originalSchemaFile, latestSchemaOutput, datasetID, metaStorage, config)
-		if err != nil {
-			// if the featurize step fails hard delete the dataset
-			_ = DeleteDataset(ingestedDataset, metaStorage, dataStorage, false)
-			return nil, errors.Wrap(err, "unable to featurize dataset")
-		}
-		log.Infof("finished featurizing the dataset")
-		ingestedDataset.LearningDataset = featurizedDatasetPath
-		err = metaStorage.UpdateDataset(ingestedDataset)
-		if err != nil {
-			return nil, errors.Wrap(err, "unable to store updated metadata")
-		}
+	if !state.config.FeaturizationEnabled || state.steps.SkipFeaturization || !canFeaturize(state.datasetID, state.metaStorage) {
+		return nil
+	}
+
+	ingestedDataset, err := state.metaStorage.FetchDataset(state.datasetID, true, true, false)
+	if err != nil {
+		return errors.Wrap(err, "unable to load metadata")
 	}
 
+	// a failure leaves the ingested dataset in place so that featurization can be resumed
+	_, featurizedDatasetPath, err := FeaturizeDataset(state.originalSchemaFile, state.latestSchemaOutput, state.datasetID, state.metaStorage, state.config)
+	if err != nil {
+		return errors.Wrap(err, "unable to featurize dataset")
+	}
+	log.Infof("finished featurizing the dataset")
+	ingestedDataset.LearningDataset = featurizedDatasetPath
+	err = state.metaStorage.UpdateDataset(ingestedDataset)
+	if err != nil {
+		return errors.Wrap(err, "unable to store updated metadata")
+	}
+
+	return nil
+}
+
+func ingestUpdateExtremasStep(state *ingestState) error {
 	// updating extremas is optional
-	err = UpdateExtremas(datasetID, metaStorage, dataStorage)
+	err := UpdateExtremas(state.datasetID, state.metaStorage, state.dataStorage)
 	if err != nil {
 		log.Errorf("unable to update extremas ranked data: %v", err)
 	}
 	log.Infof("finished updating extremas")
 
-	return &IngestResult{
-		DatasetID: datasetID,
-		Sampled:   sampled,
-		RowCount:  rowCount,
-	}, nil
+	return nil
 }
 
 // Featurize provides a separate step for featurzing data so that it can be called independently of the ingest step.
@@ -335,18 +491,9 @@ func Ingest(originalSchemaFile string, schemaFile string, data api.DataStorage,
 	}
 
 	// Connect to the database.
-	postgresConfig := &postgres.Config{
-		Password:         config.DatabasePassword,
-		User:             config.DatabaseUser,
-		Database:         config.Database,
-		Host:             config.DatabaseHost,
-		Port:             config.DatabasePort,
-		BatchSize:        config.DatabaseBatchSize,
-		PostgresLogLevel: "error",
-	}
-	pg, err := postgres.NewDatabase(postgresConfig, true)
+	db, err := newIngestDatabase(config)
 	if err != nil {
-		return "", errors.Wrap(err, "unable to initialize a new database")
+		return "", err
 	}
 
 	// Check for existing dataset
@@ -358,7 +505,7 @@ func Ingest(originalSchemaFile string, schemaFile string, data api.DataStorage,
 		}
 		if match != "" {
 			log.Infof("Matched %s to dataset %s", meta.Name, match)
-			err = deleteDataset(match, pg, storage)
+			err = deleteDataset(match, db, storage)
 			if err != nil {
 				log.Errorf("error deleting dataset: %v", err)
 			}
@@ -445,12 +592,16 @@ func IngestMetadata(originalSchemaFile string, schemaFile string, data api.DataS
 	return meta.ID, nil
 }
 
-// IngestPostgres ingests a dataset to PG storage.
+// IngestPostgres ingests a dataset to PG storage, or to the embedded sqlite
+// database when it is the configured data storage.
 func IngestPostgres(originalSchemaFile string, schemaFile string, params *IngestParams, config *IngestTaskConfig, steps *IngestSteps) error {
 	_, meta, err := loadMetadataForIngest(originalSchemaFile, schemaFile, params, config, steps)
 	if err != nil {
 		return err
 	}
+	if config.DataStorage == env.DataStorageSQLite {
+		return ingestSQLite(schemaFile, meta, params, config, steps)
+	}
 	mainDR := meta.GetMainDataResource()
 	dataDir := model.GetResourcePath(schemaFile, mainDR)
 
@@ -648,7 +799,38 @@ func matchDataset(storage api.MetadataStorage, meta *model.Metadata) (string, er
 	return "", nil
 }
 
-func deleteDataset(name string, pg *postgres.Database, meta api.MetadataStorage) error {
+// datasetDeleter removes the tables of a dataset from the data storage.
+type datasetDeleter interface {
+	DeleteDataset(name string)
+}
+
+// newIngestDatabase connects to the configured data storage.
+func newIngestDatabase(config *IngestTaskConfig) (datasetDeleter, error) {
+	if config.DataStorage == env.DataStorageSQLite {
+		db, err := sqlite.NewDatabase(sqlite.NewClient(config.SQLitePath), config.DatabaseBatchSize)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to initialize a new database")
+		}
+		return db, nil
+	}
+
+	postgresConfig := &postgres.Config{
+		Password:         config.DatabasePassword,
+		User:             config.DatabaseUser,
+		Database:         config.Database,
+		Host:             config.DatabaseHost,
+		Port:             config.DatabasePort,
+		BatchSize:        config.DatabaseBatchSize,
+		PostgresLogLevel: "error",
+	}
+	pg, err := postgres.NewDatabase(postgresConfig, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to initialize a new database")
+	}
+	return pg, nil
+}
+
+func deleteDataset(name string, db datasetDeleter, meta api.MetadataStorage) error {
 	success := false
 	for i := 0; i < 10 && !success; i++ {
 		err := meta.DeleteDataset(name, false)
@@ -660,7 +842,7 @@ func deleteDataset(name string, pg *postgres.Database, meta api.MetadataStorage)
 	}
 
 	if success {
-		pg.DeleteDataset(name)
+		db.DeleteDataset(name)
 	}
 
 	return nil
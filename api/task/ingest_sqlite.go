@@ -0,0 +1,114 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	log "github.com/unchartedsoftware/plog"
+
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/serialization"
+	"github.com/uncharted-distil/distil/api/sqlite"
+)
+
+// ingestSQLite ingests a dataset to the embedded sqlite database.
+func ingestSQLite(schemaFile string, meta *model.Metadata, params *IngestParams, config *IngestTaskConfig, steps *IngestSteps) error {
+	mainDR := meta.GetMainDataResource()
+	dataDir := model.GetResourcePath(schemaFile, mainDR)
+
+	db, err := sqlite.NewDatabase(sqlite.NewClient(config.SQLitePath), config.DatabaseBatchSize)
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize a new database")
+	}
+
+	dbTable := meta.StorageName
+	if steps.CreateMetadataTables {
+		err = db.CreateSolutionMetadataTables()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Drop the current table if requested.
+	_ = db.DropView(dbTable)
+	_ = db.DropTable(fmt.Sprintf("%s%s", dbTable, baseTableSuffix))
+	_ = db.DropTable(fmt.Sprintf("%s%s", dbTable, explainTableSuffix))
+
+	// Create the database table.
+	ds, err := db.InitializeDataset(meta)
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize a new dataset")
+	}
+
+	err = db.InitializeTable(dbTable, ds)
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize a table")
+	}
+
+	err = db.StoreMetadata(dbTable)
+	if err != nil {
+		return errors.Wrap(err, "unable to store the metadata")
+	}
+
+	err = db.CreateResultTable(dbTable)
+	if err != nil {
+		return errors.Wrap(err, "unable to create the result table")
+	}
+
+	// Load the data.
+	log.Infof("inserting rows into sqlite based on data found in %s", dataDir)
+	datasetStorage := serialization.GetStorage(dataDir)
+	data, err := datasetStorage.ReadData(dataDir)
+	if err != nil {
+		return errors.Wrap(err, "unable to read input data")
+	}
+	// skip header
+	data = data[1:]
+	for i, line := range data {
+		err = db.IngestRow(dbTable, line)
+		if err != nil {
+			return errors.Wrap(err, "unable to ingest row")
+		}
+
+		if (i+1)%10000 == 0 {
+			log.Infof("inserted %d rows so far", i+1)
+		}
+	}
+
+	log.Infof("ingesting final rows")
+	err = db.InsertRemainingRows()
+	if err != nil {
+		return errors.Wrap(err, "unable to ingest last rows")
+	}
+
+	// create indices for flagged fields
+	mappedVariables := api.MapVariables(mainDR.Variables, func(variable *model.Variable) string { return variable.Key })
+	for _, fieldName := range params.IndexFields {
+		field := mappedVariables[fieldName]
+		log.Infof("creating index on %s", field.Key)
+		err = db.CreateIndex(fmt.Sprintf("%s%s", dbTable, baseTableSuffix), field.Key)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Infof("all data ingested")
+
+	return nil
+}
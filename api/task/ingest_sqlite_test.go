@@ -0,0 +1,84 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uncharted-distil/distil-compute/model"
+	"github.com/uncharted-distil/distil-compute/primitive/compute"
+
+	"github.com/uncharted-distil/distil/api/env"
+	"github.com/uncharted-distil/distil/api/sqlite"
+)
+
+func TestIngestSQLite(t *testing.T) {
+	datasetDir := t.TempDir()
+	err := ioutil.WriteFile(path.Join(datasetDir, "learningData.csv"), []byte("d3mIndex,species,length\n0,setosa,1.5\n1,virginica,\n2,virginica,5.5\n"), 0644)
+	assert.NoError(t, err)
+
+	meta := model.NewMetadata("flowers", "flowers", "", "flowers")
+	dr := model.NewDataResource("learningData", model.ResTypeTable, map[string][]string{compute.D3MResourceFormat: {"csv"}})
+	dr.ResPath = "learningData.csv"
+	dr.Variables = []*model.Variable{
+		model.NewVariable(0, "d3mIndex", "d3mIndex", "d3mIndex", "d3mIndex", model.IndexType, model.IndexType, "", []string{model.RoleIndex}, []string{model.VarDistilRoleIndex}, nil, nil, false),
+		model.NewVariable(1, "species", "species", "species", "species", model.CategoricalType, model.CategoricalType, "", []string{model.RoleAttribute}, []string{model.VarDistilRoleData}, nil, nil, false),
+		model.NewVariable(2, "length", "length", "length", "length", model.RealType, model.RealType, "", []string{model.RoleAttribute}, []string{model.VarDistilRoleData}, nil, nil, false),
+	}
+	meta.DataResources = []*model.DataResource{dr}
+
+	config := &IngestTaskConfig{
+		DataStorage:       env.DataStorageSQLite,
+		SQLitePath:        path.Join(t.TempDir(), "distil.db"),
+		DatabaseBatchSize: 2,
+	}
+	params := &IngestParams{IndexFields: []string{"species"}}
+	err = ingestSQLite(path.Join(datasetDir, compute.D3MDataSchema), meta, params, config, &IngestSteps{CreateMetadataTables: true})
+	assert.NoError(t, err)
+
+	client, err := sqlite.NewClient(config.SQLitePath)()
+	assert.NoError(t, err)
+	var count int
+	var total float64
+	err = client.QueryRow(fmt.Sprintf("SELECT COUNT(*), SUM(length) FROM %s;", meta.StorageName)).Scan(&count, &total)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.Equal(t, 7.0, total)
+
+	// the flagged fields are indexed
+	var indices int
+	err = client.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = ?1;", fmt.Sprintf("%s%s_species", meta.StorageName, baseTableSuffix)).Scan(&indices)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, indices)
+
+	// ingesting again replaces the data
+	err = ingestSQLite(path.Join(datasetDir, compute.D3MDataSchema), meta, params, config, &IngestSteps{})
+	assert.NoError(t, err)
+	err = client.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s;", meta.StorageName)).Scan(&count)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	db, err := newIngestDatabase(config)
+	assert.NoError(t, err)
+	db.DeleteDataset(meta.StorageName)
+	err = client.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name LIKE ?1;", meta.StorageName+"%").Scan(&count)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
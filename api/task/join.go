@@ -58,7 +58,7 @@ func JoinDatamart(joinLeft *JoinSpec, joinRight *JoinSpec, rightOrigin *model.Da
 	}
 	datasetLeftURI := env.ResolvePath(joinLeft.DatasetSource, joinLeft.DatasetPath)
 
-	return join(joinLeft, joinRight, "", pipelineDesc, []string{datasetLeftURI}, defaultSubmitter{}, false)
+	return join(joinLeft, joinRight, "", pipelineDesc, []string{datasetLeftURI}, defaultSubmitter{requester: joinLeft.DatasetID}, false)
 }
 
 // JoinDistil will bring misery.
@@ -113,7 +113,7 @@ func JoinDistil(dataStorage apiModel.DataStorage, joinLeft *JoinSpec, joinRight
 	datasetLeftURI := joinLeft.DatasetPath
 	datasetRightURI := joinRight.DatasetPath
 
-	return join(joinLeft, joinRight, "", pipelineDesc, []string{datasetLeftURI, datasetRightURI}, defaultSubmitter{}, returnRaw)
+	return join(joinLeft, joinRight, "", pipelineDesc, []string{datasetLeftURI, datasetRightURI}, defaultSubmitter{requester: joinLeft.DatasetID}, returnRaw)
 }
 
 func join(joinLeft *JoinSpec, joinRight *JoinSpec, outputPath string, pipelineDesc *description.FullySpecifiedPipeline,
@@ -145,10 +145,12 @@ func join(joinLeft *JoinSpec, joinRight *JoinSpec, outputPath string, pipelineDe
 	return outputPath, data, nil
 }
 
-type defaultSubmitter struct{}
+type defaultSubmitter struct {
+	requester string
+}
 
-func (defaultSubmitter) submit(datasetURIs []string, pipelineDesc *description.FullySpecifiedPipeline) (string, error) {
-	return submitPipeline(datasetURIs, pipelineDesc, true)
+func (s defaultSubmitter) submit(datasetURIs []string, pipelineDesc *description.FullySpecifiedPipeline) (string, error) {
+	return submitPipeline(datasetURIs, pipelineDesc, true, pipelinePriorityDefault, s.requester, "join")
 }
 
 func createDatasetFromCSV(csvFile string, datasetName string, storageName string,
@@ -158,6 +160,13 @@ func createDatasetFromCSV(csvFile string, datasetName string, storageName string
 		return "", nil, err
 	}
 
+	return createDatasetFromData(inputData, datasetName, storageName, joinLeft, joinRight, outputPath)
+}
+
+// createDatasetFromData writes joined data out as a new dataset, merging the metadata of both
+// sides of the join.  The first row of the data is expected to be the header.
+func createDatasetFromData(inputData [][]string, datasetName string, storageName string,
+	joinLeft *JoinSpec, joinRight *JoinSpec, outputPath string) (string, []*model.Variable, error) {
 	metadata := model.NewMetadata(datasetName, datasetName, datasetName, storageName)
 	dataResource := model.NewDataResource(compute.DefaultResourceID, compute.D3MResourceType, map[string][]string{compute.D3MResourceFormat: {"csv"}})
 
@@ -178,7 +187,7 @@ func createDatasetFromCSV(csvFile string, datasetName string, storageName string
 		Data:     inputData,
 	}
 
-	err = serialization.WriteDataset(outputPath, rawDataset)
+	err := serialization.WriteDataset(outputPath, rawDataset)
 	if err != nil {
 		return "", nil, err
 	}
@@ -0,0 +1,145 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	"github.com/uncharted-distil/distil-compute/primitive/compute/description"
+	log "github.com/unchartedsoftware/plog"
+
+	apiModel "github.com/uncharted-distil/distil/api/model"
+)
+
+// JoinSpatial joins two datasets on the geometries of their geobounds groupings, matching
+// every left row to the nearest right row satisfying the spatial predicate.  The join runs
+// over the stored data and the output is written as a new dataset.
+func JoinSpatial(dataStorage apiModel.DataStorage, joinLeft *JoinSpec, joinRight *JoinSpec, predicate string,
+	distance float64, joinType string) (string, *apiModel.FilteredData, error) {
+	if !isValidJoinType(joinType) {
+		return "", nil, errors.Errorf("unsupported join type")
+	}
+	if !apiModel.IsValidSpatialPredicate(predicate) {
+		return "", nil, errors.Errorf("unsupported spatial join predicate '%s'", predicate)
+	}
+	if predicate == apiModel.SpatialJoinNearest && distance <= 0 {
+		return "", nil, errors.Errorf("nearest spatial join requires a positive distance")
+	}
+
+	leftGeometry, err := getJoinGeometry(joinLeft)
+	if err != nil {
+		return "", nil, err
+	}
+	rightGeometry, err := getJoinGeometry(joinRight)
+	if err != nil {
+		return "", nil, err
+	}
+
+	header := []string{}
+	leftColumns, header, err := getSpatialJoinColumns(dataStorage, joinLeft, map[string]bool{}, header)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// there is only allowed to be one set of geo coords after a join so the right geometry is
+	// dropped, and the left dataset takes priority in case of conflict
+	excludes := map[string]bool{
+		model.D3MIndexFieldName:      true,
+		rightGeometry.CoordinatesCol: true,
+		rightGeometry.PolygonCol:     true,
+	}
+	for _, h := range header {
+		excludes[h] = true
+	}
+	rightColumns, header, err := getSpatialJoinColumns(dataStorage, joinRight, excludes, header)
+	if err != nil {
+		return "", nil, err
+	}
+
+	spatialJoin := &apiModel.SpatialJoin{
+		Predicate: predicate,
+		Distance:  distance,
+		Inner:     joinType == description.JoinTypeInner,
+		Left: &apiModel.SpatialJoinSide{
+			StorageName: joinLeft.ExistingMetadata.StorageName,
+			GeometryCol: leftGeometry.PolygonCol,
+			Columns:     leftColumns,
+		},
+		Right: &apiModel.SpatialJoinSide{
+			StorageName: joinRight.ExistingMetadata.StorageName,
+			GeometryCol: rightGeometry.PolygonCol,
+			Columns:     rightColumns,
+		},
+	}
+	rows, err := dataStorage.FetchSpatialJoin(spatialJoin)
+	if err != nil {
+		return "", nil, err
+	}
+	log.Infof("spatial join (%s) of '%s' and '%s' produced %d rows", predicate, joinLeft.DatasetID, joinRight.DatasetID, len(rows))
+
+	// create a new dataset from the joined rows
+	inputData := append([][]string{header}, rows...)
+	datasetName := strings.Join([]string{joinLeft.DatasetID, joinRight.DatasetID}, "-")
+	storageName := model.NormalizeDatasetID(datasetName)
+	outputPath, mergedVariables, err := createDatasetFromData(inputData, datasetName, storageName, joinLeft, joinRight, "")
+	if err != nil {
+		return "", nil, errors.Wrap(err, "unable to create dataset from spatial join")
+	}
+
+	// return some of the data for the client to preview
+	data, err := apiModel.CreateFilteredData(inputData, mergedVariables, false, 100)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return outputPath, data, nil
+}
+
+func getJoinGeometry(joinSpec *JoinSpec) (*model.GeoBoundsGrouping, error) {
+	for _, v := range joinSpec.UpdatedVariables {
+		if v.IsGrouping() && model.IsGeoBounds(v.Type) {
+			return v.Grouping.(*model.GeoBoundsGrouping), nil
+		}
+	}
+
+	return nil, errors.Errorf("dataset '%s' has no geometry to join on", joinSpec.DatasetID)
+}
+
+// getSpatialJoinColumns lists the stored columns of the dataset variables, appending
+// their header names to the supplied header.
+func getSpatialJoinColumns(dataStorage apiModel.DataStorage, joinSpec *JoinSpec, excludes map[string]bool,
+	header []string) ([]string, []string, error) {
+	columns := []string{}
+	for _, v := range joinSpec.ExistingMetadata.GetMainDataResource().Variables {
+		headerName := denormVariableName(v)
+		if excludes[v.Key] || excludes[headerName] {
+			continue
+		}
+		exists, err := dataStorage.DoesVariableExist(joinSpec.DatasetID, joinSpec.ExistingMetadata.StorageName, v.Key)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !exists {
+			continue
+		}
+		columns = append(columns, v.Key)
+		header = append(header, headerName)
+	}
+
+	return columns, header, nil
+}
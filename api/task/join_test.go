@@ -194,3 +194,17 @@ func TestJoin(t *testing.T) {
 		}
 	}
 }
+
+func TestJoinSpatialValidation(t *testing.T) {
+	spec := &JoinSpec{DatasetID: "regions"}
+
+	_, _, err := JoinSpatial(nil, spec, spec, "touches", 0, description.JoinTypeLeft)
+	assert.Error(t, err)
+
+	_, _, err = JoinSpatial(nil, spec, spec, apiModel.SpatialJoinNearest, 0, description.JoinTypeLeft)
+	assert.Error(t, err)
+
+	// the geometries come from the geobounds groupings of the datasets
+	_, _, err = JoinSpatial(nil, spec, spec, apiModel.SpatialJoinIntersects, 0, description.JoinTypeInner)
+	assert.Error(t, err)
+}
@@ -0,0 +1,101 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	log "github.com/unchartedsoftware/plog"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+// RecordLineage stores the lineage edge describing how the target dataset was
+// derived from the source datasets.
+func RecordLineage(metaStorage api.MetadataStorage, operation string, target string, sources []string,
+	parameters map[string]interface{}, filters *api.FilterParams) error {
+	edge := api.NewLineageEdge(operation, target, sources, parameters, filters)
+	err := metaStorage.PersistLineageEdge(edge)
+	if err != nil {
+		return err
+	}
+	log.Infof("recorded %s lineage of dataset '%s' from %v", operation, target, sources)
+
+	return nil
+}
+
+// FetchLineage builds the lineage graph of a dataset by walking the lineage
+// edges back to the datasets that have no recorded derivation.
+func FetchLineage(dataset string, metaStorage api.MetadataStorage) (*api.LineageGraph, error) {
+	graph := &api.LineageGraph{
+		Dataset: dataset,
+		Nodes:   []*api.LineageNode{},
+		Edges:   []*api.LineageEdge{},
+	}
+
+	visited := map[string]bool{dataset: true}
+	pending := []string{dataset}
+	for len(pending) > 0 {
+		datasetID := pending[0]
+		pending = pending[1:]
+
+		node, err := fetchLineageNode(datasetID, metaStorage)
+		if err != nil {
+			return nil, err
+		}
+		graph.Nodes = append(graph.Nodes, node)
+
+		edges, err := metaStorage.FetchLineageEdges(datasetID)
+		if err != nil {
+			return nil, err
+		}
+		for _, edge := range edges {
+			graph.Edges = append(graph.Edges, edge)
+			for _, source := range edge.Sources {
+				// in place modifications list the dataset as its own source
+				if !visited[source] {
+					visited[source] = true
+					pending = append(pending, source)
+				}
+			}
+		}
+	}
+
+	return graph, nil
+}
+
+func fetchLineageNode(datasetID string, metaStorage api.MetadataStorage) (*api.LineageNode, error) {
+	node := &api.LineageNode{
+		ID: datasetID,
+	}
+
+	// datasets may have been removed since they were used as a source
+	exists, err := metaStorage.DatasetExists(datasetID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return node, nil
+	}
+
+	ds, err := metaStorage.FetchDataset(datasetID, false, false, false)
+	if err != nil {
+		return nil, err
+	}
+	node.Exists = true
+	node.Name = ds.Name
+	node.Deleted = ds.Deleted
+
+	return node, nil
+}
@@ -0,0 +1,185 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/unchartedsoftware/plog"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+var (
+	modelLocks     = map[string]*sync.Mutex{}
+	modelLocksLock = &sync.Mutex{}
+)
+
+// lockModel serializes the updates to the versions of a model, returning the
+// function releasing the lock.
+func lockModel(modelName string) func() {
+	modelLocksLock.Lock()
+	lock, ok := modelLocks[modelName]
+	if !ok {
+		lock = &sync.Mutex{}
+		modelLocks[modelName] = lock
+	}
+	modelLocksLock.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+// RegisterModel persists an exported model as a version of the model sharing
+// its name. New versions start in staging. Saving a fitted solution that is
+// already registered under the same name updates it in place, while saving it
+// under a different name is rejected since models are stored by fitted solution.
+func RegisterModel(exported *api.ExportedModel, modelStorage api.ExportedModelStorage) error {
+	if len(exported.Metrics) == 0 {
+		return errors.Errorf("model '%s' has no metrics", exported.ModelName)
+	}
+	if len(exported.Variables) == 0 {
+		return errors.Errorf("model '%s' has no features", exported.ModelName)
+	}
+	if exported.Lineage == nil {
+		return errors.Errorf("model '%s' has no training dataset lineage", exported.ModelName)
+	}
+
+	unlock := lockModel(exported.ModelName)
+	defer unlock()
+
+	existing, err := modelStorage.FetchModelByID(exported.FittedSolutionID)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ModelName != exported.ModelName {
+		return errors.Errorf("fitted solution '%s' is already registered as model '%s'", exported.FittedSolutionID, existing.ModelName)
+	}
+
+	versions, err := modelStorage.FetchModelVersions(exported.ModelName)
+	if err != nil {
+		return err
+	}
+
+	exported.Version = api.NextModelVersion(versions)
+	exported.Stage = api.ModelStageStaging
+	exported.CreatedTime = time.Now()
+	for _, v := range versions {
+		if v.FittedSolutionID == exported.FittedSolutionID {
+			exported.Version = v.Version
+			exported.Stage = v.Stage
+			exported.CreatedTime = v.CreatedTime
+			break
+		}
+	}
+
+	err = modelStorage.PersistExportedModel(exported)
+	if err != nil {
+		return err
+	}
+	log.Infof("registered fitted solution '%s' as version %d of model '%s'", exported.FittedSolutionID, exported.Version, exported.ModelName)
+
+	return nil
+}
+
+// FetchModelVersions returns the versions of a model that have not been
+// deleted.
+func FetchModelVersions(modelName string, modelStorage api.ExportedModelStorage) ([]*api.ExportedModel, error) {
+	versions, err := modelStorage.FetchModelVersions(modelName)
+	if err != nil {
+		return nil, err
+	}
+
+	available := []*api.ExportedModel{}
+	for _, v := range versions {
+		if !v.Deleted {
+			available = append(available, v)
+		}
+	}
+	return available, nil
+}
+
+// SetModelStage moves a model version to a new stage. Promoting a version to
+// production archives the version previously in production.
+func SetModelStage(modelName string, version int, stage string, modelStorage api.ExportedModelStorage) (*api.ExportedModel, error) {
+	if !api.IsModelStage(stage) {
+		return nil, errors.Errorf("'%s' is not a valid model stage", stage)
+	}
+
+	unlock := lockModel(modelName)
+	defer unlock()
+
+	versions, err := FetchModelVersions(modelName, modelStorage)
+	if err != nil {
+		return nil, err
+	}
+	target := findModelVersion(versions, version)
+	if target == nil {
+		return nil, errors.Errorf("model '%s' has no version %d", modelName, version)
+	}
+
+	if stage == api.ModelStageProduction {
+		for _, v := range versions {
+			if v.Version != version && v.Stage == api.ModelStageProduction {
+				v.Stage = api.ModelStageArchived
+				err = modelStorage.PersistExportedModel(v)
+				if err != nil {
+					return nil, err
+				}
+				log.Infof("archived version %d of model '%s'", v.Version, modelName)
+			}
+		}
+	}
+
+	target.Stage = stage
+	err = modelStorage.PersistExportedModel(target)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("moved version %d of model '%s' to %s", version, modelName, stage)
+
+	return target, nil
+}
+
+// CompareModelVersions diffs two versions of a model.
+func CompareModelVersions(modelName string, baseVersion int, otherVersion int, modelStorage api.ExportedModelStorage) (*api.ModelVersionComparison, error) {
+	versions, err := FetchModelVersions(modelName, modelStorage)
+	if err != nil {
+		return nil, err
+	}
+
+	base := findModelVersion(versions, baseVersion)
+	if base == nil {
+		return nil, errors.Errorf("model '%s' has no version %d", modelName, baseVersion)
+	}
+	other := findModelVersion(versions, otherVersion)
+	if other == nil {
+		return nil, errors.Errorf("model '%s' has no version %d", modelName, otherVersion)
+	}
+
+	return api.CompareModelVersions(base, other), nil
+}
+
+func findModelVersion(versions []*api.ExportedModel, version int) *api.ExportedModel {
+	for _, v := range versions {
+		if v.Version == version {
+			return v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+// testModelStorage keeps models in memory, keyed by fitted solution like the
+// real storages.
+type testModelStorage struct {
+	models map[string]*api.ExportedModel
+	mu     sync.Mutex
+}
+
+func newTestModelStorage() *testModelStorage {
+	return &testModelStorage{models: map[string]*api.ExportedModel{}}
+}
+
+func (s *testModelStorage) PersistExportedModel(exportedModel *api.ExportedModel) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *exportedModel
+	s.models[exportedModel.FittedSolutionID] = &copied
+	return nil
+}
+
+func (s *testModelStorage) FetchModel(model string) (*api.ExportedModel, error) {
+	versions, _ := s.FetchModelVersions(model)
+	if len(versions) == 0 {
+		return nil, nil
+	}
+	return versions[len(versions)-1], nil
+}
+
+func (s *testModelStorage) FetchModelByID(fittedSolutionID string) (*api.ExportedModel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.models[fittedSolutionID], nil
+}
+
+func (s *testModelStorage) FetchModels(includeDeleted bool) ([]*api.ExportedModel, error) {
+	return nil, nil
+}
+
+func (s *testModelStorage) SearchModels(terms string, includeDeleted bool) ([]*api.ExportedModel, error) {
+	return nil, nil
+}
+
+func (s *testModelStorage) DeleteModel(fittedSolutionID string) error {
+	return nil
+}
+
+func (s *testModelStorage) FetchModelVersions(modelName string) ([]*api.ExportedModel, error) {
+	// give concurrent registrations the chance to interleave
+	time.Sleep(time.Millisecond)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	versions := []*api.ExportedModel{}
+	for _, m := range s.models {
+		if m.ModelName == modelName {
+			copied := *m
+			versions = append(versions, &copied)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+	return versions, nil
+}
+
+func createTestExportedModel(modelName string, fittedSolutionID string) *api.ExportedModel {
+	return &api.ExportedModel{
+		ModelName:        modelName,
+		FittedSolutionID: fittedSolutionID,
+		Variables:        []string{"alpha"},
+		Metrics:          []*api.SolutionScore{{Metric: "accuracy", Score: 0.9}},
+		Lineage:          &api.LineageGraph{},
+	}
+}
+
+func TestRegisterModelConcurrentVersions(t *testing.T) {
+	storage := newTestModelStorage()
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, RegisterModel(createTestExportedModel("churn", fmt.Sprintf("fitted-%d", i)), storage))
+		}(i)
+	}
+	wg.Wait()
+
+	versions, err := storage.FetchModelVersions("churn")
+	assert.NoError(t, err)
+	assert.Len(t, versions, 5)
+	for i, v := range versions {
+		assert.Equal(t, i+1, v.Version)
+	}
+}
+
+func TestRegisterModelName(t *testing.T) {
+	storage := newTestModelStorage()
+	assert.NoError(t, RegisterModel(createTestExportedModel("churn", "fitted"), storage))
+
+	// saving again under the same name updates the version in place
+	assert.NoError(t, RegisterModel(createTestExportedModel("churn", "fitted"), storage))
+	versions, err := storage.FetchModelVersions("churn")
+	assert.NoError(t, err)
+	assert.Len(t, versions, 1)
+	assert.Equal(t, 1, versions[0].Version)
+
+	// a fitted solution cannot be moved to another model
+	assert.Error(t, RegisterModel(createTestExportedModel("retention", "fitted"), storage))
+	existing, err := storage.FetchModelByID("fitted")
+	assert.NoError(t, err)
+	assert.Equal(t, "churn", existing.ModelName)
+}
@@ -105,7 +105,7 @@ func OutlierDetection(dataset *api.Dataset, variable string) ([]*OutlierPoint, e
 		return nil, err
 	}
 
-	datasetURI, err := submitPipeline([]string{datasetInputDir}, step, true)
+	datasetURI, err := submitPipeline([]string{datasetInputDir}, step, true, pipelinePriorityInteractive, dataset.ID, "outlier-detection")
 	if err != nil {
 		return nil, err
 	}
@@ -33,6 +33,11 @@ const (
 	OutlierAnomaly = "anomaly"
 	// OutlierRegular is the category name used for regular values as discovered by outlier detection
 	OutlierRegular = "regular"
+
+	// pipelines a user is waiting on are queued ahead of the ingest pipelines
+	pipelinePriorityInteractive = sr.QueuePriorityInteractive
+	pipelinePriorityDefault     = sr.QueuePriorityDefault
+	pipelinePriorityBatch       = sr.QueuePriorityBatch
 )
 
 var (
@@ -61,8 +66,8 @@ func SetClient(computeClient *compute.Client) {
 	client = computeClient
 }
 
-func submitPipeline(datasets []string, step *description.FullySpecifiedPipeline, shouldCache bool) (string, error) {
-	return sr.SubmitPipeline(client, datasets, nil, nil, step, nil, shouldCache)
+func submitPipeline(datasets []string, step *description.FullySpecifiedPipeline, shouldCache bool, priority sr.QueuePriority, requester string, operation string) (string, error) {
+	return sr.SubmitPipeline(client, datasets, nil, nil, step, nil, shouldCache, priority, requester, operation)
 }
 
 func getD3MIndexField(dr *model.DataResource) int {
@@ -298,6 +298,11 @@ func PrepExistingPredictionDataset(params *PredictParams) (string, string, error
 	if err != nil {
 		return "", "", err
 	}
+	err = RecordLineage(params.MetaStorage, api.LineageOperationPrediction, cloneDatasetID, []string{params.Dataset},
+		map[string]interface{}{"fittedSolutionId": params.FittedSolutionID}, nil)
+	if err != nil {
+		return "", "", err
+	}
 
 	// pull the cloned dataset for updates
 	dsCloned, err = params.MetaStorage.FetchDataset(cloneDatasetID, true, true, true)
@@ -611,6 +616,11 @@ func IngestPredictionDataset(params *PredictParams) error {
 	if err != nil {
 		return err
 	}
+	err = RecordLineage(params.MetaStorage, api.LineageOperationPrediction, params.Dataset, []string{params.SourceDatasetID},
+		map[string]interface{}{"fittedSolutionId": params.FittedSolutionID}, nil)
+	if err != nil {
+		return err
+	}
 
 	// only featurize if the source dataset was featurized
 	if params.Meta.LearningDataset != "" {
@@ -0,0 +1,194 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"io/ioutil"
+	"path"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/metadata"
+	"github.com/uncharted-distil/distil-compute/model"
+	"github.com/uncharted-distil/distil-compute/primitive/compute"
+
+	comp "github.com/uncharted-distil/distil/api/compute"
+	"github.com/uncharted-distil/distil/api/dataset"
+	"github.com/uncharted-distil/distil/api/env"
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+// CreatePredictions ingests the data of a predict request and produces predictions for it
+// using the requested fitted solution.  It returns the prediction result once done.
+func CreatePredictions(request *comp.PredictRequest, metaStorage api.MetadataStorage, dataStorage api.DataStorage,
+	solutionStorage api.SolutionStorage, modelStorage api.ExportedModelStorage) (*api.SolutionResult, error) {
+	// get the solution id from the fitted solution ID
+	solutionResults, err := solutionStorage.FetchSolutionResultsByFittedSolutionID(request.FittedSolutionID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to fetch solution results fitted solution id")
+	}
+	if len(solutionResults) == 0 {
+		return nil, errors.Errorf("unable to map fitted solution id to dataset or solution id")
+	}
+	sr := solutionResults[0]
+
+	// read the metadata of the original dataset
+	datasetES, err := metaStorage.FetchDataset(sr.Dataset, false, false, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to fetch dataset from es")
+	}
+
+	// get the source dataset from the fitted solution ID
+	req, err := solutionStorage.FetchRequestByFittedSolutionID(sr.FittedSolutionID)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaPath := path.Join(env.ResolvePath(datasetES.Source, datasetES.Folder), compute.D3MDataSchema)
+	meta, err := metadata.LoadMetadataFromOriginalSchema(schemaPath, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load metadata from source dataset schema doc")
+	}
+
+	var learningDataMeta *model.Metadata
+	if datasetES.LearningDataset != "" {
+		learningDataSchemaPath := path.Join(datasetES.LearningDataset, compute.D3MDataSchema)
+		learningDataMeta, err = metadata.LoadMetadataFromOriginalSchema(learningDataSchemaPath, false)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to load metadata from source dataset schema doc")
+		}
+	}
+
+	// In the case of grouped variables, the target will not be variable itself, but one of its property
+	// values.  We need to fetch using the original dataset, since it will have grouped variable info,
+	// and then resolve the actual target.
+	targetVar, err := metaStorage.FetchVariable(meta.ID, req.TargetFeature())
+	if err != nil {
+		return nil, err
+	}
+
+	variables, err := metaStorage.FetchVariablesByName(req.Dataset, req.Filters.Variables, false, false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// resolve the task so we know what type of data we should be expecting
+	requestTask, err := comp.ResolveTask(dataStorage, meta.StorageName, targetVar, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	// config objects required for ingest
+	config, _ := env.LoadConfig()
+	ingestConfig := NewConfig(config)
+
+	predictParams := &PredictParams{
+		Meta:             meta,
+		LearningDataMeta: learningDataMeta,
+		SourceDataset:    datasetES,
+		Dataset:          request.DatasetID,
+		SolutionID:       sr.SolutionID,
+		FittedSolutionID: request.FittedSolutionID,
+		OutputPath:       path.Join(config.D3MOutputDir, config.AugmentedSubFolder),
+		Target:           targetVar,
+		MetaStorage:      metaStorage,
+		DataStorage:      dataStorage,
+		SolutionStorage:  solutionStorage,
+		ModelStorage:     modelStorage,
+		Config:           &config,
+		IngestConfig:     ingestConfig,
+		SourceDatasetID:  meta.ID,
+	}
+
+	datasetName, datasetPath, err := getPredictionDataset(requestTask, request, predictParams)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create raw dataset")
+	}
+	predictParams.Dataset = datasetName
+	predictParams.SchemaPath = datasetPath
+
+	// run predictions - synchronous call for now
+	resultID, err := Predict(predictParams)
+	if err != nil {
+		return nil, err
+	}
+
+	// read the results from the database
+	return solutionStorage.FetchPredictionResultByProduceRequestID(resultID)
+}
+
+func createPredictionDataset(requestTask *comp.Task, request *comp.PredictRequest,
+	predictParams *PredictParams) (DatasetConstructor, []string, error) {
+	datasetID := request.DatasetID
+	datasetPath := request.DatasetPath
+	var ds DatasetConstructor
+	var err error
+	indexFields := []string{}
+	if comp.HasTaskType(requestTask, compute.RemoteSensingTask) {
+		ds, err = dataset.NewSatelliteDataset(datasetID, "tif", predictParams.SourceDataset.Sensor, datasetPath)
+		indexFields = dataset.GetSatelliteIndexFields()
+	} else if comp.HasTaskType(requestTask, compute.ImageTask) {
+		ds, err = dataset.NewMediaDataset(datasetID, "png", "jpeg", datasetPath)
+	} else if comp.HasTaskType(requestTask, compute.TimeSeriesTask) && comp.HasTaskType(requestTask, compute.ForecastingTask) {
+		ds, err = NewPredictionTimeseriesDataset(predictParams, request.IntervalLength, request.IntervalCount)
+	} else {
+		var data []byte
+		data, err = ioutil.ReadFile(datasetPath)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "unable to read raw tabular data")
+		}
+		ds, err = dataset.NewTableDataset(datasetID, data, false)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ds, indexFields, nil
+}
+
+func getPredictionDataset(requestTask *comp.Task, request *comp.PredictRequest, predictParams *PredictParams) (string, string, error) {
+	// check if the dataset already exists
+	if request.ExistingDataset {
+		clonedID, clonedPath, err := PrepExistingPredictionDataset(predictParams)
+		if err != nil {
+			return "", "", err
+		}
+
+		return clonedID, path.Join(clonedPath, compute.D3MDataSchema), nil
+	}
+
+	// ingest the data as a new prediction dataset
+	ds, indexFields, err := createPredictionDataset(requestTask, request, predictParams)
+	if err != nil {
+		return "", "", err
+	}
+	predictParams.DatasetConstructor = ds
+	predictParams.IndexFields = indexFields
+	// import the dataset
+	datasetName, datasetPath, err := ImportPredictionDataset(predictParams)
+	if err != nil {
+		return "", "", err
+	}
+	predictParams.Dataset = datasetName
+	predictParams.SchemaPath = datasetPath
+
+	// ingest the dataset
+	err = IngestPredictionDataset(predictParams)
+	if err != nil {
+		return "", "", err
+	}
+
+	return predictParams.Dataset, predictParams.SchemaPath, nil
+}
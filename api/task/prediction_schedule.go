@@ -0,0 +1,440 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+	log "github.com/unchartedsoftware/plog"
+
+	comp "github.com/uncharted-distil/distil/api/compute"
+	"github.com/uncharted-distil/distil/api/env"
+	api "github.com/uncharted-distil/distil/api/model"
+	"github.com/uncharted-distil/distil/api/util/cron"
+)
+
+const (
+	// how often the schedules are checked for a pending run
+	predictionSchedulerInterval = 15 * time.Second
+)
+
+var (
+	scheduler *predictionScheduler
+)
+
+// PredictionExporter formats the results of a prediction for export, returning
+// the file extension to use along with the formatted results.
+type PredictionExporter func(produceRequestID string, format string) (string, []byte, error)
+
+type predictionScheduler struct {
+	metaCtor     api.MetadataStorageCtor
+	dataCtor     api.DataStorageCtor
+	solutionCtor api.SolutionStorageCtor
+	modelCtor    api.ExportedModelStorageCtor
+	exporter     PredictionExporter
+	schedules    map[string]*scheduledPrediction
+	mu           *sync.Mutex
+}
+
+type scheduledPrediction struct {
+	schedule *api.PredictionSchedule
+	cron     *cron.Schedule
+	next     time.Time
+	running  bool
+}
+
+// InitializePredictionScheduler starts the prediction scheduler in the background and
+// loads the persisted prediction schedules into it.
+func InitializePredictionScheduler(metaCtor api.MetadataStorageCtor, dataCtor api.DataStorageCtor,
+	solutionCtor api.SolutionStorageCtor, modelCtor api.ExportedModelStorageCtor, exporter PredictionExporter) error {
+	scheduler = &predictionScheduler{
+		metaCtor:     metaCtor,
+		dataCtor:     dataCtor,
+		solutionCtor: solutionCtor,
+		modelCtor:    modelCtor,
+		exporter:     exporter,
+		schedules:    map[string]*scheduledPrediction{},
+		mu:           &sync.Mutex{},
+	}
+	go scheduler.run()
+
+	solutionStorage, err := solutionCtor()
+	if err != nil {
+		return err
+	}
+	schedules, err := solutionStorage.FetchPredictionSchedules()
+	if err != nil {
+		return err
+	}
+	for _, schedule := range schedules {
+		err = scheduler.add(schedule)
+		if err != nil {
+			log.Warnf("unable to schedule predictions for schedule '%s': %v", schedule.ScheduleID, err)
+		}
+	}
+
+	return nil
+}
+
+// CreatePredictionSchedule registers a fitted solution to be run on a cron schedule against
+// the files found in an input folder or matching an input glob, with the results of each
+// run written to the output folder in the requested export format.
+func CreatePredictionSchedule(fittedSolutionID string, inputPath string, cronExpression string,
+	outputPath string, format string) (*api.PredictionSchedule, error) {
+	if scheduler == nil {
+		return nil, errors.New("prediction scheduler not initialized")
+	}
+	_, err := cron.Parse(cronExpression)
+	if err != nil {
+		return nil, err
+	}
+	inputPath, err = resolveSchedulePath(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	_, err = filepath.Glob(inputPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid input path '%s'", inputPath)
+	}
+	outputPath, err = resolveSchedulePath(outputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	solutionStorage, err := scheduler.solutionCtor()
+	if err != nil {
+		return nil, err
+	}
+	results, err := solutionStorage.FetchSolutionResultsByFittedSolutionID(fittedSolutionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, errors.Errorf("fitted solution '%s' not found", fittedSolutionID)
+	}
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to generate prediction schedule id")
+	}
+	schedule := &api.PredictionSchedule{
+		ScheduleID:       id.String(),
+		FittedSolutionID: fittedSolutionID,
+		InputPath:        inputPath,
+		Cron:             cronExpression,
+		OutputPath:       outputPath,
+		Format:           format,
+		CreatedTime:      time.Now(),
+	}
+	err = solutionStorage.PersistPredictionSchedule(schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	err = scheduler.add(schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// DeletePredictionSchedule stops and removes a prediction schedule.  A run in
+// progress is allowed to complete.
+func DeletePredictionSchedule(scheduleID string) error {
+	if scheduler == nil {
+		return errors.New("prediction scheduler not initialized")
+	}
+	solutionStorage, err := scheduler.solutionCtor()
+	if err != nil {
+		return err
+	}
+	err = solutionStorage.DeletePredictionSchedule(scheduleID)
+	if err != nil {
+		return err
+	}
+
+	scheduler.mu.Lock()
+	delete(scheduler.schedules, scheduleID)
+	scheduler.mu.Unlock()
+
+	return nil
+}
+
+// RunPredictionSchedule runs a prediction schedule immediately, outside of its
+// regular schedule.
+func RunPredictionSchedule(scheduleID string) error {
+	if scheduler == nil {
+		return errors.New("prediction scheduler not initialized")
+	}
+
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+	entry, ok := scheduler.schedules[scheduleID]
+	if !ok {
+		return errors.Errorf("prediction schedule '%s' not found", scheduleID)
+	}
+	if entry.running {
+		return errors.Errorf("prediction schedule '%s' is already running", scheduleID)
+	}
+	scheduler.start(entry)
+
+	return nil
+}
+
+func (s *predictionScheduler) add(schedule *api.PredictionSchedule) error {
+	parsed, err := cron.Parse(schedule.Cron)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schedules[schedule.ScheduleID] = &scheduledPrediction{
+		schedule: schedule,
+		cron:     parsed,
+		next:     parsed.Next(time.Now()),
+	}
+
+	return nil
+}
+
+func (s *predictionScheduler) run() {
+	ticker := time.NewTicker(predictionSchedulerInterval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		s.mu.Lock()
+		for _, entry := range s.schedules {
+			if entry.next.IsZero() || now.Before(entry.next) {
+				continue
+			}
+			// a run that is still going when the next one is due causes the latter to be skipped
+			if entry.running {
+				log.Warnf("skipping run of prediction schedule '%s' as the previous run is still in progress", entry.schedule.ScheduleID)
+			} else {
+				s.start(entry)
+			}
+			entry.next = entry.cron.Next(now)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// start runs a schedule in the background - callers must hold the scheduler lock.
+func (s *predictionScheduler) start(entry *scheduledPrediction) {
+	entry.running = true
+	go func() {
+		s.runSchedule(entry.schedule)
+
+		s.mu.Lock()
+		entry.running = false
+		s.mu.Unlock()
+	}()
+}
+
+func (s *predictionScheduler) runSchedule(schedule *api.PredictionSchedule) {
+	solutionStorage, err := s.solutionCtor()
+	if err != nil {
+		log.Errorf("unable to run prediction schedule '%s': %+v", schedule.ScheduleID, err)
+		return
+	}
+
+	// every input found is predicted separately, and gets its own entry in the run history
+	inputs, err := resolveScheduleInputs(schedule.InputPath)
+	if err == nil {
+		// schedules persisted before the data folders were changed are no longer allowed to run
+		_, err = resolveSchedulePath(schedule.OutputPath)
+	}
+	if err == nil && len(inputs) == 0 {
+		err = errors.Errorf("no input found at '%s'", schedule.InputPath)
+	}
+	if err != nil {
+		run := newPredictionScheduleRun(schedule, schedule.InputPath)
+		completePredictionScheduleRun(run, solutionStorage.PersistPredictionScheduleRun, err)
+		return
+	}
+
+	for _, input := range inputs {
+		run := newPredictionScheduleRun(schedule, input)
+		err = solutionStorage.PersistPredictionScheduleRun(run)
+		if err != nil {
+			log.Errorf("unable to persist run of prediction schedule '%s': %+v", schedule.ScheduleID, err)
+		}
+
+		err = s.predict(schedule, run)
+		completePredictionScheduleRun(run, solutionStorage.UpdatePredictionScheduleRun, err)
+	}
+}
+
+func (s *predictionScheduler) predict(schedule *api.PredictionSchedule, run *api.PredictionScheduleRun) error {
+	metaStorage, err := s.metaCtor()
+	if err != nil {
+		return err
+	}
+	dataStorage, err := s.dataCtor()
+	if err != nil {
+		return err
+	}
+	solutionStorage, err := s.solutionCtor()
+	if err != nil {
+		return err
+	}
+	modelStorage, err := s.modelCtor()
+	if err != nil {
+		return err
+	}
+
+	// the prediction dataset is named after the input and the run time
+	inputName := strings.TrimSuffix(path.Base(run.InputPath), path.Ext(run.InputPath))
+	runName := fmt.Sprintf("%s-%s", inputName, run.StartTime.Format("20060102150405"))
+	request := &comp.PredictRequest{
+		DatasetID:        runName,
+		DatasetPath:      run.InputPath,
+		FittedSolutionID: schedule.FittedSolutionID,
+	}
+	result, err := CreatePredictions(request, metaStorage, dataStorage, solutionStorage, modelStorage)
+	if err != nil {
+		return err
+	}
+	run.ProduceRequestID = result.ProduceRequestID
+
+	// the exported file is the output of the run so the dataset ingested for it is
+	// removed once the predictions are written
+	defer deletePredictionDataset(result.ProduceRequestID, metaStorage, dataStorage, solutionStorage)
+
+	extension, output, err := s.exporter(result.ProduceRequestID, schedule.Format)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(schedule.OutputPath, 0755)
+	if err != nil {
+		return errors.Wrapf(err, "unable to create output folder '%s'", schedule.OutputPath)
+	}
+	outputPath := path.Join(schedule.OutputPath, fmt.Sprintf("%s.%s", runName, extension))
+	err = ioutil.WriteFile(outputPath, output, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "unable to write prediction results to '%s'", outputPath)
+	}
+	run.OutputPath = outputPath
+
+	return nil
+}
+
+func deletePredictionDataset(produceRequestID string, metaStorage api.MetadataStorage,
+	dataStorage api.DataStorage, solutionStorage api.SolutionStorage) {
+	prediction, err := solutionStorage.FetchPrediction(produceRequestID)
+	if err != nil {
+		log.Warnf("unable to find the dataset of prediction '%s': %v", produceRequestID, err)
+		return
+	}
+	ds, err := metaStorage.FetchDataset(prediction.Dataset, true, true, true)
+	if err != nil {
+		log.Warnf("unable to fetch prediction dataset '%s': %v", prediction.Dataset, err)
+		return
+	}
+	err = DeleteDataset(ds, metaStorage, dataStorage, false)
+	if err != nil {
+		log.Warnf("unable to delete prediction dataset '%s': %v", prediction.Dataset, err)
+	}
+}
+
+// resolveSchedulePath cleans a schedule input or output path, which is only allowed
+// to refer to a location within the configured input or output data folders.
+func resolveSchedulePath(schedulePath string) (string, error) {
+	config, err := env.LoadConfig()
+	if err != nil {
+		return "", err
+	}
+
+	resolved, err := filepath.Abs(schedulePath)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to resolve path '%s'", schedulePath)
+	}
+	for _, folder := range []string{config.D3MInputDir, config.D3MOutputDir} {
+		root, err := filepath.Abs(folder)
+		if err != nil {
+			return "", errors.Wrapf(err, "unable to resolve data folder '%s'", folder)
+		}
+		if resolved == root || strings.HasPrefix(resolved, root+string(filepath.Separator)) {
+			return resolved, nil
+		}
+	}
+
+	return "", errors.Errorf("path '%s' is not within the input or output data folders", schedulePath)
+}
+
+// resolveScheduleInputs lists the inputs of a schedule.  A folder provides all of the
+// files it contains, skipping hidden files and sub folders, while anything else is
+// treated as a glob.
+func resolveScheduleInputs(inputPath string) ([]string, error) {
+	info, err := os.Stat(inputPath)
+	if err == nil && info.IsDir() {
+		files, err := ioutil.ReadDir(inputPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read input folder '%s'", inputPath)
+		}
+		inputs := []string{}
+		for _, f := range files {
+			if !f.IsDir() && !strings.HasPrefix(f.Name(), ".") {
+				inputs = append(inputs, path.Join(inputPath, f.Name()))
+			}
+		}
+		return inputs, nil
+	}
+
+	inputs, err := filepath.Glob(inputPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid input path '%s'", inputPath)
+	}
+	sort.Strings(inputs)
+
+	return inputs, nil
+}
+
+func newPredictionScheduleRun(schedule *api.PredictionSchedule, inputPath string) *api.PredictionScheduleRun {
+	return &api.PredictionScheduleRun{
+		RunID:      uuid.Must(uuid.NewV4()).String(),
+		ScheduleID: schedule.ScheduleID,
+		InputPath:  inputPath,
+		Status:     api.PredictionRunRunning,
+		StartTime:  time.Now(),
+	}
+}
+
+func completePredictionScheduleRun(run *api.PredictionScheduleRun, persist func(*api.PredictionScheduleRun) error, err error) {
+	run.EndTime = time.Now()
+	run.Status = api.PredictionRunComplete
+	if err != nil {
+		log.Errorf("run of prediction schedule '%s' on '%s' failed: %+v", run.ScheduleID, run.InputPath, err)
+		run.Status = api.PredictionRunFailed
+		run.Error = err.Error()
+	}
+
+	err = persist(run)
+	if err != nil {
+		log.Errorf("unable to persist run of prediction schedule '%s': %+v", run.ScheduleID, err)
+	}
+}
@@ -0,0 +1,47 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uncharted-distil/distil/api/env"
+)
+
+func TestResolveSchedulePath(t *testing.T) {
+	cfg, err := env.LoadConfig()
+	assert.NoError(t, err)
+
+	resolved, err := resolveSchedulePath(path.Join(cfg.D3MInputDir, "incoming", "*.csv"))
+	assert.NoError(t, err)
+	expected, _ := filepath.Abs(path.Join(cfg.D3MInputDir, "incoming", "*.csv"))
+	assert.Equal(t, expected, resolved)
+
+	_, err = resolveSchedulePath(path.Join(cfg.D3MOutputDir, "predictions"))
+	assert.NoError(t, err)
+
+	// paths escaping the data folders are rejected
+	_, err = resolveSchedulePath(path.Join(cfg.D3MOutputDir, "..", "..", "predictions"))
+	assert.Error(t, err)
+	_, err = resolveSchedulePath(cfg.D3MInputDir + "-other")
+	assert.Error(t, err)
+	_, err = resolveSchedulePath("/etc")
+	assert.Error(t, err)
+}
@@ -103,7 +103,7 @@ func Query(params QueryParams) (map[string]interface{}, error) {
 	}
 
 	// submit the pipeline with no cache
-	resultURI, err := submitPipeline([]string{ds.LearningDataset, datasetPath}, desc, false)
+	resultURI, err := submitPipeline([]string{ds.LearningDataset, datasetPath}, desc, false, pipelinePriorityInteractive, ds.ID, "query")
 	if err != nil {
 		return nil, err
 	}
@@ -45,7 +45,7 @@ func Rank(schemaPath string, dataset string, config *IngestTaskConfig) (string,
 		return "", errors.Wrap(err, "unable to create PCA pipeline")
 	}
 
-	datasetURI, err := submitPipeline([]string{schemaDoc}, pip, true)
+	datasetURI, err := submitPipeline([]string{schemaDoc}, pip, true, pipelinePriorityBatch, dataset, "rank")
 	if err != nil {
 		return "", errors.Wrap(err, "unable to run PCA pipeline")
 	}
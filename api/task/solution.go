@@ -18,6 +18,7 @@ package task
 import (
 	"context"
 
+	"github.com/pkg/errors"
 	"github.com/uncharted-distil/distil-compute/model"
 	api "github.com/uncharted-distil/distil/api/model"
 )
@@ -83,6 +84,24 @@ func SaveFittedSolution(fittedSolutionID string, modelName string, modelDescript
 		}
 	}
 
+	// the metrics come from the solution the fitted solution was produced by
+	results, err := solutionStorage.FetchSolutionResultsByFittedSolutionID(fittedSolutionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, errors.Errorf("no results found for fitted solution '%s'", fittedSolutionID)
+	}
+	metrics, err := solutionStorage.FetchSolutionScores(results[0].SolutionID)
+	if err != nil {
+		return nil, err
+	}
+
+	lineage, err := FetchLineage(request.Dataset, metadataStorage)
+	if err != nil {
+		return nil, err
+	}
+
 	return &api.ExportedModel{
 		FilePath:         uri,
 		FittedSolutionID: fittedSolutionID,
@@ -93,6 +112,8 @@ func SaveFittedSolution(fittedSolutionID string, modelName string, modelDescript
 		Target:           target,
 		ModelName:        modelName,
 		ModelDescription: modelDescription,
+		Lineage:          lineage,
+		Metrics:          metrics,
 	}, nil
 }
 
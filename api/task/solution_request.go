@@ -0,0 +1,87 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package task
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/distil-compute/model"
+	"github.com/uncharted-distil/distil-compute/primitive/compute"
+	log "github.com/unchartedsoftware/plog"
+
+	comp "github.com/uncharted-distil/distil/api/compute"
+	"github.com/uncharted-distil/distil/api/env"
+	api "github.com/uncharted-distil/distil/api/model"
+)
+
+// CreateSolutionSearch parses a raw solution request, fills in the defaults for any
+// unspecified search parameters and starts the search as a server side job.
+func CreateSolutionSearch(client *compute.Client, data []byte, metaStorage api.MetadataStorage,
+	dataStorage api.DataStorage, solutionStorage api.SolutionStorage) (*comp.SolutionSearch, error) {
+	dataset, err := comp.ExtractDatasetFromRawRequest(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to pull dataset from request")
+	}
+
+	vars, err := metaStorage.FetchVariables(dataset, false, true, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to pull variables from storage")
+	}
+
+	// unmarshal request
+	request, err := comp.NewSolutionRequest(vars, data)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to unmarshal create solutions request")
+	}
+
+	// load defaults
+	config, _ := env.LoadConfig()
+	if len(request.Task) == 0 {
+		request.Task = comp.DefaultTaskType(request.TargetFeature.Type, request.ProblemType)
+		log.Infof("Defaulting task type to `%s`", request.Task)
+	}
+	if len(request.Metrics) == 0 {
+		request.Metrics = comp.DefaultMetrics(request.Task)
+		log.Infof("Defaulting metrics to `%s`", strings.Join(request.Metrics, ","))
+	}
+	if request.MaxTime == 0 {
+		request.MaxTime = config.SolutionSearchMaxTime
+		log.Infof("Defaulting max search time to `%d`", request.MaxTime)
+	}
+
+	// set augmentation info
+	requestDataset, err := metaStorage.FetchDataset(request.Dataset, true, true, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to pull joined dataset")
+	}
+
+	if requestDataset.JoinSuggestions != nil {
+		request.DatasetAugmentations = make([]*model.DatasetOrigin, len(requestDataset.JoinSuggestions))
+		for i, js := range requestDataset.JoinSuggestions {
+			request.DatasetAugmentations[i] = js.DatasetOrigin
+		}
+	}
+
+	// persist the request information and dispatch the request - the search runs
+	// server side from here on, independent of the caller
+	search, err := comp.StartSolutionSearch(request, client, solutionStorage, metaStorage, dataStorage)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to dispatch solution request to TA2")
+	}
+
+	return search, nil
+}
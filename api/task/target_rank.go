@@ -83,7 +83,7 @@ func TargetRank(dataset *api.Dataset, target string, features []*model.Variable,
 		return nil, errors.Errorf("path \"%s\" cannot be made absolute", datasetInputDir)
 	}
 
-	datasetURI, err := submitPipeline([]string{datasetInputDir}, pip, true)
+	datasetURI, err := submitPipeline([]string{datasetInputDir}, pip, true, pipelinePriorityInteractive, dataset.ID, "target-rank")
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to run ranking pipeline")
 	}
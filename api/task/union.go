@@ -52,7 +52,7 @@ func VerticalConcat(dataStorage apiModel.DataStorage, joinLeft *JoinSpec, joinRi
 	leftFolder := path.Base(joinLeft.DatasetPath)
 	rightFolder := path.Base(joinRight.DatasetPath)
 	datasetPath := env.ResolvePath(metadata.Augmented, fmt.Sprintf("%s-union-%s", leftFolder, rightFolder))
-	datasetPath, _, err = join(joinLeft, joinRight, datasetPath, pipelineDesc, unionPaths, defaultSubmitter{}, true)
+	datasetPath, _, err = join(joinLeft, joinRight, datasetPath, pipelineDesc, unionPaths, defaultSubmitter{requester: joinLeft.DatasetID}, true)
 	if err != nil {
 		return "", nil, err
 	}
@@ -0,0 +1,172 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package cron
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maximum time searched for the next activation, which covers the rarest
+// valid expression (the 29th of February on a given weekday)
+const maxSearchYears = 28
+
+type field struct {
+	name string
+	min  int
+	max  int
+}
+
+var fields = []field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+// Schedule is a parsed five field cron expression (minute, hour, day of month,
+// month and day of week).  Each field can be a '*', a value, a range, a list
+// of these, and have a step.  As with cron, when both the day of month and the
+// day of week are restricted, a day matching either of them is a match.
+type Schedule struct {
+	Expression string
+	minutes    map[int]bool
+	hours      map[int]bool
+	days       map[int]bool
+	months     map[int]bool
+	weekdays   map[int]bool
+	anyDay     bool
+	anyWeekday bool
+}
+
+// Parse parses a five field cron expression.
+func Parse(expression string) (*Schedule, error) {
+	parts := strings.Fields(expression)
+	if len(parts) != len(fields) {
+		return nil, errors.Errorf("cron expression '%s' needs %d fields", expression, len(fields))
+	}
+
+	values := make([]map[int]bool, len(fields))
+	for i, f := range fields {
+		parsed, err := parseField(parts[i], f)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse cron expression '%s'", expression)
+		}
+		values[i] = parsed
+	}
+
+	// sunday can be written as either 0 or 7
+	weekdays := map[int]bool{}
+	for d := range values[4] {
+		weekdays[d%7] = true
+	}
+
+	return &Schedule{
+		Expression: expression,
+		minutes:    values[0],
+		hours:      values[1],
+		days:       values[2],
+		months:     values[3],
+		weekdays:   weekdays,
+		anyDay:     strings.HasPrefix(parts[2], "*"),
+		anyWeekday: strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+// Next returns the first activation time strictly after the given time, or the
+// zero time if the schedule never activates.
+func (s *Schedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	end := t.AddDate(maxSearchYears, 0, 0)
+	for t.Before(end) {
+		if !s.months[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.hours[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.minutes[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (s *Schedule) matchDay(t time.Time) bool {
+	day := s.days[t.Day()]
+	weekday := s.weekdays[int(t.Weekday())]
+	if s.anyDay || s.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
+
+func parseField(value string, f field) (map[int]bool, error) {
+	matches := map[int]bool{}
+	for _, item := range strings.Split(value, ",") {
+		step := 1
+		if index := strings.Index(item, "/"); index >= 0 {
+			var err error
+			step, err = strconv.Atoi(item[index+1:])
+			if err != nil || step <= 0 {
+				return nil, errors.Errorf("invalid %s step '%s'", f.name, item)
+			}
+			item = item[:index]
+		}
+
+		start, end := f.min, f.max
+		if item != "*" {
+			bounds := strings.SplitN(item, "-", 2)
+			var err error
+			start, err = strconv.Atoi(bounds[0])
+			if err != nil {
+				return nil, errors.Errorf("invalid %s '%s'", f.name, item)
+			}
+			end = start
+			if len(bounds) == 2 {
+				end, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					return nil, errors.Errorf("invalid %s '%s'", f.name, item)
+				}
+			} else if step > 1 {
+				// a single value with a step runs to the end of the range
+				end = f.max
+			}
+		}
+		if start < f.min || end > f.max || start > end {
+			return nil, errors.Errorf("%s '%s' is outside of %d-%d", f.name, item, f.min, f.max)
+		}
+
+		for i := start; i <= end; i += step {
+			matches[i] = true
+		}
+	}
+
+	return matches, nil
+}
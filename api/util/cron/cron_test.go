@@ -0,0 +1,77 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	_, err := Parse("0 2 * * *")
+	assert.NoError(t, err)
+	_, err = Parse("*/15 0-6,22 1 */3 1-5")
+	assert.NoError(t, err)
+
+	_, err = Parse("0 2 * *")
+	assert.Error(t, err)
+	_, err = Parse("60 2 * * *")
+	assert.Error(t, err)
+	_, err = Parse("0 5-2 * * *")
+	assert.Error(t, err)
+	_, err = Parse("*/0 * * * *")
+	assert.Error(t, err)
+}
+
+func TestNext(t *testing.T) {
+	start := time.Date(2021, time.March, 10, 14, 30, 20, 0, time.UTC)
+
+	// nightly
+	schedule, err := Parse("0 2 * * *")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2021, time.March, 11, 2, 0, 0, 0, time.UTC), schedule.Next(start))
+
+	// strictly after the current activation
+	assert.Equal(t, time.Date(2021, time.March, 12, 2, 0, 0, 0, time.UTC), schedule.Next(time.Date(2021, time.March, 11, 2, 0, 0, 0, time.UTC)))
+
+	// every quarter hour
+	schedule, err = Parse("*/15 * * * *")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2021, time.March, 10, 14, 45, 0, 0, time.UTC), schedule.Next(start))
+
+	// sundays, written as 7
+	schedule, err = Parse("30 6 * * 7")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2021, time.March, 14, 6, 30, 0, 0, time.UTC), schedule.Next(start))
+
+	// day of month or day of week when both are restricted
+	schedule, err = Parse("0 0 1 * 5")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2021, time.March, 12, 0, 0, 0, 0, time.UTC), schedule.Next(start))
+	assert.Equal(t, time.Date(2021, time.April, 1, 0, 0, 0, 0, time.UTC), schedule.Next(time.Date(2021, time.March, 26, 0, 0, 0, 0, time.UTC)))
+
+	// leap day
+	schedule, err = Parse("0 0 29 2 *")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC), schedule.Next(start))
+
+	// never
+	schedule, err = Parse("0 0 31 2 *")
+	assert.NoError(t, err)
+	assert.True(t, schedule.Next(start).IsZero())
+}
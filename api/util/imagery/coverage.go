@@ -0,0 +1,177 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package imagery
+
+import (
+	"github.com/pkg/errors"
+	"github.com/uncharted-distil/gdal"
+)
+
+const (
+	// CloudBrightnessThreshold is the normalized mean visible band value at or above which
+	// a pixel is considered to be cloud when no quality band is available.
+	CloudBrightnessThreshold = 0.3
+)
+
+// Coverage captures the fraction of an image that is obscured by cloud or holds no data.
+// The cloud fraction is relative to the pixels holding data.
+type Coverage struct {
+	CloudFraction  float64
+	NodataFraction float64
+}
+
+type raster struct {
+	values []uint16
+	width  int
+	height int
+	nodata uint16
+}
+
+// ComputeCoverage determines the cloud and nodata fractions of an image.  The quality band
+// is used when supplied, otherwise nodata pixels are those where every band holds its nodata
+// value and clouds are estimated from the brightness of the visible bands.  Band files are
+// keyed by band label (ie b02).
+func ComputeCoverage(sensor *SensorProfile, bandFiles map[string]string, qualityFile string) (*Coverage, error) {
+	if qualityFile != "" && sensor.QualityBand != nil {
+		return computeQualityCoverage(sensor.QualityBand, qualityFile)
+	}
+
+	// use the visible bands if all are present since they also drive the brightness heuristic
+	checkClouds := len(sensor.VisibleBands) > 0
+	files := []string{}
+	for _, label := range sensor.VisibleBands {
+		file, ok := bandFiles[label]
+		if !ok {
+			checkClouds = false
+			break
+		}
+		files = append(files, file)
+	}
+	if !checkClouds {
+		files = []string{}
+		for _, file := range bandFiles {
+			files = append(files, file)
+		}
+	}
+	if len(files) == 0 {
+		return nil, errors.Errorf("no band files available to compute coverage")
+	}
+
+	// read all bands at the resolution of the first
+	rasters := make([]*raster, len(files))
+	width := 0
+	height := 0
+	for i, file := range files {
+		r, err := readRaster(file, width, height)
+		if err != nil {
+			return nil, err
+		}
+		width = r.width
+		height = r.height
+		rasters[i] = r
+	}
+
+	nodataCount := 0
+	cloudCount := 0
+	maxValue := float64(sensor.Max) * float64(len(rasters))
+	for i := 0; i < width*height; i++ {
+		nodata := true
+		sum := 0.0
+		for _, r := range rasters {
+			if r.values[i] != r.nodata {
+				nodata = false
+			}
+			sum += float64(r.values[i])
+		}
+		if nodata {
+			nodataCount++
+		} else if checkClouds && sum/maxValue >= CloudBrightnessThreshold {
+			cloudCount++
+		}
+	}
+
+	return newCoverage(width*height, nodataCount, cloudCount), nil
+}
+
+func computeQualityCoverage(quality *QualityBand, qualityFile string) (*Coverage, error) {
+	r, err := readRaster(qualityFile, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	nodataCount := 0
+	cloudCount := 0
+	for _, v := range r.values {
+		if quality.IsNodata(v) {
+			nodataCount++
+		} else if quality.IsCloud(v) {
+			cloudCount++
+		}
+	}
+
+	return newCoverage(len(r.values), nodataCount, cloudCount), nil
+}
+
+func newCoverage(total int, nodataCount int, cloudCount int) *Coverage {
+	coverage := &Coverage{}
+	if total == 0 {
+		return coverage
+	}
+	coverage.NodataFraction = float64(nodataCount) / float64(total)
+	if total > nodataCount {
+		coverage.CloudFraction = float64(cloudCount) / float64(total-nodataCount)
+	}
+
+	return coverage
+}
+
+// readRaster reads the first band of an image, resampling it to the supplied size.  A size
+// of 0 reads the raster at its native resolution.
+func readRaster(filePath string, width int, height int) (*raster, error) {
+	dataset, err := gdal.Open(filePath, gdal.ReadOnly)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to open '%s'", filePath)
+	}
+	defer dataset.Close()
+
+	if dataset.RasterCount() == 0 {
+		return nil, errors.Errorf("no bands found in '%s'", filePath)
+	}
+	xSize := dataset.RasterXSize()
+	ySize := dataset.RasterYSize()
+	if width == 0 || height == 0 {
+		width = xSize
+		height = ySize
+	}
+
+	band := dataset.RasterBand(1)
+	r := &raster{
+		values: make([]uint16, width*height),
+		width:  width,
+		height: height,
+	}
+	if nodata, ok := band.NoDataValue(); ok && nodata >= 0 && nodata <= 65535 {
+		r.nodata = uint16(nodata)
+	}
+
+	// gdal handles the conversion to 16 bit values as well as the resampling
+	err = band.IO(gdal.Read, 0, 0, xSize, ySize, r.values, width, height, 0, 0)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read band data from '%s'", filePath)
+	}
+
+	return r, nil
+}
@@ -0,0 +1,52 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package imagery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQualityBand(t *testing.T) {
+	sentinel, err := GetSensorProfile(Sentinel2)
+	assert.NoError(t, err)
+	assert.True(t, sentinel.IsQualityBand("S2A_MSIL2A_20190616T101031_SCL.tif"))
+	assert.False(t, sentinel.IsQualityBand("S2A_MSIL2A_20190616T101031_B02.tif"))
+	assert.True(t, sentinel.QualityBand.IsCloud(9))
+	assert.False(t, sentinel.QualityBand.IsCloud(4))
+	assert.True(t, sentinel.QualityBand.IsNodata(0))
+
+	landsat, err := GetSensorProfile(Landsat8)
+	assert.NoError(t, err)
+	assert.True(t, landsat.QualityBand.IsCloud(1<<3))
+	assert.False(t, landsat.QualityBand.IsCloud(1<<6))
+	assert.True(t, landsat.QualityBand.IsNodata(1))
+
+	planet, err := GetSensorProfile(PlanetScope)
+	assert.NoError(t, err)
+	assert.False(t, planet.IsQualityBand("20190616_SCL.tif"))
+}
+
+func TestNewCoverage(t *testing.T) {
+	coverage := newCoverage(100, 20, 40)
+	assert.InDelta(t, 0.2, coverage.NodataFraction, 1e-9)
+	assert.InDelta(t, 0.5, coverage.CloudFraction, 1e-9)
+
+	coverage = newCoverage(100, 100, 0)
+	assert.InDelta(t, 1.0, coverage.NodataFraction, 1e-9)
+	assert.InDelta(t, 0.0, coverage.CloudFraction, 1e-9)
+}
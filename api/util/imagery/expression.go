@@ -0,0 +1,400 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package imagery
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultExpressionMin is the expression value mapped to the start of the color ramp when no domain is supplied.
+	DefaultExpressionMin = -1.0
+
+	// DefaultExpressionMax is the expression value mapped to the end of the color ramp when no domain is supplied.
+	DefaultExpressionMax = 1.0
+)
+
+// BandExpression is a compiled band math expression such as `(b08-b04)/(b08+b04)`.
+// Expressions support numeric constants, band labels of the sensor, the `+ - * / ^`
+// operators, parentheses and the `abs`, `sqrt`, `min` and `max` functions.  Band values
+// are normalized to reflectance (0 - 1) using the sensor max before evaluation.
+type BandExpression struct {
+	Source string
+	// Bands lists the referenced band labels in the order their values are expected.
+	Bands []string
+	root  expressionNode
+}
+
+type expressionNode interface {
+	eval(values []float64) float64
+}
+
+type constantNode float64
+
+func (n constantNode) eval(values []float64) float64 {
+	return float64(n)
+}
+
+type bandNode int
+
+func (n bandNode) eval(values []float64) float64 {
+	return values[n]
+}
+
+type negateNode struct {
+	operand expressionNode
+}
+
+func (n *negateNode) eval(values []float64) float64 {
+	return -n.operand.eval(values)
+}
+
+type binaryNode struct {
+	op    byte
+	left  expressionNode
+	right expressionNode
+}
+
+func (n *binaryNode) eval(values []float64) float64 {
+	left := n.left.eval(values)
+	right := n.right.eval(values)
+	switch n.op {
+	case '+':
+		return left + right
+	case '-':
+		return left - right
+	case '*':
+		return left * right
+	case '/':
+		return left / right
+	default:
+		return math.Pow(left, right)
+	}
+}
+
+type callNode struct {
+	fn   func(args ...float64) float64
+	args []expressionNode
+}
+
+func (n *callNode) eval(values []float64) float64 {
+	args := make([]float64, len(n.args))
+	for i, a := range n.args {
+		args[i] = a.eval(values)
+	}
+	return n.fn(args...)
+}
+
+type expressionFunction struct {
+	arity int
+	fn    func(args ...float64) float64
+}
+
+var expressionFunctions = map[string]*expressionFunction{
+	"abs":  {1, func(args ...float64) float64 { return math.Abs(args[0]) }},
+	"sqrt": {1, func(args ...float64) float64 { return math.Sqrt(args[0]) }},
+	"min":  {2, func(args ...float64) float64 { return math.Min(args[0], args[1]) }},
+	"max":  {2, func(args ...float64) float64 { return math.Max(args[0], args[1]) }},
+}
+
+// CompileBandExpression parses the expression, validating band references against the
+// bands of the supplied sensor.
+func CompileBandExpression(expression string, sensor *SensorProfile) (*BandExpression, error) {
+	p := &expressionParser{
+		input:     expression,
+		sensor:    sensor,
+		bandIndex: map[string]int{},
+	}
+	p.next()
+
+	root, err := p.parseSum()
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse band expression '%s'", expression)
+	}
+	if p.token != "" {
+		return nil, errors.Errorf("unable to parse band expression '%s': unexpected '%s' at %d", expression, p.token, p.tokenPos)
+	}
+	if len(p.bands) == 0 {
+		return nil, errors.Errorf("band expression '%s' does not reference any bands", expression)
+	}
+
+	return &BandExpression{
+		Source: expression,
+		Bands:  p.bands,
+		root:   root,
+	}, nil
+}
+
+// Evaluate computes the expression using the supplied values, ordered as the expression bands.
+func (e *BandExpression) Evaluate(values ...float64) float64 {
+	return e.root.eval(values)
+}
+
+// NewExpressionBandCombination builds a band combination that renders the compiled expression
+// through a color ramp, mapping the [min, max] expression domain onto the ramp.
+func NewExpressionBandCombination(id string, displayName string, expression string, ramp string, min float64, max float64, sensor *SensorProfile) (*BandCombination, error) {
+	compiled, err := CompileBandExpression(expression, sensor)
+	if err != nil {
+		return nil, err
+	}
+	if min == 0 && max == 0 {
+		min = DefaultExpressionMin
+		max = DefaultExpressionMax
+	}
+	if max <= min {
+		return nil, errors.Errorf("band expression domain max (%f) must exceed min (%f)", max, min)
+	}
+
+	sensorMax := float64(sensor.Max)
+	transform := func(edges *OptramEdges, bandValues ...uint16) float64 {
+		values := make([]float64, len(bandValues))
+		for i, v := range bandValues {
+			values[i] = float64(v) / sensorMax
+		}
+		result := compiled.Evaluate(values...)
+		if math.IsNaN(result) {
+			return 0
+		}
+		return math.Max(0, math.Min((result-min)/(max-min), 1))
+	}
+
+	return &BandCombination{
+		ID:          BandCombinationID(id),
+		DisplayName: displayName,
+		Mapping:     compiled.Bands,
+		Ramp:        GetColorRamp(ramp),
+		Transform:   transform,
+	}, nil
+}
+
+type expressionParser struct {
+	input     string
+	pos       int
+	token     string
+	tokenPos  int
+	sensor    *SensorProfile
+	bands     []string
+	bandIndex map[string]int
+}
+
+// next advances to the next token, leaving an empty token at the end of the input.
+func (p *expressionParser) next() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+	p.tokenPos = p.pos
+	if p.pos >= len(p.input) {
+		p.token = ""
+		return
+	}
+
+	c := p.input[p.pos]
+	start := p.pos
+	switch {
+	case c >= '0' && c <= '9' || c == '.':
+		for p.pos < len(p.input) && (isDigit(p.input[p.pos]) || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		// scientific notation
+		if p.pos < len(p.input) && (p.input[p.pos] == 'e' || p.input[p.pos] == 'E') {
+			p.pos++
+			if p.pos < len(p.input) && (p.input[p.pos] == '+' || p.input[p.pos] == '-') {
+				p.pos++
+			}
+			for p.pos < len(p.input) && isDigit(p.input[p.pos]) {
+				p.pos++
+			}
+		}
+	case unicode.IsLetter(rune(c)):
+		for p.pos < len(p.input) && (unicode.IsLetter(rune(p.input[p.pos])) || isDigit(p.input[p.pos]) || p.input[p.pos] == '_') {
+			p.pos++
+		}
+	default:
+		p.pos++
+	}
+	p.token = p.input[start:p.pos]
+}
+
+func (p *expressionParser) expect(token string) error {
+	if p.token != token {
+		if p.token == "" {
+			return errors.Errorf("expected '%s' but reached the end of the expression", token)
+		}
+		return errors.Errorf("expected '%s' but found '%s' at %d", token, p.token, p.tokenPos)
+	}
+	p.next()
+	return nil
+}
+
+func (p *expressionParser) parseSum() (expressionNode, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for p.token == "+" || p.token == "-" {
+		op := p.token[0]
+		p.next()
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op, left, right}
+	}
+	return left, nil
+}
+
+func (p *expressionParser) parseProduct() (expressionNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.token == "*" || p.token == "/" {
+		op := p.token[0]
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op, left, right}
+	}
+	return left, nil
+}
+
+func (p *expressionParser) parseUnary() (expressionNode, error) {
+	if p.token == "-" {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &negateNode{operand}, nil
+	}
+	if p.token == "+" {
+		p.next()
+		return p.parseUnary()
+	}
+	return p.parsePower()
+}
+
+func (p *expressionParser) parsePower() (expressionNode, error) {
+	base, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if p.token == "^" {
+		p.next()
+		// right associative
+		exponent, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &binaryNode{'^', base, exponent}, nil
+	}
+	return base, nil
+}
+
+func (p *expressionParser) parsePrimary() (expressionNode, error) {
+	token := p.token
+	pos := p.tokenPos
+	switch {
+	case token == "":
+		return nil, errors.New("unexpected end of expression")
+	case token == "(":
+		p.next()
+		node, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		err = p.expect(")")
+		if err != nil {
+			return nil, err
+		}
+		return node, nil
+	case isDigit(token[0]) || token[0] == '.':
+		value, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return nil, errors.Errorf("invalid number '%s' at %d", token, pos)
+		}
+		p.next()
+		return constantNode(value), nil
+	case unicode.IsLetter(rune(token[0])):
+		p.next()
+		name := strings.ToLower(token)
+		if fn, ok := expressionFunctions[name]; ok {
+			return p.parseCall(name, fn)
+		}
+		return p.parseBand(name, pos)
+	default:
+		return nil, errors.Errorf("unexpected '%s' at %d", token, pos)
+	}
+}
+
+func (p *expressionParser) parseCall(name string, fn *expressionFunction) (expressionNode, error) {
+	err := p.expect("(")
+	if err != nil {
+		return nil, err
+	}
+	args := []expressionNode{}
+	for {
+		arg, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.token != "," {
+			break
+		}
+		p.next()
+	}
+	err = p.expect(")")
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != fn.arity {
+		return nil, errors.Errorf("function '%s' expects %d arguments but got %d", name, fn.arity, len(args))
+	}
+	return &callNode{fn.fn, args}, nil
+}
+
+func (p *expressionParser) parseBand(name string, pos int) (expressionNode, error) {
+	if idx, ok := p.bandIndex[name]; ok {
+		return bandNode(idx), nil
+	}
+	found := false
+	for _, band := range p.sensor.Bands {
+		if band == name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, errors.Errorf("unknown %s band '%s' at %d", p.sensor.ID, name, pos)
+	}
+
+	idx := len(p.bands)
+	p.bands = append(p.bands, name)
+	p.bandIndex[name] = idx
+	return bandNode(idx), nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,62 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package imagery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompileBandExpression(t *testing.T) {
+	sensor, err := GetSensorProfile(Sentinel2)
+	assert.NoError(t, err)
+
+	evi, err := CompileBandExpression("2.5*(b08-b04)/(b08+6*b04-7.5*b02+1)", sensor)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b08", "b04", "b02"}, evi.Bands)
+	assert.InDelta(t, 2.5*(0.5-0.1)/(0.5+6*0.1-7.5*0.05+1), evi.Evaluate(0.5, 0.1, 0.05), 1e-9)
+
+	expr, err := CompileBandExpression("-B03^2 + max(abs(b04 - b03), sqrt(4)) * 1e1", sensor)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b03", "b04"}, expr.Bands)
+	assert.InDelta(t, -4.0+20.0, expr.Evaluate(2, 1), 1e-9)
+
+	_, err = CompileBandExpression("(b08-b04", sensor)
+	assert.Error(t, err)
+	_, err = CompileBandExpression("b08 b04", sensor)
+	assert.Error(t, err)
+	_, err = CompileBandExpression("b99/b04", sensor)
+	assert.Error(t, err)
+	_, err = CompileBandExpression("min(b08)", sensor)
+	assert.Error(t, err)
+	_, err = CompileBandExpression("2*3", sensor)
+	assert.Error(t, err)
+}
+
+func TestExpressionBandCombination(t *testing.T) {
+	sensor, err := GetSensorProfile(Sentinel2)
+	assert.NoError(t, err)
+
+	combination, err := NewExpressionBandCombination("ndvi2", "NDVI", "(b08-b04)/(b08+b04)", "viridis", 0, 0, sensor)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b08", "b04"}, combination.Mapping)
+	assert.InDelta(t, 0.5, combination.Transform(nil, 5000, 5000), 1e-9)
+	assert.InDelta(t, 0.0, combination.Transform(nil, 0, 0), 1e-9)
+
+	_, err = NewExpressionBandCombination("bad", "bad", "b08", "", 1, 1, sensor)
+	assert.Error(t, err)
+}
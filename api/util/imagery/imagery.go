@@ -213,9 +213,17 @@ func Initialize(config *env.Config) {
 	log.Infof("imagery utils initialized")
 }
 
+// ClearCache drops all cached images.  Needed when band combinations are redefined.
+func ClearCache() {
+	if cache != nil {
+		cache.Purge()
+	}
+}
+
 // ImageCacheKey stores the fields used to generate an image hash key.
 type ImageCacheKey struct {
 	DatasetDir      string
+	Sensor          string
 	BandCombination string
 	ImageScale      *ImageScale
 	Ramp            string
@@ -223,16 +231,27 @@ type ImageCacheKey struct {
 	BandsMapped     []string
 }
 
-// ImageFromCombination takes a base dataset directory, fileID and a band combination label and
-// returns a composed image.  NOTE: Currently a bit hardcoded for sentinel-2 data.
-func ImageFromCombination(datasetDir string, bandFileMapping map[string]string, bandCombo string, imageScale ImageScale, edges *OptramEdges, ramp string, options ...Options) (*image.RGBA, error) {
-	// attempt to get the folder file type for the supplied dataset dir from the cache, if
-	// not do the look up
+// ImageFromCombination takes a base dataset directory, the sensor profile of the imagery, fileID and
+// a band combination label and returns a composed image.
+func ImageFromCombination(datasetDir string, sensor *SensorProfile, bandFileMapping map[string]string, bandCombo string, imageScale ImageScale, edges *OptramEdges, ramp string, options ...Options) (*image.RGBA, error) {
 	bandCombination := strings.ToLower(string(BandCombinationID(bandCombo)))
+	combination, ok := sensor.BandCombinations[bandCombination]
+	if !ok {
+		return nil, errors.Errorf("unhandled band combination %s for sensor %s", bandCombination, sensor.ID)
+	}
+
+	return ImageFromBandCombination(datasetDir, sensor, bandFileMapping, combination, imageScale, edges, ramp, options...)
+}
 
+// ImageFromBandCombination takes a base dataset directory, the sensor profile of the imagery, fileID and
+// a band combination and returns a composed image.
+func ImageFromBandCombination(datasetDir string, sensor *SensorProfile, bandFileMapping map[string]string, bandCombo *BandCombination, imageScale ImageScale, edges *OptramEdges, ramp string, options ...Options) (*image.RGBA, error) {
+	// attempt to get the folder file type for the supplied dataset dir from the cache, if
+	// not do the look up
 	keyStruct := ImageCacheKey{
 		DatasetDir:      datasetDir,
-		BandCombination: bandCombination,
+		Sensor:          sensor.ID,
+		BandCombination: string(bandCombo.ID),
 		ImageScale:      &imageScale,
 		Ramp:            ramp,
 		Options:         options,
@@ -264,43 +283,39 @@ func ImageFromCombination(datasetDir string, bandFileMapping map[string]string,
 
 	// map the band files to the inputs
 	filePaths := []string{}
-	if bandCombo, ok := SentinelBandCombinations[bandCombination]; ok {
-		for _, bandLabel := range bandCombo.Mapping {
-			filePaths = append(filePaths, path.Join(datasetDir, bandFileMapping[bandLabel]))
-		}
-
-		imageRamp := bandCombo.Ramp
-
-		if ramp != "" {
-			imageRamp = GetColorRamp(ramp)
-		}
+	for _, bandLabel := range bandCombo.Mapping {
+		filePaths = append(filePaths, path.Join(datasetDir, bandFileMapping[bandLabel]))
+	}
 
-		image, err := ImageFromBands(filePaths, imageRamp, bandCombo.Transform, imageScale, edges, bandCombo.AdvancedColorModel, options...)
+	imageRamp := bandCombo.Ramp
 
-		if err != nil {
-			return nil, err
-		}
-		if cache != nil {
-			cache.Add(cacheKey, image)
-		}
+	if ramp != "" {
+		imageRamp = GetColorRamp(ramp)
+	}
 
-		return image, nil
+	image, err := ImageFromBands(filePaths, sensor.Max, imageRamp, bandCombo.Transform, imageScale, edges, bandCombo.AdvancedColorModel, options...)
+	if err != nil {
+		return nil, err
+	}
+	if cache != nil {
+		cache.Add(cacheKey, image)
 	}
 
-	return nil, errors.Errorf("unhandled band combination %s", bandCombination)
+	return image, nil
 }
 
 // ImageFromBands loads band data from the file paths array into a single RGB image,
 // where the file names map to R,G,B in order.  The results are returned as a JPEG
-// encoded byte stream. If errors are encountered processing a band an attempt will
-// be made to create the image from the remaining bands, while logging an error.
-func ImageFromBands(paths []string, ramp []uint8, transform func(*OptramEdges, ...uint16) float64, imageScale ImageScale, edges *OptramEdges, advancedColorModel bool, options ...Options) (*image.RGBA, error) {
+// encoded byte stream. Band values are clamped to, and normalized by, the supplied max.
+// If errors are encountered processing a band an attempt will be made to create the
+// image from the remaining bands, while logging an error.
+func ImageFromBands(paths []string, maxValue uint16, ramp []uint8, transform func(*OptramEdges, ...uint16) float64, imageScale ImageScale, edges *OptramEdges, advancedColorModel bool, options ...Options) (*image.RGBA, error) {
 	bandImages := []*image.Gray16{}
 	maxXSize := 0
 	maxYSize := 0
 
 	for _, filePath := range paths {
-		bandImage, err := loadAsGray16(filePath)
+		bandImage, err := loadAsGray16(filePath, maxValue)
 		bandImages = append(bandImages, bandImage)
 		if err != nil {
 			return nil, err
@@ -326,7 +341,7 @@ func ImageFromBands(paths []string, ramp []uint8, transform func(*OptramEdges, .
 	// a transform and color lookup
 	if ramp == nil || transform == nil {
 		// Create an RGBA image from the resized bands
-		return createRGBAFromBands(maxXSize, maxYSize, bandImages, advancedColorModel, options...), nil
+		return createRGBAFromBands(maxXSize, maxYSize, bandImages, maxValue, advancedColorModel, options...), nil
 	}
 	return createRGBAFromRamp(maxXSize, maxYSize, bandImages, transform, ramp, edges), nil
 }
@@ -434,7 +449,7 @@ func getMaxDimensions(bandImages *[]*image.Gray16) (int, int) {
 	return width, height
 }
 
-func loadAsGray16(filePath string) (*image.Gray16, error) {
+func loadAsGray16(filePath string, maxValue uint16) (*image.Gray16, error) {
 	// Load each of the datasets
 	dataset, err := gdal.Open(filePath, gdal.ReadOnly)
 	if err != nil {
@@ -478,8 +493,8 @@ func loadAsGray16(filePath string) (*image.Gray16, error) {
 	// crappy for now - go image lib stores its gray16 as [uint8, uint8] so we need an extra copy here
 	badCount := 0
 	for i, grayVal := range buffer {
-		if grayVal > Sentinel2Max {
-			grayVal = Sentinel2Max
+		if grayVal > maxValue {
+			grayVal = maxValue
 			badCount++
 		}
 		// decompose the 16-bit value into 8 bit values with a big endian ordering as per the image lib
@@ -494,7 +509,7 @@ func loadAsGray16(filePath string) (*image.Gray16, error) {
 	return bandImage, nil
 }
 
-func createRGBAFromBands(xSize int, ySize int, bandImages []*image.Gray16, advancedColorModel bool, options ...Options) *image.RGBA {
+func createRGBAFromBands(xSize int, ySize int, bandImages []*image.Gray16, maxValue uint16, advancedColorModel bool, options ...Options) *image.RGBA {
 	// Create a new RGBA image to hold the collected bands
 	outputImage := image.NewRGBA(image.Rect(0, 0, xSize, ySize))
 
@@ -506,7 +521,7 @@ func createRGBAFromBands(xSize int, ySize int, bandImages []*image.Gray16, advan
 		for j, bandImage := range bandImages {
 			if bandImage != nil {
 				grayValue16 := uint16(bandImage.Pix[i])<<8 | uint16(bandImage.Pix[i+1])
-				bandBuffer[j] = float64(grayValue16) / Sentinel2Max
+				bandBuffer[j] = float64(grayValue16) / float64(maxValue)
 			} else {
 				bandBuffer[j] = 0.5
 			}
@@ -39,7 +39,7 @@ func TestImageFromCombination(t *testing.T) {
 		"b12": "S2A_MSIL2A_20171121T112351_79_21_B12.tif",
 	}
 
-	composedImage, err := ImageFromCombination("../test/bigearthnet", bandMap, NaturalColors2, ImageScale{}, &OptramEdges{}, "")
+	composedImage, err := ImageFromCombination("../test/bigearthnet", sensorProfiles[Sentinel2], bandMap, NaturalColors2, ImageScale{}, &OptramEdges{}, "")
 
 	assert.NoError(t, err)
 	assert.NotNil(t, composedImage)
@@ -59,7 +59,7 @@ func TestImageFromBandsTrueColor(t *testing.T) {
 		"../test/bigearthnet/S2A_MSIL2A_20171121T112351_79_21_B04.tif",
 		"../test/bigearthnet/S2A_MSIL2A_20171121T112351_79_21_B03.tif",
 		"../test/bigearthnet/S2A_MSIL2A_20171121T112351_79_21_B02.tif",
-	}, nil, nil, ImageScale{}, &OptramEdges{}, true)
+	}, Sentinel2Max, nil, nil, ImageScale{}, &OptramEdges{}, true)
 	assert.NoError(t, err)
 	assert.NotNil(t, composedImage)
 	assert.True(t, len(composedImage.Pix) > 0)
@@ -79,7 +79,7 @@ func TestImageFromBandsResize(t *testing.T) {
 		"../test/bigearthnet/S2A_MSIL2A_20171121T112351_79_21_B12.tif",
 		"../test/bigearthnet/S2A_MSIL2A_20171121T112351_79_21_B08.tif",
 		"../test/bigearthnet/S2A_MSIL2A_20171121T112351_79_21_B04.tif",
-	}, nil, nil, ImageScale{}, &OptramEdges{}, true)
+	}, Sentinel2Max, nil, nil, ImageScale{}, &OptramEdges{}, true)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, composedImage)
@@ -99,7 +99,7 @@ func TestImageFromRamp(t *testing.T) {
 	composedImage, err := ImageFromBands([]string{
 		"../test/bigearthnet/S2A_MSIL2A_20171121T112351_79_21_B08.tif",
 		"../test/bigearthnet/S2A_MSIL2A_20171121T112351_79_21_B11.tif",
-	}, BrownYellowBlueRamp, SentinelBandCombinations[NDMI].Transform, ImageScale{}, &OptramEdges{}, true)
+	}, Sentinel2Max, BrownYellowBlueRamp, SentinelBandCombinations[NDMI].Transform, ImageScale{}, &OptramEdges{}, true)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, composedImage)
@@ -119,7 +119,7 @@ func TestImageFromRampClamped(t *testing.T) {
 	composedImage, err := ImageFromBands([]string{
 		"../test/bigearthnet/S2A_MSIL2A_20171121T112351_79_21_B08.tif",
 		"../test/bigearthnet/S2A_MSIL2A_20171121T112351_79_21_B04.tif",
-	}, RedYellowGreenRamp, SentinelBandCombinations[NDVI].Transform, ImageScale{}, &OptramEdges{}, true)
+	}, Sentinel2Max, RedYellowGreenRamp, SentinelBandCombinations[NDVI].Transform, ImageScale{}, &OptramEdges{}, true)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, composedImage)
@@ -140,7 +140,7 @@ func TestImageFromBandsMissing(t *testing.T) {
 		"../test/bigearthnet/S2A_MSIL2A_20171121T112351_79_21_B12.tif",
 		"../test/bigearthnet/S2A_MSIL2A_20171121T112351_79_21_B08.tif",
 		"../test/bigearthnet/S2A_MSIL2A_20171121T112351_79_21_B04.tif",
-	}, nil, SentinelBandCombinations[ShortwaveInfrared].Transform, ImageScale{}, &OptramEdges{}, true)
+	}, Sentinel2Max, nil, SentinelBandCombinations[ShortwaveInfrared].Transform, ImageScale{}, &OptramEdges{}, true)
 	assert.NoError(t, err)
 	assert.NotNil(t, composedImage)
 	assert.True(t, len(composedImage.Pix) > 0)
@@ -0,0 +1,216 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package imagery
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// Sentinel2 identifies the ESA Sentinel-2 MSI sensor profile.
+	Sentinel2 = "sentinel-2"
+
+	// Landsat8 identifies the USGS Landsat 8/9 OLI sensor profile.  Values are expected to be collection 2
+	// level 2 surface reflectance.
+	Landsat8 = "landsat-8"
+
+	// PlanetScope identifies the 4 band PlanetScope analytic surface reflectance sensor profile.
+	PlanetScope = "planetscope"
+
+	// DefaultSensor is the sensor profile assumed when none is specified.
+	DefaultSensor = Sentinel2
+
+	// Landsat8Max is the collection 2 level 2 surface reflectance value corresponding to a reflectance of 1.
+	Landsat8Max = 43636
+
+	// PlanetScopeMax is the maximum expected value stored in a PlanetScope surface reflectance band.
+	PlanetScopeMax = 10000
+)
+
+// SensorProfile captures the band naming, value range and renderable band combinations
+// of an imaging sensor.
+type SensorProfile struct {
+	ID          string
+	DisplayName string
+	// Bands lists the band labels (as used in band combination mappings) in sensor order.
+	Bands []string
+	// BandPattern matches the band portion of a band file name.  The first capture group holds
+	// the band label without the `b` prefix.
+	BandPattern *regexp.Regexp
+	// Max is the largest expected radiometric value, used to normalize band values.
+	Max uint16
+	// MultiBandMapping maps 1-based raster band indices of a multiband file to band labels
+	// when the bands are split into separate files.  Bands mapped to an empty label are dropped.
+	MultiBandMapping map[int]string
+	// BandCombinations lists the band combinations that can be rendered for the sensor.
+	BandCombinations map[string]*BandCombination
+	// VisibleBands lists the blue, green and red band labels used by the cloud brightness heuristic.
+	VisibleBands []string
+	// QualityBand describes the per pixel classification file delivered with the imagery, if any.
+	QualityBand *QualityBand
+}
+
+// QualityBand describes a per pixel scene classification or quality assessment band.
+type QualityBand struct {
+	// Pattern matches the quality portion of a quality band file name.
+	Pattern  *regexp.Regexp
+	IsCloud  func(value uint16) bool
+	IsNodata func(value uint16) bool
+}
+
+var (
+	sensorProfiles = map[string]*SensorProfile{}
+)
+
+func init() {
+	// band combinations reference color ramps so profiles need to be built in init
+	landsatBandCombinations := map[string]*BandCombination{
+		NaturalColors1:         {NaturalColors1, "Natural Colors", []string{"b4", "b3", "b2"}, nil, nil, false},
+		NaturalColors2:         {NaturalColors2, "Natural Colors 2", []string{"b4", "b3", "b2"}, nil, nil, true},
+		FalseColorInfrared:     {FalseColorInfrared, "False Color Infrared", []string{"b5", "b4", "b3"}, nil, nil, false},
+		FalseColorUrban:        {FalseColorUrban, "False Color Urban", []string{"b7", "b6", "b4"}, nil, nil, false},
+		Agriculture:            {Agriculture, "Agriculture", []string{"b6", "b5", "b2"}, nil, nil, false},
+		AtmosphericPenetration: {AtmosphericPenetration, "Atmospheric Penetration", []string{"b7", "b6", "b5"}, nil, nil, false},
+		HealthyVegetation:      {HealthyVegetation, "Healthy Vegetation", []string{"b5", "b6", "b2"}, nil, nil, false},
+		LandWater:              {LandWater, "Land/Water", []string{"b5", "b6", "b4"}, nil, nil, false},
+		AtmosphericRemoval:     {AtmosphericRemoval, "Atmospheric Removal", []string{"b7", "b5", "b3"}, nil, nil, false},
+		ShortwaveInfrared:      {ShortwaveInfrared, "Shortwave Infrared", []string{"b7", "b5", "b4"}, nil, nil, false},
+		VegetationAnalysis:     {VegetationAnalysis, "Vegetation Analysis", []string{"b6", "b5", "b4"}, nil, nil, false},
+		NDVI:                   {NDVI, "Normalized Difference Vegetation Index", []string{"b5", "b4"}, RedYellowGreenRamp, ClampedNormalizingTransform, false},
+		NDMI:                   {NDMI, "Normalized Difference Moisture Index ", []string{"b5", "b6"}, BrownYellowBlueRamp, NormalizingTransform, false},
+		NDWI:                   {NDWI, "Normalized Difference Water Index", []string{"b3", "b5"}, BrownYellowBlueRamp, NormalizingTransform, false},
+		NSMI:                   {NSMI, "Normalized Soil Moisture Index", []string{"b6", "b7"}, BrownYellowBlueRamp, NormalizingTransform, false},
+		MNDWI:                  {MNDWI, "Modified Normalized Difference Water Index", []string{"b3", "b6"}, BrownYellowBlueRamp, NormalizingTransform, false},
+		RSWIR:                  {RSWIR, "Red and Shortwave Infrared", []string{"b4", "b6"}, BrownYellowBlueRamp, NormalizingTransform, false},
+		OPTRAM:                 {OPTRAM, "OPTRAM", []string{"b5", "b4", "b7"}, RedYellowGreenRamp, OptramTransform, false},
+	}
+
+	planetScopeBandCombinations := map[string]*BandCombination{
+		NaturalColors1:     {NaturalColors1, "Natural Colors", []string{"b3", "b2", "b1"}, nil, nil, false},
+		NaturalColors2:     {NaturalColors2, "Natural Colors 2", []string{"b3", "b2", "b1"}, nil, nil, true},
+		FalseColorInfrared: {FalseColorInfrared, "False Color Infrared", []string{"b4", "b3", "b2"}, nil, nil, false},
+		NDVI:               {NDVI, "Normalized Difference Vegetation Index", []string{"b4", "b3"}, RedYellowGreenRamp, ClampedNormalizingTransform, false},
+		NDWI:               {NDWI, "Normalized Difference Water Index", []string{"b2", "b4"}, BrownYellowBlueRamp, NormalizingTransform, false},
+	}
+
+	sensorProfiles = map[string]*SensorProfile{
+		Sentinel2: {
+			ID:          Sentinel2,
+			DisplayName: "Sentinel-2",
+			Bands:       []string{"b01", "b02", "b03", "b04", "b05", "b06", "b07", "b08", "b8a", "b09", "b10", "b11", "b12"},
+			BandPattern: regexp.MustCompile(`_B([0-9][0-9a-zA-Z])[.]`),
+			Max:         Sentinel2Max,
+			// eurosat drops cloud layer, has the 8A layer and offsets everything else.
+			MultiBandMapping: map[int]string{
+				10: "",
+				13: "8A",
+			},
+			BandCombinations: SentinelBandCombinations,
+			VisibleBands:     []string{"b02", "b03", "b04"},
+			QualityBand: &QualityBand{
+				// level 2A scene classification layer
+				Pattern: regexp.MustCompile(`_SCL[.]`),
+				IsCloud: func(value uint16) bool {
+					// cloud shadows, medium & high probability clouds and thin cirrus
+					return value == 3 || value == 8 || value == 9 || value == 10
+				},
+				IsNodata: func(value uint16) bool {
+					return value == 0
+				},
+			},
+		},
+		Landsat8: {
+			ID:               Landsat8,
+			DisplayName:      "Landsat 8/9",
+			Bands:            []string{"b1", "b2", "b3", "b4", "b5", "b6", "b7", "b8", "b9", "b10", "b11"},
+			BandPattern:      regexp.MustCompile(`_[Bb]([0-9]{1,2})[.]`),
+			Max:              Landsat8Max,
+			MultiBandMapping: map[int]string{1: "1", 2: "2", 3: "3", 4: "4", 5: "5", 6: "6", 7: "7", 8: "8", 9: "9", 10: "10", 11: "11"},
+			BandCombinations: landsatBandCombinations,
+			VisibleBands:     []string{"b2", "b3", "b4"},
+			QualityBand: &QualityBand{
+				// collection 2 pixel quality assessment bit flags
+				Pattern: regexp.MustCompile(`_QA_PIXEL[.]`),
+				IsCloud: func(value uint16) bool {
+					// dilated cloud, cirrus, cloud and cloud shadow bits
+					return value&0x1e != 0
+				},
+				IsNodata: func(value uint16) bool {
+					// fill bit
+					return value&0x1 != 0
+				},
+			},
+		},
+		PlanetScope: {
+			ID:               PlanetScope,
+			DisplayName:      "PlanetScope",
+			Bands:            []string{"b1", "b2", "b3", "b4"},
+			BandPattern:      regexp.MustCompile(`_B([1-4])[.]`),
+			Max:              PlanetScopeMax,
+			MultiBandMapping: map[int]string{1: "1", 2: "2", 3: "3", 4: "4"},
+			BandCombinations: planetScopeBandCombinations,
+			VisibleBands:     []string{"b1", "b2", "b3"},
+		},
+	}
+}
+
+// GetSensorProfile returns the sensor profile matching the supplied ID.  An empty ID
+// resolves to the default (sentinel 2) profile for datasets ingested before sensors
+// were tracked.
+func GetSensorProfile(id string) (*SensorProfile, error) {
+	if id == "" {
+		id = DefaultSensor
+	}
+	profile, ok := sensorProfiles[strings.ToLower(id)]
+	if !ok {
+		return nil, errors.Errorf("unrecognized sensor '%s'", id)
+	}
+
+	return profile, nil
+}
+
+// GetSensorProfiles returns all known sensor profiles sorted by ID.
+func GetSensorProfiles() []*SensorProfile {
+	profiles := make([]*SensorProfile, 0, len(sensorProfiles))
+	for _, p := range sensorProfiles {
+		profiles = append(profiles, p)
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].ID < profiles[j].ID
+	})
+
+	return profiles
+}
+
+// ExtractBand returns the lower case band label (without the `b` prefix) found in
+// the supplied file name.
+func (s *SensorProfile) ExtractBand(filename string) (string, bool) {
+	match := s.BandPattern.FindStringSubmatch(filename)
+	if len(match) < 2 {
+		return "", false
+	}
+
+	return strings.ToLower(match[1]), true
+}
+
+// IsQualityBand returns true if the supplied file name is the quality band of the sensor.
+func (s *SensorProfile) IsQualityBand(filename string) bool {
+	return s.QualityBand != nil && s.QualityBand.Pattern.MatchString(filename)
+}
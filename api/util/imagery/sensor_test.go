@@ -0,0 +1,42 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package imagery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSensorProfileExtractBand(t *testing.T) {
+	sentinel, err := GetSensorProfile("")
+	assert.NoError(t, err)
+	assert.Equal(t, Sentinel2, sentinel.ID)
+	band, ok := sentinel.ExtractBand("S2A_MSIL2A_20190616T101031_B8A.tif")
+	assert.True(t, ok)
+	assert.Equal(t, "8a", band)
+
+	landsat, err := GetSensorProfile(Landsat8)
+	assert.NoError(t, err)
+	band, ok = landsat.ExtractBand("LC08_L2SP_044034_20200106_B10.TIF")
+	assert.True(t, ok)
+	assert.Equal(t, "10", band)
+	_, ok = landsat.ExtractBand("LC08_L2SP_044034_20200106_QA.TIF")
+	assert.False(t, ok)
+
+	_, err = GetSensorProfile("modis")
+	assert.Error(t, err)
+}
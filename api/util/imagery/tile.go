@@ -0,0 +1,155 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package imagery
+
+import (
+	"hash/fnv"
+	"image"
+	"image/color"
+	"math"
+)
+
+const (
+	// TileSize is the pixel width and height of a web mercator map tile.
+	TileSize = 256
+)
+
+var (
+	// categoricalPalette is used to colour categorical predictions in tile overlays.
+	categoricalPalette = []color.RGBA{
+		{31, 119, 180, 255},
+		{255, 127, 14, 255},
+		{44, 160, 44, 255},
+		{214, 39, 40, 255},
+		{148, 103, 189, 255},
+		{140, 86, 75, 255},
+		{227, 119, 194, 255},
+		{127, 127, 127, 255},
+		{188, 189, 34, 255},
+		{23, 190, 207, 255},
+	}
+)
+
+// TileChip is an image with known geographic (lon / lat) bounds to be placed into a map tile.
+// An optional overlay colour is blended over the image.
+type TileChip struct {
+	Image   *image.RGBA
+	MinX    float64
+	MinY    float64
+	MaxX    float64
+	MaxY    float64
+	Overlay *color.RGBA
+}
+
+// TileBounds returns the lon / lat bounds of the XYZ web mercator tile as min x, min y, max x, max y.
+func TileBounds(z int, x int, y int) (float64, float64, float64, float64) {
+	n := math.Exp2(float64(z))
+	minX := float64(x)/n*360.0 - 180.0
+	maxX := float64(x+1)/n*360.0 - 180.0
+	maxY := tileLatitude(float64(y), n)
+	minY := tileLatitude(float64(y+1), n)
+	return minX, minY, maxX, maxY
+}
+
+// ValidTile returns true if the XYZ coordinates identify an existing tile.
+func ValidTile(z int, x int, y int) bool {
+	if z < 0 || z > 30 {
+		return false
+	}
+	n := 1 << uint(z)
+	return x >= 0 && x < n && y >= 0 && y < n
+}
+
+func tileLatitude(y float64, n float64) float64 {
+	return math.Atan(math.Sinh(math.Pi*(1-2*y/n))) * 180.0 / math.Pi
+}
+
+// TilePixelSize returns the size in tile pixels covered by the supplied lon / lat bounds.
+func TilePixelSize(z int, minX float64, minY float64, maxX float64, maxY float64) (float64, float64) {
+	n := math.Exp2(float64(z)) * TileSize
+	width := (maxX - minX) / 360.0 * n
+	height := (mercatorY(minY) - mercatorY(maxY)) * n
+	return width, height
+}
+
+// mercatorY projects a latitude onto the [0, 1] web mercator y axis (0 at the top).
+func mercatorY(lat float64) float64 {
+	latRad := lat * math.Pi / 180.0
+	return (1 - math.Log(math.Tan(latRad)+1/math.Cos(latRad))/math.Pi) / 2
+}
+
+// MosaicTile composes the chips into a single transparent RGBA tile.  Chips are
+// sampled per tile pixel so only the portion within the tile is processed.
+// Overlays are blended with the supplied opacity (0 - 255).
+func MosaicTile(z int, x int, y int, chips []*TileChip, overlayOpacity uint8) *image.RGBA {
+	tile := image.NewRGBA(image.Rect(0, 0, TileSize, TileSize))
+	minX, _, maxX, _ := TileBounds(z, x, y)
+	n := math.Exp2(float64(z))
+	alpha := float64(overlayOpacity) / 255.0
+
+	// precompute the geographic coordinates of each pixel center
+	lons := make([]float64, TileSize)
+	lats := make([]float64, TileSize)
+	for i := 0; i < TileSize; i++ {
+		offset := (float64(i) + 0.5) / TileSize
+		lons[i] = minX + (maxX-minX)*offset
+		lats[i] = tileLatitude(float64(y)+offset, n)
+	}
+
+	for _, chip := range chips {
+		if chip.Image == nil || chip.MaxX <= chip.MinX || chip.MaxY <= chip.MinY {
+			continue
+		}
+		bounds := chip.Image.Bounds()
+		srcWidth := float64(bounds.Dx())
+		srcHeight := float64(bounds.Dy())
+		for py := 0; py < TileSize; py++ {
+			lat := lats[py]
+			if lat < chip.MinY || lat >= chip.MaxY {
+				continue
+			}
+			sy := bounds.Min.Y + int((chip.MaxY-lat)/(chip.MaxY-chip.MinY)*srcHeight)
+			for px := 0; px < TileSize; px++ {
+				lon := lons[px]
+				if lon < chip.MinX || lon >= chip.MaxX {
+					continue
+				}
+				sx := bounds.Min.X + int((lon-chip.MinX)/(chip.MaxX-chip.MinX)*srcWidth)
+				src := chip.Image.RGBAAt(sx, sy)
+				if chip.Overlay != nil {
+					src.R = blend(src.R, chip.Overlay.R, alpha)
+					src.G = blend(src.G, chip.Overlay.G, alpha)
+					src.B = blend(src.B, chip.Overlay.B, alpha)
+				}
+				tile.SetRGBA(px, py, src)
+			}
+		}
+	}
+
+	return tile
+}
+
+func blend(base uint8, overlay uint8, alpha float64) uint8 {
+	return uint8(float64(base)*(1-alpha) + float64(overlay)*alpha)
+}
+
+// CategoryColor returns a stable colour for a categorical value.
+func CategoryColor(category string) *color.RGBA {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(category))
+	c := categoricalPalette[h.Sum32()%uint32(len(categoricalPalette))]
+	return &c
+}
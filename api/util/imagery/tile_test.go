@@ -0,0 +1,58 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package imagery
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTileBounds(t *testing.T) {
+	minX, minY, maxX, maxY := TileBounds(0, 0, 0)
+	assert.InDelta(t, -180.0, minX, 1e-9)
+	assert.InDelta(t, 180.0, maxX, 1e-9)
+	assert.InDelta(t, -85.0511, minY, 1e-4)
+	assert.InDelta(t, 85.0511, maxY, 1e-4)
+
+	minX, minY, maxX, maxY = TileBounds(1, 1, 0)
+	assert.InDelta(t, 0.0, minX, 1e-9)
+	assert.InDelta(t, 180.0, maxX, 1e-9)
+	assert.InDelta(t, 0.0, minY, 1e-9)
+	assert.InDelta(t, 85.0511, maxY, 1e-4)
+
+	assert.True(t, ValidTile(2, 3, 3))
+	assert.False(t, ValidTile(2, 4, 0))
+	assert.False(t, ValidTile(-1, 0, 0))
+}
+
+func TestMosaicTile(t *testing.T) {
+	chipImage := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	for i := range chipImage.Pix {
+		chipImage.Pix[i] = 255
+	}
+	// chip covering the north east quadrant of the world tile
+	chip := &TileChip{Image: chipImage, MinX: 0, MinY: 0, MaxX: 180, MaxY: 85.0511}
+	tile := MosaicTile(0, 0, 0, []*TileChip{chip}, 0)
+	assert.Equal(t, color.RGBA{255, 255, 255, 255}, tile.RGBAAt(TileSize-1, 0))
+	assert.Equal(t, color.RGBA{}, tile.RGBAAt(0, TileSize-1))
+
+	chip.Overlay = &color.RGBA{0, 0, 0, 255}
+	tile = MosaicTile(0, 0, 0, []*TileChip{chip}, 255)
+	assert.Equal(t, color.RGBA{0, 0, 0, 255}, tile.RGBAAt(TileSize-1, 0))
+}
@@ -45,6 +45,7 @@ type Connection struct {
 	conn    *websocket.Conn
 	mu      *sync.Mutex
 	handler requestHandler
+	closed  chan struct{}
 }
 
 // NewConnection returns a pointer to a new tile dispatcher object.
@@ -58,6 +59,7 @@ func NewConnection(w http.ResponseWriter, r *http.Request, handler requestHandle
 		conn:    conn,
 		handler: handler,
 		mu:      &sync.Mutex{},
+		closed:  make(chan struct{}),
 	}, nil
 }
 
@@ -95,4 +97,10 @@ func (c *Connection) Close() {
 	defer c.mu.Unlock()
 	// close websocket connection
 	c.conn.Close()
+	close(c.closed)
+}
+
+// Closed returns a channel that is closed when the connection is closed.
+func (c *Connection) Closed() <-chan struct{} {
+	return c.closed
 }
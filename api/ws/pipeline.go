@@ -17,42 +17,24 @@ package ws
 
 import (
 	"fmt"
-	"io/ioutil"
 	"net/http"
-	"path"
-	"strings"
-	"sync"
 
 	"github.com/pkg/errors"
 	log "github.com/unchartedsoftware/plog"
 
-	"github.com/uncharted-distil/distil-compute/metadata"
-	"github.com/uncharted-distil/distil-compute/model"
 	"github.com/uncharted-distil/distil-compute/primitive/compute"
 	api "github.com/uncharted-distil/distil/api/compute"
-	"github.com/uncharted-distil/distil/api/dataset"
-	"github.com/uncharted-distil/distil/api/env"
 	apiModel "github.com/uncharted-distil/distil/api/model"
 	"github.com/uncharted-distil/distil/api/task"
 	jutil "github.com/uncharted-distil/distil/api/util/json"
 )
 
 const (
-	createSolutions = "CREATE_SOLUTIONS"
-	stopSolutions   = "STOP_SOLUTIONS"
-	predict         = "PREDICT"
-	query           = "QUERY"
-)
-
-var (
-	// shared map of running requests - accessed by message status handlers that are
-	// run under separate go routines so needs to be locked
-	requestMap = struct {
-		sync.RWMutex
-		m map[string]*api.SolutionRequest
-	}{
-		m: map[string]*api.SolutionRequest{},
-	}
+	createSolutions    = "CREATE_SOLUTIONS"
+	subscribeSolutions = "SUBSCRIBE_SOLUTIONS"
+	stopSolutions      = "STOP_SOLUTIONS"
+	predict            = "PREDICT"
+	query              = "QUERY"
 )
 
 // SolutionHandler represents a solution websocket handler.
@@ -100,6 +82,9 @@ func handleMessage(conn *Connection, client *compute.Client, metadataCtor apiMod
 	case createSolutions:
 		handleCreateSolutions(conn, client, metadataCtor, dataCtor, solutionCtor, msg)
 		return
+	case subscribeSolutions:
+		handleSubscribeSolutions(conn, solutionCtor, msg)
+		return
 	case stopSolutions:
 		handleStopSolutions(conn, client, msg)
 		return
@@ -116,12 +101,6 @@ func handleMessage(conn *Connection, client *compute.Client, metadataCtor apiMod
 
 func handleCreateSolutions(conn *Connection, client *compute.Client, metadataCtor apiModel.MetadataStorageCtor,
 	dataCtor apiModel.DataStorageCtor, solutionCtor apiModel.SolutionStorageCtor, msg *Message) {
-	dataset, err := api.ExtractDatasetFromRawRequest(msg.Body)
-	if err != nil {
-		handleErr(conn, msg, errors.Wrap(err, "unable to pull dataset from request"))
-		return
-	}
-
 	// initialize the storage
 	dataStorage, err := dataCtor()
 	if err != nil {
@@ -143,95 +122,54 @@ func handleCreateSolutions(conn *Connection, client *compute.Client, metadataCto
 		return
 	}
 
-	vars, err := metaStorage.FetchVariables(dataset, false, true, false)
+	// the search runs server side from here on, independent of this connection
+	search, err := task.CreateSolutionSearch(client, msg.Body, metaStorage, dataStorage, solutionStorage)
 	if err != nil {
-		handleErr(conn, msg, errors.Wrap(err, "unable to pull variables from storage"))
-		return
-	}
-
-	// unmarshal request
-	request, err := api.NewSolutionRequest(vars, msg.Body)
-	if err != nil {
-		handleErr(conn, msg, errors.Wrap(err, "unable to unmarshal create solutions request"))
+		handleErr(conn, msg, err)
 		return
 	}
 
-	// load defaults
-	config, _ := env.LoadConfig()
-	if len(request.Task) == 0 {
-		request.Task = api.DefaultTaskType(request.TargetFeature.Type, request.ProblemType)
-		log.Infof("Defaulting task type to `%s`", request.Task)
-	}
-	if len(request.Metrics) == 0 {
-		request.Metrics = api.DefaultMetrics(request.Task)
-		log.Infof("Defaulting metrics to `%s`", strings.Join(request.Metrics, ","))
-	}
-	if request.MaxTime == 0 {
-		request.MaxTime = config.SolutionSearchMaxTime
-		log.Infof("Defaulting max search time to `%d`", request.MaxTime)
-	}
+	listenSolutionSearch(conn, solutionStorage, search.Request.RequestID, msg)
+}
 
-	// set augmentation info
-	requestDataset, err := metaStorage.FetchDataset(request.Dataset, true, true, false)
+func handleSubscribeSolutions(conn *Connection, solutionCtor apiModel.SolutionStorageCtor, msg *Message) {
+	// unmarshal request
+	request, err := api.NewSubscribeSolutionSearchRequest(msg.Body)
 	if err != nil {
-		handleErr(conn, msg, errors.Wrap(err, "unable to pull joined dataset"))
+		handleErr(conn, msg, errors.Wrap(err, "unable to unmarshal subscribe solutions request"))
 		return
 	}
 
-	if requestDataset.JoinSuggestions != nil {
-		request.DatasetAugmentations = make([]*model.DatasetOrigin, len(requestDataset.JoinSuggestions))
-		for i, js := range requestDataset.JoinSuggestions {
-			request.DatasetAugmentations[i] = js.DatasetOrigin
-		}
-	}
-
-	// persist the request information and dispatch the request
-	err = request.PersistAndDispatch(client, solutionStorage, metaStorage, dataStorage)
+	// initialize solution storage
+	solutionStorage, err := solutionCtor()
 	if err != nil {
-		handleErr(conn, msg, errors.Wrap(err, "unable to dispatch solution request to TA2"))
+		handleErr(conn, msg, errors.Wrap(err, "unable to initialize solution storage"))
 		return
 	}
 
-	// listen for solution updates - handler runs under a separate go routine
-	requestFinished := make(chan api.SolutionStatus, 1)
-	defer close(requestFinished)
-	err = request.Listen(func(status api.SolutionStatus) {
-
-		// update the map of currently running requests - this is crappy because go's
-		// read/write locks are not upgradable or re-entrant
-		requestMap.RLock()
-		if _, ok := requestMap.m[status.RequestID]; !ok {
-			requestMap.RUnlock()
-			requestMap.Lock()
-			requestMap.m[status.RequestID] = request
-			requestMap.Unlock()
-		} else {
-			requestMap.RUnlock()
-		}
+	listenSolutionSearch(conn, solutionStorage, request.RequestID, msg)
+}
 
+// listenSolutionSearch sends the status updates of a solution search to the client, starting
+// with a replay of the updates sent so far, until either the search completes or the client goes away.
+func listenSolutionSearch(conn *Connection, solutionStorage apiModel.SolutionStorage, requestID string, msg *Message) {
+	// handler runs under a separate go routine
+	done, unsubscribe, err := api.SubscribeSolutionSearch(solutionStorage, requestID, func(status api.SolutionStatus) {
 		// send status to client - this includes any error status we encountered
 		handleSuccess(conn, msg, jutil.StructToMap(status))
-
-		// flag request as finished if it completed normally, or an error occurred
-		// note that normally can include a cancellation, as some pipelines may have completed successfully
-		if status.Progress == compute.RequestCompletedStatus || status.Progress == compute.RequestErroredStatus {
-			// remove completed
-			requestMap.Lock()
-			delete(requestMap.m, status.RequestID)
-			requestMap.Unlock()
-
-			requestFinished <- status
-		}
 	})
-	// something went wrong internally when setting up the request handling (downstream errors should come
-	// through as status messages handled by the listener)
 	if err != nil {
-		handleErr(conn, msg, errors.Wrap(err, "received internal error"))
+		handleErr(conn, msg, errors.Wrap(err, "unable to subscribe to solution request"))
 		return
 	}
+	defer unsubscribe()
 
 	// wait on request completed / request errored status before we move on
-	<-requestFinished
+	select {
+	case <-done:
+	case <-conn.Closed():
+		return
+	}
 
 	// complete the request
 	handleComplete(conn, msg)
@@ -247,11 +185,9 @@ func handleStopSolutions(conn *Connection, client *compute.Client, msg *Message)
 
 	// Cancel any pending fit, score or produce calls on each solution - this is done at
 	// the grpc level via the context cancel function since there isn't ta3ta2 api support for this.
-	requestMap.RLock()
-	req, ok := requestMap.m[request.RequestID]
-	requestMap.RUnlock()
-	if ok {
-		req.Cancel()
+	search := api.GetSolutionSearch(request.RequestID)
+	if search != nil {
+		search.Cancel()
 	}
 
 	// Dispatch stop search request to ta2.
@@ -352,111 +288,7 @@ func handlePredict(conn *Connection, client *compute.Client, metadataCtor apiMod
 		return
 	}
 
-	// get the solution id from the fitted solution ID
-	solutionResults, err := solutionStorage.FetchSolutionResultsByFittedSolutionID(request.FittedSolutionID)
-	if err != nil {
-		handleErr(conn, msg, errors.Wrap(err, "unable to fetch solution results fitted solution id"))
-		return
-	}
-	if len(solutionResults) == 0 {
-		handleErr(conn, msg, errors.Errorf("unable to map fitted solution id to dataset or solution id"))
-		return
-	}
-	sr := solutionResults[0]
-
-	// read the metadata of the original dataset
-	datasetES, err := metaStorage.FetchDataset(sr.Dataset, false, false, false)
-	if err != nil {
-		handleErr(conn, msg, errors.Wrap(err, "unable to fetch dataset from es"))
-		return
-	}
-
-	// get the source dataset from the fitted solution ID
-	req, err := solutionStorage.FetchRequestByFittedSolutionID(sr.FittedSolutionID)
-	if err != nil {
-		handleErr(conn, msg, err)
-		return
-	}
-
-	schemaPath := path.Join(env.ResolvePath(datasetES.Source, datasetES.Folder), compute.D3MDataSchema)
-	meta, err := metadata.LoadMetadataFromOriginalSchema(schemaPath, true)
-	if err != nil {
-		handleErr(conn, msg, errors.Wrap(err, "unable to load metadata from source dataset schema doc"))
-		return
-	}
-
-	var learningDataMeta *model.Metadata
-	if datasetES.LearningDataset != "" {
-		learningDataSchemaPath := path.Join(datasetES.LearningDataset, compute.D3MDataSchema)
-		learningDataMeta, err = metadata.LoadMetadataFromOriginalSchema(learningDataSchemaPath, false)
-		if err != nil {
-			handleErr(conn, msg, errors.Wrap(err, "unable to load metadata from source dataset schema doc"))
-			return
-		}
-	}
-
-	target := getTarget(req)
-
-	// In the case of grouped variables, the target will not be variable itself, but one of its property
-	// values.  We need to fetch using the original dataset, since it will have grouped variable info,
-	// and then resolve the actual target.
-	targetVar, err := metaStorage.FetchVariable(meta.ID, target)
-	if err != nil {
-		handleErr(conn, msg, err)
-		return
-	}
-
-	variables, err := metaStorage.FetchVariablesByName(req.Dataset, req.Filters.Variables, false, false, false)
-	if err != nil {
-		handleErr(conn, msg, err)
-		return
-	}
-
-	// resolve the task so we know what type of data we should be expecting
-	requestTask, err := api.ResolveTask(dataStorage, meta.StorageName, targetVar, variables)
-	if err != nil {
-		handleErr(conn, msg, err)
-		return
-	}
-
-	// config objects required for ingest
-	config, _ := env.LoadConfig()
-	ingestConfig := task.NewConfig(config)
-
-	predictParams := &task.PredictParams{
-		Meta:             meta,
-		LearningDataMeta: learningDataMeta,
-		Dataset:          request.DatasetID,
-		SolutionID:       sr.SolutionID,
-		FittedSolutionID: request.FittedSolutionID,
-		OutputPath:       path.Join(config.D3MOutputDir, config.AugmentedSubFolder),
-		Target:           targetVar,
-		MetaStorage:      metaStorage,
-		DataStorage:      dataStorage,
-		SolutionStorage:  solutionStorage,
-		ModelStorage:     modelStorage,
-		Config:           &config,
-		IngestConfig:     ingestConfig,
-		SourceDatasetID:  meta.ID,
-	}
-
-	datasetName, datasetPath, err := getPredictionDataset(requestTask, request, predictParams)
-	if err != nil {
-		handleErr(conn, msg, errors.Wrap(err, "unable to create raw dataset"))
-		return
-	}
-	predictParams.Dataset = datasetName
-	predictParams.SchemaPath = datasetPath
-
-	// run predictions - synchronous call for now
-	resultID, err := task.Predict(predictParams)
-	if err != nil {
-		handleErr(conn, msg, err)
-		return
-	}
-
-	// read the results from the database
-	result, err := solutionStorage.FetchPredictionResultByProduceRequestID(resultID)
+	result, err := task.CreatePredictions(request, metaStorage, dataStorage, solutionStorage, modelStorage)
 	if err != nil {
 		handleErr(conn, msg, err)
 		return
@@ -469,77 +301,3 @@ func handlePredict(conn *Connection, client *compute.Client, metadataCtor apiMod
 	// notify the client that we're done
 	handleComplete(conn, msg)
 }
-
-func getTarget(request *apiModel.Request) string {
-	for _, f := range request.Features {
-		if f.FeatureType == "target" {
-			return f.FeatureName
-		}
-	}
-
-	return ""
-}
-
-func createPredictionDataset(requestTask *api.Task, request *api.PredictRequest,
-	predictParams *task.PredictParams) (task.DatasetConstructor, []string, error) {
-	datasetID := request.DatasetID
-	datasetPath := request.DatasetPath
-	var ds task.DatasetConstructor
-	var err error
-	indexFields := []string{}
-	if api.HasTaskType(requestTask, compute.RemoteSensingTask) {
-		ds, err = dataset.NewSatelliteDataset(datasetID, "tif", datasetPath)
-		indexFields = dataset.GetSatelliteIndexFields()
-	} else if api.HasTaskType(requestTask, compute.ImageTask) {
-		ds, err = dataset.NewMediaDataset(datasetID, "png", "jpeg", datasetPath)
-	} else if api.HasTaskType(requestTask, compute.TimeSeriesTask) && api.HasTaskType(requestTask, compute.ForecastingTask) {
-		ds, err = task.NewPredictionTimeseriesDataset(predictParams, request.IntervalLength, request.IntervalCount)
-	} else {
-		var data []byte
-		data, err = ioutil.ReadFile(datasetPath)
-		if err != nil {
-			return nil, nil, errors.Wrapf(err, "unable to read raw tabular data")
-		}
-		ds, err = dataset.NewTableDataset(datasetID, data, false)
-	}
-	if err != nil {
-		return nil, nil, err
-	}
-
-	return ds, indexFields, nil
-}
-
-func getPredictionDataset(requestTask *api.Task, request *api.PredictRequest, predictParams *task.PredictParams) (string, string, error) {
-	// check if the dataset already exists
-	if request.ExistingDataset {
-		clonedID, clonedPath, err := task.PrepExistingPredictionDataset(predictParams)
-		if err != nil {
-			return "", "", err
-		}
-
-		return clonedID, path.Join(clonedPath, compute.D3MDataSchema), nil
-	}
-
-	// ingest the data as a new prediction dataset
-	ds, indexFields, err := createPredictionDataset(requestTask, request, predictParams)
-	if err != nil {
-		return "", "", err
-	}
-	predictParams.DatasetConstructor = ds
-	predictParams.IndexFields = indexFields
-	// import the dataset
-	datasetName, datasetPath, err := task.ImportPredictionDataset(predictParams)
-	if err != nil {
-		return "", "", err
-	}
-	predictParams.Dataset = datasetName
-	predictParams.SchemaPath = datasetPath
-
-	// ingest the dataset
-	err = task.IngestPredictionDataset(predictParams)
-	if err != nil {
-		return "", "", err
-	}
-
-	return predictParams.Dataset, predictParams.SchemaPath, nil
-}
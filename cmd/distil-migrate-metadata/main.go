@@ -0,0 +1,121 @@
+//
+//   Copyright © 2021 Uncharted Software Inc.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+// distil-migrate-metadata copies the dataset metadata, lineage and exported
+// models from the elasticsearch indices into postgres. It is safe to run
+// more than once: datasets and models are overwritten and lineage edges that
+// were already copied are skipped.
+package main
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	log "github.com/unchartedsoftware/plog"
+
+	"github.com/uncharted-distil/distil/api/elastic"
+	"github.com/uncharted-distil/distil/api/env"
+	es "github.com/uncharted-distil/distil/api/model/storage/elastic"
+	pg "github.com/uncharted-distil/distil/api/model/storage/postgres"
+	"github.com/uncharted-distil/distil/api/postgres"
+)
+
+func main() {
+	config, err := env.LoadConfig()
+	if err != nil {
+		log.Errorf("%+v", err)
+		os.Exit(1)
+	}
+
+	err = migrate(&config)
+	if err != nil {
+		log.Errorf("%+v", err)
+		os.Exit(1)
+	}
+}
+
+func migrate(config *env.Config) error {
+	source, err := es.NewExportStorage(config.ESDatasetsIndex, config.ESLineageIndex, config.ESModelsIndex,
+		elastic.NewClient(config.ElasticEndpoint, false))
+	if err != nil {
+		return errors.Wrap(err, "unable to connect to elasticsearch")
+	}
+
+	target, err := pg.NewMetadataStorageClient(postgres.NewClient(config.PostgresHost, config.PostgresPort, config.PostgresUser,
+		config.PostgresPassword, config.PostgresDatabase, config.PostgresLogLevel, false))
+	if err != nil {
+		return errors.Wrap(err, "unable to connect to postgres")
+	}
+	err = target.InitializeMetadataStorage()
+	if err != nil {
+		return err
+	}
+
+	datasets, err := source.ExportDatasets()
+	if err != nil {
+		return err
+	}
+	for _, ds := range datasets {
+		err = target.UpdateDataset(ds)
+		if err != nil {
+			return errors.Wrapf(err, "unable to copy dataset '%s'", ds.ID)
+		}
+	}
+	log.Infof("copied %d datasets from index '%s'", len(datasets), config.ESDatasetsIndex)
+
+	edges, err := source.ExportLineageEdges()
+	if err != nil {
+		return err
+	}
+	copied := map[string]map[string]bool{}
+	edgeCount := 0
+	for _, edge := range edges {
+		if copied[edge.Target] == nil {
+			existing, err := target.FetchLineageEdges(edge.Target)
+			if err != nil {
+				return err
+			}
+			copied[edge.Target] = map[string]bool{}
+			for _, e := range existing {
+				copied[edge.Target][e.ID] = true
+			}
+		}
+		if copied[edge.Target][edge.ID] {
+			continue
+		}
+
+		err = target.PersistLineageEdge(edge)
+		if err != nil {
+			return errors.Wrapf(err, "unable to copy lineage edge '%s'", edge.ID)
+		}
+		copied[edge.Target][edge.ID] = true
+		edgeCount++
+	}
+	log.Infof("copied %d of %d lineage edges from index '%s'", edgeCount, len(edges), config.ESLineageIndex)
+
+	models, err := source.ExportModels()
+	if err != nil {
+		return err
+	}
+	for _, m := range models {
+		err = target.PersistExportedModel(m)
+		if err != nil {
+			return errors.Wrapf(err, "unable to copy model '%s'", m.FittedSolutionID)
+		}
+	}
+	log.Infof("copied %d models from index '%s'", len(models), config.ESModelsIndex)
+
+	return nil
+}
@@ -3,12 +3,13 @@ module github.com/uncharted-distil/distil
 go 1.13
 
 require (
+	github.com/360EntSecGroup-Skylar/excelize/v2 v2.3.2
+	github.com/apache/arrow/go/arrow v0.0.0-20210521153258-78c88a9f517b
 	github.com/araddon/dateparse v0.0.0-20190622164848-0fb0a474d195
 	github.com/caarlos0/env v3.5.0+incompatible
 	github.com/davecgh/go-spew v1.1.1
 	github.com/gofrs/uuid v3.3.0+incompatible
 	github.com/golang/protobuf v1.4.2
-	github.com/google/go-cmp v0.5.2 // indirect
 	github.com/gorilla/websocket v1.4.2
 	github.com/h2non/filetype v1.0.12
 	github.com/hashicorp/golang-lru v0.5.4
@@ -16,11 +17,11 @@ require (
 	github.com/jackc/pgproto3/v2 v2.0.2
 	github.com/jackc/pgtype v1.4.0
 	github.com/jackc/pgx/v4 v4.7.1
-	github.com/klauspost/compress v1.11.0 // indirect
 	github.com/kr/text v0.2.0 // indirect
 	github.com/lucasb-eyer/go-colorful v1.0.3
 	github.com/mattn/go-colorable v0.1.8 // indirect
 	github.com/mattn/go-isatty v0.0.12
+	github.com/mattn/go-sqlite3 v1.14.16
 	github.com/mgutz/ansi v0.0.0-20200706080929-d51e80ef957d
 	github.com/microcosm-cc/bluemonday v1.0.16
 	github.com/mitchellh/hashstructure v1.0.0
@@ -45,6 +46,6 @@ require (
 	goji.io/v3 v3.0.0
 	golang.org/x/net v0.0.0-20210614182718-04defd469f4e
 	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1 // indirect
-	google.golang.org/grpc v1.27.0
+	google.golang.org/grpc v1.32.0
 	gopkg.in/check.v1 v1.0.0-20200227125254-8fa46927fb4f // indirect
 )
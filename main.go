@@ -40,10 +40,13 @@ import (
 	es "github.com/uncharted-distil/distil/api/model/storage/elastic"
 	"github.com/uncharted-distil/distil/api/model/storage/file"
 	pg "github.com/uncharted-distil/distil/api/model/storage/postgres"
+	"github.com/uncharted-distil/distil/api/model/storage/sqlbuilder"
+	sq "github.com/uncharted-distil/distil/api/model/storage/sqlite"
 	"github.com/uncharted-distil/distil/api/postgres"
 	"github.com/uncharted-distil/distil/api/rest"
 	"github.com/uncharted-distil/distil/api/routes"
 	"github.com/uncharted-distil/distil/api/service"
+	"github.com/uncharted-distil/distil/api/sqlite"
 	"github.com/uncharted-distil/distil/api/task"
 	"github.com/uncharted-distil/distil/api/util"
 	"github.com/uncharted-distil/distil/api/util/imagery"
@@ -66,6 +69,11 @@ func registerRoutePost(mux *goji.Mux, pattern string, handler func(http.Response
 	mux.HandleFunc(pat.Post(pattern), handler)
 }
 
+func registerRouteDelete(mux *goji.Mux, pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	log.Infof("Registering DELETE route %s", pattern)
+	mux.HandleFunc(pat.Delete(pattern), handler)
+}
+
 func validateULimit(config env.Config) {
 	var rLimit syscall.Rlimit
 
@@ -153,20 +161,39 @@ func main() {
 	postgresBatchClientCtor := postgres.NewClient(config.PostgresHost, config.PostgresPort, config.PostgresUser, config.PostgresPassword,
 		config.PostgresDatabase, "error", true)
 
-	// instantiate the metadata storage (using ES).
-	esMetadataStorageCtor := es.NewMetadataStorage(config.ESDatasetsIndex, false, esClientCtor)
-
-	// instantiate the exported model storage (using ES).
-	esExportedModelStorageCtor := es.NewExportedModelStorage(config.ESModelsIndex, false, esClientCtor)
+	// instantiate the embedded sqlite client constructor.
+	sqliteClientCtor := sqlite.NewClient(config.SQLitePath)
+
+	// instantiate the metadata and exported model storage (using ES, postgres or sqlite).
+	var metadataStorageCtor model.MetadataStorageCtor
+	var exportedModelStorageCtor model.ExportedModelStorageCtor
+	metadataProvenance := es.Provenance
+	if config.MetadataStorage == env.MetadataStoragePostgres {
+		metadataStorageCtor = pg.NewMetadataStorage(postgresClientCtor)
+		exportedModelStorageCtor = pg.NewExportedModelStorage(postgresClientCtor)
+		metadataProvenance = pg.MetadataProvenance
+	} else if config.MetadataStorage == env.MetadataStorageSQLite {
+		metadataStorageCtor = sq.NewMetadataStorage(sqliteClientCtor)
+		exportedModelStorageCtor = sq.NewExportedModelStorage(sqliteClientCtor)
+		metadataProvenance = sq.MetadataProvenance
+	} else {
+		metadataStorageCtor = es.NewMetadataStorage(config.ESDatasetsIndex, config.ESLineageIndex, false, esClientCtor)
+		exportedModelStorageCtor = es.NewExportedModelStorage(config.ESModelsIndex, false, esClientCtor)
+	}
 
 	// instantiate the metadata storage (using filesystem).
 	fileMetadataStorageCtor := file.NewMetadataStorage(config.D3MOutputDir)
 
-	// instantiate the postgres data storage constructor.
-	pgDataStorageCtor := pg.NewDataStorage(postgresClientCtor, postgresBatchClientCtor, esMetadataStorageCtor)
-
-	// instantiate the postgres solution storage constructor.
-	pgSolutionStorageCtor := pg.NewSolutionStorage(postgresClientCtor, esMetadataStorageCtor)
+	// instantiate the data and solution storage constructors (using postgres or sqlite).
+	var dataStorageCtor model.DataStorageCtor
+	var solutionStorageCtor model.SolutionStorageCtor
+	if config.DataStorage == env.DataStorageSQLite {
+		dataStorageCtor = sq.NewDataStorage(sqliteClientCtor, metadataStorageCtor)
+		solutionStorageCtor = sq.NewSolutionStorage(sqliteClientCtor, metadataStorageCtor)
+	} else {
+		dataStorageCtor = pg.NewDataStorage(postgresClientCtor, postgresBatchClientCtor, metadataStorageCtor)
+		solutionStorageCtor = pg.NewSolutionStorage(postgresClientCtor, metadataStorageCtor)
+	}
 
 	// Instantiate the solution compute client
 	solutionClient, err := task.NewDefaultClient(config, userAgent, discoveryLogger)
@@ -177,13 +204,17 @@ func main() {
 	defer solutionClient.Close()
 
 	// wait for required services.
-	servicesToWait["postgres"] = func() bool {
-		_, err := postgresClientCtor()
-		return err == nil
+	if config.DataStorage != env.DataStorageSQLite || config.MetadataStorage == env.MetadataStoragePostgres {
+		servicesToWait["postgres"] = func() bool {
+			_, err := postgresClientCtor()
+			return err == nil
+		}
 	}
-	servicesToWait["elastic"] = func() bool {
-		_, err := esClientCtor()
-		return err == nil
+	if config.MetadataStorage != env.MetadataStoragePostgres && config.MetadataStorage != env.MetadataStorageSQLite {
+		servicesToWait["elastic"] = func() bool {
+			_, err := esClientCtor()
+			return err == nil
+		}
 	}
 	servicesToWait["ta2"] = func() bool {
 		versionNumber, err := solutionClient.Hello()
@@ -205,8 +236,54 @@ func main() {
 		}
 	}
 
-	// set the postgres random seed for data table reading
-	pg.SetRandomSeed(config.PostgresRandomSeed)
+	// make sure the metadata tables exist when metadata is stored in postgres
+	if config.MetadataStorage == env.MetadataStoragePostgres {
+		pgMetadataStorage, err := pg.NewMetadataStorageClient(postgresClientCtor)
+		if err == nil {
+			err = pgMetadataStorage.InitializeMetadataStorage()
+		}
+		if err != nil {
+			log.Errorf("%+v", err)
+			os.Exit(1)
+		}
+	}
+
+	// make sure the metadata tables exist when metadata is stored in sqlite
+	if config.MetadataStorage == env.MetadataStorageSQLite {
+		sqliteMetadataStorage, err := sq.NewMetadataStorageClient(sqliteClientCtor)
+		if err == nil {
+			err = sqliteMetadataStorage.InitializeMetadataStorage()
+		}
+		if err != nil {
+			log.Errorf("%+v", err)
+			os.Exit(1)
+		}
+	}
+
+	// make sure the model index carries the model registry mappings when models are stored in ES
+	if config.MetadataStorage != env.MetadataStoragePostgres && config.MetadataStorage != env.MetadataStorageSQLite {
+		_, err = es.NewExportedModelStorage(config.ESModelsIndex, true, esClientCtor)()
+		if err != nil {
+			log.Errorf("%+v", err)
+			os.Exit(1)
+		}
+	}
+
+	// the embedded database is not set up by a separate ingest step so the
+	// solution tables are created on startup
+	if config.DataStorage == env.DataStorageSQLite {
+		sqliteClient, err := sqliteClientCtor()
+		if err == nil {
+			err = sqlite.CreateSolutionStorageTables(sqliteClient)
+		}
+		if err != nil {
+			log.Errorf("%+v", err)
+			os.Exit(1)
+		}
+	}
+
+	// set the random seed for data table reading
+	sqlbuilder.SetRandomSeed(config.PostgresRandomSeed)
 
 	// set the ingest client to use
 	task.SetClient(solutionClient)
@@ -224,7 +301,7 @@ func main() {
 		isiDatamartClientCtor := rest.NewClient(config.DatamartURIISI)
 		datamartCtors[dm.ProvenanceISI] = dm.NewISIMetadataStorage(config.DatamartImportFolder, &config, ingestConfig, isiDatamartClientCtor)
 	}
-	datamartCtors[es.Provenance] = esMetadataStorageCtor
+	datamartCtors[metadataProvenance] = metadataStorageCtor
 
 	// Loads image enhancement library
 	if config.ShouldScaleImages {
@@ -238,6 +315,13 @@ func main() {
 			os.Exit(1)
 		}
 	}
+	// start running the scheduled predictions
+	err = task.InitializePredictionScheduler(metadataStorageCtor, dataStorageCtor, solutionStorageCtor, exportedModelStorageCtor,
+		routes.NewPredictionResultExporter(solutionStorageCtor, dataStorageCtor, metadataStorageCtor))
+	if err != nil {
+		log.Warnf("unable to load prediction schedules: %+v", err)
+	}
+
 	// register routes
 	mux := goji.NewMux()
 	mux.Use(middleware.Log)
@@ -246,70 +330,99 @@ func main() {
 	routes.SetVerboseError(config.VerboseError)
 	// GET
 	registerRoute(mux, "/distil/datasets", routes.DatasetsHandler(datamartCtors))
-	registerRoute(mux, "/distil/available", routes.AvailableDatasetsHandler(esMetadataStorageCtor))
-	registerRoute(mux, "/distil/datasets/:dataset", routes.DatasetHandler(esMetadataStorageCtor))
-	registerRoute(mux, "/distil/models", routes.ModelsHandler(esExportedModelStorageCtor))
-	registerRoute(mux, "/distil/models/:model", routes.ModelHandler(esExportedModelStorageCtor))
+	registerRoute(mux, "/distil/available", routes.AvailableDatasetsHandler(metadataStorageCtor))
+	registerRoute(mux, "/distil/datasets/:dataset", routes.DatasetHandler(metadataStorageCtor))
+	registerRoute(mux, "/distil/models", routes.ModelsHandler(exportedModelStorageCtor))
+	registerRoute(mux, "/distil/models/:model", routes.ModelHandler(exportedModelStorageCtor))
+	registerRoute(mux, "/distil/model-registry/:model", routes.ModelVersionsHandler(exportedModelStorageCtor))
+	registerRoute(mux, "/distil/model-registry/:model/compare/:base/:other", routes.ModelCompareHandler(exportedModelStorageCtor))
 	registerRoute(mux, "/distil/join-suggestions/:dataset", routes.DatasetsHandler(datamartCtors))
-	registerRoute(mux, "/distil/solution/:solution-id", routes.SolutionHandler(pgSolutionStorageCtor, esMetadataStorageCtor))
-	registerRoute(mux, "/distil/solutions/:dataset/:target", routes.SolutionsHandler(pgSolutionStorageCtor, esMetadataStorageCtor))
-	registerRoute(mux, "/distil/solution-requests/:dataset/:target", routes.SolutionRequestsHandler(pgSolutionStorageCtor))
-	registerRoute(mux, "/distil/solution-request/:request-id", routes.SolutionRequestHandler(pgSolutionStorageCtor))
-	registerRoute(mux, "/distil/prediction/:request-id", routes.PredictionHandler(pgSolutionStorageCtor))
-	registerRoute(mux, "/distil/predictions/:fitted-solution-id", routes.PredictionsHandler(pgSolutionStorageCtor))
-	registerRoute(mux, "/distil/variables/:dataset", routes.VariablesHandler(esMetadataStorageCtor, pgDataStorageCtor))
-	registerRoute(mux, "/distil/variable-rankings/:dataset/:target", routes.VariableRankingHandler(esMetadataStorageCtor))
-	registerRoute(mux, "/distil/residuals-extrema/:dataset/:target", routes.ResidualsExtremaHandler(esMetadataStorageCtor, pgSolutionStorageCtor, pgDataStorageCtor))
+	registerRoute(mux, "/distil/solution/:solution-id", routes.SolutionHandler(solutionStorageCtor, metadataStorageCtor))
+	registerRoute(mux, "/distil/solutions/:dataset/:target", routes.SolutionsHandler(solutionStorageCtor, metadataStorageCtor))
+	registerRoute(mux, "/distil/solution-requests/:dataset/:target", routes.SolutionRequestsHandler(solutionStorageCtor))
+	registerRoute(mux, "/distil/solution-request/:request-id", routes.SolutionRequestHandler(solutionStorageCtor))
+	registerRoute(mux, "/distil/solution-request-split/:request-id", routes.SolutionRequestSplitHandler(solutionStorageCtor))
+	registerRoute(mux, "/distil/prediction/:request-id", routes.PredictionHandler(solutionStorageCtor))
+	registerRoute(mux, "/distil/predictions/:fitted-solution-id", routes.PredictionsHandler(solutionStorageCtor))
+	registerRoute(mux, "/distil/variables/:dataset", routes.VariablesHandler(metadataStorageCtor, dataStorageCtor))
+	registerRoute(mux, "/distil/variable-rankings/:dataset/:target", routes.VariableRankingHandler(metadataStorageCtor))
+	registerRoute(mux, "/distil/residuals-extrema/:dataset/:target", routes.ResidualsExtremaHandler(metadataStorageCtor, solutionStorageCtor, dataStorageCtor))
 	registerRoute(mux, "/distil/export/:solution-id", routes.ExportHandler(solutionClient, config.D3MOutputDir, discoveryLogger))
 	registerRoute(mux, "/distil/config", routes.ConfigHandler(config, version, timestamp, ta2Version))
-	registerRoute(mux, "/distil/task/:dataset/:target/:variables", routes.TaskHandler(pgDataStorageCtor, esMetadataStorageCtor))
-	registerRoute(mux, "/distil/multiband-image/:dataset/:image-id/:band-combination/:is-thumbnail/:ramp/*", routes.MultiBandImageHandler(esMetadataStorageCtor, pgDataStorageCtor, config))
-	registerRoute(mux, "/distil/solution-variable-rankings/:solution-id", routes.SolutionVariableRankingHandler(esMetadataStorageCtor, pgSolutionStorageCtor))
-	registerRoute(mux, "/distil/export-results/:produce-request-id/:format", routes.ExportResultHandler(pgSolutionStorageCtor, pgDataStorageCtor, esMetadataStorageCtor))
-	registerRoute(mux, "/ws", ws.SolutionHandler(solutionClient, esMetadataStorageCtor, pgDataStorageCtor, pgSolutionStorageCtor, esExportedModelStorageCtor))
-	registerRoute(mux, "/distil/image-attention/:dataset/:result-id/:index/:opacity/:color-scale", routes.ImageAttentionHandler(pgSolutionStorageCtor, esMetadataStorageCtor))
-	registerRoute(mux, "/distil/outlier-detection/:dataset/:variable", routes.OutlierDetectionHandler(esMetadataStorageCtor))
-	registerRoute(mux, "/distil/outlier-results/:dataset/:variable", routes.OutlierResultsHandler(esMetadataStorageCtor, pgDataStorageCtor))
+	registerRoute(mux, "/distil/task/:dataset/:target/:variables", routes.TaskHandler(dataStorageCtor, metadataStorageCtor))
+	registerRoute(mux, "/distil/multiband-image/:dataset/:image-id/:band-combination/:is-thumbnail/:ramp/*", routes.MultiBandImageHandler(metadataStorageCtor, dataStorageCtor, config))
+	registerRoute(mux, "/distil/tiles/:dataset/:band-combination/:z/:x/:y.png", routes.TileHandler(metadataStorageCtor, dataStorageCtor, solutionStorageCtor))
+	registerRoute(mux, "/distil/solution-variable-rankings/:solution-id", routes.SolutionVariableRankingHandler(metadataStorageCtor, solutionStorageCtor))
+	registerRoute(mux, "/distil/export-results/:produce-request-id/:format", routes.ExportResultHandler(solutionStorageCtor, dataStorageCtor, metadataStorageCtor))
+	registerRoute(mux, "/distil/solution-search/:request-id/events", routes.SolutionSearchEventsHandler(solutionStorageCtor))
+	registerRoute(mux, "/distil/prediction-schedules", routes.PredictionSchedulesHandler(solutionStorageCtor))
+	registerRoute(mux, "/distil/prediction-schedules/:schedule-id/runs", routes.PredictionScheduleRunsHandler(solutionStorageCtor))
+	registerRoute(mux, "/ws", ws.SolutionHandler(solutionClient, metadataStorageCtor, dataStorageCtor, solutionStorageCtor, exportedModelStorageCtor))
+	registerRoute(mux, "/distil/image-attention/:dataset/:result-id/:index/:opacity/:color-scale", routes.ImageAttentionHandler(solutionStorageCtor, metadataStorageCtor))
+	registerRoute(mux, "/distil/outlier-detection/:dataset/:variable", routes.OutlierDetectionHandler(metadataStorageCtor))
+	registerRoute(mux, "/distil/outlier-results/:dataset/:variable", routes.OutlierResultsHandler(metadataStorageCtor, dataStorageCtor))
+	registerRoute(mux, "/distil/import-status/:datasetID", routes.ImportStatusHandler())
+	registerRoute(mux, "/distil/data-quality/:dataset", routes.DataQualityHandler(metadataStorageCtor, &config))
+	registerRoute(mux, "/distil/lineage/:dataset", routes.LineageHandler(metadataStorageCtor))
+	registerRoute(mux, "/distil/band-expressions/:dataset", routes.BandExpressionsHandler(metadataStorageCtor))
+	registerRoute(mux, "/distil/import-jobs", routes.ImportJobsHandler())
+	registerRoute(mux, "/distil/queue", routes.QueueHandler())
+	registerRoute(mux, "/distil/import-jobs/:job-id", routes.ImportJobHandler())
 
 	// POST
-	registerRoutePost(mux, "/distil/grouping/:dataset", routes.GroupingHandler(pgDataStorageCtor, esMetadataStorageCtor))
-	registerRoutePost(mux, "/distil/remove-grouping/:dataset/:variable", routes.RemoveGroupingHandler(pgDataStorageCtor, esMetadataStorageCtor))
-	registerRoutePost(mux, "/distil/variables/:dataset", routes.VariableTypeHandler(pgDataStorageCtor, esMetadataStorageCtor))
-	registerRoutePost(mux, "/distil/image-pack", routes.MultiBandImagePackHandler(esMetadataStorageCtor, pgDataStorageCtor, config))
-	registerRoutePost(mux, "/distil/data/:dataset", routes.DataHandler(pgDataStorageCtor, esMetadataStorageCtor, pgSolutionStorageCtor))
-	registerRoutePost(mux, "/distil/import/:datasetID/:source/:provenance", routes.ImportHandler(pgDataStorageCtor, datamartCtors, fileMetadataStorageCtor, esMetadataStorageCtor, &config))
-	registerRoutePost(mux, "/distil/delete/:dataset/:variable", routes.DeleteHandler(pgDataStorageCtor, esMetadataStorageCtor))
-	registerRoutePost(mux, "/distil/prediction-results/:produce-request-id", routes.PredictionResultsHandler(pgSolutionStorageCtor, pgDataStorageCtor, esMetadataStorageCtor))
-	registerRoutePost(mux, "/distil/index-data/:type", routes.IndexDataHandler(esMetadataStorageCtor))
-	registerRoutePost(mux, "/distil/variable-summary/:dataset/:variable/:mode", routes.VariableSummaryHandler(esMetadataStorageCtor, pgDataStorageCtor))
-	registerRoutePost(mux, "/distil/training-summary/:dataset/:variable/:results-uuid/:mode", routes.TrainingSummaryHandler(esMetadataStorageCtor, pgSolutionStorageCtor, pgDataStorageCtor))
-	registerRoutePost(mux, "/distil/target-summary/:dataset/:target/:results-uuid/:mode", routes.TargetSummaryHandler(esMetadataStorageCtor, pgSolutionStorageCtor, pgDataStorageCtor))
-	registerRoutePost(mux, "/distil/residuals-summary/:dataset/:target/:results-uuid/:mode", routes.ResidualsSummaryHandler(esMetadataStorageCtor, pgSolutionStorageCtor, pgDataStorageCtor))
-	registerRoutePost(mux, "/distil/correctness-summary/:dataset/:results-uuid/:mode", routes.CorrectnessSummaryHandler(esMetadataStorageCtor, pgSolutionStorageCtor, pgDataStorageCtor))
-	registerRoutePost(mux, "/distil/confidence-summary/:dataset/:results-uuid/:mode", routes.ConfidenceSummaryHandler(esMetadataStorageCtor, pgSolutionStorageCtor, pgDataStorageCtor))
-	registerRoutePost(mux, "/distil/prediction-result-summary/:results-uuid/:mode", routes.PredictionResultSummaryHandler(esMetadataStorageCtor, pgSolutionStorageCtor, pgDataStorageCtor))
-	registerRoutePost(mux, "/distil/solution-result-summary/:results-uuid/:mode", routes.SolutionResultSummaryHandler(esMetadataStorageCtor, pgSolutionStorageCtor, pgDataStorageCtor))
-	registerRoutePost(mux, "/distil/geocode/:dataset/:variable", routes.GeocodingHandler(esMetadataStorageCtor, pgDataStorageCtor))
-	registerRoutePost(mux, "/distil/clear/:dataset/:variable", routes.ClearHandler(esMetadataStorageCtor, pgDataStorageCtor))
-	registerRoutePost(mux, "/distil/cluster/:dataset/:variable", routes.ClusteringHandler(esMetadataStorageCtor, pgDataStorageCtor, config))
-	registerRoutePost(mux, "/distil/cluster/:result-id", routes.ClusteringExplainHandler(pgSolutionStorageCtor, esMetadataStorageCtor, pgDataStorageCtor, config))
+	registerRoutePost(mux, "/distil/grouping/:dataset", routes.GroupingHandler(dataStorageCtor, metadataStorageCtor))
+	registerRoutePost(mux, "/distil/remove-grouping/:dataset/:variable", routes.RemoveGroupingHandler(dataStorageCtor, metadataStorageCtor))
+	registerRoutePost(mux, "/distil/variables/:dataset", routes.VariableTypeHandler(dataStorageCtor, metadataStorageCtor))
+	registerRoutePost(mux, "/distil/image-pack", routes.MultiBandImagePackHandler(metadataStorageCtor, dataStorageCtor, config))
+	registerRoutePost(mux, "/distil/data/:dataset", routes.DataHandler(dataStorageCtor, metadataStorageCtor, solutionStorageCtor))
+	registerRoutePost(mux, "/distil/import/:datasetID/:source/:provenance", routes.ImportHandler(dataStorageCtor, datamartCtors, fileMetadataStorageCtor, metadataStorageCtor, &config))
+	registerRoutePost(mux, "/distil/import-resume/:datasetID", routes.ImportResumeHandler(dataStorageCtor, metadataStorageCtor, &config))
+	registerRoutePost(mux, "/distil/delete/:dataset/:variable", routes.DeleteHandler(dataStorageCtor, metadataStorageCtor))
+	registerRoutePost(mux, "/distil/prediction-results/:produce-request-id", routes.PredictionResultsHandler(solutionStorageCtor, dataStorageCtor, metadataStorageCtor))
+	registerRoutePost(mux, "/distil/index-data/:type", routes.IndexDataHandler(metadataStorageCtor))
+	registerRoutePost(mux, "/distil/band-expressions/:dataset", routes.BandExpressionCreateHandler(metadataStorageCtor))
+	registerRoutePost(mux, "/distil/variable-summary/:dataset/:variable/:mode", routes.VariableSummaryHandler(metadataStorageCtor, dataStorageCtor))
+	registerRoutePost(mux, "/distil/training-summary/:dataset/:variable/:results-uuid/:mode", routes.TrainingSummaryHandler(metadataStorageCtor, solutionStorageCtor, dataStorageCtor))
+	registerRoutePost(mux, "/distil/target-summary/:dataset/:target/:results-uuid/:mode", routes.TargetSummaryHandler(metadataStorageCtor, solutionStorageCtor, dataStorageCtor))
+	registerRoutePost(mux, "/distil/residuals-summary/:dataset/:target/:results-uuid/:mode", routes.ResidualsSummaryHandler(metadataStorageCtor, solutionStorageCtor, dataStorageCtor))
+	registerRoutePost(mux, "/distil/correctness-summary/:dataset/:results-uuid/:mode", routes.CorrectnessSummaryHandler(metadataStorageCtor, solutionStorageCtor, dataStorageCtor))
+	registerRoutePost(mux, "/distil/confidence-summary/:dataset/:results-uuid/:mode", routes.ConfidenceSummaryHandler(metadataStorageCtor, solutionStorageCtor, dataStorageCtor))
+	registerRoutePost(mux, "/distil/prediction-result-summary/:results-uuid/:mode", routes.PredictionResultSummaryHandler(metadataStorageCtor, solutionStorageCtor, dataStorageCtor))
+	registerRoutePost(mux, "/distil/solution-result-summary/:results-uuid/:mode", routes.SolutionResultSummaryHandler(metadataStorageCtor, solutionStorageCtor, dataStorageCtor))
+	registerRoutePost(mux, "/distil/geocode/:dataset/:variable", routes.GeocodingHandler(metadataStorageCtor, dataStorageCtor))
+	registerRoutePost(mux, "/distil/clear/:dataset/:variable", routes.ClearHandler(metadataStorageCtor, dataStorageCtor))
+	registerRoutePost(mux, "/distil/cluster/:dataset/:variable", routes.ClusteringHandler(metadataStorageCtor, dataStorageCtor, config))
+	registerRoutePost(mux, "/distil/cluster/:result-id", routes.ClusteringExplainHandler(solutionStorageCtor, metadataStorageCtor, dataStorageCtor, config))
 	registerRoutePost(mux, "/distil/upload/:dataset", routes.UploadHandler(&config))
-	registerRoutePost(mux, "/distil/update/:dataset", routes.UpdateHandler(esMetadataStorageCtor, pgDataStorageCtor, config))
-	registerRoutePost(mux, "/distil/clone-result/:produce-request-id", routes.CloningResultsHandler(esMetadataStorageCtor, pgDataStorageCtor, pgSolutionStorageCtor, config))
-	registerRoutePost(mux, "/distil/clone/:dataset", routes.CloningHandler(esMetadataStorageCtor, pgDataStorageCtor, config))
-	registerRoutePost(mux, "/distil/save-dataset/:dataset", routes.SaveDatasetHandler(esMetadataStorageCtor, pgDataStorageCtor, config))
-	registerRoutePost(mux, "/distil/add-field/:dataset", routes.AddFieldHandler(esMetadataStorageCtor, pgDataStorageCtor))
-	registerRoutePost(mux, "/distil/extract/:dataset", routes.ExtractHandler(esMetadataStorageCtor, pgDataStorageCtor, config))
-	registerRoutePost(mux, "/distil/join", routes.JoinHandler(pgDataStorageCtor, esMetadataStorageCtor))
-	registerRoutePost(mux, "/distil/timeseries/:dataset/:timeseriesColName/:xColName/:yColName", routes.TimeseriesHandler(esMetadataStorageCtor, pgDataStorageCtor))
-	registerRoutePost(mux, "/distil/timeseries-forecast/:truthDataset/:forecastDataset/:timeseriesColName/:xColName/:yColName/:result-uuid", routes.TimeseriesForecastHandler(esMetadataStorageCtor, pgDataStorageCtor, pgSolutionStorageCtor, config.TrainTestSplitTimeSeries))
+	registerRoutePost(mux, "/distil/update/:dataset", routes.UpdateHandler(metadataStorageCtor, dataStorageCtor, config))
+	registerRoutePost(mux, "/distil/clone-result/:produce-request-id", routes.CloningResultsHandler(metadataStorageCtor, dataStorageCtor, solutionStorageCtor, config))
+	registerRoutePost(mux, "/distil/clone/:dataset", routes.CloningHandler(metadataStorageCtor, dataStorageCtor, config))
+	registerRoutePost(mux, "/distil/save-dataset/:dataset", routes.SaveDatasetHandler(metadataStorageCtor, dataStorageCtor, config))
+	registerRoutePost(mux, "/distil/export-dataset/:dataset/:format", routes.ExportDatasetHandler(metadataStorageCtor, dataStorageCtor))
+	registerRoutePost(mux, "/distil/add-field/:dataset", routes.AddFieldHandler(metadataStorageCtor, dataStorageCtor))
+	registerRoutePost(mux, "/distil/extract/:dataset", routes.ExtractHandler(metadataStorageCtor, dataStorageCtor, config))
+	registerRoutePost(mux, "/distil/solution-search", routes.CreateSolutionSearchHandler(solutionClient, metadataStorageCtor, dataStorageCtor, solutionStorageCtor))
+	registerRoutePost(mux, "/distil/solution-search/:request-id/stop", routes.StopSolutionSearchHandler())
+	registerRoutePost(mux, "/distil/predict", routes.PredictHandler(metadataStorageCtor, dataStorageCtor, solutionStorageCtor, exportedModelStorageCtor))
+	registerRoutePost(mux, "/distil/query", routes.QueryHandler(metadataStorageCtor, dataStorageCtor))
+	registerRoutePost(mux, "/distil/prediction-schedules", routes.PredictionScheduleCreateHandler())
+	registerRoutePost(mux, "/distil/prediction-schedules/:schedule-id/run", routes.PredictionScheduleRunHandler())
+	registerRoutePost(mux, "/distil/join", routes.JoinHandler(dataStorageCtor, metadataStorageCtor))
+	registerRoutePost(mux, "/distil/timeseries/:dataset/:timeseriesColName/:xColName/:yColName", routes.TimeseriesHandler(metadataStorageCtor, dataStorageCtor))
+	registerRoutePost(mux, "/distil/timeseries-forecast/:truthDataset/:forecastDataset/:timeseriesColName/:xColName/:yColName/:result-uuid", routes.TimeseriesForecastHandler(metadataStorageCtor, dataStorageCtor, solutionStorageCtor, config.TrainTestSplitTimeSeries))
 	registerRoutePost(mux, "/distil/event", routes.UserEventHandler(discoveryLogger))
-	registerRoutePost(mux, "/distil/save/:solution-id/:fitted", routes.SaveHandler(esExportedModelStorageCtor, pgSolutionStorageCtor, esMetadataStorageCtor))
-	registerRoutePost(mux, "/distil/delete-dataset/:dataset", routes.DeletingDatasetHandler(esMetadataStorageCtor, pgDataStorageCtor))
-	registerRoutePost(mux, "/distil/delete-model/:model", routes.DeletingModelHandler(esExportedModelStorageCtor))
+	registerRoutePost(mux, "/distil/save/:solution-id/:fitted", routes.SaveHandler(exportedModelStorageCtor, solutionStorageCtor, metadataStorageCtor))
+	registerRoutePost(mux, "/distil/delete-dataset/:dataset", routes.DeletingDatasetHandler(metadataStorageCtor, dataStorageCtor))
+	registerRoutePost(mux, "/distil/delete-model/:model", routes.DeletingModelHandler(exportedModelStorageCtor))
+	registerRoutePost(mux, "/distil/model-registry/:model/:version/stage", routes.ModelStageHandler(exportedModelStorageCtor))
+
+	// DELETE
+	registerRouteDelete(mux, "/distil/import-jobs/:job-id", routes.CancelImportJobHandler())
+	registerRouteDelete(mux, "/distil/prediction-schedules/:schedule-id", routes.PredictionScheduleDeleteHandler())
+	registerRouteDelete(mux, "/distil/band-expressions/:dataset/:expression-id", routes.BandExpressionDeleteHandler(metadataStorageCtor))
 
 	// static
-	registerRoute(mux, "/distil/image/:dataset/:file/:is-thumbnail/:scale", routes.ImageHandler(esMetadataStorageCtor, &config))
+	registerRoute(mux, "/distil/image/:dataset/:file/:is-thumbnail/:scale", routes.ImageHandler(metadataStorageCtor, &config))
 	registerRoute(mux, "/*", routes.FileHandler("./dist"))
 
 	// catch kill signals for graceful shutdown
@@ -351,4 +464,10 @@ func createOutputFolders(config *env.Config) {
 	if err := os.MkdirAll(batchPath, os.ModePerm); err != nil {
 		log.Error(errors.Wrap(err, "failed to created batch folder"))
 	}
+
+	// create the ingest checkpoint folder
+	checkpointPath := env.GetCheckpointPath()
+	if err := os.MkdirAll(checkpointPath, os.ModePerm); err != nil {
+		log.Error(errors.Wrap(err, "failed to created checkpoint folder"))
+	}
 }
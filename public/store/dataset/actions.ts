@@ -74,6 +74,7 @@ import {
   SummaryMode,
   TableData,
   Task,
+  TimeseriesResample,
   Variable,
   VariableRankingPendingRequest,
   VariableSummaryKey,
@@ -93,6 +94,26 @@ async function getVariables(dataset: string): Promise<Variable[]> {
   }));
 }
 
+// interval between two import job status requests, in milliseconds
+const IMPORT_JOB_POLL_INTERVAL = 2000;
+
+/** Poll an import job until it completes and return the import result. */
+async function waitForImportJob(jobID: string): Promise<any> {
+  for (;;) {
+    const response = await axios.get(`/distil/import-jobs/${jobID}`);
+    const job = response.data;
+    if (job.status === "complete") {
+      return job.result;
+    }
+    if (job.status === "failed" || job.status === "cancelled") {
+      throw new Error(job.error);
+    }
+    await new Promise((resolve) =>
+      setTimeout(resolve, IMPORT_JOB_POLL_INTERVAL)
+    );
+  }
+}
+
 /** Return the best variable name of a dataset needed for the outlier detection. */
 function getOutlierVariableName(): string {
   const variables = getters.getVariables(store) ?? [];
@@ -657,8 +678,9 @@ export const actions = {
       `/distil/import/${args.datasetID}/${args.source}/${args.provenance}`,
       postParams
     );
+    const result = await waitForImportJob(response.data.jobId);
     await actions.searchDatasets(context, args.terms);
-    return response.data;
+    return result;
   },
 
   async importJoinDataset(
@@ -689,11 +711,12 @@ export const actions = {
           searchResults: args.searchResults,
         }
       );
+      const result = await waitForImportJob(response.data.jobId);
       mutations.updatePendingRequests(context, {
         ...update,
         status: DatasetPendingRequestStatus.RESOLVED,
       });
-      return response && response.data;
+      return result;
     } catch (error) {
       mutations.updatePendingRequests(context, {
         ...update,
@@ -1349,6 +1372,8 @@ export const actions = {
       yColName: string;
       timeseriesIds: string[];
       uniqueTrail?: string;
+      resample?: TimeseriesResample;
+      maxPoints?: number;
     }
   ) {
     // format the data
@@ -1364,7 +1389,11 @@ export const actions = {
         )}/${encodeURIComponent(args.variableKey)}/${encodeURIComponent(
           args.xColName
         )}/${encodeURIComponent(args.yColName)}`,
-        { timeseries: timeseriesIDs }
+        {
+          timeseries: timeseriesIDs,
+          resample: args.resample,
+          maxPoints: args.maxPoints,
+        }
       );
       mutations.bulkUpdateTimeseries(context, {
         dataset: args.dataset,
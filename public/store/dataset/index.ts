@@ -171,10 +171,18 @@ export interface Bucket {
   buckets?: Bucket[];
 }
 
+// Calendar resampling applied to timeseries requests, with the aggregation
+// used for each bucket (add, min, max or mean).
+export interface TimeseriesResample {
+  interval: "minute" | "hour" | "day" | "week" | "month";
+  operation?: string;
+}
+
 export interface Histogram {
   buckets?: Bucket[];
   categoryBuckets?: Dictionary<Bucket[]>;
   extrema: Extrema;
+  missing?: Bucket;
   exemplars?: string[];
   stddev?: number;
   mean?: number;
@@ -94,6 +94,12 @@ export const TEXT_FILTER = "text";
  */
 export const GEOCOORDINATE_FILTER = "geocoordinate";
 
+/**
+ * Missing filter, selecting documents that have no value for the specified key.
+ * @constant {string}
+ */
+export const MISSING_FILTER = "missing";
+
 /**
  * Include filter, excluding documents that do not fall within the filter.
  * @constant {string}
@@ -126,9 +132,21 @@ export interface Filter {
   categories?: string[];
   d3mIndices?: string[];
 }
+/**
+ * Node of a boolean filter expression tree. Leaves hold a single filter while
+ * groups combine their children with the operator.
+ */
+export interface FilterExpression {
+  operator?: "and" | "or";
+  not?: boolean;
+  filter?: Filter;
+  children?: FilterExpression[];
+}
+
 export interface FilterSetsParams {
   highlights: FilterObject;
   filters: FilterSet;
+  expression?: FilterExpression;
   variables: string[];
   size?: number;
   dataMode?: string;
@@ -149,6 +167,7 @@ export interface FilterSet {
 export interface FilterParams {
   highlights: FilterObject;
   filters: FilterObject;
+  expression?: FilterExpression;
   variables: string[];
   size?: number;
   dataMode?: string;